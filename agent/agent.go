@@ -0,0 +1,301 @@
+// Package agent provides a ReAct-style agent that alternates between model
+// generations and tool executions until the task is done.
+package agent
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/tools"
+)
+
+const defaultMaxSteps = 10
+
+// ErrStop can be returned by an event handler to end a run early
+var ErrStop = errors.New("agent stopped")
+
+// TerminationReason describes why a run ended
+type TerminationReason string
+
+const (
+	// Finished means the model answered without calling tools
+	Finished TerminationReason = "finished"
+	// MaxStepsReached means the step limit was hit while tools were still called
+	MaxStepsReached TerminationReason = "max_steps"
+	// Stopped means an event handler returned ErrStop
+	Stopped TerminationReason = "stopped"
+	// Cancelled means the context was cancelled or its deadline expired
+	Cancelled TerminationReason = "cancelled"
+	// Failed means generation or an event handler returned an error
+	Failed TerminationReason = "failed"
+)
+
+// EventType identifies an event emitted during a run
+type EventType string
+
+const (
+	EventStepStart  EventType = "step_start"
+	EventResponse   EventType = "response"
+	EventToolResult EventType = "tool_result"
+	EventFinish     EventType = "finish"
+)
+
+// Event represents progress of a run
+type Event struct {
+	Type     EventType
+	Step     int
+	Response *generator.Response // Set for EventResponse
+	Results  []generator.Message // Tool results, set for EventToolResult
+	Result   *Result             // Set for EventFinish
+}
+
+// Handler receives events. Returning ErrStop ends the run with Stopped;
+// returning any other error ends it with Failed.
+type Handler func(Event) error
+
+// Step represents one scratchpad entry: what the model thought, which tools
+// it called and what it observed
+type Step struct {
+	Thought      string               `json:"thought,omitempty"`
+	ToolCalls    []generator.ToolCall `json:"tool_calls,omitempty"`
+	Observations []generator.Message  `json:"observations,omitempty"`
+}
+
+// State represents a run at a step boundary. It is what a StateStore persists
+// and what Resume continues from.
+type State struct {
+	ID       string              `json:"id"`
+	Messages []generator.Message `json:"messages"`
+	// PendingToolCalls are calls requested by the model but not executed yet
+	PendingToolCalls []generator.ToolCall `json:"pending_tool_calls,omitempty"`
+	Steps            int                  `json:"steps"`
+	Scratchpad       []Step               `json:"scratchpad,omitempty"`
+	Output           string               `json:"output,omitempty"`
+	// Reason is empty while the run is in progress
+	Reason TerminationReason `json:"reason,omitempty"`
+}
+
+// Result represents the outcome of a run
+type Result struct {
+	State
+	Err error
+}
+
+// Agent runs tasks with a client and a tool set. An Agent holds no per-run
+// state and is safe for concurrent use.
+type Agent struct {
+	client       *gollm.Client
+	tools        *tools.Registry
+	systemPrompt string
+	model        string
+	maxSteps     int
+	handler      Handler
+	template     generator.Request
+	store        StateStore
+}
+
+// Option is a function that configures an Agent
+type Option func(*Agent)
+
+// WithTools sets the tools available to the agent
+func WithTools(registry *tools.Registry) Option {
+	return func(a *Agent) {
+		a.tools = registry
+	}
+}
+
+// WithSystemPrompt sets the instructions of the agent
+func WithSystemPrompt(prompt string) Option {
+	return func(a *Agent) {
+		a.systemPrompt = prompt
+	}
+}
+
+// WithModel sets the model used for every step
+func WithModel(model string) Option {
+	return func(a *Agent) {
+		a.model = model
+	}
+}
+
+// WithMaxSteps bounds the number of generations of a run, 10 by default
+func WithMaxSteps(n int) Option {
+	return func(a *Agent) {
+		a.maxSteps = n
+	}
+}
+
+// WithHandler sets the function receiving run events
+func WithHandler(h Handler) Option {
+	return func(a *Agent) {
+		a.handler = h
+	}
+}
+
+// WithStateStore checkpoints runs to store after every step so they can be
+// resumed with Resume after an interruption or a restart
+func WithStateStore(store StateStore) Option {
+	return func(a *Agent) {
+		a.store = store
+	}
+}
+
+// WithRequestTemplate sets generation parameters applied to every step. Its
+// Model, Messages and Tools are ignored.
+func WithRequestTemplate(req generator.Request) Option {
+	return func(a *Agent) {
+		a.template = req
+	}
+}
+
+// New creates a new agent sending requests through client
+func New(client *gollm.Client, opts ...Option) *Agent {
+	if client == nil {
+		panic("client cannot be nil")
+	}
+
+	a := &Agent{client: client, maxSteps: defaultMaxSteps}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.tools == nil {
+		a.tools, _ = tools.NewRegistry()
+	}
+	return a
+}
+
+// Run executes the task described by input. The returned Result is never nil;
+// the error is the one that caused a Failed or Cancelled termination. The
+// run ID is available as Result.ID.
+func (a *Agent) Run(ctx context.Context, input string) (*Result, error) {
+	res := &Result{State: State{ID: uuid.New().String()}}
+	if a.systemPrompt != "" {
+		res.Messages = append(res.Messages, generator.Message{Role: generator.SYSTEM, Content: a.systemPrompt})
+	}
+	res.Messages = append(res.Messages, generator.Message{Role: generator.USER, Content: input})
+	return a.run(ctx, res)
+}
+
+// Resume continues a run checkpointed in the state store, first executing
+// tool calls that were pending when it stopped. Finished runs are returned as
+// is; other runs get a fresh step budget.
+func (a *Agent) Resume(ctx context.Context, id string) (*Result, error) {
+	if a.store == nil {
+		return nil, errors.New("agent has no state store")
+	}
+	st, err := a.store.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{State: *st}
+	if res.Reason == Finished {
+		return res, nil
+	}
+	res.Reason = ""
+	return a.run(ctx, res)
+}
+
+func (a *Agent) run(ctx context.Context, res *Result) (*Result, error) {
+	for n := 0; ; n++ {
+		if len(res.PendingToolCalls) > 0 {
+			observations := a.tools.Execute(ctx, res.PendingToolCalls)
+			if last := len(res.Scratchpad) - 1; last >= 0 {
+				res.Scratchpad[last].Observations = observations
+			}
+			res.Messages = append(res.Messages, observations...)
+			res.PendingToolCalls = nil
+			if err := a.checkpoint(ctx, res); err != nil {
+				return a.finish(ctx, res, Failed, err)
+			}
+			if err := a.emit(Event{Type: EventToolResult, Step: res.Steps, Results: observations}); err != nil {
+				return a.finish(ctx, res, stopReason(err), err)
+			}
+		}
+
+		if n >= a.maxSteps {
+			return a.finish(ctx, res, MaxStepsReached, nil)
+		}
+		if err := ctx.Err(); err != nil {
+			return a.finish(ctx, res, Cancelled, err)
+		}
+		if err := a.emit(Event{Type: EventStepStart, Step: res.Steps + 1}); err != nil {
+			return a.finish(ctx, res, stopReason(err), err)
+		}
+
+		resp, err := a.client.Generate(ctx, a.request(res.Messages))
+		if err != nil {
+			if ctx.Err() != nil {
+				return a.finish(ctx, res, Cancelled, err)
+			}
+			return a.finish(ctx, res, Failed, err)
+		}
+		res.Steps++
+		res.Output = resp.Content
+		res.Messages = append(res.Messages, generator.Message{
+			Role:      generator.ASSISTANT,
+			Content:   resp.Content,
+			ToolCalls: resp.ToolCalls,
+		})
+		res.Scratchpad = append(res.Scratchpad, Step{Thought: resp.Content, ToolCalls: resp.ToolCalls})
+		res.PendingToolCalls = resp.ToolCalls
+		if err := a.checkpoint(ctx, res); err != nil {
+			return a.finish(ctx, res, Failed, err)
+		}
+
+		if err := a.emit(Event{Type: EventResponse, Step: res.Steps, Response: resp}); err != nil {
+			return a.finish(ctx, res, stopReason(err), err)
+		}
+		if len(resp.ToolCalls) == 0 {
+			return a.finish(ctx, res, Finished, nil)
+		}
+	}
+}
+
+func (a *Agent) request(messages []generator.Message) *generator.Request {
+	req := a.template
+	req.Model = a.model
+	req.Messages = messages
+	req.Tools = a.tools.Definitions()
+	return &req
+}
+
+func (a *Agent) emit(e Event) error {
+	if a.handler == nil {
+		return nil
+	}
+	return a.handler(e)
+}
+
+func (a *Agent) checkpoint(ctx context.Context, res *Result) error {
+	if a.store == nil {
+		return nil
+	}
+	st := res.State
+	return a.store.Save(ctx, &st)
+}
+
+func (a *Agent) finish(ctx context.Context, res *Result, reason TerminationReason, err error) (*Result, error) {
+	res.Reason = reason
+	if reason == Failed || reason == Cancelled {
+		res.Err = err
+	}
+	if a.store != nil {
+		// A cancelled run must still be checkpointed to be resumable
+		if saveErr := a.checkpoint(context.WithoutCancel(ctx), res); saveErr != nil && res.Err == nil {
+			res.Reason, res.Err = Failed, saveErr
+		}
+	}
+	// The finish event is informational; its error cannot change the outcome
+	_ = a.emit(Event{Type: EventFinish, Step: res.Steps, Result: res})
+	return res, res.Err
+}
+
+func stopReason(err error) TerminationReason {
+	if errors.Is(err, ErrStop) {
+		return Stopped
+	}
+	return Failed
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/tools"
+)
+
+func calculator(t *testing.T) *tools.Registry {
+	t.Helper()
+	type args struct {
+		A int `json:"a"`
+		B int `json:"b"`
+	}
+	r, err := tools.NewRegistry(tools.Func("add", "Add two numbers", func(_ context.Context, in args) (int, error) {
+		return in.A + in.B, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestAgent_Run(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		if last := req.Messages[len(req.Messages)-1]; last.Role == generator.TOOL {
+			return &generator.Response{Content: "The answer is " + last.Content}, nil
+		}
+		return &generator.Response{
+			Content:   "I should add the numbers",
+			ToolCalls: []generator.ToolCall{{ID: "1", Name: "add", Arguments: `{"a":2,"b":3}`}},
+		}, nil
+	}
+
+	var events []EventType
+	a := New(gollm.NewClient(m), WithTools(calculator(t)), WithHandler(func(e Event) error {
+		events = append(events, e.Type)
+		return nil
+	}))
+
+	res, err := a.Run(context.Background(), "what is 2+3?")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if res.Reason != Finished || res.Output != "The answer is 5" || res.Steps != 2 {
+		t.Errorf("Run() = %+v", res)
+	}
+	if len(res.Scratchpad) != 2 || res.Scratchpad[0].Observations[0].Content != "5" {
+		t.Errorf("Scratchpad = %+v", res.Scratchpad)
+	}
+	want := []EventType{EventStepStart, EventResponse, EventToolResult, EventStepStart, EventResponse, EventFinish}
+	if len(events) != len(want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("events[%d] = %s, want %s", i, events[i], want[i])
+		}
+	}
+}
+
+func TestAgent_RunTermination(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return &generator.Response{ToolCalls: []generator.ToolCall{{ID: "1", Name: "add", Arguments: `{}`}}}, nil
+	}
+	client := gollm.NewClient(m)
+
+	res, _ := New(client, WithTools(calculator(t)), WithMaxSteps(2)).Run(context.Background(), "loop")
+	if res.Reason != MaxStepsReached || res.Steps != 2 {
+		t.Errorf("Run() = %+v, want MaxStepsReached after 2 steps", res)
+	}
+
+	stop := WithHandler(func(e Event) error {
+		if e.Type == EventToolResult {
+			return ErrStop
+		}
+		return nil
+	})
+	res, err := New(client, WithTools(calculator(t)), stop).Run(context.Background(), "loop")
+	if res.Reason != Stopped || err != nil {
+		t.Errorf("Run() = %+v, %v; want Stopped", res, err)
+	}
+}
+
+func TestAgent_Resume(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		if last := req.Messages[len(req.Messages)-1]; last.Role == generator.TOOL {
+			return &generator.Response{Content: "The answer is " + last.Content}, nil
+		}
+		return &generator.Response{ToolCalls: []generator.ToolCall{{ID: "1", Name: "add", Arguments: `{"a":1,"b":1}`}}}, nil
+	}
+	client := gollm.NewClient(m)
+	store, err := NewFileStateStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Stop before the requested tool call is executed
+	interrupt := WithHandler(func(e Event) error {
+		if e.Type == EventResponse {
+			return ErrStop
+		}
+		return nil
+	})
+	res, _ := New(client, WithTools(calculator(t)), WithStateStore(store), interrupt).Run(context.Background(), "1+1?")
+	if res.Reason != Stopped || len(res.PendingToolCalls) != 1 {
+		t.Fatalf("Run() = %+v, want Stopped with a pending tool call", res)
+	}
+
+	resumed, err := New(client, WithTools(calculator(t)), WithStateStore(store)).Resume(context.Background(), res.ID)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if resumed.Reason != Finished || resumed.Output != "The answer is 2" || resumed.Steps != 2 {
+		t.Errorf("Resume() = %+v", resumed)
+	}
+
+	if _, err := New(client, WithStateStore(store)).Resume(context.Background(), "missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("Resume() error = %v, want ErrRunNotFound", err)
+	}
+}
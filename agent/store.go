@@ -0,0 +1,140 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrRunNotFound is returned when a run is not present in a StateStore
+var ErrRunNotFound = errors.New("agent run not found")
+
+// StateStore persists run states by run ID
+type StateStore interface {
+	// Save stores state, replacing any previous state of the same run
+	Save(ctx context.Context, state *State) error
+
+	// Load returns the state of a run, or ErrRunNotFound
+	Load(ctx context.Context, id string) (*State, error)
+
+	// Delete removes the state of a run
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStateStore is a StateStore backed by a map. It survives interrupted
+// runs but not process restarts.
+type MemoryStateStore struct {
+	mu     sync.RWMutex
+	states map[string][]byte
+}
+
+// NewMemoryStateStore creates a new empty in-memory store
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: make(map[string][]byte)}
+}
+
+func (s *MemoryStateStore) Save(_ context.Context, state *State) error {
+	// Stored encoded so later mutations of the run don't leak into the store
+	b, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encoding run %s: %w", state.ID, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state.ID] = b
+	return nil
+}
+
+func (s *MemoryStateStore) Load(_ context.Context, id string) (*State, error) {
+	s.mu.RLock()
+	b, ok := s.states[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	return decodeState(id, b)
+}
+
+func (s *MemoryStateStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, id)
+	return nil
+}
+
+// FileStateStore is a StateStore keeping each run in <dir>/<id>.json
+type FileStateStore struct {
+	dir string
+}
+
+// NewFileStateStore creates a store rooted at dir, creating it if needed
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStateStore{dir: dir}, nil
+}
+
+func (s *FileStateStore) Save(_ context.Context, state *State) error {
+	path, err := s.path(state.ID)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encoding run %s: %w", state.ID, err)
+	}
+
+	// Write then rename so a crash never leaves a truncated checkpoint
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *FileStateStore) Load(_ context.Context, id string) (*State, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeState(id, b)
+}
+
+func (s *FileStateStore) Delete(_ context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileStateStore) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == ".." {
+		return "", fmt.Errorf("invalid run ID: %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+func decodeState(id string, b []byte) (*State, error) {
+	var st State
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, fmt.Errorf("decoding run %s: %w", id, err)
+	}
+	return &st, nil
+}
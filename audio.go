@@ -0,0 +1,18 @@
+package gollm
+
+import (
+	"context"
+	"io"
+)
+
+// cancelCloser releases the context of a streamed body when it is closed
+type cancelCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelCloser) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
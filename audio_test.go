@@ -0,0 +1,43 @@
+package gollm
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/tts"
+)
+
+// fakeSynthesizer streams the request text and keeps the request context
+type fakeSynthesizer struct {
+	ctx context.Context
+}
+
+func (f *fakeSynthesizer) Synthesize(ctx context.Context, req *tts.Request) (*tts.Response, error) {
+	f.ctx = ctx
+	return &tts.Response{Audio: io.NopCloser(strings.NewReader(req.Text)), Format: tts.FormatPCM}, nil
+}
+
+func (f *fakeSynthesizer) GetSynthesizerName() string { return "fake" }
+
+func TestClient_Synthesize(t *testing.T) {
+	syn := &fakeSynthesizer{}
+	client := NewClient(mock.New(), WithSynthesizer(syn))
+
+	resp, err := client.Synthesize(context.Background(), &tts.Request{Text: "hello"})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if syn.ctx.Err() != nil {
+		t.Fatal("Synthesize() cancelled the stream before it was read")
+	}
+	if audio, _ := io.ReadAll(resp.Audio); string(audio) != "hello" {
+		t.Errorf("Audio = %q", audio)
+	}
+	resp.Audio.Close()
+	if syn.ctx.Err() == nil {
+		t.Error("Close() did not release the stream context")
+	}
+}
@@ -0,0 +1,253 @@
+// Package audit records full Generate requests and responses to a sink, e.g.
+// a JSONL file, for teams that must retain LLM interactions. Records are
+// redacted before they are written.
+package audit
+
+import (
+	"context"
+	"maps"
+	"regexp"
+	"strings"
+	"time"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// Redacted replaces redacted text
+const Redacted = "[REDACTED]"
+
+// Record represents an audited call
+type Record struct {
+	Time      time.Time           `json:"time"`
+	Operation gollm.Operation     `json:"operation"`
+	Latency   time.Duration       `json:"latency"`
+	Request   *generator.Request  `json:"request"`
+	Response  *generator.Response `json:"response,omitempty"` // For streams, assembled from the chunks
+	Error     string              `json:"error,omitempty"`
+}
+
+// Sink receives audit records
+type Sink interface {
+	Write(ctx context.Context, r Record) error
+}
+
+// secretPatterns match common API key and token formats
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`),                 // OpenAI and Anthropic keys
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),                    // AWS access key IDs
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),               // Google API keys
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),          // GitHub tokens
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{16,}=*`), // Bearer tokens
+}
+
+// secretParams are substrings of provider param names holding credentials
+var secretParams = []string{"key", "token", "secret", "password", "authorization"}
+
+// Auditor writes a record of every call it wraps to its sink
+type Auditor struct {
+	sink          Sink
+	redactContent bool
+	redactors     []func(*Record)
+	onError       func(error)
+}
+
+// Option is a function that configures an Auditor
+type Option func(*Auditor)
+
+// WithContentRedaction replaces the content of messages, responses and tool
+// call arguments, keeping only roles, tool names and metadata
+func WithContentRedaction() Option {
+	return func(a *Auditor) {
+		a.redactContent = true
+	}
+}
+
+// WithRedactor adds a function redacting records, run after the builtin
+// redaction on a copy the caller's request and response do not share
+func WithRedactor(f func(*Record)) Option {
+	return func(a *Auditor) {
+		a.redactors = append(a.redactors, f)
+	}
+}
+
+// WithErrorHandler sets the function receiving sink errors, which are
+// otherwise dropped; calls never fail because of auditing
+func WithErrorHandler(f func(error)) Option {
+	return func(a *Auditor) {
+		a.onError = f
+	}
+}
+
+// New creates a new auditor writing to sink. API keys and tokens found in
+// message content and provider params are always redacted.
+func New(sink Sink, opts ...Option) *Auditor {
+	a := &Auditor{sink: sink}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Option attaches the auditor to a client, through its middleware
+func (a *Auditor) Option() gollm.Option {
+	return func(c *gollm.Client) {
+		gollm.WithMiddleware(a.Middleware)(c)
+		gollm.WithStreamMiddleware(a.StreamMiddleware)(c)
+	}
+}
+
+// Middleware audits Generate calls
+func (a *Auditor) Middleware(next gollm.GenerateFunc) gollm.GenerateFunc {
+	return func(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+		start := time.Now()
+		resp, err := next(ctx, req)
+		a.write(ctx, Record{Time: start, Operation: gollm.OpGenerate, Latency: time.Since(start)}, req, resp, err)
+		return resp, err
+	}
+}
+
+// StreamMiddleware audits GenerateStream calls once their stream closes
+func (a *Auditor) StreamMiddleware(next gollm.GenerateStreamFunc) gollm.GenerateStreamFunc {
+	return func(ctx context.Context, req *generator.Request) (<-chan *generator.Response, error) {
+		start := time.Now()
+		r := Record{Time: start, Operation: gollm.OpGenerateStream}
+		stream, err := next(ctx, req)
+		if err != nil {
+			r.Latency = time.Since(start)
+			a.write(ctx, r, req, nil, err)
+			return nil, err
+		}
+
+		out := make(chan *generator.Response)
+		go func() {
+			defer close(out)
+			var content, reasoning strings.Builder
+			resp := &generator.Response{}
+			var streamErr error
+			defer func() {
+				resp.Content, resp.Reasoning = content.String(), reasoning.String()
+				r.Latency = time.Since(start)
+				a.write(ctx, r, req, resp, streamErr)
+			}()
+			for chunk := range stream {
+				content.WriteString(chunk.Content)
+				reasoning.WriteString(chunk.Reasoning)
+				resp.ID, resp.Model = first(resp.ID, chunk.ID), first(resp.Model, chunk.Model)
+				resp.ToolCalls = append(resp.ToolCalls, chunk.ToolCalls...)
+				if chunk.FinishReason != "" {
+					resp.FinishReason = chunk.FinishReason
+				}
+				if chunk.Usage != (generator.TokenUsage{}) {
+					resp.Usage, resp.Cost = chunk.Usage, chunk.Cost
+				}
+				if chunk.Err != nil {
+					streamErr = chunk.Err
+				}
+				select {
+				case out <- chunk:
+				case <-ctx.Done():
+					streamErr = ctx.Err()
+					return
+				}
+			}
+		}()
+		return out, nil
+	}
+}
+
+func (a *Auditor) write(ctx context.Context, r Record, req *generator.Request, resp *generator.Response, err error) {
+	r.Request = copyRequest(req)
+	if resp != nil {
+		c := *resp
+		c.ToolCalls = append([]generator.ToolCall(nil), resp.ToolCalls...)
+		c.Metadata = maps.Clone(resp.Metadata)
+		c.Err = nil
+		r.Response = &c
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	a.redact(&r)
+	for _, f := range a.redactors {
+		f(&r)
+	}
+	// The call may be over, but the record must still be written
+	if werr := a.sink.Write(context.WithoutCancel(ctx), r); werr != nil && a.onError != nil {
+		a.onError(werr)
+	}
+}
+
+func (a *Auditor) redact(r *Record) {
+	text := redactSecrets
+	if a.redactContent {
+		text = func(s string) string {
+			if s == "" {
+				return s
+			}
+			return Redacted
+		}
+	}
+
+	for i, m := range r.Request.Messages {
+		r.Request.Messages[i].Content = text(m.Content)
+		r.Request.Messages[i].ToolCalls = redactCalls(m.ToolCalls, text)
+	}
+	for k, v := range r.Request.ProviderParams {
+		if isSecretParam(k) {
+			r.Request.ProviderParams[k] = Redacted
+		} else if s, ok := v.(string); ok {
+			r.Request.ProviderParams[k] = redactSecrets(s)
+		}
+	}
+	if r.Response != nil {
+		r.Response.Content = text(r.Response.Content)
+		r.Response.Reasoning = text(r.Response.Reasoning)
+		r.Response.ToolCalls = redactCalls(r.Response.ToolCalls, text)
+	}
+	r.Error = redactSecrets(r.Error)
+}
+
+func redactCalls(calls []generator.ToolCall, text func(string) string) []generator.ToolCall {
+	for i, tc := range calls {
+		calls[i].Arguments = text(tc.Arguments)
+	}
+	return calls
+}
+
+func redactSecrets(s string) string {
+	for _, p := range secretPatterns {
+		s = p.ReplaceAllString(s, Redacted)
+	}
+	return s
+}
+
+func isSecretParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range secretParams {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyRequest copies req deeply enough for redaction not to reach it
+func copyRequest(req *generator.Request) *generator.Request {
+	c := *req
+	c.Messages = make([]generator.Message, len(req.Messages))
+	for i, m := range req.Messages {
+		m.ToolCalls = append([]generator.ToolCall(nil), m.ToolCalls...)
+		c.Messages[i] = m
+	}
+	c.ProviderParams = maps.Clone(req.ProviderParams)
+	c.Metadata = maps.Clone(req.Metadata)
+	return &c
+}
+
+func first(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func records(t *testing.T, b []byte) []Record {
+	t.Helper()
+	var rs []Record
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+		rs = append(rs, r)
+	}
+	return rs
+}
+
+func TestAuditor(t *testing.T) {
+	var buf bytes.Buffer
+	client := gollm.NewClient(mock.New(), New(NewWriter(&buf)).Option())
+
+	req := &generator.Request{
+		Model:          "gpt-4o",
+		Messages:       []generator.Message{{Role: generator.USER, Content: "my key is sk-abcdefghijklmnopqrstuvwx, thanks"}},
+		ProviderParams: map[string]interface{}{"api_key": "hunter2", "seed": "7"},
+	}
+	if _, err := client.Generate(context.Background(), req); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	stream, _ := client.GenerateStream(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "stream me"}}})
+	for range stream {
+	}
+
+	rs := records(t, buf.Bytes())
+	if len(rs) != 2 {
+		t.Fatalf("got %d records, want 2", len(rs))
+	}
+	r := rs[0]
+	if r.Operation != gollm.OpGenerate || r.Request.Model != "gpt-4o" {
+		t.Errorf("record = %+v", r)
+	}
+	if want := "my key is [REDACTED], thanks"; r.Request.Messages[0].Content != want || r.Response.Content != want {
+		t.Errorf("record content = %q, %q, want %q", r.Request.Messages[0].Content, r.Response.Content, want)
+	}
+	if r.Request.ProviderParams["api_key"] != Redacted || r.Request.ProviderParams["seed"] != "7" {
+		t.Errorf("record params = %v", r.Request.ProviderParams)
+	}
+	if req.ProviderParams["api_key"] != "hunter2" || strings.Contains(req.Messages[0].Content, Redacted) {
+		t.Error("redaction changed the caller's request")
+	}
+	if rs[1].Operation != gollm.OpGenerateStream || rs[1].Response.Content != "stream me" {
+		t.Errorf("stream record = %+v", rs[1])
+	}
+}
+
+func TestAuditor_ContentRedaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFile(path)
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+	a := New(sink, WithContentRedaction(), WithRedactor(func(r *Record) { r.Request.User = "" }))
+	client := gollm.NewClient(mock.New(), a.Option())
+	client.Generate(context.Background(), &generator.Request{User: "alice", Messages: []generator.Message{{Role: generator.USER, Content: "private"}}})
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	b, _ := os.ReadFile(path)
+	r := records(t, b)[0]
+	if r.Request.Messages[0].Content != Redacted || r.Response.Content != Redacted || r.Request.User != "" {
+		t.Errorf("record = %+v, want content and user redacted", r)
+	}
+}
@@ -0,0 +1,138 @@
+// Package auth authorizes the HTTP requests of providers: static API keys,
+// bearer tokens refreshed on expiry, AWS Signature Version 4 and Google
+// Application Default Credentials. Providers apply the generator.Config Auth
+// with Transport, so enterprise deployments (Bedrock, Vertex AI, Azure AD)
+// share one credential handling.
+package auth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Credentials authorize HTTP requests. Implementations are safe for
+// concurrent use.
+type Credentials interface {
+	// Authorize sets the credentials of req, e.g. its Authorization header.
+	// The body of req, if any, can be read again through req.GetBody.
+	Authorize(req *http.Request) error
+}
+
+// CredentialsFunc is a function implementing Credentials
+type CredentialsFunc func(req *http.Request) error
+
+// Authorize calls f
+func (f CredentialsFunc) Authorize(req *http.Request) error {
+	return f(req)
+}
+
+// APIKey sets the header name to key, e.g. "x-api-key" or "api-key"
+func APIKey(name, key string) Credentials {
+	return CredentialsFunc(func(req *http.Request) error {
+		req.Header.Set(name, key)
+		return nil
+	})
+}
+
+// Bearer sets token as the bearer token of requests
+func Bearer(token string) Credentials {
+	return CredentialsFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// Token represents an access token
+type Token struct {
+	Value string
+	// Expiry is when the token stops being valid; zero for never
+	Expiry time.Time
+}
+
+// expiryMargin is how long before its expiry a token is refreshed, so it
+// does not expire in flight
+const expiryMargin = time.Minute
+
+func (t Token) valid(now time.Time) bool {
+	return t.Value != "" && (t.Expiry.IsZero() || now.Add(expiryMargin).Before(t.Expiry))
+}
+
+// RefreshingBearer sets the token returned by refresh as the bearer token of
+// requests. The token is reused until a minute before its expiry; refresh is
+// called with the context of the request needing a new one, one call at a
+// time.
+func RefreshingBearer(refresh func(ctx context.Context) (Token, error)) Credentials {
+	return &refreshing{refresh: refresh}
+}
+
+type refreshing struct {
+	refresh func(ctx context.Context) (Token, error)
+
+	mu    sync.Mutex
+	token Token
+}
+
+func (r *refreshing) Authorize(req *http.Request) error {
+	token, err := r.get(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Value)
+	return nil
+}
+
+func (r *refreshing) get(ctx context.Context) (Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.token.valid(time.Now()) {
+		return r.token, nil
+	}
+	token, err := r.refresh(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+	r.token = token
+	return token, nil
+}
+
+// Transport returns a RoundTripper sending requests through base, or
+// http.DefaultTransport when nil, authorized with creds. Request bodies
+// that cannot be read again are buffered first, for signatures covering the
+// body.
+func Transport(base http.RoundTripper, creds Credentials) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, creds: creds}
+}
+
+type transport struct {
+	base  http.RoundTripper
+	creds Credentials
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	if err := t.creds.Authorize(r); err != nil {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		return nil, err
+	}
+	return t.base.RoundTrip(r)
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRefreshingBearer(t *testing.T) {
+	refreshes := 0
+	expiry := time.Now().Add(time.Hour)
+	creds := RefreshingBearer(func(context.Context) (Token, error) {
+		refreshes++
+		if refreshes == 3 {
+			return Token{}, errors.New("refresh failed")
+		}
+		return Token{Value: "token-" + string(rune('0'+refreshes)), Expiry: expiry}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	for range 2 {
+		if err := creds.Authorize(req); err != nil || req.Header.Get("Authorization") != "Bearer token-1" {
+			t.Fatalf("Authorize() = %v with %q, want the cached token", err, req.Header.Get("Authorization"))
+		}
+	}
+
+	// Tokens about to expire are refreshed
+	expiry = time.Now().Add(30 * time.Second)
+	creds.(*refreshing).token.Expiry = expiry
+	if err := creds.Authorize(req); err != nil || req.Header.Get("Authorization") != "Bearer token-2" {
+		t.Errorf("Authorize() = %v with %q, want a refreshed token", err, req.Header.Get("Authorization"))
+	}
+	if err := creds.Authorize(req); err == nil {
+		t.Error("Authorize() error = nil, want the refresh error")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got *http.Request
+	var body string
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	var hashed string
+	creds := CredentialsFunc(func(r *http.Request) error {
+		b, err := r.GetBody()
+		if err != nil {
+			return err
+		}
+		data, _ := io.ReadAll(b)
+		hashed = string(data)
+		return APIKey("x-api-key", "k").Authorize(r)
+	})
+
+	// A body without GetBody is buffered so creds can read it
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com", io.NopCloser(strings.NewReader("payload")))
+	if _, err := Transport(base, creds).RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if got.Header.Get("x-api-key") != "k" || hashed != "payload" || body != "payload" {
+		t.Errorf("RoundTrip() sent key %q, creds read %q, body %q", got.Header.Get("x-api-key"), hashed, body)
+	}
+	if req.Header.Get("x-api-key") != "" {
+		t.Error("RoundTrip() modified the caller's request")
+	}
+
+	failing := CredentialsFunc(func(*http.Request) error { return errors.New("no credentials") })
+	if _, err := Transport(base, failing).RoundTrip(httptest.NewRequest(http.MethodGet, "https://example.com", nil)); err == nil {
+		t.Error("RoundTrip() error = nil, want the credentials error")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
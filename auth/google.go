@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/parikxxit/go-llm/llmerrors"
+	llmtransport "github.com/parikxxit/go-llm/transport"
+)
+
+const (
+	// CloudPlatformScope is the OAuth scope of Google Cloud APIs, e.g.
+	// Vertex AI, requested when no scope is given
+	CloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+// googleFile represents the fields of a credentials file used here
+type googleFile struct {
+	Type string `json:"type"`
+	// service_account
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+	// authorized_user, as written by gcloud auth application-default login
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// GoogleDefault returns the Application Default Credentials for scopes,
+// CloudPlatformScope when none: the credentials file named by
+// GOOGLE_APPLICATION_CREDENTIALS, else the one of gcloud auth
+// application-default login, else the service account of the metadata
+// server on Google Cloud. Files of service accounts and authorized users are
+// supported.
+func GoogleDefault(scopes ...string) (Credentials, error) {
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		return googleFromFile(path, scopes)
+	}
+	if path := gcloudCredentialsPath(); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return googleFromFile(path, scopes)
+		}
+	}
+	return GoogleMetadata(scopes...), nil
+}
+
+// GoogleCredentialsJSON returns the credentials of the contents of a
+// credentials file, for scopes
+func GoogleCredentialsJSON(data []byte, scopes ...string) (Credentials, error) {
+	if len(scopes) == 0 {
+		scopes = []string{CloudPlatformScope}
+	}
+	var f googleFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("auth: decoding Google credentials: %w", err)
+	}
+	switch f.Type {
+	case "service_account":
+		key, err := parseRSAKey(f.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		if f.TokenURI == "" {
+			f.TokenURI = googleTokenURL
+		}
+		return RefreshingBearer(func(ctx context.Context) (Token, error) {
+			assertion, err := f.assertion(key, scopes, time.Now())
+			if err != nil {
+				return Token{}, err
+			}
+			return googleToken(ctx, f.TokenURI, url.Values{
+				"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+				"assertion":  {assertion},
+			})
+		}), nil
+	case "authorized_user":
+		if f.TokenURI == "" {
+			f.TokenURI = googleTokenURL
+		}
+		return RefreshingBearer(func(ctx context.Context) (Token, error) {
+			return googleToken(ctx, f.TokenURI, url.Values{
+				"grant_type":    {"refresh_token"},
+				"client_id":     {f.ClientID},
+				"client_secret": {f.ClientSecret},
+				"refresh_token": {f.RefreshToken},
+			})
+		}), nil
+	default:
+		return nil, fmt.Errorf("auth: unsupported Google credentials type %q", f.Type)
+	}
+}
+
+// GoogleMetadata returns the credentials of the service account attached to
+// the Google Cloud resource running the program, from its metadata server
+// (GCE_METADATA_HOST when set)
+func GoogleMetadata(scopes ...string) Credentials {
+	if len(scopes) == 0 {
+		scopes = []string{CloudPlatformScope}
+	}
+	return RefreshingBearer(func(ctx context.Context) (Token, error) {
+		host := os.Getenv("GCE_METADATA_HOST")
+		if host == "" {
+			host = "metadata.google.internal"
+		}
+		endpoint := "http://" + host + "/computeMetadata/v1/instance/service-accounts/default/token?scopes=" + url.QueryEscape(strings.Join(scopes, ","))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return Token{}, err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		return doToken(req)
+	})
+}
+
+func googleFromFile(path string, scopes []string) (Credentials, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+	return GoogleCredentialsJSON(data, scopes...)
+}
+
+func gcloudCredentialsPath() string {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return filepath.Join(dir, "gcloud", "application_default_credentials.json")
+		}
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+// assertion returns the signed JWT exchanged for a token of a service
+// account
+func (f googleFile) assertion(key *rsa.PrivateKey, scopes []string, now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": f.PrivateKeyID})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   f.ClientEmail,
+		"scope": strings.Join(scopes, " "),
+		"aud":   f.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("auth: signing assertion: %w", err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+func parseRSAKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("auth: private_key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("auth: parsing private_key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("auth: private_key is not an RSA key")
+	}
+	return key, nil
+}
+
+func googleToken(ctx context.Context, tokenURL string, form url.Values) (Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doToken(req)
+}
+
+func doToken(req *http.Request) (Token, error) {
+	res, err := llmtransport.DefaultClient().Do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("auth: fetching Google token: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return Token{}, llmerrors.FromResponse("google auth", res)
+	}
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return Token{}, fmt.Errorf("auth: decoding Google token: %w", err)
+	}
+	if out.AccessToken == "" {
+		return Token{}, errors.New("auth: Google token response without access_token")
+	}
+	t := Token{Value: out.AccessToken}
+	if out.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(out.ExpiresIn) * time.Second)
+	}
+	return t, nil
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoogleCredentialsJSON_ServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Errorf("grant_type = %q", r.Form.Get("grant_type"))
+		}
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if len(parts) != 3 {
+			t.Fatalf("assertion = %q, want a JWT", r.Form.Get("assertion"))
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+			t.Errorf("assertion signature: %v", err)
+		}
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var c map[string]any
+		json.Unmarshal(claims, &c)
+		if c["iss"] != "sa@project.iam.gserviceaccount.com" || c["scope"] != CloudPlatformScope || c["aud"] != "http://"+r.Host+"/token" {
+			t.Errorf("assertion claims = %v", c)
+		}
+		io.WriteString(w, `{"access_token":"sa-token","expires_in":3600,"token_type":"Bearer"}`)
+	}))
+	defer srv.Close()
+
+	file, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "sa@project.iam.gserviceaccount.com",
+		"private_key":    string(pemKey),
+		"private_key_id": "kid",
+		"token_uri":      srv.URL + "/token",
+	})
+	creds, err := GoogleCredentialsJSON(file)
+	if err != nil {
+		t.Fatalf("GoogleCredentialsJSON() error = %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "https://aiplatform.googleapis.com", nil)
+	if err := creds.Authorize(req); err != nil || req.Header.Get("Authorization") != "Bearer sa-token" {
+		t.Errorf("Authorize() = %v with %q", err, req.Header.Get("Authorization"))
+	}
+}
+
+func TestGoogleDefault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			r.ParseForm()
+			if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh" || r.Form.Get("client_id") != "id" {
+				t.Errorf("token request form = %v", r.Form)
+			}
+			io.WriteString(w, `{"access_token":"user-token","expires_in":3600}`)
+		case "/computeMetadata/v1/instance/service-accounts/default/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" || r.URL.Query().Get("scopes") != "a,b" {
+				t.Errorf("metadata request with headers %v, query %v", r.Header, r.URL.Query())
+			}
+			io.WriteString(w, `{"access_token":"gce-token","expires_in":3600}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "adc.json")
+	os.WriteFile(path, []byte(`{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"refresh","token_uri":"`+srv.URL+`/token"}`), 0o600)
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+	creds, err := GoogleDefault()
+	if err != nil {
+		t.Fatalf("GoogleDefault() error = %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "https://aiplatform.googleapis.com", nil)
+	if err := creds.Authorize(req); err != nil || req.Header.Get("Authorization") != "Bearer user-token" {
+		t.Errorf("Authorize(authorized_user) = %v with %q", err, req.Header.Get("Authorization"))
+	}
+
+	// Without a file, the metadata server is used
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(srv.URL, "http://"))
+	if creds, err = GoogleDefault("a", "b"); err != nil {
+		t.Fatalf("GoogleDefault() error = %v", err)
+	}
+	if err := creds.Authorize(req); err != nil || req.Header.Get("Authorization") != "Bearer gce-token" {
+		t.Errorf("Authorize(metadata) = %v with %q", err, req.Header.Get("Authorization"))
+	}
+
+	if _, err := GoogleCredentialsJSON([]byte(`{"type":"external_account"}`)); err == nil {
+		t.Error("GoogleCredentialsJSON(external_account) error = nil")
+	}
+}
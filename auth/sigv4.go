@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AWSCredentials represents the credentials of an AWS principal
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials, e.g. of an assumed role
+	SessionToken string
+}
+
+// AWSCredentialsProvider returns the credentials signing a request, called
+// for every request so rotated credentials are picked up
+type AWSCredentialsProvider func(ctx context.Context) (AWSCredentials, error)
+
+// StaticAWS returns creds for every request
+func StaticAWS(creds AWSCredentials) AWSCredentialsProvider {
+	return func(context.Context) (AWSCredentials, error) {
+		return creds, nil
+	}
+}
+
+// AWSFromEnv reads the credentials of the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables
+func AWSFromEnv() AWSCredentialsProvider {
+	return func(context.Context) (AWSCredentials, error) {
+		creds := AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return AWSCredentials{}, errors.New("auth: AWS_ACCESS_KEY_ID or AWS_SECRET_ACCESS_KEY not set")
+		}
+		return creds, nil
+	}
+}
+
+// SigV4 signs requests with AWS Signature Version 4 for service in region,
+// e.g. "bedrock" in "us-east-1"
+func SigV4(region, service string, creds AWSCredentialsProvider) Credentials {
+	return &sigV4{region: region, service: service, creds: creds, now: time.Now}
+}
+
+type sigV4 struct {
+	region  string
+	service string
+	creds   AWSCredentialsProvider
+	now     func() time.Time
+}
+
+func (s *sigV4) Authorize(req *http.Request) error {
+	creds, err := s.creds(req.Context())
+	if err != nil {
+		return err
+	}
+	payload, err := payloadHash(req)
+	if err != nil {
+		return fmt.Errorf("auth: hashing body: %w", err)
+	}
+
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	if s.service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payload)
+	}
+
+	headers, signed := canonicalHeaders(req)
+	canonical := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(req),
+		canonicalQuery(req),
+		headers,
+		signed,
+		payload,
+	}, "\n")
+	scope := date + "/" + s.region + "/" + s.service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonical))
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	for _, part := range []string{s.region, s.service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", creds.AccessKeyID, scope, signed, signature))
+	return nil
+}
+
+// canonicalURI returns the path of req with each segment escaped, twice for
+// every service but S3
+func (s *sigV4) canonicalURI(req *http.Request) string {
+	if req.URL.Path == "" {
+		return "/"
+	}
+	segments := strings.Split(req.URL.Path, "/")
+	for i, seg := range segments {
+		segments[i] = escape(seg)
+		if s.service != "s3" {
+			segments[i] = escape(segments[i])
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(req *http.Request) string {
+	var pairs []string
+	for k, vs := range req.URL.Query() {
+		for _, v := range vs {
+			pairs = append(pairs, escape(k)+"="+escape(v))
+		}
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// canonicalHeaders returns the canonical headers of req and the names of
+// the headers signed: host, content-type and the x-amz- ones
+func canonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for k, vs := range req.Header {
+		name := strings.ToLower(k)
+		if name != "content-type" && !strings.HasPrefix(name, "x-amz-") {
+			continue
+		}
+		trimmed := make([]string, len(vs))
+		for i, v := range vs {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		values[name] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + values[name] + "\n")
+	}
+	return b.String(), strings.Join(names, ";")
+}
+
+func payloadHash(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return hexSHA256(nil), nil
+	}
+	if req.GetBody == nil {
+		return "", errors.New("body cannot be read again; send requests through Transport")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// escape percent-encodes s as RFC 3986, leaving only unreserved characters
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var exampleAWS = AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+func TestSigV4(t *testing.T) {
+	// get-vanilla of the AWS Signature Version 4 test suite
+	s := SigV4("us-east-1", "service", StaticAWS(exampleAWS)).(*sigV4)
+	s.now = func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) }
+	req := httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	req.Header = http.Header{}
+	if err := s.Authorize(req); err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %q", got)
+	}
+}
+
+func TestSigV4_SessionAndBody(t *testing.T) {
+	creds := exampleAWS
+	creds.SessionToken = "session"
+	s := SigV4("us-west-2", "bedrock", StaticAWS(creds))
+
+	var auth, token string
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		auth, token = r.Header.Get("Authorization"), r.Header.Get("X-Amz-Security-Token")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	req, _ := http.NewRequest(http.MethodPost, "https://bedrock-runtime.us-west-2.amazonaws.com/model/anthropic.claude-v2:1/invoke", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	if _, err := Transport(base, s).RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if token != "session" || !strings.Contains(auth, "/us-west-2/bedrock/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token, Signature=") {
+		t.Errorf("Authorization = %q with token %q", auth, token)
+	}
+
+	// Path segments are escaped twice, but for S3
+	if got := s.(*sigV4).canonicalURI(req); got != "/model/anthropic.claude-v2%253A1/invoke" {
+		t.Errorf("canonicalURI() = %q", got)
+	}
+}
+
+func TestAWSFromEnv(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	if _, err := AWSFromEnv()(context.Background()); err == nil {
+		t.Error("AWSFromEnv() error = nil without credentials")
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+	if c, err := AWSFromEnv()(context.Background()); err != nil || c != (AWSCredentials{"id", "secret", "session"}) {
+		t.Errorf("AWSFromEnv() = %+v, %v", c, err)
+	}
+}
@@ -0,0 +1,130 @@
+package gollm
+
+import (
+	"context"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// Backpressure represents what a stream does when its consumer falls behind
+// and its buffer is full
+type Backpressure string
+
+const (
+	// BackpressureBlock stops reading from the provider until the consumer
+	// catches up, holding the connection open; nothing is lost
+	BackpressureBlock Backpressure = "block"
+	// BackpressureDrop drops chunks of only content or reasoning text. Chunks
+	// with tool calls, a finish reason, usage or an error are always
+	// delivered, so the stream ends as it would have.
+	BackpressureDrop Backpressure = "drop"
+	// BackpressureCoalesce merges chunks into the last one buffered: the
+	// consumer receives fewer, larger chunks with nothing lost, while the
+	// provider is read at full speed. Errors are never merged, and merged
+	// chunks have no Raw payload.
+	BackpressureCoalesce Backpressure = "coalesce"
+)
+
+// WithStreamBuffer buffers up to size chunks between the provider and the
+// consumer of streams, handling a full buffer by policy. Without it streams
+// are unbuffered and block: the provider is read as fast as the consumer
+// receives. Whatever the policy, chunks keep their order, the chunk with Err
+// set is the last, and streams close once ended.
+func WithStreamBuffer(size int, policy Backpressure) Option {
+	return func(c *Client) {
+		c.streamBuffer, c.backpressure = size, policy
+	}
+}
+
+// bufferStream forwards stream through a buffer of c.streamBuffer chunks
+func (c *Client) bufferStream(ctx context.Context, stream <-chan *generator.Response) <-chan *generator.Response {
+	size := c.streamBuffer
+	block := c.backpressure == "" || c.backpressure == BackpressureBlock
+	if size == 0 && block {
+		return stream
+	}
+	size = max(size, 1)
+
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+		var queue []*generator.Response
+		in := stream
+		for in != nil || len(queue) > 0 {
+			var send chan<- *generator.Response
+			var head *generator.Response
+			if len(queue) > 0 {
+				send, head = out, queue[0]
+			}
+			recv := in
+			if len(queue) >= size && block {
+				recv = nil
+			}
+			select {
+			case chunk, ok := <-recv:
+				if !ok {
+					in = nil
+					continue
+				}
+				queue = c.enqueue(queue, chunk, size)
+			case send <- head:
+				queue[0] = nil
+				queue = queue[1:]
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// enqueue adds chunk to queue, applying the backpressure policy when the
+// queue holds size chunks
+func (c *Client) enqueue(queue []*generator.Response, chunk *generator.Response, size int) []*generator.Response {
+	if len(queue) < size {
+		return append(queue, chunk)
+	}
+	switch last := queue[len(queue)-1]; c.backpressure {
+	case BackpressureDrop:
+		if len(chunk.ToolCalls) == 0 && chunk.FinishReason == "" && chunk.Usage == (generator.TokenUsage{}) && chunk.Err == nil {
+			return queue
+		}
+	case BackpressureCoalesce:
+		if chunk.Err == nil && last.Err == nil {
+			queue[len(queue)-1] = coalesce(last, chunk)
+			return queue
+		}
+	}
+	return append(queue, chunk)
+}
+
+// coalesce returns a chunk of the deltas of a followed by those of b
+func coalesce(a, b *generator.Response) *generator.Response {
+	merged := *a
+	merged.Content += b.Content
+	merged.Reasoning += b.Reasoning
+	merged.ToolCalls = append(append([]generator.ToolCall(nil), a.ToolCalls...), b.ToolCalls...)
+	merged.Raw = nil
+	if b.ID != "" {
+		merged.ID = b.ID
+	}
+	if b.Model != "" {
+		merged.Model = b.Model
+	}
+	if b.FinishReason != "" {
+		merged.FinishReason = b.FinishReason
+	}
+	if b.Usage != (generator.TokenUsage{}) {
+		merged.Usage, merged.Cost, merged.StreamStats = b.Usage, b.Cost, b.StreamStats
+	}
+	if len(b.Metadata) > 0 {
+		merged.Metadata = make(map[string]string, len(a.Metadata)+len(b.Metadata))
+		for k, v := range a.Metadata {
+			merged.Metadata[k] = v
+		}
+		for k, v := range b.Metadata {
+			merged.Metadata[k] = v
+		}
+	}
+	return &merged
+}
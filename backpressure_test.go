@@ -0,0 +1,63 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_WithStreamBuffer(t *testing.T) {
+	reply := func(streamErr error) mock.Reply {
+		var chunks []*generator.Response
+		for _, s := range strings.Split("abcdefghij", "") {
+			chunks = append(chunks, &generator.Response{Content: s})
+		}
+		last := &generator.Response{FinishReason: "stop", Usage: generator.TokenUsage{CompletionTokens: 10}}
+		if streamErr != nil {
+			last = &generator.Response{Content: "k"}
+		}
+		return mock.Reply{Chunks: append(chunks, last), StreamErr: streamErr}
+	}
+	tests := []struct {
+		name      string
+		policy    Backpressure
+		streamErr error
+		want      string
+		maxChunks int
+	}{
+		{"block", BackpressureBlock, nil, "abcdefghij", 11},
+		{"drop", BackpressureDrop, nil, "a", 2},
+		{"coalesce", BackpressureCoalesce, nil, "abcdefghij", 2},
+		{"coalesce error", BackpressureCoalesce, errors.New("reset"), "abcdefghijk", 3},
+	}
+	for _, tt := range tests {
+		client := NewClient(mock.New().Enqueue(reply(tt.streamErr)), WithStreamBuffer(1, tt.policy))
+		stream, err := client.GenerateStream(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+		if err != nil {
+			t.Fatalf("%s: GenerateStream() error = %v", tt.name, err)
+		}
+		// A slow consumer, letting the buffer fill
+		time.Sleep(50 * time.Millisecond)
+		var content strings.Builder
+		var chunks []*generator.Response
+		for chunk := range stream {
+			content.WriteString(chunk.Content)
+			chunks = append(chunks, chunk)
+		}
+		last := chunks[len(chunks)-1]
+		if content.String() != tt.want || len(chunks) > tt.maxChunks {
+			t.Errorf("%s: received %q in %d chunks, want %q in at most %d", tt.name, content.String(), len(chunks), tt.want, tt.maxChunks)
+		}
+		if tt.streamErr == nil && (last.FinishReason != "stop" || last.Usage.CompletionTokens != 10) {
+			t.Errorf("%s: last chunk = %+v, want the finish reason and usage", tt.name, last)
+		}
+		if tt.streamErr != nil && !errors.Is(last.Err, tt.streamErr) {
+			t.Errorf("%s: last chunk error = %v, want %v", tt.name, last.Err, tt.streamErr)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package balancer spreads generation traffic across several equivalent
+// generators, e.g. API keys, regions or providers serving the same model.
+package balancer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/models"
+)
+
+// Strategy represents how a Balancer picks the member serving a request
+type Strategy string
+
+const (
+	// RoundRobin cycles through the members in order
+	RoundRobin Strategy = "round-robin"
+	// Weighted cycles through the members in proportion to their Weight,
+	// interleaving them smoothly
+	Weighted Strategy = "weighted"
+	// LeastLatency picks the member with the lowest moving average latency,
+	// trying members without measurements first
+	LeastLatency Strategy = "least-latency"
+	// LeastCost picks the member whose model is cheapest per token according
+	// to the models pricing registry; members of unknown pricing come last
+	LeastCost Strategy = "least-cost"
+)
+
+const (
+	// latencyDecay is the weight of the previous average in the latency
+	// moving average
+	latencyDecay = 0.8
+	// errorLatency is the latency observed for a failed request, steering
+	// LeastLatency away from failing members
+	errorLatency = 5 * time.Second
+)
+
+// Member represents a generator of a Balancer
+type Member struct {
+	Generator generator.Generator
+	// Weight is the share of traffic under Weighted; 1 when not positive
+	Weight int
+	// Model replaces Request.Model for this member, for providers naming the
+	// same model differently
+	Model string
+}
+
+type member struct {
+	Member
+	current int     // Smooth weighted round-robin credit
+	latency float64 // Moving average in seconds; 0 until measured
+}
+
+// Balancer is a generator.Generator sending each request to one of its
+// members, picked by its strategy. Ties are broken in round-robin order.
+type Balancer struct {
+	strategy Strategy
+
+	mu      sync.Mutex
+	members []*member
+	next    int
+}
+
+// New creates a new balancer with strategy over members. Pass it to
+// gollm.NewClient as the generator, with fallbacks as usual.
+func New(strategy Strategy, members ...Member) (*Balancer, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("balancer requires at least one member")
+	}
+	switch strategy {
+	case RoundRobin, Weighted, LeastLatency, LeastCost:
+	default:
+		return nil, fmt.Errorf("unknown balancing strategy %q", strategy)
+	}
+
+	b := &Balancer{strategy: strategy}
+	for _, m := range members {
+		if m.Generator == nil {
+			return nil, fmt.Errorf("balancer member without a generator")
+		}
+		if m.Weight <= 0 {
+			m.Weight = 1
+		}
+		b.members = append(b.members, &member{Member: m})
+	}
+	return b, nil
+}
+
+func (b *Balancer) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	m := b.pick(req)
+	start := time.Now()
+	resp, err := m.Generator.Generate(ctx, m.request(req))
+	b.observe(ctx, m, time.Since(start), err)
+	return resp, err
+}
+
+// GenerateStream measures the latency of members until their stream opens
+func (b *Balancer) GenerateStream(ctx context.Context, req *generator.Request) (<-chan *generator.Response, error) {
+	m := b.pick(req)
+	start := time.Now()
+	stream, err := m.Generator.GenerateStream(ctx, m.request(req))
+	b.observe(ctx, m, time.Since(start), err)
+	return stream, err
+}
+
+func (b *Balancer) GetName() string {
+	return "balancer"
+}
+
+// SupportsPrefill reports whether every member supports prefill
+func (b *Balancer) SupportsPrefill() bool {
+	for _, m := range b.members {
+		if s, ok := m.Generator.(generator.PrefillSupporter); !ok || !s.SupportsPrefill() {
+			return false
+		}
+	}
+	return true
+}
+
+// SupportsGrammar reports whether every member supports grammars of kind
+func (b *Balancer) SupportsGrammar(kind generator.GrammarKind) bool {
+	for _, m := range b.members {
+		if s, ok := m.Generator.(generator.GrammarSupporter); !ok || !s.SupportsGrammar(kind) {
+			return false
+		}
+	}
+	return true
+}
+
+// Members returns the generators of the balancer
+func (b *Balancer) Members() []generator.Generator {
+	gens := make([]generator.Generator, len(b.members))
+	for i, m := range b.members {
+		gens[i] = m.Generator
+	}
+	return gens
+}
+
+func (m *member) request(req *generator.Request) *generator.Request {
+	if m.Model == "" || m.Model == req.Model {
+		return req
+	}
+	r := *req
+	r.Model = m.Model
+	return &r
+}
+
+func (b *Balancer) pick(req *generator.Request) *member {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	start := b.next
+	b.next = (b.next + 1) % len(b.members)
+	switch b.strategy {
+	case Weighted:
+		return b.pickWeighted()
+	case LeastLatency:
+		return b.pickMin(start, func(m *member) float64 { return m.latency })
+	case LeastCost:
+		return b.pickMin(start, func(m *member) float64 {
+			model := m.Model
+			if model == "" {
+				model = req.Model
+			}
+			p, ok := models.PricingOf(model)
+			if !ok {
+				return math.Inf(1)
+			}
+			return p.Input + p.Output
+		})
+	}
+	return b.members[start]
+}
+
+// pickWeighted is nginx's smooth weighted round-robin
+func (b *Balancer) pickWeighted() *member {
+	var best *member
+	total := 0
+	for _, m := range b.members {
+		m.current += m.Weight
+		total += m.Weight
+		if best == nil || m.current > best.current {
+			best = m
+		}
+	}
+	best.current -= total
+	return best
+}
+
+// pickMin returns the member of least score, scanning from start
+func (b *Balancer) pickMin(start int, score func(*member) float64) *member {
+	best, bestScore := b.members[start], score(b.members[start])
+	for i := 1; i < len(b.members); i++ {
+		m := b.members[(start+i)%len(b.members)]
+		if s := score(m); s < bestScore {
+			best, bestScore = m, s
+		}
+	}
+	return best
+}
+
+func (b *Balancer) observe(ctx context.Context, m *member, latency time.Duration, err error) {
+	if err != nil {
+		if ctx.Err() != nil {
+			// The caller gave up; the member is not to blame
+			return
+		}
+		latency = max(latency, errorLatency)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if m.latency == 0 {
+		m.latency = latency.Seconds()
+		return
+	}
+	m.latency = latencyDecay*m.latency + (1-latencyDecay)*latency.Seconds()
+}
@@ -0,0 +1,88 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/models"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func named(name string) *mock.Mock {
+	m := mock.New()
+	m.Name = name
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		return &generator.Response{Content: name, Model: req.Model}, nil
+	}
+	return m
+}
+
+// sequence sends n requests through b, returning who answered each
+func sequence(t *testing.T, b *Balancer, model string, n int) string {
+	t.Helper()
+	var got []string
+	for i := 0; i < n; i++ {
+		resp, err := b.Generate(context.Background(), &generator.Request{Model: model})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		got = append(got, resp.Content)
+	}
+	return strings.Join(got, "")
+}
+
+func TestBalancer(t *testing.T) {
+	models.SetPricing("balancer-cheap", models.Pricing{Input: 1, Output: 2})
+	models.SetPricing("balancer-dear", models.Pricing{Input: 10, Output: 20})
+
+	tests := []struct {
+		strategy Strategy
+		members  []Member
+		want     string
+	}{
+		{RoundRobin, []Member{{Generator: named("a")}, {Generator: named("b")}, {Generator: named("c")}}, "abcabc"},
+		{Weighted, []Member{{Generator: named("a"), Weight: 2}, {Generator: named("b")}}, "abaaba"},
+		{LeastCost, []Member{{Generator: named("a"), Model: "balancer-dear"}, {Generator: named("b"), Model: "unknown"}, {Generator: named("c"), Model: "balancer-cheap"}}, "cccccc"},
+		{LeastCost, []Member{{Generator: named("a")}, {Generator: named("b")}}, "ababab"},
+	}
+	for _, tt := range tests {
+		b, err := New(tt.strategy, tt.members...)
+		if err != nil {
+			t.Fatalf("New(%s) error = %v", tt.strategy, err)
+		}
+		if got := sequence(t, b, "balancer-dear", 6); got != tt.want {
+			t.Errorf("%s: answered by %q, want %q", tt.strategy, got, tt.want)
+		}
+	}
+
+	if _, err := New(RoundRobin); err == nil {
+		t.Error("New() without members error = nil")
+	}
+	if _, err := New("random", Member{Generator: named("a")}); err == nil {
+		t.Error("New() of an unknown strategy error = nil")
+	}
+}
+
+func TestBalancer_LeastLatency(t *testing.T) {
+	slow, failing := named("s"), named("f")
+	slow.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		time.Sleep(20 * time.Millisecond)
+		return &generator.Response{Content: "s"}, nil
+	}
+	failing.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return nil, errors.New("down")
+	}
+	b, _ := New(LeastLatency, Member{Generator: slow}, Member{Generator: named("q")}, Member{Generator: failing})
+
+	// Each member is tried once before the fastest takes over
+	for i := 0; i < 3; i++ {
+		b.Generate(context.Background(), &generator.Request{})
+	}
+	if got := sequence(t, b, "", 3); got != "qqq" {
+		t.Errorf("answered by %q, want the quick member", got)
+	}
+}
@@ -0,0 +1,90 @@
+package gollm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+)
+
+const (
+	defaultBatchConcurrency = 8
+	defaultBatchRetryDelay  = 500 * time.Millisecond
+)
+
+// BatchOptions configures GenerateBatch
+type BatchOptions struct {
+	// Concurrency is the number of requests in flight, 8 when zero
+	Concurrency int
+	// Retries is the number of extra attempts per item failing with a
+	// retryable error, replacing the client's own retries; when zero, the
+	// client retries as it does for Generate
+	Retries int
+	// RetryDelay is the wait before the first retry, doubled on each attempt;
+	// 500ms when zero
+	RetryDelay time.Duration
+}
+
+// GenerateBatch sends requests through a bounded worker pool. Responses and
+// errors are returned in input order: for each index exactly one of them is
+// non-nil.
+func (c *Client) GenerateBatch(ctx context.Context, requests []*generator.Request, opts BatchOptions) ([]*generator.Response, []error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = defaultBatchRetryDelay
+	}
+
+	responses := make([]*generator.Response, len(requests))
+	errs := make([]error, len(requests))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(requests); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				responses[i], errs[i] = c.generateWithRetries(ctx, requests[i], opts)
+			}
+		}()
+	}
+
+	for i := range requests {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return responses, errs
+}
+
+func (c *Client) generateWithRetries(ctx context.Context, request *generator.Request, opts BatchOptions) (*generator.Response, error) {
+	if opts.Retries <= 0 {
+		return c.Generate(ctx, request)
+	}
+	delay := opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.Generate(ctx, request, CallWithRetryCount(0))
+		if err == nil || attempt >= opts.Retries || !llmerrors.Retryable(err) {
+			return resp, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+			delay *= 2
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
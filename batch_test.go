@@ -0,0 +1,69 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_GenerateBatch(t *testing.T) {
+	var calls, fives, sevens atomic.Int32
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		// Fail the first attempt of "3", every attempt of "5" and "7", the
+		// latter with an error not worth retrying
+		switch content := req.Messages[0].Content; {
+		case content == "3" && calls.Add(1) == 1:
+			return nil, llmerrors.New("mock", http.StatusServiceUnavailable, "", errors.New("overloaded"))
+		case content == "5":
+			fives.Add(1)
+			return nil, llmerrors.New("mock", http.StatusServiceUnavailable, "", errors.New("overloaded"))
+		case content == "7":
+			sevens.Add(1)
+			return nil, llmerrors.New("mock", http.StatusBadRequest, "", errors.New("bad request"))
+		default:
+			return &generator.Response{Content: content}, nil
+		}
+	}
+	client := NewClient(m)
+
+	requests := make([]*generator.Request, 10)
+	for i := range requests {
+		requests[i] = &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: strconv.Itoa(i)}}}
+	}
+
+	responses, errs := client.GenerateBatch(context.Background(), requests, BatchOptions{
+		Concurrency: 3,
+		Retries:     1,
+		RetryDelay:  time.Millisecond,
+	})
+
+	for i := range requests {
+		if i == 5 || i == 7 {
+			if errs[i] == nil {
+				t.Errorf("errs[%d] = nil, want error", i)
+			}
+			continue
+		}
+		if errs[i] != nil {
+			t.Errorf("errs[%d] = %v", i, errs[i])
+			continue
+		}
+		if responses[i].Content != strconv.Itoa(i) {
+			t.Errorf("responses[%d] = %q, want %q", i, responses[i].Content, strconv.Itoa(i))
+		}
+	}
+
+	// Attempts are the batch's, not multiplied by the client's retries
+	if fives.Load() != 2 || sevens.Load() != 1 {
+		t.Errorf("attempts of 5, 7 = %d, %d, want 2, 1", fives.Load(), sevens.Load())
+	}
+}
@@ -0,0 +1,256 @@
+// Package bench fires a workload at several targets, clients of different
+// providers or models, and reports latency percentiles, time to first
+// token, throughput, error rates and cost side by side, for choosing
+// providers with data.
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// Target represents a client benchmarked
+type Target struct {
+	Name   string
+	Client *gollm.Client
+	// Model is set on requests without one
+	Model string
+}
+
+// Workload represents the requests sent to each target
+type Workload struct {
+	// Requests are sent in turn: request i is Requests[i%len(Requests)]
+	Requests []*generator.Request
+	// N is the number of requests per target, len(Requests) when zero
+	N int
+	// Concurrency is the number of requests in flight, 1 when zero
+	Concurrency int
+	// Stream sends streaming requests, measuring the time to first token
+	Stream bool
+	// Warmup requests are sent to each target first and not measured
+	Warmup int
+}
+
+// Percentiles summarizes a distribution of durations
+type Percentiles struct {
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// Result represents the measurements of a target
+type Result struct {
+	Target   string
+	Requests int
+	Errors   int
+	// ErrorRate is Errors over Requests
+	ErrorRate float64
+	// Latency is the time to the complete response of requests that
+	// succeeded
+	Latency Percentiles
+	// TTFT is the time to the first content of streams; zero without
+	// streaming
+	TTFT Percentiles
+	// Throughput is the requests succeeding per second
+	Throughput float64
+	// TokensPerSecond is the completion tokens generated per second
+	TokensPerSecond float64
+	Usage           generator.TokenUsage
+	Cost            float64
+	Duration        time.Duration
+	// Err is the first error, for a look at why requests failed
+	Err error
+}
+
+// Report represents the results of every target, in order
+type Report struct {
+	Results []Result
+}
+
+// Run sends w to each target in turn, so targets do not compete for the
+// network, and measures them
+func Run(ctx context.Context, targets []Target, w Workload) (*Report, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("bench: no targets")
+	}
+	if len(w.Requests) == 0 {
+		return nil, errors.New("bench: no requests")
+	}
+	report := &Report{}
+	for _, t := range targets {
+		for i := 0; i < w.Warmup; i++ {
+			send(ctx, t, w.Requests[i%len(w.Requests)], w.Stream)
+		}
+		res := measure(ctx, t, w)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report, nil
+}
+
+// sample represents the measurements of a request
+type sample struct {
+	latency time.Duration
+	ttft    time.Duration
+	usage   generator.TokenUsage
+	cost    float64
+	err     error
+}
+
+func measure(ctx context.Context, t Target, w Workload) Result {
+	n := w.N
+	if n <= 0 {
+		n = len(w.Requests)
+	}
+	concurrency := max(w.Concurrency, 1)
+
+	samples := make([]sample, n)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < concurrency && i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				samples[i] = send(ctx, t, w.Requests[i%len(w.Requests)], w.Stream)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	res := Result{Target: t.Name, Requests: n, Duration: time.Since(start)}
+	var latencies, ttfts []time.Duration
+	for _, s := range samples {
+		res.Cost += s.cost
+		addUsage(&res.Usage, s.usage)
+		if s.err != nil {
+			res.Errors++
+			if res.Err == nil {
+				res.Err = s.err
+			}
+			continue
+		}
+		latencies = append(latencies, s.latency)
+		if w.Stream && s.ttft > 0 {
+			ttfts = append(ttfts, s.ttft)
+		}
+	}
+	res.ErrorRate = float64(res.Errors) / float64(n)
+	res.Latency, res.TTFT = percentiles(latencies), percentiles(ttfts)
+	if secs := res.Duration.Seconds(); secs > 0 {
+		res.Throughput = float64(len(latencies)) / secs
+		res.TokensPerSecond = float64(res.Usage.CompletionTokens) / secs
+	}
+	return res
+}
+
+// send sends request to t and measures it
+func send(ctx context.Context, t Target, request *generator.Request, stream bool) sample {
+	req := *request
+	if req.Model == "" {
+		req.Model = t.Model
+	}
+	start := time.Now()
+	if !stream {
+		resp, err := t.Client.Generate(ctx, &req)
+		s := sample{latency: time.Since(start), err: err}
+		if err == nil {
+			s.usage, s.cost = resp.Usage, resp.Cost
+		}
+		return s
+	}
+
+	chunks, err := t.Client.GenerateStream(ctx, &req)
+	if err != nil {
+		return sample{latency: time.Since(start), err: err}
+	}
+	var s sample
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			s.err = chunk.Err
+			continue
+		}
+		if s.ttft == 0 && (chunk.Content != "" || chunk.Reasoning != "" || len(chunk.ToolCalls) > 0) {
+			s.ttft = time.Since(start)
+		}
+		if chunk.Usage != (generator.TokenUsage{}) {
+			s.usage = chunk.Usage
+			s.cost += chunk.Cost
+		}
+	}
+	s.latency = time.Since(start)
+	return s
+}
+
+func percentiles(d []time.Duration) Percentiles {
+	if len(d) == 0 {
+		return Percentiles{}
+	}
+	slices.Sort(d)
+	var sum time.Duration
+	for _, v := range d {
+		sum += v
+	}
+	at := func(p float64) time.Duration {
+		return d[min(int(p*float64(len(d))), len(d)-1)]
+	}
+	return Percentiles{Mean: sum / time.Duration(len(d)), P50: at(0.5), P90: at(0.9), P99: at(0.99), Max: d[len(d)-1]}
+}
+
+func addUsage(u *generator.TokenUsage, v generator.TokenUsage) {
+	u.PromptTokens += v.PromptTokens
+	u.CachedPromptTokens += v.CachedPromptTokens
+	u.CompletionTokens += v.CompletionTokens
+	u.ReasoningTokens += v.ReasoningTokens
+	u.TotalTokens += v.TotalTokens
+}
+
+// Write writes the report as a table of a row per target
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "TARGET\tREQUESTS\tERRORS\tP50\tP90\tP99\tTTFT P50\tTTFT P90\tREQ/S\tTOK/S\tCOST\t")
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\t%s\t%s\t%s\t%s\t%s\t%.2f\t%.1f\t$%.4f\t\n",
+			res.Target, res.Requests, 100*res.ErrorRate,
+			round(res.Latency.P50), round(res.Latency.P90), round(res.Latency.P99),
+			round(res.TTFT.P50), round(res.TTFT.P90),
+			res.Throughput, res.TokensPerSecond, res.Cost)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, res := range r.Results {
+		if res.Err != nil {
+			fmt.Fprintf(w, "%s: first error: %v\n", res.Target, res.Err)
+		}
+	}
+	return nil
+}
+
+func round(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "-"
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
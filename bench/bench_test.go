@@ -0,0 +1,59 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestRun(t *testing.T) {
+	fast := mock.New()
+	fast.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return &generator.Response{Content: "hi", Usage: generator.TokenUsage{PromptTokens: 2, CompletionTokens: 3, TotalTokens: 5}}, nil
+	}
+	slow := mock.New().Enqueue(mock.Failure(400, "bad request"))
+	slow.Latency = 20 * time.Millisecond
+
+	targets := []Target{
+		{Name: "fast", Client: gollm.NewClient(fast)},
+		{Name: "slow", Client: gollm.NewClient(slow), Model: "m"},
+	}
+	w := Workload{Requests: []*generator.Request{{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}}, N: 4, Concurrency: 2, Stream: true}
+	report, err := Run(context.Background(), targets, w)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	f, s := report.Results[0], report.Results[1]
+	if f.Requests != 4 || f.Errors != 0 || f.Usage.CompletionTokens != 12 || f.TTFT.P50 == 0 || f.Throughput <= 0 {
+		t.Errorf("fast result = %+v", f)
+	}
+	if s.Errors != 1 || s.ErrorRate != 0.25 || s.Err == nil || s.Latency.P50 < 20*time.Millisecond {
+		t.Errorf("slow result = %+v, want 1 of 4 failing and 20ms latency", s)
+	}
+	if got := slow.LastRequest().Model; got != "m" {
+		t.Errorf("request model = %q, want the target model", got)
+	}
+
+	var out bytes.Buffer
+	if err := report.Write(&out); err != nil || !strings.Contains(out.String(), "25.0%") || !strings.Contains(out.String(), "slow: first error") {
+		t.Errorf("Write() = %v, wrote\n%s", err, out.String())
+	}
+}
+
+func TestPercentiles(t *testing.T) {
+	var d []time.Duration
+	for i := 100; i >= 1; i-- {
+		d = append(d, time.Duration(i)*time.Millisecond)
+	}
+	p := percentiles(d)
+	if p.P50 != 51*time.Millisecond || p.P90 != 91*time.Millisecond || p.P99 != 100*time.Millisecond || p.Max != 100*time.Millisecond {
+		t.Errorf("percentiles() = %+v", p)
+	}
+}
@@ -0,0 +1,119 @@
+package gollm
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/parikxxit/go-llm/cache"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// WithCache caches responses in store for ttl (forever when <= 0): Generate
+// responses to requests with a zero Temperature, keyed by a hash of the
+// generator and request, and embeddings per input, as cache.Embedder does.
+// Metadata, User and IdempotencyKey are not part of the key. Cache hits cost nothing and
+// skip the provider, but still pass output moderation and guardrails. Size
+// limits are up to the store, e.g. the capacity of cache.NewLRU.
+func WithCache(store cache.Store, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cache, c.cacheTTL = store, ttl
+	}
+}
+
+// cacheKey returns the cache key of request, or false when it is not cached
+func (c *Client) cacheKey(ctx context.Context, request *generator.Request) (string, bool) {
+	if c.cache == nil || request.Temperature != 0 || cache.Bypassed(ctx) {
+		return "", false
+	}
+	g, err := c.generatorFor(ctx)
+	if err != nil {
+		return "", false
+	}
+	k := *request
+	k.Metadata, k.User, k.IdempotencyKey = nil, "", ""
+	b, err := json.Marshal(k)
+	if err != nil {
+		return "", false
+	}
+	return cache.Key("generate", g.GetName(), string(b)), true
+}
+
+// cachedResponse returns the cached response to request, if any. Cache
+// errors count as misses.
+func (c *Client) cachedResponse(ctx context.Context, request *generator.Request) (*generator.Response, bool) {
+	key, ok := c.cacheKey(ctx, request)
+	if !ok {
+		return nil, false
+	}
+	b, ok, err := c.cache.Get(ctx, key)
+	if err != nil {
+		c.logger.WarnContext(ctx, "reading response cache", "error", err)
+	}
+	if !ok {
+		return nil, false
+	}
+	var resp generator.Response
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, false
+	}
+	resp.Cost = 0
+	return &resp, true
+}
+
+// cacheResponse stores resp as the response to request
+func (c *Client) cacheResponse(ctx context.Context, request *generator.Request, resp *generator.Response) {
+	key, ok := c.cacheKey(ctx, request)
+	if !ok {
+		return
+	}
+	b, err := json.Marshal(resp)
+	if err == nil {
+		err = c.cache.Set(ctx, key, b, c.cacheTTL)
+	}
+	if err != nil {
+		c.logger.WarnContext(ctx, "writing response cache", "error", err)
+	}
+}
+
+// ResponseCache serves responses to requests similar to ones answered
+// before, e.g. the semantic cache of package semcache
+type ResponseCache interface {
+	Lookup(ctx context.Context, req *generator.Request) (*generator.Response, bool, error)
+	Add(ctx context.Context, req *generator.Request, resp *generator.Response) error
+}
+
+// WithResponseCache serves Generate calls missing the WithCache cache from
+// rc, adding the responses of misses to it. rc sees requests past the input
+// guardrails and moderation, and responses before the output ones, which
+// hits still pass. Calls whose context is cache.Bypassed skip it; its errors
+// count as misses.
+func WithResponseCache(rc ResponseCache) Option {
+	return func(c *Client) {
+		c.responseCache = rc
+	}
+}
+
+// lookupResponse returns the response of the response cache to request, if
+// any
+func (c *Client) lookupResponse(ctx context.Context, request *generator.Request) (*generator.Response, bool) {
+	if c.responseCache == nil || cache.Bypassed(ctx) {
+		return nil, false
+	}
+	resp, ok, err := c.responseCache.Lookup(ctx, request)
+	if err != nil {
+		c.logger.WarnContext(ctx, "reading response cache", "error", err)
+		return nil, false
+	}
+	return resp, ok
+}
+
+// addResponse adds resp to the response cache as the response to request
+func (c *Client) addResponse(ctx context.Context, request *generator.Request, resp *generator.Response) {
+	if c.responseCache == nil || cache.Bypassed(ctx) {
+		return
+	}
+	if err := c.responseCache.Add(ctx, request, resp); err != nil {
+		c.logger.WarnContext(ctx, "writing response cache", "error", err)
+	}
+}
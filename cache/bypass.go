@@ -0,0 +1,17 @@
+package cache
+
+import "context"
+
+type bypassKey struct{}
+
+// WithBypass returns a context whose requests skip the caches of this
+// package and those honoring Bypassed, neither reading nor writing entries
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// Bypassed reports whether ctx requests skipping caches
+func Bypassed(ctx context.Context) bool {
+	b, _ := ctx.Value(bypassKey{}).(bool)
+	return b
+}
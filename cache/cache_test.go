@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/embedder"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	c.Get(ctx, "a") // b becomes least recently used
+	c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("Get(b) found an evicted entry")
+	}
+	if v, ok, _ := c.Get(ctx, "a"); !ok || string(v) != "1" {
+		t.Errorf("Get(a) = %q, %v", v, ok)
+	}
+
+	c.Set(ctx, "short", []byte("x"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok, _ := c.Get(ctx, "short"); ok {
+		t.Error("Get() found an expired entry")
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("ab", "c") == Key("a", "bc") {
+		t.Error("Key() collides on different part boundaries")
+	}
+}
+
+type countingEmbedder struct {
+	inputs []string
+}
+
+func (e *countingEmbedder) Embed(_ context.Context, req *embedder.Request) (*embedder.Response, error) {
+	e.inputs = append(e.inputs, req.Input...)
+	resp := &embedder.Response{Model: req.Model, Usage: embedder.TokenUsage{TotalTokens: len(req.Input)}}
+	for i, in := range req.Input {
+		resp.Data = append(resp.Data, embedder.EmbedData{Embedding: []float64{float64(len(in)), 0.1}, Index: i})
+	}
+	return resp, nil
+}
+
+func (e *countingEmbedder) GetEmbedderName() string { return "counting" }
+
+func TestEmbedder(t *testing.T) {
+	ctx := context.Background()
+	next := &countingEmbedder{}
+	e := NewEmbedder(next, NewLRU(100), 0)
+
+	if _, err := e.Embed(ctx, &embedder.Request{Model: "m", Input: []string{"a", "bb"}}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := e.Embed(ctx, &embedder.Request{Model: "m", Input: []string{"bb", "ccc", "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next.inputs) != 3 || next.inputs[2] != "ccc" {
+		t.Errorf("inputs sent = %v, want only ccc on the second call", next.inputs)
+	}
+	for i, want := range []float64{2, 3, 1} {
+		if d := resp.Data[i]; d.Index != i || d.Embedding[0] != want || d.Embedding[1] != 0.1 {
+			t.Errorf("Data[%d] = %+v, want embedding of length %v", i, d, want)
+		}
+	}
+	if resp.Usage.TotalTokens != 1 {
+		t.Errorf("Usage = %+v, want only the miss", resp.Usage)
+	}
+
+	// Another model is a different cache entry
+	e.Embed(ctx, &embedder.Request{Model: "other", Input: []string{"a"}})
+	if len(next.inputs) != 4 {
+		t.Errorf("inputs sent = %v, want a re-embedded for another model", next.inputs)
+	}
+
+	resp, _ = e.Embed(ctx, &embedder.Request{Model: "m", Input: []string{"a"}, Float32: true})
+	if d := resp.Data[0]; d.Embedding32 == nil || d.Embedding32[0] != 1 {
+		t.Errorf("Float32 hit = %+v", d)
+	}
+}
@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/parikxxit/go-llm/embedder"
+)
+
+// Embedder wraps an embedder, caching each input's embedding under a hash of
+// the model, dimensions and input text. Only inputs missing from the cache
+// are sent, so usage reflects what was actually paid for. Requests with
+// images or for sparse or multi-vector outputs, and those whose context is
+// Bypassed, bypass the cache.
+type Embedder struct {
+	next  embedder.Embedder
+	store Store
+	ttl   time.Duration
+}
+
+// NewEmbedder creates a caching embedder storing entries in store for ttl;
+// a ttl <= 0 never expires. Cache errors fall through to next.
+func NewEmbedder(next embedder.Embedder, store Store, ttl time.Duration) *Embedder {
+	return &Embedder{next: next, store: store, ttl: ttl}
+}
+
+func (e *Embedder) Embed(ctx context.Context, req *embedder.Request) (*embedder.Response, error) {
+	if len(req.Images) > 0 || !req.WantsOnlyDense() || Bypassed(ctx) {
+		return e.next.Embed(ctx, req)
+	}
+
+	resp := &embedder.Response{Object: "list", Model: req.Model, Data: make([]embedder.EmbedData, len(req.Input))}
+
+	keys := make([]string, len(req.Input))
+	var missing []int
+	for i, input := range req.Input {
+		keys[i] = Key("embedding", req.Model, strconv.Itoa(req.Dimensions), input)
+		resp.Data[i] = embedder.EmbedData{Object: "embedding", Index: i}
+		value, ok, err := e.store.Get(ctx, keys[i])
+		if err != nil || !ok || !decodeInto(&resp.Data[i], value, req.Float32) {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return resp, nil
+	}
+
+	miss := *req
+	miss.Input = make([]string, len(missing))
+	for j, i := range missing {
+		miss.Input[j] = req.Input[i]
+	}
+	fresh, err := e.next.Embed(ctx, &miss)
+	if err != nil {
+		return nil, err
+	}
+	if len(fresh.Data) != len(missing) {
+		return nil, fmt.Errorf("embedder returned %d embeddings for %d inputs", len(fresh.Data), len(missing))
+	}
+
+	resp.Object, resp.Model, resp.Usage = fresh.Object, fresh.Model, fresh.Usage
+	for _, d := range fresh.Data {
+		if d.Index < 0 || d.Index >= len(missing) {
+			return nil, fmt.Errorf("embedder returned out of range index %d", d.Index)
+		}
+		i := missing[d.Index]
+		d.Index = i
+		resp.Data[i] = d
+		// A failed write only costs a future miss
+		_ = e.store.Set(ctx, keys[i], encode(d), e.ttl)
+	}
+	return resp, nil
+}
+
+func (e *Embedder) GetEmbedderName() string {
+	return e.next.GetEmbedderName()
+}
+
+// MaxBatchSize passes through the batch limit of the wrapped embedder
+func (e *Embedder) MaxBatchSize() int {
+	if l, ok := e.next.(embedder.BatchLimiter); ok {
+		return l.MaxBatchSize()
+	}
+	return 0
+}
+
+// SupportsImages passes through the image support of the wrapped embedder
+func (e *Embedder) SupportsImages() bool {
+	s, ok := e.next.(embedder.ImageSupporter)
+	return ok && s.SupportsImages()
+}
+
+// SupportsOutput passes through the outputs of the wrapped embedder
+func (e *Embedder) SupportsOutput(out embedder.Output) bool {
+	return embedder.Supports(e.next, out)
+}
+
+// encode stores a vector as a width byte (4 or 8) followed by little-endian
+// floats, keeping the precision it was returned with
+func encode(d embedder.EmbedData) []byte {
+	if d.Embedding32 != nil {
+		b := make([]byte, 1+4*len(d.Embedding32))
+		b[0] = 4
+		for i, x := range d.Embedding32 {
+			binary.LittleEndian.PutUint32(b[1+4*i:], math.Float32bits(x))
+		}
+		return b
+	}
+	b := make([]byte, 1+8*len(d.Embedding))
+	b[0] = 8
+	for i, x := range d.Embedding {
+		binary.LittleEndian.PutUint64(b[1+8*i:], math.Float64bits(x))
+	}
+	return b
+}
+
+// decodeInto fills d from an encoded vector in the representation asked for,
+// reporting false for corrupt entries
+func decodeInto(d *embedder.EmbedData, b []byte, float32s bool) bool {
+	if len(b) == 0 || (b[0] != 4 && b[0] != 8) || (len(b)-1)%int(b[0]) != 0 {
+		return false
+	}
+	width := int(b[0])
+	n := (len(b) - 1) / width
+	if width == 4 {
+		v := make([]float32, n)
+		for i := range v {
+			v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[1+4*i:]))
+		}
+		d.Embedding32 = v
+	} else {
+		v := make([]float64, n)
+		for i := range v {
+			v[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[1+8*i:]))
+		}
+		d.Embedding = v
+	}
+	if float32s && d.Embedding32 == nil {
+		d.Embedding32, d.Embedding = embedder.Float32(d.Embedding), nil
+	} else if !float32s && d.Embedding == nil {
+		d.Embedding, d.Embedding32 = embedder.Float64(d.Embedding32), nil
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package gollm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/parikxxit/go-llm/cache"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_WithCache(t *testing.T) {
+	m := mock.New()
+	emb := &fakeEmbedder{}
+	client := NewClient(m, WithEmbedder(emb), WithCache(cache.NewLRU(10), 0))
+	ctx := context.Background()
+	req := func(content string, temperature float64, user string) *generator.Request {
+		return &generator.Request{Temperature: temperature, User: user, Messages: []generator.Message{{Role: generator.USER, Content: content}}}
+	}
+
+	for _, r := range []*generator.Request{req("a", 0, "u1"), req("a", 0, "u2"), req("b", 0, ""), req("a", 0.7, ""), req("a", 0.7, "")} {
+		if _, err := client.Generate(ctx, r); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+	// "a" once for both users, "b", and both non-deterministic requests
+	if n := len(m.Requests()); n != 4 {
+		t.Errorf("provider requests = %d, want 4", n)
+	}
+	resp, _ := client.Generate(ctx, req("b", 0, ""))
+	if resp.Content != "b" || resp.Cost != 0 {
+		t.Errorf("cached Generate() = %+v", resp)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Embed(ctx, &embedder.Request{Input: []string{"x", "yy"}}); err != nil {
+			t.Fatalf("Embed() error = %v", err)
+		}
+	}
+	if len(emb.batches) != 1 {
+		t.Errorf("embedder batches = %v, want 1", emb.batches)
+	}
+
+	// A derived client with its own cache leaves the parent's alone
+	derived := client.With(WithCache(cache.NewLRU(10), 0))
+	for _, c := range []*Client{derived, derived, client} {
+		if _, err := c.Embed(ctx, &embedder.Request{Input: []string{"z"}}); err != nil {
+			t.Fatalf("Embed() error = %v", err)
+		}
+	}
+	if len(emb.batches) != 3 {
+		t.Errorf("embedder batches = %v, want 3", emb.batches)
+	}
+}
@@ -0,0 +1,155 @@
+package gollm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/parikxxit/go-llm/cache"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/reranker"
+)
+
+// CallOption overrides the client's defaults for a single Generate,
+// GenerateStream, Embed or Rerank call. Options travel in the context, so
+// they also apply to the calls of GenerateWithTools, GenerateValidated and
+// other helpers given that context.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	timeout       time.Duration
+	retryCount    int
+	setRetryCount bool
+	noCache       bool
+	provider      string
+	raw           bool
+}
+
+type callOptionsKey struct{}
+
+// CallWithTimeout sets the timeout of the call, the idle timeout for
+// streams
+func CallWithTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// CallWithRetryCount sets the number of retries of the call; zero disables
+// retries
+func CallWithRetryCount(count int) CallOption {
+	return func(o *callOptions) {
+		o.retryCount, o.setRetryCount = count, true
+	}
+}
+
+// CallWithoutCache skips the response and embedding caches, neither reading
+// nor writing them; see cache.WithBypass
+func CallWithoutCache() CallOption {
+	return func(o *callOptions) {
+		o.noCache = true
+	}
+}
+
+// CallWithProvider sends the call to the primary or fallback provider of
+// the given name, without hedging
+func CallWithProvider(name string) CallOption {
+	return func(o *callOptions) {
+		o.provider = name
+	}
+}
+
+// CallWithRawResponse keeps the provider payload of the response, or of
+// each chunk of a stream, in Response.Raw; see generator.WithRawCapture.
+// Cache hits carry the payload of the response cached, if it was kept.
+func CallWithRawResponse() CallOption {
+	return func(o *callOptions) {
+		o.raw = true
+	}
+}
+
+// withCallOptions returns ctx carrying opts on top of the options it
+// already carries
+func withCallOptions(ctx context.Context, opts []CallOption) context.Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+	o := callOpts(ctx)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.noCache {
+		ctx = cache.WithBypass(ctx)
+	}
+	if o.raw {
+		ctx = generator.WithRawCapture(ctx)
+	}
+	return context.WithValue(ctx, callOptionsKey{}, o)
+}
+
+func callOpts(ctx context.Context) callOptions {
+	o, _ := ctx.Value(callOptionsKey{}).(callOptions)
+	return o
+}
+
+// timeoutFor returns the timeout of the op call in ctx
+func (c *Client) timeoutFor(ctx context.Context, op Operation) time.Duration {
+	if o := callOpts(ctx); o.timeout > 0 {
+		return o.timeout
+	}
+	if t, ok := c.timeouts[op]; ok {
+		return t
+	}
+	return c.timeout
+}
+
+// retryCountFor returns the number of retries of the call in ctx
+func (c *Client) retryCountFor(ctx context.Context) int {
+	if o := callOpts(ctx); o.setRetryCount {
+		return o.retryCount
+	}
+	return c.retryCount
+}
+
+// generatorFor returns the generator the call in ctx is sent to
+func (c *Client) generatorFor(ctx context.Context) (generator.Generator, error) {
+	name := callOpts(ctx).provider
+	if name == "" || name == c.llm.GetName() {
+		return c.llm, nil
+	}
+	for _, g := range c.fallbackGenerator {
+		if g.GetName() == name {
+			return g, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown generator provider %q", name)
+}
+
+// embedderFor returns the embedder the call in ctx is sent to
+func (c *Client) embedderFor(ctx context.Context) (embedder.Embedder, error) {
+	name := callOpts(ctx).provider
+	if name == "" || name == c.embedder.GetEmbedderName() {
+		return c.embedder, nil
+	}
+	for _, e := range c.fallbackEmbedder {
+		if e.GetEmbedderName() == name {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown embedder provider %q", name)
+}
+
+// rerankerFor returns the reranker the call in ctx is sent to
+func (c *Client) rerankerFor(ctx context.Context) (reranker.Reranker, error) {
+	name := callOpts(ctx).provider
+	if name == "" || name == c.reranker.GetRerankerName() {
+		return c.reranker, nil
+	}
+	for _, r := range c.fallbackReranker {
+		if r.GetRerankerName() == name {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown reranker provider %q", name)
+}
@@ -0,0 +1,98 @@
+package gollm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/cache"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_CallOptions(t *testing.T) {
+	overloaded := &llmerrors.Error{Kind: llmerrors.ErrOverloaded, StatusCode: 529, Err: errors.New("overloaded")}
+	calls := 0
+	primary := mock.New()
+	primary.GenerateFunc = func(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+		calls++
+		if req.Messages[0].Content == "fail" {
+			return nil, overloaded
+		}
+		if req.Messages[0].Content == "slow" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &generator.Response{Content: "primary"}, nil
+	}
+	backup := mock.New()
+	backup.Name = "backup"
+	client := NewClient(primary,
+		WithFallbackGenerators([]generator.Generator{backup}),
+		WithRetryBackoff(time.Millisecond, time.Millisecond),
+		WithCache(cache.NewLRU(10), 0),
+	)
+	req := func(content string) *generator.Request {
+		return &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: content}}}
+	}
+
+	start := time.Now()
+	if _, err := client.Generate(context.Background(), req("slow"), CallWithTimeout(10*time.Millisecond)); !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Errorf("Generate() with CallWithTimeout() error = %v after %v", err, time.Since(start))
+	}
+
+	calls = 0
+	client.Generate(context.Background(), req("fail"), CallWithRetryCount(0))
+	if calls != 1 {
+		t.Errorf("Generate() with CallWithRetryCount(0) made %d calls, want 1", calls)
+	}
+
+	calls = 0
+	client.Generate(context.Background(), req("hi"))
+	client.Generate(context.Background(), req("hi"))
+	client.Generate(context.Background(), req("hi"), CallWithoutCache())
+	if calls != 2 {
+		t.Errorf("Generate() made %d calls, want a cache hit and a bypass", calls)
+	}
+
+	resp, err := client.Generate(context.Background(), req("hi"), CallWithProvider("backup"))
+	if err != nil || resp.Content != "hi" {
+		t.Errorf("Generate() with CallWithProvider() = %v, %v, want the backup's echo", resp, err)
+	}
+	if _, err := client.Generate(context.Background(), req("hi"), CallWithProvider("unknown")); err == nil {
+		t.Error("Generate() with an unknown provider error = nil")
+	}
+
+	emb := &fakeEmbedder{}
+	client = NewClient(mock.New(), WithEmbedder(emb), WithCache(cache.NewLRU(10), 0))
+	for _, opts := range [][]CallOption{nil, nil, {CallWithoutCache()}} {
+		client.Embed(context.Background(), &embedder.Request{Input: []string{"a"}}, opts...)
+	}
+	if len(emb.batches) != 2 {
+		t.Errorf("Embed() sent %d requests, want a cache hit and a bypass", len(emb.batches))
+	}
+}
+
+func TestClient_CallWithRawResponse(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+		resp := &generator.Response{Content: "hi"}
+		if generator.RawCaptured(ctx) {
+			resp.Raw = json.RawMessage(`{"system_fingerprint":"fp_1"}`)
+		}
+		return resp, nil
+	}
+	client := NewClient(m)
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+	if resp, err := client.Generate(context.Background(), req); err != nil || resp.Raw != nil {
+		t.Errorf("Generate() = %+v, %v, want no Raw", resp, err)
+	}
+	resp, err := client.Generate(context.Background(), req, CallWithRawResponse())
+	if err != nil || string(resp.Raw) != `{"system_fingerprint":"fp_1"}` {
+		t.Errorf("Generate(CallWithRawResponse) = %+v, %v", resp, err)
+	}
+}
@@ -0,0 +1,53 @@
+package gollm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/models"
+)
+
+// ErrUnsupported is returned when a request needs a capability its model
+// lacks, according to the models registry
+var ErrUnsupported = errors.New("unsupported by model")
+
+// WithCapabilityChecks checks Generate and GenerateStream requests against
+// the models registry entry of Request.Model before sending them: requests
+// with tools to models without tools support, a MaxTokens over the model's
+// MaxOutputTokens or to an embedding model fail with ErrUnsupported, and
+// requests whose estimated tokens overflow the context window fail with
+// llmerrors.ErrContextLengthExceeded, which WithContextRecovery recovers
+// from without a provider round trip. Models missing from the registry are
+// not checked.
+func WithCapabilityChecks() Option {
+	return func(c *Client) {
+		c.capabilityChecks = true
+	}
+}
+
+// checkCapabilities checks request against the registry, when enabled
+func (c *Client) checkCapabilities(request *generator.Request) error {
+	if !c.capabilityChecks || request.Model == "" {
+		return nil
+	}
+	info, ok := models.Lookup(request.Model)
+	if !ok {
+		return nil
+	}
+	switch {
+	case info.Capabilities.Has(models.Embedding):
+		return fmt.Errorf("%w: %s is an embedding model", ErrUnsupported, request.Model)
+	case len(request.Tools) > 0 && !info.Capabilities.Has(models.Tools):
+		return fmt.Errorf("%w: %s does not support tools", ErrUnsupported, request.Model)
+	case info.MaxOutputTokens > 0 && request.MaxTokens > info.MaxOutputTokens:
+		return fmt.Errorf("%w: MaxTokens %d over the %d output tokens of %s", ErrUnsupported, request.MaxTokens, info.MaxOutputTokens, request.Model)
+	}
+	if info.ContextWindow > 0 {
+		if n := generateTokens(request)(); n > info.ContextWindow {
+			return fmt.Errorf("%w: about %d tokens for the %d of %s", llmerrors.ErrContextLengthExceeded, n, info.ContextWindow, request.Model)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/models"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_WithCapabilityChecks(t *testing.T) {
+	models.Register(models.Info{Name: "tiny-chat", ContextWindow: 100, MaxOutputTokens: 20})
+	hi := []generator.Message{{Role: generator.USER, Content: "hi"}}
+	long := []generator.Message{{Role: generator.USER, Content: strings.Repeat("word ", 200)}, {Role: generator.USER, Content: "hi"}}
+	tools := []generator.Tool{{Name: "lookup"}}
+
+	tests := []struct {
+		name    string
+		request generator.Request
+		wantErr error
+	}{
+		{"fits", generator.Request{Model: "tiny-chat", Messages: hi, MaxTokens: 20}, nil},
+		{"unknown model", generator.Request{Model: "private-model", Messages: long, Tools: tools}, nil},
+		{"no model", generator.Request{Messages: hi, Tools: tools}, nil},
+		{"tools", generator.Request{Model: "tiny-chat-v2", Messages: hi, Tools: tools}, ErrUnsupported},
+		{"max tokens", generator.Request{Model: "tiny-chat", Messages: hi, MaxTokens: 21}, ErrUnsupported},
+		{"embedding model", generator.Request{Model: "text-embedding-3-small", Messages: hi}, ErrUnsupported},
+		{"context window", generator.Request{Model: "tiny-chat", Messages: long}, llmerrors.ErrContextLengthExceeded},
+	}
+	for _, tt := range tests {
+		m := mock.New()
+		client := NewClient(m, WithCapabilityChecks())
+		_, err := client.Generate(context.Background(), &tt.request)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: Generate() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if _, serr := client.GenerateStream(context.Background(), &tt.request); !errors.Is(serr, tt.wantErr) {
+			t.Errorf("%s: GenerateStream() error = %v, want %v", tt.name, serr, tt.wantErr)
+		}
+		if sent := len(m.Requests()) > 0; sent != (tt.wantErr == nil) {
+			t.Errorf("%s: request sent = %v", tt.name, sent)
+		}
+	}
+
+	// Overflowing requests are truncated before reaching the provider
+	m := mock.New()
+	client := NewClient(m, WithCapabilityChecks(), WithContextRecovery(dropOldest(1)))
+	resp, err := client.Generate(context.Background(), &generator.Request{Model: "tiny-chat", Messages: long})
+	if err != nil || resp.Content != "hi" || len(m.Requests()) != 1 {
+		t.Errorf("Generate() = %v, %v after %d requests, want the truncated request sent once", resp, err, len(m.Requests()))
+	}
+}
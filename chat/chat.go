@@ -0,0 +1,152 @@
+// Package chat provides conversation sessions that own their message history.
+package chat
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/memory"
+)
+
+// Session represents a conversation with a model. The history lives in a
+// memory.Store, in-memory by default. Session is safe for concurrent use;
+// concurrent Send calls are serialized so the history stays ordered.
+type Session struct {
+	id           string
+	client       *gollm.Client
+	store        memory.Store
+	model        string
+	systemPrompt string
+	template     generator.Request
+	truncator    Truncator
+
+	mu sync.Mutex
+}
+
+// Option is a function that configures a Session
+type Option func(*Session)
+
+// WithModel sets the model used for every request of the session
+func WithModel(model string) Option {
+	return func(s *Session) {
+		s.model = model
+	}
+}
+
+// WithSystemPrompt sets a system prompt sent ahead of the history
+func WithSystemPrompt(prompt string) Option {
+	return func(s *Session) {
+		s.systemPrompt = prompt
+	}
+}
+
+// WithStore sets the store holding the history, so a session can outlive the
+// process or be shared between replicas
+func WithStore(store memory.Store) Option {
+	return func(s *Session) {
+		s.store = store
+	}
+}
+
+// WithSessionID sets the ID the history is stored under; a random ID is used
+// otherwise. Reuse an ID to resume a stored conversation.
+func WithSessionID(id string) Option {
+	return func(s *Session) {
+		s.id = id
+	}
+}
+
+// WithRequestTemplate sets generation parameters (temperature, max tokens, ...)
+// applied to every request. Its Model and Messages are ignored.
+func WithRequestTemplate(req generator.Request) Option {
+	return func(s *Session) {
+		s.template = req
+	}
+}
+
+// WithTruncator sets the policy shortening requests that would exceed the
+// model's context window. The stored history itself is never truncated.
+func WithTruncator(t Truncator) Option {
+	return func(s *Session) {
+		s.truncator = t
+	}
+}
+
+// NewSession creates a new session sending requests through client
+func NewSession(client *gollm.Client, opts ...Option) *Session {
+	if client == nil {
+		panic("client cannot be nil")
+	}
+
+	s := &Session{
+		id:     uuid.New().String(),
+		client: client,
+		store:  memory.NewInMemoryStore(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ID returns the ID the session history is stored under
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Send appends text as a user message, generates a reply and appends it to the
+// history. On error the history is left unchanged.
+func (s *Session) Send(ctx context.Context, text string) (*generator.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, err := s.store.Get(ctx, s.id)
+	if err != nil {
+		return nil, err
+	}
+
+	user := generator.Message{Role: generator.USER, Content: text}
+	req := s.request(append(history, user))
+	if s.truncator != nil {
+		if req.Messages, err = s.truncator.Truncate(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := s.client.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	assistant := generator.Message{Role: generator.ASSISTANT, Content: resp.Content}
+	if err := s.store.Append(ctx, s.id, user, assistant); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Messages returns the session history, without the system prompt
+func (s *Session) Messages(ctx context.Context) ([]generator.Message, error) {
+	return s.store.Get(ctx, s.id)
+}
+
+// Reset clears the session history
+func (s *Session) Reset(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store.Trim(ctx, s.id, 0)
+}
+
+func (s *Session) request(history []generator.Message) *generator.Request {
+	req := s.template
+	req.Model = s.model
+	req.Messages = make([]generator.Message, 0, len(history)+1)
+	if s.systemPrompt != "" {
+		req.Messages = append(req.Messages, generator.Message{Role: generator.SYSTEM, Content: s.systemPrompt})
+	}
+	req.Messages = append(req.Messages, history...)
+	return &req
+}
@@ -0,0 +1,79 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/memory"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestSession_Send(t *testing.T) {
+	m := mock.New()
+	s := NewSession(gollm.NewClient(m), WithModel("test"), WithSystemPrompt("be brief"))
+
+	if _, err := s.Send(context.Background(), "hi"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	resp, err := s.Send(context.Background(), "again")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.Content != "again" {
+		t.Errorf("Content = %q, want %q", resp.Content, "again")
+	}
+
+	history, err := s.Messages(context.Background())
+	if err != nil {
+		t.Fatalf("Messages() error = %v", err)
+	}
+	if len(history) != 4 {
+		t.Fatalf("len(Messages()) = %d, want 4", len(history))
+	}
+
+	reqs := m.Requests()
+	last := reqs[len(reqs)-1]
+	if last.Model != "test" {
+		t.Errorf("Model = %q, want %q", last.Model, "test")
+	}
+	if len(last.Messages) != 4 || last.Messages[0].Role != generator.SYSTEM {
+		t.Errorf("request messages = %+v, want system prompt followed by 3 messages", last.Messages)
+	}
+}
+
+func TestSession_SendError(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return nil, errors.New("boom")
+	}
+	s := NewSession(gollm.NewClient(m))
+
+	if _, err := s.Send(context.Background(), "hi"); err == nil {
+		t.Fatal("Send() error = nil, want error")
+	}
+	if history, _ := s.Messages(context.Background()); len(history) != 0 {
+		t.Errorf("len(Messages()) = %d, want 0", len(history))
+	}
+}
+
+func TestSession_WithStore(t *testing.T) {
+	store := memory.NewInMemoryStore()
+	client := gollm.NewClient(mock.New())
+
+	first := NewSession(client, WithStore(store), WithSessionID("abc"))
+	if _, err := first.Send(context.Background(), "hi"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	resumed := NewSession(client, WithStore(store), WithSessionID("abc"))
+	history, err := resumed.Messages(context.Background())
+	if err != nil {
+		t.Fatalf("Messages() error = %v", err)
+	}
+	if len(history) != 2 {
+		t.Errorf("len(Messages()) = %d, want 2", len(history))
+	}
+}
@@ -0,0 +1,152 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/models"
+	"github.com/parikxxit/go-llm/tokenizer"
+)
+
+const defaultSummaryPrompt = "Summarize the following conversation concisely, keeping facts, decisions and open questions:"
+
+// Truncator shortens the messages of a request so they fit the model's context
+// window. The last message is always kept.
+type Truncator interface {
+	Truncate(ctx context.Context, req *generator.Request) ([]generator.Message, error)
+}
+
+// SlidingWindow drops the oldest messages until the request fits its token
+// budget. The budget is MaxTokens, or the model's context window from the
+// models registry, minus the tokens reserved for the reply. Requests whose
+// budget cannot be determined are left untouched.
+type SlidingWindow struct {
+	Counter          tokenizer.Counter // Defaults to tokenizer.ForModel
+	MaxTokens        int
+	ReserveTokens    int  // Used when the request has no MaxTokens
+	KeepSystemPrompt bool // Never drop system messages
+}
+
+func (w SlidingWindow) Truncate(_ context.Context, req *generator.Request) ([]generator.Message, error) {
+	pinned, kept, _ := w.split(req)
+	return merge(pinned, kept), nil
+}
+
+// split divides messages into pinned ones, kept ones and the dropped prefix
+func (w SlidingWindow) split(req *generator.Request) (pinned, kept, dropped []generator.Message) {
+	var rest []generator.Message
+	for _, m := range req.Messages {
+		if w.KeepSystemPrompt && m.Role == generator.SYSTEM {
+			pinned = append(pinned, m)
+		} else {
+			rest = append(rest, m)
+		}
+	}
+
+	budget := w.budget(req)
+	if budget <= 0 {
+		return pinned, rest, nil
+	}
+	counter := w.counter(req.Model)
+	n := w.fit(counter, budget-tokenizer.CountMessages(counter, pinned), rest)
+	return pinned, rest[n:], rest[:n]
+}
+
+// fit returns how many leading messages must be dropped to fit budget
+func (w SlidingWindow) fit(counter tokenizer.Counter, budget int, messages []generator.Message) int {
+	total := tokenizer.CountMessages(counter, messages)
+	n := 0
+	for total > budget && n < len(messages)-1 {
+		total -= tokenizer.CountMessage(counter, messages[n])
+		n++
+	}
+	return n
+}
+
+func (w SlidingWindow) budget(req *generator.Request) int {
+	budget := w.MaxTokens
+	if budget == 0 {
+		budget = models.ContextWindow(req.Model)
+	}
+	if budget == 0 {
+		return 0
+	}
+	if req.MaxTokens > 0 {
+		return budget - req.MaxTokens
+	}
+	return budget - w.ReserveTokens
+}
+
+func (w SlidingWindow) counter(model string) tokenizer.Counter {
+	if w.Counter == nil {
+		return tokenizer.ForModel(model)
+	}
+	return w.Counter
+}
+
+// SummarizeOldest replaces the messages a SlidingWindow would drop with a
+// system message summarizing them, generated by Client. It costs an extra
+// generation every time the window overflows.
+type SummarizeOldest struct {
+	Client *gollm.Client
+	Model  string // Model used for summaries, defaults to the request model
+	Prompt string
+	Window SlidingWindow
+}
+
+func (s SummarizeOldest) Truncate(ctx context.Context, req *generator.Request) ([]generator.Message, error) {
+	pinned, kept, dropped := s.Window.split(req)
+	if len(dropped) == 0 {
+		return merge(pinned, kept), nil
+	}
+
+	summary, err := s.summarize(ctx, req.Model, dropped)
+	if err != nil {
+		return nil, fmt.Errorf("summarizing history: %w", err)
+	}
+	pinned = append(pinned, summary)
+
+	// The summary takes space as well, so drop more if needed
+	if budget := s.Window.budget(req); budget > 0 {
+		counter := s.Window.counter(req.Model)
+		kept = kept[s.Window.fit(counter, budget-tokenizer.CountMessages(counter, pinned), kept):]
+	}
+	return merge(pinned, kept), nil
+}
+
+func (s SummarizeOldest) summarize(ctx context.Context, model string, messages []generator.Message) (generator.Message, error) {
+	prompt := s.Prompt
+	if prompt == "" {
+		prompt = defaultSummaryPrompt
+	}
+	if s.Model != "" {
+		model = s.Model
+	}
+
+	var transcript strings.Builder
+	for _, m := range messages {
+		fmt.Fprintf(&transcript, "%s: %s\n", m.Role, m.Content)
+	}
+
+	resp, err := s.Client.Generate(ctx, &generator.Request{
+		Model: model,
+		Messages: []generator.Message{
+			{Role: generator.SYSTEM, Content: prompt},
+			{Role: generator.USER, Content: transcript.String()},
+		},
+	})
+	if err != nil {
+		return generator.Message{}, err
+	}
+	return generator.Message{
+		Role:    generator.SYSTEM,
+		Content: "Summary of the earlier conversation: " + resp.Content,
+	}, nil
+}
+
+func merge(pinned, kept []generator.Message) []generator.Message {
+	return append(append(make([]generator.Message, 0, len(pinned)+len(kept)), pinned...), kept...)
+}
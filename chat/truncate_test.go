@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func longHistory() []generator.Message {
+	messages := []generator.Message{{Role: generator.SYSTEM, Content: "system"}}
+	for i := 0; i < 10; i++ {
+		messages = append(messages, generator.Message{Role: generator.USER, Content: strings.Repeat("x", 40)})
+	}
+	return messages
+}
+
+func TestSlidingWindow_Truncate(t *testing.T) {
+	w := SlidingWindow{MaxTokens: 50, KeepSystemPrompt: true}
+
+	got, err := w.Truncate(context.Background(), &generator.Request{Messages: longHistory()})
+	if err != nil {
+		t.Fatalf("Truncate() error = %v", err)
+	}
+	// system: 2+4 tokens, each user message: 10+4 tokens
+	if len(got) != 4 {
+		t.Fatalf("len(Truncate()) = %d, want 4", len(got))
+	}
+	if got[0].Role != generator.SYSTEM {
+		t.Errorf("first message role = %q, want system", got[0].Role)
+	}
+}
+
+func TestSlidingWindow_UnknownModel(t *testing.T) {
+	got, _ := SlidingWindow{}.Truncate(context.Background(), &generator.Request{Model: "unknown", Messages: longHistory()})
+	if len(got) != 11 {
+		t.Errorf("len(Truncate()) = %d, want 11", len(got))
+	}
+}
+
+func TestSummarizeOldest_Truncate(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return &generator.Response{Content: "short"}, nil
+	}
+	s := SummarizeOldest{
+		Client: gollm.NewClient(m),
+		Window: SlidingWindow{MaxTokens: 50, KeepSystemPrompt: true},
+	}
+
+	got, err := s.Truncate(context.Background(), &generator.Request{Messages: longHistory()})
+	if err != nil {
+		t.Fatalf("Truncate() error = %v", err)
+	}
+	if len(got) < 2 || !strings.Contains(got[1].Content, "short") {
+		t.Errorf("Truncate() = %+v, want summary after system prompt", got)
+	}
+	if len(m.Requests()) != 1 {
+		t.Errorf("summary requests = %d, want 1", len(m.Requests()))
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/parikxxit/go-llm/bench"
+	"github.com/parikxxit/go-llm/config"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// targetFlags represents the repeated -target flags, as name=file or file
+type targetFlags []string
+
+func (t *targetFlags) String() string { return strings.Join(*t, ",") }
+
+func (t *targetFlags) Set(v string) error {
+	*t = append(*t, v)
+	return nil
+}
+
+func runBench(ctx context.Context, g globalFlags, environ, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var targetFiles targetFlags
+	fs.Var(&targetFiles, "target", "configuration `file` of a target, as name=file or file named after its base name; repeatable")
+	n := fs.Int("n", 10, "`number` of requests per target")
+	concurrency := fs.Int("c", 4, "`number` of requests in flight")
+	warmup := fs.Int("warmup", 0, "`number` of requests per target sent first and not measured")
+	stream := fs.Bool("stream", false, "stream the replies, measuring the time to first token")
+	maxTokens := fs.Int("max-tokens", 0, "maximum `number` of tokens generated")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: gollm bench [flags] [prompt...]\n\nWithout arguments, each line of stdin is a prompt, sent in turn. Without\n-target, the provider configured by the global flags is the only target.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	prompts, err := inputs(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	if len(prompts) == 0 {
+		return errors.New("bench: no prompt given")
+	}
+
+	targets, err := benchTargets(g, environ, targetFiles)
+	if err != nil {
+		return err
+	}
+	w := bench.Workload{N: *n, Concurrency: *concurrency, Stream: *stream, Warmup: *warmup}
+	for _, p := range prompts {
+		w.Requests = append(w.Requests, &generator.Request{
+			Messages:  []generator.Message{{Role: generator.USER, Content: p}},
+			MaxTokens: *maxTokens,
+		})
+	}
+	report, err := bench.Run(ctx, targets, w)
+	if err != nil {
+		return err
+	}
+	return report.Write(stdout)
+}
+
+// benchTargets returns a target per file, or else the target configured by
+// g and environ
+func benchTargets(g globalFlags, environ []string, files []string) ([]bench.Target, error) {
+	if len(files) == 0 {
+		cfg, err := loadConfig(g, environ)
+		if err != nil {
+			return nil, err
+		}
+		client, err := cfg.NewClient()
+		if err != nil {
+			return nil, err
+		}
+		return []bench.Target{{Name: targetName(cfg), Client: client}}, nil
+	}
+
+	var targets []bench.Target
+	for _, f := range files {
+		name, path, ok := strings.Cut(f, "=")
+		if !ok {
+			path = f
+			name = strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+		}
+		cfg, err := config.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", name, err)
+		}
+		cfg.Debug = cfg.Debug || g.debug
+		client, err := cfg.NewClient()
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", name, err)
+		}
+		targets = append(targets, bench.Target{Name: name, Client: client})
+	}
+	return targets, nil
+}
+
+func targetName(cfg *config.Config) string {
+	if cfg.Provider.Model == "" {
+		return cfg.Provider.Name
+	}
+	return cfg.Provider.Name + "/" + cfg.Provider.Model
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+func runChat(ctx context.Context, client *gollm.Client, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("chat", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var r requestFlags
+	r.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: gollm chat [flags]\n\nType /reset to start over, /exit or end the input to quit.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var history []generator.Message
+	in := bufio.NewScanner(stdin)
+	in.Buffer(nil, 1<<20)
+	for {
+		fmt.Fprint(stdout, "> ")
+		if !in.Scan() {
+			fmt.Fprintln(stdout)
+			return in.Err()
+		}
+		line := strings.TrimSpace(in.Text())
+		switch line {
+		case "":
+			continue
+		case "/exit":
+			return nil
+		case "/reset":
+			history = nil
+			continue
+		}
+
+		user := generator.Message{Role: generator.USER, Content: line}
+		reply, err := printStream(ctx, client, r.request(append(history, user)...), stdout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// The conversation goes on, without the failed turn
+			fmt.Fprintln(stderr, "error:", err)
+			continue
+		}
+		history = append(history, user, generator.Message{Role: generator.ASSISTANT, Content: reply})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/reranker"
+)
+
+func runEmbed(ctx context.Context, client *gollm.Client, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("embed", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	model := fs.String("model", "", "embedding `model`")
+	dimensions := fs.Int("dimensions", 0, "`number` of dimensions, for models supporting several")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: gollm embed [flags] [text...]\n\nWithout arguments, each line of stdin is a text.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	texts, err := inputs(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	if len(texts) == 0 {
+		return errors.New("embed: no text given")
+	}
+
+	resp, err := client.Embed(ctx, &embedder.Request{Model: *model, Input: texts, Dimensions: *dimensions})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(stdout)
+	for _, d := range resp.Data {
+		if err := enc.Encode(d.Embedding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runRerank(ctx context.Context, client *gollm.Client, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("rerank", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	model := fs.String("model", "", "reranking `model`")
+	query := fs.String("query", "", "`text` the documents are ranked against")
+	topN := fs.Int("top-n", 0, "print only the `number` most relevant documents")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: gollm rerank -query text [flags] [document...]\n\nWithout arguments, each line of stdin is a document. Documents are\nprinted most relevant first, after their score.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *query == "" {
+		return errors.New("rerank: -query is required")
+	}
+	texts, err := inputs(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	if len(texts) == 0 {
+		return errors.New("rerank: no document given")
+	}
+
+	docs := make([]reranker.Document, len(texts))
+	for i, text := range texts {
+		docs[i] = reranker.Document{Text: text}
+	}
+	resp, err := client.Rerank(ctx, &reranker.Request{Model: *model, Query: *query, Documents: docs, TopN: *topN})
+	if err != nil {
+		return err
+	}
+	for _, r := range resp.Results {
+		if _, err := fmt.Fprintf(stdout, "%.4f\t%s\n", r.RelevanceScore, texts[r.Index]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inputs returns args, or else the non-empty lines of stdin
+func inputs(args []string, stdin io.Reader) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	var lines []string
+	in := bufio.NewScanner(stdin)
+	in.Buffer(nil, 1<<20)
+	for in.Scan() {
+		if line := strings.TrimSpace(in.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, in.Err()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// requestFlags represents the flags shaping generation requests
+type requestFlags struct {
+	system      string
+	maxTokens   int
+	temperature float64
+	prefill     string
+}
+
+func (r *requestFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&r.system, "system", "", "system `prompt`")
+	fs.IntVar(&r.maxTokens, "max-tokens", 0, "maximum `number` of tokens generated")
+	fs.Float64Var(&r.temperature, "temperature", 0, "sampling `temperature`, between 0 and 2")
+	fs.StringVar(&r.prefill, "prefill", "", "`text` starting the reply, for the model to continue")
+}
+
+// request returns a request of the flags with messages after the system
+// prompt
+func (r *requestFlags) request(messages ...generator.Message) *generator.Request {
+	req := &generator.Request{MaxTokens: r.maxTokens, Temperature: r.temperature, Prefill: r.prefill}
+	if r.system != "" {
+		req.Messages = append(req.Messages, generator.Message{Role: generator.SYSTEM, Content: r.system})
+	}
+	req.Messages = append(req.Messages, messages...)
+	return req
+}
+
+func runGenerate(ctx context.Context, client *gollm.Client, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var r requestFlags
+	r.register(fs)
+	stream := fs.Bool("stream", false, "print the reply as it is generated")
+	asJSON := fs.Bool("json", false, "print the whole response as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: gollm generate [flags] [prompt...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	prompt := strings.Join(fs.Args(), " ")
+	if prompt == "" {
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		prompt = strings.TrimSpace(string(b))
+	}
+	if prompt == "" {
+		return errors.New("generate: empty prompt")
+	}
+	req := r.request(generator.Message{Role: generator.USER, Content: prompt})
+
+	if *stream && !*asJSON {
+		_, err := printStream(ctx, client, req, stdout)
+		return err
+	}
+	resp, err := client.Generate(ctx, req)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(resp)
+	}
+	_, err = fmt.Fprintln(stdout, resp.Content)
+	return err
+}
+
+// printStream streams the reply to req to w, returning its content
+func printStream(ctx context.Context, client *gollm.Client, req *generator.Request, w io.Writer) (string, error) {
+	stream, err := client.GenerateStream(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	var content strings.Builder
+	for chunk := range stream {
+		if chunk.Err != nil {
+			return "", chunk.Err
+		}
+		content.WriteString(chunk.Content)
+		if _, err := io.WriteString(w, chunk.Content); err != nil {
+			return "", err
+		}
+	}
+	_, err = fmt.Fprintln(w)
+	return content.String(), err
+}
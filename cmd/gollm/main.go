@@ -0,0 +1,155 @@
+// Command gollm talks to LLM providers from the command line, for trying
+// providers and prompts without writing a program.
+//
+// Usage:
+//
+//	gollm [flags] <command> [command flags] [args]
+//
+// The commands are:
+//
+//	chat      converse interactively, streaming the replies
+//	generate  reply to a prompt, read from stdin without arguments
+//	embed     print the embedding of each text, one JSON array per line
+//	rerank    rank documents by relevance to a query
+//	bench     compare providers on a workload of prompts
+//
+// The provider is configured by the GOLLM_ environment variables read by
+// config.FromEnv, or by the file given with -config; the -provider, -model,
+// -api-key and -base-url flags override either. Run a command with -h for
+// its flags.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/config"
+
+	_ "github.com/parikxxit/go-llm/providers/mock"
+	_ "github.com/parikxxit/go-llm/providers/openai"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Args[1:], os.Environ(), os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "gollm:", err)
+		os.Exit(1)
+	}
+}
+
+// command represents a subcommand, running with the client configured and
+// its arguments
+type command struct {
+	summary string
+	run     func(ctx context.Context, client *gollm.Client, args []string, stdin io.Reader, stdout, stderr io.Writer) error
+	// standalone runs instead of run for commands configuring their own
+	// clients
+	standalone func(ctx context.Context, g globalFlags, environ, args []string, stdin io.Reader, stdout, stderr io.Writer) error
+}
+
+var commands = map[string]command{
+	"chat":     {summary: "converse interactively, streaming the replies", run: runChat},
+	"generate": {summary: "reply to a prompt, read from stdin without arguments", run: runGenerate},
+	"embed":    {summary: "print the embedding of each text, one JSON array per line", run: runEmbed},
+	"rerank":   {summary: "rank documents by relevance to a query", run: runRerank},
+	"bench":    {summary: "compare providers on a workload of prompts", standalone: runBench},
+}
+
+// globalFlags represents the flags configuring the client
+type globalFlags struct {
+	config   string
+	provider string
+	model    string
+	apiKey   string
+	baseURL  string
+	debug    bool
+}
+
+func run(ctx context.Context, args, environ []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("gollm", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var g globalFlags
+	fs.StringVar(&g.config, "config", "", "read the configuration from this YAML or JSON `file` instead of the environment")
+	fs.StringVar(&g.provider, "provider", "", "provider `name`, e.g. openai")
+	fs.StringVar(&g.model, "model", "", "default `model` of the provider")
+	fs.StringVar(&g.apiKey, "api-key", "", "API `key` of the provider")
+	fs.StringVar(&g.baseURL, "base-url", "", "base `URL` of the provider API")
+	fs.BoolVar(&g.debug, "debug", false, "log requests to stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: gollm [flags] <command> [command flags] [args]\n\nCommands:")
+		for _, name := range []string{"chat", "generate", "embed", "rerank", "bench"} {
+			fmt.Fprintf(stderr, "  %-9s %s\n", name, commands[name].summary)
+		}
+		fmt.Fprintln(stderr, "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no command given")
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+	if cmd.standalone != nil {
+		return cmd.standalone(ctx, g, environ, fs.Args()[1:], stdin, stdout, stderr)
+	}
+
+	cfg, err := loadConfig(g, environ)
+	if err != nil {
+		return err
+	}
+	client, err := cfg.NewClient()
+	if err != nil {
+		return err
+	}
+	return cmd.run(ctx, client, fs.Args()[1:], stdin, stdout, stderr)
+}
+
+// loadConfig reads the configuration of the file of g, or else of environ,
+// overridden by the flags of g
+func loadConfig(g globalFlags, environ []string) (*config.Config, error) {
+	if g.config == "" {
+		overrides := map[string]string{"PROVIDER": g.provider, "MODEL": g.model, "API_KEY": g.apiKey, "BASE_URL": g.baseURL}
+		for name, v := range overrides {
+			if v != "" {
+				environ = append(environ, config.EnvPrefix+name+"="+v)
+			}
+		}
+		if g.debug {
+			environ = append(environ, config.EnvPrefix+"DEBUG=true")
+		}
+		return config.FromEnviron(environ)
+	}
+
+	cfg, err := config.Load(g.config)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range []struct{ flag, field *string }{
+		{&g.provider, &cfg.Provider.Name},
+		{&g.model, &cfg.Provider.Model},
+		{&g.apiKey, &cfg.Provider.APIKey},
+		{&g.baseURL, &cfg.Provider.BaseURL},
+	} {
+		if *o.flag != "" {
+			*o.field = *o.flag
+		}
+	}
+	cfg.Debug = cfg.Debug || g.debug
+	return cfg, cfg.Validate()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/reranker"
+)
+
+// testProvider echoes like the mock, embeds texts as their length and ranks
+// documents by length, longest first
+type testProvider struct {
+	*mock.Mock
+}
+
+func init() {
+	generator.Register("cli-test", func(cfg generator.Config) (generator.Generator, error) {
+		m := mock.New()
+		m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+			var parts []string
+			for _, msg := range req.Messages {
+				parts = append(parts, string(msg.Role)+":"+msg.Content)
+			}
+			return &generator.Response{Content: cfg.Model + " " + strings.Join(parts, "|")}, nil
+		}
+		return testProvider{m}, nil
+	})
+}
+
+func (testProvider) Embed(_ context.Context, req *embedder.Request) (*embedder.Response, error) {
+	resp := &embedder.Response{}
+	for i, in := range req.Input {
+		resp.Data = append(resp.Data, embedder.EmbedData{Embedding: []float64{float64(len(in))}, Index: i})
+	}
+	return resp, nil
+}
+
+func (testProvider) GetEmbedderName() string { return "cli-test" }
+
+func (testProvider) Rerank(_ context.Context, req *reranker.Request) (*reranker.Response, error) {
+	resp := &reranker.Response{}
+	for i, d := range req.Documents {
+		resp.Results = append(resp.Results, reranker.Result{Index: i, RelevanceScore: float64(len(d.Text))})
+	}
+	sort.Slice(resp.Results, func(i, j int) bool { return resp.Results[i].RelevanceScore > resp.Results[j].RelevanceScore })
+	if req.TopN > 0 && req.TopN < len(resp.Results) {
+		resp.Results = resp.Results[:req.TopN]
+	}
+	return resp, nil
+}
+
+func (testProvider) GetRerankerName() string { return "cli-test" }
+
+func TestRun(t *testing.T) {
+	env := []string{"GOLLM_PROVIDER=cli-test", "GOLLM_MODEL=env-model"}
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		want  string
+	}{
+		{"generate", []string{"generate", "-system", "be brief", "hello", "there"}, "", "env-model system:be brief|user:hello there\n"},
+		{"generate stdin", []string{"-model", "flag-model", "generate", "-stream"}, "from stdin\n", "flag-model user:from stdin\n"},
+		{"chat", []string{"chat"}, "hi\nagain\n/reset\nfresh\n", "> env-model user:hi\n> env-model user:hi|assistant:env-model user:hi|user:again\n> > env-model user:fresh\n> \n"},
+		{"embed", []string{"embed", "a", "abc"}, "", "[1]\n[3]\n"},
+		{"rerank", []string{"rerank", "-query", "q", "-top-n", "2"}, "ab\na\nabc\n", "3.0000\tabc\n2.0000\tab\n"},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if err := run(context.Background(), tt.args, env, strings.NewReader(tt.stdin), &stdout, &stderr); err != nil {
+			t.Errorf("%s: run() error = %v, stderr %q", tt.name, err, stderr.String())
+			continue
+		}
+		if stdout.String() != tt.want {
+			t.Errorf("%s: run() printed %q, want %q", tt.name, stdout.String(), tt.want)
+		}
+	}
+}
+
+func TestRun_Config(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gollm.yaml")
+	os.WriteFile(path, []byte("provider:\n  name: cli-test\n  model: file-model\n"), 0o600)
+
+	var stdout bytes.Buffer
+	if err := run(context.Background(), []string{"-config", path, "generate", "hi"}, nil, nil, &stdout, &bytes.Buffer{}); err != nil || stdout.String() != "file-model user:hi\n" {
+		t.Errorf("run(-config) = %q, %v", stdout.String(), err)
+	}
+
+	for _, args := range [][]string{nil, {"unknown"}, {"rerank", "doc"}, {"-provider", "missing", "generate", "hi"}} {
+		if err := run(context.Background(), args, []string{"GOLLM_PROVIDER=cli-test"}, strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+			t.Errorf("run(%q) error = nil", args)
+		}
+	}
+}
+
+func TestRun_Bench(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yaml")
+	os.WriteFile(a, []byte("provider:\n  name: cli-test\n  model: a\n"), 0o600)
+	os.WriteFile(b, []byte("provider:\n  name: cli-test\n  model: b\n"), 0o600)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"bench", "-target", a, "-target", "second=" + b, "-n", "3", "-stream"}
+	if err := run(context.Background(), args, nil, strings.NewReader("one\ntwo\n"), &stdout, &stderr); err != nil {
+		t.Fatalf("run(bench) error = %v, stderr %q", err, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(strings.TrimSpace(lines[1]), "a ") || !strings.HasPrefix(strings.TrimSpace(lines[2]), "second ") {
+		t.Errorf("run(bench) printed\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := run(context.Background(), []string{"bench", "-n", "1", "hi"}, []string{"GOLLM_PROVIDER=cli-test", "GOLLM_MODEL=m"}, nil, &stdout, &stderr); err != nil || !strings.Contains(stdout.String(), "cli-test/m") {
+		t.Errorf("run(bench) = %q, %v, want the configured target", stdout.String(), err)
+	}
+}
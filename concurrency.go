@@ -0,0 +1,90 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// ErrQueueFull is returned when a call finds every concurrency slot taken
+// and the queue of waiting calls full
+var ErrQueueFull = errors.New("request queue full")
+
+// concurrencyLimit is a semaphore of in-flight provider calls
+type concurrencyLimit struct {
+	slots    chan struct{}
+	maxQueue int // Negative for unbounded
+	queued   atomic.Int64
+}
+
+// WithMaxConcurrency allows at most n provider calls in flight at once,
+// across all capabilities and providers of the client. Retries take a slot
+// per attempt and streams hold theirs until they close. Calls beyond n wait
+// for a free slot, as bounded by WithMaxQueue.
+func WithMaxConcurrency(n int) Option {
+	return func(c *Client) {
+		if n <= 0 {
+			c.concurrency = nil
+			return
+		}
+		c.concurrency = &concurrencyLimit{slots: make(chan struct{}, n), maxQueue: c.maxQueue}
+	}
+}
+
+// WithMaxQueue bounds the calls waiting for a slot of WithMaxConcurrency;
+// further calls fail at once with ErrQueueFull. Zero rejects every call
+// finding the slots taken; by default calls wait until their context ends.
+func WithMaxQueue(size int) Option {
+	return func(c *Client) {
+		c.maxQueue = size
+		if c.concurrency != nil {
+			// The slots may be shared with a client derived by With
+			c.concurrency = &concurrencyLimit{slots: c.concurrency.slots, maxQueue: size}
+		}
+	}
+}
+
+// acquire takes a concurrency slot, returning the function releasing it
+func (c *Client) acquire(ctx context.Context) (func(), error) {
+	l := c.concurrency
+	if l == nil {
+		return func() {}, nil
+	}
+	release := func() { <-l.slots }
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	if n := l.queued.Add(1); l.maxQueue >= 0 && n > int64(l.maxQueue) {
+		l.queued.Add(-1)
+		return nil, ErrQueueFull
+	}
+	defer l.queued.Add(-1)
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// releaseStream forwards stream, calling release once it closes or ctx ends
+func releaseStream(ctx context.Context, release func(), stream <-chan *generator.Response) <-chan *generator.Response {
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+		defer release()
+		for chunk := range stream {
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
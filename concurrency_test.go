@@ -0,0 +1,76 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_WithMaxConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return &generator.Response{Content: "ok"}, nil
+	}
+	client := NewClient(m, WithMaxConcurrency(2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}); err != nil {
+				t.Errorf("Generate() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if peak != 2 {
+		t.Errorf("peak in-flight calls = %d, want 2", peak)
+	}
+}
+
+func TestClient_WithMaxQueue(t *testing.T) {
+	started, block := make(chan struct{}), make(chan struct{})
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		started <- struct{}{}
+		<-block
+		return &generator.Response{Content: "ok"}, nil
+	}
+	client := NewClient(m, WithMaxQueue(0), WithMaxConcurrency(1))
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+
+	done := make(chan error)
+	go func() {
+		_, err := client.Generate(context.Background(), req)
+		done <- err
+	}()
+	<-started
+	if _, err := client.Generate(context.Background(), req); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Generate() while a call is in flight error = %v, want %v", err, ErrQueueFull)
+	}
+	close(block)
+	if err := <-done; err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	go func() { <-started }()
+	if _, err := client.Generate(context.Background(), req); err != nil {
+		t.Errorf("Generate() after the slot was released error = %v", err)
+	}
+}
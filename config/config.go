@@ -0,0 +1,319 @@
+// Package config builds a gollm.Client from a YAML or JSON file, or from
+// environment variables, so deployments can change providers, retries,
+// timeouts, caching and rate limits without code changes. Providers are
+// created through the registry of generator.Register: their packages must be
+// imported, e.g.
+//
+//	import _ "github.com/parikxxit/go-llm/providers/openai"
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/cache"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/transport"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// Config represents the configuration of a client. Zero values keep the
+// defaults of gollm.NewClient.
+//
+//	provider:
+//	  name: openai
+//	  model: gpt-4o
+//	  api_key: ${OPENAI_API_KEY}
+//	  http:
+//	    max_idle_conns_per_host: 128
+//	fallbacks:
+//	  - name: openai
+//	    model: gpt-4o-mini
+//	    api_key: ${OPENAI_API_KEY}
+//	retry:
+//	  count: 5
+//	  base_delay: 1s
+//	timeout: 20s
+//	timeouts:
+//	  generate_stream: 1m
+//	stream_buffer:
+//	  size: 64
+//	  policy: coalesce
+//	cache:
+//	  size: 1000
+//	  ttl: 1h
+//	rate_limit:
+//	  requests_per_minute: 500
+//	  tokens_per_minute: 200000
+type Config struct {
+	Provider  Provider   `yaml:"provider"`
+	Fallbacks []Provider `yaml:"fallbacks"`
+	Retry     Retry      `yaml:"retry"`
+	// Timeout is the default timeout of every operation
+	Timeout time.Duration `yaml:"timeout"`
+	// Timeouts override Timeout by operation, e.g. generate or
+	// generate_stream; see gollm.WithOperationTimeout
+	Timeouts       map[gollm.Operation]time.Duration `yaml:"timeouts"`
+	StreamDeadline time.Duration                     `yaml:"stream_deadline"`
+	StreamBuffer   *StreamBuffer                     `yaml:"stream_buffer"`
+	Cache          *Cache                            `yaml:"cache"`
+	RateLimit      *RateLimit                        `yaml:"rate_limit"`
+	MaxConcurrency int                               `yaml:"max_concurrency"`
+	// Budget is in dollars; see gollm.WithBudget
+	Budget float64 `yaml:"budget"`
+	Debug  bool    `yaml:"debug"`
+}
+
+// Provider represents a provider registered with generator.Register, and
+// the generator.Config it is created with
+type Provider struct {
+	Name    string            `yaml:"name"`
+	Model   string            `yaml:"model"`
+	APIKey  string            `yaml:"api_key"`
+	BaseURL string            `yaml:"base_url"`
+	Headers map[string]string `yaml:"headers"`
+	Timeout time.Duration     `yaml:"timeout"`
+	// HTTP tunes the connections to the provider; see transport.Config
+	HTTP *HTTP `yaml:"http"`
+}
+
+// HTTP represents the tuning of the HTTP transport of a provider; zero
+// fields take the defaults of the transport package
+type HTTP struct {
+	MaxIdleConns          int           `yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost   int           `yaml:"max_idle_conns_per_host"`
+	MaxConnsPerHost       int           `yaml:"max_conns_per_host"`
+	IdleConnTimeout       time.Duration `yaml:"idle_conn_timeout"`
+	KeepAlive             time.Duration `yaml:"keep_alive"`
+	DialTimeout           time.Duration `yaml:"dial_timeout"`
+	TLSHandshakeTimeout   time.Duration `yaml:"tls_handshake_timeout"`
+	ResponseHeaderTimeout time.Duration `yaml:"response_header_timeout"`
+	DisableHTTP2          bool          `yaml:"disable_http2"`
+}
+
+// Retry represents the retries of transient provider errors
+type Retry struct {
+	// Count is the number of retries, 3 when unset
+	Count     *int          `yaml:"count"`
+	BaseDelay time.Duration `yaml:"base_delay"`
+	MaxDelay  time.Duration `yaml:"max_delay"`
+}
+
+// StreamBuffer represents the buffering of streams, see
+// gollm.WithStreamBuffer
+type StreamBuffer struct {
+	Size int `yaml:"size"`
+	// Policy is block, drop or coalesce, block when empty
+	Policy gollm.Backpressure `yaml:"policy"`
+}
+
+// Cache represents an in-memory response cache, see gollm.WithCache
+type Cache struct {
+	// Size is the number of entries kept, the least recently used evicted
+	// first
+	Size int           `yaml:"size"`
+	TTL  time.Duration `yaml:"ttl"`
+}
+
+// RateLimit represents the pacing of provider requests, see
+// gollm.WithRateLimit
+type RateLimit struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	TokensPerMinute   int `yaml:"tokens_per_minute"`
+}
+
+// Load reads the configuration of a YAML or JSON file, see Parse
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return Parse(data)
+}
+
+// envRef matches the ${NAME} references of Parse
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Parse decodes a YAML or JSON configuration, JSON being a subset of YAML,
+// and validates it. ${NAME} references are replaced by the value of the
+// environment variable NAME beforehand, keeping secrets out of the file.
+// Unknown fields are an error, so a typo does not silently fall back to a
+// default.
+func Parse(data []byte) (*Config, error) {
+	data = envRef.ReplaceAllFunc(data, func(ref []byte) []byte {
+		return []byte(os.Getenv(string(ref[2 : len(ref)-1])))
+	})
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var c Config
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate reports the first invalid setting of c
+func (c *Config) Validate() error {
+	if err := c.Provider.validate("provider"); err != nil {
+		return err
+	}
+	for i, p := range c.Fallbacks {
+		if err := p.validate(fmt.Sprintf("fallbacks[%d]", i)); err != nil {
+			return err
+		}
+	}
+	if c.Retry.Count != nil && *c.Retry.Count < 0 {
+		return fmt.Errorf("config: retry.count is negative")
+	}
+	for op := range c.Timeouts {
+		switch op {
+		case gollm.OpGenerate, gollm.OpGenerateStream, gollm.OpEmbed, gollm.OpRerank:
+		default:
+			return fmt.Errorf("config: timeouts: unknown operation %q", op)
+		}
+	}
+	if b := c.StreamBuffer; b != nil {
+		if b.Size < 0 {
+			return fmt.Errorf("config: stream_buffer.size is negative")
+		}
+		switch b.Policy {
+		case "", gollm.BackpressureBlock, gollm.BackpressureDrop, gollm.BackpressureCoalesce:
+		default:
+			return fmt.Errorf("config: stream_buffer: unknown policy %q", b.Policy)
+		}
+	}
+	if c.Cache != nil && c.Cache.Size <= 0 {
+		return fmt.Errorf("config: cache.size must be positive")
+	}
+	if c.RateLimit != nil && (c.RateLimit.RequestsPerMinute < 0 || c.RateLimit.TokensPerMinute < 0) {
+		return fmt.Errorf("config: rate_limit is negative")
+	}
+	return nil
+}
+
+func (p Provider) validate(field string) error {
+	if p.Name == "" {
+		return fmt.Errorf("config: %s.name is required", field)
+	}
+	if h := p.HTTP; h != nil && (h.MaxIdleConns < 0 || h.MaxIdleConnsPerHost < 0 || h.MaxConnsPerHost < 0) {
+		return fmt.Errorf("config: %s.http: negative connection limit", field)
+	}
+	return nil
+}
+
+// generator creates the generator of p with the registry
+func (p Provider) generator(field string) (generator.Generator, error) {
+	cfg := generator.Config{
+		ApiKey:  p.APIKey,
+		Model:   p.Model,
+		BaseURL: p.BaseURL,
+		Headers: p.Headers,
+		Timeout: p.Timeout,
+	}
+	if h := p.HTTP; h != nil {
+		cfg.HTTPClient = transport.Client(transport.Config{
+			MaxIdleConns:          h.MaxIdleConns,
+			MaxIdleConnsPerHost:   h.MaxIdleConnsPerHost,
+			MaxConnsPerHost:       h.MaxConnsPerHost,
+			IdleConnTimeout:       h.IdleConnTimeout,
+			KeepAlive:             h.KeepAlive,
+			DialTimeout:           h.DialTimeout,
+			TLSHandshakeTimeout:   h.TLSHandshakeTimeout,
+			ResponseHeaderTimeout: h.ResponseHeaderTimeout,
+			DisableHTTP2:          h.DisableHTTP2,
+		})
+	}
+	g, err := generator.New(p.Name, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("config: %s: %w", field, err)
+	}
+	return g, nil
+}
+
+// Options returns the client options of c, creating its fallbacks
+func (c *Config) Options() ([]gollm.Option, error) {
+	var opts []gollm.Option
+	if len(c.Fallbacks) > 0 {
+		fallbacks := make([]generator.Generator, len(c.Fallbacks))
+		for i, p := range c.Fallbacks {
+			g, err := p.generator(fmt.Sprintf("fallbacks[%d]", i))
+			if err != nil {
+				return nil, err
+			}
+			fallbacks[i] = g
+		}
+		opts = append(opts, gollm.WithFallbackGenerators(fallbacks))
+	}
+	if c.Retry.Count != nil {
+		opts = append(opts, gollm.WithRetryCount(*c.Retry.Count))
+	}
+	if c.Retry.BaseDelay > 0 || c.Retry.MaxDelay > 0 {
+		base, max := c.Retry.BaseDelay, c.Retry.MaxDelay
+		if base <= 0 {
+			base = defaultRetryBaseDelay
+		}
+		if max <= 0 {
+			max = defaultRetryMaxDelay
+		}
+		opts = append(opts, gollm.WithRetryBackoff(base, max))
+	}
+	if c.Timeout > 0 {
+		opts = append(opts, gollm.WithTimeout(c.Timeout))
+	}
+	for op, d := range c.Timeouts {
+		opts = append(opts, gollm.WithOperationTimeout(op, d))
+	}
+	if c.StreamDeadline > 0 {
+		opts = append(opts, gollm.WithStreamDeadline(c.StreamDeadline))
+	}
+	if c.StreamBuffer != nil {
+		opts = append(opts, gollm.WithStreamBuffer(c.StreamBuffer.Size, c.StreamBuffer.Policy))
+	}
+	if c.Cache != nil {
+		opts = append(opts, gollm.WithCache(cache.NewLRU(c.Cache.Size), c.Cache.TTL))
+	}
+	if c.RateLimit != nil {
+		opts = append(opts, gollm.WithRateLimit(c.RateLimit.RequestsPerMinute, c.RateLimit.TokensPerMinute))
+	}
+	if c.MaxConcurrency > 0 {
+		opts = append(opts, gollm.WithMaxConcurrency(c.MaxConcurrency))
+	}
+	if c.Budget > 0 {
+		opts = append(opts, gollm.WithBudget(c.Budget))
+	}
+	if c.Debug {
+		opts = append(opts, gollm.WithDebug(true))
+	}
+	return opts, nil
+}
+
+// NewClient creates the client of c. opts are applied after the options of
+// c, e.g. for hooks or middleware, which are not configurable.
+func (c *Config) NewClient(opts ...gollm.Option) (*gollm.Client, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	llm, err := c.Provider.generator("provider")
+	if err != nil {
+		return nil, err
+	}
+	configured, err := c.Options()
+	if err != nil {
+		return nil, err
+	}
+	return gollm.New(llm, append(configured, opts...)...)
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+var calls atomic.Int32
+
+func init() {
+	// Named after the model, so fallbacks can be told apart
+	generator.Register("config-test", func(cfg generator.Config) (generator.Generator, error) {
+		m := mock.New()
+		m.Name = cfg.Model
+		m.GenerateFunc = func(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+			calls.Add(1)
+			return &generator.Response{Content: cfg.Model + ":" + cfg.ApiKey}, nil
+		}
+		return m, nil
+	})
+}
+
+const yamlConfig = `
+provider:
+  name: config-test
+  model: primary
+  api_key: ${CONFIG_TEST_KEY}
+  timeout: 10s
+  http:
+    max_idle_conns_per_host: 128
+    response_header_timeout: 2m
+fallbacks:
+  - name: config-test
+    model: backup
+retry:
+  count: 0
+  base_delay: 1ms
+timeouts:
+  generate_stream: 1m
+stream_buffer:
+  size: 16
+  policy: coalesce
+cache:
+  size: 10
+  ttl: 1h
+rate_limit:
+  requests_per_minute: 600
+`
+
+func TestParse(t *testing.T) {
+	t.Setenv("CONFIG_TEST_KEY", "secret")
+	c, err := Parse([]byte(yamlConfig))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if c.Provider.APIKey != "secret" || c.Provider.Timeout != 10*time.Second || c.Provider.HTTP.MaxIdleConnsPerHost != 128 || len(c.Fallbacks) != 1 {
+		t.Errorf("Parse() provider = %+v, fallbacks %+v", c.Provider, c.Fallbacks)
+	}
+	if *c.Retry.Count != 0 || c.Retry.BaseDelay != time.Millisecond || c.Timeouts[gollm.OpGenerateStream] != time.Minute {
+		t.Errorf("Parse() retry = %+v, timeouts %v", c.Retry, c.Timeouts)
+	}
+	if c.StreamBuffer.Size != 16 || c.StreamBuffer.Policy != gollm.BackpressureCoalesce {
+		t.Errorf("Parse() stream buffer = %+v", c.StreamBuffer)
+	}
+	if c.Cache.Size != 10 || c.RateLimit.RequestsPerMinute != 600 {
+		t.Errorf("Parse() cache = %+v, rate limit %+v", c.Cache, c.RateLimit)
+	}
+
+	c, err = Parse([]byte(`{"provider": {"name": "config-test", "model": "m"}, "timeout": "5s", "budget": 2.5}`))
+	if err != nil || c.Provider.Model != "m" || c.Timeout != 5*time.Second || c.Budget != 2.5 {
+		t.Errorf("Parse(JSON) = %+v, %v", c, err)
+	}
+
+	for _, tt := range []struct {
+		name, data, want string
+	}{
+		{"unknown field", "provider: {name: mock}\nretries: 3", "field retries not found"},
+		{"unknown nested field", "provider: {name: mock, apikey: k}", "field apikey not found"},
+		{"no provider", "timeout: 1s", "provider.name is required"},
+		{"unnamed fallback", "provider: {name: mock}\nfallbacks: [{model: m}]", "fallbacks[0].name is required"},
+		{"unknown operation", "provider: {name: mock}\ntimeouts: {chat: 1s}", `unknown operation "chat"`},
+		{"unknown backpressure", "provider: {name: mock}\nstream_buffer: {size: 8, policy: spill}", `unknown policy "spill"`},
+		{"negative connections", "provider: {name: mock, http: {max_conns_per_host: -1}}", "provider.http: negative connection limit"},
+		{"empty cache", "provider: {name: mock}\ncache: {ttl: 1m}", "cache.size"},
+		{"bad duration", "provider: {name: mock}\ntimeout: soon", "soon"},
+	} {
+		if _, err := Parse([]byte(tt.data)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%s) error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gollm.json")
+	if err := os.WriteFile(path, []byte(`{"provider": {"name": "mock"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := Load(path); err != nil || c.Provider.Name != "mock" {
+		t.Errorf("Load() = %+v, %v", c, err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Load(missing) error = nil")
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	c, err := FromEnviron([]string{
+		"GOLLM_PROVIDER=config-test",
+		"GOLLM_MODEL=primary",
+		"GOLLM_API_KEY=secret",
+		"GOLLM_FALLBACK1_PROVIDER=config-test",
+		"GOLLM_FALLBACK1_MODEL=backup",
+		"GOLLM_FALLBACK3_PROVIDER=skipped",
+		"GOLLM_RETRIES=0",
+		"GOLLM_GENERATE_STREAM_TIMEOUT=1m",
+		"GOLLM_CACHE_SIZE=10",
+		"GOLLM_RATE_LIMIT_TPM=1000",
+		"GOLLM_DEBUG=true",
+		"HOME=/root",
+	})
+	if err == nil || !strings.Contains(err.Error(), "GOLLM_FALLBACK3_PROVIDER") {
+		t.Errorf("FromEnviron() error = %v, want the fallback after a gap unknown", err)
+	}
+
+	c, err = FromEnviron([]string{
+		"GOLLM_PROVIDER=config-test",
+		"GOLLM_MODEL=primary",
+		"GOLLM_API_KEY=secret",
+		"GOLLM_MAX_IDLE_CONNS_PER_HOST=100",
+		"GOLLM_FALLBACK1_PROVIDER=config-test",
+		"GOLLM_FALLBACK1_MODEL=backup",
+		"GOLLM_RETRIES=0",
+		"GOLLM_GENERATE_STREAM_TIMEOUT=1m",
+		"GOLLM_CACHE_SIZE=10",
+		"GOLLM_RATE_LIMIT_TPM=1000",
+		"GOLLM_STREAM_BACKPRESSURE=drop",
+		"GOLLM_DEBUG=true",
+	})
+	if err != nil {
+		t.Fatalf("FromEnviron() error = %v", err)
+	}
+	if c.Provider.APIKey != "secret" || c.Provider.HTTP.MaxIdleConnsPerHost != 100 || len(c.Fallbacks) != 1 || c.Fallbacks[0].Model != "backup" || c.Fallbacks[0].HTTP != nil {
+		t.Errorf("FromEnviron() provider = %+v, fallbacks %+v", c.Provider, c.Fallbacks)
+	}
+	if *c.Retry.Count != 0 || c.Timeouts[gollm.OpGenerateStream] != time.Minute || c.Cache.Size != 10 || c.RateLimit.TokensPerMinute != 1000 || c.StreamBuffer.Policy != gollm.BackpressureDrop || !c.Debug {
+		t.Errorf("FromEnviron() = %+v", c)
+	}
+
+	if _, err := FromEnviron([]string{"GOLLM_PROVIDER=mock", "GOLLM_TIMEOUT=soon"}); err == nil || !strings.Contains(err.Error(), "GOLLM_TIMEOUT") {
+		t.Errorf("FromEnviron(bad duration) error = %v", err)
+	}
+	if _, err := FromEnviron(nil); err == nil {
+		t.Error("FromEnviron(empty) error = nil, want the provider required")
+	}
+}
+
+func TestConfig_NewClient(t *testing.T) {
+	t.Setenv("CONFIG_TEST_KEY", "secret")
+	c, err := Parse([]byte(yamlConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := c.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := context.Background()
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+	calls.Store(0)
+	for range 2 {
+		resp, err := client.Generate(ctx, req)
+		if err != nil || resp.Content != "primary:secret" {
+			t.Fatalf("Generate() = %v, %v, want primary:secret", resp, err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("provider calls = %d, want 1 with the cache", n)
+	}
+	if resp, err := client.Generate(ctx, req, gollm.CallWithProvider("backup")); err != nil || resp.Content != "backup:" {
+		t.Errorf("Generate(backup) = %v, %v", resp, err)
+	}
+
+	c.Fallbacks[0].Name = "missing"
+	if _, err := c.NewClient(); err == nil || !strings.Contains(err.Error(), "fallbacks[0]") {
+		t.Errorf("NewClient(unknown fallback) error = %v", err)
+	}
+}
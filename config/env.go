@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	gollm "github.com/parikxxit/go-llm"
+)
+
+// EnvPrefix prefixes the environment variables read by FromEnv
+const EnvPrefix = "GOLLM_"
+
+// FromEnv reads the configuration of the GOLLM_ environment variables:
+//
+//	GOLLM_PROVIDER, GOLLM_MODEL, GOLLM_API_KEY, GOLLM_BASE_URL,
+//	GOLLM_PROVIDER_TIMEOUT, GOLLM_MAX_IDLE_CONNS_PER_HOST,
+//	GOLLM_MAX_CONNS_PER_HOST, GOLLM_RESPONSE_HEADER_TIMEOUT
+//	                            the primary provider
+//	GOLLM_FALLBACK<N>_PROVIDER, GOLLM_FALLBACK<N>_MODEL, ...
+//	                            the fallbacks, from N=1 up to the first gap
+//	GOLLM_RETRIES, GOLLM_RETRY_BASE_DELAY, GOLLM_RETRY_MAX_DELAY
+//	GOLLM_TIMEOUT, GOLLM_GENERATE_TIMEOUT, GOLLM_GENERATE_STREAM_TIMEOUT,
+//	GOLLM_EMBED_TIMEOUT, GOLLM_RERANK_TIMEOUT, GOLLM_STREAM_DEADLINE
+//	GOLLM_STREAM_BUFFER, GOLLM_STREAM_BACKPRESSURE
+//	GOLLM_CACHE_SIZE, GOLLM_CACHE_TTL
+//	GOLLM_RATE_LIMIT_RPM, GOLLM_RATE_LIMIT_TPM
+//	GOLLM_MAX_CONCURRENCY, GOLLM_BUDGET, GOLLM_DEBUG
+//
+// Durations are in the format of time.ParseDuration. Unknown GOLLM_
+// variables are an error, as unknown fields are for Parse.
+func FromEnv() (*Config, error) {
+	return FromEnviron(os.Environ())
+}
+
+// FromEnviron is like FromEnv but reads environ, in the "key=value" form of
+// os.Environ; of variables set twice, the last one wins
+func FromEnviron(environ []string) (*Config, error) {
+	env := map[string]string{}
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(k, EnvPrefix) {
+			env[strings.TrimPrefix(k, EnvPrefix)] = v
+		}
+	}
+	r := envReader{env: env, read: map[string]bool{}}
+
+	var c Config
+	c.Provider = r.provider("")
+	for n := 1; ; n++ {
+		prefix := "FALLBACK" + strconv.Itoa(n) + "_"
+		if _, ok := env[prefix+"PROVIDER"]; !ok {
+			break
+		}
+		c.Fallbacks = append(c.Fallbacks, r.provider(prefix))
+	}
+
+	if _, ok := env["RETRIES"]; ok {
+		count := r.int("RETRIES")
+		c.Retry.Count = &count
+	}
+	c.Retry.BaseDelay = r.duration("RETRY_BASE_DELAY")
+	c.Retry.MaxDelay = r.duration("RETRY_MAX_DELAY")
+	c.Timeout = r.duration("TIMEOUT")
+	for _, op := range []gollm.Operation{gollm.OpGenerate, gollm.OpGenerateStream, gollm.OpEmbed, gollm.OpRerank} {
+		name := strings.ToUpper(string(op)) + "_TIMEOUT"
+		if _, ok := env[name]; ok {
+			if c.Timeouts == nil {
+				c.Timeouts = map[gollm.Operation]time.Duration{}
+			}
+			c.Timeouts[op] = r.duration(name)
+		}
+	}
+	c.StreamDeadline = r.duration("STREAM_DEADLINE")
+	_, size := env["STREAM_BUFFER"]
+	_, policy := env["STREAM_BACKPRESSURE"]
+	if size || policy {
+		c.StreamBuffer = &StreamBuffer{Size: r.int("STREAM_BUFFER"), Policy: gollm.Backpressure(r.string("STREAM_BACKPRESSURE"))}
+	}
+	if _, ok := env["CACHE_SIZE"]; ok {
+		c.Cache = &Cache{Size: r.int("CACHE_SIZE"), TTL: r.duration("CACHE_TTL")}
+	}
+	if rpm, tpm := r.int("RATE_LIMIT_RPM"), r.int("RATE_LIMIT_TPM"); rpm != 0 || tpm != 0 {
+		c.RateLimit = &RateLimit{RequestsPerMinute: rpm, TokensPerMinute: tpm}
+	}
+	c.MaxConcurrency = r.int("MAX_CONCURRENCY")
+	c.Budget = r.float("BUDGET")
+	c.Debug = r.bool("DEBUG")
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	for k := range env {
+		if !r.read[k] {
+			return nil, fmt.Errorf("config: unknown environment variable %s%s", EnvPrefix, k)
+		}
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// envReader parses variables, keeping the first error and the names read
+type envReader struct {
+	env  map[string]string
+	read map[string]bool
+	err  error
+}
+
+func (r *envReader) string(name string) string {
+	r.read[name] = true
+	return r.env[name]
+}
+
+func (r *envReader) provider(prefix string) Provider {
+	p := Provider{
+		Name:    r.string(prefix + "PROVIDER"),
+		Model:   r.string(prefix + "MODEL"),
+		APIKey:  r.string(prefix + "API_KEY"),
+		BaseURL: r.string(prefix + "BASE_URL"),
+		Timeout: r.duration(prefix + "PROVIDER_TIMEOUT"),
+	}
+	h := HTTP{
+		MaxIdleConnsPerHost:   r.int(prefix + "MAX_IDLE_CONNS_PER_HOST"),
+		MaxConnsPerHost:       r.int(prefix + "MAX_CONNS_PER_HOST"),
+		ResponseHeaderTimeout: r.duration(prefix + "RESPONSE_HEADER_TIMEOUT"),
+	}
+	if h != (HTTP{}) {
+		p.HTTP = &h
+	}
+	return p
+}
+
+func (r *envReader) int(name string) int {
+	v := r.string(name)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	r.fail(name, err)
+	return n
+}
+
+func (r *envReader) float(name string) float64 {
+	v := r.string(name)
+	if v == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	r.fail(name, err)
+	return f
+}
+
+func (r *envReader) bool(name string) bool {
+	v := r.string(name)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	r.fail(name, err)
+	return b
+}
+
+func (r *envReader) duration(name string) time.Duration {
+	v := r.string(name)
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	r.fail(name, err)
+	return d
+}
+
+func (r *envReader) fail(name string, err error) {
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("config: %s%s: %w", EnvPrefix, name, err)
+	}
+}
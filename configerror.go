@@ -0,0 +1,80 @@
+package gollm
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConfigError is returned by New for an invalid client configuration
+type ConfigError struct {
+	// Option is the option given the invalid value, e.g. "WithRetryCount"
+	Option string
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("gollm: invalid %s: %s", e.Option, e.Reason)
+}
+
+// validate reports the first invalid option value of c
+func (c *Client) validate() error {
+	invalid := func(option, format string, args ...any) error {
+		return &ConfigError{Option: option, Reason: fmt.Sprintf(format, args...)}
+	}
+	switch {
+	case c.retryCount < 0:
+		return invalid("WithRetryCount", "negative count %d", c.retryCount)
+	case c.retryBaseDelay < 0 || c.retryMaxDelay < 0:
+		return invalid("WithRetryBackoff", "negative delay")
+	case c.timeout <= 0:
+		return invalid("WithTimeout", "timeout %v is not positive", c.timeout)
+	case c.streamDeadline < 0:
+		return invalid("WithStreamDeadline", "negative deadline %v", c.streamDeadline)
+	case c.hedgeDelay < 0:
+		return invalid("WithHedging", "negative delay %v", c.hedgeDelay)
+	case c.streamResumes < 0:
+		return invalid("WithStreamResume", "negative attempts %d", c.streamResumes)
+	case c.streamBuffer < 0:
+		return invalid("WithStreamBuffer", "negative size %d", c.streamBuffer)
+	case c.backpressure != "" && c.backpressure != BackpressureBlock && c.backpressure != BackpressureDrop && c.backpressure != BackpressureCoalesce:
+		return invalid("WithStreamBuffer", "unknown policy %q", c.backpressure)
+	case c.embedBatchSize < 0:
+		return invalid("WithEmbedBatchSize", "negative size %d", c.embedBatchSize)
+	case c.embedConcurrency <= 0:
+		return invalid("WithEmbedConcurrency", "concurrency %d is not positive", c.embedConcurrency)
+	case c.rerankBatchSize < 0:
+		return invalid("WithRerankBatchSize", "negative size %d", c.rerankBatchSize)
+	case c.embedTokenLimit < 0:
+		return invalid("WithEmbedTokenLimit", "negative limit %d", c.embedTokenLimit)
+	case c.meter.budget < 0:
+		return invalid("WithBudget", "negative budget %v", c.meter.budget)
+	case c.meter.onSnapshot != nil && c.meter.snapshotEvery < time.Minute:
+		return invalid("WithUsageSnapshots", "interval %v under a minute", c.meter.snapshotEvery)
+	case c.rateLimits != nil && (c.rateLimits.requestsPerMinute < 0 || c.rateLimits.tokensPerMinute < 0):
+		return invalid("WithRateLimit", "negative limit")
+	case c.shadow != nil && (c.shadow.fraction < 0 || c.shadow.fraction > 1):
+		return invalid("WithShadow", "fraction %v out of [0, 1]", c.shadow.fraction)
+	}
+	for op, t := range c.timeouts {
+		// Zero disables the idle timeout of streams
+		if t < 0 || t == 0 && op != OpGenerateStream {
+			return invalid("WithOperationTimeout", "%s timeout %v", op, t)
+		}
+	}
+	for i, g := range c.fallbackGenerator {
+		if g == nil {
+			return invalid("WithFallbackGenerators", "generator %d is nil", i)
+		}
+	}
+	for i, e := range c.fallbackEmbedder {
+		if e == nil {
+			return invalid("WithFallbackEmbedders", "embedder %d is nil", i)
+		}
+	}
+	for i, r := range c.fallbackReranker {
+		if r == nil {
+			return invalid("WithFallbackRerankers", "reranker %d is nil", i)
+		}
+	}
+	return nil
+}
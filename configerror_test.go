@@ -0,0 +1,41 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestNew(t *testing.T) {
+	if c, err := New(mock.New(), WithRetryCount(0), WithOperationTimeout(OpGenerateStream, 0)); err != nil || c == nil {
+		t.Fatalf("New() = %v, %v", c, err)
+	}
+
+	tests := []struct {
+		name   string
+		llm    generator.Generator
+		opts   []Option
+		option string
+	}{
+		{"nil generator", nil, nil, "llm"},
+		{"negative retries", mock.New(), []Option{WithRetryCount(-1)}, "WithRetryCount"},
+		{"zero timeout", mock.New(), []Option{WithTimeout(0)}, "WithTimeout"},
+		{"zero operation timeout", mock.New(), []Option{WithOperationTimeout(OpEmbed, 0)}, "WithOperationTimeout"},
+		{"negative backoff", mock.New(), []Option{WithRetryBackoff(-time.Second, time.Second)}, "WithRetryBackoff"},
+		{"shadow fraction", mock.New(), []Option{WithShadow(mock.New(), 1.5, nil)}, "WithShadow"},
+		{"snapshot interval", mock.New(), []Option{WithUsageSnapshots(context.Background(), time.Second, func(UsageReport) {})}, "WithUsageSnapshots"},
+		{"nil fallback", mock.New(), []Option{WithFallbackGenerators([]generator.Generator{nil})}, "WithFallbackGenerators"},
+		{"unknown backpressure", mock.New(), []Option{WithStreamBuffer(8, "spill")}, "WithStreamBuffer"},
+	}
+	for _, tt := range tests {
+		c, err := New(tt.llm, tt.opts...)
+		var cerr *ConfigError
+		if c != nil || !errors.As(err, &cerr) || cerr.Option != tt.option {
+			t.Errorf("%s: New() = %v, %v, want a *ConfigError for %s", tt.name, c, err, tt.option)
+		}
+	}
+}
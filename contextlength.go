@@ -0,0 +1,55 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+)
+
+// Truncator shortens the messages of a request so they fit the model's
+// context window. chat.SlidingWindow and chat.SummarizeOldest implement it.
+type Truncator interface {
+	Truncate(ctx context.Context, req *generator.Request) ([]generator.Message, error)
+}
+
+// WithContextRecovery retries Generate and GenerateStream once with messages
+// shortened by t when the provider rejects a request with
+// llmerrors.ErrContextLengthExceeded. Recovery gives up when t leaves the
+// messages as they were, so truncators sized from token estimates should
+// keep some headroom, e.g. with SlidingWindow.ReserveTokens.
+func WithContextRecovery(t Truncator) Option {
+	return func(c *Client) {
+		c.truncator = t
+	}
+}
+
+// recoverable reports whether err can be recovered from by truncating
+func (c *Client) recoverable(err error) bool {
+	return c.truncator != nil && errors.Is(err, llmerrors.ErrContextLengthExceeded)
+}
+
+// truncate returns a copy of request with its messages shortened after it
+// failed with err, or err itself when the truncator makes no progress
+func (c *Client) truncate(ctx context.Context, request *generator.Request, err error) (*generator.Request, error) {
+	messages, terr := c.truncator.Truncate(ctx, request)
+	if terr != nil {
+		return nil, fmt.Errorf("%w (truncating messages: %v)", err, terr)
+	}
+	if len(messages) >= len(request.Messages) {
+		return nil, err
+	}
+
+	c.logger.InfoContext(ctx, "retrying with truncated messages", "model", request.Model,
+		"messages", len(request.Messages), "kept", len(messages), "error", err)
+	short := *request
+	short.Messages = messages
+	if short.IdempotencyKey != "" {
+		// A different request needs its own key, derived so that repeating
+		// the call is still deduplicated
+		short.IdempotencyKey += "-truncated"
+	}
+	return &short, nil
+}
@@ -0,0 +1,52 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+// dropOldest keeps the last n messages
+type dropOldest int
+
+func (n dropOldest) Truncate(_ context.Context, req *generator.Request) ([]generator.Message, error) {
+	return req.Messages[max(len(req.Messages)-int(n), 0):], nil
+}
+
+func TestClient_WithContextRecovery(t *testing.T) {
+	tooLong := &llmerrors.Error{Kind: llmerrors.ErrContextLengthExceeded, StatusCode: 400, Err: errors.New("too long")}
+	var sent []int
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		sent = append(sent, len(req.Messages))
+		if len(req.Messages) > 2 {
+			return nil, tooLong
+		}
+		return &generator.Response{Content: "ok"}, nil
+	}
+	req := &generator.Request{Messages: []generator.Message{
+		{Role: generator.USER, Content: "a"},
+		{Role: generator.ASSISTANT, Content: "b"},
+		{Role: generator.USER, Content: "c"},
+	}}
+
+	if _, err := NewClient(m).Generate(context.Background(), req); !errors.Is(err, llmerrors.ErrContextLengthExceeded) {
+		t.Errorf("Generate() without recovery error = %v, want ErrContextLengthExceeded", err)
+	}
+
+	sent = nil
+	resp, err := NewClient(m, WithContextRecovery(dropOldest(2))).Generate(context.Background(), req)
+	if err != nil || resp.Content != "ok" || len(sent) != 2 || sent[1] != 2 || len(req.Messages) != 3 {
+		t.Errorf("Generate() = %v, %v after sending %v messages", resp, err, sent)
+	}
+
+	sent = nil
+	_, err = NewClient(m, WithContextRecovery(dropOldest(3))).Generate(context.Background(), req)
+	if !errors.Is(err, llmerrors.ErrContextLengthExceeded) || len(sent) != 1 {
+		t.Errorf("Generate() with a truncator making no progress error = %v after sending %v messages", err, sent)
+	}
+}
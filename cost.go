@@ -0,0 +1,115 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/models"
+)
+
+// ErrBudgetExceeded is returned by calls made once the estimated cost of a
+// client reached its budget
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Usage represents the cumulative token usage and estimated cost of a client
+type Usage struct {
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Cost             float64 // Dollars, for models with known pricing
+}
+
+type usageMeter struct {
+	mu        sync.Mutex
+	usage     Usage
+	budget    float64
+	buckets   []*usageBucket
+	retention time.Duration
+
+	snapshotCtx   context.Context
+	snapshotEvery time.Duration
+	onSnapshot    func(UsageReport)
+}
+
+// WithBudget makes calls fail with ErrBudgetExceeded once the estimated cost
+// of the client reached dollars. Costs are only known after a call, so the
+// call crossing the budget completes.
+func WithBudget(dollars float64) Option {
+	return func(c *Client) {
+		c.meter.budget = dollars
+	}
+}
+
+// Usage returns the cumulative usage of the provider requests made by
+// Generate, GenerateStream and Embed, priced with the models registry
+func (c *Client) Usage() Usage {
+	c.meter.mu.Lock()
+	defer c.meter.mu.Unlock()
+	return c.meter.usage
+}
+
+// checkBudget returns ErrBudgetExceeded when the budget is spent
+func (c *Client) checkBudget() error {
+	c.meter.mu.Lock()
+	defer c.meter.mu.Unlock()
+	if c.meter.budget > 0 && c.meter.usage.Cost >= c.meter.budget {
+		return fmt.Errorf("%w: spent $%.4f of $%.4f", ErrBudgetExceeded, c.meter.usage.Cost, c.meter.budget)
+	}
+	return nil
+}
+
+// record adds the usage of a request to model by user, tagged with the
+// request metadata, and returns its cost
+func (c *Client) record(model, user string, tags map[string]string, u generator.TokenUsage) float64 {
+	cost := models.Cost(model, u.PromptTokens, u.CachedPromptTokens, u.CompletionTokens)
+	usage := Usage{
+		Requests:         1,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		Cost:             cost,
+	}
+
+	c.meter.mu.Lock()
+	defer c.meter.mu.Unlock()
+	c.meter.usage = c.meter.usage.add(usage)
+	c.meter.add(model, user, tags, usage)
+	return cost
+}
+
+// meterStream forwards stream, recording the usage of the chunk carrying it
+// and setting its Cost
+func (c *Client) meterStream(ctx context.Context, request *generator.Request, stream <-chan *generator.Response) <-chan *generator.Response {
+	model := request.Model
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+		for chunk := range stream {
+			if chunk.Usage != (generator.TokenUsage{}) {
+				if chunk.Model != "" {
+					model = chunk.Model
+				}
+				chunk.Cost = c.record(model, request.User, request.Metadata, chunk.Usage)
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// responseModel returns the model that answered, else the requested one
+func responseModel(answered, requested string) string {
+	if answered != "" {
+		return answered
+	}
+	return requested
+}
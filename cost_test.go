@@ -0,0 +1,52 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/models"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_Usage(t *testing.T) {
+	models.SetPricing("test-priced", models.Pricing{Input: 2, CachedInput: 1, Output: 10})
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return &generator.Response{
+			Model: "test-priced-2025-01-01",
+			Usage: generator.TokenUsage{PromptTokens: 1000, CachedPromptTokens: 500, CompletionTokens: 100, TotalTokens: 1100},
+		}, nil
+	}
+	client := NewClient(m, WithBudget(0.004))
+	req := &generator.Request{Model: "test-priced", Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+
+	resp, err := client.Generate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	// 500 * $2 + 500 * $1 + 100 * $10 per million tokens
+	if want := 0.0025; math.Abs(resp.Cost-want) > 1e-12 {
+		t.Errorf("Cost = %v, want %v", resp.Cost, want)
+	}
+
+	stream, err := client.GenerateStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	for chunk := range stream {
+		if chunk.Cost == 0 {
+			t.Errorf("stream chunk with usage has no cost")
+		}
+	}
+
+	usage := client.Usage()
+	if usage.Requests != 2 || usage.TotalTokens != 2200 || math.Abs(usage.Cost-0.005) > 1e-12 {
+		t.Errorf("Usage() = %+v", usage)
+	}
+	if _, err := client.Generate(context.Background(), req); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Generate() over budget error = %v, want ErrBudgetExceeded", err)
+	}
+}
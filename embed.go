@@ -0,0 +1,253 @@
+package gollm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/tokenizer"
+)
+
+const defaultEmbedConcurrency = 4
+
+// embedBatchLimit returns the configured batch size, else the embedder's
+func (c *Client) embedBatchLimit(e embedder.Embedder) int {
+	if c.embedBatchSize > 0 {
+		return c.embedBatchSize
+	}
+	if l, ok := e.(embedder.BatchLimiter); ok {
+		return l.MaxBatchSize()
+	}
+	return 0
+}
+
+// embedBatches splits request into batches of size inputs and merges their
+// responses. Texts and images are batched as one sequence, texts first, so
+// merged indexes match those of a single request. The first failing batch
+// cancels the others.
+func (c *Client) embedBatches(ctx context.Context, request *embedder.Request, size int) (*embedder.Response, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	texts := len(request.Input)
+	total := texts + len(request.Images)
+	n := (total + size - 1) / size
+	responses := make([]*embedder.Response, n)
+	errs := make([]error, n)
+
+	concurrency := c.embedConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultEmbedConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		lo, hi := i*size, min((i+1)*size, total)
+		batch := *request
+		batch.Input = request.Input[min(lo, texts):min(hi, texts)]
+		batch.Images = request.Images[max(lo-texts, 0):max(hi-texts, 0)]
+		if len(batch.Images) == 0 {
+			batch.Images = nil
+		}
+
+		wg.Add(1)
+		go func(i int, batch *embedder.Request) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			responses[i], errs[i] = c.embedOnce(ctx, batch)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i, &batch)
+	}
+	wg.Wait()
+
+	merged := &embedder.Response{Data: make([]embedder.EmbedData, 0, total)}
+	for i, resp := range responses {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("embedding batch %d of %d: %w", i+1, n, errs[i])
+		}
+		merged.Object, merged.Model = resp.Object, resp.Model
+		merged.Usage.PromptTokens += resp.Usage.PromptTokens
+		merged.Usage.TotalTokens += resp.Usage.TotalTokens
+		for _, d := range resp.Data {
+			d.Index += i * size
+			merged.Data = append(merged.Data, d)
+		}
+	}
+	return merged, nil
+}
+
+// Overflow represents how embedding inputs over the token limit are handled
+type Overflow string
+
+const (
+	// TruncateHead keeps the beginning of the input
+	TruncateHead Overflow = "truncate_head"
+	// TruncateTail keeps the end of the input
+	TruncateTail Overflow = "truncate_tail"
+	// SplitAverage embeds chunks of the input and averages their embeddings,
+	// weighted by token count, into one unit vector
+	SplitAverage Overflow = "split_average"
+)
+
+// embedWithinLimit fits inputs to the token limit before embedding them.
+// Split inputs are embedded as consecutive chunks merged back afterwards.
+func (c *Client) embedWithinLimit(ctx context.Context, request *embedder.Request) (*embedder.Response, error) {
+	fitted := *request
+	fitted.Input = make([]string, 0, len(request.Input))
+	// spans[i] is the range of chunks of input i in fitted.Input
+	spans := make([][2]int, len(request.Input))
+	var weights []int
+	split := false
+	counter := c.embedCounter
+	if counter == nil {
+		counter = tokenizer.ForModel(request.Model)
+	}
+	for i, in := range request.Input {
+		var chunks []string
+		switch {
+		case counter.Count(in) <= c.embedTokenLimit:
+			chunks = []string{in}
+		case c.embedOverflow == TruncateHead:
+			chunks = []string{in[:fitPrefix(in, c.embedTokenLimit, counter)]}
+		case c.embedOverflow == TruncateTail:
+			chunks = []string{in[fitSuffix(in, c.embedTokenLimit, counter):]}
+		case c.embedOverflow == SplitAverage:
+			chunks = splitTokens(in, c.embedTokenLimit, counter)
+			split = true
+		default:
+			return nil, fmt.Errorf("unknown embedding overflow %q", c.embedOverflow)
+		}
+		spans[i] = [2]int{len(fitted.Input), len(fitted.Input) + len(chunks)}
+		for _, chunk := range chunks {
+			fitted.Input = append(fitted.Input, chunk)
+			weights = append(weights, max(counter.Count(chunk), 1))
+		}
+	}
+	if split && !request.WantsOnlyDense() {
+		return nil, fmt.Errorf("split embedding inputs support dense outputs only")
+	}
+
+	resp, err := c.embedAll(ctx, &fitted)
+	if err != nil || !split {
+		return resp, err
+	}
+
+	chunks := make([]embedder.EmbedData, len(fitted.Input)+len(fitted.Images))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(chunks) {
+			return nil, fmt.Errorf("embedder returned out of range index %d", d.Index)
+		}
+		chunks[d.Index] = d
+	}
+	merged := *resp
+	merged.Data = make([]embedder.EmbedData, 0, len(request.Input)+len(request.Images))
+	for i, span := range spans {
+		d := averageEmbeddings(chunks[span[0]:span[1]], weights[span[0]:span[1]], request.Float32)
+		d.Index = i
+		merged.Data = append(merged.Data, d)
+	}
+	for i, d := range chunks[len(fitted.Input):] {
+		d.Index = len(request.Input) + i
+		merged.Data = append(merged.Data, d)
+	}
+	return &merged, nil
+}
+
+// averageEmbeddings returns the weighted mean of chunks scaled to unit length
+func averageEmbeddings(chunks []embedder.EmbedData, weights []int, float32s bool) embedder.EmbedData {
+	if len(chunks) == 1 {
+		return chunks[0]
+	}
+	var sum []float64
+	for k, d := range chunks {
+		v := d.Vector64()
+		if sum == nil {
+			sum = make([]float64, len(v))
+		}
+		for j := range sum[:min(len(sum), len(v))] {
+			sum[j] += float64(weights[k]) * v[j]
+		}
+	}
+	d := embedder.EmbedData{Object: chunks[0].Object}
+	if float32s {
+		d.Embedding32 = embedder.Float32(sum)
+	} else {
+		d.Embedding = sum
+	}
+	return embedder.Normalize(d)
+}
+
+// fitPrefix returns the length of the longest prefix of text, cut at a rune
+// boundary, within limit tokens
+func fitPrefix(text string, limit int, counter tokenizer.Counter) int {
+	runes := runeOffsets(text)
+	// Binary search the number of runes kept
+	lo, hi := 0, len(runes)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if counter.Count(text[:runes[mid]]) <= limit {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return runes[lo]
+}
+
+// fitSuffix returns the start of the longest suffix of text within limit
+// tokens
+func fitSuffix(text string, limit int, counter tokenizer.Counter) int {
+	runes := runeOffsets(text)
+	lo, hi := 0, len(runes)-1
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if counter.Count(text[runes[mid]:]) <= limit {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return runes[lo]
+}
+
+// splitTokens cuts text into chunks within limit tokens, preferring to cut
+// after whitespace in the second half of a chunk
+func splitTokens(text string, limit int, counter tokenizer.Counter) []string {
+	var chunks []string
+	for text != "" {
+		n := fitPrefix(text, limit, counter)
+		if n == 0 {
+			// A single rune over the limit is sent as is
+			_, n = utf8.DecodeRuneInString(text)
+		}
+		if n < len(text) {
+			if cut := strings.LastIndexAny(text[:n], " \t\n"); cut >= n/2 {
+				n = cut + 1
+			}
+		}
+		chunks = append(chunks, text[:n])
+		text = text[n:]
+	}
+	return chunks
+}
+
+// runeOffsets returns the byte offset of every rune of text, plus its length
+func runeOffsets(text string) []int {
+	offsets := make([]int, 0, len(text)+1)
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	return append(offsets, len(text))
+}
@@ -0,0 +1,112 @@
+package embedder
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/parikxxit/go-llm/vecmath"
+)
+
+// Float32 converts v to float32
+func Float32(v []float64) []float32 {
+	if v == nil {
+		return nil
+	}
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = float32(x)
+	}
+	return out
+}
+
+// Float64 converts v to float64
+func Float64(v []float32) []float64 {
+	if v == nil {
+		return nil
+	}
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = float64(x)
+	}
+	return out
+}
+
+// DecodeBase64 decodes a base64 string of packed little-endian float32
+// values, the format OpenAI-compatible APIs return for base64 encoding
+func DecodeBase64(s string) ([]float32, error) {
+	// The bytes are only scratch space, the floats are the caller's
+	buf := decodePool.Get().(*[]byte)
+	defer decodePool.Put(buf)
+	if n := base64.StdEncoding.DecodedLen(len(s)); cap(*buf) < n {
+		*buf = make([]byte, n)
+	}
+	n, err := base64.StdEncoding.Decode((*buf)[:cap(*buf)], []byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("decoding base64 embedding: %w", err)
+	}
+	b := (*buf)[:n]
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("decoding base64 embedding: %d bytes is not a multiple of 4", len(b))
+	}
+	out := make([]float32, len(b)/4)
+	for i := range out {
+		out[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return out, nil
+}
+
+var decodePool = sync.Pool{New: func() any { return new([]byte) }}
+
+// EncodeBase64 encodes v as packed little-endian float32 values in base64
+func EncodeBase64(v []float32) string {
+	b := make([]byte, len(v)*4)
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(x))
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// Truncate shortens the embedding to its first dims dimensions and rescales
+// it to unit length. Models trained with Matryoshka representation learning
+// keep most of their accuracy when truncated this way. Embeddings already at
+// most dims long are returned unchanged.
+func Truncate(d EmbedData, dims int) EmbedData {
+	if dims <= 0 {
+		return d
+	}
+	if len(d.Embedding32) > dims {
+		d.Embedding32 = vecmath.Normalize(append([]float32(nil), d.Embedding32[:dims]...))
+	}
+	if len(d.Embedding) > dims {
+		d.Embedding = normalize64(append([]float64(nil), d.Embedding[:dims]...))
+	}
+	return d
+}
+
+// Normalize returns the embedding rescaled to unit length
+func Normalize(d EmbedData) EmbedData {
+	if d.Embedding32 != nil {
+		d.Embedding32 = vecmath.Normalized(d.Embedding32)
+	}
+	if d.Embedding != nil {
+		d.Embedding = normalize64(append([]float64(nil), d.Embedding...))
+	}
+	return d
+}
+
+// normalize64 scales v to unit length in place
+func normalize64(v []float64) []float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	if norm := math.Sqrt(sum); norm > 0 {
+		for i := range v {
+			v[i] /= norm
+		}
+	}
+	return v
+}
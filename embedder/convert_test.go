@@ -0,0 +1,73 @@
+package embedder
+
+import "testing"
+
+func TestBase64(t *testing.T) {
+	v := []float32{0.5, -1.25, 3}
+	got, err := DecodeBase64(EncodeBase64(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range v {
+		if got[i] != v[i] {
+			t.Errorf("DecodeBase64()[%d] = %v, want %v", i, got[i], v[i])
+		}
+	}
+	if _, err := DecodeBase64("AAA="); err == nil {
+		t.Error("DecodeBase64() of 2 bytes error = nil")
+	}
+}
+
+func TestEmbedData_Vector32(t *testing.T) {
+	d := EmbedData{Embedding: []float64{1, 2}}
+	if v := d.Vector32(); len(v) != 2 || v[1] != 2 {
+		t.Errorf("Vector32() = %v", v)
+	}
+	d = EmbedData{Embedding32: []float32{3}}
+	if v := d.Vector64(); len(v) != 1 || v[0] != 3 {
+		t.Errorf("Vector64() = %v", v)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	d := Truncate(EmbedData{Embedding32: []float32{3, 4, 12}}, 2)
+	if v := d.Embedding32; len(v) != 2 || v[0] != 0.6 || v[1] != 0.8 {
+		t.Errorf("Truncate() = %v, want [0.6 0.8]", v)
+	}
+	d = Truncate(EmbedData{Embedding: []float64{3, 4, 12}}, 2)
+	if v := d.Embedding; len(v) != 2 || v[0] != 0.6 || v[1] != 0.8 {
+		t.Errorf("Truncate() = %v, want [0.6 0.8]", v)
+	}
+	full := []float64{1, 1}
+	if d := Truncate(EmbedData{Embedding: full}, 4); d.Embedding[0] != 1 {
+		t.Errorf("Truncate() of a short vector = %v, want it unchanged", d.Embedding)
+	}
+}
+
+func BenchmarkDecodeBase64(b *testing.B) {
+	s := EncodeBase64(make([]float32, 1536))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeBase64(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSparseVector_Dot(t *testing.T) {
+	a := SparseVector{Indices: []int{1, 4, 9}, Values: []float32{1, 2, 3}}
+	b := SparseVector{Indices: []int{0, 4, 9, 12}, Values: []float32{5, 0.5, 2, 7}}
+	if got := a.Dot(b); got != 7 {
+		t.Errorf("Dot() = %v, want 7", got)
+	}
+}
+
+func TestImage_DataURL(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	if got, want := (Image{Data: png}).DataURL(), "data:image/png;base64,iVBORw0KGgo="; got != want {
+		t.Errorf("DataURL() = %q, want %q", got, want)
+	}
+	if got := (Image{URL: "https://example.com/a.jpg"}).DataURL(); got != "https://example.com/a.jpg" {
+		t.Errorf("DataURL() = %q", got)
+	}
+}
@@ -3,6 +3,8 @@ package embedder
 
 import (
 	"context"
+	"encoding/base64"
+	"net/http"
 )
 
 // TokenUsage represents token usage information
@@ -11,18 +13,124 @@ type TokenUsage struct {
 	TotalTokens  int
 }
 
-// EmbedData represents embedding data
+// Encoding represents the wire format embeddings are requested in
+type Encoding string
+
+const (
+	EncodingFloat  Encoding = "float"
+	EncodingBase64 Encoding = "base64" // Packed little-endian float32, smaller on the wire
+)
+
+// Output represents a kind of embedding
+type Output string
+
+const (
+	OutputDense       Output = "dense"
+	OutputSparse      Output = "sparse"       // Lexical weights, as from SPLADE or BGE-M3
+	OutputMultiVector Output = "multi_vector" // One vector per token, for ColBERT-style late interaction
+)
+
+// SparseVector represents a sparse embedding as parallel slices of
+// vocabulary indices, in increasing order, and their weights
+type SparseVector struct {
+	Indices []int
+	Values  []float32
+}
+
+// Dot returns the inner product of two sparse vectors
+func (v SparseVector) Dot(o SparseVector) float32 {
+	var sum float32
+	for i, j := 0, 0; i < len(v.Indices) && j < len(o.Indices); {
+		switch {
+		case v.Indices[i] < o.Indices[j]:
+			i++
+		case v.Indices[i] > o.Indices[j]:
+			j++
+		default:
+			sum += v.Values[i] * o.Values[j]
+			i++
+			j++
+		}
+	}
+	return sum
+}
+
+// EmbedData represents embedding data. For dense outputs exactly one of
+// Embedding and Embedding32 is set, depending on Request.Float32; Sparse and
+// MultiVector are set when requested in Request.Outputs.
 type EmbedData struct {
-	Object    string
-	Embedding []float64
-	Index     int
+	Object      string
+	Embedding   []float64
+	Embedding32 []float32
+	Sparse      *SparseVector
+	MultiVector [][]float32
+	Index       int
+}
+
+// Vector32 returns the embedding as float32, converting it if needed
+func (d EmbedData) Vector32() []float32 {
+	if d.Embedding32 != nil {
+		return d.Embedding32
+	}
+	return Float32(d.Embedding)
+}
+
+// Vector64 returns the embedding as float64, converting it if needed
+func (d EmbedData) Vector64() []float64 {
+	if d.Embedding != nil {
+		return d.Embedding
+	}
+	return Float64(d.Embedding32)
+}
+
+// Image represents an image input of multimodal embedding models, referenced
+// by URL or given as bytes
+type Image struct {
+	URL  string
+	Data []byte
+	// MediaType is the MIME type of Data, detected from its content when empty
+	MediaType string
+}
+
+// DataURL returns the URL of the image, or Data encoded as a data URL
+func (i Image) DataURL() string {
+	if i.URL != "" {
+		return i.URL
+	}
+	return "data:" + i.ContentType() + ";base64," + base64.StdEncoding.EncodeToString(i.Data)
+}
+
+// ContentType returns MediaType, or the type detected from Data
+func (i Image) ContentType() string {
+	if i.MediaType != "" {
+		return i.MediaType
+	}
+	return http.DetectContentType(i.Data)
 }
 
 // Request represents an embedding request
 type Request struct {
-	Model          string
-	Input          []string
-	Dimensions     int
+	Model string
+	Input []string
+	// Images are embedded after Input by multimodal models: the embedding of
+	// Images[i] has index len(Input)+i. They require an embedder implementing
+	// ImageSupporter.
+	Images     []Image
+	Dimensions int
+	// Truncate shortens returned embeddings to this many dimensions and
+	// renormalizes them client-side, for Matryoshka models or providers
+	// without a Dimensions parameter. Zero keeps them as returned.
+	Truncate int
+	// Float32 returns embeddings in EmbedData.Embedding32, halving the memory
+	// of large corpora
+	Float32 bool
+	// EncodingFormat is the wire format requested from providers supporting
+	// several; providers pick the most efficient one when empty
+	EncodingFormat Encoding
+	// Outputs are the kinds of embedding to return, only OutputDense when
+	// empty. Kinds other than dense require an embedder implementing
+	// OutputSupporter.
+	Outputs        []Output
 	User           string
 	ProviderParams map[string]interface{}
 }
@@ -43,3 +151,39 @@ type Embedder interface {
 	// GetName returns the name of the implementation
 	GetEmbedderName() string
 }
+
+// BatchLimiter is implemented by embedders that cap the number of inputs per
+// request. The client splits larger requests into batches of at most
+// MaxBatchSize inputs.
+type BatchLimiter interface {
+	MaxBatchSize() int
+}
+
+// OutputSupporter is implemented by embedders returning kinds of embedding
+// other than dense ones
+type OutputSupporter interface {
+	SupportsOutput(Output) bool
+}
+
+// ImageSupporter is implemented by multimodal embedders accepting images
+type ImageSupporter interface {
+	SupportsImages() bool
+}
+
+// Supports reports whether emb can return embeddings of kind out
+func Supports(emb Embedder, out Output) bool {
+	if s, ok := emb.(OutputSupporter); ok {
+		return s.SupportsOutput(out)
+	}
+	return out == OutputDense
+}
+
+// WantsOnlyDense reports whether req asks for dense embeddings only
+func (r *Request) WantsOnlyDense() bool {
+	for _, out := range r.Outputs {
+		if out != OutputDense {
+			return false
+		}
+	}
+	return true
+}
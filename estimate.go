@@ -0,0 +1,59 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/models"
+	"github.com/parikxxit/go-llm/tokenizer"
+)
+
+// Estimate represents the estimated size and cost of a request
+type Estimate struct {
+	Model        string
+	PromptTokens int
+	// CompletionTokens is the most tokens of the response: Request.MaxTokens,
+	// or else what the model can generate in the context window left
+	CompletionTokens int
+	MaxCost          float64 // Dollars, 0 when the pricing is unknown
+	ContextWindow    int     // 0 when unknown
+	// Fits reports whether the prompt and MaxTokens fit the context window;
+	// always true when the window is unknown
+	Fits bool
+	// Known reports whether the model is in the models registry
+	Known bool
+}
+
+// Estimate estimates the prompt tokens of request, counted locally with the
+// tokenizer of its model, and the cost of the longest response from the
+// models registry, without calling any provider. Request.Model must be set.
+func (c *Client) Estimate(ctx context.Context, request *generator.Request) (*Estimate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if request.Model == "" {
+		return nil, errors.New("estimate: request has no model")
+	}
+	info, known := models.Lookup(request.Model)
+	e := &Estimate{
+		Model:         request.Model,
+		PromptTokens:  tokenizer.CountRequest(tokenizer.ForModel(request.Model), request),
+		ContextWindow: info.ContextWindow,
+		Fits:          true,
+		Known:         known,
+	}
+
+	e.CompletionTokens = request.MaxTokens
+	if e.CompletionTokens == 0 {
+		e.CompletionTokens = info.MaxOutputTokens
+		if left := info.ContextWindow - e.PromptTokens; info.ContextWindow > 0 && (e.CompletionTokens == 0 || left < e.CompletionTokens) {
+			e.CompletionTokens = max(left, 0)
+		}
+	}
+	if info.ContextWindow > 0 {
+		e.Fits = e.PromptTokens+request.MaxTokens <= info.ContextWindow
+	}
+	e.MaxCost = models.Cost(request.Model, e.PromptTokens, 0, e.CompletionTokens)
+	return e, nil
+}
@@ -0,0 +1,60 @@
+package gollm
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/models"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_Estimate(t *testing.T) {
+	models.Register(models.Info{Name: "estimate-chat", ContextWindow: 1000, MaxOutputTokens: 100, Pricing: models.Pricing{Input: 1, Output: 2}})
+	m := mock.New()
+	client := NewClient(m)
+	msg := func(words int) []generator.Message {
+		return []generator.Message{{Role: generator.USER, Content: strings.Repeat("word ", words)}}
+	}
+
+	tests := []struct {
+		name           string
+		request        *generator.Request
+		wantCompletion int
+		wantFits       bool
+	}{
+		{"max output", &generator.Request{Model: "estimate-chat", Messages: msg(10)}, 100, true},
+		{"max tokens", &generator.Request{Model: "estimate-chat", Messages: msg(10), MaxTokens: 50}, 50, true},
+		{"window left", &generator.Request{Model: "estimate-chat", Messages: msg(750)}, -1, true},
+		{"too long", &generator.Request{Model: "estimate-chat", Messages: msg(10), MaxTokens: 1000}, 1000, false},
+	}
+	for _, tt := range tests {
+		e, err := client.Estimate(context.Background(), tt.request)
+		if err != nil {
+			t.Fatalf("%s: Estimate() error = %v", tt.name, err)
+		}
+		want := tt.wantCompletion
+		if want < 0 {
+			want = 1000 - e.PromptTokens
+		}
+		if e.PromptTokens == 0 || e.CompletionTokens != want || e.Fits != tt.wantFits || !e.Known {
+			t.Errorf("%s: Estimate() = %+v, want %d completion tokens and Fits %v", tt.name, e, want, tt.wantFits)
+		}
+		if cost := float64(e.PromptTokens+2*e.CompletionTokens) / 1e6; math.Abs(e.MaxCost-cost) > 1e-12 {
+			t.Errorf("%s: Estimate().MaxCost = %v, want %v", tt.name, e.MaxCost, cost)
+		}
+	}
+
+	e, err := client.Estimate(context.Background(), &generator.Request{Model: "unknown-model", Messages: msg(10)})
+	if err != nil || e.Known || !e.Fits || e.MaxCost != 0 || e.CompletionTokens != 0 {
+		t.Errorf("Estimate(unknown model) = %+v, %v", e, err)
+	}
+	if _, err := client.Estimate(context.Background(), &generator.Request{Messages: msg(1)}); err == nil {
+		t.Error("Estimate() without a model error = nil")
+	}
+	if len(m.Requests()) != 0 {
+		t.Errorf("Estimate() made %d provider requests, want none", len(m.Requests()))
+	}
+}
@@ -0,0 +1,373 @@
+// Package eval evaluates a client on datasets of cases: each input is sent
+// to the model and its output scored by evaluators, e.g. exact match,
+// embedding similarity to the expected output, JSON diff or a model judging
+// it against a rubric. The report has the per-case scores and aggregate
+// metrics:
+//
+//	ds, err := eval.LoadJSONL("testdata/faq.jsonl")
+//	report, err := eval.Run(ctx, client, ds, []eval.Evaluator{eval.ExactMatch{IgnoreCase: true}})
+//	report.Write(os.Stdout)
+//
+// Golden checks outputs against golden files instead, for prompt regression
+// tests under go test with RunGolden.
+package eval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+const defaultConcurrency = 4
+
+// Case represents an input and the output expected for it
+type Case struct {
+	Name     string            `json:"name,omitempty"`
+	Input    string            `json:"input"`
+	Expected string            `json:"expected,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Dataset represents a named set of cases
+type Dataset struct {
+	Name  string
+	Cases []Case
+}
+
+// LoadJSONL loads a dataset of a case per line, named after the file
+func LoadJSONL(path string) (*Dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ds := &Dataset{Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		var c Case
+		if err := json.Unmarshal(sc.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		ds.Cases = append(ds.Cases, c)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// Score represents the evaluation of an output
+type Score struct {
+	// Value is from 0, worst, to 1, best
+	Value  float64
+	Pass   bool
+	Reason string
+}
+
+// Evaluator scores the output of the model for a case
+type Evaluator interface {
+	Name() string
+	Evaluate(ctx context.Context, c Case, output string) (Score, error)
+}
+
+// Result represents the outcome of a case
+type Result struct {
+	Case     Case
+	Output   string
+	Response *generator.Response
+	Latency  time.Duration
+	// Scores are by evaluator name; evaluators failing are missing
+	Scores map[string]Score
+	// Err is the error generating the output or the first error evaluating
+	// it
+	Err error
+}
+
+// Passed reports whether the case was scored by every evaluator and passed
+// them all
+func (r *Result) Passed() bool {
+	if r.Err != nil {
+		return false
+	}
+	for _, s := range r.Scores {
+		if !s.Pass {
+			return false
+		}
+	}
+	return true
+}
+
+// Metric aggregates the scores of an evaluator
+type Metric struct {
+	Count    int
+	Mean     float64
+	Min      float64
+	PassRate float64
+}
+
+// Report represents the outcome of a dataset
+type Report struct {
+	Dataset string
+	Results []Result
+	// Metrics are by evaluator name
+	Metrics map[string]Metric
+	// Errors counts the cases with an error
+	Errors int
+	// Usage and Cost add up generation, evaluators excluded
+	Usage    generator.TokenUsage
+	Cost     float64
+	Duration time.Duration
+
+	evaluators []string
+}
+
+// PassRate returns the share of cases that passed every evaluator
+func (r *Report) PassRate() float64 {
+	if len(r.Results) == 0 {
+		return 0
+	}
+	passed := 0
+	for i := range r.Results {
+		if r.Results[i].Passed() {
+			passed++
+		}
+	}
+	return float64(passed) / float64(len(r.Results))
+}
+
+// Write writes the report as a table of cases and metrics
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "CASE\tPASS\t%s\n", strings.Join(upper(r.evaluators), "\t"))
+	for i, res := range r.Results {
+		name := res.Case.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		pass := "yes"
+		if !res.Passed() {
+			pass = "no"
+		}
+		fmt.Fprintf(tw, "%s\t%s", name, pass)
+		for _, e := range r.evaluators {
+			if s, ok := res.Scores[e]; ok {
+				fmt.Fprintf(tw, "\t%.2f", s.Value)
+			} else {
+				fmt.Fprint(tw, "\t-")
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "EVALUATOR\tMEAN\tMIN\tPASS RATE")
+	for _, e := range r.evaluators {
+		m := r.Metrics[e]
+		fmt.Fprintf(tw, "%s\t%.3f\t%.3f\t%.1f%%\n", e, m.Mean, m.Min, 100*m.PassRate)
+	}
+	fmt.Fprintf(tw, "\n%s: %d cases, %.1f%% passed, %d errors, %d tokens, $%.4f, %s\n",
+		r.Dataset, len(r.Results), 100*r.PassRate(), r.Errors, r.Usage.TotalTokens, r.Cost, r.Duration.Round(time.Millisecond))
+	return tw.Flush()
+}
+
+func upper(names []string) []string {
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = strings.ToUpper(n)
+	}
+	return out
+}
+
+type runner struct {
+	model        string
+	systemPrompt string
+	request      func(Case) *generator.Request
+	concurrency  int
+}
+
+// Option is a function that configures Run
+type Option func(*runner)
+
+// WithModel sets the model evaluated
+func WithModel(model string) Option {
+	return func(r *runner) {
+		r.model = model
+	}
+}
+
+// WithSystemPrompt sends prompt before the input of every case
+func WithSystemPrompt(prompt string) Option {
+	return func(r *runner) {
+		r.systemPrompt = prompt
+	}
+}
+
+// WithRequest builds the request of each case, replacing the user message
+// of its input after WithSystemPrompt; WithModel still applies when the
+// request has no model
+func WithRequest(f func(Case) *generator.Request) Option {
+	return func(r *runner) {
+		r.request = f
+	}
+}
+
+// WithConcurrency sets the number of cases run at once, 4 by default or
+// when n is not positive
+func WithConcurrency(n int) Option {
+	return func(r *runner) {
+		r.concurrency = n
+	}
+}
+
+// Run runs the cases of ds through client and scores the outputs with
+// evaluators. Failing cases are reported in their result; Run only fails
+// without cases or evaluators, or when ctx ends.
+func Run(ctx context.Context, client *gollm.Client, ds *Dataset, evaluators []Evaluator, opts ...Option) (*Report, error) {
+	if len(ds.Cases) == 0 {
+		return nil, errors.New("eval: dataset has no cases")
+	}
+	if len(evaluators) == 0 {
+		return nil, errors.New("eval: no evaluators")
+	}
+	r := &runner{concurrency: defaultConcurrency}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	start := time.Now()
+	results := make([]Result, len(ds.Cases))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	concurrency := r.concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	for w := 0; w < concurrency && w < len(ds.Cases); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = r.run(ctx, client, ds.Cases[i], evaluators)
+			}
+		}()
+	}
+send:
+	for i := range ds.Cases {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Dataset: ds.Name, Results: results, Metrics: map[string]Metric{}, Duration: time.Since(start)}
+	for _, e := range evaluators {
+		report.evaluators = append(report.evaluators, e.Name())
+	}
+	for _, res := range results {
+		if res.Err != nil {
+			report.Errors++
+		}
+		if res.Response != nil {
+			addUsage(&report.Usage, res.Response.Usage)
+			report.Cost += res.Response.Cost
+		}
+	}
+	for _, name := range report.evaluators {
+		report.Metrics[name] = metric(results, name)
+	}
+	return report, nil
+}
+
+func (r *runner) run(ctx context.Context, client *gollm.Client, c Case, evaluators []Evaluator) Result {
+	res := Result{Case: c, Scores: map[string]Score{}}
+	req := r.buildRequest(c)
+	start := time.Now()
+	resp, err := client.Generate(ctx, req)
+	res.Latency = time.Since(start)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.Response, res.Output = resp, resp.Content
+
+	for _, e := range evaluators {
+		s, err := e.Evaluate(ctx, c, res.Output)
+		if err != nil {
+			if res.Err == nil {
+				res.Err = fmt.Errorf("%s: %w", e.Name(), err)
+			}
+			continue
+		}
+		res.Scores[e.Name()] = s
+	}
+	return res
+}
+
+func (r *runner) buildRequest(c Case) *generator.Request {
+	if r.request != nil {
+		req := r.request(c)
+		if req.Model == "" {
+			req.Model = r.model
+		}
+		return req
+	}
+	req := &generator.Request{Model: r.model}
+	if r.systemPrompt != "" {
+		req.Messages = append(req.Messages, generator.Message{Role: generator.SYSTEM, Content: r.systemPrompt})
+	}
+	req.Messages = append(req.Messages, generator.Message{Role: generator.USER, Content: c.Input})
+	return req
+}
+
+func metric(results []Result, name string) Metric {
+	var m Metric
+	sum, passed := 0.0, 0
+	for _, res := range results {
+		s, ok := res.Scores[name]
+		if !ok {
+			continue
+		}
+		if m.Count == 0 || s.Value < m.Min {
+			m.Min = s.Value
+		}
+		m.Count++
+		sum += s.Value
+		if s.Pass {
+			passed++
+		}
+	}
+	if m.Count > 0 {
+		m.Mean, m.PassRate = sum/float64(m.Count), float64(passed)/float64(m.Count)
+	}
+	return m
+}
+
+func addUsage(u *generator.TokenUsage, v generator.TokenUsage) {
+	u.PromptTokens += v.PromptTokens
+	u.CachedPromptTokens += v.CachedPromptTokens
+	u.CompletionTokens += v.CompletionTokens
+	u.ReasoningTokens += v.ReasoningTokens
+	u.TotalTokens += v.TotalTokens
+}
@@ -0,0 +1,122 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+// answers returns a mock answering inputs from answers, failing others
+func answers(answers map[string]string) *mock.Mock {
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		a, ok := answers[req.Messages[len(req.Messages)-1].Content]
+		if !ok {
+			return nil, errors.New("no answer")
+		}
+		return &generator.Response{Content: a, Usage: generator.TokenUsage{TotalTokens: 10}}, nil
+	}
+	return m
+}
+
+func TestRun(t *testing.T) {
+	client := gollm.NewClient(answers(map[string]string{"2+2": "4", "capital of France": "paris", "3+3": "7"}), gollm.WithEmbedder(mock.NewEmbedder(16)))
+	ds := &Dataset{Name: "quiz", Cases: []Case{
+		{Name: "sum", Input: "2+2", Expected: "4"},
+		{Name: "capital", Input: "capital of France", Expected: "Paris"},
+		{Name: "wrong", Input: "3+3", Expected: "6"},
+		{Name: "failing", Input: "unknown", Expected: "?"},
+	}}
+	report, err := Run(context.Background(), client, ds, []Evaluator{ExactMatch{IgnoreCase: true}, EmbeddingSimilarity{Client: client}}, WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(report.Results) != 4 || report.Errors != 1 || report.Usage.TotalTokens != 30 {
+		t.Fatalf("Run() = %+v, want 4 results, 1 error and 30 tokens", report)
+	}
+	for i, want := range []bool{true, false, false, false} {
+		if got := report.Results[i].Passed(); got != want {
+			t.Errorf("case %s passed = %v, want %v: %+v", ds.Cases[i].Name, got, want, report.Results[i])
+		}
+	}
+	if m := report.Metrics["exact_match"]; m.Count != 3 || m.PassRate < 0.66 || m.PassRate > 0.67 {
+		t.Errorf("exact_match metric = %+v, want 2 of 3 passing", m)
+	}
+	// The hash embedder scores only identical texts as similar
+	if m := report.Metrics["embedding_similarity"]; m.Count != 3 || m.Min >= 0.8 {
+		t.Errorf("embedding_similarity metric = %+v", m)
+	}
+	if p := report.PassRate(); p != 0.25 {
+		t.Errorf("PassRate() = %v, want 0.25", p)
+	}
+
+	var out bytes.Buffer
+	if err := report.Write(&out); err != nil || !strings.Contains(out.String(), "quiz: 4 cases, 25.0% passed, 1 errors") {
+		t.Errorf("Write() = %v, wrote\n%s", err, out.String())
+	}
+}
+
+func TestRun_Concurrency(t *testing.T) {
+	client := gollm.NewClient(answers(map[string]string{"2+2": "4"}))
+	ds := &Dataset{Cases: []Case{{Input: "2+2", Expected: "4"}, {Input: "2+2", Expected: "4"}}}
+	for _, n := range []int{0, -1} {
+		report, err := Run(context.Background(), client, ds, []Evaluator{ExactMatch{}}, WithConcurrency(n))
+		if err != nil || report.PassRate() != 1 {
+			t.Errorf("Run() with concurrency %d = %+v, %v, want every case passing", n, report, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, client, ds, []Evaluator{ExactMatch{}}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() of a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestJSONDiff(t *testing.T) {
+	tests := []struct {
+		output string
+		value  float64
+		pass   bool
+	}{
+		{`{"b": [1, 2], "a": "x"}`, 1, true},
+		{"```json\n{\"a\": \"x\", \"b\": [1, 3], \"c\": 1}\n```", 0.5, false},
+		{`{"a": "x", "b": [1, 2], "c": true}`, 0.75, false},
+		{`not json`, 0, false},
+	}
+	for _, tt := range tests {
+		s, err := JSONDiff{}.Evaluate(context.Background(), Case{Expected: `{"a": "x", "b": [1, 2]}`}, tt.output)
+		if err != nil || s.Value != tt.value || s.Pass != tt.pass {
+			t.Errorf("Evaluate(%q) = %+v, %v, want value %v", tt.output, s, err, tt.value)
+		}
+	}
+}
+
+func TestJudge(t *testing.T) {
+	m := mock.New().Enqueue(mock.Text(`{"reasoning": "Correct but terse.", "grade": 4}`))
+	s, err := Judge{Client: gollm.NewClient(m), Rubric: "Be helpful."}.Evaluate(context.Background(), Case{Input: "2+2", Expected: "4"}, "4")
+	if err != nil || s.Value != 0.75 || !s.Pass || s.Reason != "Correct but terse." {
+		t.Errorf("Evaluate() = %+v, %v, want a passing 0.75", s, err)
+	}
+	if prompt := m.LastRequest().Messages[0].Content; !strings.Contains(prompt, "Rubric: Be helpful.") || !strings.Contains(prompt, "Expected output:\n4") {
+		t.Errorf("judge prompt = %q", prompt)
+	}
+}
+
+func TestLoadJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "faq.jsonl")
+	os.WriteFile(path, []byte("{\"name\": \"a\", \"input\": \"hi\", \"expected\": \"hello\"}\n\n{\"input\": \"bye\"}\n"), 0o644)
+	ds, err := LoadJSONL(path)
+	if err != nil || ds.Name != "faq" || len(ds.Cases) != 2 || ds.Cases[0].Expected != "hello" {
+		t.Errorf("LoadJSONL() = %+v, %v", ds, err)
+	}
+}
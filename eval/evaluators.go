@@ -0,0 +1,219 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/guardrails"
+	"github.com/parikxxit/go-llm/validate"
+	"github.com/parikxxit/go-llm/vecmath"
+)
+
+const (
+	defaultSimilarityThreshold = 0.8
+	defaultJudgeThreshold      = 0.75
+)
+
+// ExactMatch passes outputs equal to the expected output, surrounding
+// whitespace aside
+type ExactMatch struct {
+	IgnoreCase bool
+}
+
+func (ExactMatch) Name() string { return "exact_match" }
+
+func (e ExactMatch) Evaluate(_ context.Context, c Case, output string) (Score, error) {
+	got, want := strings.TrimSpace(output), strings.TrimSpace(c.Expected)
+	if got == want || (e.IgnoreCase && strings.EqualFold(got, want)) {
+		return Score{Value: 1, Pass: true}, nil
+	}
+	return Score{Reason: fmt.Sprintf("got %q, want %q", got, want)}, nil
+}
+
+// EmbeddingSimilarity scores outputs by the cosine similarity of their
+// embedding to the embedding of the expected output, embedded with the
+// client's embedder
+type EmbeddingSimilarity struct {
+	Client *gollm.Client
+	Model  string
+	// Threshold is the similarity passing, 0.8 when zero
+	Threshold float64
+}
+
+func (EmbeddingSimilarity) Name() string { return "embedding_similarity" }
+
+func (e EmbeddingSimilarity) Evaluate(ctx context.Context, c Case, output string) (Score, error) {
+	sim, err := Similarity(ctx, e.Client, e.Model, output, c.Expected)
+	if err != nil {
+		return Score{}, err
+	}
+	threshold := e.Threshold
+	if threshold == 0 {
+		threshold = defaultSimilarityThreshold
+	}
+	s := Score{Value: max(sim, 0), Pass: sim >= threshold}
+	if !s.Pass {
+		s.Reason = fmt.Sprintf("similarity %.3f under %.3f", sim, threshold)
+	}
+	return s, nil
+}
+
+// Similarity returns the cosine similarity of the embeddings of a and b
+func Similarity(ctx context.Context, client *gollm.Client, model, a, b string) (float64, error) {
+	resp, err := client.Embed(ctx, &embedder.Request{Model: model, Input: []string{a, b}})
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Data) != 2 {
+		return 0, fmt.Errorf("embedder returned %d embeddings for 2 inputs", len(resp.Data))
+	}
+	vectors := make([][]float32, 2)
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index > 1 {
+			return 0, fmt.Errorf("embedder returned index %d for 2 inputs", d.Index)
+		}
+		vectors[d.Index] = d.Vector32()
+	}
+	return float64(vecmath.CosineSimilarity(vectors[0], vectors[1])), nil
+}
+
+// JSONDiff compares outputs to the expected output as JSON, optionally in a
+// Markdown code fence. The value is the share of leaf values, by path, that
+// match; outputs pass when equal.
+type JSONDiff struct{}
+
+func (JSONDiff) Name() string { return "json_diff" }
+
+func (JSONDiff) Evaluate(_ context.Context, c Case, output string) (Score, error) {
+	var want, got any
+	if err := json.Unmarshal([]byte(guardrails.StripCodeFence(c.Expected)), &want); err != nil {
+		return Score{}, fmt.Errorf("expected output is not valid JSON: %w", err)
+	}
+	if err := json.Unmarshal([]byte(guardrails.StripCodeFence(output)), &got); err != nil {
+		return Score{Reason: fmt.Sprintf("output is not valid JSON: %v", err)}, nil
+	}
+
+	wantLeaves, gotLeaves := map[string]any{}, map[string]any{}
+	leaves(want, "$", wantLeaves)
+	leaves(got, "$", gotLeaves)
+	paths := map[string]bool{}
+	for p := range wantLeaves {
+		paths[p] = true
+	}
+	for p := range gotLeaves {
+		paths[p] = true
+	}
+	var diffs []string
+	for p := range paths {
+		w, inWant := wantLeaves[p]
+		g, inGot := gotLeaves[p]
+		switch {
+		case !inGot:
+			diffs = append(diffs, fmt.Sprintf("%s missing", p))
+		case !inWant:
+			diffs = append(diffs, fmt.Sprintf("%s unexpected", p))
+		case !reflect.DeepEqual(w, g):
+			diffs = append(diffs, fmt.Sprintf("%s: got %v, want %v", p, g, w))
+		}
+	}
+	if len(diffs) == 0 {
+		return Score{Value: 1, Pass: true}, nil
+	}
+	sort.Strings(diffs)
+	return Score{Value: 1 - float64(len(diffs))/float64(len(paths)), Reason: strings.Join(diffs, "; ")}, nil
+}
+
+// leaves sets the scalar values of v, and its empty objects and arrays, in
+// out by path
+func leaves(v any, path string, out map[string]any) {
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			out[path] = v
+		}
+		for k, e := range v {
+			leaves(e, path+"."+k, out)
+		}
+	case []any:
+		if len(v) == 0 {
+			out[path] = v
+		}
+		for i, e := range v {
+			leaves(e, fmt.Sprintf("%s[%d]", path, i), out)
+		}
+	default:
+		out[path] = v
+	}
+}
+
+// DefaultRubric is the rubric of judges without one
+const DefaultRubric = "The response answers the input correctly and completely, " +
+	"agrees with the expected output when one is given, and contains nothing false or irrelevant."
+
+// Judge has a model grade outputs against a rubric, from 1 to 5; the value
+// is the grade scaled to 0 to 1
+type Judge struct {
+	Client *gollm.Client
+	Model  string
+	// Rubric states what a good output is, DefaultRubric when empty
+	Rubric string
+	// Threshold is the value passing, 0.75, a grade of 4, when zero
+	Threshold float64
+}
+
+func (Judge) Name() string { return "judge" }
+
+var judgeSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"reasoning": map[string]interface{}{"type": "string"},
+		"grade":     map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 5},
+	},
+	"required": []interface{}{"reasoning", "grade"},
+}
+
+func (j Judge) Evaluate(ctx context.Context, c Case, output string) (Score, error) {
+	if j.Client == nil {
+		return Score{}, errors.New("judge has no client")
+	}
+	rubric := j.Rubric
+	if rubric == "" {
+		rubric = DefaultRubric
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Grade the response to the input below against the rubric, from 1, worst, to 5, best.\n\nRubric: %s\n\nInput:\n%s\n", rubric, c.Input)
+	if c.Expected != "" {
+		fmt.Fprintf(&b, "\nExpected output:\n%s\n", c.Expected)
+	}
+	fmt.Fprintf(&b, "\nResponse:\n%s\n\nReply with a JSON object only, of the fields reasoning, a short justification, then grade, an integer from 1 to 5.", output)
+
+	resp, err := j.Client.GenerateValidated(ctx, &generator.Request{
+		Model:    j.Model,
+		Messages: []generator.Message{{Role: generator.USER, Content: b.String()}},
+	}, gollm.ValidateOptions{Validators: []validate.Validator{validate.JSONSchema(judgeSchema)}})
+	if err != nil {
+		return Score{}, err
+	}
+	var verdict struct {
+		Reasoning string `json:"reasoning"`
+		Grade     int    `json:"grade"`
+	}
+	if err := json.Unmarshal([]byte(guardrails.StripCodeFence(resp.Content)), &verdict); err != nil {
+		return Score{}, err
+	}
+
+	threshold := j.Threshold
+	if threshold == 0 {
+		threshold = defaultJudgeThreshold
+	}
+	value := float64(verdict.Grade-1) / 4
+	return Score{Value: value, Pass: value >= threshold, Reason: verdict.Reasoning}, nil
+}
@@ -0,0 +1,152 @@
+package eval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+)
+
+const defaultGoldenThreshold = 0.9
+
+// UpdateGoldenEnv names the environment variable that, set to 1, has golden
+// evaluators rewrite their files, e.g. after an intended prompt change:
+//
+//	GOLLM_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "GOLLM_UPDATE_GOLDEN"
+
+// Golden compares outputs to the outputs recorded in golden files, a file
+// per case, flagging semantic drift when prompts, models or providers
+// change: outputs pass as long as their embedding similarity to the
+// recorded output reaches Threshold. Missing files are recorded.
+type Golden struct {
+	// Dir holds the golden files, testdata/golden when empty
+	Dir string
+	// Client embeds outputs with its embedder; without a client outputs
+	// must equal the recorded ones
+	Client *gollm.Client
+	Model  string
+	// Threshold is the similarity passing, 0.9 when zero
+	Threshold float64
+	// Update rewrites the files, as does UpdateGoldenEnv
+	Update bool
+}
+
+type goldenFile struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+func (Golden) Name() string { return "golden" }
+
+func (g Golden) Evaluate(ctx context.Context, c Case, output string) (Score, error) {
+	path := g.Path(c)
+	data, err := os.ReadFile(path)
+	if g.Update || os.Getenv(UpdateGoldenEnv) == "1" || errors.Is(err, os.ErrNotExist) {
+		if err := writeGolden(path, goldenFile{Input: c.Input, Output: output}); err != nil {
+			return Score{}, err
+		}
+		return Score{Value: 1, Pass: true, Reason: "recorded " + path}, nil
+	}
+	if err != nil {
+		return Score{}, err
+	}
+	var f goldenFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return Score{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if f.Output == output {
+		return Score{Value: 1, Pass: true}, nil
+	}
+
+	changed := ""
+	if f.Input != c.Input {
+		changed = "; the input changed since it was recorded"
+	}
+	if g.Client == nil {
+		return Score{Reason: fmt.Sprintf("output differs from %s%s", path, changed)}, nil
+	}
+	sim, err := Similarity(ctx, g.Client, g.Model, output, f.Output)
+	if err != nil {
+		return Score{}, err
+	}
+	threshold := g.Threshold
+	if threshold == 0 {
+		threshold = defaultGoldenThreshold
+	}
+	s := Score{Value: max(sim, 0), Pass: sim >= threshold}
+	if !s.Pass {
+		s.Reason = fmt.Sprintf("similarity %.3f to %s under %.3f%s; got %q, recorded %q", sim, path, threshold, changed, output, f.Output)
+	}
+	return s, nil
+}
+
+// Path returns the golden file of c, named after the case, or a hash of its
+// input for cases without a name
+func (g Golden) Path(c Case) string {
+	dir := g.Dir
+	if dir == "" {
+		dir = filepath.Join("testdata", "golden")
+	}
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, c.Name)
+	if name == "" {
+		sum := sha256.Sum256([]byte(c.Input))
+		name = "case-" + hex.EncodeToString(sum[:6])
+	}
+	return filepath.Join(dir, name+".golden.json")
+}
+
+func writeGolden(path string, f goldenFile) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// RunGolden runs ds with g as a regression test: every case is a subtest of
+// t failing on errors and drift. Golden files default to
+// testdata/golden/<dataset name>.
+func RunGolden(t *testing.T, client *gollm.Client, ds *Dataset, g Golden, opts ...Option) *Report {
+	t.Helper()
+	if g.Dir == "" {
+		g.Dir = filepath.Join("testdata", "golden", ds.Name)
+	}
+	report, err := Run(context.Background(), client, ds, []Evaluator{g}, opts...)
+	if err != nil {
+		t.Fatalf("eval: %v", err)
+	}
+	for i, res := range report.Results {
+		name := res.Case.Name
+		if name == "" {
+			name = fmt.Sprintf("case%d", i+1)
+		}
+		t.Run(name, func(t *testing.T) {
+			if res.Err != nil {
+				t.Fatal(res.Err)
+			}
+			if s := res.Scores[g.Name()]; !s.Pass {
+				t.Errorf("drifted from golden output: %s", s.Reason)
+			} else if s.Reason != "" {
+				t.Log(s.Reason)
+			}
+		})
+	}
+	return report
+}
@@ -0,0 +1,60 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestGolden_Evaluate(t *testing.T) {
+	client := gollm.NewClient(mock.New(), gollm.WithEmbedder(mock.NewEmbedder(16)))
+	g := Golden{Dir: t.TempDir(), Client: client}
+	c := Case{Name: "greeting/en", Input: "Say hello"}
+
+	if s, err := g.Evaluate(context.Background(), c, "Hello!"); err != nil || !s.Pass || !strings.HasPrefix(s.Reason, "recorded") {
+		t.Fatalf("Evaluate() without a golden file = %+v, %v, want it recorded", s, err)
+	}
+	if _, err := os.Stat(filepath.Join(g.Dir, "greeting_en.golden.json")); err != nil {
+		t.Errorf("golden file not written: %v", err)
+	}
+	if s, err := g.Evaluate(context.Background(), c, "Hello!"); err != nil || !s.Pass || s.Value != 1 {
+		t.Errorf("Evaluate() of the golden output = %+v, %v, want a pass", s, err)
+	}
+
+	// The hash embedder scores different texts far apart
+	c.Input = "Say hello politely"
+	s, err := g.Evaluate(context.Background(), c, "Go away.")
+	if err != nil || s.Pass || !strings.Contains(s.Reason, "the input changed") {
+		t.Errorf("Evaluate() of a drifted output = %+v, %v, want a failure noting the input change", s, err)
+	}
+
+	g.Update = true
+	if s, _ := g.Evaluate(context.Background(), c, "Go away."); !s.Pass {
+		t.Errorf("Evaluate() updating = %+v, want a pass", s)
+	}
+	g.Update = false
+	if s, _ := g.Evaluate(context.Background(), c, "Go away."); !s.Pass {
+		t.Errorf("Evaluate() after the update = %+v, want a pass", s)
+	}
+}
+
+func TestRunGolden(t *testing.T) {
+	client := gollm.NewClient(mock.New())
+	ds := &Dataset{Name: "echo", Cases: []Case{{Name: "a", Input: "first"}, {Input: "second"}}}
+	dir := t.TempDir()
+	for range 2 {
+		report := RunGolden(t, client, ds, Golden{Dir: dir})
+		if report.PassRate() != 1 {
+			t.Errorf("RunGolden() pass rate = %v, want 1", report.PassRate())
+		}
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.golden.json"))
+	if len(files) != 2 {
+		t.Errorf("golden files = %v, want 2", files)
+	}
+}
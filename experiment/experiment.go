@@ -0,0 +1,202 @@
+// Package experiment runs A/B experiments across generators: requests are
+// assigned to variants, responses are tagged with theirs, and per-variant
+// aggregates are kept for analysis.
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/models"
+)
+
+// Response metadata keys set by an Experiment
+const (
+	MetadataExperiment = "experiment"
+	MetadataVariant    = "experiment_variant"
+)
+
+// Variant represents an arm of an experiment
+type Variant struct {
+	Name      string
+	Generator generator.Generator
+	// Model replaces Request.Model for the variant's requests when set
+	Model string
+	// Weight is the share of traffic of the variant, e.g. a percentage
+	Weight int
+}
+
+// Stats represents the aggregates of a variant
+type Stats struct {
+	Variant          string
+	Requests         int
+	Errors           int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64       // Estimated from the models pricing registry
+	Latency          time.Duration // Total latency of successful requests
+}
+
+// MeanLatency returns the mean latency of the successful requests
+func (s Stats) MeanLatency() time.Duration {
+	if ok := s.Requests - s.Errors; ok > 0 {
+		return s.Latency / time.Duration(ok)
+	}
+	return 0
+}
+
+// Experiment is a generator.Generator assigning each request to a variant.
+// Requests with a User are assigned by a hash of the experiment name and
+// user, so a user stays in the same variant; others are assigned at random.
+type Experiment struct {
+	name     string
+	variants []Variant
+	total    int
+
+	mu    sync.Mutex
+	stats []Stats
+}
+
+// New creates a new experiment called name over variants, which need a
+// generator and a positive weight
+func New(name string, variants ...Variant) (*Experiment, error) {
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("experiment %s has no variants", name)
+	}
+	e := &Experiment{name: name, variants: variants}
+	for _, v := range variants {
+		if v.Generator == nil || v.Weight <= 0 {
+			return nil, fmt.Errorf("experiment %s: variant %q needs a generator and a positive weight", name, v.Name)
+		}
+		e.total += v.Weight
+		e.stats = append(e.stats, Stats{Variant: v.Name})
+	}
+	return e, nil
+}
+
+// Assign returns the index of the variant serving req
+func (e *Experiment) Assign(req *generator.Request) int {
+	var bucket int
+	if req.User != "" {
+		h := fnv.New64a()
+		h.Write([]byte(e.name))
+		h.Write([]byte{0})
+		h.Write([]byte(req.User))
+		bucket = int(h.Sum64() % uint64(e.total))
+	} else {
+		bucket = rand.IntN(e.total)
+	}
+	for i, v := range e.variants {
+		if bucket < v.Weight {
+			return i
+		}
+		bucket -= v.Weight
+	}
+	return len(e.variants) - 1
+}
+
+func (e *Experiment) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	i, routed := e.route(req)
+	start := time.Now()
+	resp, err := e.variants[i].Generator.Generate(ctx, routed)
+	if err != nil {
+		e.record(i, routed.Model, nil, 0)
+		return nil, err
+	}
+	model := routed.Model
+	if resp.Model != "" {
+		model = resp.Model
+	}
+	e.record(i, model, &resp.Usage, time.Since(start))
+	e.tag(i, resp)
+	return resp, nil
+}
+
+// GenerateStream tags every chunk, measuring latency until the stream closes
+func (e *Experiment) GenerateStream(ctx context.Context, req *generator.Request) (<-chan *generator.Response, error) {
+	i, routed := e.route(req)
+	start := time.Now()
+	stream, err := e.variants[i].Generator.GenerateStream(ctx, routed)
+	if err != nil {
+		e.record(i, routed.Model, nil, 0)
+		return nil, err
+	}
+
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+		var usage generator.TokenUsage
+		failed := false
+		defer func() {
+			if failed {
+				e.record(i, routed.Model, nil, 0)
+				return
+			}
+			e.record(i, routed.Model, &usage, time.Since(start))
+		}()
+		for chunk := range stream {
+			if chunk.Usage != (generator.TokenUsage{}) {
+				usage = chunk.Usage
+			}
+			failed = failed || chunk.Err != nil
+			e.tag(i, chunk)
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				failed = true
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (e *Experiment) GetName() string {
+	return "experiment:" + e.name
+}
+
+// Stats returns the aggregates of every variant, in the order given to New
+func (e *Experiment) Stats() []Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Stats(nil), e.stats...)
+}
+
+func (e *Experiment) route(req *generator.Request) (int, *generator.Request) {
+	i := e.Assign(req)
+	if m := e.variants[i].Model; m != "" && m != req.Model {
+		r := *req
+		r.Model = m
+		return i, &r
+	}
+	return i, req
+}
+
+// record adds a request to the stats of variant i; a nil usage is an error
+func (e *Experiment) record(i int, model string, usage *generator.TokenUsage, latency time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := &e.stats[i]
+	s.Requests++
+	if usage == nil {
+		s.Errors++
+		return
+	}
+	s.PromptTokens += usage.PromptTokens
+	s.CompletionTokens += usage.CompletionTokens
+	s.Cost += models.Cost(model, usage.PromptTokens, usage.CachedPromptTokens, usage.CompletionTokens)
+	s.Latency += latency
+}
+
+func (e *Experiment) tag(i int, resp *generator.Response) {
+	if resp.Metadata == nil {
+		resp.Metadata = map[string]string{}
+	}
+	resp.Metadata[MetadataExperiment] = e.name
+	resp.Metadata[MetadataVariant] = e.variants[i].Name
+}
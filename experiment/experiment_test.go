@@ -0,0 +1,80 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func answering(content string) *mock.Mock {
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		return &generator.Response{Content: content, Usage: generator.TokenUsage{PromptTokens: 1000, CompletionTokens: 100}}, nil
+	}
+	return m
+}
+
+func TestExperiment(t *testing.T) {
+	e, err := New("prompt-v2",
+		Variant{Name: "control", Generator: answering("a"), Model: "gpt-4o", Weight: 80},
+		Variant{Name: "treatment", Generator: answering("b"), Model: "gpt-4o-mini", Weight: 20},
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		resp, err := e.Generate(context.Background(), &generator.Request{User: user})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		want := map[string]string{"a": "control", "b": "treatment"}[resp.Content]
+		if resp.Metadata[MetadataVariant] != want || resp.Metadata[MetadataExperiment] != "prompt-v2" {
+			t.Fatalf("Generate() metadata = %v, want variant %s", resp.Metadata, want)
+		}
+		again, _ := e.Generate(context.Background(), &generator.Request{User: user})
+		if again.Content != resp.Content {
+			t.Fatalf("user %s assigned to both variants", user)
+		}
+	}
+
+	stats := e.Stats()
+	control, treatment := stats[0], stats[1]
+	if control.Requests+treatment.Requests != 2000 || treatment.Requests < 300 || treatment.Requests > 500 {
+		t.Errorf("Stats() = %+v, want about 20%% of 2000 requests in treatment", stats)
+	}
+	// gpt-4o costs $2.5 and $10 per million input and output tokens
+	if want := float64(control.Requests) * 0.0035; control.Cost < want-1e-9 || control.Cost > want+1e-9 {
+		t.Errorf("control Cost = %v, want %v", control.Cost, want)
+	}
+	if treatment.PromptTokens != treatment.Requests*1000 {
+		t.Errorf("treatment PromptTokens = %d, want %d", treatment.PromptTokens, treatment.Requests*1000)
+	}
+
+	if _, err := New("empty"); err == nil {
+		t.Error("New() without variants error = nil")
+	}
+	if _, err := New("unweighted", Variant{Name: "a", Generator: answering("a")}); err == nil {
+		t.Error("New() with an unweighted variant error = nil")
+	}
+}
+
+func TestExperiment_GenerateStream(t *testing.T) {
+	e, _ := New("stream", Variant{Name: "only", Generator: answering("a"), Weight: 1})
+	stream, err := e.GenerateStream(context.Background(), &generator.Request{})
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	for chunk := range stream {
+		if chunk.Metadata[MetadataVariant] != "only" {
+			t.Errorf("chunk metadata = %v", chunk.Metadata)
+		}
+	}
+	if s := e.Stats()[0]; s.Requests != 1 || s.PromptTokens != 1000 {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
@@ -0,0 +1,185 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// RequestBuilder builds a Request step by step, checking it in Build:
+//
+//	req, err := generator.NewRequest().
+//		Model("gpt-4o").
+//		System("You are terse.").
+//		User("Hi").
+//		Temperature(0.2).
+//		Build()
+type RequestBuilder struct {
+	req Request
+}
+
+// NewRequest starts building a request
+func NewRequest() *RequestBuilder {
+	return &RequestBuilder{}
+}
+
+// Model sets the model, overriding the generator's
+func (b *RequestBuilder) Model(model string) *RequestBuilder {
+	b.req.Model = model
+	return b
+}
+
+// System appends a system message
+func (b *RequestBuilder) System(content string) *RequestBuilder {
+	return b.Message(Message{Role: SYSTEM, Content: content})
+}
+
+// User appends a user message
+func (b *RequestBuilder) User(content string) *RequestBuilder {
+	return b.Message(Message{Role: USER, Content: content})
+}
+
+// Assistant appends an assistant message, e.g. a previous answer
+func (b *RequestBuilder) Assistant(content string) *RequestBuilder {
+	return b.Message(Message{Role: ASSISTANT, Content: content})
+}
+
+// ToolResult appends the result of the tool call callID
+func (b *RequestBuilder) ToolResult(callID, content string) *RequestBuilder {
+	return b.Message(Message{Role: TOOL, Content: content, ToolCallID: callID})
+}
+
+// Message appends messages
+func (b *RequestBuilder) Message(messages ...Message) *RequestBuilder {
+	b.req.Messages = append(b.req.Messages, messages...)
+	return b
+}
+
+// MaxTokens sets the maximum number of tokens generated
+func (b *RequestBuilder) MaxTokens(n int) *RequestBuilder {
+	b.req.MaxTokens = n
+	return b
+}
+
+// Temperature sets the sampling temperature, between 0 and 2
+func (b *RequestBuilder) Temperature(t float64) *RequestBuilder {
+	b.req.Temperature = t
+	return b
+}
+
+// TopP sets the nucleus sampling probability mass, between 0 and 1
+func (b *RequestBuilder) TopP(p float64) *RequestBuilder {
+	b.req.TopP = p
+	return b
+}
+
+// ReasoningEffort sets the reasoning effort
+func (b *RequestBuilder) ReasoningEffort(effort ReasoningEffort) *RequestBuilder {
+	b.req.ReasoningEffort = effort
+	return b
+}
+
+// Stop appends stop sequences
+func (b *RequestBuilder) Stop(sequences ...string) *RequestBuilder {
+	b.req.Stop = append(b.req.Stop, sequences...)
+	return b
+}
+
+// Tool appends tools the model may call
+func (b *RequestBuilder) Tool(tools ...Tool) *RequestBuilder {
+	b.req.Tools = append(b.req.Tools, tools...)
+	return b
+}
+
+// ToolChoice sets "auto", "none", "required" or the name of a tool to force
+func (b *RequestBuilder) ToolChoice(choice string) *RequestBuilder {
+	b.req.ToolChoice = choice
+	return b
+}
+
+// EndUser sets the ID of the end user the request is made for
+func (b *RequestBuilder) EndUser(id string) *RequestBuilder {
+	b.req.User = id
+	return b
+}
+
+// ProviderParam sets a provider specific parameter
+func (b *RequestBuilder) ProviderParam(key string, value any) *RequestBuilder {
+	if b.req.ProviderParams == nil {
+		b.req.ProviderParams = map[string]interface{}{}
+	}
+	b.req.ProviderParams[key] = value
+	return b
+}
+
+// Metadata sets a metadata annotation
+func (b *RequestBuilder) Metadata(key, value string) *RequestBuilder {
+	if b.req.Metadata == nil {
+		b.req.Metadata = map[string]string{}
+	}
+	b.req.Metadata[key] = value
+	return b
+}
+
+// IdempotencyKey sets the idempotency key
+func (b *RequestBuilder) IdempotencyKey(key string) *RequestBuilder {
+	b.req.IdempotencyKey = key
+	return b
+}
+
+// Build returns the request, or the first mistake found: no messages,
+// sampling parameters out of range, unnamed or duplicate tools, a tool
+// choice naming no tool, or a tool result without its call ID. The builder
+// can be reused; later changes do not affect built requests.
+func (b *RequestBuilder) Build() (*Request, error) {
+	r := b.req
+	switch {
+	case len(r.Messages) == 0:
+		return nil, errors.New("request has no messages")
+	case r.Temperature < 0 || r.Temperature > 2:
+		return nil, fmt.Errorf("temperature %v out of [0, 2]", r.Temperature)
+	case r.TopP < 0 || r.TopP > 1:
+		return nil, fmt.Errorf("top_p %v out of [0, 1]", r.TopP)
+	case r.MaxTokens < 0:
+		return nil, fmt.Errorf("negative max tokens %d", r.MaxTokens)
+	}
+	for i, m := range r.Messages {
+		if m.Role == TOOL && m.ToolCallID == "" {
+			return nil, fmt.Errorf("tool message %d has no tool call ID", i)
+		}
+	}
+	names := map[string]bool{}
+	for _, t := range r.Tools {
+		if t.Name == "" {
+			return nil, errors.New("tool without a name")
+		}
+		if names[t.Name] {
+			return nil, fmt.Errorf("duplicate tool %q", t.Name)
+		}
+		names[t.Name] = true
+	}
+	switch r.ToolChoice {
+	case "", "auto", "none", "required":
+	default:
+		if !names[r.ToolChoice] {
+			return nil, fmt.Errorf("tool choice %q names no tool", r.ToolChoice)
+		}
+	}
+
+	r.Messages = append([]Message(nil), r.Messages...)
+	r.Stop = append([]string(nil), r.Stop...)
+	r.Tools = append([]Tool(nil), r.Tools...)
+	r.ProviderParams = maps.Clone(r.ProviderParams)
+	r.Metadata = maps.Clone(r.Metadata)
+	return &r, nil
+}
+
+// MustBuild is like Build but panics on a mistake, for requests known to be
+// valid, e.g. in package variables
+func (b *RequestBuilder) MustBuild() *Request {
+	r, err := b.Build()
+	if err != nil {
+		panic("generator: " + err.Error())
+	}
+	return r
+}
@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequestBuilder(t *testing.T) {
+	lookup := Tool{Name: "lookup", Parameters: map[string]interface{}{"type": "object"}}
+	b := NewRequest().
+		Model("gpt-4o").
+		System("Be terse.").
+		User("Hi").
+		Temperature(0.2).
+		MaxTokens(100).
+		Stop("\n\n").
+		Tool(lookup).
+		ToolChoice("lookup").
+		EndUser("u1").
+		Metadata("team", "search")
+	req, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if req.Model != "gpt-4o" || len(req.Messages) != 2 || req.Messages[0].Role != SYSTEM || req.Messages[1].Content != "Hi" {
+		t.Errorf("Build() = %+v", req)
+	}
+	if req.Temperature != 0.2 || req.MaxTokens != 100 || req.ToolChoice != "lookup" || req.User != "u1" || req.Metadata["team"] != "search" {
+		t.Errorf("Build() = %+v", req)
+	}
+
+	// Built requests do not share state with the builder
+	b.User("More").Metadata("team", "ads")
+	if len(req.Messages) != 2 || req.Metadata["team"] != "search" {
+		t.Errorf("Build() result changed by the builder: %+v", req)
+	}
+}
+
+func TestRequestBuilder_Mistakes(t *testing.T) {
+	tests := []struct {
+		name string
+		b    *RequestBuilder
+		want string
+	}{
+		{"no messages", NewRequest().Model("m"), "no messages"},
+		{"temperature", NewRequest().User("hi").Temperature(3), "temperature"},
+		{"top_p", NewRequest().User("hi").TopP(-0.1), "top_p"},
+		{"max tokens", NewRequest().User("hi").MaxTokens(-1), "max tokens"},
+		{"tool result", NewRequest().User("hi").ToolResult("", "42"), "tool call ID"},
+		{"unnamed tool", NewRequest().User("hi").Tool(Tool{}), "without a name"},
+		{"duplicate tool", NewRequest().User("hi").Tool(Tool{Name: "a"}, Tool{Name: "a"}), "duplicate"},
+		{"tool choice", NewRequest().User("hi").Tool(Tool{Name: "a"}).ToolChoice("b"), `"b" names no tool`},
+	}
+	for _, tt := range tests {
+		if _, err := tt.b.Build(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Build() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustBuild() did not panic without messages")
+		}
+	}()
+	NewRequest().MustBuild()
+}
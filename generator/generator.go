@@ -3,26 +3,86 @@ package generator
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/parikxxit/go-llm/auth"
 )
 
 type Role string
 
 const (
+	SYSTEM    = "system"
 	USER      = "user"
 	ASSISTANT = "assistant"
+	TOOL      = "tool"
+)
+
+// Tool represents a function the model may call
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{} // JSON schema of the arguments object
+}
+
+// ToolCall represents a function call requested by the model
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments string // JSON encoded arguments
+}
+
+// ReasoningEffort controls how much internal reasoning a reasoning model
+// (o-series, DeepSeek-R1, Claude extended thinking) spends before answering
+type ReasoningEffort string
+
+const (
+	ReasoningEffortLow    ReasoningEffort = "low"
+	ReasoningEffortMedium ReasoningEffort = "medium"
+	ReasoningEffortHigh   ReasoningEffort = "high"
+)
+
+// GrammarKind identifies the language of a Grammar
+type GrammarKind string
+
+const (
+	// GrammarGBNF is a llama.cpp GBNF grammar
+	GrammarGBNF       GrammarKind = "gbnf"
+	GrammarRegex      GrammarKind = "regex"
+	GrammarJSONSchema GrammarKind = "json_schema"
 )
 
+// Grammar constrains generated text to a language, for backends with
+// constrained decoding; see GrammarSupporter
+type Grammar struct {
+	Kind GrammarKind
+	// Definition is the GBNF grammar or the regular expression
+	Definition string
+	// Schema is the JSON schema of GrammarJSONSchema
+	Schema map[string]interface{}
+	// Name names the schema for providers requiring one
+	Name string
+}
+
 // Message represents a message in a conversation
 type Message struct {
-	Role    Role
-	Content string
+	Role      Role
+	Content   string
+	ToolCalls []ToolCall // Calls requested by an assistant message
+	// ToolCallID links a tool message to the call it answers
+	ToolCallID string
 }
 
 // TokenUsage represents token usage information
 type TokenUsage struct {
-	PromptTokens     int
-	CompletionTokens int
-	TotalTokens      int
+	PromptTokens int
+	// CachedPromptTokens is the subset of PromptTokens read from the
+	// provider's prompt cache
+	CachedPromptTokens int
+	CompletionTokens   int
+	ReasoningTokens    int // Subset of CompletionTokens spent on reasoning
+	TotalTokens        int
 }
 
 // Choice represents a choice in a generation response
@@ -34,14 +94,34 @@ type Choice struct {
 
 // Request represents a text generation request
 type Request struct {
-	Model          string //Change model in runtime in b/w conv based on some logic as well
-	Messages       []Message
-	MaxTokens      int
-	Temperature    float64
-	TopP           float64
-	Stop           []string
-	User           string
-	ProviderParams map[string]interface{}
+	Model       string //Change model in runtime in b/w conv based on some logic as well
+	Messages    []Message
+	MaxTokens   int
+	Temperature float64
+	TopP        float64
+	// ReasoningEffort is ignored by models without reasoning support
+	ReasoningEffort ReasoningEffort
+	Stop            []string
+	User            string
+	ProviderParams  map[string]interface{}
+	Tools           []Tool
+	// ToolChoice is "auto", "none", "required" or the name of a tool to force
+	ToolChoice string
+	// Metadata annotates the request for logging and analysis; it is never
+	// sent to providers
+	Metadata map[string]string
+	// IdempotencyKey identifies the request to providers deduplicating
+	// requests, so a retried request is billed once; see
+	// IdempotencySupporter
+	IdempotencyKey string
+	// Prefill starts the reply, for the model to continue, e.g. "{" to force
+	// JSON. The client sends it as a trailing assistant message, asking
+	// generators without prefill support to continue it, and responses
+	// start with it; streams send it as their first chunk.
+	Prefill string
+	// Grammar constrains the reply; requests with a grammar its generator
+	// does not support fail
+	Grammar *Grammar
 }
 
 // Response represents a text generation response
@@ -51,12 +131,68 @@ type Response struct {
 	Created int64
 	Model   string
 	Content string // Single response content
-	Usage   TokenUsage
+	// Reasoning holds the model's thinking output when the provider exposes it.
+	// In streams it carries the reasoning delta of the chunk.
+	Reasoning    string
+	ToolCalls    []ToolCall
+	FinishReason string
+	Usage        TokenUsage
+	// Cost is the estimated dollar cost of Usage, from the models pricing
+	// registry; zero when the model's pricing is unknown
+	Cost float64
+	// Metadata annotates the response, e.g. by guardrails; providers never
+	// set it
+	Metadata map[string]string
+	// Raw is the provider's response body, or the payload of the chunk in
+	// streams, for fields the unified types do not model, e.g.
+	// system_fingerprint. It is only set for contexts from WithRawCapture,
+	// by providers supporting it.
+	Raw json.RawMessage `json:",omitempty"`
+	// Err is set on the last chunk of a stream that failed after it started
+	Err error
+	// StreamStats is the timing of the stream so far, set by the client on
+	// the chunk carrying the usage
+	StreamStats *StreamStats `json:",omitempty"`
 }
 
+// StreamStats represents the timing of a stream, measured as chunks reach
+// the consumer, so a slow consumer stretches it
+type StreamStats struct {
+	// TimeToFirstToken is the time from the call to the first chunk of
+	// content, reasoning or tool calls
+	TimeToFirstToken time.Duration
+	// MeanInterChunk and MaxInterChunk are the gaps between the chunks of
+	// content, reasoning or tool calls
+	MeanInterChunk time.Duration
+	MaxInterChunk  time.Duration
+	Chunks         int
+	// Duration is the time from the call to the last chunk
+	Duration time.Duration
+	// OutputTokensPerSecond is the completion tokens over the time from the
+	// first token to the last chunk; zero without usage
+	OutputTokensPerSecond float64
+}
+
+// Config represents the configuration of a provider
 type Config struct {
 	ApiKey string
 	Model  string
+	// BaseURL replaces the provider's API URL, e.g. for a proxy or a
+	// compatible server
+	BaseURL string
+	// Headers are sent with every request, e.g. OpenAI-Organization and
+	// OpenAI-Project
+	Headers map[string]string
+	// HTTPClient sends the requests, e.g. through a proxy or with mTLS;
+	// transport.DefaultClient by default, or transport.Client for other
+	// pool sizes and timeouts
+	HTTPClient *http.Client
+	// Timeout bounds each request to the provider, streams included; zero
+	// leaves it to the context
+	Timeout time.Duration
+	// Auth authorizes the requests instead of ApiKey, e.g. with
+	// auth.RefreshingBearer for Azure AD or auth.GoogleDefault for Vertex AI
+	Auth auth.Credentials
 }
 
 // Generator defines the interface for text generation
@@ -64,9 +200,49 @@ type Generator interface {
 	// Generate sends a text generation request
 	Generate(ctx context.Context, req *Request) (*Response, error)
 
-	// GenerateStream sends a streaming text generation request
+	// GenerateStream sends a streaming text generation request. Errors after
+	// the stream started are delivered as a final chunk with Err set. Chunks
+	// belong to the consumer once delivered, see ReleaseChunk.
 	GenerateStream(ctx context.Context, req *Request) (<-chan *Response, error)
 
 	// GetName returns the name of the implementation
 	GetName() string
 }
+
+// IdempotencySupporter is implemented by generators sending
+// Request.IdempotencyKey to a provider that answers a repeated key with the
+// original response instead of generating it again
+type IdempotencySupporter interface {
+	SupportsIdempotencyKeys() bool
+}
+
+// HealthChecker is implemented by generators with a cheaper probe of the
+// provider than a generation, e.g. fetching the model
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// ModelInfo represents a model offered by a provider
+type ModelInfo struct {
+	ID      string
+	OwnedBy string
+	Created time.Time
+}
+
+// ModelLister is implemented by generators that can list the models of their
+// provider
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+}
+
+// PrefillSupporter is implemented by generators that continue a trailing
+// assistant message rather than answering after it
+type PrefillSupporter interface {
+	SupportsPrefill() bool
+}
+
+// GrammarSupporter is implemented by generators constraining decoding to
+// grammars of some kinds
+type GrammarSupporter interface {
+	SupportsGrammar(kind GrammarKind) bool
+}
@@ -0,0 +1,29 @@
+package generator
+
+import "sync"
+
+var chunkPool = sync.Pool{New: func() any { return new(Response) }}
+
+// AcquireChunk returns a zero Response for a stream chunk, reusing one
+// released by a consumer when possible. The OpenAI provider acquires its
+// stream chunks; other generators may too.
+func AcquireChunk() *Response {
+	return chunkPool.Get().(*Response)
+}
+
+// ReleaseChunk returns a chunk received from a stream for reuse by later
+// streams, sparing high-throughput consumers an allocation per chunk.
+// Releasing is optional: chunks not released are garbage collected as usual.
+//
+// Every chunk a stream delivers belongs to its consumer alone, so the
+// consumer may release it once done with it, after which neither the chunk
+// nor its ToolCalls, Metadata and Raw may be used; strings copied out of it
+// stay valid. Chunks must not be released twice, nor received from anywhere
+// but a stream.
+func ReleaseChunk(chunk *Response) {
+	if chunk == nil {
+		return
+	}
+	*chunk = Response{}
+	chunkPool.Put(chunk)
+}
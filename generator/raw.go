@@ -0,0 +1,17 @@
+package generator
+
+import "context"
+
+type rawCaptureKey struct{}
+
+// WithRawCapture returns a context whose requests keep the provider payload
+// of their responses in Response.Raw
+func WithRawCapture(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rawCaptureKey{}, true)
+}
+
+// RawCaptured reports whether ctx requests Response.Raw to be set
+func RawCaptured(ctx context.Context) bool {
+	b, _ := ctx.Value(rawCaptureKey{}).(bool)
+	return b
+}
@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Factory creates a generator from its configuration
+type Factory func(cfg Config) (Generator, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a provider available by name to New. Providers register
+// themselves when their package is imported; Register panics when name is
+// already taken.
+func Register(name string, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if f == nil {
+		panic("generator: Register factory is nil")
+	}
+	if _, ok := registry[name]; ok {
+		panic("generator: Register called twice for provider " + name)
+	}
+	registry[name] = f
+}
+
+// New creates a generator with the factory registered as name, e.g. from a
+// configuration string
+func New(name string, cfg Config) (Generator, error) {
+	registryMu.RLock()
+	f, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (registered: %s); is its package imported?", name, strings.Join(Providers(), ", "))
+	}
+	return f(cfg)
+}
+
+// Providers returns the sorted names of the registered providers
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
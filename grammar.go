@@ -0,0 +1,19 @@
+package gollm
+
+import (
+	"fmt"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// checkGrammar fails requests with a grammar g does not constrain decoding
+// to with ErrUnsupported, rather than letting g ignore it
+func checkGrammar(g generator.Generator, request *generator.Request) error {
+	if request.Grammar == nil {
+		return nil
+	}
+	if s, ok := g.(generator.GrammarSupporter); ok && s.SupportsGrammar(request.Grammar.Kind) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s does not support %s grammars", ErrUnsupported, g.GetName(), request.Grammar.Kind)
+}
@@ -0,0 +1,43 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+// regexMock is a mock constraining decoding to regular expressions
+type regexMock struct {
+	*mock.Mock
+}
+
+func (regexMock) SupportsGrammar(kind generator.GrammarKind) bool {
+	return kind == generator.GrammarRegex
+}
+
+func TestClient_Grammar(t *testing.T) {
+	m := mock.New()
+	client := NewClient(regexMock{m})
+	req := func(kind generator.GrammarKind) *generator.Request {
+		return &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "42"}}, Grammar: &generator.Grammar{Kind: kind, Definition: "[0-9]+"}}
+	}
+
+	if _, err := client.Generate(context.Background(), req(generator.GrammarRegex)); err != nil {
+		t.Errorf("Generate() with a supported grammar error = %v", err)
+	}
+	if _, err := client.Generate(context.Background(), req(generator.GrammarGBNF)); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Generate() with an unsupported grammar error = %v, want ErrUnsupported", err)
+	}
+	if _, err := client.GenerateStream(context.Background(), req(generator.GrammarGBNF)); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GenerateStream() with an unsupported grammar error = %v, want ErrUnsupported", err)
+	}
+	if _, err := NewClient(m).Generate(context.Background(), req(generator.GrammarRegex)); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Generate() with a grammar to a generator without grammars error = %v, want ErrUnsupported", err)
+	}
+	if n := m.Calls(); n != 1 {
+		t.Errorf("generator called %d times, want once", n)
+	}
+}
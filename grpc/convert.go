@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/grpc/gollmpb"
+	"github.com/parikxxit/go-llm/reranker"
+)
+
+func generateRequest(req *gollmpb.GenerateRequest) (*generator.Request, error) {
+	r := &generator.Request{
+		Model:           req.GetModel(),
+		MaxTokens:       int(req.GetMaxTokens()),
+		Temperature:     req.GetTemperature(),
+		TopP:            req.GetTopP(),
+		ReasoningEffort: generator.ReasoningEffort(req.GetReasoningEffort()),
+		Stop:            req.GetStop(),
+		User:            req.GetUser(),
+		ToolChoice:      req.GetToolChoice(),
+		Metadata:        req.GetMetadata(),
+		IdempotencyKey:  req.GetIdempotencyKey(),
+		Prefill:         req.GetPrefill(),
+	}
+	if g := req.GetGrammar(); g != nil {
+		r.Grammar = &generator.Grammar{Kind: generator.GrammarKind(g.GetKind()), Definition: g.GetDefinition(), Name: g.GetName()}
+		if g.GetSchema() != nil {
+			r.Grammar.Schema = g.GetSchema().AsMap()
+		}
+	}
+	if req.GetProviderParams() != nil {
+		r.ProviderParams = req.GetProviderParams().AsMap()
+	}
+	for _, m := range req.GetMessages() {
+		switch m.GetRole() {
+		case generator.SYSTEM, generator.USER, generator.ASSISTANT, generator.TOOL:
+		default:
+			return nil, fmt.Errorf("message role %q is not system, user, assistant or tool", m.GetRole())
+		}
+		r.Messages = append(r.Messages, generator.Message{
+			Role:       generator.Role(m.GetRole()),
+			Content:    m.GetContent(),
+			ToolCalls:  toolCalls(m.GetToolCalls()),
+			ToolCallID: m.GetToolCallId(),
+		})
+	}
+	if len(r.Messages) == 0 {
+		return nil, errors.New("request has no messages")
+	}
+	for _, t := range req.GetTools() {
+		r.Tools = append(r.Tools, generator.Tool{Name: t.GetName(), Description: t.GetDescription(), Parameters: t.GetParameters().AsMap()})
+	}
+	return r, nil
+}
+
+func toolCalls(calls []*gollmpb.ToolCall) []generator.ToolCall {
+	var out []generator.ToolCall
+	for _, c := range calls {
+		out = append(out, generator.ToolCall{ID: c.GetId(), Name: c.GetName(), Arguments: c.GetArguments()})
+	}
+	return out
+}
+
+func generateResponse(resp *generator.Response) *gollmpb.GenerateResponse {
+	out := &gollmpb.GenerateResponse{
+		Id:           resp.ID,
+		Model:        resp.Model,
+		Content:      resp.Content,
+		Reasoning:    resp.Reasoning,
+		FinishReason: resp.FinishReason,
+		Cost:         resp.Cost,
+		Metadata:     resp.Metadata,
+	}
+	for _, c := range resp.ToolCalls {
+		out.ToolCalls = append(out.ToolCalls, &gollmpb.ToolCall{Id: c.ID, Name: c.Name, Arguments: c.Arguments})
+	}
+	if u := resp.Usage; u != (generator.TokenUsage{}) {
+		out.Usage = &gollmpb.TokenUsage{
+			PromptTokens:       int32(u.PromptTokens),
+			CachedPromptTokens: int32(u.CachedPromptTokens),
+			CompletionTokens:   int32(u.CompletionTokens),
+			ReasoningTokens:    int32(u.ReasoningTokens),
+			TotalTokens:        int32(u.TotalTokens),
+		}
+	}
+	return out
+}
+
+func embedRequest(req *gollmpb.EmbedRequest) *embedder.Request {
+	return &embedder.Request{Model: req.GetModel(), Input: req.GetInput(), Dimensions: int(req.GetDimensions()), User: req.GetUser()}
+}
+
+func embedResponse(resp *embedder.Response) *gollmpb.EmbedResponse {
+	out := &gollmpb.EmbedResponse{Model: resp.Model, PromptTokens: int32(resp.Usage.PromptTokens)}
+	for _, d := range resp.Data {
+		values := d.Embedding
+		if values == nil {
+			for _, v := range d.Embedding32 {
+				values = append(values, float64(v))
+			}
+		}
+		out.Data = append(out.Data, &gollmpb.Embedding{Index: int32(d.Index), Values: values})
+	}
+	return out
+}
+
+func rerankRequest(req *gollmpb.RerankRequest) *reranker.Request {
+	r := &reranker.Request{Model: req.GetModel(), Query: req.GetQuery(), TopN: int(req.GetTopN()), MinScore: req.GetMinScore()}
+	for _, d := range req.GetDocuments() {
+		r.Documents = append(r.Documents, reranker.Document{ID: d.GetId(), Text: d.GetText()})
+	}
+	return r
+}
+
+// rerankResponse converts resp, taking the documents of the results from
+// req since providers need not return them
+func rerankResponse(req *reranker.Request, resp *reranker.Response) *gollmpb.RerankResponse {
+	out := &gollmpb.RerankResponse{Model: resp.Model}
+	for _, res := range resp.Results {
+		doc := res.Document
+		if res.Index >= 0 && res.Index < len(req.Documents) {
+			doc = req.Documents[res.Index]
+		}
+		out.Results = append(out.Results, &gollmpb.RerankResult{
+			Index:          int32(res.Index),
+			RelevanceScore: res.RelevanceScore,
+			Document:       &gollmpb.Document{Id: doc.ID, Text: doc.Text},
+		})
+	}
+	return out
+}
@@ -0,0 +1,252 @@
+// The gollm service exposes a gollm client, with its routing, fallbacks,
+// retries and caching, to services in any language.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: gollm.proto
+
+package gollmpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Gollm_Generate_FullMethodName       = "/gollm.v1.Gollm/Generate"
+	Gollm_GenerateStream_FullMethodName = "/gollm.v1.Gollm/GenerateStream"
+	Gollm_Embed_FullMethodName          = "/gollm.v1.Gollm/Embed"
+	Gollm_Rerank_FullMethodName         = "/gollm.v1.Gollm/Rerank"
+)
+
+// GollmClient is the client API for Gollm service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GollmClient interface {
+	// Generate generates a reply to a conversation
+	Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*GenerateResponse, error)
+	// GenerateStream streams the reply to a conversation: content and
+	// reasoning are deltas, usage is set on the last chunk
+	GenerateStream(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GenerateResponse], error)
+	// Embed embeds texts
+	Embed(ctx context.Context, in *EmbedRequest, opts ...grpc.CallOption) (*EmbedResponse, error)
+	// Rerank ranks documents by relevance to a query, most relevant first
+	Rerank(ctx context.Context, in *RerankRequest, opts ...grpc.CallOption) (*RerankResponse, error)
+}
+
+type gollmClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGollmClient(cc grpc.ClientConnInterface) GollmClient {
+	return &gollmClient{cc}
+}
+
+func (c *gollmClient) Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*GenerateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenerateResponse)
+	err := c.cc.Invoke(ctx, Gollm_Generate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gollmClient) GenerateStream(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GenerateResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Gollm_ServiceDesc.Streams[0], Gollm_GenerateStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GenerateRequest, GenerateResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Gollm_GenerateStreamClient = grpc.ServerStreamingClient[GenerateResponse]
+
+func (c *gollmClient) Embed(ctx context.Context, in *EmbedRequest, opts ...grpc.CallOption) (*EmbedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EmbedResponse)
+	err := c.cc.Invoke(ctx, Gollm_Embed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gollmClient) Rerank(ctx context.Context, in *RerankRequest, opts ...grpc.CallOption) (*RerankResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RerankResponse)
+	err := c.cc.Invoke(ctx, Gollm_Rerank_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GollmServer is the server API for Gollm service.
+// All implementations must embed UnimplementedGollmServer
+// for forward compatibility.
+type GollmServer interface {
+	// Generate generates a reply to a conversation
+	Generate(context.Context, *GenerateRequest) (*GenerateResponse, error)
+	// GenerateStream streams the reply to a conversation: content and
+	// reasoning are deltas, usage is set on the last chunk
+	GenerateStream(*GenerateRequest, grpc.ServerStreamingServer[GenerateResponse]) error
+	// Embed embeds texts
+	Embed(context.Context, *EmbedRequest) (*EmbedResponse, error)
+	// Rerank ranks documents by relevance to a query, most relevant first
+	Rerank(context.Context, *RerankRequest) (*RerankResponse, error)
+	mustEmbedUnimplementedGollmServer()
+}
+
+// UnimplementedGollmServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGollmServer struct{}
+
+func (UnimplementedGollmServer) Generate(context.Context, *GenerateRequest) (*GenerateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Generate not implemented")
+}
+func (UnimplementedGollmServer) GenerateStream(*GenerateRequest, grpc.ServerStreamingServer[GenerateResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method GenerateStream not implemented")
+}
+func (UnimplementedGollmServer) Embed(context.Context, *EmbedRequest) (*EmbedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Embed not implemented")
+}
+func (UnimplementedGollmServer) Rerank(context.Context, *RerankRequest) (*RerankResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rerank not implemented")
+}
+func (UnimplementedGollmServer) mustEmbedUnimplementedGollmServer() {}
+func (UnimplementedGollmServer) testEmbeddedByValue()               {}
+
+// UnsafeGollmServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GollmServer will
+// result in compilation errors.
+type UnsafeGollmServer interface {
+	mustEmbedUnimplementedGollmServer()
+}
+
+func RegisterGollmServer(s grpc.ServiceRegistrar, srv GollmServer) {
+	// If the following call pancis, it indicates UnimplementedGollmServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Gollm_ServiceDesc, srv)
+}
+
+func _Gollm_Generate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GollmServer).Generate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gollm_Generate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GollmServer).Generate(ctx, req.(*GenerateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gollm_GenerateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GenerateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GollmServer).GenerateStream(m, &grpc.GenericServerStream[GenerateRequest, GenerateResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Gollm_GenerateStreamServer = grpc.ServerStreamingServer[GenerateResponse]
+
+func _Gollm_Embed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmbedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GollmServer).Embed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gollm_Embed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GollmServer).Embed(ctx, req.(*EmbedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gollm_Rerank_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RerankRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GollmServer).Rerank(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gollm_Rerank_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GollmServer).Rerank(ctx, req.(*RerankRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Gollm_ServiceDesc is the grpc.ServiceDesc for Gollm service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Gollm_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gollm.v1.Gollm",
+	HandlerType: (*GollmServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Generate",
+			Handler:    _Gollm_Generate_Handler,
+		},
+		{
+			MethodName: "Embed",
+			Handler:    _Gollm_Embed_Handler,
+		},
+		{
+			MethodName: "Rerank",
+			Handler:    _Gollm_Rerank_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GenerateStream",
+			Handler:       _Gollm_GenerateStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gollm.proto",
+}
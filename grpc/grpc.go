@@ -0,0 +1,122 @@
+// Package grpc serves a gollm client over gRPC, so services in any language
+// share its routing, fallbacks, retries, caching and budgets. The service is
+// defined in gollmpb/gollm.proto; generate clients for other languages from
+// it. Register the service on a gRPC server:
+//
+//	s := grpc.NewServer()
+//	gollmpb.RegisterGollmServer(s, gollmgrpc.NewService(client))
+//	s.Serve(lis)
+package grpc
+
+//go:generate protoc -I gollmpb --go_out=gollmpb --go_opt=paths=source_relative --go-grpc_out=gollmpb --go-grpc_opt=paths=source_relative gollm.proto
+
+import (
+	"context"
+	"errors"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/grpc/gollmpb"
+	"github.com/parikxxit/go-llm/guardrails"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/moderation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Service implements gollmpb.GollmServer with a gollm client
+type Service struct {
+	gollmpb.UnimplementedGollmServer
+	client *gollm.Client
+}
+
+// NewService creates a service answering with client
+func NewService(client *gollm.Client) *Service {
+	return &Service{client: client}
+}
+
+func (s *Service) Generate(ctx context.Context, req *gollmpb.GenerateRequest) (*gollmpb.GenerateResponse, error) {
+	r, err := generateRequest(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp, err := s.client.Generate(ctx, r)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return generateResponse(resp), nil
+}
+
+func (s *Service) GenerateStream(req *gollmpb.GenerateRequest, stream grpc.ServerStreamingServer[gollmpb.GenerateResponse]) error {
+	r, err := generateRequest(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	// Cancelling ends the client stream when the caller goes away or a send
+	// fails, so it is drained at once
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	chunks, err := s.client.GenerateStream(ctx, r)
+	if err != nil {
+		return toStatus(err)
+	}
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			return toStatus(chunk.Err)
+		}
+		if err := stream.Send(generateResponse(chunk)); err != nil {
+			cancel()
+			for range chunks {
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) Embed(ctx context.Context, req *gollmpb.EmbedRequest) (*gollmpb.EmbedResponse, error) {
+	if !s.client.HasEmbedder() {
+		return nil, status.Error(codes.Unimplemented, "no embedder configured")
+	}
+	resp, err := s.client.Embed(ctx, embedRequest(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return embedResponse(resp), nil
+}
+
+func (s *Service) Rerank(ctx context.Context, req *gollmpb.RerankRequest) (*gollmpb.RerankResponse, error) {
+	if !s.client.HasReranker() {
+		return nil, status.Error(codes.Unimplemented, "no reranker configured")
+	}
+	r := rerankRequest(req)
+	resp, err := s.client.Rerank(ctx, r)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return rerankResponse(r, resp), nil
+}
+
+// toStatus returns err as a gRPC status of the closest code
+func toStatus(err error) error {
+	var code codes.Code
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, llmerrors.ErrRateLimited), errors.Is(err, gollm.ErrBudgetExceeded), errors.Is(err, gollm.ErrQueueFull):
+		code = codes.ResourceExhausted
+	case errors.Is(err, llmerrors.ErrOverloaded):
+		code = codes.Unavailable
+	case errors.Is(err, llmerrors.ErrAuth):
+		code = codes.Unauthenticated
+	case errors.Is(err, llmerrors.ErrModelNotFound):
+		code = codes.NotFound
+	case errors.Is(err, llmerrors.ErrContextLengthExceeded), errors.Is(err, gollm.ErrUnsupported):
+		code = codes.InvalidArgument
+	case errors.Is(err, llmerrors.ErrContentFiltered), errors.Is(err, guardrails.ErrBlocked), errors.Is(err, moderation.ErrFlagged):
+		code = codes.FailedPrecondition
+	default:
+		code = codes.Unknown
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,161 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/grpc/gollmpb"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/reranker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// fakeIndex embeds texts as their length and ranks documents by length,
+// without returning them
+type fakeIndex struct{}
+
+func (fakeIndex) Embed(_ context.Context, req *embedder.Request) (*embedder.Response, error) {
+	resp := &embedder.Response{Model: req.Model, Usage: embedder.TokenUsage{PromptTokens: len(req.Input)}}
+	for i, in := range req.Input {
+		resp.Data = append(resp.Data, embedder.EmbedData{Embedding: []float64{float64(len(in))}, Index: i})
+	}
+	return resp, nil
+}
+
+func (fakeIndex) GetEmbedderName() string { return "fake" }
+
+func (fakeIndex) Rerank(_ context.Context, req *reranker.Request) (*reranker.Response, error) {
+	resp := &reranker.Response{Model: req.Model}
+	for i := len(req.Documents) - 1; i >= 0; i-- {
+		resp.Results = append(resp.Results, reranker.Result{Index: i, RelevanceScore: float64(len(req.Documents[i].Text))})
+	}
+	return resp, nil
+}
+
+func (fakeIndex) GetRerankerName() string { return "fake" }
+
+// dial serves client on an in-memory listener, returning a connected stub
+func dial(t *testing.T, client *gollm.Client) gollmpb.GollmClient {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	gollmpb.RegisterGollmServer(s, NewService(client))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return gollmpb.NewGollmClient(conn)
+}
+
+func TestService_Generate(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		if req.Model == "limited" {
+			return nil, &llmerrors.Error{Kind: llmerrors.ErrRateLimited, StatusCode: 429, Err: errors.New("slow down")}
+		}
+		return &generator.Response{
+			Model:     req.Model,
+			Content:   req.Messages[len(req.Messages)-1].Content,
+			ToolCalls: []generator.ToolCall{{ID: "1", Name: req.Tools[0].Name, Arguments: `{"city":"Paris"}`}},
+			Usage:     generator.TokenUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+		}, nil
+	}
+	stub := dial(t, gollm.NewClient(m, gollm.WithRetryCount(0)))
+
+	params, _ := structpb.NewStruct(map[string]any{"type": "object"})
+	req := &gollmpb.GenerateRequest{
+		Model:    "gpt-4o",
+		Messages: []*gollmpb.Message{{Role: "system", Content: "be brief"}, {Role: "user", Content: "weather?"}},
+		Tools:    []*gollmpb.Tool{{Name: "weather", Parameters: params}},
+		Metadata: map[string]string{"team": "search"},
+	}
+	resp, err := stub.Generate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if resp.Content != "weather?" || len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "weather" || resp.Usage.GetTotalTokens() != 5 {
+		t.Errorf("Generate() = %v", resp)
+	}
+	got := m.Requests()[0]
+	if len(got.Messages) != 2 || got.Messages[0].Role != generator.SYSTEM || got.Tools[0].Parameters["type"] != "object" || got.Metadata["team"] != "search" {
+		t.Errorf("provider request = %+v", got)
+	}
+
+	tests := []struct {
+		name string
+		req  *gollmpb.GenerateRequest
+		want codes.Code
+	}{
+		{"no messages", &gollmpb.GenerateRequest{}, codes.InvalidArgument},
+		{"bad role", &gollmpb.GenerateRequest{Messages: []*gollmpb.Message{{Role: "robot", Content: "hi"}}}, codes.InvalidArgument},
+		{"rate limited", &gollmpb.GenerateRequest{Model: "limited", Messages: req.Messages, Tools: req.Tools}, codes.ResourceExhausted},
+	}
+	for _, tt := range tests {
+		if _, err := stub.Generate(context.Background(), tt.req); status.Code(err) != tt.want {
+			t.Errorf("%s: Generate() error = %v, want code %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestService_GenerateStream(t *testing.T) {
+	stub := dial(t, gollm.NewClient(mock.New()))
+	stream, err := stub.GenerateStream(context.Background(), &gollmpb.GenerateRequest{Messages: []*gollmpb.Message{{Role: "user", Content: "echo"}}})
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	var content string
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		content += chunk.Content
+	}
+	if content != "echo" {
+		t.Errorf("GenerateStream() content = %q, want %q", content, "echo")
+	}
+}
+
+func TestService_EmbedRerank(t *testing.T) {
+	stub := dial(t, gollm.NewClient(mock.New(), gollm.WithEmbedder(fakeIndex{}), gollm.WithReranker(fakeIndex{})))
+
+	emb, err := stub.Embed(context.Background(), &gollmpb.EmbedRequest{Input: []string{"a", "abc"}})
+	if err != nil || len(emb.Data) != 2 || emb.Data[1].Values[0] != 3 || emb.PromptTokens != 2 {
+		t.Errorf("Embed() = %v, %v", emb, err)
+	}
+
+	docs := []*gollmpb.Document{{Id: "x", Text: "ab"}, {Id: "y", Text: "abcd"}}
+	ranked, err := stub.Rerank(context.Background(), &gollmpb.RerankRequest{Query: "q", Documents: docs})
+	if err != nil || len(ranked.Results) != 2 || ranked.Results[0].Document.GetId() != "y" || ranked.Results[0].RelevanceScore != 4 {
+		t.Errorf("Rerank() = %v, %v, want the documents of the results filled in", ranked, err)
+	}
+
+	// Without an embedder or reranker the methods are unimplemented
+	bare := dial(t, gollm.NewClient(mock.New()))
+	if _, err := bare.Embed(context.Background(), &gollmpb.EmbedRequest{Input: []string{"a"}}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Embed() without embedder error = %v, want Unimplemented", err)
+	}
+	if _, err := bare.Rerank(context.Background(), &gollmpb.RerankRequest{Query: "q", Documents: docs}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Rerank() without reranker error = %v, want Unimplemented", err)
+	}
+}
@@ -0,0 +1,175 @@
+package guardrails
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/tokenizer"
+)
+
+// InputFunc adapts a function to an InputGuard
+func InputFunc(name string, f func(ctx context.Context, req *generator.Request) (Decision, error)) InputGuard {
+	return inputFunc{name, f}
+}
+
+type inputFunc struct {
+	name string
+	f    func(context.Context, *generator.Request) (Decision, error)
+}
+
+func (g inputFunc) Name() string { return g.name }
+
+func (g inputFunc) CheckInput(ctx context.Context, req *generator.Request) (Decision, error) {
+	return g.f(ctx, req)
+}
+
+// OutputFunc adapts a function to an OutputGuard, e.g. a custom validator
+func OutputFunc(name string, f func(ctx context.Context, req *generator.Request, resp *generator.Response) (Decision, error)) OutputGuard {
+	return outputFunc{name, f}
+}
+
+type outputFunc struct {
+	name string
+	f    func(context.Context, *generator.Request, *generator.Response) (Decision, error)
+}
+
+func (g outputFunc) Name() string { return g.name }
+
+func (g outputFunc) CheckOutput(ctx context.Context, req *generator.Request, resp *generator.Response) (Decision, error) {
+	return g.f(ctx, req, resp)
+}
+
+// MaxLength blocks requests whose messages exceed MaxTokens
+type MaxLength struct {
+	MaxTokens int
+	Counter   tokenizer.Counter // tokenizer.Estimate when nil
+}
+
+func (g MaxLength) Name() string { return "max_length" }
+
+func (g MaxLength) CheckInput(_ context.Context, req *generator.Request) (Decision, error) {
+	counter := g.Counter
+	if counter == nil {
+		counter = tokenizer.Estimate
+	}
+	if n := tokenizer.CountMessages(counter, req.Messages); n > g.MaxTokens {
+		return Decision{Action: Block, Reason: fmt.Sprintf("%d tokens exceed the limit of %d", n, g.MaxTokens)}, nil
+	}
+	return Decision{}, nil
+}
+
+// BlockedTopics blocks requests whose user messages match any pattern
+type BlockedTopics struct {
+	Patterns []*regexp.Regexp
+}
+
+// NewBlockedTopics compiles case-insensitive patterns into a BlockedTopics
+func NewBlockedTopics(patterns ...string) (*BlockedTopics, error) {
+	g := &BlockedTopics{}
+	for _, p := range patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return nil, fmt.Errorf("blocked topic %q: %w", p, err)
+		}
+		g.Patterns = append(g.Patterns, re)
+	}
+	return g, nil
+}
+
+func (g *BlockedTopics) Name() string { return "blocked_topics" }
+
+func (g *BlockedTopics) CheckInput(_ context.Context, req *generator.Request) (Decision, error) {
+	if re := matchUser(req.Messages, g.Patterns); re != nil {
+		return Decision{Action: Block, Reason: fmt.Sprintf("matches blocked topic %q", re.String())}, nil
+	}
+	return Decision{}, nil
+}
+
+// defaultInjectionPatterns are phrasings common in prompt injection attempts
+var defaultInjectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget)\b.{0,30}\b(previous|prior|above|earlier|all)\b.{0,20}\b(instructions|rules|prompts?|directions)\b`),
+	regexp.MustCompile(`(?i)\b(reveal|print|show|repeat|output)\b.{0,30}\b(system|hidden|initial)\s+(prompt|instructions|message)\b`),
+	regexp.MustCompile(`(?i)\byou are now\b.{0,40}\b(unrestricted|jailbroken|dan|without (any )?(rules|restrictions|limits))\b`),
+	regexp.MustCompile(`(?i)\b(developer|god|jailbreak)\s+mode\b`),
+	regexp.MustCompile(`(?i)\bnew instructions\s*:`),
+}
+
+// PromptInjection flags user messages matching prompt injection heuristics.
+// Heuristics catch common attacks only; combine them with model-based checks
+// for untrusted input.
+type PromptInjection struct {
+	// Patterns replace the default heuristics when set
+	Patterns []*regexp.Regexp
+	// AnnotateOnly records suspected injections in the "prompt_injection"
+	// metadata key instead of blocking them
+	AnnotateOnly bool
+}
+
+func (g PromptInjection) Name() string { return "prompt_injection" }
+
+func (g PromptInjection) CheckInput(_ context.Context, req *generator.Request) (Decision, error) {
+	patterns := g.Patterns
+	if patterns == nil {
+		patterns = defaultInjectionPatterns
+	}
+	re := matchUser(req.Messages, patterns)
+	switch {
+	case re == nil:
+		return Decision{}, nil
+	case g.AnnotateOnly:
+		return Decision{Annotations: map[string]string{"prompt_injection": "suspected"}}, nil
+	default:
+		return Decision{Action: Block, Reason: "suspected prompt injection"}, nil
+	}
+}
+
+// JSONValid blocks responses whose content is not valid JSON. A Markdown
+// code fence around the JSON is removed.
+type JSONValid struct{}
+
+func (JSONValid) Name() string { return "json_valid" }
+
+func (JSONValid) CheckOutput(_ context.Context, _ *generator.Request, resp *generator.Response) (Decision, error) {
+	content := StripCodeFence(resp.Content)
+	if !json.Valid([]byte(content)) {
+		return Decision{Action: Block, Reason: "response is not valid JSON"}, nil
+	}
+	if content != resp.Content {
+		return Decision{Action: Rewrite, Content: content}, nil
+	}
+	return Decision{}, nil
+}
+
+// StripCodeFence returns the body of a Markdown code fence wrapping s, or s
+// trimmed of surrounding whitespace
+func StripCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") || len(s) < 6 {
+		return s
+	}
+	body := strings.TrimSuffix(s[3:], "```")
+	// Drop the info string, e.g. json
+	if i := strings.IndexByte(body, '\n'); i >= 0 {
+		body = body[i+1:]
+	}
+	return strings.TrimSpace(body)
+}
+
+// matchUser returns the first pattern matching a user message
+func matchUser(messages []generator.Message, patterns []*regexp.Regexp) *regexp.Regexp {
+	for _, m := range messages {
+		if m.Role != generator.USER {
+			continue
+		}
+		for _, re := range patterns {
+			if re.MatchString(m.Content) {
+				return re
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package guardrails_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/guardrails"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestPII_Release(t *testing.T) {
+	g := &guardrails.PII{Restore: true}
+	p, _ := guardrails.New(g)
+	m := mock.New().Enqueue(mock.Failure(400, "bad request"), mock.Stream("Sent to ", "<EMAIL_1>"))
+	client := gollm.NewClient(m, gollm.WithGuardrails(p))
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "Email bob@example.com"}}}
+
+	if _, err := client.Generate(context.Background(), req); err == nil {
+		t.Fatal("Generate() error = nil, want the failure")
+	}
+	if n := g.Vaults(); n != 0 {
+		t.Errorf("vaults after a failed call = %d, want 0", n)
+	}
+
+	stream, err := client.GenerateStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	for range stream {
+	}
+	// The vault is released once the stream context ends, just after close
+	deadline := time.Now().Add(time.Second)
+	for g.Vaults() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := g.Vaults(); n != 0 {
+		t.Errorf("vaults after a streamed call = %d, want 0", n)
+	}
+}
@@ -0,0 +1,11 @@
+package guardrails
+
+// Vaults returns the number of requests whose originals g holds
+func (g *PII) Vaults() int {
+	n := 0
+	g.vaults.Range(func(any, any) bool {
+		n++
+		return true
+	})
+	return n
+}
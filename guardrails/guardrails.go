@@ -0,0 +1,195 @@
+// Package guardrails provides ordered checks run on generation requests
+// before they are sent and on responses before they are returned. Each check
+// can allow, block or rewrite the content, and annotate it.
+package guardrails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// ErrBlocked is matched by errors of blocked requests and responses
+var ErrBlocked = errors.New("blocked by guardrail")
+
+// Action represents what a guard decided
+type Action int
+
+const (
+	// Allow passes the content on unchanged
+	Allow Action = iota
+	// Block rejects the request or response with a *BlockedError
+	Block
+	// Rewrite replaces the content: Decision.Messages for input guards,
+	// Decision.Content for output guards
+	Rewrite
+)
+
+// Decision represents the verdict of a guard. Annotations are merged into
+// the request or response Metadata whatever the action.
+type Decision struct {
+	Action      Action
+	Reason      string
+	Messages    []generator.Message
+	Content     string
+	Annotations map[string]string
+}
+
+// InputGuard checks requests before they are sent
+type InputGuard interface {
+	Name() string
+	CheckInput(ctx context.Context, req *generator.Request) (Decision, error)
+}
+
+// OutputGuard checks responses before they are returned. The request is the
+// one that was sent, after input guards.
+type OutputGuard interface {
+	Name() string
+	CheckOutput(ctx context.Context, req *generator.Request, resp *generator.Response) (Decision, error)
+}
+
+// Releaser is implemented by guards holding state for a request until its
+// response is checked, such as PII with Restore
+type Releaser interface {
+	// Release drops the state held for req, the request sent
+	Release(req *generator.Request)
+}
+
+// BlockedError is returned when a guard blocks content. It matches
+// ErrBlocked.
+type BlockedError struct {
+	Guard  string
+	Reason string
+	Output bool // True when a response was blocked
+}
+
+func (e *BlockedError) Error() string {
+	direction := "request"
+	if e.Output {
+		direction = "response"
+	}
+	return fmt.Sprintf("%s blocked by guardrail %s: %s", direction, e.Guard, e.Reason)
+}
+
+func (e *BlockedError) Is(target error) bool {
+	return target == ErrBlocked
+}
+
+// Pipeline runs input guards then output guards, in order. The first guard
+// blocking stops the pipeline; rewrites are seen by later guards.
+type Pipeline struct {
+	Input  []InputGuard
+	Output []OutputGuard
+}
+
+// New creates a pipeline from guards implementing InputGuard, OutputGuard or
+// both, which join the respective stages in order
+func New(guards ...any) (*Pipeline, error) {
+	p := &Pipeline{}
+	for _, g := range guards {
+		in, isIn := g.(InputGuard)
+		out, isOut := g.(OutputGuard)
+		if !isIn && !isOut {
+			return nil, fmt.Errorf("guardrail %T is neither an InputGuard nor an OutputGuard", g)
+		}
+		if isIn {
+			p.Input = append(p.Input, in)
+		}
+		if isOut {
+			p.Output = append(p.Output, out)
+		}
+	}
+	return p, nil
+}
+
+// ProcessInput returns the request to send after running the input guards.
+// The caller's request is never modified.
+func (p *Pipeline) ProcessInput(ctx context.Context, req *generator.Request) (*generator.Request, error) {
+	if p == nil || len(p.Input) == 0 {
+		return req, nil
+	}
+	out := *req
+	out.Messages = append([]generator.Message(nil), req.Messages...)
+	out.Metadata = copyMetadata(req.Metadata)
+	for _, g := range p.Input {
+		d, err := g.CheckInput(ctx, &out)
+		if err != nil {
+			p.Release(&out)
+			return nil, fmt.Errorf("guardrail %s: %w", g.Name(), err)
+		}
+		out.Metadata = annotate(out.Metadata, d.Annotations)
+		switch d.Action {
+		case Block:
+			p.Release(&out)
+			return nil, &BlockedError{Guard: g.Name(), Reason: d.Reason}
+		case Rewrite:
+			out.Messages = d.Messages
+		}
+	}
+	return &out, nil
+}
+
+// Release lets the guards drop the state held for req, a request returned
+// by ProcessInput, once its call ends. ProcessOutput releases it too, so
+// calls need only release requests whose response is never processed:
+// failing or streamed ones.
+func (p *Pipeline) Release(req *generator.Request) {
+	if p == nil {
+		return
+	}
+	for _, g := range p.Input {
+		if r, ok := g.(Releaser); ok {
+			r.Release(req)
+		}
+	}
+}
+
+// ProcessOutput returns the response to return after running the output
+// guards on resp, the response to req
+func (p *Pipeline) ProcessOutput(ctx context.Context, req *generator.Request, resp *generator.Response) (*generator.Response, error) {
+	if p == nil || len(p.Output) == 0 {
+		return resp, nil
+	}
+	out := *resp
+	out.Metadata = copyMetadata(resp.Metadata)
+	for _, g := range p.Output {
+		d, err := g.CheckOutput(ctx, req, &out)
+		if err != nil {
+			return nil, fmt.Errorf("guardrail %s: %w", g.Name(), err)
+		}
+		out.Metadata = annotate(out.Metadata, d.Annotations)
+		switch d.Action {
+		case Block:
+			return nil, &BlockedError{Guard: g.Name(), Reason: d.Reason, Output: true}
+		case Rewrite:
+			out.Content = d.Content
+		}
+	}
+	return &out, nil
+}
+
+func copyMetadata(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func annotate(m, annotations map[string]string) map[string]string {
+	if len(annotations) == 0 {
+		return m
+	}
+	if m == nil {
+		m = make(map[string]string, len(annotations))
+	}
+	for k, v := range annotations {
+		m[k] = v
+	}
+	return m
+}
@@ -0,0 +1,258 @@
+package guardrails
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// PIIKind represents a kind of personal data
+type PIIKind string
+
+const (
+	PIIEmail      PIIKind = "EMAIL"
+	PIIPhone      PIIKind = "PHONE"
+	PIICreditCard PIIKind = "CREDIT_CARD"
+	PIISSN        PIIKind = "SSN"  // US social security number
+	PIIIBAN       PIIKind = "IBAN" // International bank account number
+)
+
+// metadataVault is the request metadata key linking a request to the
+// originals it had redacted
+const metadataVault = "pii_vault"
+
+// piiDetector finds candidates with a pattern and confirms them with an
+// optional validator, such as a checksum
+type piiDetector struct {
+	kind     PIIKind
+	pattern  *regexp.Regexp
+	validate func(match string) bool
+}
+
+// Detectors run in order; earlier kinds win overlapping matches, so card
+// numbers are not mistaken for phone numbers
+var piiDetectors = []piiDetector{
+	{PIIEmail, regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), nil},
+	{PIIIBAN, regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,4})?\b`), validIBAN},
+	{PIICreditCard, regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), luhn},
+	{PIISSN, regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), validSSN},
+	{PIIPhone, regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,4}\)[ .-]?)?\d{2,4}[ .-]\d{3,4}[ .-]?\d{3,4}\b`), validPhone},
+}
+
+// PII redacts personal data from every message before requests leave the
+// process, replacing each distinct value with a placeholder such as
+// <EMAIL_1>. With Restore, placeholders in the response are replaced by the
+// originals, which are held in memory until the response is processed or
+// the request released.
+type PII struct {
+	// Kinds limits detection to these kinds, all of them when empty
+	Kinds   []PIIKind
+	Restore bool
+
+	vaults sync.Map // Vault ID to placeholder->original
+}
+
+func (g *PII) Name() string { return "pii" }
+
+// Redact returns text with personal data replaced by placeholders, and the
+// placeholders mapped to the originals. Placeholders continue the numbering
+// of values.
+func (g *PII) Redact(text string, values map[string]string) (string, map[string]string) {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	type span struct {
+		start, end int
+		kind       PIIKind
+	}
+	var spans []span
+	taken := func(start, end int) bool {
+		for _, s := range spans {
+			if start < s.end && s.start < end {
+				return true
+			}
+		}
+		return false
+	}
+	for _, d := range piiDetectors {
+		if !g.detects(d.kind) {
+			continue
+		}
+		for _, m := range d.pattern.FindAllStringIndex(text, -1) {
+			if taken(m[0], m[1]) || (d.validate != nil && !d.validate(text[m[0]:m[1]])) || partOfNumber(text, m[0], m[1]) {
+				continue
+			}
+			spans = append(spans, span{m[0], m[1], d.kind})
+		}
+	}
+	if len(spans) == 0 {
+		return text, values
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	placeholders := make(map[string]string, len(values))
+	counts := make(map[PIIKind]int)
+	for p, v := range values {
+		placeholders[v] = p
+		// Placeholders are <KIND_N>
+		counts[PIIKind(p[1:strings.LastIndexByte(p, '_')])]++
+	}
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		original := text[s.start:s.end]
+		p, ok := placeholders[original]
+		if !ok {
+			counts[s.kind]++
+			p = fmt.Sprintf("<%s_%d>", s.kind, counts[s.kind])
+			placeholders[original], values[p] = p, original
+		}
+		b.WriteString(text[last:s.start])
+		b.WriteString(p)
+		last = s.end
+	}
+	b.WriteString(text[last:])
+	return b.String(), values
+}
+
+func (g *PII) CheckInput(_ context.Context, req *generator.Request) (Decision, error) {
+	var values map[string]string
+	messages := make([]generator.Message, len(req.Messages))
+	changed := false
+	for i, m := range req.Messages {
+		redacted, v := g.Redact(m.Content, values)
+		values = v
+		changed = changed || redacted != m.Content
+		m.Content = redacted
+		messages[i] = m
+	}
+	if !changed {
+		return Decision{}, nil
+	}
+
+	d := Decision{Action: Rewrite, Messages: messages, Reason: fmt.Sprintf("redacted %d values", len(values))}
+	if g.Restore {
+		id := uuid.New().String()
+		g.vaults.Store(id, values)
+		d.Annotations = map[string]string{metadataVault: id}
+	}
+	return d, nil
+}
+
+// Release implements Releaser, dropping the originals redacted from req
+func (g *PII) Release(req *generator.Request) {
+	if id, ok := req.Metadata[metadataVault]; ok {
+		g.vaults.Delete(id)
+	}
+}
+
+func (g *PII) CheckOutput(_ context.Context, req *generator.Request, resp *generator.Response) (Decision, error) {
+	id, ok := req.Metadata[metadataVault]
+	if !ok {
+		return Decision{}, nil
+	}
+	v, ok := g.vaults.LoadAndDelete(id)
+	if !ok {
+		return Decision{}, nil
+	}
+	content := resp.Content
+	for p, original := range v.(map[string]string) {
+		content = strings.ReplaceAll(content, p, original)
+	}
+	if content == resp.Content {
+		return Decision{}, nil
+	}
+	return Decision{Action: Rewrite, Content: content}, nil
+}
+
+func (g *PII) detects(kind PIIKind) bool {
+	if len(g.Kinds) == 0 {
+		return true
+	}
+	for _, k := range g.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// partOfNumber reports whether the match at text[start:end] continues a
+// longer run of digit groups, such as a phone-shaped slice of an invalid
+// card number
+func partOfNumber(text string, start, end int) bool {
+	isDigit := func(i int) bool { return i >= 0 && i < len(text) && text[i] >= '0' && text[i] <= '9' }
+	isSep := func(i int) bool { return i >= 0 && i < len(text) && (text[i] == ' ' || text[i] == '-') }
+	if !isDigit(start) && !isDigit(end-1) {
+		return false
+	}
+	return (isSep(start-1) && isDigit(start-2)) || (isSep(end) && isDigit(end+1))
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// luhn validates card numbers with the Luhn checksum
+func luhn(s string) bool {
+	d := digits(s)
+	if len(d) < 13 || len(d) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range d {
+		n := int(d[len(d)-1-i] - '0')
+		if i%2 == 1 {
+			if n *= 2; n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+	}
+	return sum%10 == 0
+}
+
+// validSSN rejects numbers never issued: area 000, 666 or 900-999, group 00
+// and serial 0000
+func validSSN(s string) bool {
+	area, group, serial := s[0:3], s[4:6], s[7:11]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// validPhone requires 7 to 15 digits, the E.164 range
+func validPhone(s string) bool {
+	n := len(digits(s))
+	return n >= 7 && n <= 15
+}
+
+// validIBAN checks the mod-97 checksum of an IBAN
+func validIBAN(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+	rearranged := s[4:] + s[:4]
+	rem := 0
+	for _, r := range rearranged {
+		switch {
+		case r >= '0' && r <= '9':
+			rem = (rem*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			rem = (rem*100 + int(r-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return rem == 1
+}
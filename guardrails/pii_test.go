@@ -0,0 +1,72 @@
+package guardrails
+
+import (
+	"context"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+func TestPII_Redact(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"mail jane.doe@example.com or jane.doe@example.com", "mail <EMAIL_1> or <EMAIL_1>"},
+		{"card 4111 1111 1111 1111, not 4111 1111 1111 1112", "card <CREDIT_CARD_1>, not 4111 1111 1111 1112"},
+		{"ssn 123-45-6789 but not 666-45-6789", "ssn <SSN_1> but not 666-45-6789"},
+		{"call +1 415-555-0132 today", "call <PHONE_1> today"},
+		{"iban GB82 WEST 1234 5698 7654 32", "iban <IBAN_1>"},
+		{"order 12345 shipped in 2024", "order 12345 shipped in 2024"},
+	}
+	g := &PII{}
+	for _, tt := range tests {
+		if got, _ := g.Redact(tt.input, nil); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	only := &PII{Kinds: []PIIKind{PIISSN}}
+	if got, _ := only.Redact("a@b.io 123-45-6789", nil); got != "a@b.io <SSN_1>" {
+		t.Errorf("Redact() with Kinds = %q", got)
+	}
+}
+
+func TestPII_Restore(t *testing.T) {
+	g := &PII{Restore: true}
+	p, _ := New(g)
+	req := &generator.Request{Messages: []generator.Message{
+		{Role: generator.SYSTEM, Content: "Support for bob@example.com"},
+		{Role: generator.USER, Content: "Email bob@example.com and alice@example.com"},
+	}}
+
+	sent, err := p.ProcessInput(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Messages[0].Content != "Support for <EMAIL_1>" || sent.Messages[1].Content != "Email <EMAIL_1> and <EMAIL_2>" {
+		t.Errorf("ProcessInput() = %+v", sent.Messages)
+	}
+
+	resp, err := p.ProcessOutput(context.Background(), sent, &generator.Response{Content: "Sent to <EMAIL_2>"})
+	if err != nil || resp.Content != "Sent to alice@example.com" {
+		t.Errorf("ProcessOutput() = %+v, %v", resp, err)
+	}
+	// Originals are dropped once restored
+	resp, _ = p.ProcessOutput(context.Background(), sent, &generator.Response{Content: "<EMAIL_2>"})
+	if resp.Content != "<EMAIL_2>" {
+		t.Errorf("ProcessOutput() restored twice: %q", resp.Content)
+	}
+}
+
+func TestPII_ReleaseBlocked(t *testing.T) {
+	g := &PII{Restore: true}
+	p, _ := New(g, MaxLength{MaxTokens: 1})
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "Email bob@example.com about the order"}}}
+	if _, err := p.ProcessInput(context.Background(), req); err == nil {
+		t.Fatal("ProcessInput() error = nil, want the request blocked")
+	}
+	if n := g.Vaults(); n != 0 {
+		t.Errorf("vaults after a blocked request = %d, want 0", n)
+	}
+}
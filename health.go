@@ -0,0 +1,76 @@
+package gollm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// ProviderHealth represents the outcome of probing a provider
+type ProviderHealth struct {
+	Provider string
+	Latency  time.Duration
+	Err      error // nil when healthy
+}
+
+// Healthy reports whether the probe succeeded
+func (h ProviderHealth) Healthy() bool {
+	return h.Err == nil
+}
+
+// HealthReport represents the health of the generators of a client
+type HealthReport struct {
+	// Providers holds the primary generator first, then the fallbacks
+	Providers []ProviderHealth
+}
+
+// Ready reports whether a generator is healthy, so the client can serve
+// requests
+func (r HealthReport) Ready() bool {
+	for _, p := range r.Providers {
+		if p.Healthy() {
+			return true
+		}
+	}
+	return false
+}
+
+// Health probes the primary and fallback generators at once, for readiness
+// checks. Generators implementing generator.HealthChecker are probed with it,
+// others with a one token generation. Probes are not retried, rate limited
+// or reported to hooks, and are bounded by the client timeout.
+func (c *Client) Health(ctx context.Context) HealthReport {
+	generators := append([]generator.Generator{c.llm}, c.fallbackGenerator...)
+	r := HealthReport{Providers: make([]ProviderHealth, len(generators))}
+	var wg sync.WaitGroup
+	for i, g := range generators {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Providers[i] = c.probe(ctx, g)
+		}()
+	}
+	wg.Wait()
+	return r
+}
+
+func (c *Client) probe(ctx context.Context, g generator.Generator) ProviderHealth {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	var err error
+	if hc, ok := g.(generator.HealthChecker); ok {
+		err = hc.CheckHealth(ctx)
+	} else {
+		_, err = g.Generate(ctx, &generator.Request{
+			Messages:  []generator.Message{{Role: generator.USER, Content: "ping"}},
+			MaxTokens: 1,
+		})
+	}
+	return ProviderHealth{Provider: g.GetName(), Latency: time.Since(start), Err: err}
+}
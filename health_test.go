@@ -0,0 +1,57 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+// checked is a generator probed with CheckHealth
+type checked struct {
+	*mock.Mock
+	err error
+}
+
+func (c *checked) CheckHealth(context.Context) error {
+	return c.err
+}
+
+func TestClient_Health(t *testing.T) {
+	down := errors.New("down")
+	primary := delayed("primary", 0, down)
+	slow := delayed("slow", time.Second, nil)
+	listed := &checked{Mock: delayed("listed", 0, down)}
+	client := NewClient(primary, WithTimeout(20*time.Millisecond), WithFallbackGenerators([]generator.Generator{slow, listed}))
+
+	r := client.Health(context.Background())
+	if len(r.Providers) != 3 {
+		t.Fatalf("Health() = %+v, want 3 providers", r)
+	}
+	want := []struct {
+		name string
+		err  error
+	}{{"primary", down}, {"slow", context.DeadlineExceeded}, {"listed", nil}}
+	for i, w := range want {
+		if p := r.Providers[i]; p.Provider != w.name || !errors.Is(p.Err, w.err) {
+			t.Errorf("Health().Providers[%d] = %+v, want %s with %v", i, p, w.name, w.err)
+		}
+	}
+	if !r.Ready() {
+		t.Error("Ready() = false with a healthy fallback")
+	}
+	if reqs := primary.Requests(); len(reqs) != 1 || reqs[0].MaxTokens != 1 {
+		t.Errorf("primary probed with %+v, want a one token generation", reqs)
+	}
+	if len(listed.Requests()) != 0 {
+		t.Error("HealthChecker generator probed with a generation")
+	}
+
+	listed.err = down
+	if client.Health(context.Background()).Ready() {
+		t.Error("Ready() = true with every provider down")
+	}
+}
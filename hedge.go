@@ -0,0 +1,69 @@
+package gollm
+
+import (
+	"context"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// WithHedging sends Generate requests not answered within delay to the
+// first fallback generator as well, returning whichever answers first and
+// cancelling the other. A primary failing before delay starts the hedge
+// at once. It trades the cost of duplicate requests for tail latency, so
+// suits interactive use; it requires WithFallbackGenerators and does not
+// apply to streams.
+func WithHedging(delay time.Duration) Option {
+	return func(c *Client) {
+		c.hedgeDelay = delay
+	}
+}
+
+type hedgeResult struct {
+	resp *generator.Response
+	err  error
+}
+
+// hedge races the primary generator against the first fallback, started
+// after the hedge delay
+func (c *Client) hedge(ctx context.Context, request *generator.Request) (*generator.Response, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered so the loser never blocks once cancelled
+	results := make(chan hedgeResult, 2)
+	send := func(g generator.Generator) {
+		resp, err := c.generateWith(ctx, g, request)
+		results <- hedgeResult{resp, err}
+	}
+	go send(c.llm)
+
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+	pending, hedged := 1, false
+	var first error
+	for {
+		select {
+		case <-timer.C:
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				return r.resp, nil
+			}
+			if first == nil {
+				first = r.err
+			}
+			if hedged && pending == 0 {
+				return nil, first
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if !hedged {
+			c.logger.DebugContext(ctx, "hedging request", "fallback", c.fallbackGenerator[0].GetName())
+			hedged = true
+			pending++
+			go send(c.fallbackGenerator[0])
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+// delayed answers with its name after delay, or fails with err
+func delayed(name string, delay time.Duration, err error) *mock.Mock {
+	m := mock.New()
+	m.Name = name
+	m.GenerateFunc = func(ctx context.Context, _ *generator.Request) (*generator.Response, error) {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &generator.Response{Content: name}, nil
+	}
+	return m
+}
+
+func TestClient_WithHedging(t *testing.T) {
+	failed := errors.New("failed")
+	tests := []struct {
+		name             string
+		primary, backup  *mock.Mock
+		want             string
+		wantErr          error
+		wantBackupCalled bool
+	}{
+		{"primary fast", delayed("primary", 0, nil), delayed("backup", 0, nil), "primary", nil, false},
+		{"primary slow", delayed("primary", time.Second, nil), delayed("backup", 0, nil), "backup", nil, true},
+		{"primary failed", delayed("primary", 0, failed), delayed("backup", 0, nil), "backup", nil, true},
+		{"both failed", delayed("primary", 0, failed), delayed("backup", 0, errors.New("also failed")), "", failed, true},
+	}
+	for _, tt := range tests {
+		client := NewClient(tt.primary, WithHedging(20*time.Millisecond), WithFallbackGenerators([]generator.Generator{tt.backup}))
+		start := time.Now()
+		resp, err := client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+		if !errors.Is(err, tt.wantErr) || (err == nil && resp.Content != tt.want) {
+			t.Errorf("%s: Generate() = %+v, %v, want %q, %v", tt.name, resp, err, tt.want, tt.wantErr)
+		}
+		if called := len(tt.backup.Requests()) > 0; called != tt.wantBackupCalled {
+			t.Errorf("%s: backup called = %v, want %v", tt.name, called, tt.wantBackupCalled)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("%s: Generate() took %v, want the slower provider cancelled", tt.name, elapsed)
+		}
+	}
+}
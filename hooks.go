@@ -0,0 +1,114 @@
+package gollm
+
+import (
+	"context"
+	"time"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/reranker"
+)
+
+// Operation names the client call an event belongs to
+type Operation string
+
+const (
+	OpGenerate       Operation = "generate"
+	OpGenerateStream Operation = "generate_stream"
+	OpEmbed          Operation = "embed"
+	OpRerank         Operation = "rerank"
+)
+
+// Event describes one provider request attempt
+type Event struct {
+	Operation Operation
+	Provider  string // Name of the generator, embedder or reranker
+	Model     string // Requested model, or the one that answered if known
+	Attempt   int    // 1 for the first attempt
+	Start     time.Time
+	// Latency is the duration of the attempt; for streams, until the stream
+	// opened, except for OnStreamEnd, until it closed
+	Latency          time.Duration
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Err              error
+	// Wait is the delay before the next attempt, set for OnRetry
+	Wait time.Duration
+	// RequestID identifies the attempt to the provider: the ID of the
+	// generated response, or the request ID of an API error
+	RequestID string
+	// Metadata is the Request.Metadata of Generate calls, else the metadata
+	// of WithRequestMetadata; it must not be modified
+	Metadata map[string]string
+	// Stream is the timing of the stream, set for OnStreamEnd
+	Stream *generator.StreamStats
+	// Request is the request of Generate and GenerateStream attempts as sent
+	// to the provider, past the input guardrails; it must not be modified
+	Request *generator.Request
+
+	// repeatable makes retry resend the request after ambiguous failures
+	repeatable bool
+}
+
+// Hooks are called around every provider request attempt, including retries.
+// Nil hooks are skipped. Hooks run synchronously and must not block.
+type Hooks struct {
+	OnRequestStart func(ctx context.Context, e Event)
+	OnResponse     func(ctx context.Context, e Event)
+	OnError        func(ctx context.Context, e Event)
+	OnRetry        func(ctx context.Context, e Event)
+	// OnStreamEnd is called once per GenerateStream call when its stream
+	// closes, with the usage, the first error and the timing of the stream
+	OnStreamEnd func(ctx context.Context, e Event)
+}
+
+// WithHooks adds hooks to the client; hooks of several calls all run, in the
+// order given
+func WithHooks(h Hooks) Option {
+	return func(c *Client) {
+		c.hooks = append(c.hooks, h)
+	}
+}
+
+type hookKind int
+
+const (
+	hookRequestStart hookKind = iota
+	hookResponse
+	hookError
+	hookRetry
+	hookStreamEnd
+)
+
+func (c *Client) emit(ctx context.Context, kind hookKind, e Event) {
+	c.logEvent(ctx, kind, e)
+	for _, h := range c.hooks {
+		f := [...]func(context.Context, Event){h.OnRequestStart, h.OnResponse, h.OnError, h.OnRetry, h.OnStreamEnd}[kind]
+		if f != nil {
+			f(ctx, e)
+		}
+	}
+}
+
+// setUsage copies the model and token usage of a response into e
+func setUsage(e *Event, resp any) {
+	switch r := resp.(type) {
+	case *generator.Response:
+		if r.Model != "" {
+			e.Model = r.Model
+		}
+		e.PromptTokens, e.CompletionTokens, e.TotalTokens = r.Usage.PromptTokens, r.Usage.CompletionTokens, r.Usage.TotalTokens
+		e.RequestID = r.ID
+	case *embedder.Response:
+		if r.Model != "" {
+			e.Model = r.Model
+		}
+		e.PromptTokens, e.TotalTokens = r.Usage.PromptTokens, r.Usage.TotalTokens
+	case *reranker.Response:
+		if r.Model != "" {
+			e.Model = r.Model
+		}
+		e.TotalTokens = r.Usage.TotalTokens
+	}
+}
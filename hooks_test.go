@@ -0,0 +1,58 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_WithHooks(t *testing.T) {
+	var events []string
+	var last Event
+	record := func(name string) func(context.Context, Event) {
+		return func(_ context.Context, e Event) {
+			events = append(events, name)
+			last = e
+		}
+	}
+	hooks := Hooks{
+		OnRequestStart: record("start"),
+		OnResponse:     record("response"),
+		OnError:        record("error"),
+		OnRetry:        record("retry"),
+	}
+
+	calls := 0
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		calls++
+		if calls == 1 {
+			return nil, &llmerrors.Error{StatusCode: 500, Err: errors.New("oops")}
+		}
+		return &generator.Response{Model: "m-1", Usage: generator.TokenUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}}, nil
+	}
+	client := NewClient(m, WithHooks(hooks), WithRetryBackoff(time.Millisecond, time.Millisecond))
+
+	_, err := client.Generate(context.Background(), &generator.Request{Model: "m", Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := []string{"start", "error", "retry", "start", "response"}
+	if len(events) != len(want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("events = %v, want %v", events, want)
+			break
+		}
+	}
+	if last.Operation != OpGenerate || last.Provider != "mock" || last.Model != "m-1" || last.Attempt != 2 || last.TotalTokens != 5 || last.Err != nil {
+		t.Errorf("OnResponse event = %+v", last)
+	}
+}
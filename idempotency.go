@@ -0,0 +1,35 @@
+package gollm
+
+import (
+	"github.com/google/uuid"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// WithIdempotencyKeys gives the Generate and GenerateStream requests without
+// an IdempotencyKey a random one, shared by their retries. Requests with a
+// key, given or generated, sent to a generator.IdempotencySupporter are also
+// retried after ambiguous network failures, which are otherwise not retried
+// as the provider may have billed them already.
+func WithIdempotencyKeys() Option {
+	return func(c *Client) {
+		c.idempotencyKeys = true
+	}
+}
+
+// idempotent returns request with an idempotency key when keys are
+// generated, copying it rather than changing the caller's request
+func (c *Client) idempotent(request *generator.Request) *generator.Request {
+	if !c.idempotencyKeys || request.IdempotencyKey != "" {
+		return request
+	}
+	r := *request
+	r.IdempotencyKey = uuid.NewString()
+	return &r
+}
+
+// repeatable reports whether request may be resent to g after an ambiguous
+// failure without being billed twice
+func repeatable(g generator.Generator, request *generator.Request) bool {
+	s, ok := g.(generator.IdempotencySupporter)
+	return ok && s.SupportsIdempotencyKeys() && request.IdempotencyKey != ""
+}
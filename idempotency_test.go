@@ -0,0 +1,64 @@
+package gollm
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+// idempotentMock is a mock supporting idempotency keys
+type idempotentMock struct {
+	*mock.Mock
+}
+
+func (idempotentMock) SupportsIdempotencyKeys() bool { return true }
+
+func TestClient_IdempotencyKeys(t *testing.T) {
+	var keys []string
+	newMock := func() *mock.Mock {
+		keys = nil
+		m := mock.New()
+		m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+			keys = append(keys, req.IdempotencyKey)
+			if len(keys) == 1 {
+				return nil, &url.Error{Op: "Post", URL: "https://api", Err: io.ErrUnexpectedEOF}
+			}
+			return &generator.Response{Content: "ok"}, nil
+		}
+		return m
+	}
+	backoff := WithRetryBackoff(time.Millisecond, time.Millisecond)
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+
+	client := NewClient(idempotentMock{newMock()}, WithIdempotencyKeys(), backoff)
+	if _, err := client.Generate(context.Background(), req); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("attempts sent keys %q, want one generated key twice", keys)
+	}
+	if req.IdempotencyKey != "" {
+		t.Errorf("caller's request got key %q", req.IdempotencyKey)
+	}
+
+	given := &generator.Request{Messages: req.Messages, IdempotencyKey: "order-42"}
+	client = NewClient(idempotentMock{newMock()}, backoff)
+	if _, err := client.Generate(context.Background(), given); err != nil || keys[1] != "order-42" {
+		t.Errorf("Generate() with a key = %v, sent keys %q", err, keys)
+	}
+
+	// Without support for keys, the request may be billed twice: no retry
+	client = NewClient(newMock(), WithIdempotencyKeys(), backoff)
+	if _, err := client.Generate(context.Background(), req); err == nil || len(keys) != 1 {
+		t.Errorf("Generate() = %v after %d attempts, want the network error after 1", err, len(keys))
+	}
+	client = NewClient(idempotentMock{newMock()}, backoff)
+	if _, err := client.Generate(context.Background(), req); err == nil || len(keys) != 1 || keys[0] != "" {
+		t.Errorf("Generate() without a key = %v after %d attempts, want the network error after 1", err, len(keys))
+	}
+}
@@ -0,0 +1,68 @@
+// Package imagegen provides interfaces and types for image generation.
+package imagegen
+
+import (
+	"context"
+	"net/http"
+)
+
+// Format represents how generated images are returned
+type Format string
+
+const (
+	FormatURL   Format = "url"   // Hosted by the provider, usually for a limited time
+	FormatBytes Format = "bytes" // Returned in Image.Data
+)
+
+// Image represents a generated image. URL or Data is set depending on the
+// requested Format and what the provider supports.
+type Image struct {
+	URL       string
+	Data      []byte
+	MediaType string
+	// RevisedPrompt is the prompt the provider actually used, if rewritten
+	RevisedPrompt string
+	Seed          int64
+}
+
+// ContentType returns MediaType, or the type detected from Data
+func (i Image) ContentType() string {
+	if i.MediaType != "" || len(i.Data) == 0 {
+		return i.MediaType
+	}
+	return http.DetectContentType(i.Data)
+}
+
+// Request represents an image generation request
+type Request struct {
+	Model  string
+	Prompt string
+	// NegativePrompt describes what to keep out of the image, where supported
+	NegativePrompt string
+	// N is the number of images, 1 when zero
+	N int
+	// Size is WIDTHxHEIGHT, e.g. "1024x1024"; providers map it to the closest
+	// size or aspect ratio they support
+	Size           string
+	Quality        string
+	Style          string
+	Format         Format
+	Seed           int64
+	User           string
+	ProviderParams map[string]interface{}
+}
+
+// Response represents an image generation response
+type Response struct {
+	Model  string
+	Images []Image
+}
+
+// ImageGenerator defines the interface for image generation
+type ImageGenerator interface {
+	// GenerateImage sends an image generation request
+	GenerateImage(ctx context.Context, req *Request) (*Response, error)
+
+	// GetImageGeneratorName returns the name of the implementation
+	GetImageGeneratorName() string
+}
@@ -0,0 +1,262 @@
+// Package keypool rotates requests among several API keys of a provider,
+// for teams sharding quota across keys. Keys that are rate limited or
+// rejected cool down before they are used again.
+package keypool
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/parikxxit/go-llm/llmerrors"
+)
+
+// Strategy represents how a pool picks the key of a request
+type Strategy int
+
+const (
+	// RoundRobin spreads requests evenly over the healthy keys
+	RoundRobin Strategy = iota
+	// Failover uses the first healthy key, moving to the next one when it is
+	// rate limited or rejected
+	Failover
+)
+
+// KeyHealth represents the state of a key
+type KeyHealth struct {
+	Key         string // Masked, e.g. "sk-...a1b2"
+	Requests    int
+	Failures    int // Network failures and error responses
+	RateLimited int
+	// CoolingUntil is when a rate limited or rejected key is used again
+	CoolingUntil time.Time
+}
+
+// Healthy reports whether the key is not cooling down
+func (h KeyHealth) Healthy() bool {
+	return time.Now().After(h.CoolingUntil)
+}
+
+type key struct {
+	value string
+	KeyHealth
+}
+
+// Pool hands out API keys. It is safe for concurrent use.
+type Pool struct {
+	strategy     Strategy
+	cooldown     time.Duration
+	authCooldown time.Duration
+
+	mu   sync.Mutex
+	keys []*key
+	next int
+}
+
+// Option is a function that configures a Pool
+type Option func(*Pool)
+
+// WithStrategy sets how keys are picked, RoundRobin by default
+func WithStrategy(s Strategy) Option {
+	return func(p *Pool) {
+		p.strategy = s
+	}
+}
+
+// WithCooldown sets how long a rate limited key is skipped when the
+// provider does not say, a minute by default
+func WithCooldown(d time.Duration) Option {
+	return func(p *Pool) {
+		p.cooldown = d
+	}
+}
+
+// WithAuthCooldown sets how long a key the provider rejected, e.g. revoked
+// or out of quota, is skipped; an hour by default
+func WithAuthCooldown(d time.Duration) Option {
+	return func(p *Pool) {
+		p.authCooldown = d
+	}
+}
+
+// New creates a new pool of keys
+func New(keys []string, opts ...Option) (*Pool, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("keypool: no keys")
+	}
+	p := &Pool{cooldown: time.Minute, authCooldown: time.Hour}
+	for _, opt := range opts {
+		opt(p)
+	}
+	for _, k := range keys {
+		if k == "" {
+			return nil, errors.New("keypool: empty key")
+		}
+		p.keys = append(p.keys, &key{value: k, KeyHealth: KeyHealth{Key: mask(k)}})
+	}
+	return p, nil
+}
+
+// Acquire returns the key of the next request. When every key is cooling
+// down, the one available soonest is returned.
+func (p *Pool) Acquire() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.acquire(nil).value
+}
+
+// acquire picks a key not in tried. The lock must be held.
+func (p *Pool) acquire(tried map[*key]bool) *key {
+	now := time.Now()
+	var soonest *key
+	for i := range p.keys {
+		k := p.keys[(p.next+i)%len(p.keys)]
+		if tried[k] {
+			continue
+		}
+		if now.After(k.CoolingUntil) {
+			if p.strategy == RoundRobin {
+				p.next = (p.next + i + 1) % len(p.keys)
+			}
+			k.Requests++
+			return k
+		}
+		if soonest == nil || k.CoolingUntil.Before(soonest.CoolingUntil) {
+			soonest = k
+		}
+	}
+	if soonest != nil {
+		soonest.Requests++
+	}
+	return soonest
+}
+
+// Report records the outcome of a request made with key: its response
+// status, 0 for a network failure, and the wait the provider asked for, if
+// any
+func (p *Pool) Report(key string, status int, retryAfter time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, k := range p.keys {
+		if k.value == key {
+			p.report(k, status, retryAfter)
+			return
+		}
+	}
+}
+
+func (p *Pool) report(k *key, status int, retryAfter time.Duration) {
+	switch {
+	case status == http.StatusTooManyRequests:
+		k.RateLimited++
+		if retryAfter <= 0 {
+			retryAfter = p.cooldown
+		}
+		k.CoolingUntil = time.Now().Add(retryAfter)
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		k.Failures++
+		k.CoolingUntil = time.Now().Add(p.authCooldown)
+	case status == 0 || status >= 500:
+		k.Failures++
+	}
+}
+
+// Health returns the state of every key, in the order given to New
+func (p *Pool) Health() []KeyHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	health := make([]KeyHealth, len(p.keys))
+	for i, k := range p.keys {
+		health[i] = k.KeyHealth
+	}
+	return health
+}
+
+// Transport returns a RoundTripper sending requests through base, or
+// http.DefaultTransport when nil, with a key of the pool set by setKey, e.g.
+// Bearer. A rate limited or rejected request is resent at once with another
+// key, when one is healthy and the request body can be replayed.
+func (p *Pool) Transport(base http.RoundTripper, setKey func(*http.Request, string)) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{pool: p, base: base, setKey: setKey}
+}
+
+// Bearer sets key as the bearer token of req
+func Bearer(req *http.Request, key string) {
+	req.Header.Set("Authorization", "Bearer "+key)
+}
+
+type transport struct {
+	pool   *Pool
+	base   http.RoundTripper
+	setKey func(*http.Request, string)
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tried := map[*key]bool{}
+	for {
+		t.pool.mu.Lock()
+		k := t.pool.acquire(tried)
+		t.pool.mu.Unlock()
+		tried[k] = true
+
+		r := req.Clone(req.Context())
+		if len(tried) > 1 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		t.setKey(r, k.value)
+		res, err := t.base.RoundTrip(r)
+
+		status := 0
+		if err == nil {
+			status = res.StatusCode
+		}
+		t.pool.mu.Lock()
+		t.pool.report(k, status, retryAfter(res))
+		switched := status == http.StatusTooManyRequests || status == http.StatusUnauthorized || status == http.StatusForbidden
+		again := switched && t.canReplay(req) && t.pool.hasHealthy(tried)
+		t.pool.mu.Unlock()
+		if !again {
+			return res, err
+		}
+		res.Body.Close()
+	}
+}
+
+func (t *transport) canReplay(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// hasHealthy reports whether a key not in tried is healthy. The lock must be
+// held.
+func (p *Pool) hasHealthy(tried map[*key]bool) bool {
+	now := time.Now()
+	for _, k := range p.keys {
+		if !tried[k] && now.After(k.CoolingUntil) {
+			return true
+		}
+	}
+	return false
+}
+
+func retryAfter(res *http.Response) time.Duration {
+	if res == nil {
+		return 0
+	}
+	return llmerrors.RetryAfter(res.Header, time.Now())
+}
+
+// mask hides all but the ends of key
+func mask(key string) string {
+	if len(key) <= 8 {
+		return "..."
+	}
+	return key[:3] + "..." + key[len(key)-4:]
+}
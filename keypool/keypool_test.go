@@ -0,0 +1,107 @@
+package keypool
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPool_Acquire(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Error("New(nil) error = nil, want an error")
+	}
+
+	p, _ := New([]string{"a", "b", "c"})
+	var got []string
+	for range 4 {
+		got = append(got, p.Acquire())
+	}
+	if strings.Join(got, "") != "abca" {
+		t.Errorf("RoundRobin Acquire() = %v, want a b c a", got)
+	}
+	p.Report("b", http.StatusTooManyRequests, time.Hour)
+	if k1, k2 := p.Acquire(), p.Acquire(); k1 != "c" || k2 != "a" {
+		t.Errorf("Acquire() with b rate limited = %s, %s, want c, a", k1, k2)
+	}
+
+	p, _ = New([]string{"a", "b"}, WithStrategy(Failover), WithCooldown(time.Hour))
+	if p.Acquire() != "a" || p.Acquire() != "a" {
+		t.Error("Failover Acquire() moved off a healthy key")
+	}
+	p.Report("a", http.StatusTooManyRequests, 0)
+	p.Report("b", http.StatusUnauthorized, 0)
+	// Every key cools down: a is available first
+	if k := p.Acquire(); k != "a" {
+		t.Errorf("Acquire() with every key cooling = %s, want a", k)
+	}
+	h := p.Health()
+	if h[0].RateLimited != 1 || h[0].Healthy() || h[1].Failures != 1 || h[0].Requests != 3 {
+		t.Errorf("Health() = %+v", h)
+	}
+}
+
+func TestPool_Transport(t *testing.T) {
+	var keys, bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		keys, bodies = append(keys, r.Header.Get("Authorization")), append(bodies, string(b))
+		if r.Header.Get("Authorization") == "Bearer sk-first-key-0001" {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	p, _ := New([]string{"sk-first-key-0001", "sk-second-key-0002"})
+	client := &http.Client{Transport: p.Transport(nil, Bearer)}
+	res, err := client.Post(srv.URL, "application/json", strings.NewReader(`{"q":1}`))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK || len(keys) != 2 || keys[1] != "Bearer sk-second-key-0002" || bodies[1] != `{"q":1}` {
+		t.Errorf("status %d after requests with keys %q and bodies %q", res.StatusCode, keys, bodies)
+	}
+	h := p.Health()
+	if h[0].Key != "sk-...0001" || h[0].Healthy() || time.Until(h[0].CoolingUntil) < 29*time.Second {
+		t.Errorf("Health() = %+v, want sk-...0001 cooling for 30s", h)
+	}
+
+	// With no healthy key left, the rate limited response is returned
+	p.Report("sk-second-key-0002", http.StatusTooManyRequests, time.Hour)
+	res, _ = client.Post(srv.URL, "application/json", strings.NewReader(`{}`))
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("status = %d with every key cooling, want 429", res.StatusCode)
+	}
+}
+
+func TestPool_Transport_NoBody(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Authorization"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	p, _ := New([]string{"sk-first-key-0001", "sk-second-key-0002"})
+	client := &http.Client{Transport: p.Transport(nil, Bearer)}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, http.NoBody)
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || len(keys) != 2 || keys[0] == keys[1] {
+		t.Errorf("status %d after requests with keys %q, want 200 from the second key", res.StatusCode, keys)
+	}
+}
@@ -4,40 +4,117 @@ package gollm
 import (
 	"context"
 	"fmt"
-	"os"
+	"log/slog"
+	"maps"
+	"slices"
 	"time"
 
+	"github.com/parikxxit/go-llm/cache"
 	"github.com/parikxxit/go-llm/embedder"
 	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/guardrails"
+	"github.com/parikxxit/go-llm/imagegen"
+	"github.com/parikxxit/go-llm/moderation"
 	"github.com/parikxxit/go-llm/reranker"
-	"github.com/rs/zerolog"
+	"github.com/parikxxit/go-llm/stt"
+	"github.com/parikxxit/go-llm/tokenizer"
+	"github.com/parikxxit/go-llm/tts"
 )
 
-// Client represents a gollm client for interacting with LLMs
+// Client represents a gollm client for interacting with LLMs. A Client is
+// safe for concurrent use by multiple goroutines once created; options must
+// not be applied to a client in use, derive a client with With instead.
 type Client struct {
-	llm               generator.Generator
-	embedder          embedder.Embedder
-	reranker          reranker.Reranker
-	retryCount        int
-	fallbackGenerator []generator.Generator
-	fallbackEmbedder  []embedder.Embedder
-	fallbackReranker  []reranker.Reranker
-	timeout           time.Duration
-	debug             bool
-	logger            zerolog.Logger
-}
-
-// NewClient creates a new gollm client with the specified LLM implementation
+	llm                generator.Generator
+	embedder           embedder.Embedder
+	baseEmbedder       embedder.Embedder // embedder without the cache
+	reranker           reranker.Reranker
+	imageGenerator     imagegen.ImageGenerator
+	synthesizer        tts.Synthesizer
+	transcriber        stt.Transcriber
+	moderator          moderation.Moderator
+	moderationScope    ModerationScope
+	guardrails         *guardrails.Pipeline
+	truncator          Truncator
+	streamResumes      int
+	streamBuffer       int
+	backpressure       Backpressure
+	stopEnforcement    bool
+	hooks              []Hooks
+	meter              *usageMeter
+	cache              cache.Store
+	cacheTTL           time.Duration
+	responseCache      ResponseCache
+	rateLimits         *rateLimits
+	concurrency        *concurrencyLimit
+	hedgeDelay         time.Duration
+	shadow             *shadow
+	maxQueue           int
+	generateMiddleware []func(GenerateFunc) GenerateFunc
+	streamMiddleware   []func(GenerateStreamFunc) GenerateStreamFunc
+	embedMiddleware    []func(EmbedFunc) EmbedFunc
+	rerankMiddleware   []func(RerankFunc) RerankFunc
+	retryCount         int
+	retryBaseDelay     time.Duration
+	retryMaxDelay      time.Duration
+	fallbackGenerator  []generator.Generator
+	fallbackEmbedder   []embedder.Embedder
+	fallbackReranker   []reranker.Reranker
+	timeout            time.Duration
+	timeouts           map[Operation]time.Duration
+	streamDeadline     time.Duration
+	idempotencyKeys    bool
+	models             *modelCatalog
+	capabilityChecks   bool
+	debug              bool
+	logger             *slog.Logger
+	embedBatchSize     int
+	embedConcurrency   int
+	rerankBatchSize    int
+	embedTokenLimit    int
+	embedOverflow      Overflow
+	embedCounter       tokenizer.Counter
+}
+
+// NewClient creates a new gollm client with the specified LLM implementation.
+// It panics when llm is nil and does not check option values; New reports
+// both as errors, for libraries that must not crash their host.
 func NewClient(llm generator.Generator, opts ...Option) *Client {
 	if llm == nil {
 		panic("llm cannot be nil")
 	}
+	client := newClient(llm, opts)
+	client.start()
+	return client
+}
 
+// New creates a new gollm client like NewClient, failing with a *ConfigError
+// when llm is nil or an option value is invalid, e.g. a negative retry count
+// or a zero timeout
+func New(llm generator.Generator, opts ...Option) (*Client, error) {
+	if llm == nil {
+		return nil, &ConfigError{Option: "llm", Reason: "generator is nil"}
+	}
+	client := newClient(llm, opts)
+	if err := client.validate(); err != nil {
+		return nil, err
+	}
+	client.start()
+	return client, nil
+}
+
+// newClient creates a client with opts applied
+func newClient(llm generator.Generator, opts []Option) *Client {
 	client := &Client{
-		llm:        llm,
-		retryCount: 3,
-		timeout:    30 * time.Second,
-		debug:      false,
+		llm:              llm,
+		retryCount:       3,
+		retryBaseDelay:   defaultRetryBaseDelay,
+		retryMaxDelay:    defaultRetryMaxDelay,
+		timeout:          30 * time.Second,
+		debug:            false,
+		embedConcurrency: defaultEmbedConcurrency,
+		meter:            &usageMeter{},
+		maxQueue:         -1,
 	}
 
 	// Check if the LLM implements additional capabilities
@@ -49,18 +126,83 @@ func NewClient(llm generator.Generator, opts ...Option) *Client {
 		client.reranker = r
 	}
 
+	if g, ok := llm.(imagegen.ImageGenerator); ok {
+		client.imageGenerator = g
+	}
+
+	if s, ok := llm.(tts.Synthesizer); ok {
+		client.synthesizer = s
+	}
+
+	if t, ok := llm.(stt.Transcriber); ok {
+		client.transcriber = t
+	}
+
+	if m, ok := llm.(moderation.Moderator); ok {
+		client.moderator = m
+	}
+
 	for _, opt := range opts {
 		opt(client)
 	}
-	// Initialize logger with default settings and generator name
-	client.logger = zerolog.New(os.Stdout).With().
-		Timestamp().
-		Str("generator", client.llm.GetName()).
-		Logger()
-
+	client.baseEmbedder = client.embedder
+	client.cacheEmbedder()
+	client.logger = newLogger(client.logger, client.debug, client.llm.GetName())
 	return client
 }
 
+// cacheEmbedder wraps the base embedder in the cache of the client, if any
+func (c *Client) cacheEmbedder() {
+	c.embedder = c.baseEmbedder
+	if c.cache != nil && c.embedder != nil {
+		c.embedder = cache.NewEmbedder(c.embedder, c.cache, c.cacheTTL)
+	}
+}
+
+// start starts the background work of the client
+func (c *Client) start() {
+	if c.meter.onSnapshot != nil && c.meter.snapshotEvery > 0 {
+		go c.meter.snapshot()
+	}
+}
+
+// With returns a client derived from c with opts applied, e.g. a shorter
+// timeout for a latency sensitive subsystem. The derived client shares the
+// providers, cache, rate limits and concurrency slots of c, along with its
+// usage: calls through either count towards the same usage and budget, and
+// options of the usage (WithBudget, WithUsageRetention and
+// WithUsageSnapshots) are ignored. Hooks and middleware given are added to
+// those of c. c is left unchanged.
+func (c *Client) With(opts ...Option) *Client {
+	d := *c
+	d.timeouts = maps.Clone(c.timeouts)
+	d.hooks = slices.Clip(c.hooks)
+	d.generateMiddleware = slices.Clip(c.generateMiddleware)
+	d.streamMiddleware = slices.Clip(c.streamMiddleware)
+	d.embedMiddleware = slices.Clip(c.embedMiddleware)
+	d.rerankMiddleware = slices.Clip(c.rerankMiddleware)
+	d.fallbackGenerator = slices.Clip(c.fallbackGenerator)
+	d.fallbackEmbedder = slices.Clip(c.fallbackEmbedder)
+	d.fallbackReranker = slices.Clip(c.fallbackReranker)
+	d.meter = &usageMeter{}
+	d.logger = nil
+	d.embedder = c.baseEmbedder
+	for _, opt := range opts {
+		opt(&d)
+	}
+	d.meter = c.meter
+
+	d.baseEmbedder = d.embedder
+	d.cacheEmbedder()
+	switch {
+	case d.logger != nil, d.debug != c.debug:
+		d.logger = newLogger(d.logger, d.debug, d.llm.GetName())
+	default:
+		d.logger = c.logger
+	}
+	return &d
+}
+
 // WithEmbedder creates a new client with an additional embedder
 func WithEmbedder(emb embedder.Embedder) Option {
 	return func(c *Client) {
@@ -75,6 +217,27 @@ func WithReranker(rer reranker.Reranker) Option {
 	}
 }
 
+// WithImageGenerator creates a new client with an additional image generator
+func WithImageGenerator(gen imagegen.ImageGenerator) Option {
+	return func(c *Client) {
+		c.imageGenerator = gen
+	}
+}
+
+// WithSynthesizer creates a new client with an additional speech synthesizer
+func WithSynthesizer(syn tts.Synthesizer) Option {
+	return func(c *Client) {
+		c.synthesizer = syn
+	}
+}
+
+// WithTranscriber creates a new client with an additional transcriber
+func WithTranscriber(tr stt.Transcriber) Option {
+	return func(c *Client) {
+		c.transcriber = tr
+	}
+}
+
 // HasGenerator returns true if the client has a generator
 func (c *Client) HasGenerator() bool {
 	return c.llm != nil
@@ -90,94 +253,421 @@ func (c *Client) HasReranker() bool {
 	return c.reranker != nil
 }
 
-// Generate sends a text generation request to the LLM
-func (c *Client) Generate(ctx context.Context, request *generator.Request) (*generator.Response, error) {
+// HasImageGenerator returns true if the client has an image generator
+func (c *Client) HasImageGenerator() bool {
+	return c.imageGenerator != nil
+}
+
+// HasSynthesizer returns true if the client has a speech synthesizer
+func (c *Client) HasSynthesizer() bool {
+	return c.synthesizer != nil
+}
+
+// HasTranscriber returns true if the client has a transcriber
+func (c *Client) HasTranscriber() bool {
+	return c.transcriber != nil
+}
+
+// Generate sends a text generation request to the LLM; opts override the
+// client's defaults for this call
+func (c *Client) Generate(ctx context.Context, request *generator.Request, opts ...CallOption) (*generator.Response, error) {
 	if c.llm == nil {
 		return nil, fmt.Errorf("generator capability not available")
 	}
+	return chain(c.generateMiddleware, c.generate)(withCallOptions(ctx, opts), withMetadata(ctx, request))
+}
 
-	if c.debug {
-		c.logger.Info().Msgf("Generating Response for req:%s", request.Messages[0].Content)
+func (c *Client) generate(ctx context.Context, request *generator.Request) (*generator.Response, error) {
+	if err := c.checkBudget(); err != nil {
+		return nil, err
 	}
+	c.logger.DebugContext(ctx, "generating response", "model", request.Model, "messages", len(request.Messages))
 
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	request, err := c.guardrails.ProcessInput(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer c.guardrails.Release(request)
+	if err := c.moderateInput(ctx, request.Messages); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeoutFor(ctx, OpGenerate))
 	defer cancel()
 
-	resp, err := c.llm.Generate(ctx, request)
-	if err != nil {
-		// TODO: Add retry logic with fallback generators
+	resp, hit := c.cachedResponse(ctx, request)
+	if !hit {
+		resp, hit = c.lookupResponse(ctx, request)
+	}
+	if !hit {
+		start := time.Now()
+		if resp, err = c.generateOnce(ctx, request); err != nil {
+			return nil, err
+		}
+		resp.Cost = c.record(responseModel(resp.Model, request.Model), request.User, request.Metadata, resp.Usage)
+		c.cacheResponse(ctx, request, resp)
+		c.addResponse(ctx, request, resp)
+		c.mirror(ctx, request, resp, time.Since(start))
+	}
+
+	if err := c.moderateOutput(ctx, resp); err != nil {
 		return nil, err
 	}
+	return c.guardrails.ProcessOutput(ctx, request, resp)
+}
 
+// generateOnce sends request to the generator, hedged when configured
+func (c *Client) generateOnce(ctx context.Context, request *generator.Request) (*generator.Response, error) {
+	if c.hedgeDelay > 0 && len(c.fallbackGenerator) > 0 && callOpts(ctx).provider == "" {
+		return c.hedge(ctx, request)
+	}
+	g, err := c.generatorFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.generateWith(ctx, g, request)
+	if err != nil {
+		// TODO: Add fallback generators
+		return nil, err
+	}
 	return resp, nil
 }
 
-// GenerateStream sends a streaming text generation request to the LLM
-func (c *Client) GenerateStream(ctx context.Context, request *generator.Request) (<-chan *generator.Response, error) {
+// generateWith sends request to g, with retries and context length recovery
+func (c *Client) generateWith(ctx context.Context, g generator.Generator, request *generator.Request) (*generator.Response, error) {
+	if err := c.validateModel(ctx, g, request); err != nil {
+		return nil, err
+	}
+	if err := checkGrammar(g, request); err != nil {
+		return nil, err
+	}
+	request = c.idempotent(request)
+	generate := func() (*generator.Response, error) {
+		if err := c.waitRateLimit(ctx, g.GetName(), generateTokens(request)); err != nil {
+			return nil, err
+		}
+		release, err := c.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return g.Generate(ctx, prefilled(g, request))
+	}
+	send := func() (*generator.Response, error) {
+		if err := c.checkCapabilities(request); err != nil {
+			return nil, err
+		}
+		return retry(ctx, c, Event{Operation: OpGenerate, Provider: g.GetName(), Model: request.Model, Metadata: request.Metadata, Request: request, repeatable: repeatable(g, request)}, generate)
+	}
+	resp, err := send()
+	if err != nil && c.recoverable(err) {
+		if request, err = c.truncate(ctx, request, err); err == nil {
+			resp, err = send()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return prefillResponse(request, c.stopResponse(request, resp)), nil
+}
+
+// GenerateStream sends a streaming text generation request to the LLM; opts
+// override the client's defaults for this call. Chunks arrive in order, an
+// error as the last chunk, and the channel closes once the stream ends or
+// ctx is done; consumers must drain it or cancel ctx. The channel is
+// unbuffered unless WithStreamBuffer, so a slow consumer slows the reading
+// of the provider. Chunks are the consumer's to keep or to release with
+// generator.ReleaseChunk.
+func (c *Client) GenerateStream(ctx context.Context, request *generator.Request, opts ...CallOption) (<-chan *generator.Response, error) {
 	if c.llm == nil {
 		return nil, fmt.Errorf("generator capability not available")
 	}
+	ctx = withCallOptions(ctx, opts)
+	request = withMetadata(ctx, request)
+	stream, err := chain(c.streamMiddleware, c.generateStream)(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return c.bufferStream(ctx, c.meterStream(ctx, request, stream)), nil
+}
 
-	if c.debug {
-		c.logger.Info().Msgf("started streaming req with msg:%s", request.Messages[0].Content)
+func (c *Client) generateStream(ctx context.Context, request *generator.Request) (<-chan *generator.Response, error) {
+	start := time.Now()
+	if err := c.checkBudget(); err != nil {
+		return nil, err
+	}
+	g, err := c.generatorFor(ctx)
+	if err != nil {
+		return nil, err
 	}
+	if err := c.validateModel(ctx, g, request); err != nil {
+		return nil, err
+	}
+	if err := checkGrammar(g, request); err != nil {
+		return nil, err
+	}
+	c.logger.DebugContext(ctx, "starting stream", "model", request.Model, "messages", len(request.Messages))
 
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
+	request, err = c.guardrails.ProcessInput(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.moderateInput(ctx, request.Messages); err != nil {
+		c.guardrails.Release(request)
+		return nil, err
+	}
 
-	stream, err := c.llm.GenerateStream(ctx, request)
+	// The stream owns its context until it closes, not until we return.
+	// Opening it counts against the idle timeout.
+	parent := ctx
+	var cancel context.CancelFunc
+	if c.streamDeadline > 0 {
+		ctx, cancel = context.WithTimeout(parent, c.streamDeadline)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+	if c.guardrails != nil {
+		// Streams skip the output guards: release the request once done
+		guarded := request
+		context.AfterFunc(ctx, func() { c.guardrails.Release(guarded) })
+	}
+	idle := c.timeoutFor(ctx, OpGenerateStream)
+	if idle > 0 {
+		opening := time.AfterFunc(idle, cancel)
+		defer opening.Stop()
+	}
+
+	request = c.idempotent(request)
+	generate := func() (<-chan *generator.Response, error) {
+		if err := c.waitRateLimit(ctx, g.GetName(), generateTokens(request)); err != nil {
+			return nil, err
+		}
+		return c.openStream(ctx, g, request)
+	}
+	send := func() (<-chan *generator.Response, error) {
+		if err := c.checkCapabilities(request); err != nil {
+			return nil, err
+		}
+		return retry(ctx, c, Event{Operation: OpGenerateStream, Provider: g.GetName(), Model: request.Model, Metadata: request.Metadata, Request: request, repeatable: repeatable(g, request)}, generate)
+	}
+	stream, err := send()
+	if err != nil && c.recoverable(err) {
+		if request, err = c.truncate(ctx, request, err); err == nil {
+			stream, err = send()
+		}
+	}
 	if err != nil {
-		// TODO: Add retry logic with fallback generators
+		// TODO: Add fallback generators
+		cancel()
 		return nil, err
 	}
 
-	return stream, nil
+	if c.streamResumes > 0 {
+		stream = c.resumeStream(ctx, g, request, stream)
+	}
+	stream = prefillStream(ctx, request, c.stopStream(ctx, request, stream))
+	return c.timeStream(parent, start, g, request, watchStream(parent, ctx, cancel, idle, stream)), nil
 }
 
-// Embed sends an embedding request to the LLM
-func (c *Client) Embed(ctx context.Context, request *embedder.Request) (*embedder.Response, error) {
+// Embed sends an embedding request to the LLM. Inputs beyond the embedder's
+// batch limit are split into batches sent concurrently; the merged response
+// keeps the indexes of the original inputs. opts override the client's
+// defaults for this call.
+func (c *Client) Embed(ctx context.Context, request *embedder.Request, opts ...CallOption) (*embedder.Response, error) {
 	if c.embedder == nil {
 		return nil, fmt.Errorf("embedder capability not available")
 	}
+	return chain(c.embedMiddleware, c.embed)(withCallOptions(ctx, opts), request)
+}
+
+func (c *Client) embed(ctx context.Context, request *embedder.Request) (*embedder.Response, error) {
+	if err := c.checkBudget(); err != nil {
+		return nil, err
+	}
+	e, err := c.embedderFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := e.(embedder.ImageSupporter); len(request.Images) > 0 && !(ok && s.SupportsImages()) {
+		return nil, fmt.Errorf("embedder %s does not support image inputs", e.GetEmbedderName())
+	}
+	for _, out := range request.Outputs {
+		if !embedder.Supports(e, out) {
+			return nil, fmt.Errorf("embedder %s does not support %s embeddings", e.GetEmbedderName(), out)
+		}
+	}
 
-	if c.debug {
-		c.logger.Info().Msgf("embedding: %s with embedder: %s", request.Model, request.Input[0])
+	c.logger.DebugContext(ctx, "embedding", "model", request.Model, "embedder", e.GetEmbedderName(), "inputs", len(request.Input), "images", len(request.Images))
+
+	if c.embedTokenLimit > 0 {
+		return c.embedWithinLimit(ctx, request)
 	}
+	return c.embedAll(ctx, request)
+}
 
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
+func (c *Client) embedAll(ctx context.Context, request *embedder.Request) (*embedder.Response, error) {
+	e, err := c.embedderFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	size := c.embedBatchLimit(e)
+	if size <= 0 || len(request.Input)+len(request.Images) <= size {
+		return c.embedOnce(ctx, request)
+	}
+	return c.embedBatches(ctx, request, size)
+}
 
-	resp, err := c.embedder.Embed(ctx, request)
+func (c *Client) embedOnce(ctx context.Context, request *embedder.Request) (*embedder.Response, error) {
+	e, err := c.embedderFor(ctx)
 	if err != nil {
-		// TODO: Add retry logic with fallback embedders
 		return nil, err
 	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeoutFor(ctx, OpEmbed))
+	defer cancel()
 
+	resp, err := retry(ctx, c, Event{Operation: OpEmbed, Provider: e.GetEmbedderName(), Model: request.Model}, func() (*embedder.Response, error) {
+		if err := c.waitRateLimit(ctx, e.GetEmbedderName(), embedTokens(request)); err != nil {
+			return nil, err
+		}
+		release, err := c.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return e.Embed(ctx, request)
+	})
+	if err != nil {
+		// TODO: Add fallback embedders
+		return nil, err
+	}
+	c.record(responseModel(resp.Model, request.Model), request.User, RequestMetadata(ctx), generator.TokenUsage{
+		PromptTokens: resp.Usage.PromptTokens,
+		TotalTokens:  resp.Usage.TotalTokens,
+	})
+
+	if request.Truncate > 0 {
+		for i := range resp.Data {
+			resp.Data[i] = embedder.Truncate(resp.Data[i], request.Truncate)
+		}
+	}
 	return resp, nil
 }
 
-// Rerank sends a reranking request to the LLM
-func (c *Client) Rerank(ctx context.Context, request *reranker.Request) (*reranker.Response, error) {
+// Rerank sends a reranking request to the LLM. Documents beyond the
+// reranker's batch limit are split into batches sent concurrently, whose
+// results are merged by score. Scores are then normalized and filtered as
+// the request asks. opts override the client's defaults for this call.
+func (c *Client) Rerank(ctx context.Context, request *reranker.Request, opts ...CallOption) (*reranker.Response, error) {
 	if c.reranker == nil {
 		return nil, fmt.Errorf("reranker capability not available")
 	}
+	return chain(c.rerankMiddleware, c.rerank)(withCallOptions(ctx, opts), request)
+}
 
-	if c.debug {
-		c.logger.Info().Msgf("reranking matches")
+func (c *Client) rerank(ctx context.Context, request *reranker.Request) (*reranker.Response, error) {
+	r, err := c.rerankerFor(ctx)
+	if err != nil {
+		return nil, err
 	}
+	c.logger.DebugContext(ctx, "reranking", "model", request.Model, "reranker", r.GetRerankerName(), "documents", len(request.Documents))
 
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	var resp *reranker.Response
+	if size := c.rerankBatchLimit(r); size > 0 && len(request.Documents) > size {
+		resp, err = c.rerankBatches(ctx, request, size)
+	} else {
+		resp, err = c.rerankOnce(ctx, request)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Providers echo documents without metadata, if at all
+	for i, r := range resp.Results {
+		if r.Index >= 0 && r.Index < len(request.Documents) {
+			resp.Results[i].Document = request.Documents[r.Index]
+		}
+	}
+	if err := reranker.NormalizeScores(resp.Results, request.Normalize); err != nil {
+		return nil, err
+	}
+	resp.Results = reranker.FilterMinScore(resp.Results, request.MinScore)
+	return resp, nil
+}
+
+func (c *Client) rerankOnce(ctx context.Context, request *reranker.Request) (*reranker.Response, error) {
+	r, err := c.rerankerFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeoutFor(ctx, OpRerank))
 	defer cancel()
 
-	resp, err := c.reranker.Rerank(ctx, request)
+	resp, err := retry(ctx, c, Event{Operation: OpRerank, Provider: r.GetRerankerName(), Model: request.Model}, func() (*reranker.Response, error) {
+		if err := c.waitRateLimit(ctx, r.GetRerankerName(), rerankTokens(request)); err != nil {
+			return nil, err
+		}
+		release, err := c.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return r.Rerank(ctx, request)
+	})
 	if err != nil {
-		// TODO: Add retry logic with fallback rerankers
+		// TODO: Add fallback rerankers
 		return nil, err
 	}
 
 	return resp, nil
 }
 
+// GenerateImage sends an image generation request to the LLM
+func (c *Client) GenerateImage(ctx context.Context, request *imagegen.Request) (*imagegen.Response, error) {
+	if c.imageGenerator == nil {
+		return nil, fmt.Errorf("image generation capability not available")
+	}
+
+	c.logger.DebugContext(ctx, "generating image", "model", request.Model, "image_generator", c.imageGenerator.GetImageGeneratorName())
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return c.imageGenerator.GenerateImage(ctx, request)
+}
+
+// Synthesize sends a speech synthesis request to the LLM. The client timeout
+// covers the whole stream, until the audio is read or closed.
+func (c *Client) Synthesize(ctx context.Context, request *tts.Request) (*tts.Response, error) {
+	if c.synthesizer == nil {
+		return nil, fmt.Errorf("speech synthesis capability not available")
+	}
+
+	c.logger.DebugContext(ctx, "synthesizing speech", "model", request.Model, "synthesizer", c.synthesizer.GetSynthesizerName())
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	resp, err := c.synthesizer.Synthesize(ctx, request)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Audio = &cancelCloser{ReadCloser: resp.Audio, cancel: cancel}
+	return resp, nil
+}
+
+// Transcribe sends a transcription request to the LLM
+func (c *Client) Transcribe(ctx context.Context, request *stt.Request) (*stt.Response, error) {
+	if c.transcriber == nil {
+		return nil, fmt.Errorf("transcription capability not available")
+	}
+
+	c.logger.DebugContext(ctx, "transcribing audio", "model", request.Model, "transcriber", c.transcriber.GetTranscriberName())
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return c.transcriber.Transcribe(ctx, request)
+}
+
 // RetryCount returns the number of retries configured for the client
 func (c *Client) RetryCount() int {
 	return c.retryCount
@@ -211,7 +701,8 @@ func (c *Client) Debug() bool {
 // Option is a function that configures a Client
 type Option func(*Client)
 
-// WithRetryCount sets the number of retries for the client
+// WithRetryCount sets the number of retries of transient provider errors
+// (see llmerrors.Retryable) for the client
 func WithRetryCount(count int) Option {
 	return func(c *Client) {
 		c.retryCount = count
@@ -239,14 +730,81 @@ func WithFallbackRerankers(rerankers []reranker.Reranker) Option {
 	}
 }
 
-// WithTimeout sets the timeout for the client
+// WithTimeout sets the default timeout of every capability, 30s unless
+// set; see WithOperationTimeout
 func WithTimeout(timeout time.Duration) Option {
 	return func(c *Client) {
 		c.timeout = timeout
 	}
 }
 
-// WithDebug enables debug mode for the client
+// WithOperationTimeout sets the timeout of op calls, overriding WithTimeout.
+// For OpGenerateStream it is the idle timeout: streams run as long as chunks
+// keep arriving within it, which includes the wait for the first chunk, and
+// zero disables it. See WithStreamDeadline for an overall limit.
+func WithOperationTimeout(op Operation, timeout time.Duration) Option {
+	return func(c *Client) {
+		if c.timeouts == nil {
+			c.timeouts = map[Operation]time.Duration{}
+		}
+		c.timeouts[op] = timeout
+	}
+}
+
+// WithStreamDeadline limits the total duration of streams, from the request
+// to the last chunk; streams only have an idle timeout by default. Streams
+// cut short end with a chunk whose Err is context.DeadlineExceeded.
+func WithStreamDeadline(deadline time.Duration) Option {
+	return func(c *Client) {
+		c.streamDeadline = deadline
+	}
+}
+
+// WithEmbedBatchSize caps the inputs per embedding request, overriding the
+// limit reported by the embedder
+func WithEmbedBatchSize(size int) Option {
+	return func(c *Client) {
+		c.embedBatchSize = size
+	}
+}
+
+// WithEmbedConcurrency sets how many embedding batches are in flight at
+// once, 4 by default
+func WithEmbedConcurrency(n int) Option {
+	return func(c *Client) {
+		c.embedConcurrency = n
+	}
+}
+
+// WithRerankBatchSize caps the documents per reranking request, overriding
+// the limit reported by the reranker. Batches share the embedding
+// concurrency limit.
+func WithRerankBatchSize(size int) Option {
+	return func(c *Client) {
+		c.rerankBatchSize = size
+	}
+}
+
+// WithEmbedTokenLimit handles embedding inputs longer than limit tokens, as
+// measured by counter (tokenizer.ForModel of the request model when nil),
+// with overflow instead of letting the provider reject the whole batch
+func WithEmbedTokenLimit(limit int, overflow Overflow, counter tokenizer.Counter) Option {
+	return func(c *Client) {
+		c.embedTokenLimit, c.embedOverflow, c.embedCounter = limit, overflow, counter
+	}
+}
+
+// WithGuardrails runs the guards of p around Generate and GenerateStream.
+// Input guards run before moderation, so redactions apply to what the
+// moderator sees; output guards do not run on streams.
+func WithGuardrails(p *guardrails.Pipeline) Option {
+	return func(c *Client) {
+		c.guardrails = p
+	}
+}
+
+// WithDebug enables debug mode for the client, logging at debug level to
+// stdout unless WithLogger is given
 func WithDebug(debug bool) Option {
 	return func(c *Client) {
 		c.debug = debug
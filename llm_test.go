@@ -1,27 +1,315 @@
 package gollm
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/cache"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/reranker"
+	"github.com/parikxxit/go-llm/tokenizer"
 )
 
+// fakeEmbedder embeds each input as its length, and each image as its
+// negated size, and records batch sizes
+type fakeEmbedder struct {
+	limit  int
+	images bool
+
+	mu      sync.Mutex
+	batches []int
+}
+
+func (f *fakeEmbedder) Embed(_ context.Context, req *embedder.Request) (*embedder.Response, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, len(req.Input)+len(req.Images))
+	f.mu.Unlock()
+
+	resp := &embedder.Response{Model: req.Model, Usage: embedder.TokenUsage{PromptTokens: len(req.Input), TotalTokens: len(req.Input)}}
+	for i, in := range req.Input {
+		resp.Data = append(resp.Data, embedder.EmbedData{Embedding: []float64{float64(len(in))}, Index: i})
+	}
+	for i, img := range req.Images {
+		resp.Data = append(resp.Data, embedder.EmbedData{Embedding: []float64{-float64(len(img.Data))}, Index: len(req.Input) + i})
+	}
+	return resp, nil
+}
+
+func (f *fakeEmbedder) SupportsImages() bool { return f.images }
+
+func (f *fakeEmbedder) MaxBatchSize() int { return f.limit }
+
+func (f *fakeEmbedder) GetEmbedderName() string { return "fake" }
+
 func TestClient_Generate(t *testing.T) {
 	//TODO:implement
 }
 
 func TestClient_GenerateStream(t *testing.T) {
-	//TODO: implement
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+
+	// The stream's context outlives the call that started it
+	client := NewClient(&tickingStreamer{n: 3, interval: 10 * time.Millisecond})
+	stream, err := client.GenerateStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	if got, err := collect(t, stream); got != "..." || err != nil {
+		t.Errorf("GenerateStream() = %q, %v, want 3 chunks", got, err)
+	}
+
+	client = NewClient(&tickingStreamer{n: 10, interval: 10 * time.Millisecond}, WithStreamDeadline(35*time.Millisecond))
+	stream, _ = client.GenerateStream(context.Background(), req)
+	if got, err := collect(t, stream); len(got) >= 10 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GenerateStream() past its deadline = %q, %v, want %v", got, err, context.DeadlineExceeded)
+	}
+
+	client = NewClient(&tickingStreamer{n: 2, interval: 20 * time.Millisecond}, WithOperationTimeout(OpGenerateStream, 0))
+	stream, _ = client.GenerateStream(context.Background(), req)
+	if got, err := collect(t, stream); got != ".." || err != nil {
+		t.Errorf("GenerateStream() without idle timeout = %q, %v", got, err)
+	}
+
+	// Streams cancelled by the caller just end
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, _ = client.GenerateStream(ctx, req)
+	cancel()
+	for range stream {
+	}
 }
 
 func TestClient_Embed(t *testing.T) {
-	// TODO: implement
+	emb := &fakeEmbedder{limit: 3}
+	client := NewClient(mock.New(), WithEmbedder(emb), WithEmbedConcurrency(2))
+
+	input := make([]string, 8)
+	for i := range input {
+		input[i] = fmt.Sprintf("%*s", i+1, "")
+	}
+	resp, err := client.Embed(context.Background(), &embedder.Request{Model: "m", Input: input})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(emb.batches) != 3 {
+		t.Errorf("batches = %v, want 3 batches of at most 3", emb.batches)
+	}
+	if len(resp.Data) != 8 || resp.Usage.TotalTokens != 8 {
+		t.Fatalf("Embed() = %+v", resp)
+	}
+	for i, d := range resp.Data {
+		if d.Index != i || d.Embedding[0] != float64(i+1) {
+			t.Errorf("Data[%d] = %+v, want index %d of input %d", i, d, i, i)
+		}
+	}
+
+	sparse := &embedder.Request{Input: input, Outputs: []embedder.Output{embedder.OutputSparse}}
+	if _, err := client.Embed(context.Background(), sparse); err == nil {
+		t.Error("Embed() of sparse outputs from a dense embedder error = nil")
+	}
+	images := &embedder.Request{Input: input[:2], Images: []embedder.Image{{Data: []byte("a")}}}
+	if _, err := client.Embed(context.Background(), images); err == nil {
+		t.Error("Embed() of images from a text embedder error = nil")
+	}
+}
+
+func TestClient_EmbedImages(t *testing.T) {
+	emb := &fakeEmbedder{limit: 2, images: true}
+	client := NewClient(mock.New(), WithEmbedder(emb))
+
+	req := &embedder.Request{
+		Input:  []string{"a", "bb", "ccc"},
+		Images: []embedder.Image{{Data: []byte("1")}, {Data: []byte("22")}},
+	}
+	resp, err := client.Embed(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	want := []float64{1, 2, 3, -1, -2}
+	if len(resp.Data) != len(want) || len(emb.batches) != 3 {
+		t.Fatalf("Embed() = %+v in batches %v", resp.Data, emb.batches)
+	}
+	for i, d := range resp.Data {
+		if d.Index != i || d.Embedding[0] != want[i] {
+			t.Errorf("Data[%d] = %+v, want %v", i, d, want[i])
+		}
+	}
+}
+
+// letterEmbedder embeds each input as its counts of "a" and "b"
+type letterEmbedder struct {
+	inputs []string
+}
+
+func (e *letterEmbedder) Embed(_ context.Context, req *embedder.Request) (*embedder.Response, error) {
+	e.inputs = append(e.inputs, req.Input...)
+	resp := &embedder.Response{}
+	for i, in := range req.Input {
+		v := []float64{float64(strings.Count(in, "a")), float64(strings.Count(in, "b"))}
+		resp.Data = append(resp.Data, embedder.EmbedData{Embedding: v, Index: i})
+	}
+	return resp, nil
 }
 
+func (e *letterEmbedder) GetEmbedderName() string { return "letters" }
+
+func TestClient_EmbedTokenLimit(t *testing.T) {
+	words := tokenizer.CounterFunc(func(text string) int { return len(strings.Fields(text)) })
+	input := []string{"a a b b", "a a a b", "b"}
+
+	tests := []struct {
+		overflow Overflow
+		inputs   []string
+		want     [][]float64
+	}{
+		{TruncateHead, []string{"a a ", "a a ", "b"}, [][]float64{{2, 0}, {2, 0}, {0, 1}}},
+		{TruncateTail, []string{" b b", " a b", "b"}, [][]float64{{0, 2}, {1, 1}, {0, 1}}},
+		{SplitAverage, []string{"a a ", "b b", "a a ", "a b", "b"}, [][]float64{{0.7071, 0.7071}, {0.9487, 0.3162}, {0, 1}}},
+	}
+	for _, tt := range tests {
+		emb := &letterEmbedder{}
+		client := NewClient(mock.New(), WithEmbedder(emb), WithEmbedTokenLimit(2, tt.overflow, words))
+		resp, err := client.Embed(context.Background(), &embedder.Request{Input: input})
+		if err != nil {
+			t.Fatalf("Embed(%s) error = %v", tt.overflow, err)
+		}
+		if strings.Join(emb.inputs, "|") != strings.Join(tt.inputs, "|") {
+			t.Errorf("Embed(%s) sent %q, want %q", tt.overflow, emb.inputs, tt.inputs)
+		}
+		if len(resp.Data) != len(tt.want) {
+			t.Fatalf("Embed(%s) = %+v", tt.overflow, resp.Data)
+		}
+		for i, d := range resp.Data {
+			for j, want := range tt.want[i] {
+				if d.Index != i || math.Abs(d.Embedding[j]-want) > 1e-4 {
+					t.Errorf("Embed(%s) Data[%d] = %+v, want %v", tt.overflow, i, d, tt.want[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+// fakeReranker scores each document by its length, keeping the TopN best
+type fakeReranker struct {
+	limit int
+
+	mu      sync.Mutex
+	batches []int
+}
+
+func (f *fakeReranker) Rerank(_ context.Context, req *reranker.Request) (*reranker.Response, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, len(req.Documents))
+	f.mu.Unlock()
+
+	resp := &reranker.Response{Usage: reranker.TokenUsage{TotalTokens: len(req.Documents)}}
+	for i, d := range req.Documents {
+		resp.Results = append(resp.Results, reranker.Result{Document: d, Index: i, RelevanceScore: float64(len(d.Text))})
+	}
+	sort.Slice(resp.Results, func(i, j int) bool { return resp.Results[i].RelevanceScore > resp.Results[j].RelevanceScore })
+	if req.TopN > 0 && len(resp.Results) > req.TopN {
+		resp.Results = resp.Results[:req.TopN]
+	}
+	return resp, nil
+}
+
+func (f *fakeReranker) MaxBatchSize() int { return f.limit }
+
+func (f *fakeReranker) GetRerankerName() string { return "fake" }
+
 func TestClient_Rerank(t *testing.T) {
-	// TODO: implement
+	rer := &fakeReranker{limit: 3}
+	client := NewClient(mock.New(), WithReranker(rer))
+
+	lengths := []int{4, 9, 1, 7, 3, 8, 2}
+	docs := make([]reranker.Document, len(lengths))
+	for i, n := range lengths {
+		docs[i] = reranker.Document{Text: strings.Repeat("x", n), Metadata: map[string]any{"n": n}}
+	}
+	resp, err := client.Rerank(context.Background(), &reranker.Request{Documents: docs, TopN: 3})
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+	if len(rer.batches) != 3 || resp.Usage.TotalTokens != 7 {
+		t.Errorf("batches = %v, usage = %+v", rer.batches, resp.Usage)
+	}
+	want := []int{1, 5, 3}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("Rerank() = %+v", resp.Results)
+	}
+	for i, r := range resp.Results {
+		if r.Index != want[i] || r.Document.Metadata["n"] != lengths[want[i]] {
+			t.Errorf("Results[%d] = %+v, want document %d with its metadata", i, r, want[i])
+		}
+	}
+
+	resp, _ = client.Rerank(context.Background(), &reranker.Request{Documents: docs, Normalize: reranker.NormalizeMinMax, MinScore: 0.5})
+	if len(resp.Results) != 3 || resp.Results[0].RelevanceScore != 1 {
+		t.Errorf("Rerank() with MinScore = %+v, want the 3 documents at least 5 long", resp.Results)
+	}
+}
+
+func TestClient_retryDelay(t *testing.T) {
+	c := NewClient(mock.New(), WithRetryBackoff(500*time.Millisecond, 30*time.Second))
+	err := errors.New("bad gateway")
+	for _, attempt := range []int{0, 5, 35, 62, 63, 64, 100} {
+		d, _ := c.retryDelay(err, attempt)
+		if d <= 0 || d > 30*time.Second {
+			t.Errorf("retryDelay(%d) = %v, want within (0, 30s]", attempt, d)
+		}
+		if attempt >= 35 && d < 15*time.Second {
+			t.Errorf("retryDelay(%d) = %v, want at least half the max", attempt, d)
+		}
+	}
 }
 
 func TestClient_WithRetryCount(t *testing.T) {
-	// TODO: implement
+	rateLimited := &llmerrors.Error{Kind: llmerrors.ErrRateLimited, StatusCode: 429, RetryAfter: 10 * time.Millisecond, Err: errors.New("slow down")}
+	auth := &llmerrors.Error{Kind: llmerrors.ErrAuth, StatusCode: 401, Err: errors.New("bad key")}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		opts      []Option
+		wantCalls int
+		wantErr   error
+	}{
+		{"retry-after", []error{rateLimited, rateLimited}, nil, 3, nil},
+		{"exhausted", []error{rateLimited, rateLimited}, []Option{WithRetryCount(1)}, 2, llmerrors.ErrRateLimited},
+		{"not retryable", []error{auth}, nil, 1, llmerrors.ErrAuth},
+		{"wait above max", []error{rateLimited}, []Option{WithRetryBackoff(time.Millisecond, 5*time.Millisecond)}, 1, llmerrors.ErrRateLimited},
+		{"backoff", []error{&llmerrors.Error{StatusCode: 502, Err: errors.New("bad gateway")}}, []Option{WithRetryBackoff(time.Millisecond, time.Millisecond)}, 2, nil},
+	}
+	for _, tt := range tests {
+		calls := 0
+		m := mock.New()
+		m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+			calls++
+			if calls <= len(tt.errs) {
+				return nil, tt.errs[calls-1]
+			}
+			return &generator.Response{Content: "ok"}, nil
+		}
+		client := NewClient(m, tt.opts...)
+		start := time.Now()
+		_, err := client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+		if calls != tt.wantCalls || !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+			t.Errorf("%s: Generate() error = %v after %d calls, want %v after %d", tt.name, err, calls, tt.wantErr, tt.wantCalls)
+		}
+		if tt.name == "retry-after" && time.Since(start) < 20*time.Millisecond {
+			t.Errorf("%s: Generate() returned after %v, want the requested 20ms of waits", tt.name, time.Since(start))
+		}
+	}
 }
 
 func TestClient_WithFallbackGenerators(t *testing.T) {
@@ -35,3 +323,80 @@ func TestClient_WithTimeout(t *testing.T) {
 func TestClient_WithDebug(t *testing.T) {
 	//TODO: implement
 }
+
+func TestClient_With(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(ctx context.Context, _ *generator.Request) (*generator.Response, error) {
+		deadline, _ := ctx.Deadline()
+		return &generator.Response{Content: time.Until(deadline).Round(time.Second).String(), Usage: generator.TokenUsage{TotalTokens: 1}}, nil
+	}
+	var parentHooks, derivedHooks int
+	parent := NewClient(m, WithMaxConcurrency(1), WithHooks(Hooks{OnResponse: func(context.Context, Event) { parentHooks++ }}))
+	derived := parent.With(WithTimeout(time.Second), WithMaxQueue(0), WithHooks(Hooks{OnResponse: func(context.Context, Event) { derivedHooks++ }}))
+
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+	if resp, err := derived.Generate(context.Background(), req); err != nil || resp.Content != "1s" {
+		t.Errorf("derived Generate() = %v, %v, want a 1s deadline", resp, err)
+	}
+	if resp, err := parent.Generate(context.Background(), req); err != nil || resp.Content != "30s" {
+		t.Errorf("parent Generate() = %v, %v, want a 30s deadline", resp, err)
+	}
+	if parentHooks != 2 || derivedHooks != 1 {
+		t.Errorf("hooks ran %d times for the parent and %d for the derived client, want 2 and 1", parentHooks, derivedHooks)
+	}
+
+	// Usage and the concurrency slots are shared, the queue bound is not
+	if parent.Usage().Requests != 2 || derived.Usage() != parent.Usage() {
+		t.Errorf("Usage() = %+v and %+v, want 2 requests for both", parent.Usage(), derived.Usage())
+	}
+	if derived.concurrency.slots != parent.concurrency.slots || parent.concurrency.maxQueue != -1 {
+		t.Errorf("derived client changed the parent's queue bound to %d", parent.concurrency.maxQueue)
+	}
+}
+
+func TestClient_ConcurrentUse(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return &generator.Response{Content: "ok", Usage: generator.TokenUsage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2}}, nil
+	}
+	var hooks sync.Map
+	client := NewClient(m,
+		WithEmbedder(&fakeEmbedder{}),
+		WithCache(cache.NewLRU(10), time.Minute),
+		WithMaxConcurrency(4),
+		WithRateLimit(100000, 0),
+		WithHooks(Hooks{OnResponse: func(_ context.Context, e Event) { hooks.Store(e.Attempt, true) }}),
+	)
+
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := client
+			if i%2 == 1 {
+				c = client.With(WithTimeout(time.Minute))
+			}
+			req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: fmt.Sprint(i % 4)}}}
+			if _, err := c.Generate(context.Background(), req); err != nil {
+				t.Errorf("Generate() error = %v", err)
+			}
+			stream, err := c.GenerateStream(context.Background(), req)
+			if err != nil {
+				t.Errorf("GenerateStream() error = %v", err)
+				return
+			}
+			for range stream {
+			}
+			if _, err := c.Embed(context.Background(), &embedder.Request{Input: []string{fmt.Sprint(i % 4)}}); err != nil {
+				t.Errorf("Embed() error = %v", err)
+			}
+			c.Usage()
+			c.UsageReport(time.Time{})
+		}()
+	}
+	wg.Wait()
+	if got := client.Usage().Requests; got == 0 {
+		t.Errorf("Usage().Requests = %d, want the calls counted", got)
+	}
+}
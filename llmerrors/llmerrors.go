@@ -0,0 +1,208 @@
+// Package llmerrors provides a provider independent classification of API
+// errors, so callers can use errors.Is and errors.As instead of matching
+// provider messages.
+package llmerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrRateLimited           = errors.New("rate limited")
+	ErrAuth                  = errors.New("authentication failed")
+	ErrContextLengthExceeded = errors.New("context length exceeded")
+	ErrContentFiltered       = errors.New("content filtered")
+	ErrModelNotFound         = errors.New("model not found")
+	ErrOverloaded            = errors.New("provider overloaded")
+)
+
+// Error represents an error returned by a provider API. It matches its Kind
+// with errors.Is and unwraps to the provider error.
+type Error struct {
+	Kind       error // One of the Err values, nil when unclassified
+	Provider   string
+	StatusCode int
+	RequestID  string
+	// RetryAfter is the wait the provider asked for before retrying, from
+	// Retry-After or rate limit reset headers; zero when not given
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Provider, e.Err)
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", e.RequestID)
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+// New wraps err with its provider details, classifying it from the status
+// code and message
+func New(provider string, statusCode int, requestID string, err error) *Error {
+	return &Error{
+		Kind:       Classify(statusCode, err.Error()),
+		Provider:   provider,
+		StatusCode: statusCode,
+		RequestID:  requestID,
+		Err:        err,
+	}
+}
+
+// FromResponse builds the error of a failed HTTP response, reading at most
+// 1KB of its body into the message
+func FromResponse(provider string, res *http.Response) *Error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	err := fmt.Errorf("request failed with status %s: %s", res.Status, strings.TrimSpace(string(body)))
+	e := New(provider, res.StatusCode, RequestID(res.Header), err)
+	e.RetryAfter = RetryAfter(res.Header, time.Now())
+	return e
+}
+
+// requestIDHeaders are the headers providers return request IDs in
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id", "Apim-Request-Id", "Dg-Request-Id"}
+
+// RequestID returns the provider request ID of a response, if any
+func RequestID(h http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := h.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// rateLimitHeaders pairs the remaining and reset headers of the request and
+// token rate limits providers report
+var rateLimitHeaders = [][2]string{
+	{"X-Ratelimit-Remaining-Requests", "X-Ratelimit-Reset-Requests"},
+	{"X-Ratelimit-Remaining-Tokens", "X-Ratelimit-Reset-Tokens"},
+	{"Anthropic-Ratelimit-Requests-Remaining", "Anthropic-Ratelimit-Requests-Reset"},
+	{"Anthropic-Ratelimit-Tokens-Remaining", "Anthropic-Ratelimit-Tokens-Reset"},
+}
+
+// RetryAfter returns the wait requested by a response, from Retry-After-Ms,
+// Retry-After (seconds or an HTTP date) or the reset header of an exhausted
+// rate limit, or zero
+func RetryAfter(h http.Header, now time.Time) time.Duration {
+	if ms, err := strconv.ParseFloat(h.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			return max(time.Duration(secs*float64(time.Second)), 0)
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0)
+		}
+	}
+
+	var wait time.Duration
+	for _, pair := range rateLimitHeaders {
+		if h.Get(pair[0]) != "0" {
+			continue
+		}
+		wait = max(wait, resetIn(h.Get(pair[1]), now))
+	}
+	return wait
+}
+
+// resetIn parses a reset header, either a duration such as "1m30s" or
+// "250ms", seconds, or an RFC 3339 timestamp
+func resetIn(v string, now time.Time) time.Duration {
+	if d, err := time.ParseDuration(v); err == nil {
+		return max(d, 0)
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		return max(time.Duration(secs*float64(time.Second)), 0)
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+// Classify returns the Err value matching an API status code and error
+// message, or nil
+func Classify(statusCode int, message string) error {
+	msg := strings.ToLower(message)
+	switch {
+	case containsAny(msg, "context_length_exceeded", "context length", "maximum context", "too many tokens", "prompt is too long"):
+		return ErrContextLengthExceeded
+	case containsAny(msg, "content_filter", "content policy", "content_policy", "safety system"):
+		return ErrContentFiltered
+	}
+
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuth
+	case http.StatusNotFound:
+		if strings.Contains(msg, "model") {
+			return ErrModelNotFound
+		}
+	case http.StatusRequestEntityTooLarge:
+		return ErrContextLengthExceeded
+	case http.StatusServiceUnavailable, 529:
+		return ErrOverloaded
+	}
+	return nil
+}
+
+// Retryable reports whether err is a transient provider error: rate
+// limiting, overloading, a timeout, a conflict or a server error
+func Retryable(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	if e.Kind == ErrRateLimited || e.Kind == ErrOverloaded {
+		return true
+	}
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusConflict:
+		return true
+	}
+	return e.StatusCode >= 500
+}
+
+// Ambiguous reports whether err is a transport failure after which the
+// provider may or may not have received the request, e.g. a connection
+// reset or a response cut short. Retrying such a request is only safe with
+// an idempotency key.
+func Ambiguous(err error) bool {
+	var e *Error
+	if errors.As(err, &e) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package llmerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		status  int
+		message string
+		want    error
+	}{
+		{429, "slow down", ErrRateLimited},
+		{401, "invalid api key", ErrAuth},
+		{403, "forbidden", ErrAuth},
+		{404, "The model `gpt-9` does not exist", ErrModelNotFound},
+		{404, "no such file", nil},
+		{400, `{"code": "context_length_exceeded"}`, ErrContextLengthExceeded},
+		{400, "Your request was rejected by our safety system", ErrContentFiltered},
+		{503, "try again", ErrOverloaded},
+		{529, "overloaded_error", ErrOverloaded},
+		{500, "oops", nil},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.status, tt.message); got != tt.want {
+			t.Errorf("Classify(%d, %q) = %v, want %v", tt.status, tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestFromResponse(t *testing.T) {
+	res := &http.Response{
+		Status:     "429 Too Many Requests",
+		StatusCode: 429,
+		Header:     http.Header{"X-Request-Id": {"req_1"}},
+		Body:       io.NopCloser(strings.NewReader("rate limit reached\n")),
+	}
+	err := error(FromResponse("acme", res))
+	if !errors.Is(err, ErrRateLimited) || errors.Is(err, ErrAuth) {
+		t.Errorf("FromResponse() = %v, want ErrRateLimited", err)
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.StatusCode != 429 || e.RequestID != "req_1" || e.Provider != "acme" {
+		t.Errorf("FromResponse() = %+v", e)
+	}
+	want := "acme: request failed with status 429 Too Many Requests: rate limit reached (request ID req_1)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header http.Header
+		want   time.Duration
+	}{
+		{http.Header{"Retry-After": {"2"}}, 2 * time.Second},
+		{http.Header{"Retry-After": {now.Add(5 * time.Second).Format(http.TimeFormat)}}, 5 * time.Second},
+		{http.Header{"Retry-After-Ms": {"150"}, "Retry-After": {"1"}}, 150 * time.Millisecond},
+		{http.Header{"X-Ratelimit-Remaining-Tokens": {"0"}, "X-Ratelimit-Reset-Tokens": {"1m30s"}, "X-Ratelimit-Reset-Requests": {"5s"}}, 90 * time.Second},
+		{http.Header{"Anthropic-Ratelimit-Requests-Remaining": {"0"}, "Anthropic-Ratelimit-Requests-Reset": {now.Add(3 * time.Second).Format(time.RFC3339)}}, 3 * time.Second},
+		{http.Header{"X-Ratelimit-Remaining-Requests": {"10"}, "X-Ratelimit-Reset-Requests": {"5s"}}, 0},
+	}
+	for _, tt := range tests {
+		if got := RetryAfter(tt.header, now); got != tt.want {
+			t.Errorf("RetryAfter(%v) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestAmbiguous(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&url.Error{Op: "Post", URL: "https://api", Err: io.ErrUnexpectedEOF}, true},
+		{fmt.Errorf("reading: %w", syscall.ECONNRESET), true},
+		{&net.OpError{Op: "read", Net: "tcp", Err: errors.New("timeout")}, true},
+		{&Error{StatusCode: 500}, false},
+		{&url.Error{Op: "Post", URL: "https://api", Err: context.Canceled}, false},
+		{errors.New("invalid request"), false},
+	}
+	for _, tt := range tests {
+		if got := Ambiguous(tt.err); got != tt.want {
+			t.Errorf("Ambiguous(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
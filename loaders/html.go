@@ -0,0 +1,132 @@
+package loaders
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// boilerplate elements are dropped with their content
+var boilerplate = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+	atom.Nav:      true,
+	atom.Header:   true,
+	atom.Footer:   true,
+	atom.Aside:    true,
+	atom.Form:     true,
+	atom.Button:   true,
+}
+
+// blocks are separated from surrounding text by a line break
+var blocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Li: true, atom.Tr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Section: true, atom.Article: true, atom.Main: true, atom.Blockquote: true, atom.Pre: true,
+	atom.Table: true, atom.Ul: true, atom.Ol: true, atom.Dt: true, atom.Dd: true,
+}
+
+// HTML loads the visible text of a page as one document. Scripts, styles and
+// navigation, header, footer and sidebar elements are stripped, and only the
+// <main> or first <article> element is kept when there is one.
+type HTML struct {
+	// KeepBoilerplate disables stripping, keeping the text of the whole body
+	KeepBoilerplate bool
+}
+
+func (h HTML) Load(_ context.Context, r io.Reader, source string) ([]vectorstore.Document, error) {
+	root, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]any)
+	if title := find(root, atom.Title); title != nil {
+		metadata[MetadataTitle] = strings.TrimSpace(textContent(title))
+	}
+
+	content := find(root, atom.Body)
+	if !h.KeepBoilerplate {
+		if main := find(root, atom.Main); main != nil {
+			content = main
+		} else if article := find(root, atom.Article); article != nil {
+			content = article
+		}
+	}
+	if content == nil {
+		content = root
+	}
+
+	var b strings.Builder
+	h.render(&b, content)
+	return []vectorstore.Document{newDocument(source, "", collapse(b.String()), metadata)}, nil
+}
+
+func (h HTML) render(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		// Line breaks in the source are plain whitespace; blocks add their own
+		b.WriteString(strings.ReplaceAll(n.Data, "\n", " "))
+		return
+	case html.ElementNode:
+		// Scripts, styles and the head are never visible
+		if n.DataAtom == atom.Head || n.DataAtom == atom.Script || n.DataAtom == atom.Style {
+			return
+		}
+		if !h.KeepBoilerplate && boilerplate[n.DataAtom] {
+			return
+		}
+	}
+	block := n.Type == html.ElementNode && blocks[n.DataAtom]
+	if block {
+		b.WriteByte('\n')
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		h.render(b, c)
+	}
+	if block {
+		b.WriteByte('\n')
+	}
+}
+
+// collapse normalizes whitespace within lines and drops empty lines
+func collapse(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func find(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := find(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+// Package loaders turns files into documents ready for splitting and
+// embedding.
+package loaders
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// Metadata keys set by loaders. Fields of the input with the same name, e.g.
+// a "source" field of a JSONL record, are kept instead.
+const (
+	MetadataSource = "source"
+	MetadataPage   = "page"  // 1-based PDF page
+	MetadataRow    = "row"   // 1-based CSV data row or JSONL line
+	MetadataTitle  = "title" // HTML title or first Markdown heading
+)
+
+// Loader reads documents from r. Source identifies the input, typically a
+// file path or URL, and is recorded in the metadata of every document.
+type Loader interface {
+	Load(ctx context.Context, r io.Reader, source string) ([]vectorstore.Document, error)
+}
+
+// LoaderFunc is an adapter to allow the use of ordinary functions as loaders
+type LoaderFunc func(ctx context.Context, r io.Reader, source string) ([]vectorstore.Document, error)
+
+func (f LoaderFunc) Load(ctx context.Context, r io.Reader, source string) ([]vectorstore.Document, error) {
+	return f(ctx, r, source)
+}
+
+// ByExtension maps lower-case file extensions to the loaders used by LoadFile
+var ByExtension = map[string]Loader{
+	".txt":      Text{},
+	".md":       Markdown{},
+	".markdown": Markdown{},
+	".html":     HTML{},
+	".htm":      HTML{},
+	".pdf":      PDF{},
+	".csv":      CSV{},
+	".jsonl":    JSONL{},
+	".ndjson":   JSONL{},
+}
+
+// LoadFile loads the file at path with the loader registered for its
+// extension
+func LoadFile(ctx context.Context, path string) ([]vectorstore.Document, error) {
+	loader, ok := ByExtension[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, fmt.Errorf("no loader for %s", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	docs, err := loader.Load(ctx, f, path)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", path, err)
+	}
+	return docs, nil
+}
+
+// LoadDir loads every file under dir that has a registered loader
+func LoadDir(ctx context.Context, dir string) ([]vectorstore.Document, error) {
+	var docs []vectorstore.Document
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, ok := ByExtension[strings.ToLower(filepath.Ext(path))]; !ok {
+			return nil
+		}
+		loaded, err := LoadFile(ctx, path)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, loaded...)
+		return nil
+	})
+	return docs, err
+}
+
+// Text loads the whole input as one document
+type Text struct{}
+
+func (Text) Load(_ context.Context, r io.Reader, source string) ([]vectorstore.Document, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return []vectorstore.Document{newDocument(source, "", string(b), nil)}, nil
+}
+
+// newDocument creates a document with the source metadata. The ID is the
+// source, followed by suffix for inputs producing several documents.
+func newDocument(source, suffix, text string, metadata map[string]any) vectorstore.Document {
+	if metadata == nil {
+		metadata = make(map[string]any)
+	}
+	if _, ok := metadata[MetadataSource]; !ok {
+		metadata[MetadataSource] = source
+	}
+	return vectorstore.Document{ID: source + suffix, Text: text, Metadata: metadata}
+}
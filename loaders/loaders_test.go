@@ -0,0 +1,137 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTML_Load(t *testing.T) {
+	page := `<html><head><title> Guide </title><style>p{}</style></head><body>
+<nav>Home | About</nav>
+<main><h1>Install</h1><p>Run   the
+installer.</p><script>track()</script><ul><li>Step one</li></ul></main>
+<footer>Copyright</footer></body></html>`
+
+	docs, err := HTML{}.Load(context.Background(), strings.NewReader(page), "guide.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Install\nRun the installer.\nStep one"; docs[0].Text != want {
+		t.Errorf("Load() text = %q, want %q", docs[0].Text, want)
+	}
+	if docs[0].Metadata[MetadataTitle] != "Guide" || docs[0].Metadata[MetadataSource] != "guide.html" {
+		t.Errorf("Load() metadata = %v", docs[0].Metadata)
+	}
+}
+
+func TestMarkdown_Load(t *testing.T) {
+	docs, err := Markdown{}.Load(context.Background(), strings.NewReader("---\nauthor: Ann\n---\n# Intro\n\nHello"), "a.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := docs[0]
+	if d.Text != "# Intro\n\nHello" || d.Metadata["author"] != "Ann" || d.Metadata[MetadataTitle] != "Intro" {
+		t.Errorf("Load() = %+v", d)
+	}
+
+	docs, _ = Markdown{}.Load(context.Background(), strings.NewReader("---\r\nauthor: Ann\r\n---\r\n# Intro\r\n\r\nHello"), "a.md")
+	if d := docs[0]; d.Text != "# Intro\n\nHello" || d.Metadata["author"] != "Ann" {
+		t.Errorf("Load() with CRLF line endings = %+v", d)
+	}
+}
+
+func TestCSV_Load(t *testing.T) {
+	in := "name,city,id\nAnn,Oslo,1\nBob,Rome,2\n"
+	docs, err := CSV{TextColumns: []string{"name", "city"}, MetadataColumns: []string{"id"}}.Load(context.Background(), strings.NewReader(in), "people.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[1].Text != "name: Bob\ncity: Rome" || docs[1].Metadata["id"] != "2" || docs[1].Metadata[MetadataRow] != 2 {
+		t.Errorf("Load() = %+v", docs)
+	}
+	if docs[1].ID != "people.csv#row=2" {
+		t.Errorf("Load() ID = %s", docs[1].ID)
+	}
+	if _, err := (CSV{TextColumns: []string{"age"}}).Load(context.Background(), strings.NewReader(in), "x"); err == nil {
+		t.Error("Load() with unknown column error = nil")
+	}
+}
+
+func TestJSONL_Load(t *testing.T) {
+	in := `{"text":"first","lang":"en"}` + "\n\n" + `{"text":"second"}` + "\n"
+	docs, err := JSONL{}.Load(context.Background(), strings.NewReader(in), "data.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].Text != "first" || docs[0].Metadata["lang"] != "en" || docs[1].Metadata[MetadataRow] != 3 {
+		t.Errorf("Load() = %+v", docs)
+	}
+	docs, _ = JSONL{}.Load(context.Background(), strings.NewReader(`{"text":"x","source":"wiki","row":"r7"}`), "data.jsonl")
+	if m := docs[0].Metadata; m[MetadataSource] != "wiki" || m[MetadataRow] != "r7" {
+		t.Errorf("Load() metadata = %v, want the fields of the record", m)
+	}
+	if _, err := (JSONL{}).Load(context.Background(), strings.NewReader(`{"body":"x"}`), "x"); err == nil {
+		t.Error("Load() without text field error = nil")
+	}
+}
+
+func TestPDF_Load(t *testing.T) {
+	docs, err := PDF{}.Load(context.Background(), bytes.NewReader(minimalPDF("Hello PDF")), "doc.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || !strings.Contains(docs[0].Text, "Hello PDF") || docs[0].Metadata[MetadataPage] != 1 {
+		t.Errorf("Load() = %+v", docs)
+	}
+	if _, err := (PDF{}).Load(context.Background(), strings.NewReader("not a pdf"), "x.pdf"); err == nil {
+		t.Error("Load() of invalid pdf error = nil")
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("alpha"), 0o644)
+	os.WriteFile(filepath.Join(dir, "b.bin"), []byte{0}, 0o644)
+	os.Mkdir(filepath.Join(dir, "sub"), 0o755)
+	os.WriteFile(filepath.Join(dir, "sub", "c.md"), []byte("# C"), 0o644)
+
+	docs, err := LoadDir(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].Text != "alpha" || docs[1].Metadata[MetadataTitle] != "C" {
+		t.Errorf("LoadDir() = %+v", docs)
+	}
+}
+
+// minimalPDF builds a single page PDF showing text in Helvetica
+func minimalPDF(text string) []byte {
+	content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
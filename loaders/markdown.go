@@ -0,0 +1,47 @@
+package loaders
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// Markdown loads a Markdown file as one document, keeping the markup for
+// header-aware splitting. Simple "key: value" front matter is moved to the
+// metadata; the title defaults to the first heading. Line endings are
+// normalized to "\n".
+type Markdown struct{}
+
+func (Markdown) Load(_ context.Context, r io.Reader, source string) ([]vectorstore.Document, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]any)
+	text := strings.ReplaceAll(string(b), "\r\n", "\n")
+	if body, ok := strings.CutPrefix(text, "---\n"); ok {
+		if front, rest, ok := strings.Cut(body, "\n---\n"); ok {
+			for _, line := range strings.Split(front, "\n") {
+				if key, value, ok := strings.Cut(line, ":"); ok {
+					metadata[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+				}
+			}
+			text = rest
+		}
+	}
+
+	if _, ok := metadata[MetadataTitle]; !ok {
+		scanner := bufio.NewScanner(strings.NewReader(text))
+		for scanner.Scan() {
+			if title, ok := strings.CutPrefix(scanner.Text(), "# "); ok {
+				metadata[MetadataTitle] = strings.TrimSpace(title)
+				break
+			}
+		}
+	}
+	return []vectorstore.Document{newDocument(source, "", strings.TrimSpace(text), metadata)}, nil
+}
@@ -0,0 +1,53 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// PDF loads one document per page with text, recording the page number.
+// Scanned pages without a text layer produce no document.
+type PDF struct{}
+
+func (PDF) Load(ctx context.Context, r io.Reader, source string) (docs []vectorstore.Document, err error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// The parser panics on some malformed files
+	defer func() {
+		if p := recover(); p != nil {
+			docs, err = nil, fmt.Errorf("parsing pdf: %v", p)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i <= reader.NumPage(); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", i, err)
+		}
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+		docs = append(docs, newDocument(source, fmt.Sprintf("#page=%d", i), text, map[string]any{MetadataPage: i}))
+	}
+	return docs, nil
+}
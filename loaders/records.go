@@ -0,0 +1,126 @@
+package loaders
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// CSV loads one document per data row. The first row holds the column names.
+type CSV struct {
+	// TextColumns are rendered as "column: value" lines into the text; all
+	// columns when empty
+	TextColumns []string
+	// MetadataColumns are copied into the metadata
+	MetadataColumns []string
+	// Comma is the field delimiter, ',' when zero
+	Comma rune
+}
+
+func (c CSV) Load(ctx context.Context, r io.Reader, source string) ([]vectorstore.Document, error) {
+	reader := csv.NewReader(r)
+	if c.Comma != 0 {
+		reader.Comma = c.Comma
+	}
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	textColumns := c.TextColumns
+	if len(textColumns) == 0 {
+		textColumns = header
+	}
+	for _, name := range append(append([]string(nil), textColumns...), c.MetadataColumns...) {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+
+	var docs []vectorstore.Document
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+
+		lines := make([]string, len(textColumns))
+		for i, name := range textColumns {
+			lines[i] = name + ": " + field(name)
+		}
+		metadata := map[string]any{MetadataRow: row}
+		for _, name := range c.MetadataColumns {
+			metadata[name] = field(name)
+		}
+		docs = append(docs, newDocument(source, fmt.Sprintf("#row=%d", row), strings.Join(lines, "\n"), metadata))
+	}
+}
+
+// JSONL loads one document per line holding a JSON object. The text field
+// becomes the document text and the other fields its metadata.
+type JSONL struct {
+	// TextField is the field holding the text, "text" when empty
+	TextField string
+}
+
+func (j JSONL) Load(ctx context.Context, r io.Reader, source string) ([]vectorstore.Document, error) {
+	textField := j.TextField
+	if textField == "" {
+		textField = "text"
+	}
+
+	var docs []vectorstore.Document
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		b := bytes.TrimSpace(scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var record map[string]any
+		if err := json.Unmarshal(b, &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		text, ok := record[textField].(string)
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing string field %q", line, textField)
+		}
+		delete(record, textField)
+		if _, ok := record[MetadataRow]; !ok {
+			record[MetadataRow] = line
+		}
+		docs = append(docs, newDocument(source, fmt.Sprintf("#row=%d", line), text, record))
+	}
+	return docs, scanner.Err()
+}
@@ -0,0 +1,59 @@
+package gollm
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+// WithLogger logs the client's activity to l: attempts at debug level,
+// retries and recoveries at info and failures at warn, with the operation,
+// provider, model and provider request ID as attributes. This overrides
+// WithDebug's logger. Clients log nothing by default.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// newLogger returns the logger of a client configured with l, if any
+func newLogger(l *slog.Logger, debug bool, generator string) *slog.Logger {
+	switch {
+	case l != nil:
+		return l.With("generator", generator)
+	case debug:
+		h := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})
+		return slog.New(h).With("generator", generator)
+	}
+	return slog.New(discardHandler{})
+}
+
+// discardHandler is a slog.Handler dropping every record
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// logEvent logs an attempt described by e
+func (c *Client) logEvent(ctx context.Context, kind hookKind, e Event) {
+	attrs := []any{"op", e.Operation, "provider", e.Provider, "model", e.Model, "attempt", e.Attempt}
+	if e.RequestID != "" {
+		attrs = append(attrs, "request_id", e.RequestID)
+	}
+	if len(e.Metadata) > 0 {
+		attrs = append(attrs, "metadata", e.Metadata)
+	}
+	switch kind {
+	case hookResponse:
+		c.logger.DebugContext(ctx, "request completed", append(attrs, "latency", e.Latency, "total_tokens", e.TotalTokens)...)
+	case hookError:
+		c.logger.WarnContext(ctx, "request failed", append(attrs, "latency", e.Latency, "error", e.Err)...)
+	case hookStreamEnd:
+		if s := e.Stream; s != nil {
+			attrs = append(attrs, "ttft", s.TimeToFirstToken, "mean_inter_chunk", s.MeanInterChunk, "tokens_per_second", s.OutputTokensPerSecond)
+		}
+		c.logger.DebugContext(ctx, "stream ended", append(attrs, "duration", e.Latency, "total_tokens", e.TotalTokens, "error", e.Err)...)
+	}
+}
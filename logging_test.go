@@ -0,0 +1,51 @@
+package gollm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_WithLogger(t *testing.T) {
+	calls := 0
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		calls++
+		if calls == 1 {
+			return nil, &llmerrors.Error{Kind: llmerrors.ErrOverloaded, StatusCode: 529, RequestID: "req_1", Err: errors.New("overloaded")}
+		}
+		return &generator.Response{ID: "resp_2", Content: "ok"}, nil
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewClient(m, WithLogger(logger), WithRetryBackoff(time.Millisecond, time.Millisecond))
+
+	if _, err := client.Generate(context.Background(), &generator.Request{Model: "gpt-4o", Messages: []generator.Message{{Role: generator.USER, Content: "secret"}}}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	logs := buf.String()
+	for _, want := range []string{
+		`msg="request failed" generator=mock op=generate provider=mock model=gpt-4o attempt=1 request_id=req_1`,
+		`msg=retrying`,
+		`msg="request completed" generator=mock op=generate provider=mock model=gpt-4o attempt=2 request_id=resp_2`,
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs missing %q:\n%s", want, logs)
+		}
+	}
+	if strings.Contains(logs, "secret") {
+		t.Errorf("logs contain message content:\n%s", logs)
+	}
+
+	if client := NewClient(m); client.logger.Enabled(context.Background(), slog.LevelError) {
+		t.Error("client logs by default")
+	}
+}
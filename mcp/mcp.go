@@ -0,0 +1,386 @@
+// Package mcp provides a Model Context Protocol client exposing the tools of
+// MCP servers as gollm tools.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/tools"
+)
+
+const protocolVersion = "2024-11-05"
+
+// ErrClosed is returned for calls on a closed client or transport
+var ErrClosed = errors.New("mcp: connection closed")
+
+// Transport carries JSON-RPC messages to and from an MCP server
+type Transport interface {
+	// Start connects to the server
+	Start(ctx context.Context) error
+
+	// Send delivers one JSON-RPC message
+	Send(ctx context.Context, message []byte) error
+
+	// Messages returns the channel of incoming messages, closed when the
+	// connection ends
+	Messages() <-chan []byte
+
+	// Close terminates the connection
+	Close() error
+}
+
+// Tool represents a tool offered by a server
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// Content represents a piece of tool output
+type Content struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// CallToolResult represents the outcome of a tool call
+type CallToolResult struct {
+	Content []Content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+// Text returns the concatenated text content of the result
+func (r *CallToolResult) Text() string {
+	var parts []string
+	for _, c := range r.Content {
+		if c.Type == "text" {
+			parts = append(parts, c.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Resource represents a resource offered by a server
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceContent represents the content of a resource, as text or base64 blob
+type ResourceContent struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// ServerInfo represents the identity a server reported during initialization
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// RPCError represents a JSON-RPC error returned by a server
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("mcp: %s (code %d)", e.Message, e.Code)
+}
+
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  interface{}     `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// Client is a connection to one MCP server. It is safe for concurrent use.
+type Client struct {
+	transport Transport
+	info      ServerInfo
+	nextID    atomic.Int64
+
+	mu      sync.Mutex
+	pending map[int64]chan *message
+	closed  bool
+	done    chan struct{}
+}
+
+// Connect starts transport and performs the MCP initialization handshake
+func Connect(ctx context.Context, transport Transport) (*Client, error) {
+	if err := transport.Start(ctx); err != nil {
+		return nil, fmt.Errorf("mcp: starting transport: %w", err)
+	}
+
+	c := &Client{
+		transport: transport,
+		pending:   make(map[int64]chan *message),
+		done:      make(chan struct{}),
+	}
+	go c.readLoop()
+
+	var res struct {
+		ProtocolVersion string     `json:"protocolVersion"`
+		ServerInfo      ServerInfo `json:"serverInfo"`
+	}
+	err := c.call(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": "gollm", "version": "0.1.0"},
+	}, &res)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.info = res.ServerInfo
+
+	if err := c.notify(ctx, "notifications/initialized"); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// ServerInfo returns the identity of the connected server
+func (c *Client) ServerInfo() ServerInfo {
+	return c.info
+}
+
+// ListTools returns every tool offered by the server
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var all []Tool
+	cursor := ""
+	for {
+		var res struct {
+			Tools      []Tool `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", cursorParams(cursor), &res); err != nil {
+			return nil, err
+		}
+		all = append(all, res.Tools...)
+		if res.NextCursor == "" {
+			return all, nil
+		}
+		cursor = res.NextCursor
+	}
+}
+
+// CallTool invokes a tool with JSON encoded arguments
+func (c *Client) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*CallToolResult, error) {
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+	var res CallToolResult
+	err := c.call(ctx, "tools/call", map[string]interface{}{"name": name, "arguments": arguments}, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListResources returns every resource offered by the server
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	var all []Resource
+	cursor := ""
+	for {
+		var res struct {
+			Resources  []Resource `json:"resources"`
+			NextCursor string     `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "resources/list", cursorParams(cursor), &res); err != nil {
+			return nil, err
+		}
+		all = append(all, res.Resources...)
+		if res.NextCursor == "" {
+			return all, nil
+		}
+		cursor = res.NextCursor
+	}
+}
+
+// ReadResource returns the contents of a resource
+func (c *Client) ReadResource(ctx context.Context, uri string) ([]ResourceContent, error) {
+	var res struct {
+		Contents []ResourceContent `json:"contents"`
+	}
+	if err := c.call(ctx, "resources/read", map[string]string{"uri": uri}, &res); err != nil {
+		return nil, err
+	}
+	return res.Contents, nil
+}
+
+// Tools returns the server tools as gollm tools. Tool errors reported by the
+// server are returned as handler errors.
+func (c *Client) Tools(ctx context.Context) ([]tools.Tool, error) {
+	list, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]tools.Tool, 0, len(list))
+	for _, t := range list {
+		name := t.Name
+		out = append(out, tools.Tool{
+			Definition: toolDefinition(t),
+			Handler: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+				res, err := c.CallTool(ctx, name, arguments)
+				if err != nil {
+					return "", err
+				}
+				if res.IsError {
+					return "", errors.New(res.Text())
+				}
+				return res.Text(), nil
+			},
+		})
+	}
+	return out, nil
+}
+
+// Register adds every server tool to registry
+func (c *Client) Register(ctx context.Context, registry *tools.Registry) error {
+	list, err := c.Tools(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range list {
+		if err := registry.Register(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close terminates the connection and fails pending calls
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+	return c.transport.Close()
+}
+
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	id := c.nextID.Add(1)
+	ch := make(chan *message, 1)
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(ctx, message{ID: &id, Method: method, Params: params}); err != nil {
+		return err
+	}
+
+	select {
+	case res := <-ch:
+		if res.Error != nil {
+			return res.Error
+		}
+		if result == nil || len(res.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(res.Result, result); err != nil {
+			return fmt.Errorf("mcp: decoding %s result: %w", method, err)
+		}
+		return nil
+	case <-c.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) notify(ctx context.Context, method string) error {
+	return c.send(ctx, message{Method: method})
+}
+
+func (c *Client) send(ctx context.Context, m message) error {
+	m.JSONRPC = "2.0"
+	b, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("mcp: encoding %s: %w", m.Method, err)
+	}
+	return c.transport.Send(ctx, b)
+}
+
+func (c *Client) readLoop() {
+	defer close(c.done)
+	for raw := range c.transport.Messages() {
+		var m message
+		if err := json.Unmarshal(raw, &m); err != nil {
+			continue
+		}
+
+		switch {
+		case m.Method != "" && m.ID != nil:
+			c.answer(m)
+		case m.Method != "":
+			// Notifications (progress, list changes, logs) are not surfaced
+		case m.ID != nil:
+			// Delivered once: a duplicate response finds no call to block on
+			c.mu.Lock()
+			ch := c.pending[*m.ID]
+			delete(c.pending, *m.ID)
+			c.mu.Unlock()
+			if ch != nil {
+				ch <- &m
+			}
+		}
+	}
+}
+
+// answer responds to requests initiated by the server
+func (c *Client) answer(req message) {
+	res := message{ID: req.ID}
+	if req.Method == "ping" {
+		res.Result = json.RawMessage("{}")
+	} else {
+		res.Error = &RPCError{Code: -32601, Message: "method not found: " + req.Method}
+	}
+	_ = c.send(context.Background(), res)
+}
+
+func toolDefinition(t Tool) generator.Tool {
+	params := t.InputSchema
+	if params == nil {
+		params = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	return generator.Tool{Name: t.Name, Description: t.Description, Parameters: params}
+}
+
+func cursorParams(cursor string) interface{} {
+	if cursor == "" {
+		return nil
+	}
+	return map[string]string{"cursor": cursor}
+}
@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/tools"
+)
+
+// fakeServer is an in-process Transport answering like an MCP server
+type fakeServer struct {
+	messages chan []byte
+	copies   int // times each response is sent, once when zero
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{messages: make(chan []byte, 16)}
+}
+
+func (s *fakeServer) Start(context.Context) error { return nil }
+
+func (s *fakeServer) Messages() <-chan []byte { return s.messages }
+
+func (s *fakeServer) Close() error {
+	close(s.messages)
+	return nil
+}
+
+func (s *fakeServer) Send(_ context.Context, raw []byte) error {
+	var req struct {
+		ID     *int64          `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(raw, &req); err != nil || req.ID == nil {
+		return err
+	}
+
+	var result interface{}
+	switch req.Method {
+	case "initialize":
+		result = map[string]interface{}{"protocolVersion": protocolVersion, "serverInfo": ServerInfo{Name: "fake", Version: "1"}}
+	case "tools/list":
+		result = map[string]interface{}{"tools": []Tool{{Name: "echo", Description: "Echo text"}}}
+	case "tools/call":
+		var params struct {
+			Arguments struct {
+				Text string `json:"text"`
+			} `json:"arguments"`
+		}
+		json.Unmarshal(req.Params, &params)
+		result = CallToolResult{Content: []Content{{Type: "text", Text: params.Arguments.Text}}}
+	}
+	b, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": *req.ID, "result": result})
+	for range max(s.copies, 1) {
+		s.messages <- b
+	}
+	return nil
+}
+
+func TestClient_Tools(t *testing.T) {
+	ctx := context.Background()
+	c, err := Connect(ctx, newFakeServer())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	if c.ServerInfo().Name != "fake" {
+		t.Errorf("ServerInfo() = %+v", c.ServerInfo())
+	}
+
+	registry, _ := tools.NewRegistry()
+	if err := c.Register(ctx, registry); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	out, err := registry.Call(ctx, generator.ToolCall{Name: "echo", Arguments: `{"text":"hello"}`})
+	if err != nil || out != "hello" {
+		t.Errorf("Call() = %q, %v; want hello", out, err)
+	}
+}
+
+func TestClient_DuplicateResponses(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv := newFakeServer()
+	srv.copies = 3
+	c, err := Connect(ctx, srv)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	for range 3 {
+		if _, err := c.ListTools(ctx); err != nil {
+			t.Fatalf("ListTools() error = %v", err)
+		}
+	}
+}
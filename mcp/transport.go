@@ -0,0 +1,259 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/parikxxit/go-llm/transport"
+)
+
+const maxMessageSize = 16 * 1024 * 1024
+
+// StdioTransport runs a server as a subprocess and exchanges newline
+// delimited JSON over its stdin and stdout
+type StdioTransport struct {
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	messages chan []byte
+
+	mu        sync.Mutex
+	closeOnce sync.Once
+}
+
+// NewStdioTransport creates a transport for the server started by command.
+// The server's stderr is forwarded to the current process's stderr.
+func NewStdioTransport(command string, args ...string) *StdioTransport {
+	cmd := exec.Command(command, args...)
+	cmd.Stderr = os.Stderr
+	return NewCommandTransport(cmd)
+}
+
+// NewCommandTransport creates a transport for a prepared command, e.g. one
+// with a custom environment or working directory
+func NewCommandTransport(cmd *exec.Cmd) *StdioTransport {
+	return &StdioTransport{cmd: cmd, messages: make(chan []byte)}
+}
+
+func (t *StdioTransport) Start(context.Context) error {
+	stdin, err := t.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := t.cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := t.cmd.Start(); err != nil {
+		return err
+	}
+	t.stdin = stdin
+
+	go func() {
+		defer close(t.messages)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+				t.messages <- append([]byte(nil), line...)
+			}
+		}
+	}()
+	return nil
+}
+
+func (t *StdioTransport) Send(_ context.Context, message []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stdin == nil {
+		return ErrClosed
+	}
+	_, err := t.stdin.Write(append(message, '\n'))
+	return err
+}
+
+func (t *StdioTransport) Messages() <-chan []byte {
+	return t.messages
+}
+
+// Close closes the server's stdin and waits for it to exit
+func (t *StdioTransport) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		t.mu.Lock()
+		if t.stdin != nil {
+			t.stdin.Close()
+			t.stdin = nil
+		}
+		t.mu.Unlock()
+		if t.cmd.Process != nil {
+			err = t.cmd.Wait()
+		}
+	})
+	return err
+}
+
+// SSETransport connects to a server over HTTP: messages from the server
+// arrive as server-sent events and messages to it are POSTed to the endpoint
+// announced in the stream
+type SSETransport struct {
+	url        string
+	httpClient *http.Client
+	header     http.Header
+	messages   chan []byte
+
+	endpoint  string
+	body      io.ReadCloser
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+// NewSSETransport creates a transport for the SSE endpoint at url. A nil
+// httpClient uses transport.DefaultClient; header is sent with every request.
+func NewSSETransport(url string, httpClient *http.Client, header http.Header) *SSETransport {
+	if httpClient == nil {
+		httpClient = transport.DefaultClient()
+	}
+	return &SSETransport{url: url, httpClient: httpClient, header: header, messages: make(chan []byte)}
+}
+
+// Start opens the event stream and waits for the server to announce the
+// endpoint messages are posted to
+func (t *SSETransport) Start(ctx context.Context) error {
+	streamCtx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, t.url, nil)
+	if err != nil {
+		cancel()
+		return err
+	}
+	t.setHeader(req)
+	req.Header.Set("Accept", "text/event-stream")
+
+	res, err := t.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		cancel()
+		return fmt.Errorf("mcp: SSE connection failed with status %s", res.Status)
+	}
+	t.body, t.cancel = res.Body, cancel
+
+	endpoint := make(chan string, 1)
+	go t.readEvents(endpoint)
+
+	select {
+	case e, ok := <-endpoint:
+		if !ok {
+			t.Close()
+			return fmt.Errorf("mcp: SSE stream ended before the endpoint event")
+		}
+		base, err := url.Parse(t.url)
+		if err != nil {
+			t.Close()
+			return err
+		}
+		ref, err := base.Parse(e)
+		if err != nil {
+			t.Close()
+			return fmt.Errorf("mcp: invalid endpoint %q: %w", e, err)
+		}
+		t.endpoint = ref.String()
+		return nil
+	case <-ctx.Done():
+		t.Close()
+		return ctx.Err()
+	}
+}
+
+func (t *SSETransport) readEvents(endpoint chan<- string) {
+	defer close(t.messages)
+	announced := false
+	defer func() {
+		if !announced {
+			close(endpoint)
+		}
+	}()
+
+	scanner := bufio.NewScanner(t.body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	event, data := "", []string{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			payload := strings.Join(data, "\n")
+			switch {
+			case event == "endpoint" && !announced:
+				endpoint <- payload
+				announced = true
+			case event == "" || event == "message":
+				if payload != "" {
+					t.messages <- []byte(payload)
+				}
+			}
+			event, data = "", data[:0]
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+}
+
+func (t *SSETransport) Send(ctx context.Context, message []byte) error {
+	if t.endpoint == "" {
+		return ErrClosed
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(message))
+	if err != nil {
+		return err
+	}
+	t.setHeader(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("mcp: posting message failed with status %s: %s", res.Status, body)
+	}
+	return nil
+}
+
+func (t *SSETransport) Messages() <-chan []byte {
+	return t.messages
+}
+
+func (t *SSETransport) Close() error {
+	t.closeOnce.Do(func() {
+		if t.cancel != nil {
+			t.cancel()
+		}
+		if t.body != nil {
+			t.body.Close()
+		}
+	})
+	return nil
+}
+
+func (t *SSETransport) setHeader(req *http.Request) {
+	for key, values := range t.header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Package memory provides pluggable stores for conversation history.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// Store persists conversation messages by session ID. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	// Get returns the messages of a session in insertion order
+	Get(ctx context.Context, sessionID string) ([]generator.Message, error)
+
+	// Append adds messages to the end of a session
+	Append(ctx context.Context, sessionID string, messages ...generator.Message) error
+
+	// Trim keeps only the last keep messages of a session; keep <= 0 clears it
+	Trim(ctx context.Context, sessionID string, keep int) error
+}
+
+// InMemoryStore is a Store backed by a map, lost when the process exits
+type InMemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string][]generator.Message
+}
+
+// NewInMemoryStore creates a new empty in-memory store
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{sessions: make(map[string][]generator.Message)}
+}
+
+func (s *InMemoryStore) Get(_ context.Context, sessionID string) ([]generator.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]generator.Message(nil), s.sessions[sessionID]...), nil
+}
+
+func (s *InMemoryStore) Append(_ context.Context, sessionID string, messages ...generator.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[sessionID] = append(s.sessions[sessionID], messages...)
+	return nil
+}
+
+func (s *InMemoryStore) Trim(_ context.Context, sessionID string, keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := s.sessions[sessionID]
+	if keep <= 0 {
+		delete(s.sessions, sessionID)
+		return nil
+	}
+	if len(messages) > keep {
+		s.sessions[sessionID] = append([]generator.Message(nil), messages[len(messages)-keep:]...)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+func TestInMemoryStore_Trim(t *testing.T) {
+	ctx := context.Background()
+	s := NewInMemoryStore()
+	for _, c := range []string{"a", "b", "c"} {
+		if err := s.Append(ctx, "id", generator.Message{Role: generator.USER, Content: c}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	if err := s.Trim(ctx, "id", 2); err != nil {
+		t.Fatalf("Trim() error = %v", err)
+	}
+	got, _ := s.Get(ctx, "id")
+	if len(got) != 2 || got[0].Content != "b" {
+		t.Errorf("Get() = %+v, want [b c]", got)
+	}
+
+	if err := s.Trim(ctx, "id", 0); err != nil {
+		t.Fatalf("Trim() error = %v", err)
+	}
+	if got, _ := s.Get(ctx, "id"); len(got) != 0 {
+		t.Errorf("Get() = %+v, want empty", got)
+	}
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRedisPrefix = "gollm:session:"
+
+// RedisStore is a Store keeping each session as a Redis list of JSON messages
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// RedisOption is a function that configures a RedisStore
+type RedisOption func(*RedisStore)
+
+// WithKeyPrefix sets the prefix of the Redis keys holding sessions
+func WithKeyPrefix(prefix string) RedisOption {
+	return func(s *RedisStore) {
+		s.prefix = prefix
+	}
+}
+
+// NewRedisStore creates a new store using client
+func NewRedisStore(client redis.UniversalClient, opts ...RedisOption) *RedisStore {
+	s := &RedisStore{client: client, prefix: defaultRedisPrefix}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *RedisStore) Get(ctx context.Context, sessionID string) ([]generator.Message, error) {
+	items, err := s.client.LRange(ctx, s.key(sessionID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("reading session %s: %w", sessionID, err)
+	}
+
+	messages := make([]generator.Message, 0, len(items))
+	for _, item := range items {
+		var m generator.Message
+		if err := json.Unmarshal([]byte(item), &m); err != nil {
+			return nil, fmt.Errorf("decoding session %s: %w", sessionID, err)
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+func (s *RedisStore) Append(ctx context.Context, sessionID string, messages ...generator.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	items := make([]interface{}, 0, len(messages))
+	for _, m := range messages {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("encoding message: %w", err)
+		}
+		items = append(items, b)
+	}
+	if err := s.client.RPush(ctx, s.key(sessionID), items...).Err(); err != nil {
+		return fmt.Errorf("appending to session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Trim(ctx context.Context, sessionID string, keep int) error {
+	var err error
+	if keep <= 0 {
+		err = s.client.Del(ctx, s.key(sessionID)).Err()
+	} else {
+		err = s.client.LTrim(ctx, s.key(sessionID), int64(-keep), -1).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("trimming session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) key(sessionID string) string {
+	return s.prefix + sessionID
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisStore(t *testing.T) {
+	srv := miniredis.RunT(t)
+	s := NewRedisStore(redis.NewClient(&redis.Options{Addr: srv.Addr()}), WithKeyPrefix("test:"))
+	ctx := context.Background()
+
+	for _, c := range []string{"a", "b", "c"} {
+		if err := s.Append(ctx, "id", generator.Message{Role: generator.USER, Content: c}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	if !srv.Exists("test:id") {
+		t.Errorf("keys = %v, want test:id", srv.Keys())
+	}
+
+	if err := s.Trim(ctx, "id", 2); err != nil {
+		t.Fatalf("Trim() error = %v", err)
+	}
+	got, err := s.Get(ctx, "id")
+	if err != nil || len(got) != 2 || got[0].Content != "b" || got[1].Role != generator.USER {
+		t.Errorf("Get() = %+v, %v, want [b c]", got, err)
+	}
+
+	if err := s.Trim(ctx, "id", 0); err != nil {
+		t.Fatalf("Trim() error = %v", err)
+	}
+	if got, _ := s.Get(ctx, "id"); len(got) != 0 {
+		t.Errorf("Get() = %+v, want empty", got)
+	}
+}
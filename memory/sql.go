@@ -0,0 +1,200 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+const defaultSQLTable = "gollm_messages"
+
+// appendAttempts bounds the retries of appends racing other writers of the
+// session for the same sequence numbers
+const appendAttempts = 5
+
+// Placeholder formats the n-th (1-based) bind parameter of a query
+type Placeholder func(n int) string
+
+// QuestionPlaceholder is used by MySQL and SQLite
+func QuestionPlaceholder(int) string { return "?" }
+
+// DollarPlaceholder is used by PostgreSQL
+func DollarPlaceholder(n int) string { return "$" + strconv.Itoa(n) }
+
+// SQLStore is a Store backed by a database/sql table. Each row holds one JSON
+// encoded message, ordered by a per-session sequence number. Appends to the
+// same session from several processes retry when another one takes their
+// sequence numbers first.
+type SQLStore struct {
+	db          *sql.DB
+	table       string
+	placeholder Placeholder
+}
+
+// SQLOption is a function that configures a SQLStore
+type SQLOption func(*SQLStore)
+
+// WithTable sets the table holding messages
+func WithTable(table string) SQLOption {
+	return func(s *SQLStore) {
+		s.table = table
+	}
+}
+
+// WithPlaceholder sets the bind parameter style of the database driver
+func WithPlaceholder(p Placeholder) SQLOption {
+	return func(s *SQLStore) {
+		s.placeholder = p
+	}
+}
+
+// NewSQLStore creates a new store using db. Call CreateTable once to set up
+// the schema if it is not managed by migrations.
+func NewSQLStore(db *sql.DB, opts ...SQLOption) *SQLStore {
+	s := &SQLStore{db: db, table: defaultSQLTable, placeholder: QuestionPlaceholder}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateTable creates the messages table if it does not exist
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	session_id VARCHAR(255) NOT NULL,
+	seq BIGINT NOT NULL,
+	message TEXT NOT NULL,
+	PRIMARY KEY (session_id, seq)
+)`, s.table))
+	if err != nil {
+		return fmt.Errorf("creating table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+func (s *SQLStore) Get(ctx context.Context, sessionID string) ([]generator.Message, error) {
+	rows, err := s.db.QueryContext(ctx, s.query("SELECT message FROM %s WHERE session_id = %s ORDER BY seq"), sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("reading session %s: %w", sessionID, err)
+	}
+	defer rows.Close()
+
+	var messages []generator.Message
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("reading session %s: %w", sessionID, err)
+		}
+		var m generator.Message
+		if err := json.Unmarshal([]byte(raw), &m); err != nil {
+			return nil, fmt.Errorf("decoding session %s: %w", sessionID, err)
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+func (s *SQLStore) Append(ctx context.Context, sessionID string, messages ...generator.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	rows := make([]string, len(messages))
+	for i, m := range messages {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("encoding message: %w", err)
+		}
+		rows[i] = string(b)
+	}
+	for attempt := 1; ; attempt++ {
+		seq, err := s.append(ctx, sessionID, rows)
+		if err == nil || attempt == appendAttempts || ctx.Err() != nil {
+			return err
+		}
+		// The insert conflicts when another writer appended since seq was
+		// read; any other failure leaves the session as it was
+		if last, lerr := s.lastSeq(ctx, s.db, sessionID); lerr != nil || last == seq {
+			return err
+		}
+	}
+}
+
+// append inserts rows after the last message of the session in one
+// transaction, returning the sequence number it read
+func (s *SQLStore) append(ctx context.Context, sessionID string, rows []string) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("appending to session %s: %w", sessionID, err)
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	last, err := s.lastSeq(ctx, tx, sessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	insert := s.query("INSERT INTO %s (session_id, seq, message) VALUES (%s, %s, %s)")
+	for i, row := range rows {
+		if _, err := tx.ExecContext(ctx, insert, sessionID, last+int64(i)+1, row); err != nil {
+			return last, fmt.Errorf("appending to session %s: %w", sessionID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return last, fmt.Errorf("appending to session %s: %w", sessionID, err)
+	}
+	return last, nil
+}
+
+func (s *SQLStore) Trim(ctx context.Context, sessionID string, keep int) error {
+	if keep <= 0 {
+		if _, err := s.db.ExecContext(ctx, s.query("DELETE FROM %s WHERE session_id = %s"), sessionID); err != nil {
+			return fmt.Errorf("trimming session %s: %w", sessionID, err)
+		}
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("trimming session %s: %w", sessionID, err)
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	seq, err := s.lastSeq(ctx, tx, sessionID)
+	if err != nil {
+		return err
+	}
+	del := s.query("DELETE FROM %s WHERE session_id = %s AND seq <= %s")
+	if _, err := tx.ExecContext(ctx, del, sessionID, seq-int64(keep)); err != nil {
+		return fmt.Errorf("trimming session %s: %w", sessionID, err)
+	}
+	return tx.Commit()
+}
+
+// querier is a *sql.DB or *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (s *SQLStore) lastSeq(ctx context.Context, q querier, sessionID string) (int64, error) {
+	var seq int64
+	row := q.QueryRowContext(ctx, s.query("SELECT COALESCE(MAX(seq), 0) FROM %s WHERE session_id = %s"), sessionID)
+	if err := row.Scan(&seq); err != nil {
+		return 0, fmt.Errorf("reading session %s: %w", sessionID, err)
+	}
+	return seq, nil
+}
+
+// query fills the table name and bind parameters into format
+func (s *SQLStore) query(format string) string {
+	args := []interface{}{s.table}
+	for i := 1; i <= strings.Count(format, "%s")-1; i++ {
+		args = append(args, s.placeholder(i))
+	}
+	return fmt.Sprintf(format, args...)
+}
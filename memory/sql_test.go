@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+func TestSQLStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s := NewSQLStore(db, WithPlaceholder(DollarPlaceholder))
+	ctx := context.Background()
+
+	mock.ExpectQuery(`SELECT message FROM gollm_messages WHERE session_id = \$1 ORDER BY seq`).WithArgs("s").
+		WillReturnRows(sqlmock.NewRows([]string{"message"}).AddRow(`{"role":"user","content":"hi"}`))
+	if got, err := s.Get(ctx, "s"); err != nil || len(got) != 1 || got[0].Content != "hi" {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(seq\), 0\)`).WithArgs("s").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(5))
+	mock.ExpectExec(`DELETE FROM gollm_messages WHERE session_id = \$1 AND seq <= \$2`).WithArgs("s", 3).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+	if err := s.Trim(ctx, "s", 2); err != nil {
+		t.Errorf("Trim() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSQLStore_Append(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s := NewSQLStore(db)
+	ctx := context.Background()
+	lastSeq := func(seq int) {
+		mock.ExpectQuery(`SELECT COALESCE\(MAX\(seq\), 0\)`).WithArgs("s").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(seq))
+	}
+	insert := `INSERT INTO gollm_messages \(session_id, seq, message\) VALUES \(\?, \?, \?\)`
+
+	// Another writer takes seq 3 first: the append retries after it
+	mock.ExpectBegin()
+	lastSeq(2)
+	mock.ExpectExec(insert).WithArgs("s", 3, sqlmock.AnyArg()).WillReturnError(errors.New("duplicate key"))
+	mock.ExpectRollback()
+	lastSeq(3)
+	mock.ExpectBegin()
+	lastSeq(3)
+	mock.ExpectExec(insert).WithArgs("s", 4, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := s.Append(ctx, "s", generator.Message{Role: generator.USER, Content: "hi"}); err != nil {
+		t.Errorf("Append() racing another writer error = %v", err)
+	}
+
+	// Other failures are not retried
+	mock.ExpectBegin()
+	lastSeq(4)
+	mock.ExpectExec(insert).WithArgs("s", 5, sqlmock.AnyArg()).WillReturnError(errors.New("disk full"))
+	mock.ExpectRollback()
+	lastSeq(4)
+	if err := s.Append(ctx, "s", generator.Message{Role: generator.USER, Content: "hi"}); err == nil {
+		t.Error("Append() error = nil, want the insert error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,47 @@
+package gollm
+
+import (
+	"context"
+	"maps"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+type metadataKey struct{}
+
+// WithRequestMetadata returns a copy of ctx carrying metadata, given as key
+// value pairs, e.g. a tenant or feature tag set once by an HTTP middleware.
+// It is added to the Request.Metadata of the Generate and GenerateStream
+// calls made with ctx, where it takes precedence, and to the hook events,
+// logs and usage of every call. Pairs are merged into the metadata ctx
+// already carries; a trailing key without value is ignored.
+func WithRequestMetadata(ctx context.Context, kv ...string) context.Context {
+	md := maps.Clone(RequestMetadata(ctx))
+	if md == nil {
+		md = make(map[string]string, len(kv)/2)
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		md[kv[i]] = kv[i+1]
+	}
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// RequestMetadata returns the metadata carried by ctx, which must not be
+// modified
+func RequestMetadata(ctx context.Context) map[string]string {
+	md, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return md
+}
+
+// withMetadata returns request with the metadata of ctx added, copying it
+// rather than changing the caller's request
+func withMetadata(ctx context.Context, request *generator.Request) *generator.Request {
+	md := RequestMetadata(ctx)
+	if len(md) == 0 {
+		return request
+	}
+	r := *request
+	r.Metadata = maps.Clone(md)
+	maps.Copy(r.Metadata, request.Metadata)
+	return &r
+}
@@ -0,0 +1,51 @@
+package gollm
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestWithRequestMetadata(t *testing.T) {
+	var seen, events []map[string]string
+	mw := func(next GenerateFunc) GenerateFunc {
+		return func(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+			seen = append(seen, req.Metadata)
+			return next(ctx, req)
+		}
+	}
+	hooks := Hooks{OnResponse: func(_ context.Context, e Event) { events = append(events, e.Metadata) }}
+	client := NewClient(mock.New(), WithEmbedder(&fakeEmbedder{}), WithMiddleware(mw), WithHooks(hooks))
+
+	ctx := WithRequestMetadata(context.Background(), "tenant", "acme", "feature", "search")
+	ctx = WithRequestMetadata(ctx, "feature", "chat", "dangling")
+	if want := map[string]string{"tenant": "acme", "feature": "chat"}; !maps.Equal(RequestMetadata(ctx), want) {
+		t.Errorf("RequestMetadata() = %v, want %v", RequestMetadata(ctx), want)
+	}
+
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}, Metadata: map[string]string{"tenant": "globex"}}
+	if _, err := client.Generate(ctx, req); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := map[string]string{"tenant": "globex", "feature": "chat"}
+	if len(seen) != 1 || !maps.Equal(seen[0], want) {
+		t.Errorf("middleware metadata = %v, want %v", seen, want)
+	}
+	if len(req.Metadata) != 1 {
+		t.Errorf("caller's request metadata changed to %v", req.Metadata)
+	}
+	if _, err := client.Embed(ctx, &embedder.Request{Input: []string{"hi"}}); err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(events) != 2 || !maps.Equal(events[0], want) || events[1]["tenant"] != "acme" {
+		t.Errorf("event metadata = %v", events)
+	}
+	if u := client.UsageReport(time.Time{}).ByTag["feature=chat"]; u.Requests != 2 {
+		t.Errorf("usage of feature=chat = %+v, want 2 requests", u)
+	}
+}
@@ -0,0 +1,304 @@
+// Package metrics exposes Prometheus metrics for gollm clients: request and
+// error counts, latency, time to first token, inter-chunk latency and output
+// tokens per second of streams, tokens and estimated cost, labeled by
+// operation, provider and model.
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var labels = []string{"operation", "provider", "model"}
+
+// CostFunc returns the cost in dollars of a request to model
+type CostFunc func(model string, promptTokens, completionTokens int) float64
+
+// Collector records client events as Prometheus metrics. Register it on a
+// prometheus.Registerer and attach it to clients with Option.
+type Collector struct {
+	cost         CostFunc
+	metadataKeys []string
+
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	ttft     *prometheus.HistogramVec
+	gaps     *prometheus.HistogramVec
+	speed    *prometheus.HistogramVec
+	tokens   *prometheus.CounterVec
+	dollars  *prometheus.CounterVec
+}
+
+type config struct {
+	namespace    string
+	buckets      []float64
+	cost         CostFunc
+	metadataKeys []string
+}
+
+// Option is a function that configures a Collector
+type Option func(*config)
+
+// WithNamespace sets the metric name prefix, "gollm" by default
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithBuckets sets the buckets in seconds of the latency and time to first
+// token histograms
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.buckets = buckets
+	}
+}
+
+// WithCost enables the cost metric, estimated by f from token usage
+func WithCost(f CostFunc) Option {
+	return func(c *config) {
+		c.cost = f
+	}
+}
+
+// WithMetadataLabels adds a label for each of keys, valued by the request
+// metadata, e.g. a tenant from gollm.WithRequestMetadata. Keys must be valid
+// label names; keep their values few, as each makes its own series.
+func WithMetadataLabels(keys ...string) Option {
+	return func(c *config) {
+		c.metadataKeys = append(c.metadataKeys, keys...)
+	}
+}
+
+// New creates a new collector
+func New(opts ...Option) *Collector {
+	cfg := config{
+		namespace: "gollm",
+		buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	names := append(append([]string{}, labels...), cfg.metadataKeys...)
+	with := func(extra string) []string {
+		return append(append([]string{}, names...), extra)
+	}
+
+	return &Collector{
+		cost:         cfg.cost,
+		metadataKeys: cfg.metadataKeys,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "requests_total",
+			Help:      "Provider request attempts, including retries.",
+		}, names),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "errors_total",
+			Help:      "Failed provider request attempts by error class.",
+		}, with("class")),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Provider request latency; for streams, until the stream opened.",
+			Buckets:   cfg.buckets,
+		}, names),
+		ttft: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "time_to_first_token_seconds",
+			Help:      "Time from a GenerateStream call to its first content chunk.",
+			Buckets:   cfg.buckets,
+		}, names),
+		gaps: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "inter_chunk_latency_seconds",
+			Help:      "Mean gap between the content chunks of a stream.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 10),
+		}, names),
+		speed: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "output_tokens_per_second",
+			Help:      "Completion tokens per second of a stream after its first token.",
+			Buckets:   []float64{5, 10, 20, 50, 100, 200, 500, 1000},
+		}, names),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "tokens_total",
+			Help:      "Tokens used, by direction (input or output).",
+		}, with("direction")),
+		dollars: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "cost_dollars_total",
+			Help:      "Estimated cost of provider requests in dollars.",
+		}, names),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics() {
+		m.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.metrics() {
+		m.Collect(ch)
+	}
+}
+
+func (c *Collector) metrics() []prometheus.Collector {
+	ms := []prometheus.Collector{c.requests, c.errors, c.latency, c.ttft, c.gaps, c.speed, c.tokens}
+	if c.cost != nil {
+		ms = append(ms, c.dollars)
+	}
+	return ms
+}
+
+// Option attaches the collector to a client, through its hooks and stream
+// middleware
+func (c *Collector) Option() gollm.Option {
+	hooks := gollm.WithHooks(c.Hooks())
+	stream := gollm.WithStreamMiddleware(c.StreamMiddleware)
+	return func(cl *gollm.Client) {
+		hooks(cl)
+		stream(cl)
+	}
+}
+
+// Hooks returns the client hooks recording request metrics
+func (c *Collector) Hooks() gollm.Hooks {
+	return gollm.Hooks{
+		OnRequestStart: func(_ context.Context, e gollm.Event) {
+			c.requests.WithLabelValues(c.values(e)...).Inc()
+		},
+		OnResponse: func(ctx context.Context, e gollm.Event) {
+			c.latency.WithLabelValues(c.values(e)...).Observe(e.Latency.Seconds())
+			c.recordTokens(e)
+			if s, ok := ctx.Value(streamKey{}).(*streamLabels); ok && e.Operation == gollm.OpGenerateStream {
+				s.provider, s.model = e.Provider, e.Model
+			}
+		},
+		OnError: func(_ context.Context, e gollm.Event) {
+			c.latency.WithLabelValues(c.values(e)...).Observe(e.Latency.Seconds())
+			c.errors.WithLabelValues(c.values(e, Class(e.Err))...).Inc()
+		},
+		OnStreamEnd: func(_ context.Context, e gollm.Event) {
+			s := e.Stream
+			if s == nil || s.TimeToFirstToken == 0 {
+				return
+			}
+			c.ttft.WithLabelValues(c.values(e)...).Observe(s.TimeToFirstToken.Seconds())
+			if s.MeanInterChunk > 0 {
+				c.gaps.WithLabelValues(c.values(e)...).Observe(s.MeanInterChunk.Seconds())
+			}
+			if s.OutputTokensPerSecond > 0 {
+				c.speed.WithLabelValues(c.values(e)...).Observe(s.OutputTokensPerSecond)
+			}
+		},
+	}
+}
+
+func (c *Collector) recordTokens(e gollm.Event) {
+	input, output := e.PromptTokens, e.CompletionTokens
+	if input == 0 && output == 0 {
+		input = e.TotalTokens
+	}
+	if input == 0 && output == 0 {
+		return
+	}
+	c.tokens.WithLabelValues(c.values(e, "input")...).Add(float64(input))
+	c.tokens.WithLabelValues(c.values(e, "output")...).Add(float64(output))
+	if c.cost != nil {
+		c.dollars.WithLabelValues(c.values(e)...).Add(c.cost(e.Model, input, output))
+	}
+}
+
+// values returns the label values of e, followed by extra
+func (c *Collector) values(e gollm.Event, extra ...string) []string {
+	v := []string{string(e.Operation), e.Provider, e.Model}
+	for _, k := range c.metadataKeys {
+		v = append(v, e.Metadata[k])
+	}
+	return append(v, extra...)
+}
+
+type streamKey struct{}
+
+// streamLabels carries the provider and model of a stream, learned from the
+// hook of the attempt that opened it
+type streamLabels struct {
+	provider, model string
+}
+
+// StreamMiddleware records the token usage and errors of streams, which are
+// only known from their chunks; their timing is recorded by the OnStreamEnd
+// hook
+func (c *Collector) StreamMiddleware(next gollm.GenerateStreamFunc) gollm.GenerateStreamFunc {
+	return func(ctx context.Context, req *generator.Request) (<-chan *generator.Response, error) {
+		s := &streamLabels{model: req.Model}
+		in, err := next(context.WithValue(ctx, streamKey{}, s), req)
+		if err != nil {
+			return nil, err
+		}
+
+		out := make(chan *generator.Response)
+		go func() {
+			defer close(out)
+			event := gollm.Event{Operation: gollm.OpGenerateStream, Metadata: req.Metadata}
+			for chunk := range in {
+				event.Provider, event.Model = s.provider, s.model
+				if chunk.Err != nil {
+					c.errors.WithLabelValues(c.values(event, Class(chunk.Err))...).Inc()
+				}
+				event.PromptTokens = max(event.PromptTokens, chunk.Usage.PromptTokens)
+				event.CompletionTokens = max(event.CompletionTokens, chunk.Usage.CompletionTokens)
+				select {
+				case out <- chunk:
+				case <-ctx.Done():
+					return
+				}
+			}
+			event.Provider, event.Model = s.provider, s.model
+			c.recordTokens(event)
+		}()
+		return out, nil
+	}
+}
+
+// Class returns the error class label of err, e.g. "rate_limited" or
+// "timeout"
+func Class(err error) string {
+	for _, k := range []struct {
+		err   error
+		class string
+	}{
+		{llmerrors.ErrRateLimited, "rate_limited"},
+		{llmerrors.ErrAuth, "auth"},
+		{llmerrors.ErrContextLengthExceeded, "context_length"},
+		{llmerrors.ErrContentFiltered, "content_filtered"},
+		{llmerrors.ErrModelNotFound, "model_not_found"},
+		{llmerrors.ErrOverloaded, "overloaded"},
+		{context.Canceled, "canceled"},
+		{context.DeadlineExceeded, "timeout"},
+	} {
+		if errors.Is(err, k.err) {
+			return k.class
+		}
+	}
+	var e *llmerrors.Error
+	if errors.As(err, &e) {
+		if e.StatusCode >= 500 {
+			return "server"
+		}
+		return "client"
+	}
+	return "other"
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	calls := 0
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		calls++
+		if calls == 1 {
+			return nil, &llmerrors.Error{Kind: llmerrors.ErrRateLimited, StatusCode: 429, Err: errors.New("slow down")}
+		}
+		return &generator.Response{Content: "hi", Usage: generator.TokenUsage{PromptTokens: 10, CompletionTokens: 4}}, nil
+	}
+
+	c := New(WithCost(func(model string, in, out int) float64 { return float64(in+out) / 100 }))
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	client := gollm.NewClient(m, c.Option(), gollm.WithRetryBackoff(time.Millisecond, time.Millisecond))
+
+	req := &generator.Request{Model: "m", Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+	if _, err := client.Generate(context.Background(), req); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	stream, err := client.GenerateStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	for range stream {
+	}
+
+	tests := []struct {
+		metric prometheus.Collector
+		labels []string
+		want   float64
+	}{
+		{c.requests, []string{"generate", "mock", "m"}, 2},
+		{c.errors, []string{"generate", "mock", "m", "rate_limited"}, 1},
+		{c.tokens, []string{"generate", "mock", "m", "input"}, 10},
+		{c.tokens, []string{"generate_stream", "mock", "m", "output"}, 4},
+		{c.dollars, []string{"generate", "mock", "m"}, 0.14},
+	}
+	for _, tt := range tests {
+		vec, ok := tt.metric.(*prometheus.CounterVec)
+		if !ok {
+			t.Fatalf("metric %T is not a counter", tt.metric)
+		}
+		if got := testutil.ToFloat64(vec.WithLabelValues(tt.labels...)); got != tt.want {
+			t.Errorf("%v = %v, want %v", tt.labels, got, tt.want)
+		}
+	}
+	if n := testutil.CollectAndCount(c.ttft); n != 1 {
+		t.Errorf("time to first token series = %d, want 1", n)
+	}
+	if n, err := testutil.GatherAndCount(reg); err != nil || n == 0 {
+		t.Errorf("GatherAndCount() = %d, %v", n, err)
+	}
+}
+
+func TestCollector_MetadataLabels(t *testing.T) {
+	c := New(WithMetadataLabels("tenant"))
+	client := gollm.NewClient(mock.New(), c.Option())
+
+	ctx := gollm.WithRequestMetadata(context.Background(), "tenant", "acme")
+	req := &generator.Request{Model: "m", Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+	client.Generate(ctx, req)
+	client.Generate(context.Background(), req)
+	stream, _ := client.GenerateStream(ctx, req)
+	for range stream {
+	}
+
+	for _, labels := range [][]string{{"generate", "mock", "m", "acme"}, {"generate", "mock", "m", ""}, {"generate_stream", "mock", "m", "acme"}} {
+		if got := testutil.ToFloat64(c.requests.WithLabelValues(labels...)); got != 1 {
+			t.Errorf("requests %v = %v, want 1", labels, got)
+		}
+	}
+	if n := testutil.CollectAndCount(c.ttft); n != 1 {
+		t.Errorf("time to first token series = %d, want 1", n)
+	}
+}
+
+func TestClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&llmerrors.Error{Kind: llmerrors.ErrAuth}, "auth"},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), "timeout"},
+		{&llmerrors.Error{StatusCode: 502}, "server"},
+		{&llmerrors.Error{StatusCode: 422}, "client"},
+		{errors.New("eof"), "other"},
+	}
+	for _, tt := range tests {
+		if got := Class(tt.err); got != tt.want {
+			t.Errorf("Class(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCollector_StreamTiming(t *testing.T) {
+	m := mock.New().Enqueue(mock.Reply{
+		Chunks: []*generator.Response{
+			{Content: "a"},
+			{Content: "b", Usage: generator.TokenUsage{CompletionTokens: 2}},
+		},
+		ChunkDelay: 5 * time.Millisecond,
+	})
+	c := New()
+	client := gollm.NewClient(m, c.Option())
+	stream, err := client.GenerateStream(context.Background(), &generator.Request{Model: "m", Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	for range stream {
+	}
+
+	for name, h := range map[string]*prometheus.HistogramVec{"time to first token": c.ttft, "inter-chunk latency": c.gaps, "output tokens per second": c.speed} {
+		if n := testutil.CollectAndCount(h); n != 1 {
+			t.Errorf("%s series = %d, want 1", name, n)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package gollm
+
+import (
+	"context"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/reranker"
+)
+
+// GenerateFunc is the signature of Client.Generate, wrapped by middleware
+type GenerateFunc func(ctx context.Context, req *generator.Request) (*generator.Response, error)
+
+// GenerateStreamFunc is the signature of Client.GenerateStream, wrapped by
+// middleware
+type GenerateStreamFunc func(ctx context.Context, req *generator.Request) (<-chan *generator.Response, error)
+
+// EmbedFunc is the signature of Client.Embed, wrapped by middleware
+type EmbedFunc func(ctx context.Context, req *embedder.Request) (*embedder.Response, error)
+
+// RerankFunc is the signature of Client.Rerank, wrapped by middleware
+type RerankFunc func(ctx context.Context, req *reranker.Request) (*reranker.Response, error)
+
+// WithMiddleware wraps every Generate call with mw. Middleware runs in the
+// order given, the first outermost, around the whole call: guardrails,
+// moderation, retries and the provider request. It may change the request,
+// short-circuit with its own response or error, or inspect the result.
+func WithMiddleware(mw ...func(next GenerateFunc) GenerateFunc) Option {
+	return func(c *Client) {
+		c.generateMiddleware = append(c.generateMiddleware, mw...)
+	}
+}
+
+// WithStreamMiddleware wraps every GenerateStream call with mw, like
+// WithMiddleware. Chunks arrive after the middleware returns, so wrappers
+// that inspect them must forward the channel themselves.
+func WithStreamMiddleware(mw ...func(next GenerateStreamFunc) GenerateStreamFunc) Option {
+	return func(c *Client) {
+		c.streamMiddleware = append(c.streamMiddleware, mw...)
+	}
+}
+
+// WithEmbedMiddleware wraps every Embed call with mw, like WithMiddleware.
+// Middleware sees the whole request, before it is split into batches.
+func WithEmbedMiddleware(mw ...func(next EmbedFunc) EmbedFunc) Option {
+	return func(c *Client) {
+		c.embedMiddleware = append(c.embedMiddleware, mw...)
+	}
+}
+
+// WithRerankMiddleware wraps every Rerank call with mw, like WithMiddleware.
+// Middleware sees the whole request, before it is split into batches.
+func WithRerankMiddleware(mw ...func(next RerankFunc) RerankFunc) Option {
+	return func(c *Client) {
+		c.rerankMiddleware = append(c.rerankMiddleware, mw...)
+	}
+}
+
+// chain wraps f with mw, the first outermost
+func chain[F any](mw []func(F) F, f F) F {
+	for i := len(mw) - 1; i >= 0; i-- {
+		f = mw[i](f)
+	}
+	return f
+}
@@ -0,0 +1,68 @@
+package gollm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_WithMiddleware(t *testing.T) {
+	var order []string
+	tag := func(name string) func(GenerateFunc) GenerateFunc {
+		return func(next GenerateFunc) GenerateFunc {
+			return func(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+				order = append(order, name)
+				resp, err := next(ctx, req)
+				if err == nil {
+					resp.Content += " " + name
+				}
+				return resp, err
+			}
+		}
+	}
+	shortCircuit := func(next GenerateFunc) GenerateFunc {
+		return func(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+			if req.Messages[0].Content == "cached" {
+				return &generator.Response{Content: "from cache"}, nil
+			}
+			return next(ctx, req)
+		}
+	}
+	m := mock.New()
+	client := NewClient(m, WithMiddleware(tag("outer"), tag("inner")), WithMiddleware(shortCircuit))
+
+	resp, err := client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if resp.Content != "hi inner outer" || strings.Join(order, ",") != "outer,inner" {
+		t.Errorf("Generate() = %q, middleware order %v", resp.Content, order)
+	}
+
+	resp, _ = client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "cached"}}})
+	if resp.Content != "from cache inner outer" || len(m.Requests()) != 1 {
+		t.Errorf("Generate() = %q after %d provider requests, want the short-circuited response", resp.Content, len(m.Requests()))
+	}
+}
+
+func TestClient_WithEmbedMiddleware(t *testing.T) {
+	emb := &fakeEmbedder{limit: 2}
+	var seen int
+	count := func(next EmbedFunc) EmbedFunc {
+		return func(ctx context.Context, req *embedder.Request) (*embedder.Response, error) {
+			seen += len(req.Input)
+			return next(ctx, req)
+		}
+	}
+	client := NewClient(mock.New(), WithEmbedder(emb), WithEmbedMiddleware(count))
+	if _, err := client.Embed(context.Background(), &embedder.Request{Input: []string{"a", "b", "c"}}); err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if seen != 3 || len(emb.batches) != 2 {
+		t.Errorf("middleware saw %d inputs sent in batches %v, want the whole request once", seen, emb.batches)
+	}
+}
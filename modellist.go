@@ -0,0 +1,90 @@
+package gollm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+)
+
+// modelRefreshInterval is how often at most a model list is fetched again
+// for a model missing from it, so new models are picked up
+const modelRefreshInterval = time.Minute
+
+// modelCatalog caches the model lists of generators by name
+type modelCatalog struct {
+	mu    sync.Mutex
+	lists map[string]*modelList
+}
+
+type modelList struct {
+	mu      sync.Mutex
+	ids     map[string]bool
+	fetched time.Time
+}
+
+// WithModelValidation makes Generate and GenerateStream fail fast with
+// llmerrors.ErrModelNotFound when Request.Model is set but not offered by
+// the generator, for generators implementing generator.ModelLister. Lists
+// are fetched on first use and again, at most once a minute, for a model
+// missing from them. Requests are not blocked when listing fails.
+func WithModelValidation() Option {
+	return func(c *Client) {
+		c.models = &modelCatalog{lists: map[string]*modelList{}}
+	}
+}
+
+// ListModels returns the models offered by the generator the call in ctx is
+// sent to, the primary one unless CallWithProvider is given
+func (c *Client) ListModels(ctx context.Context, opts ...CallOption) ([]generator.ModelInfo, error) {
+	g, err := c.generatorFor(withCallOptions(ctx, opts))
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := g.(generator.ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("generator %s cannot list models", g.GetName())
+	}
+	return lister.ListModels(ctx)
+}
+
+// validateModel checks that g offers the model of request, when validation
+// is enabled
+func (c *Client) validateModel(ctx context.Context, g generator.Generator, request *generator.Request) error {
+	lister, ok := g.(generator.ModelLister)
+	if c.models == nil || !ok || request.Model == "" {
+		return nil
+	}
+	c.models.mu.Lock()
+	l, ok := c.models.lists[g.GetName()]
+	if !ok {
+		l = &modelList{}
+		c.models.lists[g.GetName()] = l
+	}
+	c.models.mu.Unlock()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ids[request.Model] {
+		return nil
+	}
+	if l.ids == nil || time.Since(l.fetched) >= modelRefreshInterval {
+		models, err := lister.ListModels(ctx)
+		if err != nil {
+			c.logger.WarnContext(ctx, "listing models", "provider", g.GetName(), "error", err)
+			return nil
+		}
+		l.ids = make(map[string]bool, len(models))
+		for _, m := range models {
+			l.ids[m.ID] = true
+		}
+		l.fetched = time.Now()
+	}
+	if !l.ids[request.Model] {
+		return fmt.Errorf("%w: %s does not offer %q", llmerrors.ErrModelNotFound, g.GetName(), request.Model)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+// listing is a generator listing models
+type listing struct {
+	*mock.Mock
+	models []string
+	err    error
+	lists  int
+}
+
+func (l *listing) ListModels(context.Context) ([]generator.ModelInfo, error) {
+	l.lists++
+	var models []generator.ModelInfo
+	for _, id := range l.models {
+		models = append(models, generator.ModelInfo{ID: id})
+	}
+	return models, l.err
+}
+
+func TestClient_WithModelValidation(t *testing.T) {
+	g := &listing{Mock: mock.New(), models: []string{"gpt-4o", "gpt-4o-mini"}}
+	client := NewClient(g, WithModelValidation())
+	ctx := context.Background()
+	msgs := []generator.Message{{Role: generator.USER, Content: "hi"}}
+
+	for _, model := range []string{"gpt-4o", "gpt-4o-mini", ""} {
+		if _, err := client.Generate(ctx, &generator.Request{Model: model, Messages: msgs}); err != nil {
+			t.Errorf("Generate(%q) error = %v", model, err)
+		}
+	}
+	_, err := client.Generate(ctx, &generator.Request{Model: "gpt-5", Messages: msgs})
+	if !errors.Is(err, llmerrors.ErrModelNotFound) {
+		t.Errorf("Generate(gpt-5) error = %v, want ErrModelNotFound", err)
+	}
+	if _, err := client.GenerateStream(ctx, &generator.Request{Model: "gpt-5", Messages: msgs}); !errors.Is(err, llmerrors.ErrModelNotFound) {
+		t.Errorf("GenerateStream(gpt-5) error = %v, want ErrModelNotFound", err)
+	}
+	if len(g.Requests()) != 3 {
+		t.Errorf("generator received %d requests, want the unknown models rejected before sending", len(g.Requests()))
+	}
+	if g.lists != 1 {
+		t.Errorf("ListModels() called %d times, want the list cached", g.lists)
+	}
+
+	// Listing failures do not block requests
+	g = &listing{Mock: mock.New(), err: errors.New("unavailable")}
+	client = NewClient(g, WithModelValidation())
+	if _, err := client.Generate(ctx, &generator.Request{Model: "gpt-5", Messages: msgs}); err != nil {
+		t.Errorf("Generate() error = %v with listing failing", err)
+	}
+}
+
+func TestClient_ListModels(t *testing.T) {
+	client := NewClient(&listing{Mock: mock.New(), models: []string{"m"}})
+	if models, err := client.ListModels(context.Background()); err != nil || len(models) != 1 || models[0].ID != "m" {
+		t.Errorf("ListModels() = %v, %v", models, err)
+	}
+	if _, err := NewClient(mock.New()).ListModels(context.Background()); err == nil {
+		t.Error("ListModels() error = nil for a generator without listing")
+	}
+}
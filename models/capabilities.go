@@ -0,0 +1,55 @@
+package models
+
+import "strings"
+
+// Capability represents a feature a model supports, as a bit set
+type Capability uint
+
+const (
+	// Tools is function calling
+	Tools Capability = 1 << iota
+	// Vision is image inputs
+	Vision
+	// JSON is a JSON output mode
+	JSON
+	// Reasoning is internal reasoning controlled by ReasoningEffort
+	Reasoning
+	// Embedding marks embedding models
+	Embedding
+)
+
+var capabilityNames = []string{"tools", "vision", "json", "reasoning", "embedding"}
+
+// Has reports whether c includes every capability of want
+func (c Capability) Has(want Capability) bool {
+	return c&want == want
+}
+
+func (c Capability) String() string {
+	var names []string
+	for i, name := range capabilityNames {
+		if c&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Supports reports whether a model, resolved like Lookup, has every
+// capability of want; unknown models support nothing
+func Supports(name string, want Capability) bool {
+	info, ok := Lookup(name)
+	return ok && info.Capabilities.Has(want)
+}
+
+// WithCapabilities returns the registered models having every capability of
+// want, sorted by name
+func WithCapabilities(want Capability) []Info {
+	var infos []Info
+	for _, info := range All() {
+		if info.Capabilities.Has(want) {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
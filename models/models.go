@@ -0,0 +1,110 @@
+// Package models provides a runtime registry of model metadata.
+package models
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Info represents metadata about a model
+type Info struct {
+	Name            string
+	ContextWindow   int // Maximum prompt + completion tokens
+	MaxOutputTokens int
+	Pricing         Pricing
+	Capabilities    Capability
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Info{}
+)
+
+func init() {
+	for _, info := range builtin {
+		registry[info.Name] = info
+	}
+}
+
+// multimodal is the capabilities of current multimodal chat models
+const multimodal = Tools | Vision | JSON
+
+var builtin = []Info{
+	{Name: "gpt-3.5-turbo", ContextWindow: 16385, MaxOutputTokens: 4096, Pricing: Pricing{Input: 0.5, Output: 1.5}, Capabilities: Tools | JSON},
+	{Name: "gpt-4", ContextWindow: 8192, MaxOutputTokens: 8192, Pricing: Pricing{Input: 30, Output: 60}, Capabilities: Tools},
+	{Name: "gpt-4-turbo", ContextWindow: 128000, MaxOutputTokens: 4096, Pricing: Pricing{Input: 10, Output: 30}, Capabilities: multimodal},
+	{Name: "gpt-4o", ContextWindow: 128000, MaxOutputTokens: 16384, Pricing: Pricing{Input: 2.5, CachedInput: 1.25, Output: 10}, Capabilities: multimodal},
+	{Name: "gpt-4o-mini", ContextWindow: 128000, MaxOutputTokens: 16384, Pricing: Pricing{Input: 0.15, CachedInput: 0.075, Output: 0.6}, Capabilities: multimodal},
+	{Name: "gpt-4.1", ContextWindow: 1047576, MaxOutputTokens: 32768, Pricing: Pricing{Input: 2, CachedInput: 0.5, Output: 8}, Capabilities: multimodal},
+	{Name: "gpt-4.1-mini", ContextWindow: 1047576, MaxOutputTokens: 32768, Pricing: Pricing{Input: 0.4, CachedInput: 0.1, Output: 1.6}, Capabilities: multimodal},
+	{Name: "o1", ContextWindow: 200000, MaxOutputTokens: 100000, Pricing: Pricing{Input: 15, CachedInput: 7.5, Output: 60}, Capabilities: multimodal | Reasoning},
+	{Name: "o3", ContextWindow: 200000, MaxOutputTokens: 100000, Pricing: Pricing{Input: 2, CachedInput: 0.5, Output: 8}, Capabilities: multimodal | Reasoning},
+	{Name: "o3-mini", ContextWindow: 200000, MaxOutputTokens: 100000, Pricing: Pricing{Input: 1.1, CachedInput: 0.55, Output: 4.4}, Capabilities: Tools | JSON | Reasoning},
+	{Name: "o4-mini", ContextWindow: 200000, MaxOutputTokens: 100000, Pricing: Pricing{Input: 1.1, CachedInput: 0.275, Output: 4.4}, Capabilities: multimodal | Reasoning},
+	{Name: "claude-3-5-haiku", ContextWindow: 200000, MaxOutputTokens: 8192, Pricing: Pricing{Input: 0.8, CachedInput: 0.08, Output: 4}, Capabilities: Tools | Vision},
+	{Name: "claude-3-5-sonnet", ContextWindow: 200000, MaxOutputTokens: 8192, Pricing: Pricing{Input: 3, CachedInput: 0.3, Output: 15}, Capabilities: Tools | Vision},
+	{Name: "claude-3-7-sonnet", ContextWindow: 200000, MaxOutputTokens: 64000, Pricing: Pricing{Input: 3, CachedInput: 0.3, Output: 15}, Capabilities: Tools | Vision | Reasoning},
+	{Name: "claude-sonnet-4", ContextWindow: 200000, MaxOutputTokens: 64000, Pricing: Pricing{Input: 3, CachedInput: 0.3, Output: 15}, Capabilities: Tools | Vision | Reasoning},
+	{Name: "claude-opus-4", ContextWindow: 200000, MaxOutputTokens: 32000, Pricing: Pricing{Input: 15, CachedInput: 1.5, Output: 75}, Capabilities: Tools | Vision | Reasoning},
+	{Name: "gemini-1.5-pro", ContextWindow: 2097152, MaxOutputTokens: 8192, Pricing: Pricing{Input: 1.25, CachedInput: 0.3125, Output: 5}, Capabilities: multimodal},
+	{Name: "gemini-2.0-flash", ContextWindow: 1048576, MaxOutputTokens: 8192, Pricing: Pricing{Input: 0.1, CachedInput: 0.025, Output: 0.4}, Capabilities: multimodal},
+	{Name: "deepseek-chat", ContextWindow: 64000, MaxOutputTokens: 8192, Pricing: Pricing{Input: 0.27, CachedInput: 0.07, Output: 1.1}, Capabilities: Tools | JSON},
+	{Name: "deepseek-reasoner", ContextWindow: 64000, MaxOutputTokens: 8192, Pricing: Pricing{Input: 0.55, CachedInput: 0.14, Output: 2.19}, Capabilities: Reasoning},
+	{Name: "text-embedding-3-small", ContextWindow: 8191, Pricing: Pricing{Input: 0.02}, Capabilities: Embedding},
+	{Name: "text-embedding-3-large", ContextWindow: 8191, Pricing: Pricing{Input: 0.13}, Capabilities: Embedding},
+	{Name: "text-embedding-ada-002", ContextWindow: 8191, Pricing: Pricing{Input: 0.1}, Capabilities: Embedding},
+}
+
+// Register adds or replaces the metadata of a model, e.g. of a fine-tune or
+// self-hosted model. Names are matched like Lookup, so registering "my-model"
+// covers "my-model-v2".
+func Register(info Info) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[info.Name] = info
+}
+
+// Lookup returns the metadata of a model. Dated or suffixed names such as
+// "gpt-4o-2024-08-06" resolve to the longest registered prefix.
+func Lookup(name string) (Info, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if info, ok := registry[name]; ok {
+		return info, true
+	}
+
+	var best Info
+	for key, info := range registry {
+		if strings.HasPrefix(name, key+"-") && len(key) > len(best.Name) {
+			best = info
+		}
+	}
+	return best, best.Name != ""
+}
+
+// ContextWindow returns the context window of a model, or 0 when unknown
+func ContextWindow(name string) int {
+	info, _ := Lookup(name)
+	return info.ContextWindow
+}
+
+// MaxOutputTokens returns the most tokens a model generates in a response,
+// or 0 when unknown
+func MaxOutputTokens(name string) int {
+	info, _ := Lookup(name)
+	return info.MaxOutputTokens
+}
+
+// All returns the metadata of every registered model, sorted by name
+func All() []Info {
+	mu.RLock()
+	defer mu.RUnlock()
+	infos := make([]Info, 0, len(registry))
+	for _, info := range registry {
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b Info) int { return strings.Compare(a.Name, b.Name) })
+	return infos
+}
@@ -0,0 +1,45 @@
+package models
+
+// Pricing represents the dollar prices of a model per million tokens. The
+// builtin prices are list prices at the time of writing; override them with
+// SetPricing for negotiated rates or price changes.
+type Pricing struct {
+	Input float64
+	// CachedInput applies to prompt tokens read from the provider's prompt
+	// cache; Input is used when zero
+	CachedInput float64
+	Output      float64
+}
+
+// Cost returns the dollar cost of input tokens, cachedInput of which were
+// cached, and output tokens
+func (p Pricing) Cost(input, cachedInput, output int) float64 {
+	cached := p.CachedInput
+	if cached == 0 {
+		cached = p.Input
+	}
+	return (float64(input-cachedInput)*p.Input + float64(cachedInput)*cached + float64(output)*p.Output) / 1e6
+}
+
+// SetPricing sets the pricing of a model, registering it when unknown
+func SetPricing(name string, p Pricing) {
+	mu.Lock()
+	defer mu.Unlock()
+	info := registry[name]
+	info.Name, info.Pricing = name, p
+	registry[name] = info
+}
+
+// PricingOf returns the pricing of a model, resolved like Lookup, and whether
+// it is known
+func PricingOf(name string) (Pricing, bool) {
+	info, _ := Lookup(name)
+	return info.Pricing, info.Pricing != Pricing{}
+}
+
+// Cost returns the dollar cost of a request to a model, or 0 when its
+// pricing is unknown
+func Cost(name string, input, cachedInput, output int) float64 {
+	p, _ := PricingOf(name)
+	return p.Cost(input, cachedInput, output)
+}
@@ -0,0 +1,97 @@
+package gollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/moderation"
+)
+
+// ModerationScope represents which content is moderated automatically
+type ModerationScope int
+
+const (
+	// ModerateInput rejects requests whose new user messages are flagged
+	ModerateInput ModerationScope = 1 << iota
+	// ModerateOutput rejects completions that are flagged. Streamed
+	// completions are not moderated.
+	ModerateOutput
+	// ModerateBoth moderates prompts and completions
+	ModerateBoth = ModerateInput | ModerateOutput
+)
+
+// WithModeration moderates prompts and/or completions of Generate and
+// GenerateStream with mod, rejecting flagged content with a
+// *moderation.FlaggedError. A nil mod uses the moderator of the LLM.
+func WithModeration(mod moderation.Moderator, scope ModerationScope) Option {
+	return func(c *Client) {
+		if mod != nil {
+			c.moderator = mod
+		}
+		c.moderationScope = scope
+	}
+}
+
+// WithModerator creates a new client with an additional moderator, without
+// moderating content automatically
+func WithModerator(mod moderation.Moderator) Option {
+	return func(c *Client) {
+		c.moderator = mod
+	}
+}
+
+// HasModerator returns true if the client has a moderator
+func (c *Client) HasModerator() bool {
+	return c.moderator != nil
+}
+
+// Moderate sends a moderation request to the LLM
+func (c *Client) Moderate(ctx context.Context, request *moderation.Request) (*moderation.Response, error) {
+	if c.moderator == nil {
+		return nil, fmt.Errorf("moderation capability not available")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return c.moderator.Moderate(ctx, request)
+}
+
+// moderateInput checks the user messages after the last assistant message,
+// earlier turns having been checked when they were sent
+func (c *Client) moderateInput(ctx context.Context, messages []generator.Message) error {
+	if c.moderationScope&ModerateInput == 0 {
+		return nil
+	}
+	var input []string
+	for i := len(messages) - 1; i >= 0 && messages[i].Role != generator.ASSISTANT; i-- {
+		if messages[i].Role == generator.USER && messages[i].Content != "" {
+			input = append(input, messages[i].Content)
+		}
+	}
+	return c.moderate(ctx, input, false)
+}
+
+func (c *Client) moderateOutput(ctx context.Context, resp *generator.Response) error {
+	if c.moderationScope&ModerateOutput == 0 || resp.Content == "" {
+		return nil
+	}
+	return c.moderate(ctx, []string{resp.Content}, true)
+}
+
+func (c *Client) moderate(ctx context.Context, input []string, output bool) error {
+	if len(input) == 0 {
+		return nil
+	}
+	resp, err := c.Moderate(ctx, &moderation.Request{Input: input})
+	if err != nil {
+		return fmt.Errorf("moderating content: %w", err)
+	}
+	for _, r := range resp.Results {
+		if r.Flagged {
+			return &moderation.FlaggedError{Output: output, Result: r}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/moderation"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+// keywordModerator flags inputs containing "bad" as violence and records
+// what it was sent
+type keywordModerator struct {
+	inputs []string
+}
+
+func (m *keywordModerator) Moderate(_ context.Context, req *moderation.Request) (*moderation.Response, error) {
+	m.inputs = append(m.inputs, req.Input...)
+	resp := &moderation.Response{}
+	for _, in := range req.Input {
+		bad := strings.Contains(in, "bad")
+		resp.Results = append(resp.Results, moderation.NewResult(nil, map[moderation.Category]bool{moderation.CategoryViolence: bad}))
+	}
+	return resp, nil
+}
+
+func (m *keywordModerator) GetModeratorName() string { return "keyword" }
+
+func TestClient_Moderation(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		return &generator.Response{Content: "echo " + req.Messages[len(req.Messages)-1].Content}, nil
+	}
+	mod := &keywordModerator{}
+	client := NewClient(m, WithModeration(mod, ModerateBoth))
+
+	messages := []generator.Message{
+		{Role: generator.USER, Content: "old bad turn"},
+		{Role: generator.ASSISTANT, Content: "ok"},
+		{Role: generator.USER, Content: "hello"},
+	}
+	if _, err := client.Generate(context.Background(), &generator.Request{Messages: messages}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(mod.inputs) != 2 || mod.inputs[0] != "hello" || mod.inputs[1] != "echo hello" {
+		t.Errorf("moderated %q, want the new turn and the completion", mod.inputs)
+	}
+
+	_, err := client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "bad"}}})
+	var flagged *moderation.FlaggedError
+	if !errors.Is(err, moderation.ErrFlagged) || !errors.As(err, &flagged) || flagged.Output {
+		t.Errorf("Generate() error = %v, want a flagged prompt", err)
+	}
+
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return &generator.Response{Content: "something bad"}, nil
+	}
+	_, err = client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+	if !errors.As(err, &flagged) || !flagged.Output || flagged.Result.Categories[0] != moderation.CategoryViolence {
+		t.Errorf("Generate() error = %v, want a flagged completion", err)
+	}
+}
@@ -0,0 +1,95 @@
+// Package moderation provides interfaces and types for content moderation.
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Category represents a normalized harm category. Providers map their own
+// taxonomies onto these.
+type Category string
+
+const (
+	CategoryHate       Category = "hate"
+	CategoryHarassment Category = "harassment"
+	CategorySelfHarm   Category = "self_harm"
+	CategorySexual     Category = "sexual"
+	CategoryViolence   Category = "violence"
+	CategoryIllicit    Category = "illicit"
+)
+
+// ErrFlagged is matched by errors rejecting flagged content
+var ErrFlagged = errors.New("content flagged by moderation")
+
+// Request represents a moderation request
+type Request struct {
+	Model          string
+	Input          []string
+	ProviderParams map[string]interface{}
+}
+
+// Result represents the moderation of one input
+type Result struct {
+	Flagged bool
+	// Categories are the flagged categories, sorted
+	Categories []Category
+	// Scores are the confidence or severity of each category, in [0, 1]
+	Scores map[Category]float64
+}
+
+// Response represents a moderation response, with one result per input
+type Response struct {
+	Model   string
+	Results []Result
+}
+
+// Moderator defines the interface for moderation
+type Moderator interface {
+	// Moderate sends a moderation request
+	Moderate(ctx context.Context, req *Request) (*Response, error)
+
+	// GetModeratorName returns the name of the implementation
+	GetModeratorName() string
+}
+
+// FlaggedError is returned when content is rejected by moderation. It
+// matches ErrFlagged.
+type FlaggedError struct {
+	// Output is true for a flagged completion, false for a flagged prompt
+	Output bool
+	Result Result
+}
+
+func (e *FlaggedError) Error() string {
+	direction := "prompt"
+	if e.Output {
+		direction = "completion"
+	}
+	categories := make([]string, len(e.Result.Categories))
+	for i, c := range e.Result.Categories {
+		categories[i] = string(c)
+	}
+	return fmt.Sprintf("%s flagged by moderation: %s", direction, strings.Join(categories, ", "))
+}
+
+func (e *FlaggedError) Is(target error) bool {
+	return target == ErrFlagged
+}
+
+// NewResult builds a result from category scores and flags, sorting the
+// flagged categories
+func NewResult(scores map[Category]float64, flagged map[Category]bool) Result {
+	r := Result{Scores: scores}
+	for c, f := range flagged {
+		if f {
+			r.Categories = append(r.Categories, c)
+		}
+	}
+	sort.Slice(r.Categories, func(i, j int) bool { return r.Categories[i] < r.Categories[j] })
+	r.Flagged = len(r.Categories) > 0
+	return r
+}
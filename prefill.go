@@ -0,0 +1,70 @@
+package gollm
+
+import (
+	"context"
+	"slices"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// continuePrompt asks generators without prefill support to continue the
+// trailing assistant message of a prefilled request
+const continuePrompt = "Continue your last message exactly where it stops, without repeating any of it."
+
+// supportsPrefill reports whether g continues a trailing assistant message
+func supportsPrefill(g generator.Generator) bool {
+	p, ok := g.(generator.PrefillSupporter)
+	return ok && p.SupportsPrefill()
+}
+
+// prefilled returns request as sent to g, Request.Prefill moved to a
+// trailing assistant message. Generators without prefill support are then
+// asked to continue it, the usual pattern of OpenAI style APIs.
+func prefilled(g generator.Generator, request *generator.Request) *generator.Request {
+	if request.Prefill == "" {
+		return request
+	}
+	req := *request
+	req.Prefill = ""
+	req.Messages = append(slices.Clip(request.Messages), generator.Message{Role: generator.ASSISTANT, Content: request.Prefill})
+	if !supportsPrefill(g) {
+		req.Messages = append(req.Messages, generator.Message{Role: generator.USER, Content: continuePrompt})
+	}
+	return &req
+}
+
+// prefillResponse returns resp with the prefill of request leading its
+// content
+func prefillResponse(request *generator.Request, resp *generator.Response) *generator.Response {
+	if request.Prefill == "" {
+		return resp
+	}
+	r := *resp
+	r.Content = request.Prefill + r.Content
+	return &r
+}
+
+// prefillStream forwards stream after a first chunk of the prefill of
+// request
+func prefillStream(ctx context.Context, request *generator.Request, stream <-chan *generator.Response) <-chan *generator.Response {
+	if request.Prefill == "" {
+		return stream
+	}
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+		select {
+		case out <- &generator.Response{Object: "chat.completion.chunk", Model: request.Model, Content: request.Prefill}:
+		case <-ctx.Done():
+			return
+		}
+		for chunk := range stream {
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
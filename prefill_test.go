@@ -0,0 +1,72 @@
+package gollm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+// prefillMock is a mock continuing trailing assistant messages
+type prefillMock struct {
+	*mock.Mock
+}
+
+func (prefillMock) SupportsPrefill() bool { return true }
+
+func TestClient_Prefill(t *testing.T) {
+	req := &generator.Request{Prefill: "{", Messages: []generator.Message{{Role: generator.USER, Content: "JSON please"}}}
+
+	tests := []struct {
+		name    string
+		prefill bool
+		want    []generator.Message
+	}{
+		{"supported", true, []generator.Message{
+			{Role: generator.USER, Content: "JSON please"},
+			{Role: generator.ASSISTANT, Content: "{"},
+		}},
+		{"unsupported", false, []generator.Message{
+			{Role: generator.USER, Content: "JSON please"},
+			{Role: generator.ASSISTANT, Content: "{"},
+			{Role: generator.USER, Content: continuePrompt},
+		}},
+	}
+	for _, tt := range tests {
+		m := mock.New().Enqueue(mock.Text(`"a":1}`), mock.Stream(`"a"`, `:1}`))
+		var g generator.Generator = m
+		if tt.prefill {
+			g = prefillMock{m}
+		}
+		client := NewClient(g)
+
+		resp, err := client.Generate(context.Background(), req)
+		if err != nil || resp.Content != `{"a":1}` {
+			t.Errorf("%s: Generate() = %v, %v, want content {\"a\":1}", tt.name, resp, err)
+		}
+		sent := m.LastRequest()
+		if sent.Prefill != "" || len(sent.Messages) != len(tt.want) {
+			t.Fatalf("%s: sent %+v, want messages %+v", tt.name, sent, tt.want)
+		}
+		for i, msg := range tt.want {
+			if sent.Messages[i].Role != msg.Role || sent.Messages[i].Content != msg.Content {
+				t.Errorf("%s: sent message %d = %+v, want %+v", tt.name, i, sent.Messages[i], msg)
+			}
+		}
+
+		stream, err := client.GenerateStream(context.Background(), req)
+		if err != nil {
+			t.Fatalf("%s: GenerateStream() error = %v", tt.name, err)
+		}
+		if got, err := collect(t, stream); got != `{"a":1}` || err != nil {
+			t.Errorf("%s: stream = %q, %v, want {\"a\":1}", tt.name, got, err)
+		}
+		if n := len(m.LastRequest().Messages); n != len(tt.want) {
+			t.Errorf("%s: stream sent %d messages, want %d", tt.name, n, len(tt.want))
+		}
+	}
+	if len(req.Messages) != 1 {
+		t.Errorf("Generate() modified the request messages: %+v", req.Messages)
+	}
+}
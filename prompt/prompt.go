@@ -0,0 +1,276 @@
+// Package prompt provides templates that render to conversation messages.
+package prompt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// Metadata keys set by Template.Tag
+const (
+	MetadataName    = "prompt"
+	MetadataVersion = "prompt_version"
+)
+
+// Example represents a few-shot example rendered as a user/assistant exchange
+type Example struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+// part is either a message template or a block of examples
+type part struct {
+	role       generator.Role
+	text       string
+	isExamples bool
+	examples   []Example
+}
+
+// Template renders text/template message templates to messages. Variables are
+// referenced as {{.name}}; partials are included with {{template "name" .}}.
+type Template struct {
+	name     string
+	version  string
+	parts    []part
+	partials map[string]string
+	funcs    template.FuncMap
+	tmpls    []*template.Template
+}
+
+// Option is a function that configures a Template
+type Option func(*Template)
+
+// Message appends a message template with the given role
+func Message(role generator.Role, text string) Option {
+	return func(t *Template) {
+		t.parts = append(t.parts, part{role: role, text: text})
+	}
+}
+
+// System appends a system message template
+func System(text string) Option {
+	return Message(generator.SYSTEM, text)
+}
+
+// User appends a user message template
+func User(text string) Option {
+	return Message(generator.USER, text)
+}
+
+// Assistant appends an assistant message template
+func Assistant(text string) Option {
+	return Message(generator.ASSISTANT, text)
+}
+
+// Examples appends few-shot examples at this position of the conversation
+func Examples(examples ...Example) Option {
+	return func(t *Template) {
+		t.parts = append(t.parts, part{isExamples: true, examples: examples})
+	}
+}
+
+// Partial defines a named template usable from every message
+func Partial(name, text string) Option {
+	return func(t *Template) {
+		t.partials[name] = text
+	}
+}
+
+// Version sets the version the template is published under
+func Version(version string) Option {
+	return func(t *Template) {
+		t.version = version
+	}
+}
+
+// Funcs adds functions usable from every message
+func Funcs(funcs template.FuncMap) Option {
+	return func(t *Template) {
+		for name, fn := range funcs {
+			t.funcs[name] = fn
+		}
+	}
+}
+
+// New parses a template from its options
+func New(name string, opts ...Option) (*Template, error) {
+	t := &Template{
+		name:     name,
+		partials: make(map[string]string),
+		funcs:    make(template.FuncMap),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	base := template.New(name).Funcs(t.funcs).Option("missingkey=error")
+	for partial, text := range t.partials {
+		if _, err := base.New(partial).Parse(text); err != nil {
+			return nil, fmt.Errorf("prompt %s: parsing partial %s: %w", name, partial, err)
+		}
+	}
+
+	for i, p := range t.parts {
+		if p.isExamples {
+			t.tmpls = append(t.tmpls, nil)
+			continue
+		}
+		clone, err := base.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("prompt %s: %w", name, err)
+		}
+		tmpl, err := clone.New(fmt.Sprintf("%s#%d", name, i)).Parse(p.text)
+		if err != nil {
+			return nil, fmt.Errorf("prompt %s: parsing message %d: %w", name, i, err)
+		}
+		t.tmpls = append(t.tmpls, tmpl)
+	}
+	return t, nil
+}
+
+// Must is a helper that wraps a call to New and panics if the error is non-nil
+func Must(t *Template, err error) *Template {
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Name returns the name of the template
+func (t *Template) Name() string {
+	return t.name
+}
+
+// Version returns the version of the template, empty when unversioned
+func (t *Template) Version() string {
+	return t.version
+}
+
+// Tag records the template name and version in the request metadata
+func (t *Template) Tag(req *generator.Request) {
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]string)
+	}
+	req.Metadata[MetadataName] = t.name
+	if t.version != "" {
+		req.Metadata[MetadataVersion] = t.version
+	}
+}
+
+// Variables returns the sorted names of the top-level variables the template
+// references
+func (t *Template) Variables() []string {
+	seen := make(map[string]bool)
+	for _, tmpl := range t.tmpls {
+		if tmpl != nil {
+			collect(tmpl, tmpl.Tree.Root, seen, 0)
+		}
+	}
+
+	vars := make([]string, 0, len(seen))
+	for v := range seen {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// Render validates that every variable is supplied and renders the messages
+func (t *Template) Render(vars map[string]any) ([]generator.Message, error) {
+	var missing []string
+	for _, v := range t.Variables() {
+		if _, ok := vars[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("prompt %s: missing variables: %s", t.name, strings.Join(missing, ", "))
+	}
+
+	var messages []generator.Message
+	for i, p := range t.parts {
+		if p.isExamples {
+			for _, ex := range p.examples {
+				messages = append(messages,
+					generator.Message{Role: generator.USER, Content: ex.Input},
+					generator.Message{Role: generator.ASSISTANT, Content: ex.Output},
+				)
+			}
+			continue
+		}
+
+		var b strings.Builder
+		if err := t.tmpls[i].Execute(&b, vars); err != nil {
+			return nil, fmt.Errorf("prompt %s: rendering message %d: %w", t.name, i, err)
+		}
+		messages = append(messages, generator.Message{Role: p.role, Content: b.String()})
+	}
+	return messages, nil
+}
+
+// maxPartialDepth guards against recursive partials
+const maxPartialDepth = 16
+
+// collect records top-level field references in node. Bodies of range and
+// with rebind the dot, so only their pipelines are inspected.
+func collect(tmpl *template.Template, node parse.Node, seen map[string]bool, depth int) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collect(tmpl, child, seen, depth)
+		}
+	case *parse.ActionNode:
+		collect(tmpl, n.Pipe, seen, depth)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collect(tmpl, cmd, seen, depth)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collect(tmpl, arg, seen, depth)
+		}
+	case *parse.FieldNode:
+		seen[n.Ident[0]] = true
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			seen[n.Ident[1]] = true
+		}
+	case *parse.ChainNode:
+		collect(tmpl, n.Node, seen, depth)
+	case *parse.IfNode:
+		collect(tmpl, n.Pipe, seen, depth)
+		collect(tmpl, n.List, seen, depth)
+		collect(tmpl, n.ElseList, seen, depth)
+	case *parse.RangeNode:
+		collect(tmpl, n.Pipe, seen, depth)
+		collect(tmpl, n.ElseList, seen, depth)
+	case *parse.WithNode:
+		collect(tmpl, n.Pipe, seen, depth)
+		collect(tmpl, n.ElseList, seen, depth)
+	case *parse.TemplateNode:
+		collect(tmpl, n.Pipe, seen, depth)
+		if partial := tmpl.Lookup(n.Name); partial != nil && partial.Tree != nil && passesDot(n.Pipe) && depth < maxPartialDepth {
+			collect(tmpl, partial.Tree.Root, seen, depth+1)
+		}
+	}
+}
+
+// passesDot reports whether a template invocation receives the current dot
+func passesDot(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	_, ok := pipe.Cmds[0].Args[0].(*parse.DotNode)
+	return ok
+}
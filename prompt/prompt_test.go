@@ -0,0 +1,57 @@
+package prompt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+func TestTemplate_Render(t *testing.T) {
+	tmpl := Must(New("support",
+		System(`You support {{.product}}. {{template "tone" .}}`),
+		Partial("tone", "Answer in {{.language}}."),
+		Examples(Example{Input: "2+2?", Output: "4"}),
+		User("{{.question}}"),
+	))
+
+	if got, want := tmpl.Variables(), []string{"language", "product", "question"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Variables() = %v, want %v", got, want)
+	}
+
+	messages, err := tmpl.Render(map[string]any{"product": "gollm", "language": "French", "question": "Hi?"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := []generator.Message{
+		{Role: generator.SYSTEM, Content: "You support gollm. Answer in French."},
+		{Role: generator.USER, Content: "2+2?"},
+		{Role: generator.ASSISTANT, Content: "4"},
+		{Role: generator.USER, Content: "Hi?"},
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("Render() = %+v, want %+v", messages, want)
+	}
+}
+
+func TestTemplate_RenderNoExamples(t *testing.T) {
+	var examples []Example
+	tmpl := Must(New("ask", Examples(examples...), User("Hi?")))
+	messages, err := tmpl.Render(nil)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if want := []generator.Message{{Role: generator.USER, Content: "Hi?"}}; !reflect.DeepEqual(messages, want) {
+		t.Errorf("Render() = %+v, want %+v", messages, want)
+	}
+}
+
+func TestTemplate_RenderMissingVariables(t *testing.T) {
+	tmpl := Must(New("greet", User("Hello {{.name}} from {{.city}}, {{range .items}}{{.title}}{{end}}")))
+
+	_, err := tmpl.Render(map[string]any{"name": "Ada"})
+	if err == nil || !strings.Contains(err.Error(), "city, items") {
+		t.Errorf("Render() error = %v, want missing city, items", err)
+	}
+}
@@ -0,0 +1,337 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// ErrNotFound is returned when a prompt or prompt version does not exist
+var ErrNotFound = errors.New("prompt not found")
+
+// Definition represents the serializable form of a versioned template
+type Definition struct {
+	Name     string              `json:"name"`
+	Version  string              `json:"version"`
+	Messages []MessageDefinition `json:"messages"`
+	Partials map[string]string   `json:"partials,omitempty"`
+}
+
+// MessageDefinition represents either a message template or an examples block
+type MessageDefinition struct {
+	Role     generator.Role `json:"role,omitempty"`
+	Text     string         `json:"text,omitempty"`
+	Examples []Example      `json:"examples,omitempty"`
+}
+
+// Template parses the definition
+func (d Definition) Template(opts ...Option) (*Template, error) {
+	all := []Option{Version(d.Version)}
+	for name, text := range d.Partials {
+		all = append(all, Partial(name, text))
+	}
+	for _, m := range d.Messages {
+		if m.Examples != nil {
+			all = append(all, Examples(m.Examples...))
+		} else {
+			all = append(all, Message(m.Role, m.Text))
+		}
+	}
+	return New(d.Name, append(all, opts...)...)
+}
+
+// Store persists prompt definitions by name and version
+type Store interface {
+	// Save stores a definition, replacing the same name and version
+	Save(ctx context.Context, def Definition) error
+
+	// Load returns a definition, or ErrNotFound
+	Load(ctx context.Context, name, version string) (Definition, error)
+
+	// Versions returns the stored versions of a prompt
+	Versions(ctx context.Context, name string) ([]string, error)
+}
+
+// MemoryStore is a Store backed by a map
+type MemoryStore struct {
+	mu   sync.RWMutex
+	defs map[string]map[string]Definition
+}
+
+// NewMemoryStore creates a new empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{defs: make(map[string]map[string]Definition)}
+}
+
+func (s *MemoryStore) Save(_ context.Context, def Definition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.defs[def.Name] == nil {
+		s.defs[def.Name] = make(map[string]Definition)
+	}
+	s.defs[def.Name][def.Version] = def
+	return nil
+}
+
+func (s *MemoryStore) Load(_ context.Context, name, version string) (Definition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	def, ok := s.defs[name][version]
+	if !ok {
+		return Definition{}, fmt.Errorf("%w: %s@%s", ErrNotFound, name, version)
+	}
+	return def, nil
+}
+
+func (s *MemoryStore) Versions(_ context.Context, name string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := make([]string, 0, len(s.defs[name]))
+	for v := range s.defs[name] {
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// FileStore is a Store keeping each definition in <dir>/<name>/<version>.json,
+// so prompts can be edited and deployed independently of the binary
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store rooted at dir
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+func (s *FileStore) Save(_ context.Context, def Definition) error {
+	path, err := s.path(def.Name, def.Version)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(def, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding prompt %s@%s: %w", def.Name, def.Version, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+func (s *FileStore) Load(_ context.Context, name, version string) (Definition, error) {
+	path, err := s.path(name, version)
+	if err != nil {
+		return Definition{}, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Definition{}, fmt.Errorf("%w: %s@%s", ErrNotFound, name, version)
+	}
+	if err != nil {
+		return Definition{}, err
+	}
+
+	var def Definition
+	if err := json.Unmarshal(b, &def); err != nil {
+		return Definition{}, fmt.Errorf("decoding prompt %s@%s: %w", name, version, err)
+	}
+	def.Name, def.Version = name, version
+	return def, nil
+}
+
+func (s *FileStore) Versions(_ context.Context, name string) ([]string, error) {
+	dir, err := s.path(name, "")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Dir(dir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, e := range entries {
+		if v, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+func (s *FileStore) path(name, version string) (string, error) {
+	for _, part := range []string{name, version} {
+		if strings.ContainsAny(part, `/\`) || part == ".." {
+			return "", fmt.Errorf("invalid prompt name or version: %q", part)
+		}
+	}
+	return filepath.Join(s.dir, name, version+".json"), nil
+}
+
+// Variant represents a version served to a share of traffic
+type Variant struct {
+	Version string
+	Weight  int
+}
+
+// Registry resolves prompts by name from a Store. The served version of a
+// prompt is, in order of precedence: an A/B split, a pinned version, or the
+// latest stored version. Registry is safe for concurrent use.
+type Registry struct {
+	store Store
+
+	mu          sync.RWMutex
+	pins        map[string]string
+	experiments map[string][]Variant
+}
+
+// NewRegistry creates a registry backed by store
+func NewRegistry(store Store) *Registry {
+	return &Registry{
+		store:       store,
+		pins:        make(map[string]string),
+		experiments: make(map[string][]Variant),
+	}
+}
+
+// Publish stores a new prompt version after checking that it parses
+func (r *Registry) Publish(ctx context.Context, def Definition) error {
+	if def.Name == "" || def.Version == "" {
+		return errors.New("prompt name and version are required")
+	}
+	if _, err := def.Template(); err != nil {
+		return err
+	}
+	return r.store.Save(ctx, def)
+}
+
+// Pin makes name resolve to version until unpinned; pinning an older version
+// rolls the prompt back
+func (r *Registry) Pin(name, version string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pins[name] = version
+}
+
+// Unpin makes name resolve to its latest version again
+func (r *Registry) Unpin(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pins, name)
+}
+
+// Split serves the variants of name proportionally to their weights. Calling
+// Split without variants ends the experiment.
+func (r *Registry) Split(name string, variants ...Variant) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(variants) == 0 {
+		delete(r.experiments, name)
+		return
+	}
+	r.experiments[name] = variants
+}
+
+// Get returns a specific version of a prompt, or the served version when
+// version is empty
+func (r *Registry) Get(ctx context.Context, name, version string) (*Template, error) {
+	if version == "" {
+		return r.Select(ctx, name, "")
+	}
+	def, err := r.store.Load(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	return def.Template()
+}
+
+// Select returns the served version of a prompt. key (e.g. a user ID) makes
+// A/B assignment sticky; an empty key always gets the first variant with a
+// positive weight.
+func (r *Registry) Select(ctx context.Context, name, key string) (*Template, error) {
+	r.mu.RLock()
+	variants := r.experiments[name]
+	version := r.pins[name]
+	r.mu.RUnlock()
+
+	if len(variants) > 0 {
+		version = pick(variants, key)
+	}
+	if version == "" {
+		versions, err := r.store.Versions(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		version = latest(versions)
+	}
+	return r.Get(ctx, name, version)
+}
+
+func pick(variants []Variant, key string) string {
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return variants[0].Version
+	}
+	if key == "" {
+		for _, v := range variants {
+			if v.Weight > 0 {
+				return v.Version
+			}
+		}
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	n := int(h.Sum32() % uint32(total))
+	for _, v := range variants {
+		if n < v.Weight {
+			return v.Version
+		}
+		n -= v.Weight
+	}
+	return variants[len(variants)-1].Version
+}
+
+// latest returns the highest version, comparing dot-separated numeric segments
+// numerically ("v1.10" > "v1.9") and anything else lexically
+func latest(versions []string) string {
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) < 0
+	})
+	return versions[len(versions)-1]
+}
+
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		ai, aerr := strconv.Atoi(as[i])
+		bi, berr := strconv.Atoi(bs[i])
+		switch {
+		case aerr == nil && berr == nil && ai != bi:
+			return ai - bi
+		case (aerr != nil || berr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return len(as) - len(bs)
+}
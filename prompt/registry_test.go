@@ -0,0 +1,57 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+func definition(version, text string) Definition {
+	return Definition{
+		Name:     "greet",
+		Version:  version,
+		Messages: []MessageDefinition{{Role: generator.USER, Text: text}},
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	for _, store := range []Store{NewMemoryStore(), NewFileStore(t.TempDir())} {
+		r := NewRegistry(store)
+		for _, def := range []Definition{definition("1.9", "v1.9"), definition("1.10", "v1.10")} {
+			if err := r.Publish(ctx, def); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+		}
+
+		tmpl, err := r.Get(ctx, "greet", "")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if tmpl.Version() != "1.10" {
+			t.Errorf("latest version = %q, want 1.10", tmpl.Version())
+		}
+
+		r.Pin("greet", "1.9")
+		tmpl, _ = r.Get(ctx, "greet", "")
+		req := &generator.Request{}
+		tmpl.Tag(req)
+		if req.Metadata[MetadataVersion] != "1.9" {
+			t.Errorf("tagged version = %q, want 1.9", req.Metadata[MetadataVersion])
+		}
+
+		r.Split("greet", Variant{Version: "1.9", Weight: 0}, Variant{Version: "1.10", Weight: 1})
+		if tmpl, _ = r.Select(ctx, "greet", "user-1"); tmpl.Version() != "1.10" {
+			t.Errorf("variant version = %q, want 1.10", tmpl.Version())
+		}
+		if tmpl, _ = r.Select(ctx, "greet", ""); tmpl.Version() != "1.10" {
+			t.Errorf("variant version without a key = %q, want 1.10", tmpl.Version())
+		}
+
+		if _, err := r.Get(ctx, "missing", ""); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get() error = %v, want ErrNotFound", err)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package gollm
+
+import "github.com/parikxxit/go-llm/generator"
+
+// NewProvider creates the generator registered as name, e.g. "openai", with
+// cfg. Providers register when their package is imported, for instance
+//
+//	import _ "github.com/parikxxit/go-llm/providers/openai"
+func NewProvider(name string, cfg generator.Config) (generator.Generator, error) {
+	return generator.New(name, cfg)
+}
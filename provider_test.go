@@ -0,0 +1,35 @@
+package gollm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestNewProvider(t *testing.T) {
+	var got generator.Config
+	generator.Register("test-provider", func(cfg generator.Config) (generator.Generator, error) {
+		got = cfg
+		return mock.New(), nil
+	})
+
+	g, err := NewProvider("test-provider", generator.Config{ApiKey: "key", Model: "m"})
+	if err != nil || g == nil || got.ApiKey != "key" || got.Model != "m" {
+		t.Errorf("NewProvider() = %v, %v with config %+v", g, err, got)
+	}
+	if g, err := NewProvider("mock", generator.Config{}); err != nil || g.GetName() != "mock" {
+		t.Errorf("NewProvider(mock) = %v, %v", g, err)
+	}
+	if _, err := NewProvider("nope", generator.Config{}); err == nil || !strings.Contains(err.Error(), "mock, test-provider") {
+		t.Errorf("NewProvider(nope) error = %v, want the registered providers", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() of a taken name did not panic")
+		}
+	}()
+	generator.Register("mock", func(generator.Config) (generator.Generator, error) { return nil, nil })
+}
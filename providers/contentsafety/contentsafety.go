@@ -0,0 +1,139 @@
+// Package contentsafety provides a moderation.Moderator backed by Azure AI
+// Content Safety text analysis.
+package contentsafety
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/moderation"
+	"github.com/parikxxit/go-llm/transport"
+)
+
+const (
+	apiVersion       = "2024-09-01"
+	maxSeverity      = 6 // Of the FourSeverityLevels output: 0, 2, 4 or 6
+	defaultThreshold = 4
+)
+
+// categories maps Content Safety categories to normalized ones
+var categories = map[string]moderation.Category{
+	"Hate":     moderation.CategoryHate,
+	"SelfHarm": moderation.CategorySelfHarm,
+	"Sexual":   moderation.CategorySexual,
+	"Violence": moderation.CategoryViolence,
+}
+
+// ContentSafety moderates text with an Azure AI Content Safety resource.
+// Severities are reported as scores of severity/6.
+type ContentSafety struct {
+	endpoint   string
+	apiKey     string
+	threshold  int
+	httpClient *http.Client
+}
+
+// Option is a function that configures a ContentSafety client
+type Option func(*ContentSafety)
+
+// WithThreshold sets the severity, from 0 to 6, at which a category is
+// flagged, 4 (medium) by default
+func WithThreshold(severity int) Option {
+	return func(c *ContentSafety) {
+		c.threshold = severity
+	}
+}
+
+// WithHTTPClient sets the HTTP client, transport.DefaultClient by default
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *ContentSafety) {
+		c.httpClient = hc
+	}
+}
+
+// New creates a new client for the resource at endpoint, e.g.
+// https://<resource>.cognitiveservices.azure.com
+func New(endpoint, apiKey string, opts ...Option) *ContentSafety {
+	c := &ContentSafety{
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		apiKey:     apiKey,
+		threshold:  defaultThreshold,
+		httpClient: transport.DefaultClient(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Moderate analyzes each input with one request
+func (c *ContentSafety) Moderate(ctx context.Context, req *moderation.Request) (*moderation.Response, error) {
+	resp := &moderation.Response{Model: "content-safety-" + apiVersion, Results: make([]moderation.Result, len(req.Input))}
+	for i, text := range req.Input {
+		r, err := c.analyze(ctx, text, req.ProviderParams)
+		if err != nil {
+			return nil, err
+		}
+		resp.Results[i] = r
+	}
+	return resp, nil
+}
+
+func (c *ContentSafety) analyze(ctx context.Context, text string, params map[string]interface{}) (moderation.Result, error) {
+	body := map[string]any{"text": text, "outputType": "FourSeverityLevels"}
+	for k, v := range params {
+		body[k] = v
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return moderation.Result{}, err
+	}
+
+	url := c.endpoint + "/contentsafety/text:analyze?api-version=" + apiVersion
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return moderation.Result{}, err
+	}
+	httpReq.Header.Set("Ocp-Apim-Subscription-Key", c.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return moderation.Result{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return moderation.Result{}, llmerrors.FromResponse("contentsafety", res)
+	}
+
+	var out struct {
+		CategoriesAnalysis []struct {
+			Category string `json:"category"`
+			Severity int    `json:"severity"`
+		} `json:"categoriesAnalysis"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return moderation.Result{}, fmt.Errorf("contentsafety: decoding response: %w", err)
+	}
+
+	scores := make(map[moderation.Category]float64)
+	flagged := make(map[moderation.Category]bool)
+	for _, a := range out.CategoriesAnalysis {
+		category, ok := categories[a.Category]
+		if !ok {
+			continue
+		}
+		scores[category] = float64(a.Severity) / maxSeverity
+		flagged[category] = a.Severity >= c.threshold
+	}
+	return moderation.NewResult(scores, flagged), nil
+}
+
+func (c *ContentSafety) GetModeratorName() string {
+	return "contentsafety"
+}
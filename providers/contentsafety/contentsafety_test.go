@@ -0,0 +1,34 @@
+package contentsafety
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/parikxxit/go-llm/moderation"
+)
+
+func TestContentSafety_Moderate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/contentsafety/text:analyze" || r.Header.Get("Ocp-Apim-Subscription-Key") != "key" {
+			t.Errorf("request = %s, headers = %v", r.URL, r.Header)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"categoriesAnalysis":[{"category":"Hate","severity":0},{"category":"Violence","severity":4},{"category":"Sexual","severity":2}]}`)
+	}))
+	defer srv.Close()
+
+	resp, err := New(srv.URL, "key").Moderate(context.Background(), &moderation.Request{Input: []string{"text"}})
+	if err != nil {
+		t.Fatalf("Moderate() error = %v", err)
+	}
+	r := resp.Results[0]
+	if !r.Flagged || len(r.Categories) != 1 || r.Categories[0] != moderation.CategoryViolence {
+		t.Errorf("Moderate() = %+v, want violence flagged", r)
+	}
+	if r.Scores[moderation.CategorySexual] != 2.0/6 {
+		t.Errorf("Scores = %v", r.Scores)
+	}
+}
@@ -0,0 +1,159 @@
+// Package deepgram provides an stt.Transcriber backed by the Deepgram
+// pre-recorded audio API.
+package deepgram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/stt"
+	"github.com/parikxxit/go-llm/transport"
+)
+
+const (
+	defaultBaseURL = "https://api.deepgram.com"
+	defaultModel   = "nova-3"
+)
+
+// Deepgram transcribes audio with Deepgram models
+type Deepgram struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Option is a function that configures a Deepgram client
+type Option func(*Deepgram)
+
+// WithBaseURL sets the API URL, https://api.deepgram.com by default
+func WithBaseURL(url string) Option {
+	return func(d *Deepgram) {
+		d.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client, transport.DefaultClient by default
+func WithHTTPClient(c *http.Client) Option {
+	return func(d *Deepgram) {
+		d.httpClient = c
+	}
+}
+
+// New creates a new Deepgram client
+func New(apiKey string, opts ...Option) *Deepgram {
+	d := &Deepgram{apiKey: apiKey, baseURL: defaultBaseURL, httpClient: transport.DefaultClient()}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+type response struct {
+	Metadata struct {
+		Duration float64 `json:"duration"`
+	} `json:"metadata"`
+	Results struct {
+		Channels []struct {
+			DetectedLanguage string `json:"detected_language"`
+			Alternatives     []struct {
+				Transcript string `json:"transcript"`
+				Words      []struct {
+					Word           string  `json:"word"`
+					PunctuatedWord string  `json:"punctuated_word"`
+					Start          float64 `json:"start"`
+					End            float64 `json:"end"`
+					Confidence     float64 `json:"confidence"`
+				} `json:"words"`
+			} `json:"alternatives"`
+		} `json:"channels"`
+		Utterances []struct {
+			Start      float64 `json:"start"`
+			End        float64 `json:"end"`
+			Transcript string  `json:"transcript"`
+		} `json:"utterances"`
+	} `json:"results"`
+}
+
+// Transcribe streams the request audio to Deepgram. Utterances are returned
+// as segments; the language is detected unless given.
+func (d *Deepgram) Transcribe(ctx context.Context, req *stt.Request) (*stt.Response, error) {
+	model := req.Model
+	if model == "" {
+		model = defaultModel
+	}
+	query := url.Values{"model": {model}, "smart_format": {"true"}}
+	if req.Language != "" {
+		query.Set("language", req.Language)
+	} else {
+		query.Set("detect_language", "true")
+	}
+	if req.Wants(stt.GranularitySegment) {
+		query.Set("utterances", "true")
+	}
+	if req.Prompt != "" {
+		// Deepgram takes vocabulary hints rather than a free-form prompt
+		query.Set("keyterm", req.Prompt)
+	}
+	for k, v := range req.ProviderParams {
+		query.Set(k, fmt.Sprint(v))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, d.baseURL+"/v1/listen?"+query.Encode(), req.Audio)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Token "+d.apiKey)
+	mediaType := req.MediaType
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+	httpReq.Header.Set("Content-Type", mediaType)
+
+	res, err := d.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return nil, llmerrors.FromResponse("deepgram", res)
+	}
+
+	var out response
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("deepgram: decoding response: %w", err)
+	}
+
+	resp := &stt.Response{Model: model, Language: req.Language, Duration: stt.Seconds(out.Metadata.Duration)}
+	if len(out.Results.Channels) > 0 {
+		ch := out.Results.Channels[0]
+		if ch.DetectedLanguage != "" {
+			resp.Language = ch.DetectedLanguage
+		}
+		if len(ch.Alternatives) > 0 {
+			alt := ch.Alternatives[0]
+			resp.Text = alt.Transcript
+			if req.Wants(stt.GranularityWord) {
+				for _, w := range alt.Words {
+					text := w.PunctuatedWord
+					if text == "" {
+						text = w.Word
+					}
+					resp.Words = append(resp.Words, stt.Word{Start: stt.Seconds(w.Start), End: stt.Seconds(w.End), Text: text, Confidence: w.Confidence})
+				}
+			}
+		}
+	}
+	for _, u := range out.Results.Utterances {
+		resp.Segments = append(resp.Segments, stt.Segment{Start: stt.Seconds(u.Start), End: stt.Seconds(u.End), Text: u.Transcript})
+	}
+	return resp, nil
+}
+
+func (d *Deepgram) GetTranscriberName() string {
+	return "deepgram"
+}
@@ -0,0 +1,50 @@
+package deepgram
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/stt"
+)
+
+func TestDeepgram_Transcribe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		audio, _ := io.ReadAll(r.Body)
+		q := r.URL.Query()
+		if string(audio) != "audio" || r.Header.Get("Authorization") != "Token key" {
+			t.Errorf("request body = %q, headers = %v", audio, r.Header)
+		}
+		if q.Get("detect_language") != "true" || q.Get("utterances") != "true" {
+			t.Errorf("query = %v", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"metadata":{"duration":1.5},"results":{
+			"channels":[{"detected_language":"en","alternatives":[{"transcript":"hello world",
+				"words":[{"word":"hello","punctuated_word":"Hello","start":0.1,"end":0.5,"confidence":0.9}]}]}],
+			"utterances":[{"start":0.1,"end":1.2,"transcript":"hello world"}]}}`)
+	}))
+	defer srv.Close()
+
+	d := New("key", WithBaseURL(srv.URL))
+	resp, err := d.Transcribe(context.Background(), &stt.Request{
+		Audio:      strings.NewReader("audio"),
+		Timestamps: []stt.Granularity{stt.GranularitySegment, stt.GranularityWord},
+	})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if resp.Text != "hello world" || resp.Language != "en" || resp.Duration != 1500*time.Millisecond {
+		t.Errorf("Transcribe() = %+v", resp)
+	}
+	if len(resp.Words) != 1 || resp.Words[0].Text != "Hello" || resp.Words[0].End != 500*time.Millisecond {
+		t.Errorf("Words = %+v", resp.Words)
+	}
+	if len(resp.Segments) != 1 || resp.Segments[0].End != 1200*time.Millisecond {
+		t.Errorf("Segments = %+v", resp.Segments)
+	}
+}
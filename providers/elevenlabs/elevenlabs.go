@@ -0,0 +1,117 @@
+// Package elevenlabs provides a tts.Synthesizer backed by the ElevenLabs
+// text-to-speech API.
+package elevenlabs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/transport"
+	"github.com/parikxxit/go-llm/tts"
+)
+
+const (
+	defaultBaseURL = "https://api.elevenlabs.io"
+	defaultModel   = "eleven_multilingual_v2"
+)
+
+// outputFormats maps formats to ElevenLabs output formats
+var outputFormats = map[tts.Format]string{
+	tts.FormatMP3:  "mp3_44100_128",
+	tts.FormatOpus: "opus_48000_128",
+	tts.FormatPCM:  "pcm_24000",
+}
+
+// ElevenLabs synthesizes speech with ElevenLabs voices. Request.Voice is a
+// voice ID.
+type ElevenLabs struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Option is a function that configures an ElevenLabs client
+type Option func(*ElevenLabs)
+
+// WithBaseURL sets the API URL, https://api.elevenlabs.io by default
+func WithBaseURL(url string) Option {
+	return func(e *ElevenLabs) {
+		e.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client, transport.DefaultClient by default
+func WithHTTPClient(c *http.Client) Option {
+	return func(e *ElevenLabs) {
+		e.httpClient = c
+	}
+}
+
+// New creates a new ElevenLabs client
+func New(apiKey string, opts ...Option) *ElevenLabs {
+	e := &ElevenLabs{apiKey: apiKey, baseURL: defaultBaseURL, httpClient: transport.DefaultClient()}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Synthesize streams speech for the request text from the streaming endpoint.
+// Supported formats are mp3, opus and pcm.
+func (e *ElevenLabs) Synthesize(ctx context.Context, req *tts.Request) (*tts.Response, error) {
+	if req.Voice == "" {
+		return nil, fmt.Errorf("elevenlabs: a voice ID is required")
+	}
+	format := req.Format
+	if format == "" {
+		format = tts.FormatMP3
+	}
+	output, ok := outputFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("elevenlabs: unsupported format %q", format)
+	}
+	model := req.Model
+	if model == "" {
+		model = defaultModel
+	}
+
+	body := map[string]any{"text": req.Text, "model_id": model}
+	if req.Speed != 0 {
+		body["voice_settings"] = map[string]any{"speed": req.Speed}
+	}
+	for k, v := range req.ProviderParams {
+		body[k] = v
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	u := e.baseURL + "/v1/text-to-speech/" + url.PathEscape(req.Voice) + "/stream?output_format=" + output
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("xi-api-key", e.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	res, err := e.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 300 {
+		defer res.Body.Close()
+		return nil, llmerrors.FromResponse("elevenlabs", res)
+	}
+	return &tts.Response{Audio: res.Body, Format: format}, nil
+}
+
+func (e *ElevenLabs) GetSynthesizerName() string {
+	return "elevenlabs"
+}
@@ -0,0 +1,41 @@
+package elevenlabs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/parikxxit/go-llm/tts"
+)
+
+func TestElevenLabs_Synthesize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/v1/text-to-speech/voice/stream" || r.URL.Query().Get("output_format") != "pcm_24000" {
+			t.Errorf("request = %s", r.URL)
+		}
+		if r.Header.Get("xi-api-key") != "key" || body["text"] != "hello" {
+			t.Errorf("headers = %v, body = %v", r.Header, body)
+		}
+		w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	e := New("key", WithBaseURL(srv.URL))
+	resp, err := e.Synthesize(context.Background(), &tts.Request{Text: "hello", Voice: "voice", Format: tts.FormatPCM})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	defer resp.Audio.Close()
+	if audio, _ := io.ReadAll(resp.Audio); string(audio) != "audio" {
+		t.Errorf("Audio = %q", audio)
+	}
+
+	if _, err := e.Synthesize(context.Background(), &tts.Request{Text: "hello", Voice: "voice", Format: tts.FormatFLAC}); err == nil {
+		t.Error("Synthesize() of flac error = nil")
+	}
+}
@@ -0,0 +1,90 @@
+package mock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/parikxxit/go-llm/embedder"
+)
+
+// Embedder is an embedder.Embedder answering with deterministic, unit
+// length embeddings derived from a hash of each input: equal inputs embed
+// equally across runs, and different ones unrelatedly
+type Embedder struct {
+	// Dimensions of the embeddings when the request sets none, 8 when zero
+	Dimensions int
+	// Err, set, fails every call
+	Err error
+
+	mu       sync.Mutex
+	requests []*embedder.Request
+}
+
+// NewEmbedder creates a mock embedder of the given dimensions
+func NewEmbedder(dimensions int) *Embedder {
+	return &Embedder{Dimensions: dimensions}
+}
+
+func (e *Embedder) Embed(ctx context.Context, req *embedder.Request) (*embedder.Response, error) {
+	e.mu.Lock()
+	e.requests = append(e.requests, req)
+	e.mu.Unlock()
+	if e.Err != nil {
+		return nil, e.Err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	dims := req.Dimensions
+	if dims == 0 {
+		dims = e.Dimensions
+	}
+	if dims == 0 {
+		dims = 8
+	}
+	resp := &embedder.Response{Object: "list", Model: req.Model}
+	for i, in := range req.Input {
+		resp.Data = append(resp.Data, embedder.EmbedData{Object: "embedding", Embedding: HashEmbedding(in, dims), Index: i})
+		resp.Usage.PromptTokens += len(in)/4 + 1
+	}
+	resp.Usage.TotalTokens = resp.Usage.PromptTokens
+	return resp, nil
+}
+
+func (e *Embedder) GetEmbedderName() string {
+	return "mock"
+}
+
+// Requests returns every request received so far
+func (e *Embedder) Requests() []*embedder.Request {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*embedder.Request(nil), e.requests...)
+}
+
+// HashEmbedding returns the unit length embedding of text in dims
+// dimensions used by Embedder
+func HashEmbedding(text string, dims int) []float64 {
+	v := make([]float64, dims)
+	var norm float64
+	var sum [sha256.Size]byte
+	for i := range v {
+		// Each hash gives 4 dimensions
+		if i%4 == 0 {
+			sum = sha256.Sum256([]byte(strconv.Itoa(i/4) + "\x00" + text))
+		}
+		x := binary.BigEndian.Uint64(sum[(i%4)*8:])
+		v[i] = float64(x)/math.MaxUint64*2 - 1
+		norm += v[i] * v[i]
+	}
+	norm = math.Sqrt(norm)
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
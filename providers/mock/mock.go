@@ -0,0 +1,272 @@
+// Package mock provides in-memory providers for tests.
+package mock
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+)
+
+// Mock is a generator.Generator that answers calls with the replies queued
+// by Enqueue, then with GenerateFunc, or echoes the last message when
+// GenerateFunc is nil
+type Mock struct {
+	Name         string
+	GenerateFunc func(ctx context.Context, req *generator.Request) (*generator.Response, error)
+	// Latency delays every call, until the context ends; calls of a context
+	// already done fail at once
+	Latency time.Duration
+
+	mu       sync.Mutex
+	requests []*generator.Request
+	replies  []Reply
+}
+
+// Reply represents the scripted answer to one call
+type Reply struct {
+	Response *generator.Response
+	// Err fails the call; streams fail before the first chunk
+	Err error
+	// Delay delays the call after Mock.Latency, until the context ends; a
+	// reply setting nothing else answers like an empty queue
+	Delay time.Duration
+	// Chunks are sent by GenerateStream instead of Response, ChunkDelay
+	// apart; Generate answers with them concatenated
+	Chunks     []*generator.Response
+	ChunkDelay time.Duration
+	// StreamErr ends streams after the chunks with a chunk of Err set, a
+	// failure midway; Generate fails with it
+	StreamErr error
+}
+
+// RateLimited returns a reply failing as a provider rate limiting the call,
+// asking to retry after retryAfter
+func RateLimited(retryAfter time.Duration) Reply {
+	return Reply{Err: &llmerrors.Error{
+		Kind:       llmerrors.ErrRateLimited,
+		Provider:   "mock",
+		StatusCode: http.StatusTooManyRequests,
+		RetryAfter: retryAfter,
+		Err:        errors.New("rate limit exceeded"),
+	}}
+}
+
+// Failure returns a reply failing as a provider answering with statusCode,
+// classified like provider errors
+func Failure(statusCode int, message string) Reply {
+	return Reply{Err: llmerrors.New("mock", statusCode, "", errors.New(message))}
+}
+
+// Text returns a reply answering content
+func Text(content string) Reply {
+	return Reply{Response: &generator.Response{Object: "chat.completion", Content: content, FinishReason: "stop"}}
+}
+
+// Stream returns a reply streaming a chunk of each content
+func Stream(contents ...string) Reply {
+	r := Reply{}
+	for _, c := range contents {
+		r.Chunks = append(r.Chunks, &generator.Response{Object: "chat.completion.chunk", Content: c})
+	}
+	return r
+}
+
+func init() {
+	generator.Register("mock", func(generator.Config) (generator.Generator, error) {
+		return New(), nil
+	})
+}
+
+// New creates a mock generator that echoes the last message
+func New() *Mock {
+	return &Mock{Name: "mock"}
+}
+
+// Enqueue queues replies answering the next calls, in order
+func (m *Mock) Enqueue(replies ...Reply) *Mock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replies = append(m.replies, replies...)
+	return m
+}
+
+// Pending returns the number of queued replies not served yet
+func (m *Mock) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.replies)
+}
+
+func (m *Mock) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	reply, err := m.call(ctx, req)
+	switch {
+	case err != nil || reply == nil:
+		return m.generate(ctx, req, err)
+	case reply.StreamErr != nil:
+		return nil, reply.StreamErr
+	case reply.Chunks == nil:
+		return reply.Response, nil
+	}
+	resp := *reply.Chunks[len(reply.Chunks)-1]
+	resp.Content, resp.Reasoning, resp.ToolCalls = "", "", nil
+	for _, c := range reply.Chunks {
+		resp.Content += c.Content
+		resp.Reasoning += c.Reasoning
+		resp.ToolCalls = append(resp.ToolCalls, c.ToolCalls...)
+	}
+	return &resp, nil
+}
+
+func (m *Mock) GenerateStream(ctx context.Context, req *generator.Request) (<-chan *generator.Response, error) {
+	reply, err := m.call(ctx, req)
+	if err != nil || reply == nil {
+		resp, err := m.generate(ctx, req, err)
+		if err != nil {
+			return nil, err
+		}
+		reply = &Reply{Chunks: []*generator.Response{resp}}
+	}
+	chunks := reply.Chunks
+	if chunks == nil && reply.Response != nil {
+		chunks = []*generator.Response{reply.Response}
+	}
+
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+		err := reply.StreamErr
+	chunks:
+		for i, chunk := range chunks {
+			if i > 0 && sleep(ctx, reply.ChunkDelay) != nil {
+				err = ctx.Err()
+				break
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				err = ctx.Err()
+				break chunks
+			}
+		}
+		if err != nil {
+			// Like providers, tell a consumer waiting of the failure even once
+			// ctx is done
+			select {
+			case out <- &generator.Response{Err: err}:
+			default:
+				select {
+				case out <- &generator.Response{Err: err}:
+				case <-ctx.Done():
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// call records req and waits the latency, returning the reply queued for
+// it, nil when none is or it only delays the call, or the error of the call
+func (m *Mock) call(ctx context.Context, req *generator.Request) (*Reply, error) {
+	m.mu.Lock()
+	m.requests = append(m.requests, req)
+	var reply *Reply
+	if len(m.replies) > 0 {
+		r := m.replies[0]
+		reply, m.replies = &r, m.replies[1:]
+	}
+	m.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := sleep(ctx, m.Latency); err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, nil
+	}
+	if err := sleep(ctx, reply.Delay); err != nil {
+		return nil, err
+	}
+	if reply.Err != nil {
+		return nil, reply.Err
+	}
+	if reply.Response == nil && reply.Chunks == nil && reply.StreamErr == nil {
+		return nil, nil
+	}
+	return reply, nil
+}
+
+// generate answers req without a queued reply, or fails with err
+func (m *Mock) generate(ctx context.Context, req *generator.Request, err error) (*generator.Response, error) {
+	switch {
+	case err != nil:
+		return nil, err
+	case m.GenerateFunc != nil:
+		return m.GenerateFunc(ctx, req)
+	}
+	return echo(req), nil
+}
+
+func (m *Mock) GetName() string {
+	return m.Name
+}
+
+// Requests returns every request received so far
+func (m *Mock) Requests() []*generator.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*generator.Request(nil), m.requests...)
+}
+
+// LastRequest returns the last request received, nil before any
+func (m *Mock) LastRequest() *generator.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.requests) == 0 {
+		return nil
+	}
+	return m.requests[len(m.requests)-1]
+}
+
+// Calls returns the number of requests received so far
+func (m *Mock) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.requests)
+}
+
+// Reset forgets the requests received and drops the queued replies
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests, m.replies = nil, nil
+}
+
+func echo(req *generator.Request) *generator.Response {
+	resp := &generator.Response{Object: "chat.completion", Model: req.Model}
+	if n := len(req.Messages); n > 0 {
+		resp.Content = req.Messages[n-1].Content
+	}
+	return resp
+}
+
+// sleep waits d, failing when ctx ends first
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
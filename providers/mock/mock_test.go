@@ -0,0 +1,114 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+)
+
+var hi = &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+
+func TestMock_Enqueue(t *testing.T) {
+	m := New().Enqueue(RateLimited(time.Millisecond), Failure(502, "bad gateway"), Text("ok"))
+	client := gollm.NewClient(m, gollm.WithRetryBackoff(time.Millisecond, time.Millisecond))
+	resp, err := client.Generate(context.Background(), hi)
+	if err != nil || resp.Content != "ok" || m.Calls() != 3 {
+		t.Errorf("Generate() = %v, %v after %d calls, want ok after 2 retries", resp, err, m.Calls())
+	}
+
+	// Once the queue is empty, calls echo
+	if resp, err := m.Generate(context.Background(), hi); err != nil || resp.Content != "hi" || m.Pending() != 0 {
+		t.Errorf("Generate() = %v, %v with an empty queue, want the echo", resp, err)
+	}
+	if m.LastRequest() != hi {
+		t.Errorf("LastRequest() = %v, want the last request", m.LastRequest())
+	}
+	m.Reset()
+	if m.Calls() != 0 || m.LastRequest() != nil {
+		t.Errorf("Calls() = %d after Reset()", m.Calls())
+	}
+}
+
+func TestMock_Stream(t *testing.T) {
+	broken := errors.New("connection reset")
+	failing := Stream("a", "b")
+	failing.StreamErr, failing.ChunkDelay = broken, time.Millisecond
+	m := New().Enqueue(failing, Stream("c", "d"), failing)
+
+	stream, err := m.GenerateStream(context.Background(), hi)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	var content string
+	var streamErr error
+	for chunk := range stream {
+		content += chunk.Content
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+		}
+	}
+	if content != "ab" || !errors.Is(streamErr, broken) {
+		t.Errorf("stream = %q ending with %v, want ab then the failure", content, streamErr)
+	}
+
+	// Generate answers with the chunks concatenated, or the stream failure
+	if resp, err := m.Generate(context.Background(), hi); err != nil || resp.Content != "cd" {
+		t.Errorf("Generate() = %v, %v, want cd", resp, err)
+	}
+	if _, err := m.Generate(context.Background(), hi); !errors.Is(err, broken) {
+		t.Errorf("Generate() error = %v, want %v", err, broken)
+	}
+}
+
+func TestMock_Latency(t *testing.T) {
+	m := New()
+	m.Latency = time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.Generate(ctx, hi); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Generate() error = %v, want the deadline", err)
+	}
+
+	m.Latency = 0
+	m.Enqueue(Reply{Delay: 20 * time.Millisecond})
+	start := time.Now()
+	if resp, err := m.Generate(context.Background(), hi); err != nil || resp.Content != "hi" || time.Since(start) < 20*time.Millisecond {
+		t.Errorf("Generate() = %v, %v after %v, want the echo after 20ms", resp, err, time.Since(start))
+	}
+	if !llmerrors.Retryable(RateLimited(0).Err) || !llmerrors.Retryable(Failure(503, "").Err) {
+		t.Error("RateLimited() and Failure(503) are not retryable")
+	}
+}
+
+func TestEmbedder(t *testing.T) {
+	e := NewEmbedder(16)
+	resp, err := e.Embed(context.Background(), &embedder.Request{Input: []string{"cat", "dog", "cat"}})
+	if err != nil || len(resp.Data) != 3 {
+		t.Fatalf("Embed() = %v, %v", resp, err)
+	}
+	a, b, c := resp.Data[0].Embedding, resp.Data[1].Embedding, resp.Data[2].Embedding
+	var norm, same, other float64
+	for i := range a {
+		norm += a[i] * a[i]
+		same += a[i] * c[i]
+		other += a[i] * b[i]
+	}
+	if len(a) != 16 || math.Abs(norm-1) > 1e-9 || math.Abs(same-1) > 1e-9 || other > 0.9 {
+		t.Errorf("embeddings of %d dimensions, norm %v, similarity %v for equal inputs and %v for different ones", len(a), norm, same, other)
+	}
+
+	resp, _ = e.Embed(context.Background(), &embedder.Request{Input: []string{"cat"}, Dimensions: 5})
+	if got := resp.Data[0].Embedding; len(got) != 5 || got[0] != HashEmbedding("cat", 5)[0] {
+		t.Errorf("Embed(Dimensions 5) = %v", got)
+	}
+	if len(e.Requests()) != 2 {
+		t.Errorf("Requests() = %d, want 2", len(e.Requests()))
+	}
+}
@@ -0,0 +1,262 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	openai "github.com/openai/openai-go"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+const defaultBatchPollInterval = 30 * time.Second
+
+// BatchItem represents one request of a batch. ID is chosen by the caller and
+// must be unique within the batch; results are mapped back to it.
+type BatchItem struct {
+	ID      string
+	Request *generator.Request
+}
+
+// BatchResult represents the outcome of one batch item
+type BatchResult struct {
+	ID       string
+	Response *generator.Response
+	Err      error
+}
+
+// Batch represents the state of a submitted batch
+type Batch struct {
+	ID           string
+	Status       openai.BatchStatus
+	Total        int
+	Completed    int
+	Failed       int
+	OutputFileID string
+	ErrorFileID  string
+}
+
+// Done reports whether the batch reached a terminal status
+func (b *Batch) Done() bool {
+	switch b.Status {
+	case openai.BatchStatusCompleted, openai.BatchStatusFailed,
+		openai.BatchStatusExpired, openai.BatchStatusCancelled:
+		return true
+	}
+	return false
+}
+
+type batchLine struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+type batchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// SubmitBatch uploads items as a JSONL file and creates a chat completions
+// batch, which OpenAI completes within 24 hours at a discount
+func (o *OpenAI) SubmitBatch(ctx context.Context, items []BatchItem) (*Batch, error) {
+	input, err := o.batchInput(items)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := o.Client.Files.New(ctx, openai.FileNewParams{
+		File:    openai.File(bytes.NewReader(input), "batch.jsonl", "application/jsonl"),
+		Purpose: openai.FilePurposeBatch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("uploading batch input: %w", wrapError(err))
+	}
+
+	b, err := o.Client.Batches.New(ctx, openai.BatchNewParams{
+		CompletionWindow: openai.BatchNewParamsCompletionWindow24h,
+		Endpoint:         openai.BatchNewParamsEndpointV1ChatCompletions,
+		InputFileID:      file.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating batch: %w", wrapError(err))
+	}
+	return getBatch(b), nil
+}
+
+// BatchStatus returns the current state of a batch
+func (o *OpenAI) BatchStatus(ctx context.Context, id string) (*Batch, error) {
+	b, err := o.Client.Batches.Get(ctx, id)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return getBatch(b), nil
+}
+
+// CancelBatch requests cancellation of a batch
+func (o *OpenAI) CancelBatch(ctx context.Context, id string) (*Batch, error) {
+	b, err := o.Client.Batches.Cancel(ctx, id)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return getBatch(b), nil
+}
+
+// WaitBatch polls a batch every interval (30s when zero) until it is done or
+// ctx is cancelled
+func (o *OpenAI) WaitBatch(ctx context.Context, id string, interval time.Duration) (*Batch, error) {
+	if interval <= 0 {
+		interval = defaultBatchPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		b, err := o.BatchStatus(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if b.Done() {
+			return b, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// BatchResults downloads the output and error files of a finished batch
+func (o *OpenAI) BatchResults(ctx context.Context, b *Batch) ([]BatchResult, error) {
+	var results []BatchResult
+	for _, fileID := range []string{b.OutputFileID, b.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+
+		res, err := o.Client.Files.Content(ctx, fileID)
+		if err != nil {
+			return nil, fmt.Errorf("downloading batch file %s: %w", fileID, wrapError(err))
+		}
+		parsed, err := parseBatchResults(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, parsed...)
+	}
+	return results, nil
+}
+
+// RunBatch submits items, waits for the batch to finish and returns results
+// keyed by item ID
+func (o *OpenAI) RunBatch(ctx context.Context, items []BatchItem, interval time.Duration) (map[string]BatchResult, error) {
+	b, err := o.SubmitBatch(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = o.WaitBatch(ctx, b.ID, interval); err != nil {
+		return nil, err
+	}
+	if b.Status != openai.BatchStatusCompleted {
+		return nil, fmt.Errorf("batch %s ended with status %s", b.ID, b.Status)
+	}
+
+	results, err := o.BatchResults(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]BatchResult, len(results))
+	for _, r := range results {
+		byID[r.ID] = r
+	}
+	return byID, nil
+}
+
+func (o *OpenAI) batchInput(items []BatchItem) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if item.ID == "" || seen[item.ID] {
+			return nil, fmt.Errorf("batch item IDs must be unique and non-empty: %q", item.ID)
+		}
+		seen[item.ID] = true
+
+		params := o.chatParams(item.Request)
+		body, err := json.Marshal(params)
+		releaseMessages(params.Messages)
+		if err != nil {
+			return nil, fmt.Errorf("encoding batch item %s: %w", item.ID, err)
+		}
+		if err := enc.Encode(batchLine{
+			CustomID: item.ID,
+			Method:   "POST",
+			URL:      string(openai.BatchNewParamsEndpointV1ChatCompletions),
+			Body:     body,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func parseBatchResults(r io.Reader) ([]BatchResult, error) {
+	var results []BatchResult
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var line batchOutputLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("decoding batch result: %w", err)
+		}
+		result := BatchResult{ID: line.CustomID}
+		switch {
+		case line.Error != nil:
+			result.Err = fmt.Errorf("%s: %s", line.Error.Code, line.Error.Message)
+		case line.Response == nil:
+			result.Err = fmt.Errorf("%s", errNoModelResponse)
+		case line.Response.StatusCode != 200:
+			result.Err = fmt.Errorf("batch request failed with status %d: %s", line.Response.StatusCode, line.Response.Body)
+		default:
+			var chat openai.ChatCompletion
+			if err := json.Unmarshal(line.Response.Body, &chat); err != nil {
+				result.Err = fmt.Errorf("decoding batch response: %w", err)
+			} else {
+				result.Response, result.Err = getResponse(&chat)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, scanner.Err()
+}
+
+func getBatch(b *openai.Batch) *Batch {
+	return &Batch{
+		ID:           b.ID,
+		Status:       b.Status,
+		Total:        int(b.RequestCounts.Total),
+		Completed:    int(b.RequestCounts.Completed),
+		Failed:       int(b.RequestCounts.Failed),
+		OutputFileID: b.OutputFileID,
+		ErrorFileID:  b.ErrorFileID,
+	}
+}
@@ -0,0 +1,116 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+func TestBatchInput(t *testing.T) {
+	o := NewOpenAI(generator.Config{ApiKey: "test", Model: "gpt-4o-mini"})
+	request := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+
+	input, err := o.batchInput([]BatchItem{{ID: "a", Request: request}, {ID: "b", Request: request}})
+	if err != nil {
+		t.Fatalf("batchInput() error = %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(input), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("lines = %d, want 2", len(lines))
+	}
+	var line batchLine
+	if err := json.Unmarshal(lines[0], &line); err != nil {
+		t.Fatalf("decoding line: %v", err)
+	}
+	if line.CustomID != "a" || line.URL != "/v1/chat/completions" || !bytes.Contains(line.Body, []byte(`"gpt-4o-mini"`)) {
+		t.Errorf("line = %+v", line)
+	}
+
+	if _, err := o.batchInput([]BatchItem{{ID: "a", Request: request}, {ID: "a", Request: request}}); err == nil {
+		t.Error("batchInput() with duplicate IDs error = nil")
+	}
+}
+
+func TestOpenAI_SubmitBatch(t *testing.T) {
+	var uploaded []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/files":
+			f, _, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("reading uploaded file: %v", err)
+				return
+			}
+			uploaded, _ = io.ReadAll(f)
+			io.WriteString(w, `{"id":"file-1","object":"file","purpose":"batch"}`)
+		case "/batches":
+			io.WriteString(w, `{"id":"batch-1","object":"batch","status":"validating","input_file_id":"file-1"}`)
+		default:
+			t.Errorf("request to %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	o := NewOpenAI(generator.Config{ApiKey: "test", BaseURL: srv.URL, Model: "gpt-4o"})
+	messages := []generator.Message{{Role: generator.USER, Content: "hi"}}
+	items := []BatchItem{
+		{ID: "a", Request: &generator.Request{Messages: messages}},
+		{ID: "b", Request: &generator.Request{Model: "gpt-4o-mini", Messages: messages, Temperature: 0.2, MaxTokens: 16}},
+	}
+	if _, err := o.SubmitBatch(context.Background(), items); err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(uploaded), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("uploaded lines = %d, want 2", len(lines))
+	}
+	want := []map[string]any{
+		{"model": "gpt-4o", "temperature": nil, "max_completion_tokens": nil},
+		{"model": "gpt-4o-mini", "temperature": 0.2, "max_completion_tokens": 16.0},
+	}
+	for i, l := range lines {
+		var line struct {
+			CustomID string         `json:"custom_id"`
+			Body     map[string]any `json:"body"`
+		}
+		if err := json.Unmarshal(l, &line); err != nil {
+			t.Fatalf("decoding line %d: %v", i, err)
+		}
+		if line.CustomID != items[i].ID {
+			t.Errorf("line %d custom_id = %q, want %q", i, line.CustomID, items[i].ID)
+		}
+		for k, v := range want[i] {
+			if got := line.Body[k]; got != v {
+				t.Errorf("line %d sent %s = %v, want %v", i, k, got, v)
+			}
+		}
+	}
+}
+
+func TestParseBatchResults(t *testing.T) {
+	output := `{"custom_id":"a","response":{"status_code":200,"body":{"id":"x","model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"hello"}}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}},"error":null}
+{"custom_id":"b","response":null,"error":{"code":"rate_limit","message":"slow down"}}
+`
+	results, err := parseBatchResults(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("parseBatchResults() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %d, want 2", len(results))
+	}
+	if results[0].Err != nil || results[0].Response.Content != "hello" {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	if results[1].ID != "b" || results[1].Err == nil {
+		t.Errorf("results[1] = %+v", results[1])
+	}
+}
@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openai "github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providertest"
+)
+
+// fakeAPI answers chat completions, streamed or not, and embeddings like
+// the OpenAI API
+func fakeAPI(t *testing.T) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/embeddings"):
+			var req struct{ Input []string }
+			json.Unmarshal(body, &req)
+			var data []string
+			for i := range req.Input {
+				data = append(data, `{"object":"embedding","index":`+string(rune('0'+i))+`,"embedding":[0.6,0.8]}`)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"object":"list","model":"m","data":[`+strings.Join(data, ",")+`],"usage":{"prompt_tokens":6,"total_tokens":6}}`)
+		case strings.Contains(string(body), `"stream":true`):
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, `data: {"id":"1","object":"chat.completion.chunk","model":"m","choices":[{"index":0,"delta":{"content":"po"}}]}`+"\n\n")
+			io.WriteString(w, `data: {"id":"1","object":"chat.completion.chunk","model":"m","choices":[{"index":0,"delta":{"content":"ng"},"finish_reason":"stop"}]}`+"\n\n")
+			io.WriteString(w, `data: {"id":"1","object":"chat.completion.chunk","model":"m","choices":[],"usage":{"prompt_tokens":9,"completion_tokens":1,"total_tokens":10}}`+"\n\n")
+			io.WriteString(w, "data: [DONE]\n\n")
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"id":"1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"pong"},"finish_reason":"stop"}],"usage":{"prompt_tokens":9,"completion_tokens":1,"total_tokens":10}}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func newTestOpenAI(baseURL string) *OpenAI {
+	return &OpenAI{Client: openai.NewClient(option.WithBaseURL(baseURL), option.WithAPIKey("test"), option.WithMaxRetries(0)), Model: "m"}
+}
+
+func TestOpenAI_Conformance(t *testing.T) {
+	providertest.RunGeneratorTests(t, providertest.GeneratorConfig{
+		New:            func(t *testing.T) generator.Generator { return newTestOpenAI(fakeAPI(t)) },
+		NewWithBaseURL: func(_ *testing.T, baseURL string) generator.Generator { return newTestOpenAI(baseURL) },
+	})
+	providertest.RunEmbedderTests(t, providertest.EmbedderConfig{
+		New:            func(t *testing.T) embedder.Embedder { return newTestOpenAI(fakeAPI(t)) },
+		NewWithBaseURL: func(_ *testing.T, baseURL string) embedder.Embedder { return newTestOpenAI(baseURL) },
+	})
+}
@@ -0,0 +1,100 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/parikxxit/go-llm/embedder"
+)
+
+// maxEmbedInputs is the number of inputs the embeddings endpoint accepts per
+// request
+const maxEmbedInputs = 2048
+
+const defaultEmbeddingModel = "text-embedding-3-small"
+
+// Embed creates embeddings for the request inputs. Float32 requests use the
+// base64 encoding by default, which is decoded straight into float32.
+func (o *OpenAI) Embed(ctx context.Context, req *embedder.Request) (*embedder.Response, error) {
+	model := req.Model
+	if model == "" {
+		model = defaultEmbeddingModel
+	}
+	params := openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: req.Input},
+		Model: openai.EmbeddingModel(model),
+	}
+	if req.Dimensions > 0 {
+		params.Dimensions = openai.Int(int64(req.Dimensions))
+	}
+	if req.User != "" {
+		params.User = openai.String(req.User)
+	}
+	encoding := req.EncodingFormat
+	if encoding == "" && req.Float32 {
+		encoding = embedder.EncodingBase64
+	}
+	if encoding != "" {
+		params.EncodingFormat = openai.EmbeddingNewParamsEncodingFormat(encoding)
+	}
+
+	r, err := o.Client.Embeddings.New(ctx, params)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	resp := &embedder.Response{
+		Object: string(r.Object),
+		Model:  r.Model,
+		Data:   make([]embedder.EmbedData, len(r.Data)),
+		Usage: embedder.TokenUsage{
+			PromptTokens: int(r.Usage.PromptTokens),
+			TotalTokens:  int(r.Usage.TotalTokens),
+		},
+	}
+	for i, d := range r.Data {
+		data := embedder.EmbedData{Object: string(d.Object), Index: int(d.Index)}
+		if encoding == embedder.EncodingBase64 {
+			// The SDK only decodes float arrays; base64 stays in the raw field
+			encoded, err := rawString(d.JSON.Embedding.Raw())
+			if err != nil {
+				return nil, fmt.Errorf("embedding %d: expected base64 string: %w", d.Index, err)
+			}
+			if data.Embedding32, err = embedder.DecodeBase64(encoded); err != nil {
+				return nil, err
+			}
+			if !req.Float32 {
+				data.Embedding, data.Embedding32 = embedder.Float64(data.Embedding32), nil
+			}
+		} else if req.Float32 {
+			data.Embedding32 = embedder.Float32(d.Embedding)
+		} else {
+			data.Embedding = d.Embedding
+		}
+		resp.Data[i] = data
+	}
+	return resp, nil
+}
+
+// rawString returns the string of a raw JSON string, without copying it
+// when it has no escapes, as base64 does not
+func rawString(raw string) (string, error) {
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' && !strings.ContainsRune(raw, '\\') {
+		return raw[1 : len(raw)-1], nil
+	}
+	var s string
+	err := json.Unmarshal([]byte(raw), &s)
+	return s, err
+}
+
+// MaxBatchSize implements embedder.BatchLimiter
+func (o *OpenAI) MaxBatchSize() int {
+	return maxEmbedInputs
+}
+
+func (o *OpenAI) GetEmbedderName() string {
+	return "openai"
+}
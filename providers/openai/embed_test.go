@@ -0,0 +1,88 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openai "github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/parikxxit/go-llm/embedder"
+)
+
+func TestOpenAI_Embed(t *testing.T) {
+	var formats []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			EncodingFormat string `json:"encoding_format"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		formats = append(formats, body.EncodingFormat)
+
+		w.Header().Set("Content-Type", "application/json")
+		embedding := `[0.5,-1.25]`
+		if body.EncodingFormat == "base64" {
+			embedding = `"` + embedder.EncodeBase64([]float32{0.5, -1.25}) + `"`
+		}
+		io.WriteString(w, `{"object":"list","model":"m","data":[{"object":"embedding","index":0,"embedding":`+embedding+`}],"usage":{"prompt_tokens":2,"total_tokens":2}}`)
+	}))
+	defer srv.Close()
+
+	o := &OpenAI{Client: openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"))}
+	ctx := context.Background()
+
+	resp, err := o.Embed(ctx, &embedder.Request{Input: []string{"hi"}})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if d := resp.Data[0]; len(d.Embedding) != 2 || d.Embedding[1] != -1.25 || d.Embedding32 != nil {
+		t.Errorf("Embed() data = %+v", d)
+	}
+
+	resp, err = o.Embed(ctx, &embedder.Request{Input: []string{"hi"}, Float32: true})
+	if err != nil {
+		t.Fatalf("Embed(Float32) error = %v", err)
+	}
+	if d := resp.Data[0]; len(d.Embedding32) != 2 || d.Embedding32[0] != 0.5 || d.Embedding != nil {
+		t.Errorf("Embed(Float32) data = %+v", d)
+	}
+
+	resp, err = o.Embed(ctx, &embedder.Request{Input: []string{"hi"}, EncodingFormat: embedder.EncodingBase64})
+	if err != nil {
+		t.Fatalf("Embed(base64) error = %v", err)
+	}
+	if d := resp.Data[0]; len(d.Embedding) != 2 || d.Embedding[1] != -1.25 {
+		t.Errorf("Embed(base64) data = %+v", d)
+	}
+	if formats[0] != "" || formats[1] != "base64" {
+		t.Errorf("encoding formats = %v, want default then base64", formats)
+	}
+}
+
+func BenchmarkOpenAI_Embed(b *testing.B) {
+	var data strings.Builder
+	for i := 0; i < 64; i++ {
+		if i > 0 {
+			data.WriteString(",")
+		}
+		fmt.Fprintf(&data, `{"object":"embedding","index":%d,"embedding":"%s"}`, i, embedder.EncodeBase64(make([]float32, 1536)))
+	}
+	body := `{"object":"list","model":"m","data":[` + data.String() + `],"usage":{"prompt_tokens":64,"total_tokens":64}}`
+	client := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"application/json"}}, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})}
+	o := &OpenAI{Client: openai.NewClient(option.WithHTTPClient(client), option.WithAPIKey("test"))}
+	req := &embedder.Request{Input: make([]string, 64), Float32: true}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := o.Embed(context.Background(), req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
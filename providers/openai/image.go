@@ -0,0 +1,71 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/openai/openai-go"
+	"github.com/parikxxit/go-llm/imagegen"
+)
+
+const defaultImageModel = "gpt-image-1"
+
+// GenerateImage creates images for the request prompt. gpt-image-1 always
+// returns bytes; DALL·E models return URLs unless bytes are requested.
+func (o *OpenAI) GenerateImage(ctx context.Context, req *imagegen.Request) (*imagegen.Response, error) {
+	model := req.Model
+	if model == "" {
+		model = defaultImageModel
+	}
+	params := openai.ImageGenerateParams{
+		Prompt: req.Prompt,
+		Model:  openai.ImageModel(model),
+	}
+	if req.N > 0 {
+		params.N = openai.Int(int64(req.N))
+	}
+	if req.Size != "" {
+		params.Size = openai.ImageGenerateParamsSize(req.Size)
+	}
+	if req.Quality != "" {
+		params.Quality = openai.ImageGenerateParamsQuality(req.Quality)
+	}
+	if req.Style != "" {
+		params.Style = openai.ImageGenerateParamsStyle(req.Style)
+	}
+	if req.User != "" {
+		params.User = openai.String(req.User)
+	}
+	// gpt-image-1 rejects response_format
+	switch req.Format {
+	case imagegen.FormatBytes:
+		if model != defaultImageModel {
+			params.ResponseFormat = openai.ImageGenerateParamsResponseFormatB64JSON
+		}
+	case imagegen.FormatURL:
+		params.ResponseFormat = openai.ImageGenerateParamsResponseFormatURL
+	}
+
+	r, err := o.Client.Images.Generate(ctx, params)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	resp := &imagegen.Response{Model: model, Images: make([]imagegen.Image, len(r.Data))}
+	for i, d := range r.Data {
+		img := imagegen.Image{URL: d.URL, RevisedPrompt: d.RevisedPrompt}
+		if d.B64JSON != "" {
+			if img.Data, err = base64.StdEncoding.DecodeString(d.B64JSON); err != nil {
+				return nil, fmt.Errorf("decoding image %d: %w", i, err)
+			}
+			img.MediaType = img.ContentType()
+		}
+		resp.Images[i] = img
+	}
+	return resp, nil
+}
+
+func (o *OpenAI) GetImageGeneratorName() string {
+	return "openai"
+}
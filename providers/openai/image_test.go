@@ -0,0 +1,38 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai "github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/parikxxit/go-llm/imagegen"
+)
+
+func TestOpenAI_GenerateImage(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"created":1,"data":[{"b64_json":"`+base64.StdEncoding.EncodeToString(png)+`","revised_prompt":"a red fox"}]}`)
+	}))
+	defer srv.Close()
+
+	o := &OpenAI{Client: openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("key"))}
+	resp, err := o.GenerateImage(context.Background(), &imagegen.Request{Prompt: "a fox", Size: "1024x1024", Format: imagegen.FormatBytes})
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+	if _, ok := body["response_format"]; ok || body["model"] != "gpt-image-1" {
+		t.Errorf("request = %v, want gpt-image-1 without response_format", body)
+	}
+	if len(resp.Images) != 1 || resp.Images[0].MediaType != "image/png" || resp.Images[0].RevisedPrompt != "a red fox" {
+		t.Errorf("GenerateImage() = %+v", resp)
+	}
+}
@@ -0,0 +1,69 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/parikxxit/go-llm/moderation"
+)
+
+// moderationCategories maps OpenAI categories, without their subcategory
+// suffix, to normalized ones
+var moderationCategories = map[string]moderation.Category{
+	"hate":       moderation.CategoryHate,
+	"harassment": moderation.CategoryHarassment,
+	"self-harm":  moderation.CategorySelfHarm,
+	"sexual":     moderation.CategorySexual,
+	"violence":   moderation.CategoryViolence,
+	"illicit":    moderation.CategoryIllicit,
+}
+
+// Moderate classifies the request inputs. Subcategories such as
+// "hate/threatening" are merged into their category.
+func (o *OpenAI) Moderate(ctx context.Context, req *moderation.Request) (*moderation.Response, error) {
+	params := openai.ModerationNewParams{
+		Input: openai.ModerationNewParamsInputUnion{OfModerationNewsInputArray: req.Input},
+	}
+	if req.Model != "" {
+		params.Model = openai.ModerationModel(req.Model)
+	}
+
+	r, err := o.Client.Moderations.New(ctx, params)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	resp := &moderation.Response{Model: r.Model, Results: make([]moderation.Result, len(r.Results))}
+	for i, m := range r.Results {
+		// The SDK models categories as struct fields; the raw maps are simpler to normalize
+		var raw struct {
+			Categories     map[string]bool    `json:"categories"`
+			CategoryScores map[string]float64 `json:"category_scores"`
+		}
+		if err := json.Unmarshal([]byte(m.RawJSON()), &raw); err != nil {
+			return nil, fmt.Errorf("decoding moderation result %d: %w", i, err)
+		}
+		scores := make(map[moderation.Category]float64)
+		flagged := make(map[moderation.Category]bool)
+		for name, score := range raw.CategoryScores {
+			c, ok := moderationCategories[strings.SplitN(name, "/", 2)[0]]
+			if ok && score > scores[c] {
+				scores[c] = score
+			}
+		}
+		for name, f := range raw.Categories {
+			if c, ok := moderationCategories[strings.SplitN(name, "/", 2)[0]]; ok && f {
+				flagged[c] = true
+			}
+		}
+		resp.Results[i] = moderation.NewResult(scores, flagged)
+	}
+	return resp, nil
+}
+
+func (o *OpenAI) GetModeratorName() string {
+	return "openai"
+}
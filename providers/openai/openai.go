@@ -2,13 +2,22 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	openai "github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/packages/resp"
+	"github.com/openai/openai-go/shared"
+	"github.com/parikxxit/go-llm/auth"
 	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/keypool"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/transport"
 )
 
 const (
@@ -19,37 +28,310 @@ const (
 type OpenAI struct {
 	Client openai.Client
 	Model  string
+
+	dialect GrammarDialect
+}
+
+// GrammarDialect identifies how a server of the chat completions API takes
+// grammars
+type GrammarDialect int
+
+const (
+	// DialectOpenAI takes JSON schemas as structured outputs
+	DialectOpenAI GrammarDialect = iota
+	// DialectLlamaCpp takes GBNF grammars and JSON schemas as the llama.cpp
+	// server does
+	DialectLlamaCpp
+	// DialectVLLM takes GBNF grammars, regular expressions and JSON schemas
+	// as vLLM guided decoding does
+	DialectVLLM
+)
+
+func init() {
+	generator.Register("openai", func(cfg generator.Config) (generator.Generator, error) {
+		return NewOpenAI(cfg), nil
+	})
+}
+
+// Option is a function that configures an OpenAI client
+type Option func(*settings)
+
+type settings struct {
+	keys    *keypool.Pool
+	dialect GrammarDialect
+}
+
+// WithKeyPool rotates requests among the keys of pool instead of using
+// cfg.ApiKey; rate limited keys are switched at once
+func WithKeyPool(pool *keypool.Pool) Option {
+	return func(s *settings) {
+		s.keys = pool
+	}
+}
+
+// WithGrammarDialect sets how grammars are sent, DialectOpenAI by default;
+// set it for compatible servers with constrained decoding, e.g. llama.cpp
+// or vLLM through cfg.BaseURL
+func WithGrammarDialect(d GrammarDialect) Option {
+	return func(s *settings) {
+		s.dialect = d
+	}
 }
 
 // Should we return error?
-func NewOpenAI(cfg generator.Config) *OpenAI {
+func NewOpenAI(cfg generator.Config, opts ...Option) *OpenAI {
+	var s settings
+	for _, opt := range opts {
+		opt(&s)
+	}
+	options := []option.RequestOption{
+		option.WithAPIKey(cfg.ApiKey),
+		// gollm.Client retries, honoring Retry-After across providers
+		option.WithMaxRetries(0),
+	}
+	if cfg.BaseURL != "" {
+		options = append(options, option.WithBaseURL(cfg.BaseURL))
+	}
+	for k, v := range cfg.Headers {
+		options = append(options, option.WithHeader(k, v))
+	}
+	if cfg.Timeout > 0 {
+		options = append(options, option.WithRequestTimeout(cfg.Timeout))
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = transport.DefaultClient()
+	}
+	if s.keys != nil {
+		c := *httpClient
+		c.Transport = s.keys.Transport(c.Transport, keypool.Bearer)
+		httpClient = &c
+	}
+	if cfg.Auth != nil {
+		c := *httpClient
+		c.Transport = auth.Transport(c.Transport, cfg.Auth)
+		httpClient = &c
+	}
+	options = append(options, option.WithHTTPClient(httpClient))
 	return &OpenAI{
-		Client: openai.NewClient(
-			option.WithAPIKey(cfg.ApiKey),
-		),
-		Model: cfg.Model,
+		Client:  openai.NewClient(options...),
+		Model:   cfg.Model,
+		dialect: s.dialect,
 	}
 }
 
 func (o *OpenAI) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
-	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(req.Messages))
-	for _, m := range req.Messages {
+	if err := o.checkGrammar(req); err != nil {
+		return nil, err
+	}
+	params := o.chatParams(req)
+	chat, err := o.Client.Chat.Completions.New(ctx, params, requestOptions(req)...)
+	releaseMessages(params.Messages)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	resp, err := getResponse(chat)
+	if err == nil && generator.RawCaptured(ctx) {
+		resp.Raw = json.RawMessage(chat.RawJSON())
+	}
+	return resp, err
+}
+
+func (o *OpenAI) chatParams(req *generator.Request) openai.ChatCompletionNewParams {
+	model := req.Model
+	if model == "" {
+		model = o.Model
+	}
+	params := openai.ChatCompletionNewParams{
+		Messages: toMessages(req.Messages),
+		Model:    model,
+	}
+	if req.MaxTokens > 0 {
+		// OpenAI deprecated max_tokens, which reasoning models reject, while
+		// compatible servers may only take it
+		if o.dialect == DialectOpenAI {
+			params.MaxCompletionTokens = openai.Int(int64(req.MaxTokens))
+		} else {
+			params.MaxTokens = openai.Int(int64(req.MaxTokens))
+		}
+	}
+	if req.Temperature != 0 {
+		params.Temperature = openai.Float(req.Temperature)
+	}
+	if req.TopP != 0 {
+		params.TopP = openai.Float(req.TopP)
+	}
+	if len(req.Stop) > 0 {
+		params.Stop.OfChatCompletionNewsStopArray = req.Stop
+	}
+	if req.User != "" {
+		params.User = openai.String(req.User)
+	}
+	if req.ReasoningEffort != "" {
+		params.ReasoningEffort = shared.ReasoningEffort(req.ReasoningEffort)
+	}
+	for _, t := range req.Tools {
+		fn := shared.FunctionDefinitionParam{
+			Name:       t.Name,
+			Parameters: shared.FunctionParameters(t.Parameters),
+		}
+		if t.Description != "" {
+			fn.Description = openai.String(t.Description)
+		}
+		params.Tools = append(params.Tools, openai.ChatCompletionToolParam{Function: fn})
+	}
+	switch req.ToolChoice {
+	case "":
+	case "auto", "none", "required":
+		params.ToolChoice.OfAuto = openai.String(req.ToolChoice)
+	default:
+		params.ToolChoice = openai.ChatCompletionToolChoiceOptionParamOfChatCompletionNamedToolChoice(
+			openai.ChatCompletionNamedToolChoiceFunctionParam{Name: req.ToolChoice},
+		)
+	}
+	if g := req.Grammar; g != nil {
+		o.setGrammar(&params, g)
+	}
+	return params
+}
+
+// SupportsGrammar implements generator.GrammarSupporter for the grammars of
+// the dialect
+func (o *OpenAI) SupportsGrammar(kind generator.GrammarKind) bool {
+	switch kind {
+	case generator.GrammarJSONSchema:
+		return true
+	case generator.GrammarGBNF:
+		return o.dialect == DialectLlamaCpp || o.dialect == DialectVLLM
+	case generator.GrammarRegex:
+		return o.dialect == DialectVLLM
+	}
+	return false
+}
+
+func (o *OpenAI) checkGrammar(req *generator.Request) error {
+	if req.Grammar != nil && !o.SupportsGrammar(req.Grammar.Kind) {
+		return fmt.Errorf("openai: %s grammars are not supported by the dialect", req.Grammar.Kind)
+	}
+	return nil
+}
+
+// setGrammar sets g in params as the dialect takes it
+func (o *OpenAI) setGrammar(params *openai.ChatCompletionNewParams, g *generator.Grammar) {
+	switch {
+	case o.dialect == DialectOpenAI && g.Kind == generator.GrammarJSONSchema:
+		name := g.Name
+		if name == "" {
+			name = "response"
+		}
+		params.ResponseFormat.OfJSONSchema = &shared.ResponseFormatJSONSchemaParam{
+			JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{Name: name, Schema: g.Schema, Strict: openai.Bool(true)},
+		}
+	case o.dialect == DialectLlamaCpp && g.Kind == generator.GrammarGBNF:
+		params.WithExtraFields(map[string]any{"grammar": g.Definition})
+	case o.dialect == DialectLlamaCpp && g.Kind == generator.GrammarJSONSchema:
+		params.WithExtraFields(map[string]any{"json_schema": g.Schema})
+	case o.dialect == DialectVLLM && g.Kind == generator.GrammarGBNF:
+		params.WithExtraFields(map[string]any{"guided_grammar": g.Definition})
+	case o.dialect == DialectVLLM && g.Kind == generator.GrammarRegex:
+		params.WithExtraFields(map[string]any{"guided_regex": g.Definition})
+	case o.dialect == DialectVLLM && g.Kind == generator.GrammarJSONSchema:
+		params.WithExtraFields(map[string]any{"guided_json": g.Schema})
+	}
+}
+
+// requestOptions returns the per-request options of req
+func requestOptions(req *generator.Request) []option.RequestOption {
+	if req.IdempotencyKey == "" {
+		return nil
+	}
+	return []option.RequestOption{option.WithHeader("Idempotency-Key", req.IdempotencyKey)}
+}
+
+// SupportsIdempotencyKeys implements generator.IdempotencySupporter
+func (o *OpenAI) SupportsIdempotencyKeys() bool {
+	return true
+}
+
+// ListModels implements generator.ModelLister
+func (o *OpenAI) ListModels(ctx context.Context) ([]generator.ModelInfo, error) {
+	var models []generator.ModelInfo
+	iter := o.Client.Models.ListAutoPaging(ctx)
+	for iter.Next() {
+		m := iter.Current()
+		models = append(models, generator.ModelInfo{ID: m.ID, OwnedBy: m.OwnedBy, Created: time.Unix(m.Created, 0)})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+	return models, nil
+}
+
+// CheckHealth implements generator.HealthChecker by fetching the model, or
+// listing the models when none is configured
+func (o *OpenAI) CheckHealth(ctx context.Context) error {
+	var err error
+	if o.Model != "" {
+		_, err = o.Client.Models.Get(ctx, o.Model)
+	} else {
+		_, err = o.Client.Models.List(ctx)
+	}
+	if err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// messagePool holds message slices released once their request is sent
+var messagePool sync.Pool
+
+// toMessages converts in, into a slice of messagePool to release with
+// releaseMessages once sent
+func toMessages(in []generator.Message) []openai.ChatCompletionMessageParamUnion {
+	var messages []openai.ChatCompletionMessageParamUnion
+	if p, ok := messagePool.Get().(*[]openai.ChatCompletionMessageParamUnion); ok && cap(*p) >= len(in) {
+		messages = (*p)[:0]
+	} else {
+		messages = make([]openai.ChatCompletionMessageParamUnion, 0, len(in))
+	}
+	for _, m := range in {
 		switch m.Role {
+		case generator.SYSTEM:
+			messages = append(messages, openai.SystemMessage(m.Content))
 		case generator.USER:
 			messages = append(messages, openai.UserMessage(m.Content))
 		case generator.ASSISTANT:
-			messages = append(messages, openai.AssistantMessage(m.Content))
+			if len(m.ToolCalls) == 0 {
+				messages = append(messages, openai.AssistantMessage(m.Content))
+				continue
+			}
+			assistant := openai.ChatCompletionAssistantMessageParam{}
+			if m.Content != "" {
+				assistant.Content.OfString = openai.String(m.Content)
+			}
+			for _, call := range m.ToolCalls {
+				assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallParam{
+					ID: call.ID,
+					Function: openai.ChatCompletionMessageToolCallFunctionParam{
+						Name:      call.Name,
+						Arguments: call.Arguments,
+					},
+				})
+			}
+			messages = append(messages, openai.ChatCompletionMessageParamUnion{OfAssistant: &assistant})
+		case generator.TOOL:
+			messages = append(messages, openai.ToolMessage(m.Content, m.ToolCallID))
 		}
 	}
+	return messages
+}
 
-	chat, err := o.Client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-		Messages: messages,
-		Model:    o.Model,
-	})
-	if err != nil {
-		return nil, err
-	}
-	return getResponse(chat)
+// releaseMessages returns messages to messagePool, clearing them so the
+// pool holds no content
+func releaseMessages(messages []openai.ChatCompletionMessageParamUnion) {
+	clear(messages[:cap(messages)])
+	messages = messages[:0]
+	messagePool.Put(&messages)
 }
 
 func (o *OpenAI) Chat(ctx context.Context, messages []generator.Message) (*generator.Response, error) {
@@ -58,8 +340,72 @@ func (o *OpenAI) Chat(ctx context.Context, messages []generator.Message) (*gener
 }
 
 func (o *OpenAI) GenerateStream(ctx context.Context, req *generator.Request) (<-chan *generator.Response, error) {
-	//TODO: implement before merge
-	return nil, nil
+	if err := o.checkGrammar(req); err != nil {
+		return nil, err
+	}
+	params := o.chatParams(req)
+	params.StreamOptions.IncludeUsage = openai.Bool(true)
+
+	stream := o.Client.Chat.Completions.NewStreaming(ctx, params, requestOptions(req)...)
+	// The request body is sent by now
+	releaseMessages(params.Messages)
+	if err := stream.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+		defer stream.Close()
+
+		id := uuid.New().String()
+		raw := generator.RawCaptured(ctx)
+		var calls []generator.ToolCall
+	chunks:
+		for stream.Next() {
+			chunk := stream.Current()
+			resp := generator.AcquireChunk()
+			resp.ID, resp.Object, resp.Created = id, "chat.completion.chunk", time.Now().Unix()
+			resp.Model, resp.Usage = chunk.Model, getUsage(chunk.Usage)
+			if raw {
+				resp.Raw = json.RawMessage(chunk.RawJSON())
+			}
+			if len(chunk.Choices) > 0 {
+				choice := chunk.Choices[0]
+				resp.Content = choice.Delta.Content
+				resp.Reasoning = reasoningContent(choice.Delta.JSON.ExtraFields)
+				resp.FinishReason = choice.FinishReason
+				calls = accumulateToolCalls(calls, choice.Delta.ToolCalls)
+				// Tool calls arrive in fragments and are only usable once complete
+				if choice.FinishReason != "" {
+					resp.ToolCalls, calls = calls, nil
+				}
+			}
+			select {
+			case out <- resp:
+			case <-ctx.Done():
+				break chunks
+			}
+		}
+		err := stream.Err()
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			// A consumer waiting is told of the failure even once ctx is done,
+			// so a cancelled stream is not taken for a complete one
+			errChunk := &generator.Response{ID: id, Object: "chat.completion.chunk", Created: time.Now().Unix(), Err: wrapError(err)}
+			select {
+			case out <- errChunk:
+			default:
+				select {
+				case out <- errChunk:
+				case <-ctx.Done():
+				}
+			}
+		}
+	}()
+	return out, nil
 }
 
 func (o *OpenAI) GetName() string {
@@ -71,16 +417,76 @@ func getResponse(r *openai.ChatCompletion) (*generator.Response, error) {
 		return nil, fmt.Errorf("%s: %s", errNoModelResponse, r.Model)
 	}
 	choice := r.Choices[0]
-	return &generator.Response{
-		ID:      uuid.New().String(),
-		Object:  "chat.completion",
-		Created: time.Now().Unix(),
-		Model:   r.Model,
-		Content: choice.Message.Content,
-		Usage: generator.TokenUsage{
-			PromptTokens:     int(r.Usage.PromptTokens),
-			CompletionTokens: int(r.Usage.CompletionTokens),
-			TotalTokens:      int(r.Usage.TotalTokens),
-		},
-	}, nil
+	resp := &generator.Response{
+		ID:           uuid.New().String(),
+		Object:       "chat.completion",
+		Created:      time.Now().Unix(),
+		Model:        r.Model,
+		Content:      choice.Message.Content,
+		Reasoning:    reasoningContent(choice.Message.JSON.ExtraFields),
+		FinishReason: choice.FinishReason,
+		Usage:        getUsage(r.Usage),
+	}
+	for _, call := range choice.Message.ToolCalls {
+		resp.ToolCalls = append(resp.ToolCalls, generator.ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return resp, nil
+}
+
+// wrapError classifies OpenAI API errors, leaving others unchanged
+func wrapError(err error) error {
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	e := llmerrors.New("openai", apiErr.StatusCode, "", err)
+	if apiErr.Response != nil {
+		e.RequestID = llmerrors.RequestID(apiErr.Response.Header)
+		e.RetryAfter = llmerrors.RetryAfter(apiErr.Response.Header, time.Now())
+	}
+	return e
+}
+
+// accumulateToolCalls merges streamed tool call fragments by index
+func accumulateToolCalls(calls []generator.ToolCall, deltas []openai.ChatCompletionChunkChoiceDeltaToolCall) []generator.ToolCall {
+	for _, d := range deltas {
+		for int(d.Index) >= len(calls) {
+			calls = append(calls, generator.ToolCall{})
+		}
+		call := &calls[d.Index]
+		if d.ID != "" {
+			call.ID = d.ID
+		}
+		call.Name += d.Function.Name
+		call.Arguments += d.Function.Arguments
+	}
+	return calls
+}
+
+func getUsage(u openai.CompletionUsage) generator.TokenUsage {
+	return generator.TokenUsage{
+		PromptTokens:       int(u.PromptTokens),
+		CachedPromptTokens: int(u.PromptTokensDetails.CachedTokens),
+		CompletionTokens:   int(u.CompletionTokens),
+		ReasoningTokens:    int(u.CompletionTokensDetails.ReasoningTokens),
+		TotalTokens:        int(u.TotalTokens),
+	}
+}
+
+// reasoningContent extracts the non-standard reasoning_content field that
+// OpenAI-compatible reasoning backends (e.g. DeepSeek-R1) return alongside content
+func reasoningContent(extra map[string]resp.Field) string {
+	field, ok := extra["reasoning_content"]
+	if !ok || !field.IsPresent() {
+		return ""
+	}
+	var reasoning string
+	if err := json.Unmarshal([]byte(field.Raw()), &reasoning); err != nil {
+		return ""
+	}
+	return reasoning
 }
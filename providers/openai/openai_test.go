@@ -0,0 +1,356 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	openai "github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/parikxxit/go-llm/auth"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/keypool"
+	"github.com/parikxxit/go-llm/llmerrors"
+)
+
+func TestOpenAI_GenerateError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req_1")
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error":{"message":"This model's maximum context length is 8192 tokens.","type":"invalid_request_error","code":"context_length_exceeded"}}`)
+	}))
+	defer srv.Close()
+
+	o := &OpenAI{Client: openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))}
+	_, err := o.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+	if !errors.Is(err, llmerrors.ErrContextLengthExceeded) {
+		t.Fatalf("Generate() error = %v, want ErrContextLengthExceeded", err)
+	}
+	var e *llmerrors.Error
+	if !errors.As(err, &e) || e.StatusCode != 400 || e.RequestID != "req_1" {
+		t.Errorf("Generate() error = %+v", e)
+	}
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		t.Error("Generate() error does not unwrap to *openai.Error")
+	}
+}
+
+func TestNewOpenAI_Config(t *testing.T) {
+	var auth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("OpenAI-Organization") != "org-1" || r.Header.Get("X-Proxied") != "yes" {
+			t.Errorf("request to %s with headers %v", r.URL.Path, r.Header)
+		}
+		if r.Header.Get("Authorization") == "Bearer first" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`)
+	}))
+	defer srv.Close()
+
+	// The client's transport marks requests that went through it
+	proxied := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		r.Header.Set("X-Proxied", "yes")
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	pool, _ := keypool.New([]string{"first", "second"})
+	o := NewOpenAI(generator.Config{
+		Model:      "m",
+		BaseURL:    srv.URL + "/v1",
+		Headers:    map[string]string{"OpenAI-Organization": "org-1"},
+		HTTPClient: proxied,
+		Timeout:    time.Second,
+	}, WithKeyPool(pool))
+
+	resp, err := o.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+	if err != nil || resp.Content != "hi" {
+		t.Fatalf("Generate() = %+v, %v", resp, err)
+	}
+	if len(auth) != 2 || auth[1] != "Bearer second" {
+		t.Errorf("requests authorized with %q, want first then second key", auth)
+	}
+}
+
+func TestNewOpenAI_Auth(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`)
+	}))
+	defer srv.Close()
+
+	o := NewOpenAI(generator.Config{
+		ApiKey:  "ignored",
+		Model:   "m",
+		BaseURL: srv.URL,
+		Auth: auth.RefreshingBearer(func(context.Context) (auth.Token, error) {
+			return auth.Token{Value: "azure-ad", Expiry: time.Now().Add(time.Hour)}, nil
+		}),
+	})
+	if _, err := o.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got != "Bearer azure-ad" {
+		t.Errorf("Authorization = %q, want the token of Auth", got)
+	}
+}
+
+func TestOpenAI_CheckHealth(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/models/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":{"message":"The model does not exist","type":"invalid_request_error","code":"model_not_found"}}`)
+			return
+		}
+		io.WriteString(w, `{"object":"list","data":[{"id":"m","object":"model","created":1,"owned_by":"openai"}]}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	if err := NewOpenAI(generator.Config{Model: "m", BaseURL: srv.URL}).CheckHealth(ctx); err != nil {
+		t.Errorf("CheckHealth() error = %v", err)
+	}
+	if err := NewOpenAI(generator.Config{BaseURL: srv.URL}).CheckHealth(ctx); err != nil {
+		t.Errorf("CheckHealth(no model) error = %v", err)
+	}
+	var e *llmerrors.Error
+	if err := NewOpenAI(generator.Config{Model: "missing", BaseURL: srv.URL}).CheckHealth(ctx); !errors.As(err, &e) || e.StatusCode != http.StatusNotFound {
+		t.Errorf("CheckHealth(missing) error = %v, want a 404 *llmerrors.Error", err)
+	}
+	if len(paths) != 3 || paths[0] != "/models/m" || paths[1] != "/models" {
+		t.Errorf("requested %q", paths)
+	}
+}
+
+func TestOpenAI_ListModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"object":"list","data":[{"id":"gpt-4o","object":"model","created":1715367049,"owned_by":"system"},{"id":"gpt-4o-mini","object":"model","created":1721172741,"owned_by":"system"}]}`)
+	}))
+	defer srv.Close()
+
+	models, err := NewOpenAI(generator.Config{BaseURL: srv.URL}).ListModels(context.Background())
+	if err != nil || len(models) != 2 {
+		t.Fatalf("ListModels() = %v, %v", models, err)
+	}
+	if m := models[0]; m.ID != "gpt-4o" || m.OwnedBy != "system" || m.Created.Unix() != 1715367049 {
+		t.Errorf("ListModels()[0] = %+v", m)
+	}
+}
+
+func TestOpenAI_RawCapture(t *testing.T) {
+	const completion = `{"id":"1","object":"chat.completion","model":"m","system_fingerprint":"fp_1","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`
+	const chunk = `{"id":"1","object":"chat.completion.chunk","model":"m","system_fingerprint":"fp_1","choices":[{"index":0,"delta":{"content":"hi"}}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"stream":true`) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "data: "+chunk+"\n\ndata: [DONE]\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, completion)
+	}))
+	defer srv.Close()
+
+	o := &OpenAI{Client: openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))}
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+	if resp, err := o.Generate(context.Background(), req); err != nil || resp.Raw != nil {
+		t.Errorf("Generate() = %+v, %v, want no Raw without capture", resp, err)
+	}
+
+	ctx := generator.WithRawCapture(context.Background())
+	resp, err := o.Generate(ctx, req)
+	if err != nil || string(resp.Raw) != completion {
+		t.Errorf("Generate() Raw = %s, %v, want the response body", resp.Raw, err)
+	}
+	stream, err := o.GenerateStream(ctx, req)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	for c := range stream {
+		if string(c.Raw) != chunk {
+			t.Errorf("chunk Raw = %s, want the chunk payload", c.Raw)
+		}
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestOpenAI_IdempotencyKey(t *testing.T) {
+	var key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.Header.Get("Idempotency-Key")
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`)
+	}))
+	defer srv.Close()
+
+	o := &OpenAI{Client: openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))}
+	_, err := o.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}, IdempotencyKey: "k1"})
+	if err != nil || key != "k1" {
+		t.Errorf("Generate() = %v, sent Idempotency-Key %q, want k1", err, key)
+	}
+}
+
+func TestOpenAI_GenerateStreamError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		io.WriteString(w, "data: {\"error\":{\"message\":\"The server had an error\",\"type\":\"server_error\"}}\n\n")
+	}))
+	defer srv.Close()
+
+	o := &OpenAI{Client: openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))}
+	stream, err := o.GenerateStream(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	var chunks []*generator.Response
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) != 2 || chunks[0].Content != "Hel" || chunks[1].Err == nil {
+		t.Errorf("stream = %+v, want a content chunk then an error chunk", chunks)
+	}
+}
+
+func TestOpenAI_Grammar(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"42"},"finish_reason":"stop"}]}`)
+	}))
+	defer srv.Close()
+
+	schema := map[string]interface{}{"type": "object"}
+	tests := []struct {
+		name    string
+		dialect GrammarDialect
+		grammar generator.Grammar
+		field   string
+		want    any
+	}{
+		{"openai schema", DialectOpenAI, generator.Grammar{Kind: generator.GrammarJSONSchema, Schema: schema}, "response_format",
+			map[string]any{"type": "json_schema", "json_schema": map[string]any{"name": "response", "schema": map[string]any{"type": "object"}, "strict": true}}},
+		{"llama.cpp gbnf", DialectLlamaCpp, generator.Grammar{Kind: generator.GrammarGBNF, Definition: `root ::= [0-9]+`}, "grammar", `root ::= [0-9]+`},
+		{"llama.cpp schema", DialectLlamaCpp, generator.Grammar{Kind: generator.GrammarJSONSchema, Schema: schema}, "json_schema", map[string]any{"type": "object"}},
+		{"vllm regex", DialectVLLM, generator.Grammar{Kind: generator.GrammarRegex, Definition: `[0-9]+`}, "guided_regex", `[0-9]+`},
+		{"vllm schema", DialectVLLM, generator.Grammar{Kind: generator.GrammarJSONSchema, Schema: schema}, "guided_json", map[string]any{"type": "object"}},
+	}
+	for _, tt := range tests {
+		o := NewOpenAI(generator.Config{ApiKey: "test", BaseURL: srv.URL}, WithGrammarDialect(tt.dialect))
+		req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "a number"}}, Grammar: &tt.grammar}
+		if _, err := o.Generate(context.Background(), req); err != nil {
+			t.Fatalf("%s: Generate() error = %v", tt.name, err)
+		}
+		if got := body[tt.field]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: sent %s = %v, want %v", tt.name, tt.field, got, tt.want)
+		}
+	}
+
+	o := NewOpenAI(generator.Config{ApiKey: "test", BaseURL: srv.URL})
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "a number"}}, Grammar: &generator.Grammar{Kind: generator.GrammarGBNF, Definition: `root ::= "a"`}}
+	if _, err := o.Generate(context.Background(), req); err == nil {
+		t.Error("Generate() of a GBNF grammar to OpenAI error = nil, want an error")
+	}
+}
+
+func BenchmarkOpenAI_GenerateStream(b *testing.B) {
+	var events strings.Builder
+	for i := 0; i < 100; i++ {
+		events.WriteString(`data: {"id":"1","object":"chat.completion.chunk","model":"m","choices":[{"index":0,"delta":{"content":"token "}}]}` + "\n\n")
+	}
+	events.WriteString(`data: {"id":"1","object":"chat.completion.chunk","model":"m","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":100,"total_tokens":110}}` + "\n\ndata: [DONE]\n\n")
+	body := events.String()
+	client := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"text/event-stream"}}, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})}
+	o := &OpenAI{Client: openai.NewClient(option.WithHTTPClient(client), option.WithAPIKey("test"), option.WithMaxRetries(0))}
+	messages := make([]generator.Message, 0, 20)
+	for i := 0; i < 10; i++ {
+		messages = append(messages, generator.Message{Role: generator.USER, Content: "question"}, generator.Message{Role: generator.ASSISTANT, Content: "answer"})
+	}
+	req := &generator.Request{Messages: messages}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		stream, err := o.GenerateStream(context.Background(), req)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for chunk := range stream {
+			generator.ReleaseChunk(chunk)
+		}
+	}
+}
+
+func TestOpenAI_RequestParams(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`)
+	}))
+	defer srv.Close()
+
+	messages := []generator.Message{{Role: generator.USER, Content: "hi"}}
+	o := NewOpenAI(generator.Config{ApiKey: "test", BaseURL: srv.URL, Model: "gpt-4o"})
+	req := &generator.Request{Model: "gpt-4o-mini", Messages: messages, MaxTokens: 64, Temperature: 0.5, TopP: 0.9, Stop: []string{"\n\n"}, User: "u1"}
+	if _, err := o.Generate(context.Background(), req); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := map[string]any{"model": "gpt-4o-mini", "max_completion_tokens": 64.0, "temperature": 0.5, "top_p": 0.9, "stop": []any{"\n\n"}, "user": "u1"}
+	for k, v := range want {
+		if !reflect.DeepEqual(body[k], v) {
+			t.Errorf("sent %s = %v, want %v", k, body[k], v)
+		}
+	}
+	if _, ok := body["max_tokens"]; ok {
+		t.Errorf("sent max_tokens = %v, want none", body["max_tokens"])
+	}
+
+	if _, err := o.Generate(context.Background(), &generator.Request{Messages: messages}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if body["model"] != "gpt-4o" {
+		t.Errorf("sent model = %v, want gpt-4o", body["model"])
+	}
+	for _, k := range []string{"max_completion_tokens", "temperature", "top_p", "stop", "user"} {
+		if _, ok := body[k]; ok {
+			t.Errorf("sent %s = %v, want none", k, body[k])
+		}
+	}
+
+	o = NewOpenAI(generator.Config{ApiKey: "test", BaseURL: srv.URL, Model: "m"}, WithGrammarDialect(DialectVLLM))
+	stream, err := o.GenerateStream(context.Background(), &generator.Request{Messages: messages, MaxTokens: 32})
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	for range stream {
+	}
+	if body["max_tokens"] != 32.0 {
+		t.Errorf("sent max_tokens = %v, want 32", body["max_tokens"])
+	}
+}
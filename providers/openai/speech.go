@@ -0,0 +1,49 @@
+package openai
+
+import (
+	"context"
+
+	"github.com/openai/openai-go"
+	"github.com/parikxxit/go-llm/tts"
+)
+
+const (
+	defaultSpeechModel = openai.SpeechModelGPT4oMiniTTS
+	defaultVoice       = "alloy"
+)
+
+// Synthesize streams speech for the request text
+func (o *OpenAI) Synthesize(ctx context.Context, req *tts.Request) (*tts.Response, error) {
+	model, voice, format := req.Model, req.Voice, req.Format
+	if model == "" {
+		model = defaultSpeechModel
+	}
+	if voice == "" {
+		voice = defaultVoice
+	}
+	if format == "" {
+		format = tts.FormatMP3
+	}
+	params := openai.AudioSpeechNewParams{
+		Input:          req.Text,
+		Model:          model,
+		Voice:          openai.AudioSpeechNewParamsVoice(voice),
+		ResponseFormat: openai.AudioSpeechNewParamsResponseFormat(format),
+	}
+	if req.Speed != 0 {
+		params.Speed = openai.Float(req.Speed)
+	}
+	if req.Instructions != "" {
+		params.Instructions = openai.String(req.Instructions)
+	}
+
+	res, err := o.Client.Audio.Speech.New(ctx, params)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &tts.Response{Audio: res.Body, Format: format}, nil
+}
+
+func (o *OpenAI) GetSynthesizerName() string {
+	return "openai"
+}
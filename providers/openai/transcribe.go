@@ -0,0 +1,92 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/openai/openai-go"
+	"github.com/parikxxit/go-llm/stt"
+)
+
+const defaultTranscriptionModel = openai.AudioModelGPT4oTranscribe
+
+// Transcribe transcribes the request audio. Timestamps, language detection
+// and duration are only reported by whisper-1, which is the default model
+// when timestamps are requested.
+func (o *OpenAI) Transcribe(ctx context.Context, req *stt.Request) (*stt.Response, error) {
+	model := req.Model
+	if model == "" {
+		model = defaultTranscriptionModel
+		if len(req.Timestamps) > 0 {
+			model = openai.AudioModelWhisper1
+		}
+	}
+	verbose := model == openai.AudioModelWhisper1
+	if len(req.Timestamps) > 0 && !verbose {
+		return nil, fmt.Errorf("model %s does not return timestamps", model)
+	}
+
+	filename := req.Filename
+	if filename == "" {
+		filename = "audio.mp3"
+	}
+	params := openai.AudioTranscriptionNewParams{
+		File:  openai.File(req.Audio, filename, req.MediaType),
+		Model: model,
+	}
+	if req.Language != "" {
+		params.Language = openai.String(req.Language)
+	}
+	if req.Prompt != "" {
+		params.Prompt = openai.String(req.Prompt)
+	}
+	if verbose {
+		params.ResponseFormat = openai.AudioResponseFormatVerboseJSON
+		for _, g := range req.Timestamps {
+			params.TimestampGranularities = append(params.TimestampGranularities, string(g))
+		}
+	}
+
+	t, err := o.Client.Audio.Transcriptions.New(ctx, params)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	resp := &stt.Response{Model: model, Text: t.Text, Language: req.Language}
+	if !verbose {
+		return resp, nil
+	}
+	// The SDK models the plain transcription; verbose fields stay in the raw JSON
+	var v struct {
+		Language string  `json:"language"`
+		Duration float64 `json:"duration"`
+		Segments []struct {
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+			Text  string  `json:"text"`
+		} `json:"segments"`
+		Words []struct {
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+			Word  string  `json:"word"`
+		} `json:"words"`
+	}
+	if err := json.Unmarshal([]byte(t.RawJSON()), &v); err != nil {
+		return nil, fmt.Errorf("decoding transcription: %w", err)
+	}
+	resp.Language, resp.Duration = v.Language, stt.Seconds(v.Duration)
+	if req.Wants(stt.GranularitySegment) {
+		for _, s := range v.Segments {
+			resp.Segments = append(resp.Segments, stt.Segment{Start: stt.Seconds(s.Start), End: stt.Seconds(s.End), Text: s.Text})
+		}
+	}
+	for _, w := range v.Words {
+		resp.Words = append(resp.Words, stt.Word{Start: stt.Seconds(w.Start), End: stt.Seconds(w.End), Text: w.Word})
+	}
+	return resp, nil
+}
+
+func (o *OpenAI) GetTranscriberName() string {
+	return "openai"
+}
@@ -0,0 +1,50 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	openai "github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/parikxxit/go-llm/stt"
+)
+
+func TestOpenAI_Transcribe(t *testing.T) {
+	var form map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		form = r.MultipartForm.Value
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"text":"hi there","language":"english","duration":2.5,
+			"segments":[{"start":0,"end":2.5,"text":"hi there"}],
+			"words":[{"start":0,"end":0.5,"word":"hi"},{"start":0.5,"end":1,"word":"there"}]}`)
+	}))
+	defer srv.Close()
+
+	o := &OpenAI{Client: openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("key"))}
+	resp, err := o.Transcribe(context.Background(), &stt.Request{
+		Audio:      strings.NewReader("audio"),
+		Timestamps: []stt.Granularity{stt.GranularityWord},
+	})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if form["model"][0] != "whisper-1" || form["response_format"][0] != "verbose_json" {
+		t.Errorf("form = %v, want whisper-1 with verbose_json", form)
+	}
+	if resp.Text != "hi there" || resp.Language != "english" || resp.Duration != 2500*time.Millisecond {
+		t.Errorf("Transcribe() = %+v", resp)
+	}
+	if len(resp.Words) != 2 || len(resp.Segments) != 0 {
+		t.Errorf("Transcribe() words = %+v, segments = %+v; want words only", resp.Words, resp.Segments)
+	}
+
+	if _, err := o.Transcribe(context.Background(), &stt.Request{Model: "gpt-4o-transcribe", Timestamps: []stt.Granularity{stt.GranularityWord}}); err == nil {
+		t.Error("Transcribe() of timestamps from gpt-4o-transcribe error = nil")
+	}
+}
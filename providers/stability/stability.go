@@ -0,0 +1,199 @@
+// Package stability provides an imagegen.ImageGenerator backed by the
+// Stability AI Stable Image API.
+package stability
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/parikxxit/go-llm/imagegen"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/transport"
+)
+
+const (
+	defaultBaseURL = "https://api.stability.ai"
+	defaultModel   = "core"
+)
+
+// aspectRatios are the ratios the API accepts, keyed by their value
+var aspectRatios = map[string]float64{
+	"21:9": 21.0 / 9, "16:9": 16.0 / 9, "3:2": 3.0 / 2, "5:4": 5.0 / 4, "1:1": 1,
+	"4:5": 4.0 / 5, "2:3": 2.0 / 3, "9:16": 9.0 / 16, "9:21": 9.0 / 21,
+}
+
+// Stability generates images with Stable Image Core, Ultra or SD3 models.
+// Request.Model is "core", "ultra", or an SD3 model such as "sd3.5-large".
+type Stability struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Option is a function that configures a Stability client
+type Option func(*Stability)
+
+// WithBaseURL sets the API URL, https://api.stability.ai by default
+func WithBaseURL(url string) Option {
+	return func(s *Stability) {
+		s.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client, transport.DefaultClient by default
+func WithHTTPClient(c *http.Client) Option {
+	return func(s *Stability) {
+		s.httpClient = c
+	}
+}
+
+// New creates a new Stability client
+func New(apiKey string, opts ...Option) *Stability {
+	s := &Stability{apiKey: apiKey, baseURL: defaultBaseURL, httpClient: transport.DefaultClient()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GenerateImage creates req.N images, one API call each, returned as bytes.
+// Size is mapped to the closest supported aspect ratio.
+func (s *Stability) GenerateImage(ctx context.Context, req *imagegen.Request) (*imagegen.Response, error) {
+	if req.Format == imagegen.FormatURL {
+		return nil, fmt.Errorf("stability: images are only returned as bytes")
+	}
+	model := req.Model
+	if model == "" {
+		model = defaultModel
+	}
+	endpoint := model
+	if strings.HasPrefix(model, "sd3") {
+		endpoint = "sd3"
+	}
+
+	fields := map[string]string{"prompt": req.Prompt, "output_format": "png"}
+	if endpoint == "sd3" {
+		fields["model"] = model
+	}
+	if req.NegativePrompt != "" {
+		fields["negative_prompt"] = req.NegativePrompt
+	}
+	if req.Seed != 0 {
+		fields["seed"] = strconv.FormatInt(req.Seed, 10)
+	}
+	if req.Style != "" {
+		fields["style_preset"] = req.Style
+	}
+	if req.Size != "" {
+		ratio, err := aspectRatio(req.Size)
+		if err != nil {
+			return nil, err
+		}
+		fields["aspect_ratio"] = ratio
+	}
+	for k, v := range req.ProviderParams {
+		fields[k] = fmt.Sprint(v)
+	}
+
+	n := max(req.N, 1)
+	resp := &imagegen.Response{Model: model, Images: make([]imagegen.Image, 0, n)}
+	for i := 0; i < n; i++ {
+		img, err := s.generate(ctx, endpoint, fields)
+		if err != nil {
+			return nil, err
+		}
+		resp.Images = append(resp.Images, img)
+	}
+	return resp, nil
+}
+
+func (s *Stability) generate(ctx context.Context, endpoint string, fields map[string]string) (imagegen.Image, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			return imagegen.Image{}, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return imagegen.Image{}, err
+	}
+
+	url := s.baseURL + "/v2beta/stable-image/generate/" + endpoint
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return imagegen.Image{}, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+	httpReq.Header.Set("Content-Type", w.FormDataContentType())
+	httpReq.Header.Set("Accept", "application/json")
+
+	res, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return imagegen.Image{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return imagegen.Image{}, llmerrors.FromResponse("stability", res)
+	}
+
+	var out struct {
+		Image        string `json:"image"`
+		FinishReason string `json:"finish_reason"`
+		Seed         int64  `json:"seed"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return imagegen.Image{}, fmt.Errorf("stability: decoding response: %w", err)
+	}
+	if out.FinishReason == "CONTENT_FILTERED" {
+		return imagegen.Image{}, &llmerrors.Error{
+			Kind:       llmerrors.ErrContentFiltered,
+			Provider:   "stability",
+			StatusCode: res.StatusCode,
+			RequestID:  llmerrors.RequestID(res.Header),
+			Err:        errors.New("image was content filtered"),
+		}
+	}
+	data, err := base64.StdEncoding.DecodeString(out.Image)
+	if err != nil {
+		return imagegen.Image{}, fmt.Errorf("stability: decoding image: %w", err)
+	}
+	return imagegen.Image{Data: data, MediaType: "image/png", Seed: out.Seed}, nil
+}
+
+func (s *Stability) GetImageGeneratorName() string {
+	return "stability"
+}
+
+// aspectRatio returns the supported aspect ratio closest to a WIDTHxHEIGHT
+// size; ratios such as "16:9" are passed through
+func aspectRatio(size string) (string, error) {
+	if _, ok := aspectRatios[size]; ok {
+		return size, nil
+	}
+	w, h, ok := strings.Cut(size, "x")
+	width, werr := strconv.ParseFloat(w, 64)
+	height, herr := strconv.ParseFloat(h, 64)
+	if !ok || werr != nil || herr != nil || width <= 0 || height <= 0 {
+		return "", fmt.Errorf("stability: invalid size %q", size)
+	}
+	best, bestDiff := "1:1", -1.0
+	for ratio, value := range aspectRatios {
+		diff := value - width/height
+		if diff < 0 {
+			diff = -diff
+		}
+		if bestDiff < 0 || diff < bestDiff || (diff == bestDiff && ratio < best) {
+			best, bestDiff = ratio, diff
+		}
+	}
+	return best, nil
+}
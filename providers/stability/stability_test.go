@@ -0,0 +1,38 @@
+package stability
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/parikxxit/go-llm/imagegen"
+)
+
+func TestStability_GenerateImage(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	var paths, ratios []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		paths = append(paths, r.URL.Path)
+		ratios = append(ratios, r.FormValue("aspect_ratio"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"image":"` + base64.StdEncoding.EncodeToString(png) + `","finish_reason":"SUCCESS","seed":7}`))
+	}))
+	defer srv.Close()
+
+	s := New("key", WithBaseURL(srv.URL))
+	resp, err := s.GenerateImage(context.Background(), &imagegen.Request{Model: "sd3.5-large", Prompt: "a fox", N: 2, Size: "1792x1024"})
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+	if len(resp.Images) != 2 || string(resp.Images[0].Data) != string(png) || resp.Images[0].Seed != 7 {
+		t.Errorf("GenerateImage() = %+v", resp)
+	}
+	if paths[0] != "/v2beta/stable-image/generate/sd3" || ratios[0] != "16:9" {
+		t.Errorf("request = %s with aspect ratio %s", paths[0], ratios[0])
+	}
+}
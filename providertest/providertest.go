@@ -0,0 +1,383 @@
+// Package providertest provides conformance suites that generator, embedder
+// and reranker implementations are expected to pass, so third-party
+// providers can check they behave like the builtin ones: streaming,
+// cancellation, error mapping to llmerrors and usage reporting.
+//
+// The suites send real requests through New, e.g. replaying a vcr cassette.
+// Error mapping and cancellation are tested against servers of the suite,
+// through NewWithBaseURL; they are skipped when it is nil.
+package providertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/reranker"
+)
+
+// cancelWithin is how long a call may run after its context is cancelled
+const cancelWithin = 2 * time.Second
+
+// GeneratorConfig configures RunGeneratorTests
+type GeneratorConfig struct {
+	// New returns the generator tested; it is called once per test
+	New func(t *testing.T) generator.Generator
+	// NewWithBaseURL returns the generator sending its requests to baseURL,
+	// with its own retries disabled
+	NewWithBaseURL func(t *testing.T, baseURL string) generator.Generator
+	// Model is set in requests when not empty
+	Model string
+}
+
+// RunGeneratorTests runs the generator suite as subtests of t. Responses
+// must have content and usage, streams must carry usage on a chunk and end
+// with an Err chunk on failures after they started.
+func RunGeneratorTests(t *testing.T, cfg GeneratorConfig) {
+	request := func() *generator.Request {
+		return &generator.Request{Model: cfg.Model, MaxTokens: 16, Messages: []generator.Message{{Role: generator.USER, Content: "Reply with the single word: pong"}}}
+	}
+
+	t.Run("Generate", func(t *testing.T) {
+		resp, err := cfg.New(t).Generate(context.Background(), request())
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if resp.Content == "" && len(resp.ToolCalls) == 0 {
+			t.Error("Generate() content is empty")
+		}
+		checkUsage(t, "Generate()", resp.Usage.PromptTokens, resp.Usage.CompletionTokens, resp.Usage.TotalTokens)
+	})
+
+	t.Run("GenerateStream", func(t *testing.T) {
+		stream, err := cfg.New(t).GenerateStream(context.Background(), request())
+		if err != nil {
+			t.Fatalf("GenerateStream() error = %v", err)
+		}
+		var content string
+		var usage generator.TokenUsage
+		for chunk := range stream {
+			if chunk.Err != nil {
+				t.Fatalf("stream chunk error = %v", chunk.Err)
+			}
+			content += chunk.Content
+			if chunk.Usage != (generator.TokenUsage{}) {
+				usage = chunk.Usage
+			}
+		}
+		if content == "" {
+			t.Error("GenerateStream() streamed no content")
+		}
+		checkUsage(t, "GenerateStream()", usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := cfg.New(t).Generate(ctx, request()); !errors.Is(err, context.Canceled) {
+			t.Errorf("Generate() error = %v with a cancelled context, want context.Canceled", err)
+		}
+	})
+
+	if cfg.NewWithBaseURL == nil {
+		return
+	}
+	t.Run("Cancellation", func(t *testing.T) {
+		g := cfg.NewWithBaseURL(t, hangingServer(t))
+		checkCancelled(t, "Generate()", func(ctx context.Context) error {
+			_, err := g.Generate(ctx, request())
+			return err
+		})
+		checkCancelled(t, "GenerateStream()", func(ctx context.Context) error {
+			stream, err := g.GenerateStream(ctx, request())
+			if err != nil {
+				return err
+			}
+			for chunk := range stream {
+				if chunk.Err != nil {
+					err = chunk.Err
+				}
+			}
+			if err == nil {
+				return errors.New("stream closed without an error")
+			}
+			return err
+		})
+	})
+	t.Run("ErrorMapping", func(t *testing.T) {
+		checkErrors(t, func(baseURL string) func(ctx context.Context) error {
+			g := cfg.NewWithBaseURL(t, baseURL)
+			return func(ctx context.Context) error {
+				_, err := g.Generate(ctx, request())
+				return err
+			}
+		})
+		checkErrors(t, func(baseURL string) func(ctx context.Context) error {
+			g := cfg.NewWithBaseURL(t, baseURL)
+			return func(ctx context.Context) error {
+				stream, err := g.GenerateStream(ctx, request())
+				if err != nil {
+					return err
+				}
+				for chunk := range stream {
+					if chunk.Err != nil {
+						err = chunk.Err
+					}
+				}
+				return err
+			}
+		})
+	})
+}
+
+// EmbedderConfig configures RunEmbedderTests
+type EmbedderConfig struct {
+	// New returns the embedder tested; it is called once per test
+	New func(t *testing.T) embedder.Embedder
+	// NewWithBaseURL returns the embedder sending its requests to baseURL,
+	// with its own retries disabled
+	NewWithBaseURL func(t *testing.T, baseURL string) embedder.Embedder
+	// Model is set in requests when not empty
+	Model string
+}
+
+// RunEmbedderTests runs the embedder suite as subtests of t. Responses must
+// hold an embedding of the same dimensions per input, indexed by input, and
+// their usage.
+func RunEmbedderTests(t *testing.T, cfg EmbedderConfig) {
+	request := func() *embedder.Request {
+		return &embedder.Request{Model: cfg.Model, Input: []string{"cats purr", "dogs bark", "fish swim"}}
+	}
+
+	t.Run("Embed", func(t *testing.T) {
+		resp, err := cfg.New(t).Embed(context.Background(), request())
+		if err != nil {
+			t.Fatalf("Embed() error = %v", err)
+		}
+		if len(resp.Data) != 3 {
+			t.Fatalf("Embed() returned %d embeddings for 3 inputs", len(resp.Data))
+		}
+		seen, dims := map[int]bool{}, -1
+		for _, d := range resp.Data {
+			n := max(len(d.Embedding), len(d.Embedding32))
+			if n == 0 || (dims >= 0 && n != dims) {
+				t.Errorf("Embed() embedding %d has %d dimensions, want the same non-zero number for all", d.Index, n)
+			}
+			dims = n
+			if d.Index < 0 || d.Index > 2 || seen[d.Index] {
+				t.Errorf("Embed() embedding index %d invalid or repeated", d.Index)
+			}
+			seen[d.Index] = true
+		}
+		if resp.Usage.PromptTokens <= 0 {
+			t.Errorf("Embed() usage = %+v, want prompt tokens", resp.Usage)
+		}
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := cfg.New(t).Embed(ctx, request()); !errors.Is(err, context.Canceled) {
+			t.Errorf("Embed() error = %v with a cancelled context, want context.Canceled", err)
+		}
+	})
+
+	if cfg.NewWithBaseURL == nil {
+		return
+	}
+	t.Run("Cancellation", func(t *testing.T) {
+		e := cfg.NewWithBaseURL(t, hangingServer(t))
+		checkCancelled(t, "Embed()", func(ctx context.Context) error {
+			_, err := e.Embed(ctx, request())
+			return err
+		})
+	})
+	t.Run("ErrorMapping", func(t *testing.T) {
+		checkErrors(t, func(baseURL string) func(ctx context.Context) error {
+			e := cfg.NewWithBaseURL(t, baseURL)
+			return func(ctx context.Context) error {
+				_, err := e.Embed(ctx, request())
+				return err
+			}
+		})
+	})
+}
+
+// RerankerConfig configures RunRerankerTests
+type RerankerConfig struct {
+	// New returns the reranker tested; it is called once per test
+	New func(t *testing.T) reranker.Reranker
+	// NewWithBaseURL returns the reranker sending its requests to baseURL,
+	// with its own retries disabled
+	NewWithBaseURL func(t *testing.T, baseURL string) reranker.Reranker
+	// Model is set in requests when not empty
+	Model string
+}
+
+// RunRerankerTests runs the reranker suite as subtests of t. Results must
+// be sorted most relevant first, rank the obviously relevant document first
+// and honor TopN.
+func RunRerankerTests(t *testing.T, cfg RerankerConfig) {
+	request := func() *reranker.Request {
+		return &reranker.Request{Model: cfg.Model, Query: "What is the capital of France?", Documents: []reranker.Document{
+			{Text: "Bananas are rich in potassium."},
+			{Text: "Paris is the capital and largest city of France."},
+			{Text: "The Rhine flows through Germany."},
+		}}
+	}
+
+	t.Run("Rerank", func(t *testing.T) {
+		resp, err := cfg.New(t).Rerank(context.Background(), request())
+		if err != nil {
+			t.Fatalf("Rerank() error = %v", err)
+		}
+		if len(resp.Results) != 3 {
+			t.Fatalf("Rerank() returned %d results for 3 documents", len(resp.Results))
+		}
+		seen := map[int]bool{}
+		for i, r := range resp.Results {
+			if r.Index < 0 || r.Index > 2 || seen[r.Index] {
+				t.Errorf("Rerank() result index %d invalid or repeated", r.Index)
+			}
+			seen[r.Index] = true
+			if i > 0 && r.RelevanceScore > resp.Results[i-1].RelevanceScore {
+				t.Errorf("Rerank() results not sorted by decreasing score: %+v", resp.Results)
+			}
+		}
+		if resp.Results[0].Index != 1 {
+			t.Errorf("Rerank() ranked document %d first, want 1", resp.Results[0].Index)
+		}
+	})
+
+	t.Run("TopN", func(t *testing.T) {
+		req := request()
+		req.TopN = 2
+		resp, err := cfg.New(t).Rerank(context.Background(), req)
+		if err != nil || len(resp.Results) != 2 {
+			t.Errorf("Rerank(TopN 2) = %v, %v, want 2 results", resp, err)
+		}
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := cfg.New(t).Rerank(ctx, request()); !errors.Is(err, context.Canceled) {
+			t.Errorf("Rerank() error = %v with a cancelled context, want context.Canceled", err)
+		}
+	})
+
+	if cfg.NewWithBaseURL == nil {
+		return
+	}
+	t.Run("Cancellation", func(t *testing.T) {
+		r := cfg.NewWithBaseURL(t, hangingServer(t))
+		checkCancelled(t, "Rerank()", func(ctx context.Context) error {
+			_, err := r.Rerank(ctx, request())
+			return err
+		})
+	})
+	t.Run("ErrorMapping", func(t *testing.T) {
+		checkErrors(t, func(baseURL string) func(ctx context.Context) error {
+			r := cfg.NewWithBaseURL(t, baseURL)
+			return func(ctx context.Context) error {
+				_, err := r.Rerank(ctx, request())
+				return err
+			}
+		})
+	})
+}
+
+func checkUsage(t *testing.T, call string, prompt, completion, total int) {
+	t.Helper()
+	if prompt <= 0 || completion <= 0 || total < prompt+completion {
+		t.Errorf("%s usage = %d prompt, %d completion and %d total tokens, want positive counts adding up", call, prompt, completion, total)
+	}
+}
+
+// hangingServer returns the URL of a server sending the headers of its
+// responses, then nothing until the request is cancelled
+func hangingServer(t *testing.T) string {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	t.Cleanup(func() {
+		close(done)
+		srv.Close()
+	})
+	return srv.URL
+}
+
+// checkCancelled checks call returns context.Canceled soon after its
+// context is cancelled
+func checkCancelled(t *testing.T, name string, call func(ctx context.Context) error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	errc := make(chan error, 1)
+	go func() { errc <- call(ctx) }()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("%s error = %v once cancelled, want context.Canceled", name, err)
+		}
+	case <-time.After(cancelWithin):
+		t.Errorf("%s still running %v after its context was cancelled", name, cancelWithin)
+	}
+}
+
+// checkErrors checks the calls of newCall against servers failing with
+// various statuses return the matching llmerrors
+func checkErrors(t *testing.T, newCall func(baseURL string) func(ctx context.Context) error) {
+	t.Helper()
+	tests := []struct {
+		status     int
+		kind       error
+		retryAfter time.Duration
+	}{
+		{http.StatusUnauthorized, llmerrors.ErrAuth, 0},
+		{http.StatusTooManyRequests, llmerrors.ErrRateLimited, 7 * time.Second},
+		{http.StatusServiceUnavailable, llmerrors.ErrOverloaded, 0},
+		{http.StatusInternalServerError, nil, 0},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if tt.retryAfter > 0 {
+				w.Header().Set("Retry-After", fmt.Sprint(tt.retryAfter.Seconds()))
+			}
+			w.WriteHeader(tt.status)
+			io.WriteString(w, fmt.Sprintf(`{"error":{"message":"%s","type":"error","code":"%d"}}`, http.StatusText(tt.status), tt.status))
+		}))
+		err := newCall(srv.URL)(context.Background())
+		srv.Close()
+
+		var e *llmerrors.Error
+		switch {
+		case !errors.As(err, &e):
+			t.Errorf("status %d: error = %v, want an *llmerrors.Error", tt.status, err)
+		case e.StatusCode != tt.status:
+			t.Errorf("status %d: error StatusCode = %d", tt.status, e.StatusCode)
+		case tt.kind != nil && !errors.Is(err, tt.kind):
+			t.Errorf("status %d: error = %v, want %v", tt.status, err, tt.kind)
+		case tt.kind == nil && !llmerrors.Retryable(err):
+			t.Errorf("status %d: error = %v, want it retryable", tt.status, err)
+		case e.RetryAfter != tt.retryAfter:
+			t.Errorf("status %d: error RetryAfter = %v, want %v", tt.status, e.RetryAfter, tt.retryAfter)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package providertest_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/providertest"
+	"github.com/parikxxit/go-llm/reranker"
+)
+
+func TestRunGeneratorTests_Mock(t *testing.T) {
+	providertest.RunGeneratorTests(t, providertest.GeneratorConfig{
+		New: func(t *testing.T) generator.Generator {
+			m := mock.New()
+			m.GenerateFunc = func(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+				return &generator.Response{Content: "pong", Usage: generator.TokenUsage{PromptTokens: 5, CompletionTokens: 1, TotalTokens: 6}}, nil
+			}
+			return m
+		},
+	})
+}
+
+func TestRunEmbedderTests_Mock(t *testing.T) {
+	providertest.RunEmbedderTests(t, providertest.EmbedderConfig{
+		New: func(t *testing.T) embedder.Embedder {
+			return mock.NewEmbedder(4)
+		},
+	})
+}
+
+// overlapReranker scores documents by the words they share with the query
+type overlapReranker struct{}
+
+func (overlapReranker) Rerank(ctx context.Context, req *reranker.Request) (*reranker.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	query := strings.Fields(strings.ToLower(strings.TrimSuffix(req.Query, "?")))
+	resp := &reranker.Response{Model: req.Model}
+	for i, d := range req.Documents {
+		var score float64
+		for _, w := range strings.Fields(strings.ToLower(strings.TrimSuffix(d.Text, "."))) {
+			for _, q := range query {
+				if w == q {
+					score++
+				}
+			}
+		}
+		resp.Results = append(resp.Results, reranker.Result{Index: i, RelevanceScore: score, Document: d})
+	}
+	sort.SliceStable(resp.Results, func(i, j int) bool {
+		return resp.Results[i].RelevanceScore > resp.Results[j].RelevanceScore
+	})
+	if req.TopN > 0 && req.TopN < len(resp.Results) {
+		resp.Results = resp.Results[:req.TopN]
+	}
+	return resp, nil
+}
+
+func (overlapReranker) GetRerankerName() string {
+	return "overlap"
+}
+
+func TestRunRerankerTests_Overlap(t *testing.T) {
+	providertest.RunRerankerTests(t, providertest.RerankerConfig{
+		New: func(t *testing.T) reranker.Reranker {
+			return overlapReranker{}
+		},
+	})
+}
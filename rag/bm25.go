@@ -0,0 +1,166 @@
+package rag
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// BM25 is an in-memory keyword index ranking documents with Okapi BM25. It
+// complements vector search on queries where exact terms matter, such as
+// names, codes and identifiers. It is safe for concurrent use.
+type BM25 struct {
+	// K1 controls term frequency saturation, 1.2 when zero
+	K1 float64
+	// B controls document length normalization, 0.75 when zero
+	B float64
+	// TopK is the number of documents returned by Retrieve, 5 when zero
+	TopK int
+
+	mu       sync.RWMutex
+	docs     map[string]bm25Doc
+	postings map[string]map[string]int // term -> document ID -> frequency
+	length   int                       // Total terms of all documents
+}
+
+type bm25Doc struct {
+	doc    vectorstore.Document
+	length int
+}
+
+// NewBM25 creates a new empty index with the default parameters
+func NewBM25() *BM25 {
+	return &BM25{}
+}
+
+// Add indexes documents, replacing those with the same ID
+func (x *BM25) Add(docs ...vectorstore.Document) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.docs == nil {
+		x.docs = make(map[string]bm25Doc)
+		x.postings = make(map[string]map[string]int)
+	}
+
+	for _, d := range docs {
+		x.remove(d.ID)
+		terms := Tokenize(d.Text)
+		for _, t := range terms {
+			if x.postings[t] == nil {
+				x.postings[t] = make(map[string]int)
+			}
+			x.postings[t][d.ID]++
+		}
+		d.Embedding = nil
+		x.docs[d.ID] = bm25Doc{doc: d, length: len(terms)}
+		x.length += len(terms)
+	}
+}
+
+// Remove deletes documents by ID; unknown IDs are ignored
+func (x *BM25) Remove(ids ...string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, id := range ids {
+		x.remove(id)
+	}
+}
+
+func (x *BM25) remove(id string) {
+	d, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	for _, t := range Tokenize(d.doc.Text) {
+		delete(x.postings[t], id)
+		if len(x.postings[t]) == 0 {
+			delete(x.postings, t)
+		}
+	}
+	x.length -= d.length
+	delete(x.docs, id)
+}
+
+// Len returns the number of indexed documents
+func (x *BM25) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docs)
+}
+
+// Search returns the topK documents best matching query, or all matching
+// documents when topK <= 0. Documents sharing no term with query are omitted.
+func (x *BM25) Search(query string, topK int) []vectorstore.Match {
+	k1, b := x.K1, x.B
+	if k1 == 0 {
+		k1 = 1.2
+	}
+	if b == 0 {
+		b = 0.75
+	}
+
+	x.mu.RLock()
+	n := float64(len(x.docs))
+	avg := 0.0
+	if n > 0 {
+		avg = float64(x.length) / n
+	}
+	scores := make(map[string]float64)
+	seen := make(map[string]bool)
+	for _, t := range Tokenize(query) {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		postings := x.postings[t]
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range postings {
+			f := float64(tf)
+			norm := 1 - b + b*float64(x.docs[id].length)/avg
+			scores[id] += idf * f * (k1 + 1) / (f + k1*norm)
+		}
+	}
+	matches := make([]vectorstore.Match, 0, len(scores))
+	for id, score := range scores {
+		matches = append(matches, vectorstore.Match{Document: x.docs[id].doc, Score: score})
+	}
+	x.mu.RUnlock()
+
+	sortMatches(matches)
+	if topK > 0 && len(matches) > topK {
+		matches = matches[:topK]
+	}
+	return matches
+}
+
+// Retrieve implements Retriever, returning TopK documents
+func (x *BM25) Retrieve(_ context.Context, query string) ([]vectorstore.Match, error) {
+	topK := x.TopK
+	if topK <= 0 {
+		topK = defaultTopK
+	}
+	return x.Search(query, topK), nil
+}
+
+// Tokenize lower-cases text and splits it into runs of letters and digits
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// sortMatches orders matches by descending score, then by ID for stability
+func sortMatches(matches []vectorstore.Match) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Document.ID < matches[j].Document.ID
+	})
+}
@@ -0,0 +1,75 @@
+package rag
+
+import (
+	"context"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+func TestBM25_Search(t *testing.T) {
+	x := NewBM25()
+	x.Add(
+		vectorstore.Document{ID: "a", Text: "Error E1234 occurs when the disk is full."},
+		vectorstore.Document{ID: "b", Text: "The disk, the disk, the disk: disks everywhere."},
+		vectorstore.Document{ID: "c", Text: "Nothing relevant."},
+	)
+
+	got := x.Search("what is error e1234", 10)
+	if len(got) != 1 || got[0].Document.ID != "a" {
+		t.Errorf("Search() = %v, want [a]", matchIDs(got))
+	}
+	got = x.Search("disk", 10)
+	if len(got) != 2 || got[0].Document.ID != "b" {
+		t.Errorf("Search(disk) = %v, want b first", matchIDs(got))
+	}
+
+	x.Add(vectorstore.Document{ID: "b", Text: "Replaced."})
+	x.Remove("c")
+	if got := x.Search("disk", 10); len(got) != 1 || x.Len() != 2 {
+		t.Errorf("Search() after replace = %v, Len() = %d", matchIDs(got), x.Len())
+	}
+}
+
+func TestFuseRRF(t *testing.T) {
+	doc := func(id string) vectorstore.Match { return vectorstore.Match{Document: vectorstore.Document{ID: id}} }
+	dense := []vectorstore.Match{doc("a"), doc("b"), doc("c")}
+	sparse := []vectorstore.Match{doc("c"), doc("b"), doc("d")}
+
+	got := matchIDs(FuseRRF(0, dense, sparse))
+	// c and b appear in both lists, ahead of a which only dense ranks first
+	if len(got) != 4 || got[0] != "c" || got[1] != "b" || got[2] != "a" {
+		t.Errorf("FuseRRF() = %v, want [c b a d]", got)
+	}
+}
+
+func TestPipeline_Hybrid(t *testing.T) {
+	client := gollm.NewClient(mock.New(), gollm.WithEmbedder(keywords{"disk"}))
+	p := New(client, vectorstore.NewMemoryStore(vectorstore.Cosine), WithKeywordIndex(NewBM25()), WithTopK(2))
+	err := p.Index(context.Background(),
+		vectorstore.Document{ID: "a", Text: "Error E1234 means the disk is full."},
+		vectorstore.Document{ID: "b", Text: "Disk maintenance guide."},
+		vectorstore.Document{ID: "c", Text: "Unrelated."},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := p.Retrieve(context.Background(), "E1234 disk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := matchIDs(got); len(ids) != 2 || ids[0] != "a" {
+		t.Errorf("Retrieve() = %v, want a first", ids)
+	}
+}
+
+func matchIDs(matches []vectorstore.Match) []string {
+	out := make([]string, len(matches))
+	for i, m := range matches {
+		out[i] = m.Document.ID
+	}
+	return out
+}
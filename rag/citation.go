@@ -0,0 +1,44 @@
+package rag
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/parikxxit/go-llm/loaders"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// Citation represents a source cited in an answer
+type Citation struct {
+	// Marker is the number the source was cited with, e.g. 2 for [2]
+	Marker  int
+	ChunkID string
+	// Source is the "source" metadata of the chunk, e.g. its file path
+	Source string
+	Match  vectorstore.Match
+}
+
+// markers matches [1] and grouped forms such as [1, 3]
+var markers = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// Cite maps the citation markers in text to sources, numbered from 1 as in
+// Prompt. Each source is cited once, in order of first citation; markers
+// without a matching source are ignored.
+func Cite(text string, sources []vectorstore.Match) []Citation {
+	var citations []Citation
+	seen := make(map[int]bool)
+	for _, m := range markers.FindAllStringSubmatch(text, -1) {
+		for _, part := range strings.Split(m[1], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n < 1 || n > len(sources) || seen[n] {
+				continue
+			}
+			seen[n] = true
+			s := sources[n-1]
+			source, _ := s.Document.Metadata[loaders.MetadataSource].(string)
+			citations = append(citations, Citation{Marker: n, ChunkID: s.Document.ID, Source: source, Match: s})
+		}
+	}
+	return citations
+}
@@ -0,0 +1,77 @@
+package rag
+
+import (
+	"context"
+	"sync"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// DefaultRRFK is the rank constant of reciprocal rank fusion from the
+// original paper
+const DefaultRRFK = 60
+
+// FuseRRF merges ranked lists with reciprocal rank fusion: each document
+// scores the sum of 1/(k+rank) over the lists it appears in, so documents
+// ranked well by several retrievers rise to the top regardless of how each
+// retriever scales its scores. A k <= 0 uses DefaultRRFK.
+func FuseRRF(k int, lists ...[]vectorstore.Match) []vectorstore.Match {
+	if k <= 0 {
+		k = DefaultRRFK
+	}
+	scores := make(map[string]float64)
+	docs := make(map[string]vectorstore.Document)
+	for _, list := range lists {
+		for rank, m := range list {
+			id := m.Document.ID
+			scores[id] += 1 / float64(k+rank+1)
+			// Keep the first copy seen, which carries the embedding when the
+			// dense list comes first
+			if _, ok := docs[id]; !ok {
+				docs[id] = m.Document
+			}
+		}
+	}
+
+	fused := make([]vectorstore.Match, 0, len(scores))
+	for id, score := range scores {
+		fused = append(fused, vectorstore.Match{Document: docs[id], Score: score})
+	}
+	sortMatches(fused)
+	return fused
+}
+
+// HybridRetriever runs retrievers concurrently and fuses their results with
+// reciprocal rank fusion
+type HybridRetriever struct {
+	Retrievers []Retriever
+	// K is the RRF rank constant, DefaultRRFK when zero
+	K int
+	// TopK bounds the fused results; all are returned when zero
+	TopK int
+}
+
+func (h *HybridRetriever) Retrieve(ctx context.Context, query string) ([]vectorstore.Match, error) {
+	lists := make([][]vectorstore.Match, len(h.Retrievers))
+	errs := make([]error, len(h.Retrievers))
+	var wg sync.WaitGroup
+	for i, r := range h.Retrievers {
+		wg.Add(1)
+		go func(i int, r Retriever) {
+			defer wg.Done()
+			lists[i], errs[i] = r.Retrieve(ctx, query)
+		}(i, r)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fused := FuseRRF(h.K, lists...)
+	if h.TopK > 0 && len(fused) > h.TopK {
+		fused = fused[:h.TopK]
+	}
+	return fused, nil
+}
@@ -0,0 +1,73 @@
+package rag
+
+import (
+	"math"
+
+	"github.com/parikxxit/go-llm/vecmath"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// MMR selects k matches by maximal marginal relevance, trading relevance for
+// diversity: each pick maximizes lambda*relevance - (1-lambda)*similarity to
+// the matches already picked. Lambda 1 keeps the original ranking and lambda
+// 0 maximizes diversity; 0.5 is a common choice.
+//
+// Relevance is Match.Score rescaled to [0, 1], so matches from vector search
+// and reranker output can both be diversified. Similarity is the cosine of
+// document embeddings; documents without one are never considered similar.
+func MMR(matches []vectorstore.Match, k int, lambda float64) []vectorstore.Match {
+	if k <= 0 || k > len(matches) {
+		k = len(matches)
+	}
+	relevance := normalize(matches)
+
+	selected := make([]vectorstore.Match, 0, k)
+	picked := make([]bool, len(matches))
+	// maxSim[i] is the highest similarity of candidate i to a picked match
+	maxSim := make([]float64, len(matches))
+	for len(selected) < k {
+		best, bestScore := -1, math.Inf(-1)
+		for i := range matches {
+			if picked[i] {
+				continue
+			}
+			score := lambda*relevance[i] - (1-lambda)*maxSim[i]
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		picked[best] = true
+		selected = append(selected, matches[best])
+		for i := range matches {
+			if !picked[i] {
+				maxSim[i] = math.Max(maxSim[i], similarity(matches[i].Document.Embedding, matches[best].Document.Embedding))
+			}
+		}
+	}
+	return selected
+}
+
+// normalize rescales scores to [0, 1]; equal scores all become 1
+func normalize(matches []vectorstore.Match) []float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, m := range matches {
+		lo, hi = math.Min(lo, m.Score), math.Max(hi, m.Score)
+	}
+	out := make([]float64, len(matches))
+	for i, m := range matches {
+		if hi > lo {
+			out[i] = (m.Score - lo) / (hi - lo)
+		} else {
+			out[i] = 1
+		}
+	}
+	return out
+}
+
+// similarity is the cosine of a and b, or 0 when either is missing
+func similarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	return float64(vecmath.CosineSimilarity(a, b))
+}
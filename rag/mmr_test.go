@@ -0,0 +1,25 @@
+package rag
+
+import (
+	"testing"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+func TestMMR(t *testing.T) {
+	matches := []vectorstore.Match{
+		{Document: vectorstore.Document{ID: "a", Embedding: []float32{1, 0}}, Score: 0.95},
+		{Document: vectorstore.Document{ID: "a-copy", Embedding: []float32{1, 0.01}}, Score: 0.94},
+		{Document: vectorstore.Document{ID: "b", Embedding: []float32{0, 1}}, Score: 0.7},
+	}
+
+	if got := matchIDs(MMR(matches, 2, 1)); got[0] != "a" || got[1] != "a-copy" {
+		t.Errorf("MMR(lambda=1) = %v, want original order", got)
+	}
+	if got := matchIDs(MMR(matches, 2, 0.5)); got[0] != "a" || got[1] != "b" {
+		t.Errorf("MMR(lambda=0.5) = %v, want [a b]", got)
+	}
+	if got := MMR(matches, 0, 0.5); len(got) != 3 {
+		t.Errorf("MMR(k=0) returned %d matches, want all", len(got))
+	}
+}
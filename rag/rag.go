@@ -0,0 +1,297 @@
+// Package rag provides a retrieval-augmented generation pipeline: documents
+// are embedded into a vector store, and questions are answered from the
+// chunks most similar to them, with citations back to those chunks.
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/loaders"
+	"github.com/parikxxit/go-llm/reranker"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+const defaultTopK = 5
+
+// DefaultSystemPrompt asks the model to answer from the numbered sources and
+// cite them inline
+const DefaultSystemPrompt = `Answer the question using only the numbered sources provided. ` +
+	`After each statement, cite the sources supporting it with their number in square brackets, e.g. [1] or [2][3]. ` +
+	`If the sources do not contain the answer, say that you don't know.`
+
+// Answer represents the answer to a question
+type Answer struct {
+	Text string
+	// Citations are the sources cited in Text, in order of first citation
+	Citations []Citation
+	// Sources are all chunks given to the model; source n is Sources[n-1]
+	Sources  []vectorstore.Match
+	Response *generator.Response
+}
+
+// Pipeline indexes documents and answers questions about them
+type Pipeline struct {
+	client         *gollm.Client
+	store          vectorstore.Store
+	retriever      Retriever
+	model          string
+	embeddingModel string
+	systemPrompt   string
+	topK           int
+	filter         *vectorstore.Filter
+	keywords       *BM25
+	mmr            bool
+	mmrLambda      float64
+	fetchK         int
+	rerank         bool
+	rerankModel    string
+	rerankTopN     int
+}
+
+// Option is a function that configures a Pipeline
+type Option func(*Pipeline)
+
+// WithModel sets the model generating answers
+func WithModel(model string) Option {
+	return func(p *Pipeline) {
+		p.model = model
+	}
+}
+
+// WithEmbeddingModel sets the model embedding documents and questions
+func WithEmbeddingModel(model string) Option {
+	return func(p *Pipeline) {
+		p.embeddingModel = model
+	}
+}
+
+// WithSystemPrompt replaces DefaultSystemPrompt. Custom prompts should keep
+// asking for [n] citation markers for Answer.Citations to be filled.
+func WithSystemPrompt(prompt string) Option {
+	return func(p *Pipeline) {
+		p.systemPrompt = prompt
+	}
+}
+
+// WithTopK sets the number of chunks retrieved per question, 5 by default
+func WithTopK(k int) Option {
+	return func(p *Pipeline) {
+		p.topK = k
+	}
+}
+
+// WithFilter restricts retrieval to chunks matching filter
+func WithFilter(filter *vectorstore.Filter) Option {
+	return func(p *Pipeline) {
+		p.filter = filter
+	}
+}
+
+// WithRetriever replaces vector search over the store, e.g. with hybrid
+// retrieval. Index still writes to the store.
+func WithRetriever(r Retriever) Option {
+	return func(p *Pipeline) {
+		p.retriever = r
+	}
+}
+
+// WithKeywordIndex enables hybrid retrieval: Index also adds documents to
+// index, and questions are answered from vector and BM25 results fused with
+// reciprocal rank fusion. It has no effect on a custom retriever.
+func WithKeywordIndex(index *BM25) Option {
+	return func(p *Pipeline) {
+		p.keywords = index
+	}
+}
+
+// WithRerank reranks retrieved chunks with the client's reranker, keeping
+// the best topN
+func WithRerank(model string, topN int) Option {
+	return func(p *Pipeline) {
+		p.rerank = true
+		p.rerankModel = model
+		p.rerankTopN = topN
+	}
+}
+
+// WithMMR diversifies sources with maximal marginal relevance: fetchK
+// candidates are retrieved, and the top K are picked among them with MMR
+// using lambda. Reranking, if enabled, runs before MMR.
+func WithMMR(lambda float64, fetchK int) Option {
+	return func(p *Pipeline) {
+		p.mmr = true
+		p.mmrLambda = lambda
+		p.fetchK = fetchK
+	}
+}
+
+// New creates a new pipeline answering with client from chunks in store. The
+// client must have an embedder unless a custom retriever is used and Index
+// is never called.
+func New(client *gollm.Client, store vectorstore.Store, opts ...Option) *Pipeline {
+	if client == nil {
+		panic("client cannot be nil")
+	}
+
+	p := &Pipeline{client: client, store: store, systemPrompt: DefaultSystemPrompt, topK: defaultTopK}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.retriever == nil {
+		p.retriever = &VectorRetriever{
+			Embedder: ClientEmbedder(client),
+			Model:    p.embeddingModel,
+			Store:    store,
+			TopK:     p.candidates(),
+			Filter:   p.filter,
+		}
+		if p.keywords != nil {
+			p.retriever = &HybridRetriever{Retrievers: []Retriever{p.retriever, p.keywordRetriever()}, TopK: p.candidates()}
+		}
+	}
+	return p
+}
+
+// Index embeds documents and upserts them into the store
+func (p *Pipeline) Index(ctx context.Context, docs ...vectorstore.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	docs = append([]vectorstore.Document(nil), docs...)
+	if err := vectorstore.Embed(ctx, ClientEmbedder(p.client), p.embeddingModel, docs); err != nil {
+		return fmt.Errorf("embedding documents: %w", err)
+	}
+	if err := p.store.Upsert(ctx, docs...); err != nil {
+		return err
+	}
+	if p.keywords != nil {
+		p.keywords.Add(docs...)
+	}
+	return nil
+}
+
+// keywordRetriever searches the keyword index, applying the pipeline filter
+func (p *Pipeline) keywordRetriever() Retriever {
+	return RetrieverFunc(func(_ context.Context, query string) ([]vectorstore.Match, error) {
+		var matches []vectorstore.Match
+		for _, m := range p.keywords.Search(query, 0) {
+			if p.filter.Match(m.Document.Metadata) {
+				matches = append(matches, m)
+			}
+			if len(matches) == p.candidates() {
+				break
+			}
+		}
+		return matches, nil
+	})
+}
+
+// Retrieve returns the chunks used to answer question
+func (p *Pipeline) Retrieve(ctx context.Context, question string) ([]vectorstore.Match, error) {
+	matches, err := p.retriever.Retrieve(ctx, question)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving sources: %w", err)
+	}
+	if p.rerank && len(matches) > 0 {
+		if matches, err = p.rerankMatches(ctx, question, matches); err != nil {
+			return nil, err
+		}
+	}
+	if p.mmr {
+		matches = MMR(matches, p.topK, p.mmrLambda)
+	}
+	return matches, nil
+}
+
+// candidates returns the number of matches to retrieve before reranking or
+// MMR narrow them down
+func (p *Pipeline) candidates() int {
+	if p.mmr && p.fetchK > p.topK {
+		return p.fetchK
+	}
+	return p.topK
+}
+
+func (p *Pipeline) rerankMatches(ctx context.Context, question string, matches []vectorstore.Match) ([]vectorstore.Match, error) {
+	docs := make([]reranker.Document, len(matches))
+	for i, m := range matches {
+		docs[i] = reranker.Document{ID: m.Document.ID, Text: m.Document.Text, Metadata: m.Document.Metadata}
+	}
+	resp, err := p.client.Rerank(ctx, &reranker.Request{Model: p.rerankModel, Query: question, Documents: docs, TopN: p.rerankTopN})
+	if err != nil {
+		return nil, fmt.Errorf("reranking sources: %w", err)
+	}
+
+	reranked := make([]vectorstore.Match, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		if r.Index < 0 || r.Index >= len(matches) {
+			return nil, fmt.Errorf("reranker returned out of range index %d", r.Index)
+		}
+		reranked = append(reranked, vectorstore.Match{Document: matches[r.Index].Document, Score: r.RelevanceScore})
+	}
+	return reranked, nil
+}
+
+// Query answers question from the retrieved chunks
+func (p *Pipeline) Query(ctx context.Context, question string) (*Answer, error) {
+	sources, err := p.Retrieve(ctx, question)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Generate(ctx, &generator.Request{
+		Model: p.model,
+		Messages: []generator.Message{
+			{Role: generator.SYSTEM, Content: p.systemPrompt},
+			{Role: generator.USER, Content: Prompt(question, sources)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Answer{
+		Text:      resp.Content,
+		Citations: Cite(resp.Content, sources),
+		Sources:   sources,
+		Response:  resp,
+	}, nil
+}
+
+// Prompt renders question and its numbered sources as a user message
+func Prompt(question string, sources []vectorstore.Match) string {
+	var b strings.Builder
+	b.WriteString("Sources:\n")
+	for i, s := range sources {
+		fmt.Fprintf(&b, "\n[%d]", i+1)
+		if src, ok := s.Document.Metadata[loaders.MetadataSource].(string); ok && src != "" {
+			fmt.Fprintf(&b, " (%s)", src)
+		}
+		fmt.Fprintf(&b, " %s\n", s.Document.Text)
+	}
+	fmt.Fprintf(&b, "\nQuestion: %s", question)
+	return b.String()
+}
+
+// ClientEmbedder adapts the embedding capability of client to an
+// embedder.Embedder
+func ClientEmbedder(client *gollm.Client) embedder.Embedder {
+	return clientEmbedder{client}
+}
+
+type clientEmbedder struct {
+	client *gollm.Client
+}
+
+func (e clientEmbedder) Embed(ctx context.Context, req *embedder.Request) (*embedder.Response, error) {
+	return e.client.Embed(ctx, req)
+}
+
+func (e clientEmbedder) GetEmbedderName() string {
+	return "gollm"
+}
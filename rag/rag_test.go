@@ -0,0 +1,76 @@
+package rag
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// keywords embeds texts by which of a fixed vocabulary they mention
+type keywords []string
+
+func (k keywords) Embed(_ context.Context, req *embedder.Request) (*embedder.Response, error) {
+	resp := &embedder.Response{}
+	for i, text := range req.Input {
+		v := make([]float64, len(k))
+		for j, word := range k {
+			if strings.Contains(strings.ToLower(text), word) {
+				v[j] = 1
+			}
+		}
+		resp.Data = append(resp.Data, embedder.EmbedData{Embedding: v, Index: i})
+	}
+	return resp, nil
+}
+
+func (keywords) GetEmbedderName() string { return "keywords" }
+
+func TestPipeline_Query(t *testing.T) {
+	m := mock.New()
+	var prompt string
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		prompt = req.Messages[1].Content
+		return &generator.Response{Content: "Cats purr [1]. They also sleep a lot [1, 2][7]."}, nil
+	}
+	client := gollm.NewClient(m, gollm.WithEmbedder(keywords{"cat", "sleep", "dog"}))
+
+	p := New(client, vectorstore.NewMemoryStore(vectorstore.Cosine), WithTopK(2))
+	err := p.Index(context.Background(),
+		vectorstore.Document{ID: "a:0", Text: "Cats purr when happy.", Metadata: map[string]any{"source": "cats.md"}},
+		vectorstore.Document{ID: "b:0", Text: "Cats sleep sixteen hours.", Metadata: map[string]any{"source": "sleep.md"}},
+		vectorstore.Document{ID: "c:0", Text: "Dogs bark."},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	answer, err := p.Query(context.Background(), "Do cats sleep?")
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(answer.Sources) != 2 || !strings.Contains(prompt, "[1] (sleep.md) Cats sleep sixteen hours.") {
+		t.Errorf("prompt = %q", prompt)
+	}
+	if len(answer.Citations) != 2 {
+		t.Fatalf("Citations = %+v, want 2", answer.Citations)
+	}
+	if c := answer.Citations[0]; c.Marker != 1 || c.ChunkID != "b:0" || c.Source != "sleep.md" {
+		t.Errorf("Citations[0] = %+v", c)
+	}
+	if c := answer.Citations[1]; c.Marker != 2 || c.ChunkID != "a:0" {
+		t.Errorf("Citations[1] = %+v", c)
+	}
+}
+
+func TestCite(t *testing.T) {
+	sources := []vectorstore.Match{{Document: vectorstore.Document{ID: "x"}}}
+	if got := Cite("No markers here, or only [2] and [a].", sources); len(got) != 0 {
+		t.Errorf("Cite() = %+v, want none", got)
+	}
+}
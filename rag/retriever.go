@@ -0,0 +1,42 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// Retriever finds the chunks relevant to a query, most relevant first
+type Retriever interface {
+	Retrieve(ctx context.Context, query string) ([]vectorstore.Match, error)
+}
+
+// RetrieverFunc is an adapter to allow the use of ordinary functions as
+// retrievers
+type RetrieverFunc func(ctx context.Context, query string) ([]vectorstore.Match, error)
+
+func (f RetrieverFunc) Retrieve(ctx context.Context, query string) ([]vectorstore.Match, error) {
+	return f(ctx, query)
+}
+
+// VectorRetriever embeds the query and searches a vector store
+type VectorRetriever struct {
+	Embedder embedder.Embedder
+	Model    string
+	Store    vectorstore.Store
+	TopK     int
+	Filter   *vectorstore.Filter
+}
+
+func (r *VectorRetriever) Retrieve(ctx context.Context, query string) ([]vectorstore.Match, error) {
+	resp, err := r.Embedder.Embed(ctx, &embedder.Request{Model: r.Model, Input: []string{query}, Float32: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != 1 {
+		return nil, fmt.Errorf("embedder returned %d embeddings for 1 query", len(resp.Data))
+	}
+	return r.Store.Query(ctx, vectorstore.Query{Vector: resp.Data[0].Vector32(), TopK: r.TopK, Filter: r.Filter})
+}
@@ -0,0 +1,110 @@
+package gollm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/reranker"
+	"github.com/parikxxit/go-llm/tokenizer"
+	"golang.org/x/time/rate"
+)
+
+// rateLimits holds a pair of token buckets per provider
+type rateLimits struct {
+	requestsPerMinute int
+	tokensPerMinute   int
+
+	mu        sync.Mutex
+	providers map[string]*providerLimit
+}
+
+type providerLimit struct {
+	requests *rate.Limiter
+	tokens   *rate.Limiter
+}
+
+// WithRateLimit paces provider requests, retries included, to at most
+// requestsPerMinute and tokensPerMinute, waiting rather than tripping the
+// provider's 429s; zero disables either limit. Tokens are estimated before
+// sending with tokenizer.ForModel, counting a request's MaxTokens as well.
+// Each provider, e.g. each fallback, has its own limits, starting full so a
+// minute's worth may burst at once.
+func WithRateLimit(requestsPerMinute, tokensPerMinute int) Option {
+	return func(c *Client) {
+		c.rateLimits = &rateLimits{
+			requestsPerMinute: requestsPerMinute,
+			tokensPerMinute:   tokensPerMinute,
+			providers:         map[string]*providerLimit{},
+		}
+	}
+}
+
+func (l *rateLimits) provider(name string) *providerLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	p, ok := l.providers[name]
+	if !ok {
+		p = &providerLimit{}
+		if l.requestsPerMinute > 0 {
+			p.requests = rate.NewLimiter(rate.Limit(float64(l.requestsPerMinute)/60), l.requestsPerMinute)
+		}
+		if l.tokensPerMinute > 0 {
+			p.tokens = rate.NewLimiter(rate.Limit(float64(l.tokensPerMinute)/60), l.tokensPerMinute)
+		}
+		l.providers[name] = p
+	}
+	return p
+}
+
+// waitRateLimit blocks until provider may be sent a request of the tokens
+// returned by count, which is only called when tokens are limited
+func (c *Client) waitRateLimit(ctx context.Context, provider string, count func() int) error {
+	if c.rateLimits == nil {
+		return nil
+	}
+	p := c.rateLimits.provider(provider)
+	if p.requests != nil {
+		if err := p.requests.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit of %s: %w", provider, err)
+		}
+	}
+	if p.tokens != nil {
+		// Requests larger than a minute's worth wait for a full bucket
+		n := min(count(), p.tokens.Burst())
+		if err := p.tokens.WaitN(ctx, n); err != nil {
+			return fmt.Errorf("rate limit of %s: %w", provider, err)
+		}
+	}
+	return nil
+}
+
+func generateTokens(req *generator.Request) func() int {
+	return func() int {
+		return tokenizer.CountRequest(tokenizer.ForModel(req.Model), req) + req.MaxTokens
+	}
+}
+
+func embedTokens(req *embedder.Request) func() int {
+	return func() int {
+		counter := tokenizer.ForModel(req.Model)
+		total := 0
+		for _, in := range req.Input {
+			total += counter.Count(in)
+		}
+		return total
+	}
+}
+
+func rerankTokens(req *reranker.Request) func() int {
+	return func() int {
+		counter := tokenizer.ForModel(req.Model)
+		total := counter.Count(req.Query)
+		for _, d := range req.Documents {
+			total += counter.Count(d.Text)
+		}
+		return total
+	}
+}
@@ -0,0 +1,51 @@
+package gollm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_WithRateLimit(t *testing.T) {
+	req := func(maxTokens int) *generator.Request {
+		return &generator.Request{Model: "gpt-4o", MaxTokens: maxTokens, Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+	}
+	tests := []struct {
+		name     string
+		rpm, tpm int
+		first    *generator.Request
+		second   *generator.Request
+	}{
+		{"requests", 1, 0, req(0), req(0)},
+		{"tokens", 0, 1000, req(900), req(200)},
+		{"oversized", 0, 100, req(5000), req(0)},
+	}
+	for _, tt := range tests {
+		calls := 0
+		m := mock.New()
+		m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+			calls++
+			return &generator.Response{Content: "ok"}, nil
+		}
+		client := NewClient(m, WithRateLimit(tt.rpm, tt.tpm), WithEmbedder(&fakeEmbedder{}))
+
+		if _, err := client.Generate(context.Background(), tt.first); err != nil {
+			t.Fatalf("%s: first Generate() error = %v", tt.name, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err := client.Generate(ctx, tt.second)
+		cancel()
+		if err == nil || calls != 1 {
+			t.Errorf("%s: second Generate() error = %v after %d calls, want the limit to hold it back", tt.name, err, calls)
+		}
+
+		// Providers are limited independently
+		if _, err := client.Embed(context.Background(), &embedder.Request{Input: []string{"a"}}); err != nil {
+			t.Errorf("%s: Embed() error = %v, want its own limit", tt.name, err)
+		}
+	}
+}
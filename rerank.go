@@ -0,0 +1,84 @@
+package gollm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/parikxxit/go-llm/reranker"
+)
+
+// rerankBatchLimit returns the configured batch size, else the reranker's
+func (c *Client) rerankBatchLimit(r reranker.Reranker) int {
+	if c.rerankBatchSize > 0 {
+		return c.rerankBatchSize
+	}
+	if l, ok := r.(reranker.BatchLimiter); ok {
+		return l.MaxBatchSize()
+	}
+	return 0
+}
+
+// rerankBatches splits request into batches of size documents and merges
+// their results, sorted by score across batches and cut to TopN. Each batch
+// keeps TopN results, which always include the overall TopN. The first
+// failing batch cancels the others.
+func (c *Client) rerankBatches(ctx context.Context, request *reranker.Request, size int) (*reranker.Response, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	n := (len(request.Documents) + size - 1) / size
+	responses := make([]*reranker.Response, n)
+	errs := make([]error, n)
+
+	concurrency := c.embedConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultEmbedConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		batch := *request
+		batch.Documents = request.Documents[i*size : min((i+1)*size, len(request.Documents))]
+
+		wg.Add(1)
+		go func(i int, batch *reranker.Request) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			responses[i], errs[i] = c.rerankOnce(ctx, batch)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i, &batch)
+	}
+	wg.Wait()
+
+	merged := &reranker.Response{}
+	for i, resp := range responses {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("reranking batch %d of %d: %w", i+1, n, errs[i])
+		}
+		merged.Object, merged.Model = resp.Object, resp.Model
+		merged.Usage.PromptTokens += resp.Usage.PromptTokens
+		merged.Usage.TotalTokens += resp.Usage.TotalTokens
+		for _, r := range resp.Results {
+			r.Index += i * size
+			merged.Results = append(merged.Results, r)
+		}
+	}
+	sort.SliceStable(merged.Results, func(i, j int) bool {
+		return merged.Results[i].RelevanceScore > merged.Results[j].RelevanceScore
+	})
+	if request.TopN > 0 && len(merged.Results) > request.TopN {
+		merged.Results = merged.Results[:request.TopN]
+	}
+	return merged, nil
+}
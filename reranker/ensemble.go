@@ -0,0 +1,153 @@
+package reranker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fusion represents how an ensemble combines the scores of its members
+type Fusion string
+
+const (
+	// FuseWeighted averages min-max normalized scores by member weight
+	FuseWeighted Fusion = "weighted"
+	// FuseRRF sums weighted reciprocal ranks, ignoring score scales entirely
+	FuseRRF Fusion = "rrf"
+)
+
+const defaultRRFK = 60
+
+// Member represents a reranker of an ensemble
+type Member struct {
+	Reranker Reranker
+	// Model is sent to this member in place of the request's model
+	Model string
+	// Weight scales the member's contribution, 1 when zero
+	Weight float64
+}
+
+// Ensemble is a reranker running its members concurrently on the same
+// documents and fusing their scores, e.g. a cheap local cross-encoder with
+// an API reranker
+type Ensemble struct {
+	members []Member
+	fusion  Fusion
+	k       float64
+}
+
+// EnsembleOption is a function that configures an Ensemble
+type EnsembleOption func(*Ensemble)
+
+// WithFusion sets how member scores are combined, FuseWeighted by default
+func WithFusion(f Fusion) EnsembleOption {
+	return func(e *Ensemble) {
+		e.fusion = f
+	}
+}
+
+// WithRRFK sets the rank constant of FuseRRF, 60 by default
+func WithRRFK(k float64) EnsembleOption {
+	return func(e *Ensemble) {
+		e.k = k
+	}
+}
+
+// NewEnsemble creates a new ensemble of members
+func NewEnsemble(members []Member, opts ...EnsembleOption) *Ensemble {
+	e := &Ensemble{members: members, fusion: FuseWeighted, k: defaultRRFK}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Rerank asks every member to score all documents and returns the TopN
+// documents by fused score. Any failing member fails the request.
+func (e *Ensemble) Rerank(ctx context.Context, req *Request) (*Response, error) {
+	if len(e.members) == 0 {
+		return nil, fmt.Errorf("ensemble has no rerankers")
+	}
+	if e.fusion != FuseWeighted && e.fusion != FuseRRF {
+		return nil, fmt.Errorf("unknown fusion %q", e.fusion)
+	}
+
+	responses := make([]*Response, len(e.members))
+	errs := make([]error, len(e.members))
+	var wg sync.WaitGroup
+	for i, m := range e.members {
+		sub := *req
+		sub.TopN, sub.Normalize, sub.MinScore = 0, "", 0
+		if m.Model != "" {
+			sub.Model = m.Model
+		}
+		wg.Add(1)
+		go func(i int, m Member, sub *Request) {
+			defer wg.Done()
+			responses[i], errs[i] = m.Reranker.Rerank(ctx, sub)
+		}(i, m, &sub)
+	}
+	wg.Wait()
+
+	fused := make([]float64, len(req.Documents))
+	resp := &Response{Object: "list", Model: e.GetRerankerName()}
+	for i, r := range responses {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("reranker %s: %w", e.members[i].Reranker.GetRerankerName(), errs[i])
+		}
+		weight := e.members[i].Weight
+		if weight == 0 {
+			weight = 1
+		}
+		resp.Usage.PromptTokens += r.Usage.PromptTokens
+		resp.Usage.TotalTokens += r.Usage.TotalTokens
+
+		results := append([]Result(nil), r.Results...)
+		sort.SliceStable(results, func(a, b int) bool { return results[a].RelevanceScore > results[b].RelevanceScore })
+		if e.fusion == FuseWeighted {
+			NormalizeScores(results, NormalizeMinMax)
+		}
+		for rank, res := range results {
+			if res.Index < 0 || res.Index >= len(fused) {
+				return nil, fmt.Errorf("reranker %s returned out of range index %d", e.members[i].Reranker.GetRerankerName(), res.Index)
+			}
+			if e.fusion == FuseRRF {
+				fused[res.Index] += weight / (e.k + float64(rank+1))
+			} else {
+				fused[res.Index] += weight * res.RelevanceScore
+			}
+		}
+	}
+
+	var total float64
+	for _, m := range e.members {
+		if m.Weight == 0 {
+			total++
+		} else {
+			total += m.Weight
+		}
+	}
+	resp.Results = make([]Result, len(req.Documents))
+	for i, doc := range req.Documents {
+		score := fused[i]
+		if e.fusion == FuseWeighted {
+			score /= total
+		}
+		resp.Results[i] = Result{Document: doc, Index: i, RelevanceScore: score}
+	}
+	sort.SliceStable(resp.Results, func(a, b int) bool { return resp.Results[a].RelevanceScore > resp.Results[b].RelevanceScore })
+	if req.TopN > 0 && len(resp.Results) > req.TopN {
+		resp.Results = resp.Results[:req.TopN]
+	}
+	return resp, nil
+}
+
+func (e *Ensemble) GetRerankerName() string {
+	names := make([]string, len(e.members))
+	for i, m := range e.members {
+		names[i] = m.Reranker.GetRerankerName()
+	}
+	return "ensemble(" + strings.Join(names, ",") + ")"
+}
@@ -0,0 +1,56 @@
+package reranker
+
+import (
+	"context"
+	"testing"
+)
+
+// fixedReranker returns the given score for each document index
+type fixedReranker struct {
+	name   string
+	scores []float64
+}
+
+func (f fixedReranker) Rerank(_ context.Context, req *Request) (*Response, error) {
+	resp := &Response{}
+	for i := range req.Documents {
+		resp.Results = append(resp.Results, Result{Index: i, RelevanceScore: f.scores[i]})
+	}
+	return resp, nil
+}
+
+func (f fixedReranker) GetRerankerName() string { return f.name }
+
+func TestEnsemble(t *testing.T) {
+	docs := []Document{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	local := fixedReranker{"local", []float64{10, 0, 5}}  // a c b
+	api := fixedReranker{"api", []float64{0.1, 0.8, 0.9}} // c b a
+
+	tests := []struct {
+		name    string
+		members []Member
+		fusion  Fusion
+		want    []string
+	}{
+		{"weighted", []Member{{Reranker: local}, {Reranker: api}}, FuseWeighted, []string{"c", "a", "b"}},
+		{"weights", []Member{{Reranker: local, Weight: 3}, {Reranker: api}}, FuseWeighted, []string{"a", "c", "b"}},
+		{"rrf", []Member{{Reranker: local}, {Reranker: api}}, FuseRRF, []string{"c", "a", "b"}},
+	}
+	for _, tt := range tests {
+		e := NewEnsemble(tt.members, WithFusion(tt.fusion))
+		resp, err := e.Rerank(context.Background(), &Request{Documents: docs})
+		if err != nil {
+			t.Fatalf("%s: Rerank() error = %v", tt.name, err)
+		}
+		for i, r := range resp.Results {
+			if r.Document.ID != tt.want[i] {
+				t.Errorf("%s: Results[%d] = %s, want %s", tt.name, i, r.Document.ID, tt.want[i])
+			}
+		}
+	}
+
+	e := NewEnsemble([]Member{{Reranker: local}, {Reranker: api}})
+	if got := e.GetRerankerName(); got != "ensemble(local,api)" {
+		t.Errorf("GetRerankerName() = %q", got)
+	}
+}
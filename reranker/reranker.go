@@ -5,10 +5,12 @@ import (
 	"context"
 )
 
-// Document represents a document for reranking
+// Document represents a document for reranking. Metadata is not sent to
+// providers; it is carried through to the results.
 type Document struct {
-	ID   string
-	Text string
+	ID       string
+	Text     string
+	Metadata map[string]any
 }
 
 // TokenUsage represents token usage information
@@ -24,6 +26,17 @@ type Result struct {
 	RelevanceScore float64
 }
 
+// Normalization represents a rescaling of relevance scores
+type Normalization string
+
+const (
+	// NormalizeMinMax maps the scores of a response linearly onto [0, 1]
+	NormalizeMinMax Normalization = "minmax"
+	// NormalizeSigmoid maps raw logits onto (0, 1) independently of the other
+	// results, keeping scores comparable across requests
+	NormalizeSigmoid Normalization = "sigmoid"
+)
+
 // Request represents a reranking request
 type Request struct {
 	Model           string
@@ -31,8 +44,14 @@ type Request struct {
 	Documents       []Document
 	TopN            int
 	ReturnDocuments bool
-	User            string
-	ProviderParams  map[string]interface{}
+	// Normalize rescales relevance scores, which are left as returned by the
+	// provider when empty
+	Normalize Normalization
+	// MinScore drops results scoring below it, after normalization; zero
+	// keeps every result
+	MinScore       float64
+	User           string
+	ProviderParams map[string]interface{}
 }
 
 // Response represents a reranking response
@@ -51,3 +70,10 @@ type Reranker interface {
 	// GetName returns the name of the implementation
 	GetRerankerName() string
 }
+
+// BatchLimiter is implemented by rerankers that cap the number of documents
+// per request. The client splits larger requests into batches of at most
+// MaxBatchSize documents.
+type BatchLimiter interface {
+	MaxBatchSize() int
+}
@@ -0,0 +1,51 @@
+package reranker
+
+import (
+	"fmt"
+	"math"
+)
+
+// NormalizeScores rescales the relevance scores of results in place
+func NormalizeScores(results []Result, n Normalization) error {
+	switch n {
+	case "":
+	case NormalizeSigmoid:
+		for i := range results {
+			results[i].RelevanceScore = 1 / (1 + math.Exp(-results[i].RelevanceScore))
+		}
+	case NormalizeMinMax:
+		if len(results) == 0 {
+			return nil
+		}
+		lo, hi := results[0].RelevanceScore, results[0].RelevanceScore
+		for _, r := range results[1:] {
+			lo, hi = math.Min(lo, r.RelevanceScore), math.Max(hi, r.RelevanceScore)
+		}
+		for i := range results {
+			if hi == lo {
+				// A single result, or a tie, is as relevant as it gets
+				results[i].RelevanceScore = 1
+			} else {
+				results[i].RelevanceScore = (results[i].RelevanceScore - lo) / (hi - lo)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown score normalization %q", n)
+	}
+	return nil
+}
+
+// FilterMinScore returns the results scoring at least min, keeping their
+// order. A zero min keeps every result.
+func FilterMinScore(results []Result, min float64) []Result {
+	if min == 0 {
+		return results
+	}
+	kept := results[:0]
+	for _, r := range results {
+		if r.RelevanceScore >= min {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
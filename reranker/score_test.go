@@ -0,0 +1,42 @@
+package reranker
+
+import (
+	"math"
+	"testing"
+)
+
+func scores(results []Result) []float64 {
+	out := make([]float64, len(results))
+	for i, r := range results {
+		out[i] = r.RelevanceScore
+	}
+	return out
+}
+
+func TestNormalizeScores(t *testing.T) {
+	results := []Result{{RelevanceScore: 8}, {RelevanceScore: 4}, {RelevanceScore: -2}}
+	if err := NormalizeScores(results, NormalizeMinMax); err != nil {
+		t.Fatal(err)
+	}
+	if got := scores(results); got[0] != 1 || got[1] != 0.6 || got[2] != 0 {
+		t.Errorf("NormalizeScores(minmax) = %v, want [1 0.6 0]", got)
+	}
+
+	results = []Result{{RelevanceScore: 0}, {RelevanceScore: 2}}
+	NormalizeScores(results, NormalizeSigmoid)
+	if got := scores(results); got[0] != 0.5 || math.Abs(got[1]-0.8808) > 1e-4 {
+		t.Errorf("NormalizeScores(sigmoid) = %v", got)
+	}
+
+	if err := NormalizeScores(results, "zscore"); err == nil {
+		t.Error("NormalizeScores() of an unknown normalization error = nil")
+	}
+}
+
+func TestFilterMinScore(t *testing.T) {
+	results := []Result{{Index: 0, RelevanceScore: 0.9}, {Index: 1, RelevanceScore: 0.2}, {Index: 2, RelevanceScore: 0.5}}
+	kept := FilterMinScore(results, 0.5)
+	if len(kept) != 2 || kept[0].Index != 0 || kept[1].Index != 2 {
+		t.Errorf("FilterMinScore() = %+v", kept)
+	}
+}
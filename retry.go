@@ -0,0 +1,102 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/parikxxit/go-llm/llmerrors"
+)
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// retry calls fn until it succeeds, fails with an error that is not
+// llmerrors.Retryable, or llmerrors.Ambiguous for repeatable requests, or
+// the client's retries are spent, emitting hook
+// events described by e for every attempt. It waits as long as
+// the provider asked through Retry-After, falling back to jittered
+// exponential backoff, and gives up early when the wait would outlast the
+// context deadline or the client's maximum delay.
+func retry[T any](ctx context.Context, c *Client, e Event, fn func() (T, error)) (T, error) {
+	model := e.Model
+	if e.Metadata == nil {
+		e.Metadata = RequestMetadata(ctx)
+	}
+	for attempt := 0; ; attempt++ {
+		e.Attempt, e.Start, e.Model, e.Err, e.Wait, e.RequestID = attempt+1, time.Now(), model, nil, 0, ""
+		c.emit(ctx, hookRequestStart, e)
+		v, err := fn()
+		e.Latency = time.Since(e.Start)
+		if err == nil {
+			setUsage(&e, v)
+			c.emit(ctx, hookResponse, e)
+			return v, nil
+		}
+		e.Err = err
+		var apiErr *llmerrors.Error
+		if errors.As(err, &apiErr) {
+			e.RequestID = apiErr.RequestID
+		}
+		c.emit(ctx, hookError, e)
+		retryable := llmerrors.Retryable(err) || e.repeatable && llmerrors.Ambiguous(err)
+		if attempt >= c.retryCountFor(ctx) || !retryable {
+			return v, err
+		}
+
+		wait, requested := c.retryDelay(err, attempt)
+		if wait > c.retryMaxDelay {
+			return v, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return v, err
+		}
+		e.Wait = wait
+		c.emit(ctx, hookRetry, e)
+		source := "backoff"
+		if requested {
+			source = "retry-after"
+		}
+		c.logger.InfoContext(ctx, "retrying", "op", e.Operation, "provider", e.Provider, "model", e.Model,
+			"attempt", e.Attempt, "wait", wait, "wait_source", source, "error", err)
+
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return v, err
+		}
+	}
+}
+
+// retryDelay returns the wait before retry attempt+1 and whether the
+// provider requested it
+func (c *Client) retryDelay(err error, attempt int) (time.Duration, bool) {
+	var e *llmerrors.Error
+	if errors.As(err, &e) && e.RetryAfter > 0 {
+		return e.RetryAfter, true
+	}
+	// Shifting past the max would overflow on high attempts
+	d := c.retryMaxDelay
+	if attempt < 63 && c.retryBaseDelay <= c.retryMaxDelay>>attempt {
+		d = c.retryBaseDelay << attempt
+	}
+	if d <= 0 {
+		return 0, false
+	}
+	// Equal jitter, over the upper half, spreads out clients retrying together
+	return d/2 + rand.N(d/2+1), false
+}
+
+// WithRetryBackoff sets the first backoff delay, doubled for every further
+// retry, and the maximum delay between attempts; 500ms and 30s by default.
+// Retries are skipped when a provider asks for a longer wait than max.
+func WithRetryBackoff(base, max time.Duration) Option {
+	return func(c *Client) {
+		c.retryBaseDelay, c.retryMaxDelay = base, max
+	}
+}
@@ -0,0 +1,117 @@
+package router
+
+import (
+	"math"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/models"
+	"github.com/parikxxit/go-llm/tokenizer"
+)
+
+// Candidate represents a route a Policy may pick, with its models registry
+// metadata and rolling metrics
+type Candidate struct {
+	Route
+	Info  models.Info
+	Stats Stats
+}
+
+// Policy picks the candidate serving a request, or returns false when none
+// is suitable
+type Policy interface {
+	Pick(req *generator.Request, candidates []Candidate) (Candidate, bool)
+}
+
+// PolicyFunc adapts a function to a Policy
+type PolicyFunc func(req *generator.Request, candidates []Candidate) (Candidate, bool)
+
+func (f PolicyFunc) Pick(req *generator.Request, candidates []Candidate) (Candidate, bool) {
+	return f(req, candidates)
+}
+
+// Cheapest picks the candidate of the lowest input plus output price that
+// has the required capabilities, tools as well when the request has any,
+// and a context window fitting the request. Models of unknown pricing are
+// picked last, those of unknown context window assumed to fit.
+func Cheapest(required models.Capability) Policy {
+	return PolicyFunc(func(req *generator.Request, candidates []Candidate) (Candidate, bool) {
+		want := required
+		if len(req.Tools) > 0 {
+			want |= models.Tools
+		}
+		var best Candidate
+		bestPrice, found := math.Inf(1), false
+		for _, c := range candidates {
+			if !c.Info.Capabilities.Has(want) || !fits(req, c) {
+				continue
+			}
+			price := c.Info.Pricing.Input + c.Info.Pricing.Output
+			if c.Info.Pricing == (models.Pricing{}) {
+				price = math.Inf(1)
+			}
+			if !found || price < bestPrice {
+				best, bestPrice, found = c, price, true
+			}
+		}
+		return best, found
+	})
+}
+
+// Fastest picks the candidate of the lowest 95th percentile latency.
+// Candidates without measurements are tried first, those failing most of
+// their requests last.
+func Fastest() Policy {
+	return PolicyFunc(func(_ *generator.Request, candidates []Candidate) (Candidate, bool) {
+		if len(candidates) == 0 {
+			return Candidate{}, false
+		}
+		best := candidates[0]
+		for _, c := range candidates[1:] {
+			if latency(c) < latency(best) {
+				best = c
+			}
+		}
+		return best, true
+	})
+}
+
+// WithinSLO restricts p to the candidates whose 95th percentile latency is
+// at most slo, candidates without measurements included. When none meets the
+// SLO, or p finds none suitable among them, the fastest of the candidates p
+// accepts is picked.
+func WithinSLO(slo time.Duration, p Policy) Policy {
+	return PolicyFunc(func(req *generator.Request, candidates []Candidate) (Candidate, bool) {
+		var within, accepted []Candidate
+		for _, c := range candidates {
+			if latency(c) <= slo {
+				within = append(within, c)
+			}
+		}
+		if c, ok := p.Pick(req, within); ok {
+			return c, true
+		}
+		for _, c := range candidates {
+			if _, ok := p.Pick(req, []Candidate{c}); ok {
+				accepted = append(accepted, c)
+			}
+		}
+		return Fastest().Pick(req, accepted)
+	})
+}
+
+// latency returns the 95th percentile latency of c, or the maximum duration
+// when c fails most of its requests
+func latency(c Candidate) time.Duration {
+	if c.Stats.Errors*2 > c.Stats.Requests {
+		return math.MaxInt64
+	}
+	return c.Stats.P95
+}
+
+func fits(req *generator.Request, c Candidate) bool {
+	if c.Info.ContextWindow == 0 {
+		return true
+	}
+	return tokenizer.CountRequest(tokenizer.ForModel(c.Model), req)+req.MaxTokens <= c.Info.ContextWindow
+}
@@ -0,0 +1,227 @@
+// Package router resolves model names and logical aliases such as "fast" or
+// "smart" to a concrete provider and model, so callers write provider
+// agnostic code while routes are repointed at runtime.
+package router
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/models"
+)
+
+// Route represents the provider and model serving a name
+type Route struct {
+	Provider string // Name the provider was added under
+	Model    string
+}
+
+// Router is a generator.Generator sending each request to the provider its
+// Request.Model routes to, with the model rewritten. A name resolves to, in
+// order: its policy, its route, "provider/model" of an added provider, or the
+// default provider. Router is safe for concurrent use.
+type Router struct {
+	mu        sync.RWMutex
+	providers map[string]generator.Generator
+	routes    map[string]Route
+	policies  map[string]policyRoute
+	fallback  string
+	collector collector
+}
+
+type policyRoute struct {
+	policy     Policy
+	candidates []Route
+}
+
+// Option is a function that configures a Router
+type Option func(*Router)
+
+// WithProvider adds a provider routes refer to by name
+func WithProvider(name string, g generator.Generator) Option {
+	return func(r *Router) {
+		r.providers[name] = g
+	}
+}
+
+// WithRoute routes name, an alias or a model name, to route
+func WithRoute(name string, route Route) Option {
+	return func(r *Router) {
+		r.routes[name] = route
+	}
+}
+
+// WithPolicy routes name to the candidate p picks for each request, e.g.
+// WithinSLO(2*time.Second, Cheapest(models.Tools))
+func WithPolicy(name string, p Policy, candidates ...Route) Option {
+	return func(r *Router) {
+		r.policies[name] = policyRoute{policy: p, candidates: candidates}
+	}
+}
+
+// WithDefaultProvider sends names without a route to provider unchanged
+func WithDefaultProvider(provider string) Option {
+	return func(r *Router) {
+		r.fallback = provider
+	}
+}
+
+// New creates a new router. Its routes must refer to added providers.
+func New(opts ...Option) (*Router, error) {
+	r := &Router{
+		providers: map[string]generator.Generator{},
+		routes:    map[string]Route{},
+		policies:  map[string]policyRoute{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	for name, route := range r.routes {
+		if _, ok := r.providers[route.Provider]; !ok {
+			return nil, fmt.Errorf("route %s: unknown provider %q", name, route.Provider)
+		}
+	}
+	for name, p := range r.policies {
+		for _, route := range p.candidates {
+			if _, ok := r.providers[route.Provider]; !ok {
+				return nil, fmt.Errorf("policy %s: unknown provider %q", name, route.Provider)
+			}
+		}
+	}
+	if _, ok := r.providers[r.fallback]; r.fallback != "" && !ok {
+		return nil, fmt.Errorf("unknown default provider %q", r.fallback)
+	}
+	return r, nil
+}
+
+// SetRoute routes name to route, replacing its previous route
+func (r *Router) SetRoute(name string, route Route) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.providers[route.Provider]; !ok {
+		return fmt.Errorf("route %s: unknown provider %q", name, route.Provider)
+	}
+	r.routes[name] = route
+	return nil
+}
+
+// DeleteRoute removes the route of name
+func (r *Router) DeleteRoute(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.routes, name)
+}
+
+// Routes returns the routed names, sorted
+func (r *Router) Routes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.routes))
+	for name := range r.routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the route of name and the provider serving it. Unknown
+// names fail with llmerrors.ErrModelNotFound.
+func (r *Router) Resolve(name string) (Route, generator.Generator, error) {
+	return r.resolve(&generator.Request{Model: name})
+}
+
+func (r *Router) resolve(req *generator.Request) (Route, generator.Generator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name := req.Model
+	if p, ok := r.policies[name]; ok {
+		candidates := make([]Candidate, len(p.candidates))
+		for i, route := range p.candidates {
+			info, _ := models.Lookup(route.Model)
+			candidates[i] = Candidate{Route: route, Info: info, Stats: r.collector.stats(route)}
+		}
+		c, ok := p.policy.Pick(req, candidates)
+		if !ok {
+			return Route{}, nil, fmt.Errorf("%w: policy %s found no suitable model", llmerrors.ErrModelNotFound, name)
+		}
+		return c.Route, r.providers[c.Provider], nil
+	}
+
+	route, ok := r.routes[name]
+	if !ok {
+		if provider, model, found := strings.Cut(name, "/"); found && r.providers[provider] != nil {
+			route = Route{Provider: provider, Model: model}
+		} else if r.fallback != "" {
+			route = Route{Provider: r.fallback, Model: name}
+		} else {
+			return Route{}, nil, fmt.Errorf("%w: no route for %q", llmerrors.ErrModelNotFound, name)
+		}
+	}
+	return route, r.providers[route.Provider], nil
+}
+
+// Info returns the models registry metadata, such as context window,
+// pricing and capabilities, of the model name resolves to
+func (r *Router) Info(name string) (models.Info, error) {
+	route, _, err := r.Resolve(name)
+	if err != nil {
+		return models.Info{}, err
+	}
+	info, ok := models.Lookup(route.Model)
+	if !ok {
+		return models.Info{}, fmt.Errorf("%w: no metadata for %q", llmerrors.ErrModelNotFound, route.Model)
+	}
+	return info, nil
+}
+
+func (r *Router) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	route, g, routed, err := r.route(req)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := g.Generate(ctx, routed)
+	r.observe(ctx, route, start, err)
+	return resp, err
+}
+
+// GenerateStream measures the latency of routes until their stream opens
+func (r *Router) GenerateStream(ctx context.Context, req *generator.Request) (<-chan *generator.Response, error) {
+	route, g, routed, err := r.route(req)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	stream, err := g.GenerateStream(ctx, routed)
+	r.observe(ctx, route, start, err)
+	return stream, err
+}
+
+func (r *Router) GetName() string {
+	return "router"
+}
+
+// observe records a request to route, unless its caller gave up
+func (r *Router) observe(ctx context.Context, route Route, start time.Time, err error) {
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	r.collector.observe(route, time.Since(start), err)
+}
+
+func (r *Router) route(req *generator.Request) (Route, generator.Generator, *generator.Request, error) {
+	route, g, err := r.resolve(req)
+	if err != nil {
+		return Route{}, nil, nil, err
+	}
+	routed := *req
+	routed.Model = route.Model
+	return route, g, &routed, nil
+}
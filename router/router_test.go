@@ -0,0 +1,138 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/models"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func named(name string) *mock.Mock {
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		return &generator.Response{Content: name + ":" + req.Model}, nil
+	}
+	return m
+}
+
+func TestRouter(t *testing.T) {
+	r, err := New(
+		WithProvider("openai", named("openai")),
+		WithProvider("anthropic", named("anthropic")),
+		WithRoute("fast", Route{Provider: "openai", Model: "gpt-4o-mini"}),
+		WithRoute("smart", Route{Provider: "anthropic", Model: "claude-sonnet-4"}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"fast", "openai:gpt-4o-mini"},
+		{"smart", "anthropic:claude-sonnet-4"},
+		{"anthropic/claude-opus-4", "anthropic:claude-opus-4"},
+	}
+	for _, tt := range tests {
+		resp, err := r.Generate(context.Background(), &generator.Request{Model: tt.model})
+		if err != nil || resp.Content != tt.want {
+			t.Errorf("Generate(%s) = %v, %v, want %s", tt.model, resp, err, tt.want)
+		}
+	}
+	if _, err := r.Generate(context.Background(), &generator.Request{Model: "gpt-4o"}); !errors.Is(err, llmerrors.ErrModelNotFound) {
+		t.Errorf("Generate() of an unrouted model error = %v, want %v", err, llmerrors.ErrModelNotFound)
+	}
+
+	if err := r.SetRoute("fast", Route{Provider: "anthropic", Model: "claude-3-5-haiku"}); err != nil {
+		t.Fatalf("SetRoute() error = %v", err)
+	}
+	if resp, _ := r.Generate(context.Background(), &generator.Request{Model: "fast"}); resp.Content != "anthropic:claude-3-5-haiku" {
+		t.Errorf("Generate(fast) after SetRoute() = %s", resp.Content)
+	}
+	if err := r.SetRoute("cheap", Route{Provider: "mistral", Model: "small"}); err == nil {
+		t.Error("SetRoute() to an unknown provider error = nil")
+	}
+
+	info, err := r.Info("smart")
+	if err != nil || info.ContextWindow != 200000 || !info.Capabilities.Has(models.Tools|models.Vision) {
+		t.Errorf("Info(smart) = %+v, %v", info, err)
+	}
+}
+
+func TestRouter_DefaultProvider(t *testing.T) {
+	if _, err := New(WithDefaultProvider("openai")); err == nil {
+		t.Error("New() with an unknown default provider error = nil")
+	}
+	r, _ := New(WithProvider("openai", named("openai")), WithDefaultProvider("openai"))
+	if resp, err := r.Generate(context.Background(), &generator.Request{Model: "gpt-4o"}); err != nil || resp.Content != "openai:gpt-4o" {
+		t.Errorf("Generate() = %v, %v, want the default provider", resp, err)
+	}
+}
+
+func TestRouter_Policy(t *testing.T) {
+	slow := named("slow")
+	slow.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		time.Sleep(20 * time.Millisecond)
+		return &generator.Response{Content: "slow:" + req.Model}, nil
+	}
+	candidates := []Route{
+		{Provider: "openai", Model: "gpt-4o"},
+		{Provider: "slow", Model: "gpt-4.1-mini"},
+		{Provider: "openai", Model: "deepseek-reasoner"},
+	}
+	r, err := New(
+		WithProvider("openai", named("openai")),
+		WithProvider("slow", slow),
+		WithPolicy("cheap", Cheapest(0), candidates...),
+		WithPolicy("cheap-reasoning", Cheapest(models.Reasoning), candidates...),
+		WithPolicy("cheap-vision-fast", WithinSLO(10*time.Millisecond, Cheapest(models.Vision)), candidates...),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	generate := func(model string, tools ...generator.Tool) (string, error) {
+		resp, err := r.Generate(context.Background(), &generator.Request{Model: model, Tools: tools})
+		if err != nil {
+			return "", err
+		}
+		return resp.Content, nil
+	}
+
+	if got, _ := generate("cheap"); got != "slow:gpt-4.1-mini" {
+		t.Errorf("Generate(cheap) = %s, want the cheapest model", got)
+	}
+	if got, _ := generate("cheap-reasoning"); got != "openai:deepseek-reasoner" {
+		t.Errorf("Generate(cheap-reasoning) = %s, want the cheapest reasoning model", got)
+	}
+	if _, err := generate("cheap-reasoning", generator.Tool{Name: "f"}); !errors.Is(err, llmerrors.ErrModelNotFound) {
+		t.Errorf("Generate(cheap-reasoning) with tools error = %v, want %v", err, llmerrors.ErrModelNotFound)
+	}
+	if s := r.Stats(candidates[1]); s.Requests != 1 || s.P95 < 20*time.Millisecond {
+		t.Errorf("Stats() = %+v, want a request of at least 20ms", s)
+	}
+	if got, _ := generate("cheap-vision-fast"); got != "openai:gpt-4o" {
+		t.Errorf("Generate(cheap-vision-fast) = %s, want the cheapest model within the SLO", got)
+	}
+}
+
+func TestWithinSLO(t *testing.T) {
+	candidates := []Candidate{
+		{Route: Route{Provider: "p", Model: "fast"}, Stats: Stats{Requests: 1, P95: time.Millisecond}},
+		{Route: Route{Provider: "p", Model: "tools-slow"}, Info: models.Info{Capabilities: models.Tools}, Stats: Stats{Requests: 1, P95: time.Second}},
+		{Route: Route{Provider: "p", Model: "tools-slower"}, Info: models.Info{Capabilities: models.Tools}, Stats: Stats{Requests: 1, P95: 2 * time.Second}},
+	}
+	p := WithinSLO(10*time.Millisecond, Cheapest(0))
+	req := &generator.Request{Tools: []generator.Tool{{Name: "f"}}}
+	if c, ok := p.Pick(req, candidates); !ok || c.Model != "tools-slow" {
+		t.Errorf("Pick() with tools = %v, %v, want the fastest model with tools", c.Model, ok)
+	}
+	if _, ok := p.Pick(req, candidates[:1]); ok {
+		t.Error("Pick() with tools among models without = true, want false")
+	}
+}
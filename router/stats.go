@@ -0,0 +1,79 @@
+package router
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// statsWindow is the number of recent requests per route that Stats covers
+const statsWindow = 100
+
+// Stats represents the rolling metrics of a route over its recent requests
+type Stats struct {
+	Requests int // Requests in the window
+	Errors   int
+	Mean     time.Duration // Latency of successful requests
+	P95      time.Duration
+}
+
+// window is a ring buffer of the latest request outcomes of a route
+type window struct {
+	latencies [statsWindow]time.Duration
+	failed    [statsWindow]bool
+	n, next   int
+}
+
+type collector struct {
+	mu      sync.Mutex
+	windows map[Route]*window
+}
+
+func (c *collector) observe(route Route, latency time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.windows == nil {
+		c.windows = map[Route]*window{}
+	}
+	w, ok := c.windows[route]
+	if !ok {
+		w = &window{}
+		c.windows[route] = w
+	}
+	w.latencies[w.next], w.failed[w.next] = latency, err != nil
+	w.next = (w.next + 1) % statsWindow
+	w.n = min(w.n+1, statsWindow)
+}
+
+func (c *collector) stats(route Route) Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w, ok := c.windows[route]
+	if !ok {
+		return Stats{}
+	}
+
+	s := Stats{Requests: w.n}
+	var succeeded []time.Duration
+	var total time.Duration
+	for i := 0; i < w.n; i++ {
+		if w.failed[i] {
+			s.Errors++
+			continue
+		}
+		succeeded = append(succeeded, w.latencies[i])
+		total += w.latencies[i]
+	}
+	if len(succeeded) > 0 {
+		sort.Slice(succeeded, func(i, j int) bool { return succeeded[i] < succeeded[j] })
+		s.Mean = total / time.Duration(len(succeeded))
+		s.P95 = succeeded[(len(succeeded)*95+99)/100-1]
+	}
+	return s
+}
+
+// Stats returns the rolling metrics of route, collected from the requests
+// the router sent
+func (r *Router) Stats(route Route) Stats {
+	return r.collector.stats(route)
+}
@@ -0,0 +1,170 @@
+// Package semcache provides a semantic response cache: a request whose last
+// user message is similar enough to one answered before gets the earlier
+// response, without calling the model. Give it to a client with
+// gollm.WithResponseCache, so it sees prompts after the input guardrails:
+//
+//	client := gollm.NewClient(llm, gollm.WithResponseCache(semcache.New(emb, store)))
+package semcache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/parikxxit/go-llm/cache"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// MetadataScore is the response metadata key holding the similarity of a
+// cache hit to the prompt it was cached for
+const MetadataScore = "semcache_score"
+
+const (
+	metaScope    = "semcache_scope"
+	metaCreated  = "semcache_created"
+	metaResponse = "semcache_response"
+)
+
+// Cache matches prompts by embedding similarity. The last user message is
+// embedded and compared; the rest of the conversation, the model, the system
+// prompt and the user must match exactly, so responses are not shared across
+// users. Responses with tool calls are not cached.
+type Cache struct {
+	embedder  embedder.Embedder
+	store     vectorstore.Store
+	model     string
+	threshold float64
+	ttl       time.Duration
+}
+
+// Option is a function that configures a Cache
+type Option func(*Cache)
+
+// WithEmbeddingModel sets the model embedding prompts
+func WithEmbeddingModel(model string) Option {
+	return func(c *Cache) {
+		c.model = model
+	}
+}
+
+// WithThreshold sets the minimum similarity score, as ranked by the store,
+// of a cache hit; 0.95 by default
+func WithThreshold(score float64) Option {
+	return func(c *Cache) {
+		c.threshold = score
+	}
+}
+
+// WithTTL ignores entries older than ttl; entries never expire by default
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// New creates a new semantic cache embedding prompts with emb, e.g.
+// rag.ClientEmbedder, and storing them in store
+func New(emb embedder.Embedder, store vectorstore.Store, opts ...Option) *Cache {
+	c := &Cache{
+		embedder:  emb,
+		store:     store,
+		threshold: 0.95,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Lookup returns the cached response to req, if any
+func (c *Cache) Lookup(ctx context.Context, req *generator.Request) (*generator.Response, bool, error) {
+	prompt, scope, ok := key(req)
+	if !ok {
+		return nil, false, nil
+	}
+	vector, err := c.embed(ctx, prompt)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, ok := c.lookup(ctx, vector, scope)
+	return resp, ok, nil
+}
+
+// Add caches resp as the response to req, unless it calls tools
+func (c *Cache) Add(ctx context.Context, req *generator.Request, resp *generator.Response) error {
+	prompt, scope, ok := key(req)
+	if !ok || len(resp.ToolCalls) > 0 {
+		return nil
+	}
+	vector, err := c.embed(ctx, prompt)
+	if err != nil {
+		return err
+	}
+	return c.add(ctx, prompt, scope, vector, resp)
+}
+
+func (c *Cache) lookup(ctx context.Context, vector []float32, scope string) (*generator.Response, bool) {
+	filter := vectorstore.Eq(metaScope, scope)
+	if c.ttl > 0 {
+		filter = vectorstore.And(filter, vectorstore.Gte(metaCreated, time.Now().Add(-c.ttl).Unix()))
+	}
+	matches, err := c.store.Query(ctx, vectorstore.Query{Vector: vector, TopK: 1, Filter: filter})
+	if err != nil || len(matches) == 0 || matches[0].Score < c.threshold {
+		return nil, false
+	}
+
+	raw, _ := matches[0].Document.Metadata[metaResponse].(string)
+	var resp generator.Response
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, false
+	}
+	resp.Cost = 0
+	resp.Metadata = map[string]string{MetadataScore: strconv.FormatFloat(matches[0].Score, 'f', 4, 64)}
+	return &resp, true
+}
+
+func (c *Cache) add(ctx context.Context, prompt, scope string, vector []float32, resp *generator.Response) error {
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return c.store.Upsert(ctx, vectorstore.Document{
+		ID:        cache.Key(scope, prompt),
+		Text:      prompt,
+		Embedding: vector,
+		Metadata: map[string]any{
+			metaScope:    scope,
+			metaCreated:  time.Now().Unix(),
+			metaResponse: string(raw),
+		},
+	})
+}
+
+func (c *Cache) embed(ctx context.Context, prompt string) ([]float32, error) {
+	docs := []vectorstore.Document{{Text: prompt}}
+	if err := vectorstore.Embed(ctx, c.embedder, c.model, docs); err != nil {
+		return nil, err
+	}
+	return docs[0].Embedding, nil
+}
+
+// key returns the last user message of req, compared semantically, and a
+// hash of everything else that must match exactly. Requests not ending with
+// a user message are not cached.
+func key(req *generator.Request) (prompt, scope string, ok bool) {
+	n := len(req.Messages)
+	if n == 0 || req.Messages[n-1].Role != generator.USER {
+		return "", "", false
+	}
+	rest := *req
+	rest.Messages = req.Messages[:n-1]
+	rest.Metadata, rest.IdempotencyKey = nil, ""
+	b, err := json.Marshal(rest)
+	if err != nil {
+		return "", "", false
+	}
+	return req.Messages[n-1].Content, cache.Key("semcache", string(b)), true
+}
@@ -0,0 +1,104 @@
+package semcache
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/embedder"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/guardrails"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// keywords embeds texts by which of a fixed vocabulary they mention
+type keywords []string
+
+func (k keywords) Embed(_ context.Context, req *embedder.Request) (*embedder.Response, error) {
+	resp := &embedder.Response{}
+	for i, text := range req.Input {
+		v := make([]float64, len(k))
+		for j, word := range k {
+			if strings.Contains(strings.ToLower(text), word) {
+				v[j] = 1
+			}
+		}
+		resp.Data = append(resp.Data, embedder.EmbedData{Embedding: v, Index: i})
+	}
+	return resp, nil
+}
+
+func (keywords) GetEmbedderName() string { return "keywords" }
+
+func TestCache_ResponseCache(t *testing.T) {
+	m := mock.New()
+	c := New(keywords{"refund", "shipping", "password"}, vectorstore.NewMemoryStore(vectorstore.Cosine))
+	client := gollm.NewClient(m, gollm.WithResponseCache(c))
+	ask := func(system, user, question string) *generator.Response {
+		t.Helper()
+		resp, err := client.Generate(context.Background(), &generator.Request{User: user, Messages: []generator.Message{
+			{Role: generator.SYSTEM, Content: system},
+			{Role: generator.USER, Content: question},
+		}})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		return resp
+	}
+
+	ask("support", "u1", "How do I get a refund?")
+	hit := ask("support", "u1", "Refund please")
+	if hit.Content != "How do I get a refund?" || hit.Metadata[MetadataScore] != "1.0000" {
+		t.Errorf("Generate() of a similar prompt = %+v, want the cached response", hit)
+	}
+	ask("support", "u1", "What about shipping?")
+	ask("sales", "u1", "Refund please")
+	ask("support", "u2", "Refund please")
+	if n := len(m.Requests()); n != 4 {
+		t.Errorf("provider requests = %d, want 4: one hit, and misses for another topic, system prompt and user", n)
+	}
+}
+
+// recorder records the texts embedded by keywords
+type recorder struct {
+	keywords
+	texts []string
+}
+
+func (r *recorder) Embed(ctx context.Context, req *embedder.Request) (*embedder.Response, error) {
+	r.texts = append(r.texts, req.Input...)
+	return r.keywords.Embed(ctx, req)
+}
+
+func TestCache_Guardrails(t *testing.T) {
+	emb := &recorder{keywords: keywords{"refund"}}
+	store := vectorstore.NewMemoryStore(vectorstore.Cosine)
+	p, err := guardrails.New(&guardrails.PII{Restore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := gollm.NewClient(mock.New(), gollm.WithGuardrails(p), gollm.WithResponseCache(New(emb, store)))
+
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "Refund jane@example.com"}}}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Generate(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		// The mock echoes the prompt, restored by the output guard
+		if resp.Content != "Refund jane@example.com" {
+			t.Errorf("Generate() #%d = %q, want the email restored", i, resp.Content)
+		}
+	}
+	for _, text := range emb.texts {
+		if strings.Contains(text, "jane@example.com") {
+			t.Errorf("embedded %q, want the email redacted", text)
+		}
+	}
+	matches, _ := store.Query(context.Background(), vectorstore.Query{Vector: []float32{1}, TopK: 1})
+	if len(matches) != 1 || strings.Contains(matches[0].Document.Metadata[metaResponse].(string), "jane@example.com") {
+		t.Errorf("cached %+v, want the redacted response", matches)
+	}
+}
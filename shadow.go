@@ -0,0 +1,97 @@
+package gollm
+
+import (
+	"context"
+	"maps"
+	"math/rand/v2"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/models"
+)
+
+// shadowMaxInFlight bounds concurrent mirrored requests; further mirrors are
+// dropped so a slow shadow cannot pile up goroutines
+const shadowMaxInFlight = 64
+
+// ShadowResult represents a request mirrored to the shadow generator, with
+// both responses for comparison
+type ShadowResult struct {
+	Request     *generator.Request
+	Primary     *generator.Response
+	PrimaryTime time.Duration
+	Shadow      *generator.Response // Nil when Err is set
+	ShadowTime  time.Duration
+	Err         error
+}
+
+type shadow struct {
+	generator generator.Generator
+	fraction  float64
+	compare   func(context.Context, ShadowResult)
+	inFlight  chan struct{}
+}
+
+// WithShadow mirrors fraction, between 0 and 1, of successful Generate
+// requests to g in the background, to evaluate a model on production
+// traffic. Callers get the primary response without waiting; compare, if
+// not nil, receives both responses, else they are logged at debug level.
+// Shadow requests are not retried, cached or counted against the budget,
+// and cache hits and streams are not mirrored.
+func WithShadow(g generator.Generator, fraction float64, compare func(context.Context, ShadowResult)) Option {
+	return func(c *Client) {
+		c.shadow = &shadow{
+			generator: g,
+			fraction:  fraction,
+			compare:   compare,
+			inFlight:  make(chan struct{}, shadowMaxInFlight),
+		}
+	}
+}
+
+// mirror sends request to the shadow generator when sampled
+func (c *Client) mirror(ctx context.Context, request *generator.Request, primary *generator.Response, elapsed time.Duration) {
+	s := c.shadow
+	if s == nil || rand.Float64() >= s.fraction {
+		return
+	}
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		c.logger.WarnContext(ctx, "shadow request dropped", "shadow", s.generator.GetName())
+		return
+	}
+
+	// The caller may change both once it has the response
+	req, prim := *request, *primary
+	prim.Metadata = maps.Clone(primary.Metadata)
+	request, primary = &req, &prim
+
+	// The caller returns right away, cancelling its context
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	go func() {
+		defer func() { <-s.inFlight }()
+		defer cancel()
+
+		start := time.Now()
+		resp, err := s.generator.Generate(ctx, prefilled(s.generator, request))
+		if err == nil {
+			resp = prefillResponse(request, c.stopResponse(request, resp))
+		}
+		r := ShadowResult{Request: request, Primary: primary, PrimaryTime: elapsed, Shadow: resp, ShadowTime: time.Since(start), Err: err}
+		if err == nil {
+			u := resp.Usage
+			resp.Cost = models.Cost(responseModel(resp.Model, request.Model), u.PromptTokens, u.CachedPromptTokens, u.CompletionTokens)
+		}
+		if s.compare != nil {
+			s.compare(ctx, r)
+			return
+		}
+		l := c.logger.With("shadow", s.generator.GetName(), "primary_time", r.PrimaryTime, "shadow_time", r.ShadowTime)
+		if err != nil {
+			l.DebugContext(ctx, "shadow request failed", "error", err)
+			return
+		}
+		l.DebugContext(ctx, "shadow response", "primary", primary.Content, "shadow_content", resp.Content)
+	}()
+}
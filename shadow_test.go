@@ -0,0 +1,56 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_WithShadow(t *testing.T) {
+	shadowed := mock.New()
+	shadowed.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		return &generator.Response{Content: "shadow", Model: "gpt-4o", Usage: generator.TokenUsage{PromptTokens: 1000000}}, nil
+	}
+	results := make(chan ShadowResult, 1)
+	client := NewClient(mock.New(), WithShadow(shadowed, 1, func(_ context.Context, r ShadowResult) { results <- r }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, err := client.Generate(ctx, &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+	cancel()
+	if err != nil || resp.Content != "hi" {
+		t.Fatalf("Generate() = %v, %v, want the primary response", resp, err)
+	}
+
+	select {
+	case r := <-results:
+		if r.Err != nil || r.Primary.Content != "hi" || r.Shadow.Content != "shadow" || r.Shadow.Cost != 2.5 {
+			t.Errorf("ShadowResult = %+v, shadow %+v", r, r.Shadow)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shadow request not sent")
+	}
+	if u := client.Usage(); u.Requests != 1 {
+		t.Errorf("Usage() = %+v, want the shadow request excluded", u)
+	}
+
+	client = NewClient(mock.New(), WithShadow(shadowed, 0, func(context.Context, ShadowResult) {
+		t.Error("request mirrored with a zero fraction")
+	}))
+	client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+
+	failing := mock.New()
+	failing.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return nil, errors.New("shadow down")
+	}
+	client = NewClient(mock.New(), WithShadow(failing, 1, func(_ context.Context, r ShadowResult) { results <- r }))
+	if _, err := client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}); err != nil {
+		t.Errorf("Generate() with a failing shadow error = %v", err)
+	}
+	if r := <-results; r.Err == nil {
+		t.Errorf("ShadowResult = %+v, want the shadow error", r)
+	}
+}
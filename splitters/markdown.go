@@ -0,0 +1,110 @@
+package splitters
+
+import (
+	"strings"
+
+	"github.com/parikxxit/go-llm/tokenizer"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// Markdown splits at headings so chunks never span sections, recursively
+// splitting sections larger than ChunkSize. Each chunk records the headings
+// it falls under, e.g. "Install > Linux", as its section.
+type Markdown struct {
+	Counter      tokenizer.Counter // Measures chunks in characters when nil
+	ChunkSize    int               // 1000 when zero
+	ChunkOverlap int
+}
+
+type section struct {
+	span
+	path string
+}
+
+func (m Markdown) Split(doc vectorstore.Document) []vectorstore.Document {
+	c := newChunker(m.Counter, m.ChunkSize, defaultChunkSize, m.ChunkOverlap)
+
+	var spans []span
+	paths := make(map[span]string)
+	for _, s := range sections(doc.Text) {
+		for _, piece := range c.recursive(doc.Text, s.start, s.end, DefaultSeparators) {
+			spans = append(spans, piece)
+			paths[piece] = s.path
+		}
+	}
+	return c.documents(doc, spans, func(s span) map[string]any {
+		if paths[s] == "" {
+			return nil
+		}
+		return map[string]any{MetadataSection: paths[s]}
+	})
+}
+
+// sections splits text at ATX headings outside fenced code blocks. Sections
+// holding only their heading are dropped.
+func sections(text string) []section {
+	var (
+		out      []section
+		headings []string // Heading text by level - 1
+		current  = section{}
+		fenced   bool
+		body     bool
+	)
+	flush := func(end int) {
+		current.end = end
+		if body && current.end > current.start {
+			out = append(out, current)
+		}
+	}
+
+	for pos := 0; pos < len(text); {
+		lineEnd := strings.IndexByte(text[pos:], '\n')
+		next := len(text)
+		if lineEnd >= 0 {
+			next = pos + lineEnd + 1
+		}
+		line := strings.TrimRight(text[pos:next], "\r\n")
+
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+		}
+		if level, title := heading(line); level > 0 && !fenced {
+			flush(pos)
+			if len(headings) >= level {
+				headings = headings[:level-1]
+			}
+			for len(headings) < level-1 {
+				headings = append(headings, "")
+			}
+			headings = append(headings, title)
+			current = section{span: span{start: pos}, path: path(headings)}
+			body = false
+		} else if strings.TrimSpace(line) != "" {
+			body = true
+		}
+		pos = next
+	}
+	flush(len(text))
+	return out
+}
+
+func heading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ') {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+}
+
+func path(headings []string) string {
+	var parts []string
+	for _, h := range headings {
+		if h != "" {
+			parts = append(parts, h)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
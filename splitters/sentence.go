@@ -0,0 +1,72 @@
+package splitters
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/parikxxit/go-llm/tokenizer"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// Sentence packs whole sentences into chunks of at most ChunkSize, so chunks
+// never end mid-sentence unless a single sentence is larger than a chunk.
+// Overlap is made of whole sentences too.
+type Sentence struct {
+	Counter      tokenizer.Counter // Measures chunks in characters when nil
+	ChunkSize    int               // 1000 when zero
+	ChunkOverlap int
+}
+
+func (s Sentence) Split(doc vectorstore.Document) []vectorstore.Document {
+	c := newChunker(s.Counter, s.ChunkSize, defaultChunkSize, s.ChunkOverlap)
+
+	var pieces []span
+	for _, sentence := range sentences(doc.Text) {
+		if c.length(doc.Text[sentence.start:sentence.end]) > c.size {
+			pieces = append(pieces, c.recursive(doc.Text, sentence.start, sentence.end, DefaultSeparators[2:])...)
+			continue
+		}
+		pieces = append(pieces, sentence)
+	}
+	return c.documents(doc, c.merge(doc.Text, pieces), nil)
+}
+
+// sentences splits text after terminal punctuation followed by whitespace
+// and at paragraph breaks. Trailing whitespace stays with the sentence.
+func sentences(text string) []span {
+	var out []span
+	start := 0
+	for pos := 0; pos < len(text); {
+		r, n := utf8.DecodeRuneInString(text[pos:])
+		pos += n
+
+		end := false
+		switch {
+		case r == '.' || r == '!' || r == '?':
+			// Include closing quotes and brackets
+			for pos < len(text) && (text[pos] == '"' || text[pos] == '\'' || text[pos] == ')') {
+				pos++
+			}
+			next, _ := utf8.DecodeRuneInString(text[pos:])
+			end = pos == len(text) || unicode.IsSpace(next)
+		case r == '\n':
+			end = pos < len(text) && text[pos] == '\n'
+		}
+		if !end {
+			continue
+		}
+		for pos < len(text) {
+			next, n := utf8.DecodeRuneInString(text[pos:])
+			if !unicode.IsSpace(next) {
+				break
+			}
+			pos += n
+		}
+		out = append(out, span{start, pos})
+		start = pos
+	}
+	if start < len(text) {
+		out = append(out, span{start, len(text)})
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+// Package splitters breaks documents into chunks sized for embedding, each
+// carrying its position in the source document for citation.
+package splitters
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/parikxxit/go-llm/tokenizer"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// Metadata keys set on chunks, in addition to the metadata of the parent
+const (
+	MetadataParentID = "parent_id"
+	MetadataChunk    = "chunk" // 0-based index of the chunk in the parent
+	MetadataStart    = "start" // Byte offset of the chunk in the parent text
+	MetadataEnd      = "end"   // Byte offset just past the chunk
+	MetadataSection  = "section"
+)
+
+const (
+	defaultChunkSize      = 1000
+	defaultTokenChunkSize = 512
+)
+
+// DefaultSeparators are tried in order by the recursive splitters: paragraphs,
+// lines, words and finally single characters
+var DefaultSeparators = []string{"\n\n", "\n", " ", ""}
+
+// Splitter splits a document into chunks
+type Splitter interface {
+	Split(doc vectorstore.Document) []vectorstore.Document
+}
+
+// SplitAll splits every document with s
+func SplitAll(s Splitter, docs []vectorstore.Document) []vectorstore.Document {
+	var chunks []vectorstore.Document
+	for _, d := range docs {
+		chunks = append(chunks, s.Split(d)...)
+	}
+	return chunks
+}
+
+// Recursive splits on the first separator that occurs in the text and
+// recursively splits pieces that are still too large with the following
+// separators, then merges pieces into chunks of at most ChunkSize characters
+type Recursive struct {
+	ChunkSize    int // 1000 when zero
+	ChunkOverlap int
+	Separators   []string // DefaultSeparators when empty
+}
+
+func (r Recursive) Split(doc vectorstore.Document) []vectorstore.Document {
+	c := newChunker(nil, r.ChunkSize, defaultChunkSize, r.ChunkOverlap)
+	return c.documents(doc, c.recursive(doc.Text, 0, len(doc.Text), separators(r.Separators)), nil)
+}
+
+// Token works like Recursive but measures chunks in tokens
+type Token struct {
+	Counter      tokenizer.Counter // tokenizer.Estimate when nil
+	ChunkSize    int               // 512 when zero
+	ChunkOverlap int
+	Separators   []string // DefaultSeparators when empty
+}
+
+func (t Token) Split(doc vectorstore.Document) []vectorstore.Document {
+	counter := t.Counter
+	if counter == nil {
+		counter = tokenizer.Estimate
+	}
+	c := newChunker(counter, t.ChunkSize, defaultTokenChunkSize, t.ChunkOverlap)
+	return c.documents(doc, c.recursive(doc.Text, 0, len(doc.Text), separators(t.Separators)), nil)
+}
+
+func separators(s []string) []string {
+	if len(s) == 0 {
+		return DefaultSeparators
+	}
+	return s
+}
+
+type span struct {
+	start, end int
+}
+
+// chunker holds the sizing shared by the splitters
+type chunker struct {
+	length  func(string) int
+	size    int
+	overlap int
+}
+
+// newChunker measures with counter, or in characters when it is nil
+func newChunker(counter tokenizer.Counter, size, defaultSize, overlap int) chunker {
+	c := chunker{length: utf8.RuneCountInString, size: size, overlap: overlap}
+	if counter != nil {
+		c.length = counter.Count
+	}
+	if c.size <= 0 {
+		c.size = defaultSize
+	}
+	if c.overlap >= c.size {
+		c.overlap = c.size / 2
+	}
+	return c
+}
+
+// recursive splits text[start:end] into contiguous pieces of at most size,
+// keeping separators at the end of the piece they follow so offsets stay exact
+func (c chunker) recursive(text string, start, end int, seps []string) []span {
+	if c.length(text[start:end]) <= c.size {
+		return []span{{start, end}}
+	}
+	if len(seps) == 0 || seps[0] == "" {
+		return c.hardSplit(text, start, end)
+	}
+	sep := seps[0]
+	if !strings.Contains(text[start:end], sep) {
+		return c.recursive(text, start, end, seps[1:])
+	}
+
+	var pieces []span
+	for pos := start; pos < end; {
+		next := strings.Index(text[pos:end], sep)
+		stop := end
+		if next >= 0 {
+			stop = pos + next + len(sep)
+		}
+		pieces = append(pieces, c.recursive(text, pos, stop, seps[1:])...)
+		pos = stop
+	}
+	return c.merge(text, pieces)
+}
+
+// hardSplit cuts text[start:end] at rune boundaries into pieces of at most size
+func (c chunker) hardSplit(text string, start, end int) []span {
+	var pieces []span
+	for pos := start; pos < end; {
+		stop := pos
+		for stop < end {
+			_, n := utf8.DecodeRuneInString(text[stop:end])
+			if stop > pos && c.length(text[pos:stop+n]) > c.size {
+				break
+			}
+			stop += n
+		}
+		pieces = append(pieces, span{pos, stop})
+		pos = stop
+	}
+	return pieces
+}
+
+// merge joins adjacent pieces into chunks of at most size. Each chunk starts
+// with trailing pieces of the previous one totalling at most overlap.
+func (c chunker) merge(text string, pieces []span) []span {
+	var chunks, current []span
+	for _, p := range pieces {
+		if len(current) > 0 && c.length(text[current[0].start:p.end]) > c.size {
+			chunks = append(chunks, span{current[0].start, current[len(current)-1].end})
+			for len(current) > 0 && (c.length(text[current[0].start:current[len(current)-1].end]) > c.overlap ||
+				c.length(text[current[0].start:p.end]) > c.size) {
+				current = current[1:]
+			}
+		}
+		current = append(current, p)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, span{current[0].start, current[len(current)-1].end})
+	}
+	return chunks
+}
+
+// documents turns spans of doc.Text into chunk documents, trimming
+// surrounding whitespace and dropping empty chunks
+func (c chunker) documents(doc vectorstore.Document, spans []span, extra func(span) map[string]any) []vectorstore.Document {
+	var chunks []vectorstore.Document
+	for _, s := range spans {
+		raw := doc.Text[s.start:s.end]
+		trimmed := strings.TrimLeft(raw, " \t\r\n")
+		start := s.start + len(raw) - len(trimmed)
+		trimmed = strings.TrimRight(trimmed, " \t\r\n")
+		if trimmed == "" {
+			continue
+		}
+
+		metadata := make(map[string]any, len(doc.Metadata)+4)
+		for k, v := range doc.Metadata {
+			metadata[k] = v
+		}
+		if extra != nil {
+			for k, v := range extra(s) {
+				metadata[k] = v
+			}
+		}
+		n := len(chunks)
+		metadata[MetadataParentID] = doc.ID
+		metadata[MetadataChunk] = n
+		metadata[MetadataStart] = start
+		metadata[MetadataEnd] = start + len(trimmed)
+		chunks = append(chunks, vectorstore.Document{
+			ID:       fmt.Sprintf("%s:%d", doc.ID, n),
+			Text:     trimmed,
+			Metadata: metadata,
+		})
+	}
+	return chunks
+}
@@ -0,0 +1,106 @@
+package splitters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/tokenizer"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// checkOffsets verifies every chunk is the parent text at its offsets
+func checkOffsets(t *testing.T, doc vectorstore.Document, chunks []vectorstore.Document) {
+	t.Helper()
+	for i, c := range chunks {
+		start, end := c.Metadata[MetadataStart].(int), c.Metadata[MetadataEnd].(int)
+		if doc.Text[start:end] != c.Text {
+			t.Errorf("chunk %d text %q does not match offsets [%d:%d]", i, c.Text, start, end)
+		}
+		if c.Metadata[MetadataParentID] != doc.ID || c.Metadata[MetadataChunk] != i {
+			t.Errorf("chunk %d metadata = %v", i, c.Metadata)
+		}
+	}
+}
+
+func texts(chunks []vectorstore.Document) []string {
+	out := make([]string, len(chunks))
+	for i, c := range chunks {
+		out[i] = c.Text
+	}
+	return out
+}
+
+func TestRecursive_Split(t *testing.T) {
+	doc := vectorstore.Document{
+		ID:       "doc",
+		Text:     "First paragraph here.\n\nSecond paragraph is a bit longer than the first one.\n\nThird.",
+		Metadata: map[string]any{"source": "a.txt"},
+	}
+	chunks := Recursive{ChunkSize: 30}.Split(doc)
+	checkOffsets(t, doc, chunks)
+
+	for _, c := range chunks {
+		if n := len([]rune(c.Text)); n > 30 {
+			t.Errorf("chunk %q has %d characters, want at most 30", c.Text, n)
+		}
+		if c.Metadata["source"] != "a.txt" {
+			t.Errorf("chunk metadata = %v, want parent metadata", c.Metadata)
+		}
+	}
+	if chunks[0].Text != "First paragraph here." || chunks[len(chunks)-1].Text != "Third." {
+		t.Errorf("Split() = %q", texts(chunks))
+	}
+	if chunks[1].ID != "doc:1" {
+		t.Errorf("chunk ID = %s, want doc:1", chunks[1].ID)
+	}
+}
+
+func TestRecursive_Overlap(t *testing.T) {
+	doc := vectorstore.Document{ID: "d", Text: "one two three four five six seven eight"}
+	chunks := Recursive{ChunkSize: 14, ChunkOverlap: 6}.Split(doc)
+	checkOffsets(t, doc, chunks)
+
+	want := []string{"one two three", "three four", "four five six", "six seven", "seven eight"}
+	if got := texts(chunks); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Split() = %q, want %q", got, want)
+	}
+}
+
+func TestToken_Split(t *testing.T) {
+	words := tokenizer.CounterFunc(func(s string) int { return len(strings.Fields(s)) })
+	doc := vectorstore.Document{ID: "d", Text: "a b c d e f g"}
+	chunks := Token{Counter: words, ChunkSize: 3}.Split(doc)
+	checkOffsets(t, doc, chunks)
+
+	if got := strings.Join(texts(chunks), "|"); got != "a b c|d e f|g" {
+		t.Errorf("Split() = %s, want a b c|d e f|g", got)
+	}
+}
+
+func TestMarkdown_Split(t *testing.T) {
+	doc := vectorstore.Document{ID: "d", Text: "# Guide\nIntro text.\n## Install\nRun it.\n```\n# not a heading\n```\n## Empty\n# Usage\nUse it.\n"}
+	chunks := Markdown{ChunkSize: 100}.Split(doc)
+	checkOffsets(t, doc, chunks)
+
+	var sections []string
+	for _, c := range chunks {
+		sections = append(sections, c.Metadata[MetadataSection].(string))
+	}
+	if got := strings.Join(sections, "|"); got != "Guide|Guide > Install|Usage" {
+		t.Errorf("sections = %s", got)
+	}
+	if !strings.Contains(chunks[1].Text, "# not a heading") {
+		t.Errorf("chunk = %q, want fenced code kept in its section", chunks[1].Text)
+	}
+}
+
+func TestSentence_Split(t *testing.T) {
+	doc := vectorstore.Document{ID: "d", Text: `Dr. Who arrived. "Hello!" he said. Is it late? No.`}
+	chunks := Sentence{ChunkSize: 20}.Split(doc)
+	checkOffsets(t, doc, chunks)
+
+	want := []string{"Dr. Who arrived.", `"Hello!" he said.`, "Is it late? No."}
+	if got := texts(chunks); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Split() = %q, want %q", got, want)
+	}
+}
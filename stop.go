@@ -0,0 +1,123 @@
+package gollm
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// WithStopEnforcement trims Generate and GenerateStream content at the first
+// of Request.Stop, for providers ignoring stop sequences or limiting their
+// number. Trimmed responses finish with "stop". Streams hold back content
+// that may start a stop sequence until the next chunk tells, so sequences
+// split across chunks are found too. The provider may keep generating after
+// a stop sequence; later chunks are dropped, except their usage.
+func WithStopEnforcement() Option {
+	return func(c *Client) {
+		c.stopEnforcement = true
+	}
+}
+
+// stopIndex returns the index in s of the first of stops, or -1
+func stopIndex(s string, stops []string) int {
+	first := -1
+	for _, stop := range stops {
+		if stop == "" {
+			continue
+		}
+		if i := strings.Index(s, stop); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	return first
+}
+
+// stopPrefix returns the length of the longest suffix of s that starts one
+// of stops
+func stopPrefix(s string, stops []string) int {
+	longest := 0
+	for _, stop := range stops {
+		for n := min(len(s), len(stop)-1); n > longest; n-- {
+			if strings.HasPrefix(stop, s[len(s)-n:]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
+
+// stopResponse returns resp trimmed at the first stop sequence of request,
+// when enforced
+func (c *Client) stopResponse(request *generator.Request, resp *generator.Response) *generator.Response {
+	if !c.stopEnforcement {
+		return resp
+	}
+	i := stopIndex(resp.Content, request.Stop)
+	if i < 0 {
+		return resp
+	}
+	r := *resp
+	r.Content, r.FinishReason = r.Content[:i], "stop"
+	return &r
+}
+
+// stopStream forwards stream trimmed at the first stop sequence of request,
+// when enforced
+func (c *Client) stopStream(ctx context.Context, request *generator.Request, stream <-chan *generator.Response) <-chan *generator.Response {
+	if !c.stopEnforcement || !slices.ContainsFunc(request.Stop, func(s string) bool { return s != "" }) {
+		return stream
+	}
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+		send := func(chunk *generator.Response) bool {
+			select {
+			case out <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var held string
+		stopped := false
+		for chunk := range stream {
+			if stopped {
+				// Only usage is of interest past the stop sequence
+				if chunk.Usage != (generator.TokenUsage{}) && !send(&generator.Response{ID: chunk.ID, Object: chunk.Object, Model: chunk.Model, Usage: chunk.Usage}) {
+					return
+				}
+				continue
+			}
+			if chunk.Err != nil {
+				if held != "" && !send(&generator.Response{Content: held}) {
+					return
+				}
+				send(chunk)
+				return
+			}
+
+			next := *chunk
+			text := held + next.Content
+			if i := stopIndex(text, request.Stop); i >= 0 {
+				next.Content, next.FinishReason, held, stopped = text[:i], "stop", "", true
+			} else {
+				n := 0
+				if next.FinishReason == "" {
+					n = stopPrefix(text, request.Stop)
+				}
+				next.Content, held = text[:len(text)-n], text[len(text)-n:]
+			}
+			if !send(&next) {
+				return
+			}
+		}
+		if held != "" {
+			send(&generator.Response{Content: held})
+		}
+	}()
+	return out
+}
@@ -0,0 +1,82 @@
+package gollm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestStopPrefix(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"Hello", 0},
+		{"Hello E", 1},
+		{"Hello EN", 2},
+		{"Hello END", 0},
+		{"Hello #", 1},
+	}
+	for _, tt := range tests {
+		if got := stopPrefix(tt.s, []string{"END", "#!"}); got != tt.want {
+			t.Errorf("stopPrefix(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestClient_WithStopEnforcement(t *testing.T) {
+	req := &generator.Request{Stop: []string{"END", "\n\n"}, Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+	usage := generator.TokenUsage{PromptTokens: 3, CompletionTokens: 5, TotalTokens: 8}
+
+	m := mock.New().Enqueue(mock.Text("Hello\n\nworld END"))
+	resp, err := NewClient(m, WithStopEnforcement()).Generate(context.Background(), req)
+	if err != nil || resp.Content != "Hello" || resp.FinishReason != "stop" {
+		t.Errorf("Generate() = %+v, %v, want Hello finishing with stop", resp, err)
+	}
+
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{"within a chunk", []string{"Hello END", " more"}, "Hello "},
+		{"across chunks", []string{"Hello E", "N", "D more"}, "Hello "},
+		{"no stop", []string{"Hello E", "N"}, "Hello EN"},
+	}
+	for _, tt := range tests {
+		reply := mock.Stream(tt.chunks...)
+		reply.Chunks = append(reply.Chunks, &generator.Response{Usage: usage})
+		m := mock.New().Enqueue(reply)
+		stream, err := NewClient(m, WithStopEnforcement()).GenerateStream(context.Background(), req)
+		if err != nil {
+			t.Fatalf("%s: GenerateStream() error = %v", tt.name, err)
+		}
+		var got, finish string
+		var gotUsage generator.TokenUsage
+		for chunk := range stream {
+			if chunk.Err != nil {
+				t.Fatalf("%s: stream error = %v", tt.name, chunk.Err)
+			}
+			got += chunk.Content
+			if chunk.FinishReason != "" {
+				finish = chunk.FinishReason
+			}
+			if chunk.Usage != (generator.TokenUsage{}) {
+				gotUsage = chunk.Usage
+			}
+		}
+		if got != tt.want || gotUsage != usage {
+			t.Errorf("%s: stream = %q with usage %+v, want %q with %+v", tt.name, got, gotUsage, tt.want, usage)
+		}
+		if stopped := got != "Hello EN"; stopped != (finish == "stop") {
+			t.Errorf("%s: finish reason = %q", tt.name, finish)
+		}
+	}
+
+	m = mock.New().Enqueue(mock.Text("Hello END"))
+	if resp, _ := NewClient(m).Generate(context.Background(), req); resp.Content != "Hello END" {
+		t.Errorf("Generate() without enforcement = %q, want Hello END", resp.Content)
+	}
+}
@@ -0,0 +1,187 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/llmerrors"
+)
+
+// ErrStreamIdle is delivered as the final chunk of a stream that received
+// no chunk within its idle timeout
+var ErrStreamIdle = errors.New("stream idle timeout")
+
+// WithStreamResume re-issues a stream that failed mid-way up to attempts
+// times. The content received so far is sent as a trailing assistant message
+// for the model to continue, so resuming after content has arrived requires
+// a generator.PrefillSupporter; otherwise the error chunk is delivered as is.
+// Tool calls in progress when the stream failed are lost.
+func WithStreamResume(attempts int) Option {
+	return func(c *Client) {
+		c.streamResumes = attempts
+	}
+}
+
+// resumeStream forwards the chunks of stream, resuming it after mid-stream
+// errors
+func (c *Client) resumeStream(ctx context.Context, g generator.Generator, request *generator.Request, stream <-chan *generator.Response) <-chan *generator.Response {
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+
+		var prefix strings.Builder
+		for resumes := 0; ; resumes++ {
+			var failed *generator.Response
+			for chunk := range stream {
+				if chunk.Err != nil {
+					failed = chunk
+					break
+				}
+				prefix.WriteString(chunk.Content)
+				select {
+				case out <- chunk:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if failed == nil {
+				return
+			}
+			if resumes >= c.streamResumes || !resumable(ctx, g, failed.Err, prefix.Len() > 0) {
+				select {
+				case out <- failed:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			c.logger.InfoContext(ctx, "resuming stream", "provider", g.GetName(), "model", request.Model,
+				"attempt", resumes+1, "prefix", prefix.Len(), "error", failed.Err)
+			req := *request
+			req.Prefill = request.Prefill + prefix.String()
+			var err error
+			stream, err = retry(ctx, c, Event{Operation: OpGenerateStream, Provider: g.GetName(), Model: req.Model, Metadata: req.Metadata, Request: &req}, func() (<-chan *generator.Response, error) {
+				if err := c.waitRateLimit(ctx, g.GetName(), generateTokens(&req)); err != nil {
+					return nil, err
+				}
+				return c.openStream(ctx, g, &req)
+			})
+			if err != nil {
+				failed.Err = err
+				select {
+				case out <- failed:
+				case <-ctx.Done():
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// watchStream forwards stream, the context of which is ctx, derived from
+// the caller's parent. It fails the stream with ErrStreamIdle when no chunk
+// arrives within idle, unless zero, and calls cancel once done. Time spent
+// waiting for the consumer does not count as idle.
+func watchStream(parent, ctx context.Context, cancel context.CancelFunc, idle time.Duration, stream <-chan *generator.Response) <-chan *generator.Response {
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+		defer cancel()
+
+		// fail delivers err unless the caller is gone or an error was
+		// delivered already
+		failed := false
+		fail := func(err error) {
+			if failed {
+				return
+			}
+			select {
+			case out <- &generator.Response{Err: err}:
+			case <-parent.Done():
+			}
+		}
+		var timeout <-chan time.Time
+		timer := time.NewTimer(idle)
+		defer timer.Stop()
+		if idle > 0 {
+			timeout = timer.C
+		}
+		for {
+			select {
+			case chunk, ok := <-stream:
+				if !ok {
+					return
+				}
+				// Drain a timer that fired meanwhile, or Reset leaves its
+				// stale expiry in the channel
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				// The chunk is the consumer's once sent, see
+				// generator.ReleaseChunk
+				isErr := chunk.Err != nil
+				select {
+				case out <- chunk:
+					failed = isErr
+				case <-ctx.Done():
+					if parent.Err() == nil {
+						fail(ctx.Err())
+					}
+					return
+				}
+				timer.Reset(idle)
+			case <-timeout:
+				fail(ErrStreamIdle)
+				return
+			case <-ctx.Done():
+				// Past the stream deadline rather than cancelled by the caller
+				if parent.Err() == nil {
+					fail(ctx.Err())
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// openStream starts a stream holding a concurrency slot until it closes
+func (c *Client) openStream(ctx context.Context, g generator.Generator, request *generator.Request) (<-chan *generator.Response, error) {
+	request = prefilled(g, request)
+	if c.concurrency == nil {
+		return g.GenerateStream(ctx, request)
+	}
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := g.GenerateStream(ctx, request)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return releaseStream(ctx, release, stream), nil
+}
+
+// resumable reports whether a stream of g that failed with err can be
+// re-issued. Transport errors are, API errors only when transient.
+func resumable(ctx context.Context, g generator.Generator, err error, prefill bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var e *llmerrors.Error
+	if errors.As(err, &e) && !llmerrors.Retryable(err) {
+		return false
+	}
+	if prefill {
+		return supportsPrefill(g)
+	}
+	return true
+}
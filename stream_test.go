@@ -0,0 +1,192 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+// flakyStreamer streams "Hello" as two parts, failing after the first part
+// of the first stream
+type flakyStreamer struct {
+	prefill  bool
+	requests []*generator.Request
+}
+
+func (f *flakyStreamer) Generate(context.Context, *generator.Request) (*generator.Response, error) {
+	return &generator.Response{Content: "Hello"}, nil
+}
+
+func (f *flakyStreamer) GenerateStream(_ context.Context, req *generator.Request) (<-chan *generator.Response, error) {
+	f.requests = append(f.requests, req)
+	out := make(chan *generator.Response, 2)
+	if last := req.Messages[len(req.Messages)-1]; last.Role == generator.ASSISTANT {
+		out <- &generator.Response{Content: strings.TrimPrefix("Hello", last.Content)}
+	} else {
+		out <- &generator.Response{Content: "Hel"}
+		out <- &generator.Response{Err: io.ErrUnexpectedEOF}
+	}
+	close(out)
+	return out, nil
+}
+
+func (f *flakyStreamer) SupportsPrefill() bool { return f.prefill }
+
+func (f *flakyStreamer) GetName() string { return "flaky" }
+
+func collect(t *testing.T, stream <-chan *generator.Response) (string, error) {
+	t.Helper()
+	var content strings.Builder
+	for chunk := range stream {
+		if chunk.Err != nil {
+			return content.String(), chunk.Err
+		}
+		content.WriteString(chunk.Content)
+	}
+	return content.String(), nil
+}
+
+func TestClient_WithStreamResume(t *testing.T) {
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "greet"}}}
+
+	tests := []struct {
+		name     string
+		prefill  bool
+		opts     []Option
+		want     string
+		wantErr  bool
+		requests int
+	}{
+		{"no resume", true, nil, "Hel", true, 1},
+		{"resume", true, []Option{WithStreamResume(1)}, "Hello", false, 2},
+		{"no prefill support", false, []Option{WithStreamResume(1)}, "Hel", true, 1},
+	}
+	for _, tt := range tests {
+		gen := &flakyStreamer{prefill: tt.prefill}
+		stream, err := NewClient(gen, tt.opts...).GenerateStream(context.Background(), req)
+		if err != nil {
+			t.Fatalf("%s: GenerateStream() error = %v", tt.name, err)
+		}
+		got, err := collect(t, stream)
+		if got != tt.want || (err != nil) != tt.wantErr || len(gen.requests) != tt.requests {
+			t.Errorf("%s: stream = %q, %v after %d requests, want %q", tt.name, got, err, len(gen.requests), tt.want)
+		}
+	}
+}
+
+func TestClient_WithStreamResume_Provider(t *testing.T) {
+	// Prefill support is the one of the generator streaming, not the primary
+	gen := &flakyStreamer{prefill: true}
+	client := NewClient(mock.New(), WithFallbackGenerators([]generator.Generator{gen}), WithStreamResume(1))
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "greet"}}}
+	stream, err := client.GenerateStream(context.Background(), req, CallWithProvider(gen.GetName()))
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	if got, err := collect(t, stream); got != "Hello" || err != nil || len(gen.requests) != 2 {
+		t.Errorf("stream = %q, %v after %d requests, want Hello resumed", got, err, len(gen.requests))
+	}
+}
+
+// tickingStreamer streams n chunks, one every interval, honoring ctx
+type tickingStreamer struct {
+	n        int
+	interval time.Duration
+	stall    time.Duration // Extra wait before the last chunk
+}
+
+func (s *tickingStreamer) Generate(context.Context, *generator.Request) (*generator.Response, error) {
+	return &generator.Response{Content: "tick"}, nil
+}
+
+func (s *tickingStreamer) GenerateStream(ctx context.Context, _ *generator.Request) (<-chan *generator.Response, error) {
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+		for i := 0; i < s.n; i++ {
+			wait := s.interval
+			if i == s.n-1 {
+				wait += s.stall
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return
+			}
+			out <- &generator.Response{Content: "."}
+		}
+	}()
+	return out, nil
+}
+
+func (s *tickingStreamer) GetName() string { return "ticking" }
+
+func TestClient_StreamIdleTimeout(t *testing.T) {
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+
+	// Streams outlive the timeout as long as chunks keep arriving
+	client := NewClient(&tickingStreamer{n: 8, interval: 10 * time.Millisecond}, WithTimeout(30*time.Millisecond))
+	stream, err := client.GenerateStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	if got, err := collect(t, stream); got != "........" || err != nil {
+		t.Errorf("GenerateStream() = %q, %v, want 8 chunks", got, err)
+	}
+
+	client = NewClient(&tickingStreamer{n: 3, interval: time.Millisecond, stall: time.Second},
+		WithOperationTimeout(OpGenerateStream, 30*time.Millisecond))
+	stream, _ = client.GenerateStream(context.Background(), req)
+	if got, err := collect(t, stream); got != ".." || !errors.Is(err, ErrStreamIdle) {
+		t.Errorf("GenerateStream() of a stalled stream = %q, %v, want %v after 2 chunks", got, err, ErrStreamIdle)
+	}
+}
+
+func TestClient_WithOperationTimeout(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(ctx context.Context, _ *generator.Request) (*generator.Response, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	client := NewClient(m, WithTimeout(time.Hour), WithOperationTimeout(OpGenerate, 10*time.Millisecond))
+	start := time.Now()
+	_, err := client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Errorf("Generate() error = %v after %v, want the generate timeout", err, time.Since(start))
+	}
+}
+
+func TestClient_GenerateStream_ReleaseChunk(t *testing.T) {
+	var chunks []*generator.Response
+	for _, s := range []string{"a", "b", "c"} {
+		chunk := generator.AcquireChunk()
+		chunk.Content = s
+		chunks = append(chunks, chunk)
+	}
+	chunks[2].Usage = generator.TokenUsage{CompletionTokens: 3}
+	m := mock.New().Enqueue(mock.Reply{Chunks: chunks}, mock.Reply{StreamErr: errors.New("reset")})
+	client := NewClient(m, WithStopEnforcement(), WithStreamBuffer(2, BackpressureCoalesce))
+
+	// Released chunks are zeroed, so a stage reading one after sending it
+	// would see it change, which the race detector reports
+	for i := 0; i < 2; i++ {
+		stream, err := client.GenerateStream(context.Background(), &generator.Request{Stop: []string{"x"}})
+		if err != nil {
+			t.Fatalf("GenerateStream() error = %v", err)
+		}
+		var content strings.Builder
+		for chunk := range stream {
+			content.WriteString(chunk.Content)
+			generator.ReleaseChunk(chunk)
+		}
+		if i == 0 && content.String() != "abc" {
+			t.Errorf("content = %q, want abc", content.String())
+		}
+	}
+}
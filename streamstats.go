@@ -0,0 +1,98 @@
+package gollm
+
+import (
+	"context"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// streamTimer accumulates the timing of a stream started at start
+type streamTimer struct {
+	start       time.Time
+	first, last time.Time
+	// token is the time of the last chunk of content, reasoning or tool
+	// calls
+	token  time.Time
+	gaps   time.Duration
+	maxGap time.Duration
+	nGaps  int
+	chunks int
+}
+
+// observe records chunk, received at now
+func (t *streamTimer) observe(chunk *generator.Response, now time.Time) {
+	t.chunks++
+	t.last = now
+	if chunk.Content == "" && chunk.Reasoning == "" && len(chunk.ToolCalls) == 0 {
+		return
+	}
+	if t.first.IsZero() {
+		t.first = now
+	} else {
+		gap := now.Sub(t.token)
+		t.gaps += gap
+		t.maxGap = max(t.maxGap, gap)
+		t.nGaps++
+	}
+	t.token = now
+}
+
+// stats returns the timing so far of a stream generating completionTokens
+func (t *streamTimer) stats(completionTokens int) *generator.StreamStats {
+	s := &generator.StreamStats{Chunks: t.chunks, MaxInterChunk: t.maxGap}
+	if !t.last.IsZero() {
+		s.Duration = t.last.Sub(t.start)
+	}
+	if t.first.IsZero() {
+		return s
+	}
+	s.TimeToFirstToken = t.first.Sub(t.start)
+	if t.nGaps > 0 {
+		s.MeanInterChunk = t.gaps / time.Duration(t.nGaps)
+	}
+	if d := t.last.Sub(t.first); d > 0 {
+		s.OutputTokensPerSecond = float64(completionTokens) / d.Seconds()
+	}
+	return s
+}
+
+// timeStream forwards stream, opened by g from start, setting the timing so
+// far on the chunk carrying the usage and calling OnStreamEnd once it closes
+func (c *Client) timeStream(ctx context.Context, start time.Time, g generator.Generator, request *generator.Request, stream <-chan *generator.Response) <-chan *generator.Response {
+	out := make(chan *generator.Response)
+	go func() {
+		defer close(out)
+
+		t := &streamTimer{start: start}
+		e := Event{Operation: OpGenerateStream, Provider: g.GetName(), Model: request.Model, Start: start, Metadata: request.Metadata}
+		defer func() {
+			e.Stream = t.stats(e.CompletionTokens)
+			e.Latency = e.Stream.Duration
+			c.emit(ctx, hookStreamEnd, e)
+		}()
+		for chunk := range stream {
+			t.observe(chunk, time.Now())
+			if chunk.Err != nil && e.Err == nil {
+				e.Err = chunk.Err
+			}
+			if chunk.ID != "" {
+				e.RequestID = chunk.ID
+			}
+			e.Model = responseModel(chunk.Model, e.Model)
+			if u := chunk.Usage; u != (generator.TokenUsage{}) {
+				e.PromptTokens, e.CompletionTokens, e.TotalTokens = u.PromptTokens, u.CompletionTokens, u.TotalTokens
+				chunk.StreamStats = t.stats(u.CompletionTokens)
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				if e.Err == nil {
+					e.Err = ctx.Err()
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,62 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_StreamStats(t *testing.T) {
+	var ends []Event
+	m := mock.New()
+	m.Latency = 20 * time.Millisecond
+	m.Enqueue(mock.Reply{
+		Chunks: []*generator.Response{
+			{Content: "a"},
+			{Content: "b"},
+			{Content: "c", FinishReason: "stop"},
+			{Usage: generator.TokenUsage{PromptTokens: 2, CompletionTokens: 30, TotalTokens: 32}},
+		},
+		ChunkDelay: 10 * time.Millisecond,
+	}, mock.Reply{Chunks: []*generator.Response{{Content: "a"}}, StreamErr: errors.New("reset")})
+	client := NewClient(m, WithHooks(Hooks{OnStreamEnd: func(_ context.Context, e Event) { ends = append(ends, e) }}))
+
+	req := &generator.Request{Model: "m", Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+	stream, err := client.GenerateStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	var stats *generator.StreamStats
+	for chunk := range stream {
+		if chunk.StreamStats != nil {
+			stats = chunk.StreamStats
+		}
+	}
+	if stats == nil {
+		t.Fatal("usage chunk has no stream stats")
+	}
+	if stats.TimeToFirstToken < 20*time.Millisecond || stats.MeanInterChunk < 10*time.Millisecond || stats.Chunks != 4 || stats.Duration < 50*time.Millisecond {
+		t.Errorf("StreamStats = %+v", stats)
+	}
+	// 30 tokens over the 30ms from the first token to the usage chunk
+	if stats.OutputTokensPerSecond <= 0 || stats.OutputTokensPerSecond > 1000 {
+		t.Errorf("OutputTokensPerSecond = %v, want at most 1000", stats.OutputTokensPerSecond)
+	}
+	if len(ends) != 1 || ends[0].Stream == nil || ends[0].TotalTokens != 32 || ends[0].Provider != "mock" || ends[0].Err != nil {
+		t.Fatalf("OnStreamEnd events = %+v", ends)
+	}
+
+	stream, err = client.GenerateStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	for range stream {
+	}
+	if len(ends) != 2 || ends[1].Err == nil || ends[1].Stream.TimeToFirstToken == 0 || ends[1].Stream.OutputTokensPerSecond != 0 {
+		t.Errorf("OnStreamEnd event of a failed stream = %+v", ends[1])
+	}
+}
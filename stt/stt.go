@@ -0,0 +1,85 @@
+// Package stt provides interfaces and types for speech-to-text.
+package stt
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Granularity represents the level of timestamps in a transcript
+type Granularity string
+
+const (
+	GranularitySegment Granularity = "segment"
+	GranularityWord    Granularity = "word"
+)
+
+// Request represents a transcription request
+type Request struct {
+	Model string
+	Audio io.Reader
+	// Filename hints the audio encoding to providers inferring it from the
+	// extension, e.g. "audio.mp3"
+	Filename  string
+	MediaType string
+	// Language is the ISO-639-1 code of the audio, detected when empty
+	Language string
+	// Prompt is text preceding the audio, such as vocabulary or a previous
+	// segment, that guides the transcription
+	Prompt string
+	// Timestamps are the granularities of timestamps to return
+	Timestamps     []Granularity
+	ProviderParams map[string]interface{}
+}
+
+// Segment represents a timed span of a transcript
+type Segment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// Word represents a timed word of a transcript
+type Word struct {
+	Start      time.Duration
+	End        time.Duration
+	Text       string
+	Confidence float64 // Zero when not reported
+}
+
+// Response represents a transcript. Segments and Words are set when asked
+// for in Request.Timestamps and supported by the provider.
+type Response struct {
+	Model    string
+	Text     string
+	Language string // Detected or given language, when reported
+	Duration time.Duration
+	Segments []Segment
+	Words    []Word
+}
+
+// Transcriber defines the interface for speech-to-text
+type Transcriber interface {
+	// Transcribe sends a transcription request
+	Transcribe(ctx context.Context, req *Request) (*Response, error)
+
+	// GetTranscriberName returns the name of the implementation
+	GetTranscriberName() string
+}
+
+// Wants reports whether the request asks for timestamps of granularity g
+func (r *Request) Wants(g Granularity) bool {
+	for _, t := range r.Timestamps {
+		if t == g {
+			return true
+		}
+	}
+	return false
+}
+
+// Seconds converts fractional seconds, as providers report them, to a
+// duration
+func Seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
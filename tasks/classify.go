@@ -0,0 +1,191 @@
+// Package tasks provides ready-made tasks over a client, e.g. zero-shot
+// classification. Longer pipelines live in subpackages, such as summarize.
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/guardrails"
+	"github.com/parikxxit/go-llm/validate"
+)
+
+const defaultConcurrency = 8
+
+// Classification represents the label chosen for a text
+type Classification struct {
+	Label string
+	// Confidence is the confidence in Label the model reports, from 0 to 1
+	Confidence float64
+	Rationale  string
+	Response   *generator.Response
+}
+
+type options struct {
+	model        string
+	descriptions map[string]string
+	instructions string
+	concurrency  int
+}
+
+// Option is a function that configures Classify and ClassifyBatch
+type Option func(*options)
+
+// WithModel sets the model classifying
+func WithModel(model string) Option {
+	return func(o *options) {
+		o.model = model
+	}
+}
+
+// WithDescriptions describes labels to the model, by label
+func WithDescriptions(descriptions map[string]string) Option {
+	return func(o *options) {
+		o.descriptions = descriptions
+	}
+}
+
+// WithInstructions adds instructions to the prompt, e.g. about the domain
+// of the texts or how to break ties
+func WithInstructions(instructions string) Option {
+	return func(o *options) {
+		o.instructions = instructions
+	}
+}
+
+// WithConcurrency sets the number of ClassifyBatch requests in flight, 8 by
+// default or when n is not positive
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// Classify labels text with one of labels. The model answers with JSON of a
+// schema enumerating the labels, constrained when the generator supports
+// JSON schema grammars, and is asked to repair answers not matching it.
+func Classify(ctx context.Context, client *gollm.Client, text string, labels []string, opts ...Option) (*Classification, error) {
+	o := classifyOptions(opts)
+	if len(labels) == 0 {
+		return nil, errors.New("classify: no labels")
+	}
+	return classify(ctx, client, text, labels, o)
+}
+
+// ClassifyBatch classifies texts concurrently. Classifications and errors
+// are returned in input order: for each index exactly one of them is
+// non-nil.
+func ClassifyBatch(ctx context.Context, client *gollm.Client, texts []string, labels []string, opts ...Option) ([]*Classification, []error) {
+	o := classifyOptions(opts)
+	results := make([]*Classification, len(texts))
+	errs := make([]error, len(texts))
+	if len(labels) == 0 {
+		for i := range errs {
+			errs[i] = errors.New("classify: no labels")
+		}
+		return results, errs
+	}
+
+	concurrency := o.concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(texts); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = classify(ctx, client, texts[i], labels, o)
+			}
+		}()
+	}
+	for i := range texts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results, errs
+}
+
+func classifyOptions(opts []Option) options {
+	o := options{concurrency: defaultConcurrency}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func classify(ctx context.Context, client *gollm.Client, text string, labels []string, o options) (*Classification, error) {
+	schema := classificationSchema(labels)
+	req := &generator.Request{
+		Model: o.model,
+		Messages: []generator.Message{
+			{Role: generator.SYSTEM, Content: systemPrompt(labels, o)},
+			{Role: generator.USER, Content: text},
+		},
+		Grammar: &generator.Grammar{Kind: generator.GrammarJSONSchema, Schema: schema, Name: "classification"},
+	}
+	validators := gollm.ValidateOptions{Validators: []validate.Validator{validate.JSONSchema(schema)}}
+	resp, err := client.GenerateValidated(ctx, req, validators)
+	if errors.Is(err, gollm.ErrUnsupported) {
+		// The schema is in the prompt, and validated anyway
+		req.Grammar = nil
+		resp, err = client.GenerateValidated(ctx, req, validators)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Label      string  `json:"label"`
+		Confidence float64 `json:"confidence"`
+		Rationale  string  `json:"rationale"`
+	}
+	if err := json.Unmarshal([]byte(guardrails.StripCodeFence(resp.Content)), &out); err != nil {
+		return nil, fmt.Errorf("classify: %w", err)
+	}
+	return &Classification{Label: out.Label, Confidence: out.Confidence, Rationale: out.Rationale, Response: resp}, nil
+}
+
+func classificationSchema(labels []string) map[string]interface{} {
+	enum := make([]interface{}, len(labels))
+	for i, l := range labels {
+		enum[i] = l
+	}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"label":      map[string]interface{}{"type": "string", "enum": enum},
+			"confidence": map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1},
+			"rationale":  map[string]interface{}{"type": "string"},
+		},
+		"required":             []interface{}{"label", "confidence", "rationale"},
+		"additionalProperties": false,
+	}
+}
+
+func systemPrompt(labels []string, o options) string {
+	var b strings.Builder
+	b.WriteString("Classify the text of the user with exactly one of these labels:\n")
+	for _, l := range labels {
+		fmt.Fprintf(&b, "- %s", l)
+		if d := o.descriptions[l]; d != "" {
+			fmt.Fprintf(&b, ": %s", d)
+		}
+		b.WriteString("\n")
+	}
+	if o.instructions != "" {
+		fmt.Fprintf(&b, "\n%s\n", o.instructions)
+	}
+	b.WriteString("\nReply with a JSON object only, of the fields label, the label chosen; confidence, a number from 0 to 1; " +
+		"and rationale, a sentence explaining the choice.")
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package tasks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+var labels = []string{"positive", "negative", "neutral"}
+
+func TestClassify(t *testing.T) {
+	m := mock.New().Enqueue(
+		mock.Text(`{"label": "great", "confidence": 0.9, "rationale": "Praise."}`),
+		mock.Text("```json\n{\"label\": \"positive\", \"confidence\": 0.9, \"rationale\": \"Praise.\"}\n```"),
+	)
+	got, err := Classify(context.Background(), gollm.NewClient(m), "I love it", labels,
+		WithDescriptions(map[string]string{"neutral": "neither praise nor criticism"}))
+	if err != nil {
+		t.Fatalf("Classify() error = %v", err)
+	}
+	if got.Label != "positive" || got.Confidence != 0.9 || got.Rationale != "Praise." {
+		t.Errorf("Classify() = %+v, want positive at 0.9", got)
+	}
+	if m.Calls() != 2 {
+		t.Errorf("Classify() sent %d requests, want a repair of the unknown label", m.Calls())
+	}
+	system := m.Requests()[0].Messages[0].Content
+	if !strings.Contains(system, "- neutral: neither praise nor criticism") || m.Requests()[0].Grammar != nil {
+		t.Errorf("first request = %+v, want descriptions and no grammar for a generator without grammars", m.Requests()[0])
+	}
+}
+
+// schemaMock is a mock constraining decoding to JSON schemas
+type schemaMock struct {
+	*mock.Mock
+}
+
+func (schemaMock) SupportsGrammar(kind generator.GrammarKind) bool {
+	return kind == generator.GrammarJSONSchema
+}
+
+func TestClassifyBatch(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		label := "neutral"
+		if strings.Contains(req.Messages[1].Content, "bad") {
+			label = "negative"
+		}
+		return &generator.Response{Content: `{"label": "` + label + `", "confidence": 0.5, "rationale": "-"}`}, nil
+	}
+	got, errs := ClassifyBatch(context.Background(), gollm.NewClient(schemaMock{m}), []string{"ok", "bad", "fine"}, labels, WithConcurrency(2))
+	for i, want := range []string{"neutral", "negative", "neutral"} {
+		if errs[i] != nil || got[i].Label != want {
+			t.Errorf("ClassifyBatch()[%d] = %+v, %v, want %s", i, got[i], errs[i], want)
+		}
+	}
+	if g := m.LastRequest().Grammar; g == nil || g.Kind != generator.GrammarJSONSchema {
+		t.Errorf("request grammar = %+v, want the JSON schema", g)
+	}
+}
+
+func TestClassifyBatch_Concurrency(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return &generator.Response{Content: `{"label": "neutral", "confidence": 0.5, "rationale": "-"}`}, nil
+	}
+	for _, n := range []int{0, -1} {
+		got, errs := ClassifyBatch(context.Background(), gollm.NewClient(m), []string{"ok", "fine"}, labels, WithConcurrency(n))
+		for i := range got {
+			if errs[i] != nil || got[i].Label != "neutral" {
+				t.Errorf("ClassifyBatch() with concurrency %d [%d] = %+v, %v, want neutral", n, i, got[i], errs[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// Package summarize summarizes documents too long for a single request: the
+// text is split into chunks of tokens, then either the chunk summaries are
+// combined, map-reduce, or a running summary is refined chunk after chunk.
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/splitters"
+	"github.com/parikxxit/go-llm/tokenizer"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+const (
+	defaultChunkSize   = 3000
+	defaultConcurrency = 4
+)
+
+// Strategy represents how chunk summaries make the summary of the document
+type Strategy int
+
+const (
+	// MapReduce summarizes the chunks concurrently, then combines the
+	// summaries, in rounds while they do not fit a chunk together
+	MapReduce Strategy = iota
+	// Refine summarizes the first chunk, then refines the summary with each
+	// following chunk in turn. It is sequential, so slower, but every step
+	// sees the summary of the text before it.
+	Refine
+)
+
+// Chunk represents a chunk of the document and its summary
+type Chunk struct {
+	Text string
+	// Summary summarizes Text with MapReduce, and the document up to and
+	// including Text with Refine
+	Summary string
+}
+
+// Summary represents the summary of a document
+type Summary struct {
+	Text   string
+	Chunks []Chunk
+	// Usage and Cost add up every request made
+	Usage generator.TokenUsage
+	Cost  float64
+}
+
+// Summarizer summarizes documents with a client
+type Summarizer struct {
+	client      *gollm.Client
+	model       string
+	strategy    Strategy
+	splitter    splitters.Splitter
+	chunkSize   int
+	words       int
+	style       string
+	concurrency int
+	maxTokens   int
+}
+
+// Option is a function that configures a Summarizer
+type Option func(*Summarizer)
+
+// WithModel sets the model summarizing, which also picks the tokenizer
+// measuring chunks
+func WithModel(model string) Option {
+	return func(s *Summarizer) {
+		s.model = model
+	}
+}
+
+// WithStrategy sets the strategy, MapReduce by default
+func WithStrategy(strategy Strategy) Option {
+	return func(s *Summarizer) {
+		s.strategy = strategy
+	}
+}
+
+// WithChunkSize sets the size of chunks in tokens, 3000 by default. Leave
+// room in the context window for the prompt and, with Refine, the summary.
+func WithChunkSize(tokens int) Option {
+	return func(s *Summarizer) {
+		s.chunkSize = tokens
+	}
+}
+
+// WithSplitter replaces the token splitter of WithChunkSize
+func WithSplitter(splitter splitters.Splitter) Option {
+	return func(s *Summarizer) {
+		s.splitter = splitter
+	}
+}
+
+// WithLength asks for summaries of at most words words
+func WithLength(words int) Option {
+	return func(s *Summarizer) {
+		s.words = words
+	}
+}
+
+// WithStyle asks for summaries written in style, e.g. "bullet points" or
+// "a single paragraph for executives"
+func WithStyle(style string) Option {
+	return func(s *Summarizer) {
+		s.style = style
+	}
+}
+
+// WithConcurrency sets the number of MapReduce requests in flight, 4 by
+// default
+func WithConcurrency(n int) Option {
+	return func(s *Summarizer) {
+		s.concurrency = n
+	}
+}
+
+// WithMaxTokens bounds the tokens generated per request
+func WithMaxTokens(n int) Option {
+	return func(s *Summarizer) {
+		s.maxTokens = n
+	}
+}
+
+// New creates a summarizer generating with client
+func New(client *gollm.Client, opts ...Option) *Summarizer {
+	s := &Summarizer{client: client, chunkSize: defaultChunkSize, concurrency: defaultConcurrency}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Summarize summarizes text
+func (s *Summarizer) Summarize(ctx context.Context, text string) (*Summary, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("summarize: empty text")
+	}
+	splitter := s.splitter
+	if splitter == nil {
+		splitter = splitters.Token{Counter: s.counter(), ChunkSize: s.chunkSize}
+	}
+	sum := &Summary{}
+	for _, chunk := range splitter.Split(vectorstore.Document{Text: text}) {
+		sum.Chunks = append(sum.Chunks, Chunk{Text: chunk.Text})
+	}
+
+	var err error
+	switch s.strategy {
+	case MapReduce:
+		err = s.mapReduce(ctx, sum)
+	case Refine:
+		err = s.refine(ctx, sum)
+	default:
+		err = fmt.Errorf("summarize: unknown strategy %d", s.strategy)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sum, nil
+}
+
+func (s *Summarizer) mapReduce(ctx context.Context, sum *Summary) error {
+	prompts := make([]string, len(sum.Chunks))
+	for i, c := range sum.Chunks {
+		prompts[i] = s.summarizePrompt(c.Text)
+	}
+	summaries, err := s.generateAll(ctx, sum, prompts)
+	if err != nil {
+		return err
+	}
+	for i := range sum.Chunks {
+		sum.Chunks[i].Summary = summaries[i]
+	}
+
+	for len(summaries) > 1 {
+		groups := s.group(summaries)
+		prompts = make([]string, len(groups))
+		for i, g := range groups {
+			prompts[i] = s.combinePrompt(g)
+		}
+		if summaries, err = s.generateAll(ctx, sum, prompts); err != nil {
+			return err
+		}
+	}
+	sum.Text = summaries[0]
+	return nil
+}
+
+func (s *Summarizer) refine(ctx context.Context, sum *Summary) error {
+	var summary string
+	for i, c := range sum.Chunks {
+		prompt := s.summarizePrompt(c.Text)
+		if i > 0 {
+			prompt = s.refinePrompt(summary, c.Text)
+		}
+		var err error
+		if summary, err = s.generate(ctx, sum, prompt); err != nil {
+			return err
+		}
+		sum.Chunks[i].Summary = summary
+	}
+	sum.Text = summary
+	return nil
+}
+
+// group packs consecutive summaries into groups fitting a chunk together.
+// Summaries that cannot be packed at all form a single group.
+func (s *Summarizer) group(summaries []string) [][]string {
+	counter := s.counter()
+	var groups [][]string
+	size := 0
+	for _, summary := range summaries {
+		n := counter.Count(summary)
+		if len(groups) == 0 || size+n > s.chunkSize {
+			groups, size = append(groups, nil), 0
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], summary)
+		size += n
+	}
+	if len(groups) == len(summaries) {
+		return [][]string{summaries}
+	}
+	return groups
+}
+
+// generateAll answers prompts concurrently, in order
+func (s *Summarizer) generateAll(ctx context.Context, sum *Summary, prompts []string) ([]string, error) {
+	if len(prompts) == 1 {
+		text, err := s.generate(ctx, sum, prompts[0])
+		return []string{text}, err
+	}
+	requests := make([]*generator.Request, len(prompts))
+	for i, p := range prompts {
+		requests[i] = s.request(p)
+	}
+	responses, errs := s.client.GenerateBatch(ctx, requests, gollm.BatchOptions{Concurrency: s.concurrency})
+	texts := make([]string, len(prompts))
+	for i, resp := range responses {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		add(sum, resp)
+		texts[i] = strings.TrimSpace(resp.Content)
+	}
+	return texts, nil
+}
+
+func (s *Summarizer) generate(ctx context.Context, sum *Summary, prompt string) (string, error) {
+	resp, err := s.client.Generate(ctx, s.request(prompt))
+	if err != nil {
+		return "", err
+	}
+	add(sum, resp)
+	return strings.TrimSpace(resp.Content), nil
+}
+
+func (s *Summarizer) request(prompt string) *generator.Request {
+	return &generator.Request{
+		Model:     s.model,
+		MaxTokens: s.maxTokens,
+		Messages:  []generator.Message{{Role: generator.USER, Content: prompt}},
+	}
+}
+
+func (s *Summarizer) counter() tokenizer.Counter {
+	return tokenizer.ForModel(s.model)
+}
+
+// add adds the usage and cost of resp to sum
+func add(sum *Summary, resp *generator.Response) {
+	u := &sum.Usage
+	u.PromptTokens += resp.Usage.PromptTokens
+	u.CachedPromptTokens += resp.Usage.CachedPromptTokens
+	u.CompletionTokens += resp.Usage.CompletionTokens
+	u.ReasoningTokens += resp.Usage.ReasoningTokens
+	u.TotalTokens += resp.Usage.TotalTokens
+	sum.Cost += resp.Cost
+}
+
+func (s *Summarizer) summarizePrompt(text string) string {
+	return fmt.Sprintf("Write a summary of the following text%s. Reply with the summary only.\n\n%s", s.instructions(), text)
+}
+
+func (s *Summarizer) combinePrompt(summaries []string) string {
+	return fmt.Sprintf("The following are summaries of consecutive parts of a document. Combine them into a single summary of the document%s. Reply with the summary only.\n\n%s",
+		s.instructions(), strings.Join(summaries, "\n\n"))
+}
+
+func (s *Summarizer) refinePrompt(summary, text string) string {
+	return fmt.Sprintf("Here is a summary of a document up to a point:\n\n%s\n\nRefine the summary with the continuation of the document below, into a summary of the whole%s. Reply with the summary only.\n\n%s",
+		summary, s.instructions(), text)
+}
+
+// instructions returns the length and style asked for
+func (s *Summarizer) instructions() string {
+	var b strings.Builder
+	if s.words > 0 {
+		fmt.Fprintf(&b, " in at most %d words", s.words)
+	}
+	if s.style != "" {
+		fmt.Fprintf(&b, ", written as %s", s.style)
+	}
+	return b.String()
+}
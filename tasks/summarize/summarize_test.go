@@ -0,0 +1,122 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/providers/openai"
+	"github.com/parikxxit/go-llm/splitters"
+)
+
+const document = "alpha cats purr and sleep most of the day.\n\nbeta dogs bark at the mail carrier.\n\n" +
+	"gamma fish swim in circles all day long.\n\ndelta birds sing before the sun rises."
+
+// summarizer returns a mock summarizing chunks as sum(first word) and
+// combining summaries as combined, recording the prompts
+func summarizer(prompts *[]string) *mock.Mock {
+	var mu sync.Mutex
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		prompt := req.Messages[0].Content
+		mu.Lock()
+		*prompts = append(*prompts, prompt)
+		mu.Unlock()
+		resp := &generator.Response{Content: "combined", Usage: generator.TokenUsage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}}
+		if !strings.HasPrefix(prompt, "The following are summaries") {
+			text := prompt[strings.LastIndex(prompt, "\n\n")+2:]
+			resp.Content = " sum(" + strings.Fields(text)[0] + ") "
+		}
+		return resp, nil
+	}
+	return m
+}
+
+func TestSummarizer_MapReduce(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunkSize int
+		calls     int
+	}{
+		{"one round", 3000, 5},
+		// Two summaries fit a chunk: two combines, then a final one
+		{"two rounds", 6, 7},
+	}
+	for _, tt := range tests {
+		var prompts []string
+		s := New(gollm.NewClient(summarizer(&prompts)), WithSplitter(splitters.Recursive{ChunkSize: 50}), WithChunkSize(tt.chunkSize), WithLength(50), WithStyle("bullet points"))
+		sum, err := s.Summarize(context.Background(), document)
+		if err != nil {
+			t.Fatalf("%s: Summarize() error = %v", tt.name, err)
+		}
+		if sum.Text != "combined" || len(sum.Chunks) != 4 || sum.Chunks[1].Summary != "sum(beta)" {
+			t.Errorf("%s: Summarize() = %+v, want 4 chunk summaries combined", tt.name, sum)
+		}
+		if len(prompts) != tt.calls || sum.Usage.TotalTokens != 12*tt.calls {
+			t.Errorf("%s: %d requests using %+v, want %d", tt.name, len(prompts), sum.Usage, tt.calls)
+		}
+		if !strings.Contains(prompts[0], "in at most 50 words, written as bullet points") {
+			t.Errorf("%s: prompt = %q, want the length and style", tt.name, prompts[0])
+		}
+	}
+}
+
+func TestSummarizer_Refine(t *testing.T) {
+	var prompts []string
+	s := New(gollm.NewClient(summarizer(&prompts)), WithStrategy(Refine), WithSplitter(splitters.Recursive{ChunkSize: 50}))
+	sum, err := s.Summarize(context.Background(), document)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if sum.Text != "sum(delta)" || len(sum.Chunks) != 4 || len(prompts) != 4 {
+		t.Errorf("Summarize() = %+v after %d requests, want 4 refinements", sum, len(prompts))
+	}
+	if !strings.Contains(prompts[1], "sum(alpha)") || !strings.Contains(prompts[1], "beta dogs") {
+		t.Errorf("refine prompt = %q, want the summary so far and the next chunk", prompts[1])
+	}
+}
+
+func TestSummarizer_EmptyText(t *testing.T) {
+	if _, err := New(gollm.NewClient(mock.New())).Summarize(context.Background(), " \n"); err == nil {
+		t.Error("Summarize() of empty text error = nil, want an error")
+	}
+}
+
+func TestSummarizer_MaxTokens(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"summary"},"finish_reason":"stop"}]}`)
+	}))
+	defer srv.Close()
+
+	llm := openai.NewOpenAI(generator.Config{ApiKey: "test", BaseURL: srv.URL, Model: "gpt-4o"})
+	s := New(gollm.NewClient(llm), WithModel("gpt-4o-mini"), WithMaxTokens(128), WithSplitter(splitters.Recursive{ChunkSize: 50}))
+	if _, err := s.Summarize(context.Background(), document); err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if len(bodies) == 0 {
+		t.Fatal("Summarize() sent no requests")
+	}
+	for i, body := range bodies {
+		if body["max_completion_tokens"] != 128.0 || body["model"] != "gpt-4o-mini" {
+			t.Errorf("request %d sent max_completion_tokens = %v, model = %v, want 128, gpt-4o-mini", i, body["max_completion_tokens"], body["model"])
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// replyPriming approximates the tokens chat formats spend priming the reply
+const replyPriming = 3
+
+// Heuristic approximates token counts from the byte length of texts, for
+// tokenizers that cannot run locally
+type Heuristic struct {
+	BytesPerToken float64
+}
+
+func (h Heuristic) Count(text string) int {
+	if len(text) == 0 || h.BytesPerToken <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(len(text)) / h.BytesPerToken))
+}
+
+var (
+	mu       sync.RWMutex
+	counters = map[string]Counter{
+		"claude":   Heuristic{BytesPerToken: 3.5},
+		"gemini":   Heuristic{BytesPerToken: 4},
+		"llama":    Heuristic{BytesPerToken: 3.8},
+		"mistral":  Heuristic{BytesPerToken: 3.7},
+		"command":  Heuristic{BytesPerToken: 4},
+		"deepseek": Heuristic{BytesPerToken: 3.9},
+	}
+)
+
+// Register sets the counter of the models whose names start with prefix,
+// replacing any previous one. Importing tokenizer/tiktoken registers exact
+// BPE counters for OpenAI models.
+func Register(prefix string, c Counter) {
+	mu.Lock()
+	defer mu.Unlock()
+	counters[prefix] = c
+}
+
+// ForModel returns the counter registered for the longest prefix of model,
+// or Estimate
+func ForModel(model string) Counter {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var best string
+	var counter Counter = Estimate
+	for prefix, c := range counters {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best, counter = prefix, c
+		}
+	}
+	return counter
+}
+
+// CountRequest counts the prompt tokens of a request: its messages, tool
+// definitions and reply priming
+func CountRequest(c Counter, req *generator.Request) int {
+	total := CountMessages(c, req.Messages) + replyPriming
+	for _, t := range req.Tools {
+		params, _ := json.Marshal(t.Parameters)
+		total += c.Count(t.Name) + c.Count(t.Description) + c.Count(string(params)) + messageOverhead
+	}
+	return total
+}
@@ -0,0 +1,103 @@
+// Package tiktoken provides exact, tiktoken compatible BPE token counting for
+// OpenAI models. The vocabularies are embedded, adding about 5MB to
+// binaries, and loaded on first use.
+//
+// Importing the package registers its counters with tokenizer.ForModel:
+//
+//	import _ "github.com/parikxxit/go-llm/tokenizer/tiktoken"
+package tiktoken
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/parikxxit/go-llm/tokenizer"
+	tiktoken "github.com/pkoukk/tiktoken-go"
+	loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// Encodings supported by New
+const (
+	CL100K = "cl100k_base" // GPT-4, GPT-3.5 and text-embedding-3 models
+	O200K  = "o200k_base"  // GPT-4o, GPT-4.1 and o-series models
+)
+
+func init() {
+	tiktoken.SetBpeLoader(loader.NewOfflineLoader())
+
+	for prefix, encoding := range map[string]string{
+		"gpt-3.5":         CL100K,
+		"gpt-4":           CL100K,
+		"text-embedding-": CL100K,
+		"gpt-4o":          O200K,
+		"gpt-4.1":         O200K,
+		"gpt-4.5":         O200K,
+		"gpt-5":           O200K,
+		"o1":              O200K,
+		"o3":              O200K,
+		"o4":              O200K,
+	} {
+		tokenizer.Register(prefix, lazy(encoding))
+	}
+}
+
+// BPE is a tokenizer.Counter using a byte pair encoding
+type BPE struct {
+	enc *tiktoken.Tiktoken
+}
+
+var (
+	mu    sync.Mutex
+	cache = map[string]*BPE{}
+)
+
+// New returns the BPE of an encoding, loading it once
+func New(encoding string) (*BPE, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if b, ok := cache[encoding]; ok {
+		return b, nil
+	}
+	if encoding != CL100K && encoding != O200K {
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+	enc, err := tiktoken.GetEncoding(encoding)
+	if err != nil {
+		return nil, fmt.Errorf("loading encoding %s: %w", encoding, err)
+	}
+	b := &BPE{enc: enc}
+	cache[encoding] = b
+	return b, nil
+}
+
+// Count returns the number of tokens of text, treating special tokens as
+// plain text
+func (b *BPE) Count(text string) int {
+	return len(b.enc.EncodeOrdinary(text))
+}
+
+// Encode returns the token IDs of text
+func (b *BPE) Encode(text string) []int {
+	return b.enc.EncodeOrdinary(text)
+}
+
+// Decode returns the text of token IDs
+func (b *BPE) Decode(tokens []int) string {
+	return b.enc.Decode(tokens)
+}
+
+// lazy returns a counter loading encoding on first use, falling back to
+// tokenizer.Estimate if it cannot be loaded
+func lazy(encoding string) tokenizer.Counter {
+	var once sync.Once
+	var counter tokenizer.Counter
+	return tokenizer.CounterFunc(func(text string) int {
+		once.Do(func() {
+			counter = tokenizer.Estimate
+			if b, err := New(encoding); err == nil {
+				counter = b
+			}
+		})
+		return counter.Count(text)
+	})
+}
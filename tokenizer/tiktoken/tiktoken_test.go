@@ -0,0 +1,47 @@
+package tiktoken
+
+import (
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/tokenizer"
+)
+
+func TestBPE(t *testing.T) {
+	tests := []struct {
+		encoding string
+		text     string
+		want     int
+	}{
+		{CL100K, "hello world", 2},
+		{CL100K, "tiktoken is great!", 6},
+		{O200K, "hello world", 2},
+		{O200K, "<|endoftext|>", 7},
+	}
+	for _, tt := range tests {
+		b, err := New(tt.encoding)
+		if err != nil {
+			t.Fatalf("New(%s) error = %v", tt.encoding, err)
+		}
+		if got := b.Count(tt.text); got != tt.want {
+			t.Errorf("%s Count(%q) = %d, want %d", tt.encoding, tt.text, got, tt.want)
+		}
+		if got := b.Decode(b.Encode(tt.text)); got != tt.text {
+			t.Errorf("%s Decode(Encode(%q)) = %q", tt.encoding, tt.text, got)
+		}
+	}
+	if _, err := New("r50k_base"); err == nil {
+		t.Error("New(r50k_base) error = nil")
+	}
+}
+
+func TestForModel(t *testing.T) {
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hello world"}}}
+	// 2 content tokens, 4 of message overhead and 3 of reply priming
+	if got := tokenizer.CountRequest(tokenizer.ForModel("gpt-4o-2024-08-06"), req); got != 9 {
+		t.Errorf("CountRequest(gpt-4o) = %d, want 9", got)
+	}
+	if got := tokenizer.ForModel("claude-sonnet-4").Count("hello world"); got != 4 {
+		t.Errorf("ForModel(claude).Count() = %d, want the heuristic 4", got)
+	}
+}
@@ -0,0 +1,46 @@
+// Package tokenizer provides token counting for text and messages.
+package tokenizer
+
+import (
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// messageOverhead approximates the tokens chat formats spend on role markers
+const messageOverhead = 4
+
+// Counter counts the tokens of a text
+type Counter interface {
+	Count(text string) int
+}
+
+// CounterFunc adapts a function to a Counter
+type CounterFunc func(text string) int
+
+func (f CounterFunc) Count(text string) int {
+	return f(text)
+}
+
+// Estimate approximates token counts at roughly four bytes per token, which is
+// close enough for budgeting English text with GPT-style tokenizers
+var Estimate Counter = CounterFunc(func(text string) int {
+	return (len(text) + 3) / 4
+})
+
+// CountMessages counts the tokens of messages including per-message overhead
+func CountMessages(c Counter, messages []generator.Message) int {
+	total := 0
+	for _, m := range messages {
+		total += CountMessage(c, m)
+	}
+	return total
+}
+
+// CountMessage counts the tokens of a single message including its overhead
+// and tool calls
+func CountMessage(c Counter, m generator.Message) int {
+	total := c.Count(m.Content) + messageOverhead
+	for _, call := range m.ToolCalls {
+		total += c.Count(call.Name) + c.Count(call.Arguments)
+	}
+	return total
+}
@@ -0,0 +1,78 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/tools"
+)
+
+const defaultMaxToolIterations = 10
+
+// ErrMaxToolIterations is returned when the model keeps calling tools after
+// the configured number of iterations
+var ErrMaxToolIterations = errors.New("maximum tool iterations reached")
+
+// ToolOptions configures GenerateWithTools
+type ToolOptions struct {
+	// MaxIterations bounds the number of generations, 10 when zero
+	MaxIterations int
+	// OnToolResults, if set, is called after each round of tool calls
+	OnToolResults func(calls []generator.ToolCall, results []generator.Message)
+}
+
+// ToolRun represents the outcome of a tool loop
+type ToolRun struct {
+	Response   *generator.Response // Final response of the model
+	Messages   []generator.Message // Conversation including tool calls and results
+	Iterations int
+}
+
+// GenerateWithTools sends request with the tools of registry and executes
+// returned tool calls concurrently, feeding their results back until the
+// model answers without calling tools. When MaxIterations is reached the
+// partial run is returned along with ErrMaxToolIterations.
+func (c *Client) GenerateWithTools(ctx context.Context, request *generator.Request, registry *tools.Registry, opts ToolOptions) (*ToolRun, error) {
+	maxIterations := opts.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultMaxToolIterations
+	}
+
+	req := *request
+	req.Tools = append(append([]generator.Tool(nil), request.Tools...), registry.Definitions()...)
+	req.Messages = append([]generator.Message(nil), request.Messages...)
+
+	run := &ToolRun{}
+	for run.Iterations < maxIterations {
+		if err := ctx.Err(); err != nil {
+			return run, err
+		}
+
+		resp, err := c.Generate(ctx, &req)
+		if err != nil {
+			return run, err
+		}
+		run.Iterations++
+		run.Response = resp
+		req.Messages = append(req.Messages, generator.Message{
+			Role:      generator.ASSISTANT,
+			Content:   resp.Content,
+			ToolCalls: resp.ToolCalls,
+		})
+		run.Messages = req.Messages
+
+		if len(resp.ToolCalls) == 0 {
+			return run, nil
+		}
+
+		results := registry.Execute(ctx, resp.ToolCalls)
+		if opts.OnToolResults != nil {
+			opts.OnToolResults(resp.ToolCalls, results)
+		}
+		req.Messages = append(req.Messages, results...)
+		run.Messages = req.Messages
+	}
+	return run, fmt.Errorf("%w (%d)", ErrMaxToolIterations, maxIterations)
+}
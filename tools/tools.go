@@ -0,0 +1,138 @@
+// Package tools provides a registry of Go functions callable by models.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// Handler executes a tool call. arguments holds the JSON object produced by
+// the model; the returned string is sent back to it as the tool result.
+type Handler func(ctx context.Context, arguments json.RawMessage) (string, error)
+
+// Tool represents a tool definition with its implementation
+type Tool struct {
+	Definition generator.Tool
+	Handler    Handler
+}
+
+// Func creates a tool from a typed function. The parameter schema is derived
+// from T, which should be a struct; see Schema for the supported tags. The
+// result is sent to the model as is when it is a string, JSON encoded otherwise.
+func Func[T any, R any](name, description string, fn func(ctx context.Context, args T) (R, error)) Tool {
+	var zero T
+	return Tool{
+		Definition: generator.Tool{
+			Name:        name,
+			Description: description,
+			Parameters:  Schema(zero),
+		},
+		Handler: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+			var args T
+			if len(arguments) > 0 {
+				if err := json.Unmarshal(arguments, &args); err != nil {
+					return "", fmt.Errorf("invalid arguments for %s: %w", name, err)
+				}
+			}
+			result, err := fn(ctx, args)
+			if err != nil {
+				return "", err
+			}
+			if s, ok := any(result).(string); ok {
+				return s, nil
+			}
+			b, err := json.Marshal(result)
+			if err != nil {
+				return "", fmt.Errorf("encoding result of %s: %w", name, err)
+			}
+			return string(b), nil
+		},
+	}
+}
+
+// Registry holds the tools available to a model. It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]Tool
+	order []string
+}
+
+// NewRegistry creates a registry holding tools
+func NewRegistry(tools ...Tool) (*Registry, error) {
+	r := &Registry{tools: make(map[string]Tool)}
+	for _, t := range tools {
+		if err := r.Register(t); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a tool; names must be unique
+func (r *Registry) Register(t Tool) error {
+	if t.Definition.Name == "" || t.Handler == nil {
+		return fmt.Errorf("tool name and handler are required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tools[t.Definition.Name]; ok {
+		return fmt.Errorf("tool %s already registered", t.Definition.Name)
+	}
+	r.tools[t.Definition.Name] = t
+	r.order = append(r.order, t.Definition.Name)
+	return nil
+}
+
+// Lookup returns a tool by name
+func (r *Registry) Lookup(name string) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tools[name]
+	return t, ok
+}
+
+// Definitions returns the definitions of every tool in registration order
+func (r *Registry) Definitions() []generator.Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	defs := make([]generator.Tool, 0, len(r.order))
+	for _, name := range r.order {
+		defs = append(defs, r.tools[name].Definition)
+	}
+	return defs
+}
+
+// Call executes a tool call
+func (r *Registry) Call(ctx context.Context, call generator.ToolCall) (string, error) {
+	t, ok := r.Lookup(call.Name)
+	if !ok {
+		return "", fmt.Errorf("unknown tool %s", call.Name)
+	}
+	return t.Handler(ctx, json.RawMessage(call.Arguments))
+}
+
+// Execute runs calls concurrently and returns one tool message per call, in
+// order. Failures are reported to the model in the message content so it can
+// recover.
+func (r *Registry) Execute(ctx context.Context, calls []generator.ToolCall) []generator.Message {
+	results := make([]generator.Message, len(calls))
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func(i int, call generator.ToolCall) {
+			defer wg.Done()
+			content, err := r.Call(ctx, call)
+			if err != nil {
+				content = "error: " + err.Error()
+			}
+			results[i] = generator.Message{Role: generator.TOOL, Content: content, ToolCallID: call.ID}
+		}(i, call)
+	}
+	wg.Wait()
+	return results
+}
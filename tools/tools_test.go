@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+)
+
+type weatherArgs struct {
+	City  string `json:"city" description:"City name"`
+	Unit  string `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+	Days  []int  `json:"days,omitempty"`
+	Debug bool   `json:"-"`
+}
+
+func TestSchema(t *testing.T) {
+	got := Schema(weatherArgs{})
+	want := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"city": map[string]interface{}{"type": "string", "description": "City name"},
+			"unit": map[string]interface{}{"type": "string", "enum": []string{"celsius", "fahrenheit"}},
+			"days": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}},
+		},
+		"required": []string{"city"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Schema() = %#v, want %#v", got, want)
+	}
+}
+
+func TestRegistry_Execute(t *testing.T) {
+	weather := Func("weather", "Get the weather", func(_ context.Context, args weatherArgs) (map[string]string, error) {
+		return map[string]string{"city": args.City, "sky": "clear"}, nil
+	})
+	r, err := NewRegistry(weather)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	if err := r.Register(weather); err == nil {
+		t.Error("Register() duplicate error = nil")
+	}
+
+	results := r.Execute(context.Background(), []generator.ToolCall{
+		{ID: "1", Name: "weather", Arguments: `{"city":"Paris"}`},
+		{ID: "2", Name: "missing"},
+	})
+	if results[0].Content != `{"city":"Paris","sky":"clear"}` || results[0].ToolCallID != "1" {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	if results[1].Content != "error: unknown tool missing" {
+		t.Errorf("results[1] = %+v", results[1])
+	}
+}
@@ -0,0 +1,55 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/tools"
+)
+
+func TestClient_GenerateWithTools(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		last := req.Messages[len(req.Messages)-1]
+		if last.Role == generator.TOOL {
+			return &generator.Response{Content: "it is " + last.Content}, nil
+		}
+		return &generator.Response{ToolCalls: []generator.ToolCall{{ID: "1", Name: "time", Arguments: "{}"}}}, nil
+	}
+	registry, _ := tools.NewRegistry(tools.Func("time", "Current time", func(context.Context, struct{}) (string, error) {
+		return "noon", nil
+	}))
+
+	run, err := NewClient(m).GenerateWithTools(context.Background(), &generator.Request{
+		Messages: []generator.Message{{Role: generator.USER, Content: "what time is it?"}},
+	}, registry, ToolOptions{})
+	if err != nil {
+		t.Fatalf("GenerateWithTools() error = %v", err)
+	}
+	if run.Response.Content != "it is noon" || run.Iterations != 2 || len(run.Messages) != 4 {
+		t.Errorf("run = %+v", run)
+	}
+	if got := m.Requests()[0].Tools; len(got) != 1 || got[0].Name != "time" {
+		t.Errorf("request tools = %+v", got)
+	}
+}
+
+func TestClient_GenerateWithToolsMaxIterations(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return &generator.Response{ToolCalls: []generator.ToolCall{{ID: "1", Name: "time"}}}, nil
+	}
+	registry, _ := tools.NewRegistry(tools.Func("time", "", func(context.Context, struct{}) (string, error) {
+		return "noon", nil
+	}))
+
+	run, err := NewClient(m).GenerateWithTools(context.Background(), &generator.Request{
+		Messages: []generator.Message{{Role: generator.USER, Content: "loop"}},
+	}, registry, ToolOptions{MaxIterations: 3})
+	if !errors.Is(err, ErrMaxToolIterations) || run.Iterations != 3 {
+		t.Errorf("GenerateWithTools() = %+v, %v; want 3 iterations and ErrMaxToolIterations", run, err)
+	}
+}
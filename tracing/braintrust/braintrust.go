@@ -0,0 +1,145 @@
+// Package braintrust provides a tracing.Exporter sending gollm calls to the
+// logs of a Braintrust project as LLM spans.
+package braintrust
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/tracing"
+	"github.com/parikxxit/go-llm/transport"
+)
+
+const defaultBaseURL = "https://api.braintrust.dev"
+
+// Exporter inserts spans into the logs of a Braintrust project. Each span is
+// a root span; its trace ID is kept in the trace_id metadata.
+type Exporter struct {
+	apiKey     string
+	projectID  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Option is a function that configures an Exporter
+type Option func(*Exporter)
+
+// WithBaseURL sets the API URL, https://api.braintrust.dev by default
+func WithBaseURL(url string) Option {
+	return func(e *Exporter) {
+		e.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client, transport.DefaultClient by default
+func WithHTTPClient(c *http.Client) Option {
+	return func(e *Exporter) {
+		e.httpClient = c
+	}
+}
+
+// New creates a new exporter logging to the project projectID
+func New(apiKey, projectID string, opts ...Option) *Exporter {
+	e := &Exporter{apiKey: apiKey, projectID: projectID, baseURL: defaultBaseURL, httpClient: transport.DefaultClient()}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+type logEvent struct {
+	ID             string         `json:"id"`
+	SpanID         string         `json:"span_id"`
+	RootSpanID     string         `json:"root_span_id"`
+	Input          any            `json:"input"`
+	Output         any            `json:"output,omitempty"`
+	Error          string         `json:"error,omitempty"`
+	Metadata       map[string]any `json:"metadata"`
+	Metrics        map[string]any `json:"metrics"`
+	SpanAttributes map[string]any `json:"span_attributes"`
+}
+
+// Export inserts spans as log events
+func (e *Exporter) Export(ctx context.Context, spans []tracing.Span) error {
+	events := make([]logEvent, len(spans))
+	for i, s := range spans {
+		events[i] = newLogEvent(s)
+	}
+	body, err := json.Marshal(map[string]any{"events": events})
+	if err != nil {
+		return fmt.Errorf("braintrust: encoding events: %w", err)
+	}
+	endpoint := e.baseURL + "/v1/project_logs/" + url.PathEscape(e.projectID) + "/insert"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return llmerrors.FromResponse("braintrust", res)
+	}
+	return nil
+}
+
+func newLogEvent(s tracing.Span) logEvent {
+	metadata := map[string]any{
+		"trace_id": s.TraceID,
+		"model":    s.Model(),
+		"provider": s.Provider,
+		"attempts": s.Attempts,
+	}
+	for k, v := range s.Parameters() {
+		metadata[k] = v
+	}
+	for k, v := range s.Request.Metadata {
+		metadata[k] = v
+	}
+	if s.Request.User != "" {
+		metadata["user"] = s.Request.User
+	}
+	if s.RequestID != "" {
+		metadata["request_id"] = s.RequestID
+	}
+
+	ev := logEvent{
+		ID:             s.ID,
+		SpanID:         s.ID,
+		RootSpanID:     s.ID,
+		Input:          s.Input(),
+		Metadata:       metadata,
+		Metrics:        map[string]any{"start": seconds(s.Start), "end": seconds(s.End)},
+		SpanAttributes: map[string]any{"name": string(s.Operation), "type": "llm"},
+	}
+	if resp := s.Response; resp != nil {
+		ev.Output = []map[string]any{{"index": 0, "message": s.Output(), "finish_reason": resp.FinishReason}}
+		ev.Metrics["prompt_tokens"] = resp.Usage.PromptTokens
+		ev.Metrics["completion_tokens"] = resp.Usage.CompletionTokens
+		ev.Metrics["tokens"] = resp.Usage.TotalTokens
+		if resp.Cost > 0 {
+			ev.Metadata["cost"] = resp.Cost
+		}
+	}
+	if s.Err != nil {
+		ev.Error = s.Err.Error()
+	}
+	return ev
+}
+
+// seconds returns t as fractional Unix seconds
+func seconds(t time.Time) float64 {
+	return float64(t.UnixMicro()) / 1e6
+}
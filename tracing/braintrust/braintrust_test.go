@@ -0,0 +1,65 @@
+package braintrust
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/tracing"
+)
+
+func TestExporter_Export(t *testing.T) {
+	var events []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/project_logs/p1/insert" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("request to %s with headers %v", r.URL.Path, r.Header)
+		}
+		var body struct{ Events []map[string]any }
+		json.NewDecoder(r.Body).Decode(&body)
+		events = body.Events
+		io.WriteString(w, `{"row_ids":["s1","s2"]}`)
+	}))
+	defer srv.Close()
+
+	start := time.Unix(1700000000, 500_000_000)
+	spans := []tracing.Span{
+		{
+			ID: "s1", TraceID: "t1", Operation: "generate", Provider: "openai", Attempts: 1, Start: start, End: start.Add(time.Second),
+			Request:  &generator.Request{Model: "gpt-4o", User: "alice", Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}},
+			Response: &generator.Response{Content: "hello", Usage: generator.TokenUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}, Cost: 0.01},
+		},
+		{ID: "s2", TraceID: "t1", Operation: "generate", Start: start, End: start, Request: &generator.Request{}, Err: errors.New("boom")},
+	}
+	if err := New("key", "p1", WithBaseURL(srv.URL)).Export(context.Background(), spans); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	ev := events[0]
+	if ev["id"] != "s1" || ev["span_id"] != "s1" || ev["root_span_id"] != "s1" {
+		t.Errorf("event IDs = %v", ev)
+	}
+	if md := ev["metadata"].(map[string]any); md["trace_id"] != "t1" || md["model"] != "gpt-4o" || md["user"] != "alice" || md["cost"] != 0.01 {
+		t.Errorf("event metadata = %v", md)
+	}
+	if m := ev["metrics"].(map[string]any); m["start"] != 1700000000.5 || m["end"] != 1700000001.5 || m["tokens"] != 5.0 {
+		t.Errorf("event metrics = %v", m)
+	}
+	if out := ev["output"].([]any)[0].(map[string]any); out["message"].(map[string]any)["content"] != "hello" {
+		t.Errorf("event output = %v", ev["output"])
+	}
+	if attrs := ev["span_attributes"].(map[string]any); attrs["type"] != "llm" || attrs["name"] != "generate" {
+		t.Errorf("event span attributes = %v", attrs)
+	}
+	if events[1]["error"] != "boom" || events[1]["output"] != nil {
+		t.Errorf("failed event = %v", events[1])
+	}
+}
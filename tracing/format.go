@@ -0,0 +1,81 @@
+package tracing
+
+import "github.com/parikxxit/go-llm/generator"
+
+// ChatMessage is a message in the OpenAI chat format, which observability
+// platforms display as a conversation
+type ChatMessage struct {
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []ChatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// ChatToolCall is a tool call in the OpenAI chat format
+type ChatToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// Model returns the model that answered the call, else the requested one
+func (s Span) Model() string {
+	if s.Response != nil && s.Response.Model != "" {
+		return s.Response.Model
+	}
+	return s.Request.Model
+}
+
+// Input returns the request messages of the span
+func (s Span) Input() []ChatMessage {
+	messages := make([]ChatMessage, len(s.Request.Messages))
+	for i, m := range s.Request.Messages {
+		messages[i] = ChatMessage{Role: string(m.Role), Content: m.Content, ToolCalls: chatToolCalls(m.ToolCalls), ToolCallID: m.ToolCallID}
+	}
+	return messages
+}
+
+// Output returns the response message of the span, if it succeeded
+func (s Span) Output() *ChatMessage {
+	if s.Response == nil {
+		return nil
+	}
+	return &ChatMessage{Role: generator.ASSISTANT, Content: s.Response.Content, ToolCalls: chatToolCalls(s.Response.ToolCalls)}
+}
+
+// Parameters returns the sampling parameters set on the request
+func (s Span) Parameters() map[string]any {
+	params := map[string]any{}
+	r := s.Request
+	if r.MaxTokens > 0 {
+		params["max_tokens"] = r.MaxTokens
+	}
+	if r.Temperature != 0 {
+		params["temperature"] = r.Temperature
+	}
+	if r.TopP != 0 {
+		params["top_p"] = r.TopP
+	}
+	if len(r.Stop) > 0 {
+		params["stop"] = r.Stop
+	}
+	if r.ReasoningEffort != "" {
+		params["reasoning_effort"] = string(r.ReasoningEffort)
+	}
+	return params
+}
+
+func chatToolCalls(calls []generator.ToolCall) []ChatToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]ChatToolCall, len(calls))
+	for i, tc := range calls {
+		out[i] = ChatToolCall{ID: tc.ID, Type: "function"}
+		out[i].Function.Name, out[i].Function.Arguments = tc.Name, tc.Arguments
+	}
+	return out
+}
@@ -0,0 +1,212 @@
+// Package langfuse provides a tracing.Exporter sending gollm calls to
+// Langfuse as traces and generations, through its ingestion API.
+package langfuse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/tracing"
+	"github.com/parikxxit/go-llm/transport"
+)
+
+const defaultHost = "https://cloud.langfuse.com"
+
+// Exporter sends spans to a Langfuse project. Each span is a generation in
+// the trace of its TraceID.
+type Exporter struct {
+	publicKey  string
+	secretKey  string
+	host       string
+	httpClient *http.Client
+}
+
+// Option is a function that configures an Exporter
+type Option func(*Exporter)
+
+// WithHost sets the Langfuse URL, https://cloud.langfuse.com by default
+func WithHost(url string) Option {
+	return func(e *Exporter) {
+		e.host = strings.TrimRight(url, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client, transport.DefaultClient by default
+func WithHTTPClient(c *http.Client) Option {
+	return func(e *Exporter) {
+		e.httpClient = c
+	}
+}
+
+// New creates a new exporter authenticating with the API keys of a project
+func New(publicKey, secretKey string, opts ...Option) *Exporter {
+	e := &Exporter{publicKey: publicKey, secretKey: secretKey, host: defaultHost, httpClient: transport.DefaultClient()}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Score represents an evaluation of a trace, or of one generation of it
+type Score struct {
+	TraceID string // tracing.MetadataTraceID of the response scored
+	// ObservationID is the span ID of the generation scored, if any
+	ObservationID string
+	Name          string
+	Value         float64
+	Comment       string
+}
+
+type event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Body      any       `json:"body"`
+}
+
+type trace struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Timestamp time.Time         `json:"timestamp"`
+	UserID    string            `json:"userId,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+type generation struct {
+	ID              string                `json:"id"`
+	TraceID         string                `json:"traceId"`
+	Name            string                `json:"name"`
+	StartTime       time.Time             `json:"startTime"`
+	EndTime         time.Time             `json:"endTime"`
+	Model           string                `json:"model,omitempty"`
+	ModelParameters map[string]any        `json:"modelParameters,omitempty"`
+	Input           []tracing.ChatMessage `json:"input"`
+	Output          *tracing.ChatMessage  `json:"output,omitempty"`
+	UsageDetails    map[string]int        `json:"usageDetails,omitempty"`
+	CostDetails     map[string]float64    `json:"costDetails,omitempty"`
+	Level           string                `json:"level"`
+	StatusMessage   string                `json:"statusMessage,omitempty"`
+	Metadata        map[string]any        `json:"metadata,omitempty"`
+}
+
+type score struct {
+	ID            string  `json:"id"`
+	TraceID       string  `json:"traceId"`
+	ObservationID string  `json:"observationId,omitempty"`
+	Name          string  `json:"name"`
+	Value         float64 `json:"value"`
+	Comment       string  `json:"comment,omitempty"`
+}
+
+// Export sends spans as traces and generations
+func (e *Exporter) Export(ctx context.Context, spans []tracing.Span) error {
+	events := make([]event, 0, 2*len(spans))
+	for _, s := range spans {
+		events = append(events,
+			event{ID: tracing.NewID(), Type: "trace-create", Timestamp: s.Start, Body: trace{
+				ID:        s.TraceID,
+				Name:      string(s.Operation),
+				Timestamp: s.Start,
+				UserID:    s.Request.User,
+				Metadata:  s.Request.Metadata,
+			}},
+			event{ID: tracing.NewID(), Type: "generation-create", Timestamp: s.End, Body: newGeneration(s)},
+		)
+	}
+	return e.ingest(ctx, events)
+}
+
+// Score attaches a score to a trace, sent right away
+func (e *Exporter) Score(ctx context.Context, s Score) error {
+	return e.ingest(ctx, []event{{ID: tracing.NewID(), Type: "score-create", Timestamp: time.Now(), Body: score{
+		ID:            tracing.NewID(),
+		TraceID:       s.TraceID,
+		ObservationID: s.ObservationID,
+		Name:          s.Name,
+		Value:         s.Value,
+		Comment:       s.Comment,
+	}}})
+}
+
+func newGeneration(s tracing.Span) generation {
+	g := generation{
+		ID:              s.ID,
+		TraceID:         s.TraceID,
+		Name:            string(s.Operation),
+		StartTime:       s.Start,
+		EndTime:         s.End,
+		Model:           s.Model(),
+		ModelParameters: s.Parameters(),
+		Input:           s.Input(),
+		Output:          s.Output(),
+		Level:           "DEFAULT",
+		Metadata:        map[string]any{"provider": s.Provider, "attempts": s.Attempts},
+	}
+	if s.RequestID != "" {
+		g.Metadata["request_id"] = s.RequestID
+	}
+	for k, v := range s.Request.Metadata {
+		if _, ok := g.Metadata[k]; !ok {
+			g.Metadata[k] = v
+		}
+	}
+	if r := s.Response; r != nil {
+		g.UsageDetails = map[string]int{"input": r.Usage.PromptTokens, "output": r.Usage.CompletionTokens, "total": r.Usage.TotalTokens}
+		if r.Cost > 0 {
+			g.CostDetails = map[string]float64{"total": r.Cost}
+		}
+		if r.FinishReason != "" {
+			g.Metadata["finish_reason"] = r.FinishReason
+		}
+	}
+	if s.Err != nil {
+		g.Level, g.StatusMessage = "ERROR", s.Err.Error()
+	}
+	return g
+}
+
+type ingestionResponse struct {
+	Errors []struct {
+		ID      string `json:"id"`
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (e *Exporter) ingest(ctx context.Context, events []event) error {
+	body, err := json.Marshal(map[string]any{"batch": events})
+	if err != nil {
+		return fmt.Errorf("langfuse: encoding events: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.host+"/api/public/ingestion", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(e.publicKey, e.secretKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return llmerrors.FromResponse("langfuse", res)
+	}
+
+	// Events are validated one by one: the batch may partially fail
+	var out ingestionResponse
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return fmt.Errorf("langfuse: decoding response: %w", err)
+	}
+	if len(out.Errors) > 0 {
+		return fmt.Errorf("langfuse: %d of %d events rejected, first with status %d: %s", len(out.Errors), len(events), out.Errors[0].Status, out.Errors[0].Message)
+	}
+	return nil
+}
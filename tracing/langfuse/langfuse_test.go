@@ -0,0 +1,87 @@
+package langfuse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/tracing"
+)
+
+func TestExporter_Export(t *testing.T) {
+	var batch []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if r.URL.Path != "/api/public/ingestion" || user != "pk" || pass != "sk" {
+			t.Errorf("request to %s with credentials %q, %q", r.URL.Path, user, pass)
+		}
+		var body struct{ Batch []map[string]any }
+		json.NewDecoder(r.Body).Decode(&body)
+		batch = body.Batch
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, `{"successes":[],"errors":[]}`)
+	}))
+	defer srv.Close()
+
+	start := time.Now()
+	spans := []tracing.Span{
+		{
+			ID: "g1", TraceID: "t1", Operation: "generate", Provider: "openai", Attempts: 2, Start: start, End: start.Add(time.Second),
+			Request:  &generator.Request{Model: "gpt-4o", User: "alice", MaxTokens: 10, Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}},
+			Response: &generator.Response{Content: "hello", Usage: generator.TokenUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}, Cost: 0.01},
+		},
+		{ID: "g2", TraceID: "t1", Operation: "generate", Start: start, End: start, Request: &generator.Request{}, Err: errors.New("boom")},
+	}
+	if err := New("pk", "sk", WithHost(srv.URL+"/")).Export(context.Background(), spans); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if len(batch) != 4 || batch[0]["type"] != "trace-create" || batch[1]["type"] != "generation-create" {
+		t.Fatalf("batch = %v", batch)
+	}
+	if tr := batch[0]["body"].(map[string]any); tr["id"] != "t1" || tr["userId"] != "alice" {
+		t.Errorf("trace = %v", tr)
+	}
+	g := batch[1]["body"].(map[string]any)
+	if g["id"] != "g1" || g["traceId"] != "t1" || g["model"] != "gpt-4o" || g["level"] != "DEFAULT" {
+		t.Errorf("generation = %v", g)
+	}
+	if out := g["output"].(map[string]any); out["content"] != "hello" || out["role"] != "assistant" {
+		t.Errorf("generation output = %v", out)
+	}
+	if u := g["usageDetails"].(map[string]any); u["input"] != 3.0 || u["output"] != 2.0 || g["costDetails"].(map[string]any)["total"] != 0.01 {
+		t.Errorf("generation usage = %v, cost = %v", u, g["costDetails"])
+	}
+	if p := g["modelParameters"].(map[string]any); p["max_tokens"] != 10.0 {
+		t.Errorf("generation parameters = %v", p)
+	}
+	if g := batch[3]["body"].(map[string]any); g["level"] != "ERROR" || g["statusMessage"] != "boom" || g["output"] != nil {
+		t.Errorf("failed generation = %v", g)
+	}
+}
+
+func TestExporter_Errors(t *testing.T) {
+	status := http.StatusMultiStatus
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		io.WriteString(w, `{"successes":[],"errors":[{"id":"e1","status":400,"message":"invalid score"}]}`)
+	}))
+	defer srv.Close()
+	e := New("pk", "sk", WithHost(srv.URL))
+
+	err := e.Score(context.Background(), Score{TraceID: "t1", Name: "helpful", Value: 1})
+	if err == nil || !strings.Contains(err.Error(), "invalid score") {
+		t.Errorf("Score() error = %v, want the rejected event", err)
+	}
+	status = http.StatusUnauthorized
+	if err := e.Score(context.Background(), Score{TraceID: "t1", Name: "helpful", Value: 1}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Score() error = %v, want 401", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package langsmith provides a tracing.Exporter sending gollm calls to
+// LangSmith as LLM runs.
+package langsmith
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/parikxxit/go-llm/llmerrors"
+	"github.com/parikxxit/go-llm/tracing"
+	"github.com/parikxxit/go-llm/transport"
+)
+
+const defaultEndpoint = "https://api.smith.langchain.com"
+
+// Exporter sends spans to a LangSmith project. Each span is a root LLM run;
+// runs of the same trace share a thread.
+type Exporter struct {
+	apiKey     string
+	endpoint   string
+	project    string
+	httpClient *http.Client
+}
+
+// Option is a function that configures an Exporter
+type Option func(*Exporter)
+
+// WithEndpoint sets the API URL, https://api.smith.langchain.com by default
+func WithEndpoint(url string) Option {
+	return func(e *Exporter) {
+		e.endpoint = strings.TrimRight(url, "/")
+	}
+}
+
+// WithProject sets the project runs are logged to, "default" by default
+func WithProject(name string) Option {
+	return func(e *Exporter) {
+		e.project = name
+	}
+}
+
+// WithHTTPClient sets the HTTP client, transport.DefaultClient by default
+func WithHTTPClient(c *http.Client) Option {
+	return func(e *Exporter) {
+		e.httpClient = c
+	}
+}
+
+// New creates a new exporter authenticating with apiKey
+func New(apiKey string, opts ...Option) *Exporter {
+	e := &Exporter{apiKey: apiKey, endpoint: defaultEndpoint, project: "default", httpClient: transport.DefaultClient()}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+type run struct {
+	ID          string         `json:"id"`
+	TraceID     string         `json:"trace_id"`
+	DottedOrder string         `json:"dotted_order"`
+	Name        string         `json:"name"`
+	RunType     string         `json:"run_type"`
+	SessionName string         `json:"session_name"`
+	StartTime   time.Time      `json:"start_time"`
+	EndTime     time.Time      `json:"end_time"`
+	Inputs      map[string]any `json:"inputs"`
+	Outputs     map[string]any `json:"outputs,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	Extra       map[string]any `json:"extra"`
+}
+
+// Export creates the runs of spans in one batch
+func (e *Exporter) Export(ctx context.Context, spans []tracing.Span) error {
+	runs := make([]run, len(spans))
+	for i, s := range spans {
+		runs[i] = e.newRun(s)
+	}
+	body, err := json.Marshal(map[string]any{"post": runs})
+	if err != nil {
+		return fmt.Errorf("langsmith: encoding runs: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+"/runs/batch", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Api-Key", e.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return llmerrors.FromResponse("langsmith", res)
+	}
+	return nil
+}
+
+func (e *Exporter) newRun(s tracing.Span) run {
+	metadata := map[string]any{
+		// LangSmith groups the runs sharing a thread_id into a thread
+		"thread_id": s.TraceID,
+		"provider":  s.Provider,
+		"attempts":  s.Attempts,
+		// Picked up by LangSmith for cost tracking
+		"ls_provider":   s.Provider,
+		"ls_model_name": s.Model(),
+	}
+	if s.RequestID != "" {
+		metadata["request_id"] = s.RequestID
+	}
+	for k, v := range s.Request.Metadata {
+		metadata[k] = v
+	}
+	if s.Request.User != "" {
+		metadata["user"] = s.Request.User
+	}
+
+	r := run{
+		ID:          s.ID,
+		TraceID:     s.ID,
+		DottedOrder: dottedOrder(s.Start, s.ID),
+		Name:        string(s.Operation),
+		RunType:     "llm",
+		SessionName: e.project,
+		StartTime:   s.Start,
+		EndTime:     s.End,
+		Inputs:      map[string]any{"messages": s.Input()},
+		Extra:       map[string]any{"metadata": metadata, "invocation_params": s.Parameters()},
+	}
+	if resp := s.Response; resp != nil {
+		r.Outputs = map[string]any{
+			"choices": []map[string]any{{"index": 0, "message": s.Output(), "finish_reason": resp.FinishReason}},
+			"usage_metadata": map[string]int{
+				"input_tokens":  resp.Usage.PromptTokens,
+				"output_tokens": resp.Usage.CompletionTokens,
+				"total_tokens":  resp.Usage.TotalTokens,
+			},
+		}
+	}
+	if s.Err != nil {
+		r.Error = s.Err.Error()
+	}
+	return r
+}
+
+// dottedOrder returns the dotted order of a root run: its start time to the
+// microsecond followed by its ID
+func dottedOrder(start time.Time, id string) string {
+	start = start.UTC()
+	return fmt.Sprintf("%s%06dZ%s", start.Format("20060102T150405"), start.Nanosecond()/1000, id)
+}
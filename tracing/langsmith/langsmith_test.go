@@ -0,0 +1,80 @@
+package langsmith
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/tracing"
+)
+
+func TestExporter_Export(t *testing.T) {
+	var runs []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/runs/batch" || r.Header.Get("X-Api-Key") != "key" {
+			t.Errorf("request to %s with headers %v", r.URL.Path, r.Header)
+		}
+		var body struct{ Post []map[string]any }
+		json.NewDecoder(r.Body).Decode(&body)
+		runs = body.Post
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	spans := []tracing.Span{
+		{
+			ID: "r1", TraceID: "t1", Operation: "generate", Provider: "openai", Start: start, End: start.Add(time.Second),
+			Request:  &generator.Request{Model: "gpt-4o", Temperature: 0.2, Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}},
+			Response: &generator.Response{Content: "hello", FinishReason: "stop", Usage: generator.TokenUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}},
+		},
+		{ID: "r2", TraceID: "t1", Operation: "generate_stream", Start: start, End: start, Request: &generator.Request{}, Err: errors.New("boom")},
+	}
+	e := New("key", WithEndpoint(srv.URL), WithProject("chat"))
+	if err := e.Export(context.Background(), spans); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(runs))
+	}
+	r := runs[0]
+	if r["id"] != "r1" || r["trace_id"] != "r1" || r["dotted_order"] != "20240501T120000123456Zr1" || r["run_type"] != "llm" || r["session_name"] != "chat" {
+		t.Errorf("run = %v", r)
+	}
+	if msgs := r["inputs"].(map[string]any)["messages"].([]any); len(msgs) != 1 || msgs[0].(map[string]any)["content"] != "hi" {
+		t.Errorf("run inputs = %v", r["inputs"])
+	}
+	out := r["outputs"].(map[string]any)
+	if choice := out["choices"].([]any)[0].(map[string]any); choice["message"].(map[string]any)["content"] != "hello" {
+		t.Errorf("run outputs = %v", out)
+	}
+	if u := out["usage_metadata"].(map[string]any); u["input_tokens"] != 3.0 || u["total_tokens"] != 5.0 {
+		t.Errorf("run usage = %v", u)
+	}
+	extra := r["extra"].(map[string]any)
+	if extra["metadata"].(map[string]any)["thread_id"] != "t1" || extra["invocation_params"].(map[string]any)["temperature"] != 0.2 {
+		t.Errorf("run extra = %v", extra)
+	}
+	if runs[1]["error"] != "boom" || runs[1]["outputs"] != nil {
+		t.Errorf("failed run = %v", runs[1])
+	}
+}
+
+func TestExporter_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	err := New("key", WithEndpoint(srv.URL)).Export(context.Background(), []tracing.Span{{ID: "r1", Request: &generator.Request{}}})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Export() error = %v, want 403", err)
+	}
+}
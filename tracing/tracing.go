@@ -0,0 +1,384 @@
+// Package tracing exports gollm calls as spans to observability platforms. A
+// Tracer records Generate and GenerateStream calls through client middleware
+// and hooks, and hands them in batches to an Exporter in the background;
+// the subpackages export to Langfuse, LangSmith and Braintrust.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+)
+
+// MetadataTraceID is the response metadata key holding the trace ID of a
+// call, e.g. to attach scores to it later
+const MetadataTraceID = "trace_id"
+
+// ErrSpanDropped is passed to the error handler for spans dropped because
+// the export queue is full or the tracer is closed
+var ErrSpanDropped = errors.New("tracing: span dropped")
+
+// Span represents a traced call
+type Span struct {
+	ID        string
+	TraceID   string
+	Operation gollm.Operation
+	Provider  string // Generator of the last attempt
+	Attempts  int
+	RequestID string // Provider request ID of the last attempt
+	Start     time.Time
+	End       time.Time
+	// Request is the call's request with the messages sent to the provider
+	// by the last attempt, recorded by Hooks past the input guardrails, e.g.
+	// with PII redacted. Calls answered without a provider request, such as
+	// cache hits and blocked requests, have no messages.
+	Request *generator.Request
+	// Response is nil for failed calls; for streams it is assembled from the
+	// chunks
+	Response *generator.Response
+	Err      error
+}
+
+// Exporter sends spans to an observability platform
+type Exporter interface {
+	Export(ctx context.Context, spans []Span) error
+}
+
+// Tracer records calls as spans and exports them asynchronously
+type Tracer struct {
+	exporter  Exporter
+	batchSize int
+	interval  time.Duration
+	queueSize int
+	onError   func(error)
+
+	queue   chan Span
+	flushes chan chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	closed  atomic.Bool
+	once    sync.Once
+}
+
+// Option is a function that configures a Tracer
+type Option func(*Tracer)
+
+// WithBatchSize sets the maximum number of spans per export, 100 by default
+func WithBatchSize(n int) Option {
+	return func(t *Tracer) {
+		t.batchSize = n
+	}
+}
+
+// WithFlushInterval sets how often queued spans are exported, every 5
+// seconds by default
+func WithFlushInterval(d time.Duration) Option {
+	return func(t *Tracer) {
+		t.interval = d
+	}
+}
+
+// WithQueueSize sets the number of spans waiting for export beyond which
+// spans are dropped, 1000 by default
+func WithQueueSize(n int) Option {
+	return func(t *Tracer) {
+		t.queueSize = n
+	}
+}
+
+// WithErrorHandler sets the function receiving export errors and
+// ErrSpanDropped, which are otherwise dropped; calls never fail because of
+// tracing
+func WithErrorHandler(f func(error)) Option {
+	return func(t *Tracer) {
+		t.onError = f
+	}
+}
+
+// New creates a new tracer exporting to exporter. Close it to export the
+// remaining spans.
+func New(exporter Exporter, opts ...Option) *Tracer {
+	t := &Tracer{exporter: exporter, batchSize: 100, interval: 5 * time.Second, queueSize: 1000}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.batchSize = max(t.batchSize, 1)
+	t.queue = make(chan Span, max(t.queueSize, 1))
+	t.flushes = make(chan chan struct{})
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+	go t.run()
+	return t
+}
+
+// Option attaches the tracer to a client, through its middleware and hooks
+func (t *Tracer) Option() gollm.Option {
+	return func(c *gollm.Client) {
+		gollm.WithMiddleware(t.Middleware)(c)
+		gollm.WithStreamMiddleware(t.StreamMiddleware)(c)
+		gollm.WithHooks(t.Hooks())(c)
+	}
+}
+
+// Flush exports the queued spans, returning once they are exported or ctx
+// is done
+func (t *Tracer) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case t.flushes <- done:
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close exports the queued spans and stops the tracer; spans of calls ending
+// afterwards are dropped
+func (t *Tracer) Close(ctx context.Context) error {
+	t.once.Do(func() {
+		t.closed.Store(true)
+		close(t.stop)
+	})
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Tracer) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	var batch []Span
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.exporter.Export(context.Background(), batch); err != nil {
+			t.error(err)
+		}
+		batch = nil
+	}
+	add := func(s Span) {
+		batch = append(batch, s)
+		if len(batch) >= t.batchSize {
+			export()
+		}
+	}
+	drain := func() {
+		for {
+			select {
+			case s := <-t.queue:
+				add(s)
+			default:
+				export()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case s := <-t.queue:
+			add(s)
+		case <-ticker.C:
+			export()
+		case done := <-t.flushes:
+			drain()
+			close(done)
+		case <-t.stop:
+			drain()
+			return
+		}
+	}
+}
+
+func (t *Tracer) error(err error) {
+	if t.onError != nil {
+		t.onError(err)
+	}
+}
+
+// WithTrace makes the calls made with ctx spans of the trace id, e.g. the
+// steps of an agent run; calls otherwise start a trace each
+func WithTrace(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceKey{}, id)
+}
+
+type traceKey struct{}
+
+type spanKey struct{}
+
+// span is a Span being recorded; hooks may update it concurrently, e.g.
+// while hedging
+type span struct {
+	mu sync.Mutex
+	Span
+}
+
+// NewID returns a random UUID, as spans and traces are identified with
+func NewID() string {
+	return uuid.NewString()
+}
+
+func (t *Tracer) start(ctx context.Context, op gollm.Operation, req *generator.Request) (context.Context, *span) {
+	traceID, _ := ctx.Value(traceKey{}).(string)
+	if traceID == "" {
+		traceID = NewID()
+	}
+	// The messages are recorded once sent, past the guardrails
+	r := copyRequest(req)
+	r.Messages, r.Prefill = nil, ""
+	s := &span{Span: Span{ID: NewID(), TraceID: traceID, Operation: op, Start: time.Now(), Request: r}}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (t *Tracer) end(s *span, resp *generator.Response, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.End = time.Now()
+	s.Err = err
+	if resp != nil {
+		c := *resp
+		c.ToolCalls = append([]generator.ToolCall(nil), resp.ToolCalls...)
+		c.Metadata = maps.Clone(resp.Metadata)
+		c.Err = nil
+		s.Response = &c
+	}
+	if t.closed.Load() {
+		t.error(ErrSpanDropped)
+		return
+	}
+	select {
+	case t.queue <- s.Span:
+	default:
+		t.error(ErrSpanDropped)
+	}
+}
+
+// Hooks returns the hooks recording the provider, attempts, request ID and
+// messages sent of the spans being recorded by the middleware
+func (t *Tracer) Hooks() gollm.Hooks {
+	attempt := func(ctx context.Context, e gollm.Event) {
+		s, ok := ctx.Value(spanKey{}).(*span)
+		if !ok || e.Operation != s.Operation {
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.Provider, s.RequestID = e.Provider, e.RequestID
+		s.Attempts++
+		if e.Request != nil {
+			s.Request.Messages = append([]generator.Message(nil), e.Request.Messages...)
+			s.Request.Prefill = e.Request.Prefill
+		}
+	}
+	return gollm.Hooks{OnResponse: attempt, OnError: attempt}
+}
+
+// Middleware traces Generate calls, setting MetadataTraceID on responses
+func (t *Tracer) Middleware(next gollm.GenerateFunc) gollm.GenerateFunc {
+	return func(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+		ctx, s := t.start(ctx, gollm.OpGenerate, req)
+		resp, err := next(ctx, req)
+		t.end(s, resp, err)
+		if resp != nil {
+			tag(resp, s.TraceID)
+		}
+		return resp, err
+	}
+}
+
+// StreamMiddleware traces GenerateStream calls once their stream closes,
+// setting MetadataTraceID on the first chunk
+func (t *Tracer) StreamMiddleware(next gollm.GenerateStreamFunc) gollm.GenerateStreamFunc {
+	return func(ctx context.Context, req *generator.Request) (<-chan *generator.Response, error) {
+		ctx, s := t.start(ctx, gollm.OpGenerateStream, req)
+		stream, err := next(ctx, req)
+		if err != nil {
+			t.end(s, nil, err)
+			return nil, err
+		}
+
+		out := make(chan *generator.Response)
+		go func() {
+			defer close(out)
+			var content, reasoning strings.Builder
+			resp := &generator.Response{}
+			var streamErr error
+			defer func() {
+				resp.Content, resp.Reasoning = content.String(), reasoning.String()
+				t.end(s, resp, streamErr)
+			}()
+			tagged := false
+			for chunk := range stream {
+				content.WriteString(chunk.Content)
+				reasoning.WriteString(chunk.Reasoning)
+				if resp.ID == "" {
+					resp.ID = chunk.ID
+				}
+				if resp.Model == "" {
+					resp.Model = chunk.Model
+				}
+				resp.ToolCalls = append(resp.ToolCalls, chunk.ToolCalls...)
+				if chunk.FinishReason != "" {
+					resp.FinishReason = chunk.FinishReason
+				}
+				if chunk.Usage != (generator.TokenUsage{}) {
+					resp.Usage, resp.Cost = chunk.Usage, chunk.Cost
+				}
+				if chunk.Err != nil {
+					streamErr = chunk.Err
+				}
+				if !tagged {
+					tag(chunk, s.TraceID)
+					tagged = true
+				}
+				select {
+				case out <- chunk:
+				case <-ctx.Done():
+					streamErr = ctx.Err()
+					return
+				}
+			}
+		}()
+		return out, nil
+	}
+}
+
+func tag(resp *generator.Response, traceID string) {
+	if resp.Metadata == nil {
+		resp.Metadata = map[string]string{}
+	}
+	resp.Metadata[MetadataTraceID] = traceID
+}
+
+// copyRequest copies req, which may be changed by the caller once the call
+// returns
+func copyRequest(req *generator.Request) *generator.Request {
+	c := *req
+	c.Messages = append([]generator.Message(nil), req.Messages...)
+	c.Stop = append([]string(nil), req.Stop...)
+	c.Tools = append([]generator.Tool(nil), req.Tools...)
+	c.ProviderParams = maps.Clone(req.ProviderParams)
+	c.Metadata = maps.Clone(req.Metadata)
+	return &c
+}
@@ -0,0 +1,147 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gollm "github.com/parikxxit/go-llm"
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/guardrails"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]Span
+}
+
+func (r *recorder) Export(_ context.Context, spans []Span) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, spans)
+	return nil
+}
+
+func (r *recorder) spans() []Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var all []Span
+	for _, b := range r.batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+func TestTracer(t *testing.T) {
+	rec := &recorder{}
+	tracer := New(rec, WithFlushInterval(time.Hour))
+	client := gollm.NewClient(mock.New(), tracer.Option())
+
+	req := &generator.Request{Model: "m", User: "u", Temperature: 0.5, Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}}
+	resp, err := client.Generate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	ctx := WithTrace(context.Background(), "run-1")
+	stream, err := client.GenerateStream(ctx, req)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	var streamTrace string
+	for chunk := range stream {
+		if id := chunk.Metadata[MetadataTraceID]; id != "" {
+			streamTrace = id
+		}
+	}
+	req.Messages[0].Content = "changed"
+
+	if err := tracer.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	spans := rec.spans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	s := spans[0]
+	if s.TraceID == "" || s.TraceID != resp.Metadata[MetadataTraceID] || s.ID == s.TraceID {
+		t.Errorf("span IDs = %q, %q, response trace = %q", s.ID, s.TraceID, resp.Metadata[MetadataTraceID])
+	}
+	if s.Operation != gollm.OpGenerate || s.Provider != "mock" || s.Attempts != 1 || s.Model() != "m" {
+		t.Errorf("span = %+v", s)
+	}
+	if in := s.Input(); len(in) != 1 || in[0].Content != "hi" || s.Output().Content != "hi" {
+		t.Errorf("span input = %+v, output = %+v", in, s.Output())
+	}
+	if s.Parameters()["temperature"] != 0.5 || s.End.Before(s.Start) {
+		t.Errorf("span parameters = %v, times = %v, %v", s.Parameters(), s.Start, s.End)
+	}
+
+	if s := spans[1]; s.TraceID != "run-1" || streamTrace != "run-1" || s.Operation != gollm.OpGenerateStream || s.Response.Content != "hi" {
+		t.Errorf("stream span = %+v, chunk trace = %q", s, streamTrace)
+	}
+}
+
+func TestTracer_Guardrails(t *testing.T) {
+	rec := &recorder{}
+	tracer := New(rec, WithFlushInterval(time.Hour))
+	p, err := guardrails.New(&guardrails.PII{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := gollm.NewClient(mock.New(), gollm.WithGuardrails(p), tracer.Option())
+
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "mail jane@example.com"}}}
+	if _, err := client.Generate(context.Background(), req); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	stream, err := client.GenerateStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	for range stream {
+	}
+	if err := tracer.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	for _, s := range rec.spans() {
+		if in := s.Input(); len(in) != 1 || in[0].Content != "mail <EMAIL_1>" {
+			t.Errorf("%s span input = %+v, want the redacted prompt", s.Operation, in)
+		}
+	}
+}
+
+func TestTracer_Errors(t *testing.T) {
+	rec := &recorder{}
+	var dropped int
+	tracer := New(rec, WithBatchSize(2), WithFlushInterval(time.Hour), WithErrorHandler(func(err error) {
+		if errors.Is(err, ErrSpanDropped) {
+			dropped++
+		}
+	}))
+	m := mock.New()
+	m.GenerateFunc = func(context.Context, *generator.Request) (*generator.Response, error) {
+		return nil, errors.New("boom")
+	}
+	client := gollm.NewClient(m, tracer.Option(), gollm.WithRetryCount(0))
+
+	for range 3 {
+		client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+	}
+	if err := tracer.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	client.Generate(context.Background(), &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "hi"}}})
+
+	if len(rec.batches) != 2 || len(rec.batches[0]) != 2 {
+		t.Errorf("got batches of %v spans, want 2 then 1", rec.batches)
+	}
+	if s := rec.spans()[0]; s.Err == nil || s.Response != nil || s.Output() != nil {
+		t.Errorf("failed span = %+v", s)
+	}
+	if dropped != 1 {
+		t.Errorf("dropped = %d, want 1", dropped)
+	}
+}
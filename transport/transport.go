@@ -0,0 +1,94 @@
+// Package transport builds the HTTP transports of providers, tuned for
+// many concurrent API requests: http.DefaultTransport keeps only 2 idle
+// connections per host, so concurrent embedding jobs keep opening fresh TLS
+// connections instead of reusing them.
+package transport
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Defaults of the Config fields left zero
+const (
+	DefaultMaxIdleConns          = 256
+	DefaultMaxIdleConnsPerHost   = 64
+	DefaultIdleConnTimeout       = 90 * time.Second
+	DefaultKeepAlive             = 30 * time.Second
+	DefaultDialTimeout           = 10 * time.Second
+	DefaultTLSHandshakeTimeout   = 10 * time.Second
+	DefaultExpectContinueTimeout = time.Second
+)
+
+// Config represents the tuning of a transport; zero fields take the
+// defaults
+type Config struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps the connections per host, dialing, active and
+	// idle; zero means no limit
+	MaxConnsPerHost int
+	IdleConnTimeout time.Duration
+	// KeepAlive is the interval of TCP keep-alive probes; negative disables
+	// them
+	KeepAlive   time.Duration
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout and ExpectContinueTimeout are as in http.Transport
+	TLSHandshakeTimeout   time.Duration
+	ExpectContinueTimeout time.Duration
+	// ResponseHeaderTimeout bounds the wait for response headers after the
+	// request is sent; zero means no bound. There is none by default because
+	// non-streaming completions only send headers once generated: bound
+	// requests with the timeouts of the client instead.
+	ResponseHeaderTimeout time.Duration
+	// DisableHTTP2 sticks to HTTP/1.1; HTTP/2 is negotiated by default
+	DisableHTTP2 bool
+}
+
+// New returns a transport of cfg, proxied by the environment like
+// http.DefaultTransport
+func New(cfg Config) *http.Transport {
+	or := func(v, def time.Duration) time.Duration {
+		if v == 0 {
+			return def
+		}
+		return v
+	}
+	orInt := func(v, def int) int {
+		if v == 0 {
+			return def
+		}
+		return v
+	}
+	dialer := &net.Dialer{
+		Timeout:   or(cfg.DialTimeout, DefaultDialTimeout),
+		KeepAlive: or(cfg.KeepAlive, DefaultKeepAlive),
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     !cfg.DisableHTTP2,
+		MaxIdleConns:          orInt(cfg.MaxIdleConns, DefaultMaxIdleConns),
+		MaxIdleConnsPerHost:   orInt(cfg.MaxIdleConnsPerHost, DefaultMaxIdleConnsPerHost),
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       or(cfg.IdleConnTimeout, DefaultIdleConnTimeout),
+		TLSHandshakeTimeout:   or(cfg.TLSHandshakeTimeout, DefaultTLSHandshakeTimeout),
+		ExpectContinueTimeout: or(cfg.ExpectContinueTimeout, DefaultExpectContinueTimeout),
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+	}
+}
+
+// Client returns an HTTP client of a transport of cfg
+func Client(cfg Config) *http.Client {
+	return &http.Client{Transport: New(cfg)}
+}
+
+var defaultClient = sync.OnceValue(func() *http.Client { return Client(Config{}) })
+
+// DefaultClient returns the client of providers not given one, shared so
+// its connections are reused across providers
+func DefaultClient() *http.Client {
+	return defaultClient()
+}
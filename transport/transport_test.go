@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tr := New(Config{})
+	if tr.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || tr.MaxIdleConns != DefaultMaxIdleConns || tr.IdleConnTimeout != DefaultIdleConnTimeout {
+		t.Errorf("New() pool = %d/%d, idle %v, want the defaults", tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.IdleConnTimeout)
+	}
+	if !tr.ForceAttemptHTTP2 || tr.ResponseHeaderTimeout != 0 || tr.Proxy == nil {
+		t.Errorf("New() = %+v, want HTTP/2, no header timeout and the environment proxy", tr)
+	}
+
+	tr = New(Config{MaxIdleConnsPerHost: 8, MaxConnsPerHost: 16, ResponseHeaderTimeout: time.Minute, DisableHTTP2: true})
+	if tr.MaxIdleConnsPerHost != 8 || tr.MaxConnsPerHost != 16 || tr.ResponseHeaderTimeout != time.Minute || tr.ForceAttemptHTTP2 {
+		t.Errorf("New() = %+v, want the config", tr)
+	}
+}
+
+func TestDefaultClient(t *testing.T) {
+	if DefaultClient() != DefaultClient() {
+		t.Error("DefaultClient() is not shared")
+	}
+}
@@ -0,0 +1,66 @@
+// Package tts provides interfaces and types for text-to-speech.
+package tts
+
+import (
+	"context"
+	"io"
+)
+
+// Format represents an audio encoding
+type Format string
+
+const (
+	FormatMP3  Format = "mp3"
+	FormatOpus Format = "opus"
+	FormatAAC  Format = "aac"
+	FormatFLAC Format = "flac"
+	FormatWAV  Format = "wav"
+	FormatPCM  Format = "pcm" // Raw 16-bit little-endian samples
+)
+
+// MediaType returns the MIME type of the format
+func (f Format) MediaType() string {
+	switch f {
+	case FormatMP3:
+		return "audio/mpeg"
+	case FormatOpus:
+		return "audio/ogg"
+	case FormatPCM:
+		return "audio/pcm"
+	case "":
+		return ""
+	default:
+		return "audio/" + string(f)
+	}
+}
+
+// Request represents a speech synthesis request
+type Request struct {
+	Model string
+	Text  string
+	// Voice is a provider voice name or ID
+	Voice string
+	// Format is the audio encoding, mp3 when empty
+	Format Format
+	// Speed scales the speaking rate, 1 when zero
+	Speed float64
+	// Instructions steer tone and delivery, where supported
+	Instructions   string
+	ProviderParams map[string]interface{}
+}
+
+// Response represents synthesized speech. Audio streams as the provider
+// produces it and must be closed by the caller.
+type Response struct {
+	Audio  io.ReadCloser
+	Format Format
+}
+
+// Synthesizer defines the interface for text-to-speech
+type Synthesizer interface {
+	// Synthesize sends a speech synthesis request
+	Synthesize(ctx context.Context, req *Request) (*Response, error)
+
+	// GetSynthesizerName returns the name of the implementation
+	GetSynthesizerName() string
+}
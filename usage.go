@@ -0,0 +1,153 @@
+package gollm
+
+import (
+	"context"
+	"time"
+)
+
+const defaultUsageRetention = 24 * time.Hour
+
+// UsageReport represents the usage of a client over a period, broken down
+// for chargeback
+type UsageReport struct {
+	Since   time.Time
+	Until   time.Time
+	Total   Usage
+	ByModel map[string]Usage
+	// ByUser is keyed by Request.User; requests without one are under ""
+	ByUser map[string]Usage
+	// ByTag is keyed by the "key=value" pairs of Request.Metadata
+	ByTag map[string]Usage
+}
+
+// usageBucket aggregates the usage of a minute
+type usageBucket struct {
+	start   time.Time
+	total   Usage
+	byModel map[string]Usage
+	byUser  map[string]Usage
+	byTag   map[string]Usage
+}
+
+// WithUsageRetention sets how long usage is kept for UsageReport, at a
+// minute granularity; 24 hours by default
+func WithUsageRetention(d time.Duration) Option {
+	return func(c *Client) {
+		c.meter.retention = d
+	}
+}
+
+// WithUsageSnapshots calls f about every interval, of a minute or more, with
+// the report of the usage since the previous call, until ctx is done. Reports
+// cover whole minutes up to the current one, excluded. f runs on its own
+// goroutine.
+func WithUsageSnapshots(ctx context.Context, every time.Duration, f func(UsageReport)) Option {
+	return func(c *Client) {
+		c.meter.snapshotCtx, c.meter.snapshotEvery, c.meter.onSnapshot = ctx, every, f
+	}
+}
+
+// UsageReport returns the usage of the provider requests made since the
+// start of the minute of since, within the usage retention
+func (c *Client) UsageReport(since time.Time) UsageReport {
+	now := time.Now()
+	c.meter.mu.Lock()
+	r := c.meter.report(since, now.Truncate(time.Minute).Add(time.Minute))
+	c.meter.mu.Unlock()
+	r.Until = now
+	return r
+}
+
+// add records usage in the bucket of the current minute, dropping buckets
+// past the retention. The meter lock must be held.
+func (m *usageMeter) add(model, user string, tags map[string]string, u Usage) {
+	now := time.Now().Truncate(time.Minute)
+	if n := len(m.buckets); n == 0 || !m.buckets[n-1].start.Equal(now) {
+		m.buckets = append(m.buckets, &usageBucket{
+			start:   now,
+			byModel: map[string]Usage{},
+			byUser:  map[string]Usage{},
+			byTag:   map[string]Usage{},
+		})
+	}
+	retention := m.retention
+	if retention <= 0 {
+		retention = defaultUsageRetention
+	}
+	retention = max(retention, m.snapshotEvery)
+	drop := 0
+	for drop < len(m.buckets) && m.buckets[drop].start.Before(now.Add(-retention)) {
+		drop++
+	}
+	m.buckets = m.buckets[drop:]
+
+	b := m.buckets[len(m.buckets)-1]
+	b.total = b.total.add(u)
+	b.byModel[model] = b.byModel[model].add(u)
+	b.byUser[user] = b.byUser[user].add(u)
+	for k, v := range tags {
+		tag := k + "=" + v
+		b.byTag[tag] = b.byTag[tag].add(u)
+	}
+}
+
+// report sums the buckets starting in [since, until), since truncated to the
+// minute. The meter lock must be held.
+func (m *usageMeter) report(since, until time.Time) UsageReport {
+	r := UsageReport{
+		Since:   since,
+		Until:   until,
+		ByModel: map[string]Usage{},
+		ByUser:  map[string]Usage{},
+		ByTag:   map[string]Usage{},
+	}
+	for _, b := range m.buckets {
+		if b.start.Before(since.Truncate(time.Minute)) || !b.start.Before(until) {
+			continue
+		}
+		r.Total = r.Total.add(b.total)
+		merge(r.ByModel, b.byModel)
+		merge(r.ByUser, b.byUser)
+		merge(r.ByTag, b.byTag)
+	}
+	return r
+}
+
+// snapshot calls onSnapshot every snapshotEvery until snapshotCtx is done.
+// Reports cover whole minutes, so each request is in exactly one.
+func (m *usageMeter) snapshot() {
+	ticker := time.NewTicker(m.snapshotEvery)
+	defer ticker.Stop()
+	since := time.Now().Truncate(time.Minute)
+	for {
+		select {
+		case now := <-ticker.C:
+			until := now.Truncate(time.Minute)
+			if !until.After(since) {
+				continue
+			}
+			m.mu.Lock()
+			r := m.report(since, until)
+			m.mu.Unlock()
+			m.onSnapshot(r)
+			since = until
+		case <-m.snapshotCtx.Done():
+			return
+		}
+	}
+}
+
+func (u Usage) add(o Usage) Usage {
+	u.Requests += o.Requests
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.TotalTokens += o.TotalTokens
+	u.Cost += o.Cost
+	return u
+}
+
+func merge(dst, src map[string]Usage) {
+	for k, u := range src {
+		dst[k] = dst[k].add(u)
+	}
+}
@@ -0,0 +1,75 @@
+package gollm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+)
+
+func TestClient_UsageReport(t *testing.T) {
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		return &generator.Response{Usage: generator.TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}}, nil
+	}
+	client := NewClient(m)
+	requests := []*generator.Request{
+		{Model: "a", User: "alice", Metadata: map[string]string{"team": "search"}},
+		{Model: "a", User: "bob", Metadata: map[string]string{"team": "search", "feature": "chat"}},
+		{Model: "b", User: "alice"},
+	}
+	start := time.Now()
+	for _, req := range requests {
+		req.Messages = []generator.Message{{Role: generator.USER, Content: "hi"}}
+		if _, err := client.Generate(context.Background(), req); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+
+	r := client.UsageReport(start)
+	if r.Total.Requests != 3 || r.Total.TotalTokens != 45 || r.Until.Before(start) {
+		t.Errorf("UsageReport().Total = %+v, Until = %v", r.Total, r.Until)
+	}
+	tests := []struct {
+		name string
+		got  Usage
+		want int
+	}{
+		{"model a", r.ByModel["a"], 2},
+		{"model b", r.ByModel["b"], 1},
+		{"user alice", r.ByUser["alice"], 2},
+		{"user bob", r.ByUser["bob"], 1},
+		{"tag team", r.ByTag["team=search"], 2},
+		{"tag feature", r.ByTag["feature=chat"], 1},
+	}
+	for _, tt := range tests {
+		if tt.got.Requests != tt.want || tt.got.PromptTokens != 10*tt.want {
+			t.Errorf("UsageReport() %s = %+v, want %d requests", tt.name, tt.got, tt.want)
+		}
+	}
+	if r := client.UsageReport(time.Now().Add(time.Minute)); r.Total.Requests != 0 {
+		t.Errorf("UsageReport(future) = %+v, want no usage", r.Total)
+	}
+}
+
+func TestUsageMeter_Report(t *testing.T) {
+	m := &usageMeter{retention: 2 * time.Minute}
+	m.add("a", "", nil, Usage{Requests: 1})
+	now := time.Now().Truncate(time.Minute)
+	// Backdate the bucket as if recorded three minutes ago
+	m.buckets[0].start = now.Add(-3 * time.Minute)
+	m.add("a", "", nil, Usage{Requests: 1})
+
+	if len(m.buckets) != 1 {
+		t.Errorf("kept %d buckets, want 1 within retention", len(m.buckets))
+	}
+	// Snapshot windows end at the current minute, excluded
+	if r := m.report(now.Add(-time.Hour), now); r.Total.Requests != 0 {
+		t.Errorf("report() up to the current minute = %+v, want no usage", r.Total)
+	}
+	if r := m.report(now, now.Add(time.Minute)); r.Total.Requests != 1 {
+		t.Errorf("report() of the current minute = %+v, want 1 request", r.Total)
+	}
+}
@@ -0,0 +1,63 @@
+package gollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/validate"
+)
+
+const defaultMaxRepairs = 2
+
+// ValidateOptions configures GenerateValidated
+type ValidateOptions struct {
+	// Validators are run in order on every response
+	Validators []validate.Validator
+	// MaxRetries bounds the repair attempts after the first response, 2 when
+	// zero; negative disables repairs
+	MaxRetries int
+	// RepairPrompt formats the message asking for a repair from the
+	// validation error. The default quotes the error and asks for a complete
+	// corrected response.
+	RepairPrompt func(err error) string
+}
+
+// GenerateValidated sends request and validates the response. Invalid
+// responses are sent back to the model with the validation error, up to
+// MaxRetries times, before a *validate.ValidationError is returned.
+func (c *Client) GenerateValidated(ctx context.Context, request *generator.Request, opts ValidateOptions) (*generator.Response, error) {
+	retries := opts.MaxRetries
+	if retries == 0 {
+		retries = defaultMaxRepairs
+	}
+	retries = max(retries, 0)
+	repair := opts.RepairPrompt
+	if repair == nil {
+		repair = defaultRepairPrompt
+	}
+
+	req := *request
+	req.Messages = append([]generator.Message(nil), request.Messages...)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.Generate(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		verr := validate.All(resp.Content, opts.Validators...)
+		if verr == nil {
+			return resp, nil
+		}
+		if attempt > retries {
+			return nil, &validate.ValidationError{Attempts: attempt, Content: resp.Content, Err: verr}
+		}
+		req.Messages = append(req.Messages,
+			generator.Message{Role: generator.ASSISTANT, Content: resp.Content},
+			generator.Message{Role: generator.USER, Content: repair(verr)},
+		)
+	}
+}
+
+func defaultRepairPrompt(err error) string {
+	return fmt.Sprintf("Your response is invalid: %v.\nReply with the complete corrected response only.", err)
+}
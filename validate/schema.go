@@ -0,0 +1,169 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/parikxxit/go-llm/guardrails"
+)
+
+// JSONSchema requires content to be JSON matching schema, e.g. one built by
+// tools.Schema. The supported keywords are type, properties, required,
+// additionalProperties, items, enum, minimum, maximum, minLength, maxLength,
+// minItems and maxItems; others are ignored.
+func JSONSchema(schema map[string]interface{}) Validator {
+	return Func(func(content string) error {
+		var v any
+		if err := json.Unmarshal([]byte(guardrails.StripCodeFence(content)), &v); err != nil {
+			return fmt.Errorf("the response is not valid JSON: %v", err)
+		}
+		return check(schema, v, "$")
+	})
+}
+
+func check(schema map[string]interface{}, v any, path string) error {
+	if t, ok := schema["type"].(string); ok && !hasType(v, t) {
+		return fmt.Errorf("%s must be of type %s", path, t)
+	}
+	if enum, ok := schema["enum"]; ok {
+		found := false
+		for _, e := range list(enum) {
+			if reflect.DeepEqual(normalize(e), v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s must be one of %v", path, list(enum))
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range list(schema["required"]) {
+			if _, ok := v[fmt.Sprint(name)]; !ok {
+				return fmt.Errorf("%s is missing the required property %q", path, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sub, ok := properties[name].(map[string]interface{})
+			if !ok {
+				switch extra := schema["additionalProperties"].(type) {
+				case bool:
+					if !extra {
+						return fmt.Errorf("%s has the unexpected property %q", path, name)
+					}
+				case map[string]interface{}:
+					sub = extra
+				}
+			}
+			if sub != nil {
+				if err := check(sub, v[name], path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if err := bounds(schema, "minItems", "maxItems", float64(len(v)), path, "items"); err != nil {
+			return err
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := check(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		return bounds(schema, "minLength", "maxLength", float64(len([]rune(v))), path, "characters")
+	case float64:
+		return bounds(schema, "minimum", "maximum", v, path, "")
+	}
+	return nil
+}
+
+func bounds(schema map[string]interface{}, minKey, maxKey string, n float64, path, unit string) error {
+	if unit != "" {
+		unit = " " + unit
+	}
+	if min, ok := number(schema[minKey]); ok && n < min {
+		return fmt.Errorf("%s must have at least %v%s", path, min, unit)
+	}
+	if max, ok := number(schema[maxKey]); ok && n > max {
+		return fmt.Errorf("%s must have at most %v%s", path, max, unit)
+	}
+	return nil
+}
+
+func hasType(v any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "null":
+		return v == nil
+	}
+	return true
+}
+
+// list returns the elements of a []string or []interface{} schema value
+func list(v any) []any {
+	switch v := v.(type) {
+	case []any:
+		return v
+	case []string:
+		out := make([]any, len(v))
+		for i, s := range v {
+			out[i] = s
+		}
+		return out
+	}
+	return nil
+}
+
+// normalize converts Go schema values to their decoded JSON form
+func normalize(v any) any {
+	if f, ok := number(v); ok {
+		return f
+	}
+	return v
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
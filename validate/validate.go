@@ -0,0 +1,91 @@
+// Package validate provides validators for model output, used by
+// Client.GenerateValidated to ask the model to repair invalid responses.
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/parikxxit/go-llm/guardrails"
+)
+
+// Validator checks the content of a response. Errors are sent back to the
+// model, so they should say what is wrong in plain words.
+type Validator interface {
+	Validate(content string) error
+}
+
+// Func adapts a function to a Validator
+type Func func(content string) error
+
+func (f Func) Validate(content string) error {
+	return f(content)
+}
+
+// Regex requires content to match pattern
+func Regex(pattern string) (Validator, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return Func(func(content string) error {
+		if !re.MatchString(content) {
+			return fmt.Errorf("the response must match the regular expression %s", re)
+		}
+		return nil
+	}), nil
+}
+
+// JSON requires content to be valid JSON, optionally in a Markdown code fence
+func JSON() Validator {
+	return Func(func(content string) error {
+		var v any
+		if err := json.Unmarshal([]byte(guardrails.StripCodeFence(content)), &v); err != nil {
+			return fmt.Errorf("the response is not valid JSON: %v", err)
+		}
+		return nil
+	})
+}
+
+// Struct requires content to decode into a T without unknown fields
+func Struct[T any]() Validator {
+	return Func(func(content string) error {
+		dec := json.NewDecoder(strings.NewReader(guardrails.StripCodeFence(content)))
+		dec.DisallowUnknownFields()
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			return fmt.Errorf("the response does not match the expected JSON structure: %v", err)
+		}
+		return nil
+	})
+}
+
+// ValidationError is returned when a response still fails validation after
+// every repair attempt
+type ValidationError struct {
+	Attempts int
+	// Content is the last invalid response content
+	Content string
+	// Err is the validation error of the last attempt
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("response failed validation after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// All runs validators in order and returns the first error
+func All(content string, validators ...Validator) error {
+	for _, v := range validators {
+		if err := v.Validate(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{"type": "string", "minLength": 1},
+			"age":  map[string]interface{}{"type": "integer", "minimum": 0},
+			"tags": map[string]interface{}{"type": "array", "items": map[string]interface{}{"enum": []string{"a", "b"}}},
+		},
+		"required":             []string{"name"},
+		"additionalProperties": false,
+	}
+	v := JSONSchema(schema)
+
+	tests := []struct {
+		content string
+		want    string // Substring of the error, empty when valid
+	}{
+		{"```json\n{\"name\": \"x\", \"age\": 3, \"tags\": [\"a\"]}\n```", ""},
+		{`{"age": 3}`, `required property "name"`},
+		{`{"name": "x", "age": 2.5}`, "$.age must be of type integer"},
+		{`{"name": "x", "age": -1}`, "$.age must have at least 0"},
+		{`{"name": "x", "tags": ["c"]}`, "$.tags[0] must be one of"},
+		{`{"name": "x", "extra": 1}`, `unexpected property "extra"`},
+		{`{"name": ""}`, "at least 1 characters"},
+		{`not json`, "not valid JSON"},
+	}
+	for _, tt := range tests {
+		err := v.Validate(tt.content)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("Validate(%q) error = %v", tt.content, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("Validate(%q) error = %v, want %q", tt.content, err, tt.want)
+		}
+	}
+}
+
+func TestStruct(t *testing.T) {
+	type answer struct {
+		Answer string `json:"answer"`
+	}
+	v := Struct[answer]()
+	if err := v.Validate(`{"answer": "yes"}`); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := v.Validate(`{"reply": "yes"}`); err == nil {
+		t.Error("Validate() of an unknown field error = nil")
+	}
+}
@@ -0,0 +1,43 @@
+package gollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/parikxxit/go-llm/generator"
+	"github.com/parikxxit/go-llm/providers/mock"
+	"github.com/parikxxit/go-llm/validate"
+)
+
+func TestClient_GenerateValidated(t *testing.T) {
+	var calls int
+	m := mock.New()
+	m.GenerateFunc = func(_ context.Context, req *generator.Request) (*generator.Response, error) {
+		calls++
+		last := req.Messages[len(req.Messages)-1]
+		if strings.Contains(last.Content, "invalid") {
+			return &generator.Response{Content: `{"ok": true}`}, nil
+		}
+		return &generator.Response{Content: "ok"}, nil
+	}
+	client := NewClient(m)
+	req := &generator.Request{Messages: []generator.Message{{Role: generator.USER, Content: "reply in JSON"}}}
+
+	resp, err := client.GenerateValidated(context.Background(), req, ValidateOptions{Validators: []validate.Validator{validate.JSON()}})
+	if err != nil {
+		t.Fatalf("GenerateValidated() error = %v", err)
+	}
+	if resp.Content != `{"ok": true}` || calls != 2 || len(req.Messages) != 1 {
+		t.Errorf("GenerateValidated() = %q after %d calls, request messages = %d", resp.Content, calls, len(req.Messages))
+	}
+
+	calls = 0
+	never := validate.Func(func(string) error { return errors.New("never valid") })
+	_, err = client.GenerateValidated(context.Background(), req, ValidateOptions{Validators: []validate.Validator{never}, MaxRetries: 1})
+	var verr *validate.ValidationError
+	if !errors.As(err, &verr) || verr.Attempts != 2 || calls != 2 {
+		t.Errorf("GenerateValidated() error = %v after %d calls, want a ValidationError after 2 attempts", err, calls)
+	}
+}
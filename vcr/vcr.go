@@ -0,0 +1,307 @@
+// Package vcr records the HTTP interactions of providers to fixture files,
+// cassettes, and replays them, so provider integration tests run in CI
+// without credentials or network. Credentials are stripped before
+// interactions are written:
+//
+//	rec := vcr.New(t, "testdata/generate.json")
+//	llm := openai.NewOpenAI(generator.Config{ApiKey: os.Getenv("OPENAI_API_KEY"), HTTPClient: rec.Client()})
+//
+// The first run, with credentials, records the cassette; later runs replay
+// it. Delete the cassette or use ModeRecord to record it again.
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Mode represents whether a recorder records or replays
+type Mode int
+
+const (
+	// ModeAuto replays the cassette when it exists and records it otherwise
+	ModeAuto Mode = iota
+	// ModeRecord sends every request, recording a new cassette
+	ModeRecord
+	// ModeReplay answers from the cassette, failing requests not recorded
+	ModeReplay
+)
+
+// Redacted replaces the values of redacted query parameters
+const Redacted = "REDACTED"
+
+// Interaction represents a recorded request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request represents a recorded request
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response represents a recorded response. Streamed bodies are recorded
+// whole and replayed at once.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Matcher reports whether the recorded request i answers the request r;
+// both are sanitized
+type Matcher func(r *Request, i *Request) bool
+
+// DefaultMatcher matches requests of the same method, URL and body
+func DefaultMatcher(r *Request, i *Request) bool {
+	return r.Method == i.Method && r.URL == i.URL && r.Body == i.Body
+}
+
+// Option is a function that configures a Recorder
+type Option func(*Recorder)
+
+// WithMode sets the mode, ModeAuto by default
+func WithMode(m Mode) Option {
+	return func(r *Recorder) {
+		r.mode = m
+	}
+}
+
+// WithTransport sets the transport sending requests while recording,
+// http.DefaultTransport by default
+func WithTransport(t http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.next = t
+	}
+}
+
+// WithRedactedHeaders strips these headers too from recorded requests and
+// responses, besides Authorization, Api-Key, X-Api-Key, X-Goog-Api-Key,
+// Xi-Api-Key, Ocp-Apim-Subscription-Key, X-Amz-Security-Token,
+// Proxy-Authorization, Cookie and Set-Cookie
+func WithRedactedHeaders(names ...string) Option {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.headers = append(r.headers, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// WithRedactedQuery redacts these query parameters too, besides key,
+// api_key and access_token
+func WithRedactedQuery(params ...string) Option {
+	return func(r *Recorder) {
+		r.query = append(r.query, params...)
+	}
+}
+
+// WithSanitizer calls f on every interaction before it is recorded and on
+// every request before it is matched, e.g. to scrub account IDs from bodies
+func WithSanitizer(f func(*Interaction)) Option {
+	return func(r *Recorder) {
+		r.sanitizers = append(r.sanitizers, f)
+	}
+}
+
+// WithMatcher sets how requests are matched, DefaultMatcher by default
+func WithMatcher(m Matcher) Option {
+	return func(r *Recorder) {
+		r.match = m
+	}
+}
+
+// redactedHeaders are the credentials of the providers, stripped by default
+var redactedHeaders = []string{
+	"Authorization", "Api-Key", "X-Api-Key", "X-Goog-Api-Key", "Xi-Api-Key", "Ocp-Apim-Subscription-Key",
+	"X-Amz-Security-Token", "Proxy-Authorization", "Cookie", "Set-Cookie",
+}
+
+// Recorder is an http.RoundTripper recording or replaying a cassette. It is
+// safe for concurrent use.
+type Recorder struct {
+	path       string
+	mode       Mode
+	next       http.RoundTripper
+	headers    []string
+	query      []string
+	sanitizers []func(*Interaction)
+	match      Matcher
+
+	mu       sync.Mutex
+	recorded []*Interaction
+	used     []bool
+}
+
+// New creates a recorder of the cassette at path, saved when tb ends in
+// ModeRecord. It fails tb when the cassette cannot be read.
+func New(tb testing.TB, path string, opts ...Option) *Recorder {
+	tb.Helper()
+	r := &Recorder{
+		path:    path,
+		next:    http.DefaultTransport,
+		headers: slices.Clone(redactedHeaders),
+		query:   []string{"key", "api_key", "access_token"},
+		match:   DefaultMatcher,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case r.mode == ModeRecord:
+	case errors.Is(err, os.ErrNotExist) && r.mode == ModeAuto:
+		r.mode = ModeRecord
+	case err != nil:
+		tb.Fatalf("vcr: %v", err)
+	default:
+		var c cassette
+		if err := json.Unmarshal(data, &c); err != nil {
+			tb.Fatalf("vcr: reading %s: %v", path, err)
+		}
+		r.mode, r.recorded, r.used = ModeReplay, c.Interactions, make([]bool, len(c.Interactions))
+	}
+
+	if r.mode == ModeRecord {
+		tb.Cleanup(func() {
+			if err := r.save(); err != nil {
+				tb.Errorf("vcr: %v", err)
+			}
+		})
+	}
+	return r
+}
+
+// Mode returns ModeRecord or ModeReplay
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an HTTP client sending requests through r
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	recorded := Request{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone(), Body: string(body)}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := r.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &Interaction{
+		Request:  recorded,
+		Response: Response{StatusCode: resp.StatusCode, Header: resp.Header.Clone(), Body: string(respBody)},
+	}
+	r.sanitize(i)
+	r.mu.Lock()
+	r.recorded = append(r.recorded, i)
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+// replay answers req with the first unused interaction matching it
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	i := &Interaction{Request: recorded}
+	r.sanitize(i)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for n, rec := range r.recorded {
+		if r.used[n] || !r.match(&i.Request, &rec.Request) {
+			continue
+		}
+		r.used[n] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", rec.Response.StatusCode, http.StatusText(rec.Response.StatusCode)),
+			StatusCode:    rec.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        rec.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(rec.Response.Body)),
+			ContentLength: int64(len(rec.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("vcr: no interaction recorded in %s for %s %s", r.path, i.Request.Method, i.Request.URL)
+}
+
+// sanitize strips credentials from i, then runs the sanitizers
+func (r *Recorder) sanitize(i *Interaction) {
+	for _, name := range r.headers {
+		i.Request.Header.Del(name)
+		i.Response.Header.Del(name)
+	}
+	if u, err := url.Parse(i.Request.URL); err == nil {
+		q := u.Query()
+		redacted := false
+		for param := range q {
+			if slices.Contains(r.query, param) {
+				q.Set(param, Redacted)
+				redacted = true
+			}
+		}
+		if redacted {
+			u.RawQuery = q.Encode()
+			i.Request.URL = u.String()
+		}
+	}
+	for _, f := range r.sanitizers {
+		f(i)
+	}
+}
+
+func (r *Recorder) save() error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(cassette{Interactions: r.recorded}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
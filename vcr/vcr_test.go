@@ -0,0 +1,134 @@
+package vcr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer sk-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=secret")
+		io.WriteString(w, "reply to "+string(body))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	send := func(c *http.Client, body string) (string, error) {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat?key=sk-secret", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer sk-secret")
+		resp, err := c.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b), nil
+	}
+
+	t.Run("record", func(t *testing.T) {
+		rec := New(t, path)
+		if rec.Mode() != ModeRecord {
+			t.Fatalf("Mode() = %v without a cassette, want ModeRecord", rec.Mode())
+		}
+		for _, body := range []string{"a", "b", "a"} {
+			if got, err := send(rec.Client(), body); err != nil || got != "reply to "+body {
+				t.Errorf("recording %q = %q, %v", body, got, err)
+			}
+		}
+	})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-secret") || strings.Contains(string(data), "session=secret") {
+		t.Errorf("cassette holds credentials:\n%s", data)
+	}
+
+	srv.Close()
+	rec := New(t, path)
+	if rec.Mode() != ModeReplay {
+		t.Fatalf("Mode() = %v with a cassette, want ModeReplay", rec.Mode())
+	}
+	for _, body := range []string{"b", "a", "a"} {
+		if got, err := send(rec.Client(), body); err != nil || got != "reply to "+body {
+			t.Errorf("replaying %q = %q, %v", body, got, err)
+		}
+	}
+	// Every interaction is replayed once
+	if _, err := send(rec.Client(), "a"); err == nil {
+		t.Error("replaying a request more often than recorded error = nil")
+	}
+	if calls != 3 {
+		t.Errorf("server got %d requests, want the 3 recorded", calls)
+	}
+}
+
+func TestRecorder_ProviderKeys(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	// The auth headers of the providers, as they send them
+	headers := []string{
+		"Authorization", "api-key", "x-api-key", "x-goog-api-key", "xi-api-key", "Ocp-Apim-Subscription-Key", "X-Amz-Security-Token",
+	}
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	t.Run("record", func(t *testing.T) {
+		rec := New(t, path)
+		for _, h := range headers {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			req.Header.Set(h, "secret-"+h)
+			res, err := rec.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+		}
+	})
+	data, _ := os.ReadFile(path)
+	for _, h := range headers {
+		if strings.Contains(string(data), "secret-"+h) {
+			t.Errorf("cassette holds the %s header:\n%s", h, data)
+		}
+	}
+}
+
+func TestRecorder_Sanitizer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("OpenAI-Organization", "org-123")
+		io.WriteString(w, `{"id":"chatcmpl-123"}`)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	scrub := WithSanitizer(func(i *Interaction) {
+		i.Response.Body = strings.ReplaceAll(i.Response.Body, "chatcmpl-123", "chatcmpl-0")
+	})
+	t.Run("record", func(t *testing.T) {
+		rec := New(t, path, scrub, WithRedactedHeaders("openai-organization"))
+		if _, err := rec.Client().Get(srv.URL); err != nil {
+			t.Fatal(err)
+		}
+	})
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "org-123") || !strings.Contains(string(data), "chatcmpl-0") {
+		t.Errorf("cassette not sanitized:\n%s", data)
+	}
+
+	if _, err := New(t, path, WithMode(ModeReplay)).Client().Get(srv.URL + "/other"); err == nil {
+		t.Error("replaying a request not recorded error = nil")
+	}
+}
@@ -0,0 +1,176 @@
+// Package vecmath provides similarity and selection functions over float32
+// embedding vectors.
+//
+// Loops are unrolled over four independent accumulators, a shape the Go
+// compiler keeps free of bounds checks and that CPUs pipeline well. Functions
+// taking two vectors panic if their lengths differ.
+package vecmath
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// DotProduct returns the inner product of a and b
+func DotProduct(a, b []float32) float32 {
+	checkLen(a, b)
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// Norm returns the Euclidean length of v
+func Norm(v []float32) float32 {
+	return float32(math.Sqrt(float64(DotProduct(v, v))))
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0
+// if either is the zero vector
+func CosineSimilarity(a, b []float32) float32 {
+	checkLen(a, b)
+	b = b[:len(a)]
+	var d0, d1, a0, a1, b0, b1 float32
+	i := 0
+	for ; i+2 <= len(a); i += 2 {
+		d0 += a[i] * b[i]
+		d1 += a[i+1] * b[i+1]
+		a0 += a[i] * a[i]
+		a1 += a[i+1] * a[i+1]
+		b0 += b[i] * b[i]
+		b1 += b[i+1] * b[i+1]
+	}
+	for ; i < len(a); i++ {
+		d0 += a[i] * b[i]
+		a0 += a[i] * a[i]
+		b0 += b[i] * b[i]
+	}
+	dot, na, nb := d0+d1, a0+a1, b0+b1
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / float32(math.Sqrt(float64(na)*float64(nb)))
+}
+
+// EuclideanDistance returns the straight-line distance between a and b
+func EuclideanDistance(a, b []float32) float32 {
+	checkLen(a, b)
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		d0, d1, d2, d3 := a[i]-b[i], a[i+1]-b[i+1], a[i+2]-b[i+2], a[i+3]-b[i+3]
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+	for ; i < len(a); i++ {
+		d := a[i] - b[i]
+		s0 += d * d
+	}
+	return float32(math.Sqrt(float64(s0 + s1 + s2 + s3)))
+}
+
+// Normalize scales v in place to unit length and returns it. The zero vector
+// is returned unchanged. Dot products of normalized vectors are cosine
+// similarities.
+func Normalize(v []float32) []float32 {
+	n := Norm(v)
+	if n == 0 {
+		return v
+	}
+	inv := 1 / n
+	for i := range v {
+		v[i] *= inv
+	}
+	return v
+}
+
+// Normalized returns a unit length copy of v
+func Normalized(v []float32) []float32 {
+	return Normalize(append([]float32(nil), v...))
+}
+
+// MaxSim returns the late-interaction score of ColBERT-style multi-vector
+// embeddings: the sum over query vectors of their best dot product with a
+// document vector
+func MaxSim(query, doc [][]float32) float32 {
+	var sum float32
+	for _, q := range query {
+		best := float32(math.Inf(-1))
+		for _, d := range doc {
+			if s := DotProduct(q, d); s > best {
+				best = s
+			}
+		}
+		if len(doc) > 0 {
+			sum += best
+		}
+	}
+	return sum
+}
+
+// Scored represents a vector selected by TopK
+type Scored struct {
+	Index int // Position in the searched slice
+	Score float32
+}
+
+// TopK returns the k vectors scoring highest against query, best first. Use
+// CosineSimilarity or DotProduct as score, or a negated distance. Ties keep
+// the lower index first.
+func TopK(query []float32, vectors [][]float32, k int, score func(a, b []float32) float32) []Scored {
+	if k <= 0 {
+		return nil
+	}
+	h := make(minHeap, 0, min(k, len(vectors)))
+	for i, v := range vectors {
+		s := Scored{Index: i, Score: score(query, v)}
+		if len(h) < k {
+			heap.Push(&h, s)
+		} else if s.Score > h[0].Score {
+			h[0] = s
+			heap.Fix(&h, 0)
+		}
+	}
+	sort.Slice(h, func(i, j int) bool { return better(h[i], h[j]) })
+	return h
+}
+
+func better(a, b Scored) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Index < b.Index
+}
+
+// minHeap keeps the worst selected vector at the root
+type minHeap []Scored
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return better(h[j], h[i]) }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(Scored)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func checkLen(a, b []float32) {
+	if len(a) != len(b) {
+		panic("vecmath: vectors have different lengths")
+	}
+}
@@ -0,0 +1,71 @@
+package vecmath
+
+import (
+	"math"
+	"testing"
+)
+
+func approx(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+func TestSimilarity(t *testing.T) {
+	a := []float32{1, 2, 3, 4, 5}
+	b := []float32{5, 4, 3, 2, 1}
+	if got := DotProduct(a, b); got != 35 {
+		t.Errorf("DotProduct() = %v, want 35", got)
+	}
+	if got := CosineSimilarity(a, b); !approx(got, 35.0/55) {
+		t.Errorf("CosineSimilarity() = %v, want %v", got, 35.0/55)
+	}
+	if got := CosineSimilarity(a, make([]float32, 5)); got != 0 {
+		t.Errorf("CosineSimilarity() with zero vector = %v, want 0", got)
+	}
+	if got := EuclideanDistance(a, b); !approx(got, float32(math.Sqrt(40))) {
+		t.Errorf("EuclideanDistance() = %v, want sqrt(40)", got)
+	}
+
+	v := Normalized([]float32{3, 4})
+	if !approx(v[0], 0.6) || !approx(v[1], 0.8) || !approx(Norm(v), 1) {
+		t.Errorf("Normalized() = %v", v)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("DotProduct() of different lengths did not panic")
+		}
+	}()
+	DotProduct(a, b[:2])
+}
+
+func TestMaxSim(t *testing.T) {
+	query := [][]float32{{1, 0}, {0, 1}}
+	doc := [][]float32{{2, 0}, {1, 1}, {0, 3}}
+	if got := MaxSim(query, doc); got != 5 {
+		t.Errorf("MaxSim() = %v, want 5", got)
+	}
+	if got := MaxSim(query, nil); got != 0 {
+		t.Errorf("MaxSim() of an empty document = %v, want 0", got)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	vectors := [][]float32{{0, 1}, {1, 0}, {0.7, 0.7}, {1, 0}, {-1, 0}}
+	got := TopK([]float32{1, 0}, vectors, 3, CosineSimilarity)
+	if len(got) != 3 || got[0].Index != 1 || got[1].Index != 3 || got[2].Index != 2 {
+		t.Errorf("TopK() = %+v, want indexes 1, 3, 2", got)
+	}
+	if got := TopK([]float32{1, 0}, vectors, 10, DotProduct); len(got) != 5 || got[4].Index != 4 {
+		t.Errorf("TopK(k > n) = %+v", got)
+	}
+}
+
+func BenchmarkCosineSimilarity(b *testing.B) {
+	x, y := make([]float32, 1536), make([]float32, 1536)
+	for i := range x {
+		x[i], y[i] = float32(i%7), float32(i%5)
+	}
+	for i := 0; i < b.N; i++ {
+		CosineSimilarity(x, y)
+	}
+}
@@ -0,0 +1,14 @@
+package vectorstore_test
+
+import (
+	"testing"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+	"github.com/parikxxit/go-llm/vectorstore/vectorstoretest"
+)
+
+func TestMemoryStore_Conformance(t *testing.T) {
+	vectorstoretest.Suite{New: func(*testing.T) vectorstore.Store {
+		return vectorstore.NewMemoryStore(vectorstore.Cosine)
+	}}.Run(t)
+}
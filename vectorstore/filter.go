@@ -0,0 +1,190 @@
+package vectorstore
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Op represents a filter operator
+type Op string
+
+const (
+	OpEq  Op = "eq"
+	OpNe  Op = "ne"
+	OpGt  Op = "gt"
+	OpGte Op = "gte"
+	OpLt  Op = "lt"
+	OpLte Op = "lte"
+	OpIn  Op = "in"
+	OpAnd Op = "and"
+	OpOr  Op = "or"
+)
+
+// Filter represents a condition on document metadata. Leaf filters compare
+// the metadata value at Key with Value; OpAnd and OpOr combine Filters.
+// Backends translate filters to their native query language.
+type Filter struct {
+	Op      Op
+	Key     string
+	Value   any      // A []any for OpIn
+	Filters []Filter // Operands of OpAnd and OpOr
+}
+
+// Eq matches documents whose metadata key equals value
+func Eq(key string, value any) *Filter { return &Filter{Op: OpEq, Key: key, Value: value} }
+
+// Ne matches documents whose metadata key is missing or differs from value
+func Ne(key string, value any) *Filter { return &Filter{Op: OpNe, Key: key, Value: value} }
+
+// Gt matches documents whose numeric metadata key is greater than value
+func Gt(key string, value any) *Filter { return &Filter{Op: OpGt, Key: key, Value: value} }
+
+// Gte matches documents whose numeric metadata key is at least value
+func Gte(key string, value any) *Filter { return &Filter{Op: OpGte, Key: key, Value: value} }
+
+// Lt matches documents whose numeric metadata key is less than value
+func Lt(key string, value any) *Filter { return &Filter{Op: OpLt, Key: key, Value: value} }
+
+// Lte matches documents whose numeric metadata key is at most value
+func Lte(key string, value any) *Filter { return &Filter{Op: OpLte, Key: key, Value: value} }
+
+// In matches documents whose metadata key equals one of values
+func In(key string, values ...any) *Filter { return &Filter{Op: OpIn, Key: key, Value: values} }
+
+// And matches documents matching every filter
+func And(filters ...*Filter) *Filter { return &Filter{Op: OpAnd, Filters: deref(filters)} }
+
+// Or matches documents matching at least one filter
+func Or(filters ...*Filter) *Filter { return &Filter{Op: OpOr, Filters: deref(filters)} }
+
+func deref(filters []*Filter) []Filter {
+	out := make([]Filter, 0, len(filters))
+	for _, f := range filters {
+		if f != nil {
+			out = append(out, *f)
+		}
+	}
+	return out
+}
+
+// Match evaluates the filter against metadata. A nil filter matches everything.
+func (f *Filter) Match(metadata map[string]any) bool {
+	if f == nil {
+		return true
+	}
+
+	switch f.Op {
+	case OpAnd:
+		for i := range f.Filters {
+			if !f.Filters[i].Match(metadata) {
+				return false
+			}
+		}
+		return true
+	case OpOr:
+		for i := range f.Filters {
+			if f.Filters[i].Match(metadata) {
+				return true
+			}
+		}
+		return false
+	}
+
+	v, ok := metadata[f.Key]
+	switch f.Op {
+	case OpEq:
+		return ok && equal(v, f.Value)
+	case OpNe:
+		return !ok || !equal(v, f.Value)
+	case OpIn:
+		values, _ := f.Value.([]any)
+		for _, want := range values {
+			if ok && equal(v, want) {
+				return true
+			}
+		}
+		return false
+	case OpGt, OpGte, OpLt, OpLte:
+		a, aok := toFloat(v)
+		b, bok := toFloat(f.Value)
+		if !ok || !aok || !bok {
+			return false
+		}
+		switch f.Op {
+		case OpGt:
+			return a > b
+		case OpGte:
+			return a >= b
+		case OpLt:
+			return a < b
+		default:
+			return a <= b
+		}
+	}
+	return false
+}
+
+// Validate reports malformed filters
+func (f *Filter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	switch f.Op {
+	case OpAnd, OpOr:
+		for i := range f.Filters {
+			if err := f.Filters[i].Validate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn:
+		if f.Key == "" {
+			return fmt.Errorf("filter %s requires a key", f.Op)
+		}
+		if _, ok := f.Value.([]any); f.Op == OpIn && !ok {
+			return fmt.Errorf("filter in requires a list of values")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown filter operator %q", f.Op)
+	}
+}
+
+func equal(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x == y
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
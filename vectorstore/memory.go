@@ -0,0 +1,104 @@
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/parikxxit/go-llm/vecmath"
+)
+
+// MemoryStore is a Store that searches documents by brute force. It suits
+// tests and small corpora and is lost when the process exits.
+type MemoryStore struct {
+	metric Metric
+
+	mu   sync.RWMutex
+	docs map[string]Document
+}
+
+// NewMemoryStore creates a new empty in-memory store ranking by metric,
+// Cosine when empty
+func NewMemoryStore(metric Metric) *MemoryStore {
+	if metric == "" {
+		metric = Cosine
+	}
+	return &MemoryStore{metric: metric, docs: make(map[string]Document)}
+}
+
+func (s *MemoryStore) Upsert(_ context.Context, docs ...Document) error {
+	for _, d := range docs {
+		if d.ID == "" {
+			return fmt.Errorf("document ID cannot be empty")
+		}
+		if len(d.Embedding) == 0 {
+			return fmt.Errorf("document %s has no embedding", d.ID)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range docs {
+		d.Embedding = append([]float32(nil), d.Embedding...)
+		s.docs[d.ID] = d
+	}
+	return nil
+}
+
+func (s *MemoryStore) Query(_ context.Context, q Query) ([]Match, error) {
+	if len(q.Vector) == 0 {
+		return nil, fmt.Errorf("query vector cannot be empty")
+	}
+	if err := q.Filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	matches := make([]Match, 0, len(s.docs))
+	for _, d := range s.docs {
+		if len(d.Embedding) != len(q.Vector) || !q.Filter.Match(d.Metadata) {
+			continue
+		}
+		matches = append(matches, Match{Document: d, Score: score(s.metric, q.Vector, d.Embedding)})
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Document.ID < matches[j].Document.ID
+	})
+	if q.TopK > 0 && len(matches) > q.TopK {
+		matches = matches[:q.TopK]
+	}
+	return matches, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.docs, id)
+	}
+	return nil
+}
+
+// Len returns the number of stored documents
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.docs)
+}
+
+func score(metric Metric, a, b []float32) float64 {
+	switch metric {
+	case DotProduct:
+		return float64(vecmath.DotProduct(a, b))
+	case Euclidean:
+		return -float64(vecmath.EuclideanDistance(a, b))
+	default:
+		return float64(vecmath.CosineSimilarity(a, b))
+	}
+}
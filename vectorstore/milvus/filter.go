@@ -0,0 +1,57 @@
+package milvus
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var operators = map[vectorstore.Op]string{
+	vectorstore.OpEq:  "==",
+	vectorstore.OpNe:  "!=",
+	vectorstore.OpGt:  ">",
+	vectorstore.OpGte: ">=",
+	vectorstore.OpLt:  "<",
+	vectorstore.OpLte: "<=",
+	vectorstore.OpIn:  "in",
+}
+
+// translate converts a filter to a Milvus boolean expression
+func translate(f *vectorstore.Filter) string {
+	switch f.Op {
+	case vectorstore.OpAnd, vectorstore.OpOr:
+		if len(f.Filters) == 0 {
+			if f.Op == vectorstore.OpAnd {
+				return "true"
+			}
+			return "false"
+		}
+		parts := make([]string, len(f.Filters))
+		for i := range f.Filters {
+			parts[i] = translate(&f.Filters[i])
+		}
+		return "(" + strings.Join(parts, " "+string(f.Op)+" ") + ")"
+	default:
+		return field(f.Key) + " " + operators[f.Op] + " " + literal(f.Value)
+	}
+}
+
+// field references a column, or a dynamic field by key when it is not a
+// valid identifier
+func field(key string) string {
+	if identifier.MatchString(key) {
+		return key
+	}
+	return `$meta[` + literal(key) + `]`
+}
+
+// literal renders strings, numbers, booleans and lists, which share JSON's
+// syntax in Milvus expressions
+func literal(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,361 @@
+// Package milvus provides a vectorstore.Store backed by a Milvus collection,
+// using the Milvus gRPC API:
+//
+//	conn, err := grpc.NewClient("localhost:19530", grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	store := milvus.New(conn, "docs")
+package milvus
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/parikxxit/go-llm/vectorstore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// Fields of the collection. Metadata is stored in dynamic fields, so keys
+// must not clash with these names.
+const (
+	FieldID     = "id"
+	FieldVector = "vector"
+	FieldText   = "text"
+)
+
+// fieldMeta is the JSON field Milvus keeps dynamic fields in
+const fieldMeta = "$meta"
+
+// Store is a vectorstore.Store backed by a Milvus collection
+type Store struct {
+	client     milvuspb.MilvusServiceClient
+	collection string
+	database   string
+	token      string
+	metric     vectorstore.Metric
+}
+
+// Option is a function that configures a Store
+type Option func(*Store)
+
+// WithToken sets the token sent with every call, an API key or
+// "user:password"
+func WithToken(token string) Option {
+	return func(s *Store) {
+		s.token = token
+	}
+}
+
+// WithDatabase sets the database of the collection, "default" when empty
+func WithDatabase(name string) Option {
+	return func(s *Store) {
+		s.database = name
+	}
+}
+
+// WithMetric sets the metric of collections created by CreateCollection,
+// Cosine by default. It must match the metric of existing collections.
+func WithMetric(metric vectorstore.Metric) Option {
+	return func(s *Store) {
+		s.metric = metric
+	}
+}
+
+// New creates a new store for collection through conn, a connection to the
+// Milvus server which the caller closes
+func New(conn grpc.ClientConnInterface, collection string, opts ...Option) *Store {
+	s := &Store{
+		client:     milvuspb.NewMilvusServiceClient(conn),
+		collection: collection,
+		metric:     vectorstore.Cosine,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateCollection creates, indexes and loads the collection for embeddings
+// of the given dimensions, with a string primary key and dynamic fields
+// enabled
+func (s *Store) CreateCollection(ctx context.Context, dimensions int) error {
+	ctx = s.outgoing(ctx)
+	schema, err := proto.Marshal(&schemapb.CollectionSchema{
+		Name: s.collection,
+		Fields: []*schemapb.FieldSchema{
+			{Name: FieldID, IsPrimaryKey: true, DataType: schemapb.DataType_VarChar, TypeParams: params("max_length", "512")},
+			{Name: FieldVector, DataType: schemapb.DataType_FloatVector, TypeParams: params("dim", strconv.Itoa(dimensions))},
+			{Name: FieldText, DataType: schemapb.DataType_VarChar, TypeParams: params("max_length", "65535")},
+		},
+		EnableDynamicField: true,
+	})
+	if err != nil {
+		return err
+	}
+	status, err := s.client.CreateCollection(ctx, &milvuspb.CreateCollectionRequest{
+		DbName:         s.database,
+		CollectionName: s.collection,
+		Schema:         schema,
+	})
+	if err := check("create collection", status, err); err != nil {
+		return err
+	}
+	status, err = s.client.CreateIndex(ctx, &milvuspb.CreateIndexRequest{
+		DbName:         s.database,
+		CollectionName: s.collection,
+		FieldName:      FieldVector,
+		ExtraParams:    params("index_type", "AUTOINDEX", "metric_type", metricType(s.metric)),
+	})
+	if err := check("create index", status, err); err != nil {
+		return err
+	}
+	status, err = s.client.LoadCollection(ctx, &milvuspb.LoadCollectionRequest{
+		DbName:         s.database,
+		CollectionName: s.collection,
+	})
+	if err := check("load collection", status, err); err != nil {
+		return err
+	}
+	return s.waitLoaded(ctx)
+}
+
+// waitLoaded polls the load state of the collection until it can be searched
+func (s *Store) waitLoaded(ctx context.Context) error {
+	for {
+		res, err := s.client.GetLoadState(ctx, &milvuspb.GetLoadStateRequest{DbName: s.database, CollectionName: s.collection})
+		if err := check("get load state", res.GetStatus(), err); err != nil {
+			return err
+		}
+		if res.GetState() == commonpb.LoadState_LoadStateLoaded {
+			return nil
+		}
+		timer := time.NewTimer(100 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// DropCollection deletes the collection and all its entities
+func (s *Store) DropCollection(ctx context.Context) error {
+	status, err := s.client.DropCollection(s.outgoing(ctx), &milvuspb.DropCollectionRequest{
+		DbName:         s.database,
+		CollectionName: s.collection,
+	})
+	return check("drop collection", status, err)
+}
+
+func (s *Store) Upsert(ctx context.Context, docs ...vectorstore.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	var (
+		ids     = make([]string, len(docs))
+		texts   = make([]string, len(docs))
+		metas   = make([][]byte, len(docs))
+		vectors []float32
+		dim     = len(docs[0].Embedding)
+	)
+	for i, d := range docs {
+		if d.ID == "" {
+			return fmt.Errorf("document ID cannot be empty")
+		}
+		if len(d.Embedding) != dim {
+			return fmt.Errorf("milvus: document %s has %d dimensions, want %d", d.ID, len(d.Embedding), dim)
+		}
+		meta := d.Metadata
+		if meta == nil {
+			meta = map[string]any{}
+		}
+		b, err := json.Marshal(meta)
+		if err != nil {
+			return fmt.Errorf("milvus: metadata of document %s: %w", d.ID, err)
+		}
+		ids[i], texts[i], metas[i] = d.ID, d.Text, b
+		vectors = append(vectors, d.Embedding...)
+	}
+
+	res, err := s.client.Upsert(s.outgoing(ctx), &milvuspb.UpsertRequest{
+		DbName:         s.database,
+		CollectionName: s.collection,
+		NumRows:        uint32(len(docs)),
+		FieldsData: []*schemapb.FieldData{
+			varchars(FieldID, ids),
+			varchars(FieldText, texts),
+			{
+				Type:      schemapb.DataType_FloatVector,
+				FieldName: FieldVector,
+				Field: &schemapb.FieldData_Vectors{Vectors: &schemapb.VectorField{
+					Dim:  int64(dim),
+					Data: &schemapb.VectorField_FloatVector{FloatVector: &schemapb.FloatArray{Data: vectors}},
+				}},
+			},
+			{
+				Type:      schemapb.DataType_JSON,
+				FieldName: fieldMeta,
+				IsDynamic: true,
+				Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+					Data: &schemapb.ScalarField_JsonData{JsonData: &schemapb.JSONArray{Data: metas}},
+				}},
+			},
+		},
+	})
+	return check("upsert", res.GetStatus(), err)
+}
+
+func (s *Store) Query(ctx context.Context, q vectorstore.Query) ([]vectorstore.Match, error) {
+	if err := q.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	limit := q.TopK
+	if limit <= 0 {
+		limit = 10
+	}
+	placeholders, err := proto.Marshal(&commonpb.PlaceholderGroup{Placeholders: []*commonpb.PlaceholderValue{{
+		Tag:    "$0",
+		Type:   commonpb.PlaceholderType_FloatVector,
+		Values: [][]byte{floats(q.Vector)},
+	}}})
+	if err != nil {
+		return nil, err
+	}
+	req := &milvuspb.SearchRequest{
+		DbName:           s.database,
+		CollectionName:   s.collection,
+		PlaceholderGroup: placeholders,
+		DslType:          commonpb.DslType_BoolExprV1,
+		OutputFields:     []string{FieldText, FieldVector, fieldMeta},
+		SearchParams: params(
+			"anns_field", FieldVector,
+			"topk", strconv.Itoa(limit),
+			"metric_type", metricType(s.metric),
+			"params", "{}",
+		),
+		Nq: 1,
+	}
+	if q.Filter != nil {
+		req.Dsl = translate(q.Filter)
+	}
+
+	res, err := s.client.Search(s.outgoing(ctx), req)
+	if err := check("search", res.GetStatus(), err); err != nil {
+		return nil, err
+	}
+
+	results := res.GetResults()
+	ids := results.GetIds().GetStrId().GetData()
+	matches := make([]vectorstore.Match, len(ids))
+	for i, id := range ids {
+		score := float64(results.GetScores()[i])
+		if s.metric == vectorstore.Euclidean {
+			score = -score // Milvus returns the distance for L2
+		}
+		matches[i] = vectorstore.Match{Document: vectorstore.Document{ID: id, Metadata: map[string]any{}}, Score: score}
+	}
+	// Output fields are columns holding one row per match
+	for _, field := range results.GetFieldsData() {
+		for i := range matches {
+			d := &matches[i].Document
+			switch {
+			case field.GetFieldName() == FieldText:
+				d.Text = field.GetScalars().GetStringData().GetData()[i]
+			case field.GetFieldName() == FieldVector:
+				v := field.GetVectors()
+				dim := int(v.GetDim())
+				d.Embedding = v.GetFloatVector().GetData()[i*dim : (i+1)*dim]
+			case field.GetIsDynamic():
+				if err := json.Unmarshal(field.GetScalars().GetJsonData().GetData()[i], &d.Metadata); err != nil {
+					return nil, fmt.Errorf("milvus: decoding metadata of %s: %w", d.ID, err)
+				}
+			}
+		}
+	}
+	return matches, nil
+}
+
+func (s *Store) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	values := make([]any, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	res, err := s.client.Delete(s.outgoing(ctx), &milvuspb.DeleteRequest{
+		DbName:         s.database,
+		CollectionName: s.collection,
+		Expr:           translate(vectorstore.In(FieldID, values...)),
+	})
+	return check("delete", res.GetStatus(), err)
+}
+
+// outgoing adds the token to the metadata of calls made with ctx
+func (s *Store) outgoing(ctx context.Context) context.Context {
+	if s.token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", base64.StdEncoding.EncodeToString([]byte(s.token)))
+}
+
+// check returns the error of a call, or the one Milvus reports in its status
+func check(op string, status *commonpb.Status, err error) error {
+	if err != nil {
+		return fmt.Errorf("milvus: %s: %w", op, err)
+	}
+	if status.GetErrorCode() != commonpb.ErrorCode_Success || status.GetCode() != 0 {
+		return fmt.Errorf("milvus: %s failed with code %d: %s", op, status.GetCode(), status.GetReason())
+	}
+	return nil
+}
+
+// params builds key/value pairs from alternating keys and values
+func params(kv ...string) []*commonpb.KeyValuePair {
+	pairs := make([]*commonpb.KeyValuePair, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		pairs = append(pairs, &commonpb.KeyValuePair{Key: kv[i], Value: kv[i+1]})
+	}
+	return pairs
+}
+
+func varchars(name string, data []string) *schemapb.FieldData {
+	return &schemapb.FieldData{
+		Type:      schemapb.DataType_VarChar,
+		FieldName: name,
+		Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+			Data: &schemapb.ScalarField_StringData{StringData: &schemapb.StringArray{Data: data}},
+		}},
+	}
+}
+
+// floats encodes a vector as Milvus expects it in placeholders: little-endian
+// float32s
+func floats(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(x))
+	}
+	return b
+}
+
+func metricType(m vectorstore.Metric) string {
+	switch m {
+	case vectorstore.DotProduct:
+		return "IP"
+	case vectorstore.Euclidean:
+		return "L2"
+	default:
+		return "COSINE"
+	}
+}
@@ -0,0 +1,182 @@
+package milvus
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/parikxxit/go-llm/vectorstore"
+	"github.com/parikxxit/go-llm/vectorstore/vectorstoretest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeMilvus records the requests of the Milvus service, answering searches
+// with result and deletes with a collection not found error
+type fakeMilvus struct {
+	milvuspb.UnimplementedMilvusServiceServer
+	create *milvuspb.CreateCollectionRequest
+	upsert *milvuspb.UpsertRequest
+	search *milvuspb.SearchRequest
+	token  []string
+	result *schemapb.SearchResultData
+}
+
+func (f *fakeMilvus) CreateCollection(_ context.Context, req *milvuspb.CreateCollectionRequest) (*commonpb.Status, error) {
+	f.create = req
+	return &commonpb.Status{}, nil
+}
+
+func (f *fakeMilvus) CreateIndex(context.Context, *milvuspb.CreateIndexRequest) (*commonpb.Status, error) {
+	return &commonpb.Status{}, nil
+}
+
+func (f *fakeMilvus) LoadCollection(context.Context, *milvuspb.LoadCollectionRequest) (*commonpb.Status, error) {
+	return &commonpb.Status{}, nil
+}
+
+func (f *fakeMilvus) GetLoadState(context.Context, *milvuspb.GetLoadStateRequest) (*milvuspb.GetLoadStateResponse, error) {
+	return &milvuspb.GetLoadStateResponse{Status: &commonpb.Status{}, State: commonpb.LoadState_LoadStateLoaded}, nil
+}
+
+func (f *fakeMilvus) Upsert(ctx context.Context, req *milvuspb.UpsertRequest) (*milvuspb.MutationResult, error) {
+	f.upsert = req
+	md, _ := metadata.FromIncomingContext(ctx)
+	f.token = md.Get("authorization")
+	return &milvuspb.MutationResult{Status: &commonpb.Status{}}, nil
+}
+
+func (f *fakeMilvus) Search(_ context.Context, req *milvuspb.SearchRequest) (*milvuspb.SearchResults, error) {
+	f.search = req
+	return &milvuspb.SearchResults{Status: &commonpb.Status{}, Results: f.result}, nil
+}
+
+func (f *fakeMilvus) Delete(context.Context, *milvuspb.DeleteRequest) (*milvuspb.MutationResult, error) {
+	return &milvuspb.MutationResult{Status: &commonpb.Status{ErrorCode: commonpb.ErrorCode_UnexpectedError, Code: 100, Reason: "collection not found"}}, nil
+}
+
+// serve starts a gRPC server of f, returning a connection to it
+func serve(t *testing.T, f *fakeMilvus) *grpc.ClientConn {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	milvuspb.RegisterMilvusServiceServer(srv, f)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestStore(t *testing.T) {
+	f := &fakeMilvus{result: &schemapb.SearchResultData{
+		Ids:    &schemapb.IDs{IdField: &schemapb.IDs_StrId{StrId: &schemapb.StringArray{Data: []string{"a"}}}},
+		Scores: []float32{0.5},
+		FieldsData: []*schemapb.FieldData{
+			varchars(FieldText, []string{"cats"}),
+			{FieldName: FieldVector, Field: &schemapb.FieldData_Vectors{Vectors: &schemapb.VectorField{
+				Dim: 2, Data: &schemapb.VectorField_FloatVector{FloatVector: &schemapb.FloatArray{Data: []float32{1, 0}}},
+			}}},
+			{FieldName: fieldMeta, IsDynamic: true, Field: &schemapb.FieldData_Scalars{Scalars: &schemapb.ScalarField{
+				Data: &schemapb.ScalarField_JsonData{JsonData: &schemapb.JSONArray{Data: [][]byte{[]byte(`{"lang":"en"}`)}}},
+			}}},
+		},
+	}}
+	ctx := context.Background()
+	s := New(serve(t, f), "docs", WithToken("root:Milvus"))
+
+	if err := s.CreateCollection(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	var schema schemapb.CollectionSchema
+	if err := proto.Unmarshal(f.create.GetSchema(), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if f.create.GetCollectionName() != "docs" || len(schema.GetFields()) != 3 || !schema.GetFields()[0].GetIsPrimaryKey() || !schema.GetEnableDynamicField() {
+		t.Errorf("CreateCollection() schema = %v", &schema)
+	}
+
+	if err := s.Upsert(ctx, vectorstore.Document{ID: "a", Text: "cats", Embedding: []float32{1, 0}, Metadata: map[string]any{"lang": "en"}}); err != nil {
+		t.Fatal(err)
+	}
+	fields := f.upsert.GetFieldsData()
+	if f.upsert.GetNumRows() != 1 || fields[0].GetScalars().GetStringData().GetData()[0] != "a" || fields[2].GetVectors().GetDim() != 2 ||
+		!fields[3].GetIsDynamic() || string(fields[3].GetScalars().GetJsonData().GetData()[0]) != `{"lang":"en"}` {
+		t.Errorf("Upsert() fields = %v", fields)
+	}
+	if want := base64.StdEncoding.EncodeToString([]byte("root:Milvus")); len(f.token) != 1 || f.token[0] != want {
+		t.Errorf("Upsert() authorization = %v, want %s", f.token, want)
+	}
+
+	matches, err := s.Query(ctx, vectorstore.Query{Vector: []float32{1, 0}, TopK: 3, Filter: vectorstore.Eq("lang", "en")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Document.ID != "a" || matches[0].Document.Text != "cats" || matches[0].Score != 0.5 ||
+		len(matches[0].Document.Embedding) != 2 || matches[0].Document.Metadata["lang"] != "en" {
+		t.Errorf("Query() = %+v", matches)
+	}
+	var group commonpb.PlaceholderGroup
+	if err := proto.Unmarshal(f.search.GetPlaceholderGroup(), &group); err != nil {
+		t.Fatal(err)
+	}
+	if f.search.GetDsl() != `lang == "en"` || !bytes.Equal(group.GetPlaceholders()[0].GetValues()[0], floats([]float32{1, 0})) {
+		t.Errorf("Query() request = %v", f.search)
+	}
+	for _, kv := range f.search.GetSearchParams() {
+		if kv.GetKey() == "topk" && kv.GetValue() != "3" {
+			t.Errorf("Query() topk = %s, want 3", kv.GetValue())
+		}
+	}
+
+	if err := s.Delete(ctx, "a"); err == nil || !strings.Contains(err.Error(), "collection not found") {
+		t.Errorf("Delete() error = %v, want the error reported in the status", err)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	f := vectorstore.And(vectorstore.Ne("lang", "fr"), vectorstore.Or(vectorstore.Gt("year", 2020), vectorstore.In("my-tag", "a", "b")))
+	want := `(lang != "fr" and (year > 2020 or $meta["my-tag"] in ["a","b"]))`
+	if got := translate(f); got != want {
+		t.Errorf("translate() = %s, want %s", got, want)
+	}
+}
+
+// TestConformance runs against the server in MILVUS_ADDR, the host and gRPC
+// port, e.g. localhost:19530
+func TestConformance(t *testing.T) {
+	addr := os.Getenv("MILVUS_ADDR")
+	if addr == "" {
+		t.Skip("MILVUS_ADDR not set")
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	vectorstoretest.Suite{New: func(t *testing.T) vectorstore.Store {
+		s := New(conn, "gollm_"+strings.ReplaceAll(uuid.NewString(), "-", ""), WithToken(os.Getenv("MILVUS_TOKEN")))
+		if err := s.CreateCollection(context.Background(), 2); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.DropCollection(context.Background()) })
+		return s
+	}}.Run(t)
+}
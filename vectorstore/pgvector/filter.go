@@ -0,0 +1,75 @@
+package pgvector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// translate converts a filter to a SQL condition on the metadata column,
+// appending its bind parameters to args. Equality uses JSONB containment so
+// it can be served by a GIN index on metadata.
+func translate(f *vectorstore.Filter, args []any) (string, []any) {
+	if f == nil {
+		return "TRUE", args
+	}
+
+	param := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	contains := func(value any) string {
+		doc, _ := json.Marshal(map[string]any{f.Key: value})
+		return fmt.Sprintf("metadata @> %s::jsonb", param(string(doc)))
+	}
+
+	switch f.Op {
+	case vectorstore.OpAnd, vectorstore.OpOr:
+		if len(f.Filters) == 0 {
+			if f.Op == vectorstore.OpAnd {
+				return "TRUE", args
+			}
+			return "FALSE", args
+		}
+		parts := make([]string, len(f.Filters))
+		for i := range f.Filters {
+			parts[i], args = translate(&f.Filters[i], args)
+		}
+		sep := " AND "
+		if f.Op == vectorstore.OpOr {
+			sep = " OR "
+		}
+		return "(" + strings.Join(parts, sep) + ")", args
+	case vectorstore.OpEq:
+		// Bound first: args is otherwise free to be read before contains appends
+		cond := contains(f.Value)
+		return cond, args
+	case vectorstore.OpNe:
+		cond := "NOT " + contains(f.Value)
+		return cond, args
+	case vectorstore.OpIn:
+		values, _ := f.Value.([]any)
+		if len(values) == 0 {
+			return "FALSE", args
+		}
+		parts := make([]string, len(values))
+		for i, v := range values {
+			parts[i] = contains(v)
+		}
+		return "(" + strings.Join(parts, " OR ") + ")", args
+	default:
+		ops := map[vectorstore.Op]string{
+			vectorstore.OpGt:  ">",
+			vectorstore.OpGte: ">=",
+			vectorstore.OpLt:  "<",
+			vectorstore.OpLte: "<=",
+		}
+		key := param(f.Key)
+		// CASE guarantees the cast only runs on numbers
+		cond := fmt.Sprintf("(CASE WHEN jsonb_typeof(metadata->%s) = 'number' THEN (metadata->>%s)::float8 %s %s ELSE FALSE END)",
+			key, key, ops[f.Op], param(f.Value))
+		return cond, args
+	}
+}
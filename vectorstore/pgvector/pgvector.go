@@ -0,0 +1,322 @@
+// Package pgvector provides a vectorstore.Store backed by PostgreSQL with the
+// pgvector extension.
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // Registers the "pgx" database/sql driver
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+const (
+	defaultTable     = "gollm_documents"
+	defaultBatchSize = 100
+)
+
+// IndexType represents the approximate nearest neighbour index created by
+// CreateTable
+type IndexType string
+
+const (
+	HNSW    IndexType = "hnsw"
+	IVFFlat IndexType = "ivfflat"
+	NoIndex IndexType = ""
+)
+
+// Store is a vectorstore.Store backed by a table with an id, text, vector
+// embedding and JSONB metadata column
+type Store struct {
+	db         *sql.DB
+	table      string
+	dimensions int
+	metric     vectorstore.Metric
+	index      IndexType
+	lists      int
+	batchSize  int
+}
+
+// Option is a function that configures a Store
+type Option func(*Store)
+
+// WithTable sets the table holding documents
+func WithTable(table string) Option {
+	return func(s *Store) {
+		s.table = table
+	}
+}
+
+// WithMetric sets the distance used for queries and the index, Cosine by default
+func WithMetric(metric vectorstore.Metric) Option {
+	return func(s *Store) {
+		s.metric = metric
+	}
+}
+
+// WithIndex sets the index created by CreateTable, HNSW by default
+func WithIndex(index IndexType) Option {
+	return func(s *Store) {
+		s.index = index
+	}
+}
+
+// WithLists sets the number of IVFFlat lists, 100 by default
+func WithLists(lists int) Option {
+	return func(s *Store) {
+		s.lists = lists
+	}
+}
+
+// WithBatchSize sets the number of documents written per INSERT statement
+func WithBatchSize(n int) Option {
+	return func(s *Store) {
+		s.batchSize = n
+	}
+}
+
+// New creates a new store using db for embeddings of the given dimensions.
+// Call CreateTable once to set up the schema if it is not managed by
+// migrations.
+func New(db *sql.DB, dimensions int, opts ...Option) *Store {
+	s := &Store{
+		db:         db,
+		table:      defaultTable,
+		dimensions: dimensions,
+		metric:     vectorstore.Cosine,
+		index:      HNSW,
+		lists:      100,
+		batchSize:  defaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Open connects to the database at dsn with the pgx driver and creates a store
+func Open(dsn string, dimensions int, opts ...Option) (*Store, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, err
+	}
+	return New(db, dimensions, opts...), nil
+}
+
+// DB returns the underlying database handle
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// CreateTable enables the vector extension and creates the documents table
+// and its index if they do not exist
+func (s *Store) CreateTable(ctx context.Context) error {
+	stmts := []string{
+		"CREATE EXTENSION IF NOT EXISTS vector",
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id TEXT PRIMARY KEY,
+	text TEXT NOT NULL,
+	embedding vector(%d) NOT NULL,
+	metadata JSONB NOT NULL DEFAULT '{}'
+)`, s.table, s.dimensions),
+	}
+	if s.index != NoIndex {
+		index := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_embedding_idx ON %s USING %s (embedding %s)",
+			s.table, s.table, s.index, s.opClass())
+		if s.index == IVFFlat {
+			index += fmt.Sprintf(" WITH (lists = %d)", s.lists)
+		}
+		stmts = append(stmts, index)
+	}
+
+	for _, stmt := range stmts {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("creating table %s: %w", s.table, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) Upsert(ctx context.Context, docs ...vectorstore.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	for _, d := range docs {
+		if d.ID == "" {
+			return fmt.Errorf("document ID cannot be empty")
+		}
+		if len(d.Embedding) != s.dimensions {
+			return fmt.Errorf("document %s has %d dimensions, want %d", d.ID, len(d.Embedding), s.dimensions)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	size := s.batchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	for start := 0; start < len(docs); start += size {
+		batch := docs[start:min(start+size, len(docs))]
+		query, args, err := s.upsertQuery(batch)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("upserting documents: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) upsertQuery(docs []vectorstore.Document) (string, []any, error) {
+	docs = dedupe(docs)
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s (id, text, embedding, metadata) VALUES ", s.table)
+	args := make([]any, 0, 4*len(docs))
+	for i, d := range docs {
+		metadata, err := json.Marshal(d.Metadata)
+		if err != nil {
+			return "", nil, fmt.Errorf("encoding metadata of document %s: %w", d.ID, err)
+		}
+		if d.Metadata == nil {
+			metadata = []byte("{}")
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&b, "($%d, $%d, $%d::vector, $%d::jsonb)", n+1, n+2, n+3, n+4)
+		args = append(args, d.ID, d.Text, encodeVector(d.Embedding), string(metadata))
+	}
+	b.WriteString(" ON CONFLICT (id) DO UPDATE SET text = EXCLUDED.text, embedding = EXCLUDED.embedding, metadata = EXCLUDED.metadata")
+	return b.String(), args, nil
+}
+
+func (s *Store) Query(ctx context.Context, q vectorstore.Query) ([]vectorstore.Match, error) {
+	if len(q.Vector) != s.dimensions {
+		return nil, fmt.Errorf("query vector has %d dimensions, want %d", len(q.Vector), s.dimensions)
+	}
+	if err := q.Filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	args := []any{encodeVector(q.Vector)}
+	where, args := translate(q.Filter, args)
+	query := fmt.Sprintf("SELECT id, text, embedding::text, metadata, embedding %s $1::vector AS distance FROM %s WHERE %s ORDER BY distance",
+		s.operator(), s.table, where)
+	if q.TopK > 0 {
+		query += " LIMIT " + strconv.Itoa(q.TopK)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying documents: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []vectorstore.Match
+	for rows.Next() {
+		var (
+			d                   vectorstore.Document
+			embedding, metadata string
+			distance            float64
+		)
+		if err := rows.Scan(&d.ID, &d.Text, &embedding, &metadata, &distance); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(embedding), &d.Embedding); err != nil {
+			return nil, fmt.Errorf("decoding embedding of document %s: %w", d.ID, err)
+		}
+		if err := json.Unmarshal([]byte(metadata), &d.Metadata); err != nil {
+			return nil, fmt.Errorf("decoding metadata of document %s: %w", d.ID, err)
+		}
+		matches = append(matches, vectorstore.Match{Document: d, Score: s.score(distance)})
+	}
+	return matches, rows.Err()
+}
+
+func (s *Store) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		args[i] = id
+	}
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id IN (%s)", s.table, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return fmt.Errorf("deleting documents: %w", err)
+	}
+	return nil
+}
+
+// operator returns the pgvector distance operator of the metric
+func (s *Store) operator() string {
+	switch s.metric {
+	case vectorstore.DotProduct:
+		return "<#>" // Negative inner product
+	case vectorstore.Euclidean:
+		return "<->"
+	default:
+		return "<=>"
+	}
+}
+
+func (s *Store) opClass() string {
+	switch s.metric {
+	case vectorstore.DotProduct:
+		return "vector_ip_ops"
+	case vectorstore.Euclidean:
+		return "vector_l2_ops"
+	default:
+		return "vector_cosine_ops"
+	}
+}
+
+// score converts a pgvector distance to a vectorstore score
+func (s *Store) score(distance float64) float64 {
+	if s.metric == vectorstore.Cosine || s.metric == "" {
+		return 1 - distance
+	}
+	return -distance
+}
+
+func encodeVector(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// dedupe keeps the last of the documents sharing an ID, at the position of
+// the first: ON CONFLICT cannot update a row twice in one statement
+func dedupe(docs []vectorstore.Document) []vectorstore.Document {
+	index := make(map[string]int, len(docs))
+	out := make([]vectorstore.Document, 0, len(docs))
+	for _, d := range docs {
+		if i, ok := index[d.ID]; ok {
+			out[i] = d
+			continue
+		}
+		index[d.ID] = len(out)
+		out = append(out, d)
+	}
+	return out
+}
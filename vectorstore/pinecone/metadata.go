@@ -0,0 +1,114 @@
+package pinecone
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// toMetadata converts document metadata to what Pinecone accepts: strings,
+// numbers, booleans and lists of strings. Nested maps are flattened to dotted
+// keys, which filters use and fromMetadata nests back, and nil values are
+// dropped.
+func toMetadata(metadata map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(metadata)+1)
+	if err := flatten(out, "", metadata); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func flatten(out map[string]any, prefix string, metadata map[string]any) error {
+	for k, v := range metadata {
+		key := prefix + k
+		switch v := v.(type) {
+		case nil:
+		case string, bool, float32, float64, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			out[key] = v
+		case []string:
+			out[key] = v
+		case []any:
+			list := make([]string, len(v))
+			for i, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("metadata %s: lists may only hold strings, got %T", key, item)
+				}
+				list[i] = s
+			}
+			out[key] = list
+		case map[string]any:
+			if err := flatten(out, key+".", v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("metadata %s: unsupported type %T", key, v)
+		}
+	}
+	return nil
+}
+
+// fromMetadata rebuilds document metadata from Pinecone metadata, nesting
+// dotted keys back into maps. Keys with dots in documents cannot be told from
+// flattened ones, so they are read back nested too, unless a key holding a
+// value takes their place.
+func fromMetadata(metadata map[string]any) map[string]any {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		if k != MetadataText {
+			keys = append(keys, k)
+		}
+	}
+	// Sorted, "a" is placed before "a.b" can make it a map
+	slices.Sort(keys)
+
+	out := make(map[string]any, len(keys))
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		m := out
+		for _, p := range parts[:len(parts)-1] {
+			next, ok := m[p].(map[string]any)
+			if !ok {
+				if _, taken := m[p]; taken {
+					m = nil
+					break
+				}
+				next = map[string]any{}
+				m[p] = next
+			}
+			m = next
+		}
+		if m == nil {
+			out[key] = metadata[key]
+			continue
+		}
+		m[parts[len(parts)-1]] = metadata[key]
+	}
+	return out
+}
+
+// never is a filter matching no vector, as every one holds MetadataText. It
+// stands for an empty Or or In, which Pinecone rejects.
+var never = map[string]any{MetadataText: map[string]any{"$exists": false}}
+
+// translate converts a filter to the Pinecone metadata filter language
+func translate(f *vectorstore.Filter) map[string]any {
+	switch f.Op {
+	case vectorstore.OpAnd, vectorstore.OpOr:
+		if f.Op == vectorstore.OpOr && len(f.Filters) == 0 {
+			return never
+		}
+		filters := make([]map[string]any, len(f.Filters))
+		for i := range f.Filters {
+			filters[i] = translate(&f.Filters[i])
+		}
+		return map[string]any{"$" + string(f.Op): filters}
+	case vectorstore.OpIn:
+		if values, _ := f.Value.([]any); len(values) == 0 {
+			return never
+		}
+	}
+	return map[string]any{f.Key: map[string]any{"$" + string(f.Op): f.Value}}
+}
@@ -0,0 +1,278 @@
+// Package pinecone provides a vectorstore.Store backed by a Pinecone index.
+package pinecone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/parikxxit/go-llm/transport"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+const (
+	defaultControllerURL = "https://api.pinecone.io"
+	apiVersion           = "2024-07"
+	defaultBatchSize     = 100
+)
+
+// MetadataText is the metadata key holding the document text
+const MetadataText = "_text"
+
+// Serverless represents where a serverless index is hosted
+type Serverless struct {
+	Cloud  string // e.g. aws
+	Region string // e.g. us-east-1
+}
+
+// Store is a vectorstore.Store backed by a namespace of a Pinecone index
+type Store struct {
+	apiKey        string
+	index         string
+	namespace     string
+	metric        vectorstore.Metric
+	controllerURL string
+	batchSize     int
+	httpClient    *http.Client
+
+	mu   sync.Mutex
+	host string
+}
+
+// Option is a function that configures a Store
+type Option func(*Store)
+
+// WithNamespace sets the namespace documents are stored in, the default
+// namespace when empty
+func WithNamespace(namespace string) Option {
+	return func(s *Store) {
+		s.namespace = namespace
+	}
+}
+
+// WithHost sets the data plane host of the index, skipping its lookup
+func WithHost(host string) Option {
+	return func(s *Store) {
+		s.host = host
+	}
+}
+
+// WithMetric sets the metric of indexes created by CreateIndex, Cosine by
+// default. It must match the metric of existing indexes.
+func WithMetric(metric vectorstore.Metric) Option {
+	return func(s *Store) {
+		s.metric = metric
+	}
+}
+
+// WithControllerURL sets the control plane URL, https://api.pinecone.io by default
+func WithControllerURL(url string) Option {
+	return func(s *Store) {
+		s.controllerURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithBatchSize sets the number of vectors sent per upsert request
+func WithBatchSize(n int) Option {
+	return func(s *Store) {
+		s.batchSize = n
+	}
+}
+
+// WithHTTPClient sets the HTTP client, transport.DefaultClient by default
+func WithHTTPClient(c *http.Client) Option {
+	return func(s *Store) {
+		s.httpClient = c
+	}
+}
+
+// New creates a new store for the named index
+func New(apiKey, index string, opts ...Option) *Store {
+	s := &Store{
+		apiKey:        apiKey,
+		index:         index,
+		metric:        vectorstore.Cosine,
+		controllerURL: defaultControllerURL,
+		batchSize:     defaultBatchSize,
+		httpClient:    transport.DefaultClient(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateIndex creates the serverless index for embeddings of the given
+// dimensions
+func (s *Store) CreateIndex(ctx context.Context, dimensions int, spec Serverless) error {
+	body := map[string]any{
+		"name":      s.index,
+		"dimension": dimensions,
+		"metric":    metric(s.metric),
+		"spec":      map[string]any{"serverless": map[string]string{"cloud": spec.Cloud, "region": spec.Region}},
+	}
+	return s.do(ctx, http.MethodPost, s.controllerURL+"/indexes", body, nil)
+}
+
+// DeleteIndex deletes the index and all its namespaces
+func (s *Store) DeleteIndex(ctx context.Context) error {
+	return s.do(ctx, http.MethodDelete, s.controllerURL+"/indexes/"+s.index, nil, nil)
+}
+
+type vector struct {
+	ID       string         `json:"id"`
+	Values   []float32      `json:"values,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Score    float64        `json:"score,omitempty"`
+}
+
+func (s *Store) Upsert(ctx context.Context, docs ...vectorstore.Document) error {
+	vectors := make([]vector, len(docs))
+	for i, d := range docs {
+		if d.ID == "" {
+			return fmt.Errorf("document ID cannot be empty")
+		}
+		metadata, err := toMetadata(d.Metadata)
+		if err != nil {
+			return fmt.Errorf("document %s: %w", d.ID, err)
+		}
+		metadata[MetadataText] = d.Text
+		vectors[i] = vector{ID: d.ID, Values: d.Embedding, Metadata: metadata}
+	}
+
+	size := s.batchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	for start := 0; start < len(vectors); start += size {
+		body := map[string]any{"vectors": vectors[start:min(start+size, len(vectors))], "namespace": s.namespace}
+		if err := s.data(ctx, "/vectors/upsert", body, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) Query(ctx context.Context, q vectorstore.Query) ([]vectorstore.Match, error) {
+	if err := q.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	topK := q.TopK
+	if topK <= 0 {
+		topK = 10
+	}
+	body := map[string]any{
+		"vector":          q.Vector,
+		"topK":            topK,
+		"namespace":       s.namespace,
+		"includeValues":   true,
+		"includeMetadata": true,
+	}
+	if q.Filter != nil {
+		body["filter"] = translate(q.Filter)
+	}
+
+	var res struct {
+		Matches []vector `json:"matches"`
+	}
+	if err := s.data(ctx, "/query", body, &res); err != nil {
+		return nil, err
+	}
+
+	matches := make([]vectorstore.Match, len(res.Matches))
+	for i, m := range res.Matches {
+		d := vectorstore.Document{ID: m.ID, Embedding: m.Values, Metadata: fromMetadata(m.Metadata)}
+		d.Text, _ = m.Metadata[MetadataText].(string)
+		score := m.Score
+		if s.metric == vectorstore.Euclidean {
+			score = -score // Pinecone returns the distance
+		}
+		matches[i] = vectorstore.Match{Document: d, Score: score}
+	}
+	return matches, nil
+}
+
+func (s *Store) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.data(ctx, "/vectors/delete", map[string]any{"ids": ids, "namespace": s.namespace}, nil)
+}
+
+// data sends a request to the data plane host of the index, looking it up
+// on first use
+func (s *Store) data(ctx context.Context, path string, body, result any) error {
+	s.mu.Lock()
+	host := s.host
+	s.mu.Unlock()
+
+	if host == "" {
+		var index struct {
+			Host string `json:"host"`
+		}
+		if err := s.do(ctx, http.MethodGet, s.controllerURL+"/indexes/"+s.index, nil, &index); err != nil {
+			return fmt.Errorf("pinecone: looking up index %s: %w", s.index, err)
+		}
+		host = index.Host
+		s.mu.Lock()
+		s.host = host
+		s.mu.Unlock()
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	return s.do(ctx, http.MethodPost, strings.TrimRight(host, "/")+path, body, result)
+}
+
+func (s *Store) do(ctx context.Context, method, url string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Api-Key", s.apiKey)
+	req.Header.Set("X-Pinecone-API-Version", apiVersion)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("pinecone: %s %s failed with status %s: %s", method, url, res.Status, msg)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("pinecone: decoding response: %w", err)
+	}
+	return nil
+}
+
+func metric(m vectorstore.Metric) string {
+	switch m {
+	case vectorstore.DotProduct:
+		return "dotproduct"
+	case vectorstore.Euclidean:
+		return "euclidean"
+	default:
+		return "cosine"
+	}
+}
@@ -0,0 +1,94 @@
+package pinecone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/parikxxit/go-llm/vectorstore"
+	"github.com/parikxxit/go-llm/vectorstore/vectorstoretest"
+)
+
+func TestStore(t *testing.T) {
+	bodies := map[string]string{}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies[r.URL.Path] = string(b)
+		switch r.URL.Path {
+		case "/indexes/docs":
+			json.NewEncoder(w).Encode(map[string]string{"host": srv.URL})
+		case "/query":
+			io.WriteString(w, `{"matches":[{"id":"a","score":0.9,"values":[1,0],"metadata":{"_text":"cats","lang":"en","author.name":"x"}}]}`)
+		default:
+			io.WriteString(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	s := New("key", "docs", WithControllerURL(srv.URL), WithNamespace("ns"))
+	err := s.Upsert(ctx, vectorstore.Document{
+		ID:        "a",
+		Text:      "cats",
+		Embedding: []float32{1, 0},
+		Metadata:  map[string]any{"lang": "en", "author": map[string]any{"name": "x"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"namespace":"ns","vectors":[{"id":"a","values":[1,0],"metadata":{"_text":"cats","author.name":"x","lang":"en"}}]}`
+	if got := bodies["/vectors/upsert"]; got != want {
+		t.Errorf("Upsert() body = %s, want %s", got, want)
+	}
+
+	matches, err := s.Query(ctx, vectorstore.Query{Vector: []float32{1, 0}, TopK: 1, Filter: vectorstore.In("lang", "en", "fr")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Document.Text != "cats" || matches[0].Document.Metadata["lang"] != "en" {
+		t.Errorf("Query() = %+v", matches)
+	}
+	if author, _ := matches[0].Document.Metadata["author"].(map[string]any); author["name"] != "x" {
+		t.Errorf("Query() metadata = %v, want the nested author back", matches[0].Document.Metadata)
+	}
+	var query map[string]any
+	json.Unmarshal([]byte(bodies["/query"]), &query)
+	if f, _ := json.Marshal(query["filter"]); string(f) != `{"lang":{"$in":["en","fr"]}}` {
+		t.Errorf("Query() filter = %s", f)
+	}
+
+	if err := s.Upsert(ctx, vectorstore.Document{ID: "b", Metadata: map[string]any{"bad": []any{1}}}); err == nil {
+		t.Error("Upsert() with unsupported metadata error = nil")
+	}
+}
+
+func TestFromMetadata(t *testing.T) {
+	got := fromMetadata(map[string]any{MetadataText: "t", "a": 1.0, "a.b": 2.0, "c.d.e": "x"})
+	if want := "map[a:1 a.b:2 c:map[d:map[e:x]]]"; fmt.Sprint(got) != want {
+		t.Errorf("fromMetadata() = %v, want %s", got, want)
+	}
+}
+
+// TestConformance runs against the two dimensional cosine index named in
+// PINECONE_INDEX, using a fresh namespace per test
+func TestConformance(t *testing.T) {
+	key, index := os.Getenv("PINECONE_API_KEY"), os.Getenv("PINECONE_INDEX")
+	if key == "" || index == "" {
+		t.Skip("PINECONE_API_KEY or PINECONE_INDEX not set")
+	}
+	vectorstoretest.Suite{
+		New: func(t *testing.T) vectorstore.Store {
+			return New(key, index, WithNamespace("gollm-"+uuid.NewString()))
+		},
+		// Serverless indexes are eventually consistent
+		Settle: 10 * time.Second,
+	}.Run(t)
+}
@@ -0,0 +1,144 @@
+package qdrant
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+	qc "github.com/qdrant/go-client/qdrant"
+)
+
+// translate converts a filter to a Qdrant payload condition
+func translate(f *vectorstore.Filter) (*qc.Condition, error) {
+	switch f.Op {
+	case vectorstore.OpAnd, vectorstore.OpOr:
+		if f.Op == vectorstore.OpOr && len(f.Filters) == 0 {
+			return never(), nil
+		}
+		conditions := make([]*qc.Condition, len(f.Filters))
+		for i := range f.Filters {
+			c, err := translate(&f.Filters[i])
+			if err != nil {
+				return nil, err
+			}
+			conditions[i] = c
+		}
+		if f.Op == vectorstore.OpAnd {
+			return qc.NewFilterAsCondition(&qc.Filter{Must: conditions}), nil
+		}
+		return qc.NewFilterAsCondition(&qc.Filter{Should: conditions}), nil
+	case vectorstore.OpEq:
+		return match(f.Key, f.Value)
+	case vectorstore.OpNe:
+		c, err := match(f.Key, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		return qc.NewFilterAsCondition(&qc.Filter{MustNot: []*qc.Condition{c}}), nil
+	case vectorstore.OpIn:
+		return matchAny(f.Key, f.Value.([]any))
+	default:
+		x, ok := number(f.Value)
+		if !ok {
+			return nil, fmt.Errorf("qdrant: filter %s on %s requires a number, got %T", f.Op, f.Key, f.Value)
+		}
+		var r qc.Range
+		switch f.Op {
+		case vectorstore.OpGt:
+			r.Gt = &x
+		case vectorstore.OpGte:
+			r.Gte = &x
+		case vectorstore.OpLt:
+			r.Lt = &x
+		default:
+			r.Lte = &x
+		}
+		return qc.NewRange(f.Key, &r), nil
+	}
+}
+
+// match builds an equality condition. Qdrant only matches keywords, integers
+// and booleans exactly, so fractional numbers use a closed range.
+func match(key string, value any) (*qc.Condition, error) {
+	switch v := value.(type) {
+	case string:
+		return qc.NewMatchKeyword(key, v), nil
+	case bool:
+		return qc.NewMatchBool(key, v), nil
+	}
+	x, ok := number(value)
+	if !ok {
+		return nil, fmt.Errorf("qdrant: cannot filter %s on a %T", key, value)
+	}
+	if x != math.Trunc(x) {
+		return qc.NewRange(key, &qc.Range{Gte: &x, Lte: &x}), nil
+	}
+	return qc.NewMatchInt(key, int64(x)), nil
+}
+
+// matchAny builds a condition matching any of values: a single condition
+// for keywords or integers alone, else one per value
+func matchAny(key string, values []any) (*qc.Condition, error) {
+	var keywords []string
+	var ints []int64
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			keywords = append(keywords, s)
+		} else if x, ok := number(v); ok && x == math.Trunc(x) {
+			ints = append(ints, int64(x))
+		}
+	}
+	switch {
+	case len(values) == 0:
+		return never(), nil
+	case len(keywords) == len(values):
+		return qc.NewMatchKeywords(key, keywords...), nil
+	case len(ints) == len(values):
+		return qc.NewMatchInts(key, ints...), nil
+	}
+	conditions := make([]*qc.Condition, len(values))
+	for i, v := range values {
+		c, err := match(key, v)
+		if err != nil {
+			return nil, err
+		}
+		conditions[i] = c
+	}
+	return qc.NewFilterAsCondition(&qc.Filter{Should: conditions}), nil
+}
+
+// never builds a condition matching no point: Qdrant matches everything with
+// an empty Should, but nothing when excluding the empty filter
+func never() *qc.Condition {
+	return qc.NewFilterAsCondition(&qc.Filter{MustNot: []*qc.Condition{qc.NewFilterAsCondition(&qc.Filter{})}})
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
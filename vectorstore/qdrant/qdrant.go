@@ -0,0 +1,269 @@
+// Package qdrant provides a vectorstore.Store backed by a Qdrant collection,
+// using the Qdrant gRPC API through the official client:
+//
+//	client, err := qc.NewClient(&qc.Config{Host: "localhost", Port: 6334, APIKey: key})
+//	store := qdrant.New(client, "docs")
+//
+// where qc is github.com/qdrant/go-client/qdrant.
+package qdrant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/parikxxit/go-llm/vectorstore"
+	qc "github.com/qdrant/go-client/qdrant"
+)
+
+// Payload keys holding the document ID and text next to the metadata
+const (
+	PayloadID   = "_id"
+	PayloadText = "_text"
+)
+
+// idNamespace derives Qdrant point UUIDs from document IDs, which may be any
+// string while Qdrant only accepts integers and UUIDs
+var idNamespace = uuid.MustParse("6f1f7a4e-2d1c-4c35-9a3b-8f0d9c3e5b21")
+
+// Store is a vectorstore.Store backed by a Qdrant collection
+type Store struct {
+	client     *qc.Client
+	collection string
+	vectorName string
+	metric     vectorstore.Metric
+}
+
+// Option is a function that configures a Store
+type Option func(*Store)
+
+// WithVectorName stores and searches embeddings under a named vector, for
+// collections holding several vectors per point
+func WithVectorName(name string) Option {
+	return func(s *Store) {
+		s.vectorName = name
+	}
+}
+
+// WithMetric sets the distance of collections created by CreateCollection,
+// Cosine by default. It must match the distance of existing collections.
+func WithMetric(metric vectorstore.Metric) Option {
+	return func(s *Store) {
+		s.metric = metric
+	}
+}
+
+// New creates a new store for collection through client, which the caller
+// closes
+func New(client *qc.Client, collection string, opts ...Option) *Store {
+	s := &Store{
+		client:     client,
+		collection: collection,
+		metric:     vectorstore.Cosine,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateCollection creates the collection for embeddings of the given
+// dimensions
+func (s *Store) CreateCollection(ctx context.Context, dimensions int) error {
+	params := &qc.VectorParams{Size: uint64(dimensions), Distance: distance(s.metric)}
+	vectors := qc.NewVectorsConfig(params)
+	if s.vectorName != "" {
+		vectors = qc.NewVectorsConfigMap(map[string]*qc.VectorParams{s.vectorName: params})
+	}
+	err := s.client.CreateCollection(ctx, &qc.CreateCollection{CollectionName: s.collection, VectorsConfig: vectors})
+	if err != nil {
+		return fmt.Errorf("qdrant: %w", err)
+	}
+	return nil
+}
+
+// DeleteCollection deletes the collection and all its points
+func (s *Store) DeleteCollection(ctx context.Context) error {
+	if err := s.client.DeleteCollection(ctx, s.collection); err != nil {
+		return fmt.Errorf("qdrant: %w", err)
+	}
+	return nil
+}
+
+// CollectionExists reports whether the collection exists
+func (s *Store) CollectionExists(ctx context.Context) (bool, error) {
+	exists, err := s.client.CollectionExists(ctx, s.collection)
+	if err != nil {
+		return false, fmt.Errorf("qdrant: %w", err)
+	}
+	return exists, nil
+}
+
+func (s *Store) Upsert(ctx context.Context, docs ...vectorstore.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	points := make([]*qc.PointStruct, len(docs))
+	for i, d := range docs {
+		if d.ID == "" {
+			return fmt.Errorf("document ID cannot be empty")
+		}
+		payload := make(map[string]any, len(d.Metadata)+2)
+		for k, v := range d.Metadata {
+			payload[k] = v
+		}
+		payload[PayloadID] = d.ID
+		payload[PayloadText] = d.Text
+		values, err := qc.TryValueMap(payload)
+		if err != nil {
+			return fmt.Errorf("qdrant: metadata of document %s: %w", d.ID, err)
+		}
+
+		vectors := qc.NewVectorsDense(d.Embedding)
+		if s.vectorName != "" {
+			vectors = qc.NewVectorsMap(map[string]*qc.Vector{s.vectorName: qc.NewVectorDense(d.Embedding)})
+		}
+		points[i] = &qc.PointStruct{Id: qc.NewID(pointID(d.ID)), Vectors: vectors, Payload: values}
+	}
+	wait := true
+	if _, err := s.client.Upsert(ctx, &qc.UpsertPoints{CollectionName: s.collection, Wait: &wait, Points: points}); err != nil {
+		return fmt.Errorf("qdrant: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Query(ctx context.Context, q vectorstore.Query) ([]vectorstore.Match, error) {
+	if err := q.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	limit := uint64(10)
+	if q.TopK > 0 {
+		limit = uint64(q.TopK)
+	}
+
+	req := &qc.QueryPoints{
+		CollectionName: s.collection,
+		Query:          qc.NewQueryDense(q.Vector),
+		Limit:          &limit,
+		WithPayload:    qc.NewWithPayload(true),
+		WithVectors:    qc.NewWithVectors(true),
+	}
+	if s.vectorName != "" {
+		req.Using = &s.vectorName
+	}
+	if q.Filter != nil {
+		condition, err := translate(q.Filter)
+		if err != nil {
+			return nil, err
+		}
+		req.Filter = &qc.Filter{Must: []*qc.Condition{condition}}
+	}
+
+	res, err := s.client.Query(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant: %w", err)
+	}
+
+	matches := make([]vectorstore.Match, len(res))
+	for i, p := range res {
+		score := float64(p.GetScore())
+		if s.metric == vectorstore.Euclidean {
+			score = -score // Qdrant returns the distance
+		}
+		matches[i] = vectorstore.Match{Document: s.document(p), Score: score}
+	}
+	return matches, nil
+}
+
+func (s *Store) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	points := make([]*qc.PointId, len(ids))
+	for i, id := range ids {
+		points[i] = qc.NewID(pointID(id))
+	}
+	wait := true
+	if _, err := s.client.Delete(ctx, &qc.DeletePoints{CollectionName: s.collection, Wait: &wait, Points: qc.NewPointsSelector(points...)}); err != nil {
+		return fmt.Errorf("qdrant: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) document(p *qc.ScoredPoint) vectorstore.Document {
+	d := vectorstore.Document{Metadata: make(map[string]any, len(p.GetPayload()))}
+	for k, v := range p.GetPayload() {
+		switch k {
+		case PayloadID:
+			d.ID = v.GetStringValue()
+		case PayloadText:
+			d.Text = v.GetStringValue()
+		default:
+			d.Metadata[k] = value(v)
+		}
+	}
+	if d.ID == "" {
+		d.ID = p.GetId().GetUuid()
+	}
+
+	vector := p.GetVectors().GetVector()
+	if s.vectorName != "" {
+		vector = p.GetVectors().GetVectors().GetVectors()[s.vectorName]
+	}
+	d.Embedding = dense(vector)
+	return d
+}
+
+// dense returns the values of a dense vector, sent in the deprecated data
+// field by servers before 1.15
+func dense(v *qc.VectorOutput) []float32 {
+	if d := v.GetDense(); d != nil {
+		return d.GetData()
+	}
+	return v.GetData()
+}
+
+// value converts a payload value to the Go value it was stored from
+func value(v *qc.Value) any {
+	switch k := v.GetKind().(type) {
+	case *qc.Value_BoolValue:
+		return k.BoolValue
+	case *qc.Value_IntegerValue:
+		return k.IntegerValue
+	case *qc.Value_DoubleValue:
+		return k.DoubleValue
+	case *qc.Value_StringValue:
+		return k.StringValue
+	case *qc.Value_ListValue:
+		list := make([]any, len(k.ListValue.GetValues()))
+		for i, e := range k.ListValue.GetValues() {
+			list[i] = value(e)
+		}
+		return list
+	case *qc.Value_StructValue:
+		m := make(map[string]any, len(k.StructValue.GetFields()))
+		for key, e := range k.StructValue.GetFields() {
+			m[key] = value(e)
+		}
+		return m
+	}
+	return nil
+}
+
+func pointID(id string) string {
+	if _, err := uuid.Parse(id); err == nil {
+		return id
+	}
+	return uuid.NewSHA1(idNamespace, []byte(id)).String()
+}
+
+func distance(m vectorstore.Metric) qc.Distance {
+	switch m {
+	case vectorstore.DotProduct:
+		return qc.Distance_Dot
+	case vectorstore.Euclidean:
+		return qc.Distance_Euclid
+	default:
+		return qc.Distance_Cosine
+	}
+}
@@ -0,0 +1,163 @@
+package qdrant
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/parikxxit/go-llm/vectorstore"
+	"github.com/parikxxit/go-llm/vectorstore/vectorstoretest"
+	qc "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakePoints records the requests of the points service, answering queries
+// with result
+type fakePoints struct {
+	qc.UnimplementedPointsServer
+	upsert *qc.UpsertPoints
+	query  *qc.QueryPoints
+	result []*qc.ScoredPoint
+}
+
+func (f *fakePoints) Upsert(_ context.Context, req *qc.UpsertPoints) (*qc.PointsOperationResponse, error) {
+	f.upsert = req
+	return &qc.PointsOperationResponse{Result: &qc.UpdateResult{}}, nil
+}
+
+func (f *fakePoints) Query(_ context.Context, req *qc.QueryPoints) (*qc.QueryResponse, error) {
+	f.query = req
+	return &qc.QueryResponse{Result: f.result}, nil
+}
+
+type fakeCollections struct {
+	qc.UnimplementedCollectionsServer
+	create *qc.CreateCollection
+}
+
+func (f *fakeCollections) Create(_ context.Context, req *qc.CreateCollection) (*qc.CollectionOperationResponse, error) {
+	f.create = req
+	return &qc.CollectionOperationResponse{Result: true}, nil
+}
+
+// serve starts a gRPC server of points and collections, returning a client
+// of it
+func serve(t *testing.T, points *fakePoints, collections *fakeCollections) *qc.Client {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	qc.RegisterPointsServer(srv, points)
+	qc.RegisterCollectionsServer(srv, collections)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	client, err := qc.NewClient(&qc.Config{Host: "127.0.0.1", Port: lis.Addr().(*net.TCPAddr).Port, SkipCompatibilityCheck: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestStore(t *testing.T) {
+	points := &fakePoints{result: []*qc.ScoredPoint{{
+		Id:      qc.NewID(pointID("a")),
+		Score:   0.9,
+		Payload: qc.NewValueMap(map[string]any{PayloadID: "a", PayloadText: "cats", "lang": "en", "year": 2020}),
+		Vectors: &qc.VectorsOutput{VectorsOptions: &qc.VectorsOutput_Vectors{Vectors: &qc.NamedVectorsOutput{
+			Vectors: map[string]*qc.VectorOutput{"dense": {Vector: &qc.VectorOutput_Dense{Dense: &qc.DenseVector{Data: []float32{1, 0}}}}},
+		}}},
+	}}}
+	collections := &fakeCollections{}
+	ctx := context.Background()
+	s := New(serve(t, points, collections), "docs", WithVectorName("dense"))
+
+	if err := s.CreateCollection(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	params := collections.create.GetVectorsConfig().GetParamsMap().GetMap()["dense"]
+	if collections.create.GetCollectionName() != "docs" || params.GetSize() != 2 || params.GetDistance() != qc.Distance_Cosine {
+		t.Errorf("CreateCollection() request = %v", collections.create)
+	}
+
+	err := s.Upsert(ctx, vectorstore.Document{ID: "a", Text: "cats", Embedding: []float32{1, 0}, Metadata: map[string]any{"lang": "en"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := points.upsert.GetPoints()[0]
+	if p.GetId().GetUuid() != pointID("a") || p.GetPayload()[PayloadID].GetStringValue() != "a" || p.GetPayload()["lang"].GetStringValue() != "en" ||
+		len(p.GetVectors().GetVectors().GetVectors()["dense"].GetData()) != 2 || !points.upsert.GetWait() {
+		t.Errorf("Upsert() point = %v", p)
+	}
+
+	matches, err := s.Query(ctx, vectorstore.Query{Vector: []float32{1, 0}, TopK: 1, Filter: vectorstore.Eq("lang", "en")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Document.ID != "a" || matches[0].Document.Text != "cats" || matches[0].Score < 0.89 ||
+		matches[0].Document.Embedding[0] != 1 || matches[0].Document.Metadata["lang"] != "en" || matches[0].Document.Metadata["year"] != int64(2020) {
+		t.Errorf("Query() = %+v", matches)
+	}
+	want := &qc.Filter{Must: []*qc.Condition{qc.NewMatchKeyword("lang", "en")}}
+	if q := points.query; q.GetUsing() != "dense" || q.GetLimit() != 1 || !proto.Equal(q.GetFilter(), want) {
+		t.Errorf("Query() request = %v", q)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	f := vectorstore.And(vectorstore.Ne("lang", "fr"), vectorstore.Or(vectorstore.Gt("year", 2020), vectorstore.In("tag", "a", "b"), vectorstore.Eq("score", 0.5)))
+	got, err := translate(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	year, half := 2020.0, 0.5
+	want := qc.NewFilterAsCondition(&qc.Filter{Must: []*qc.Condition{
+		qc.NewFilterAsCondition(&qc.Filter{MustNot: []*qc.Condition{qc.NewMatchKeyword("lang", "fr")}}),
+		qc.NewFilterAsCondition(&qc.Filter{Should: []*qc.Condition{
+			qc.NewRange("year", &qc.Range{Gt: &year}),
+			qc.NewMatchKeywords("tag", "a", "b"),
+			qc.NewRange("score", &qc.Range{Gte: &half, Lte: &half}),
+		}}),
+	}})
+	if !proto.Equal(got, want) {
+		t.Errorf("translate() = %v, want %v", got, want)
+	}
+
+	if _, err := translate(vectorstore.Eq("tags", []string{"a"})); err == nil {
+		t.Error("translate() of a list value error = nil")
+	}
+}
+
+// TestConformance runs against the server in QDRANT_ADDR, the host and gRPC
+// port, e.g. localhost:6334
+func TestConformance(t *testing.T) {
+	addr := os.Getenv("QDRANT_ADDR")
+	if addr == "" {
+		t.Skip("QDRANT_ADDR not set")
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := strconv.Atoi(port)
+	client, err := qc.NewClient(&qc.Config{Host: host, Port: p, APIKey: os.Getenv("QDRANT_API_KEY")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	vectorstoretest.Suite{New: func(t *testing.T) vectorstore.Store {
+		s := New(client, "gollm-"+uuid.NewString())
+		if err := s.CreateCollection(context.Background(), 2); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.DeleteCollection(context.Background()) })
+		return s
+	}}.Run(t)
+}
@@ -0,0 +1,76 @@
+// Package vectorstore provides interfaces and types for storing and searching
+// embeddings.
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/parikxxit/go-llm/embedder"
+)
+
+// Metric represents the similarity function used to rank matches
+type Metric string
+
+const (
+	Cosine     Metric = "cosine"
+	DotProduct Metric = "dot"
+	Euclidean  Metric = "euclidean"
+)
+
+// Document represents a stored text with its embedding and metadata
+type Document struct {
+	ID        string
+	Text      string
+	Embedding []float32
+	Metadata  map[string]any
+}
+
+// Match represents a query result. Higher scores are more similar: cosine
+// similarity, dot product, or the negated distance for Euclidean.
+type Match struct {
+	Document Document
+	Score    float64
+}
+
+// Query represents a similarity search
+type Query struct {
+	Vector []float32
+	TopK   int
+	Filter *Filter // Optional metadata filter
+}
+
+// Store defines the interface for vector stores
+type Store interface {
+	// Upsert inserts documents or replaces those with the same ID
+	Upsert(ctx context.Context, docs ...Document) error
+
+	// Query returns the TopK documents most similar to the query vector
+	Query(ctx context.Context, q Query) ([]Match, error)
+
+	// Delete removes documents by ID; unknown IDs are ignored
+	Delete(ctx context.Context, ids ...string) error
+}
+
+// Embed fills the Embedding of docs using emb, in one request
+func Embed(ctx context.Context, emb embedder.Embedder, model string, docs []Document) error {
+	input := make([]string, len(docs))
+	for i, d := range docs {
+		input[i] = d.Text
+	}
+
+	resp, err := emb.Embed(ctx, &embedder.Request{Model: model, Input: input, Float32: true})
+	if err != nil {
+		return err
+	}
+	if len(resp.Data) != len(docs) {
+		return fmt.Errorf("embedder returned %d embeddings for %d documents", len(resp.Data), len(docs))
+	}
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(docs) {
+			return fmt.Errorf("embedder returned out of range index %d", d.Index)
+		}
+		docs[d.Index].Embedding = d.Vector32()
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package vectorstore
+
+import (
+	"context"
+	"testing"
+)
+
+func seed(t *testing.T, s Store) {
+	t.Helper()
+	err := s.Upsert(context.Background(),
+		Document{ID: "a", Text: "cats", Embedding: []float32{1, 0}, Metadata: map[string]any{"lang": "en", "year": 2020}},
+		Document{ID: "b", Text: "dogs", Embedding: []float32{0.8, 0.6}, Metadata: map[string]any{"lang": "en", "year": 2023}},
+		Document{ID: "c", Text: "chats", Embedding: []float32{0, 1}, Metadata: map[string]any{"lang": "fr", "year": 2024}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ids(matches []Match) []string {
+	out := make([]string, len(matches))
+	for i, m := range matches {
+		out[i] = m.Document.ID
+	}
+	return out
+}
+
+func TestMemoryStore_Query(t *testing.T) {
+	ctx := context.Background()
+	for metric, want := range map[Metric]string{Cosine: "a,b", DotProduct: "a,b", Euclidean: "a,b"} {
+		s := NewMemoryStore(metric)
+		seed(t, s)
+		matches, err := s.Query(ctx, Query{Vector: []float32{1, 0.1}, TopK: 2})
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		if got := ids(matches); len(got) != 2 || got[0]+","+got[1] != want {
+			t.Errorf("%s: Query() = %v, want %s", metric, got, want)
+		}
+	}
+
+	s := NewMemoryStore(Cosine)
+	seed(t, s)
+	matches, _ := s.Query(ctx, Query{Vector: []float32{1, 0}, Filter: And(Eq("lang", "en"), Gte("year", 2021.0))})
+	if got := ids(matches); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Query() with filter = %v, want [b]", got)
+	}
+	matches, _ = s.Query(ctx, Query{Vector: []float32{1, 0}, Filter: Or(In("lang", "fr"), Lt("year", 2021))})
+	if got := ids(matches); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("Query() with or filter = %v, want [a c]", got)
+	}
+	if _, err := s.Query(ctx, Query{Vector: []float32{1, 0}, Filter: &Filter{Op: "like"}}); err == nil {
+		t.Error("Query() with invalid filter error = nil")
+	}
+}
+
+func TestMemoryStore_UpsertDelete(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(Cosine)
+	seed(t, s)
+
+	if err := s.Upsert(ctx, Document{ID: "a", Text: "cats v2", Embedding: []float32{1, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, "c", "missing"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", s.Len())
+	}
+	matches, _ := s.Query(ctx, Query{Vector: []float32{1, 0}, TopK: 1})
+	if matches[0].Document.Text != "cats v2" {
+		t.Errorf("Query() = %+v, want replaced document", matches[0].Document)
+	}
+	if err := s.Upsert(ctx, Document{ID: "x"}); err == nil {
+		t.Error("Upsert() without embedding error = nil")
+	}
+}
@@ -0,0 +1,159 @@
+// Package vectorstoretest provides a conformance suite that every
+// vectorstore.Store implementation is expected to pass.
+package vectorstoretest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// Suite runs the conformance tests against stores returned by New. The
+// suite uses two dimensional embeddings and assumes cosine similarity.
+type Suite struct {
+	// New returns an empty store; it is called once per test
+	New func(t *testing.T) vectorstore.Store
+	// Settle is waited after writes, for eventually consistent backends
+	Settle time.Duration
+}
+
+// Run runs the suite as subtests of t
+func (s Suite) Run(t *testing.T) {
+	t.Run("Query", s.testQuery)
+	t.Run("Upsert", s.testUpsert)
+	t.Run("Filter", s.testFilter)
+	t.Run("Delete", s.testDelete)
+}
+
+var corpus = []vectorstore.Document{
+	{ID: "a", Text: "cats", Embedding: []float32{1, 0}, Metadata: map[string]any{"lang": "en", "year": 2020}},
+	{ID: "b", Text: "dogs", Embedding: []float32{0.8, 0.6}, Metadata: map[string]any{"lang": "en", "year": 2023}},
+	{ID: "c", Text: "chats", Embedding: []float32{0, 1}, Metadata: map[string]any{"lang": "fr", "year": 2024}},
+}
+
+func (s Suite) seed(t *testing.T) vectorstore.Store {
+	t.Helper()
+	store := s.New(t)
+	docs := make([]vectorstore.Document, len(corpus))
+	copy(docs, corpus)
+	if err := store.Upsert(context.Background(), docs...); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	s.settle()
+	return store
+}
+
+func (s Suite) settle() {
+	if s.Settle > 0 {
+		time.Sleep(s.Settle)
+	}
+}
+
+func (s Suite) query(t *testing.T, store vectorstore.Store, q vectorstore.Query) []string {
+	t.Helper()
+	matches, err := store.Query(context.Background(), q)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.Document.ID
+		if i > 0 && m.Score > matches[i-1].Score {
+			t.Errorf("Query() scores not descending: %v then %v", matches[i-1].Score, m.Score)
+		}
+	}
+	return ids
+}
+
+func (s Suite) testQuery(t *testing.T) {
+	store := s.seed(t)
+
+	matches, err := store.Query(context.Background(), vectorstore.Query{Vector: []float32{1, 0.1}, TopK: 2})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(matches) != 2 || matches[0].Document.ID != "a" || matches[1].Document.ID != "b" {
+		t.Fatalf("Query() = %v, want [a b]", ids(matches))
+	}
+	d := matches[0].Document
+	if d.Text != "cats" || d.Metadata["lang"] != "en" || len(d.Embedding) != 2 {
+		t.Errorf("Query() document = %+v, want text, metadata and embedding of a", d)
+	}
+	if matches[0].Score <= matches[1].Score {
+		t.Errorf("Query() scores = %v, %v; want descending", matches[0].Score, matches[1].Score)
+	}
+}
+
+func (s Suite) testUpsert(t *testing.T) {
+	store := s.seed(t)
+
+	err := store.Upsert(context.Background(), vectorstore.Document{ID: "c", Text: "chats v2", Embedding: []float32{1, 0.05}, Metadata: map[string]any{"lang": "fr"}})
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	s.settle()
+
+	matches, err := store.Query(context.Background(), vectorstore.Query{Vector: []float32{0, 1}, TopK: 3})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(matches) != 3 {
+		t.Fatalf("Query() = %v, want 3 documents after replacing one", ids(matches))
+	}
+	for _, m := range matches {
+		if m.Document.ID == "c" && m.Document.Text != "chats v2" {
+			t.Errorf("Upsert() did not replace document c: %+v", m.Document)
+		}
+	}
+	if matches[0].Document.ID == "c" {
+		t.Errorf("Query() = %v, replaced embedding of c is not used", ids(matches))
+	}
+}
+
+func (s Suite) testFilter(t *testing.T) {
+	store := s.seed(t)
+	v := []float32{1, 0}
+
+	tests := []struct {
+		filter *vectorstore.Filter
+		want   string
+	}{
+		{vectorstore.Eq("lang", "en"), "[a b]"},
+		{vectorstore.Ne("lang", "en"), "[c]"},
+		{vectorstore.In("lang", "fr", "de"), "[c]"},
+		{vectorstore.Gt("year", 2020), "[b c]"},
+		{vectorstore.Lte("year", 2023), "[a b]"},
+		{vectorstore.And(vectorstore.Eq("lang", "en"), vectorstore.Gte("year", 2021)), "[b]"},
+		{vectorstore.Or(vectorstore.Eq("lang", "fr"), vectorstore.Lt("year", 2021)), "[a c]"},
+		{vectorstore.Or(), "[]"},
+		{vectorstore.In("lang"), "[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(s.query(t, store, vectorstore.Query{Vector: v, TopK: 10, Filter: tt.filter})); got != tt.want {
+			t.Errorf("Query() with filter %+v = %s, want %s", *tt.filter, got, tt.want)
+		}
+	}
+}
+
+func (s Suite) testDelete(t *testing.T) {
+	store := s.seed(t)
+
+	if err := store.Delete(context.Background(), "a", "missing"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	s.settle()
+	if got := fmt.Sprint(s.query(t, store, vectorstore.Query{Vector: []float32{1, 0}, TopK: 10})); got != "[b c]" {
+		t.Errorf("Query() after Delete() = %s, want [b c]", got)
+	}
+}
+
+func ids(matches []vectorstore.Match) []string {
+	out := make([]string, len(matches))
+	for i, m := range matches {
+		out[i] = m.Document.ID
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package weaviate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// enum is rendered as a bare GraphQL enum value
+type enum string
+
+var operators = map[vectorstore.Op]enum{
+	vectorstore.OpEq:  "Equal",
+	vectorstore.OpNe:  "NotEqual",
+	vectorstore.OpGt:  "GreaterThan",
+	vectorstore.OpGte: "GreaterThanEqual",
+	vectorstore.OpLt:  "LessThan",
+	vectorstore.OpLte: "LessThanEqual",
+	vectorstore.OpIn:  "ContainsAny",
+	vectorstore.OpAnd: "And",
+	vectorstore.OpOr:  "Or",
+}
+
+// translate converts a filter to a Weaviate where filter
+func translate(f *vectorstore.Filter) map[string]any {
+	where := map[string]any{"operator": operators[f.Op]}
+	switch f.Op {
+	case vectorstore.OpAnd, vectorstore.OpOr:
+		if f.Op == vectorstore.OpOr && len(f.Filters) == 0 {
+			return never()
+		}
+		operands := make([]any, len(f.Filters))
+		for i := range f.Filters {
+			operands[i] = translate(&f.Filters[i])
+		}
+		where["operands"] = operands
+		return where
+	case vectorstore.OpIn:
+		values, _ := f.Value.([]any)
+		if len(values) == 0 {
+			return never()
+		}
+		field := valueField(values[0]) + "Array"
+		where["path"] = []string{f.Key}
+		where[field] = values
+		return where
+	default:
+		where["path"] = []string{f.Key}
+		where[valueField(f.Value)] = f.Value
+		return where
+	}
+}
+
+// never builds a where filter matching no object, standing for an empty Or
+// or In which Weaviate rejects
+func never() map[string]any {
+	return map[string]any{"operator": "And", "operands": []any{
+		map[string]any{"operator": "Equal", "path": []string{PropertyID}, "valueText": ""},
+		map[string]any{"operator": "NotEqual", "path": []string{PropertyID}, "valueText": ""},
+	}}
+}
+
+func valueField(v any) string {
+	switch v.(type) {
+	case bool:
+		return "valueBoolean"
+	case string:
+		return "valueText"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		// Automatic schema creation stores JSON numbers as number properties
+		return "valueNumber"
+	default:
+		return "valueText"
+	}
+}
+
+// graphql renders v as a GraphQL input value. Object keys are sorted so the
+// output is deterministic.
+func graphql(v any) string {
+	switch v := v.(type) {
+	case enum:
+		return string(v)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + ": " + graphql(v[k])
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = graphql(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case []string:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = graphql(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	default:
+		// Strings, numbers, booleans and number arrays share JSON's syntax
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%q", fmt.Sprint(v))
+		}
+		return string(b)
+	}
+}
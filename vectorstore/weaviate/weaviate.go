@@ -0,0 +1,286 @@
+// Package weaviate provides a vectorstore.Store backed by a Weaviate class,
+// using the Weaviate REST and GraphQL APIs.
+package weaviate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/parikxxit/go-llm/transport"
+	"github.com/parikxxit/go-llm/vectorstore"
+)
+
+// Properties holding the document ID, text and JSON encoded metadata. Each
+// metadata key is also stored as its own property so it can be filtered on,
+// which requires keys to be valid GraphQL names.
+const (
+	PropertyID       = "docId"
+	PropertyText     = "text"
+	PropertyMetadata = "metadataJson"
+)
+
+// idNamespace derives Weaviate object UUIDs from document IDs
+var idNamespace = uuid.MustParse("0b5b8d0e-4a8f-4d3e-b1f6-2c7a9e6d4f13")
+
+// Store is a vectorstore.Store backed by a Weaviate class
+type Store struct {
+	baseURL    string
+	class      string
+	apiKey     string
+	metric     vectorstore.Metric
+	httpClient *http.Client
+}
+
+// Option is a function that configures a Store
+type Option func(*Store)
+
+// WithAPIKey sets the API key sent as a bearer token
+func WithAPIKey(key string) Option {
+	return func(s *Store) {
+		s.apiKey = key
+	}
+}
+
+// WithMetric sets the distance of classes created by CreateClass, Cosine by
+// default. It must match the distance of existing classes.
+func WithMetric(metric vectorstore.Metric) Option {
+	return func(s *Store) {
+		s.metric = metric
+	}
+}
+
+// WithHTTPClient sets the HTTP client, transport.DefaultClient by default
+func WithHTTPClient(c *http.Client) Option {
+	return func(s *Store) {
+		s.httpClient = c
+	}
+}
+
+// New creates a new store for class on the Weaviate server at baseURL, e.g.
+// http://localhost:8080. Weaviate capitalizes class names, so class is too.
+func New(baseURL, class string, opts ...Option) *Store {
+	if class != "" {
+		class = strings.ToUpper(class[:1]) + class[1:]
+	}
+	s := &Store{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		class:      class,
+		metric:     vectorstore.Cosine,
+		httpClient: transport.DefaultClient(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateClass creates the class with vectors supplied by the client
+func (s *Store) CreateClass(ctx context.Context) error {
+	body := map[string]any{
+		"class":             s.class,
+		"vectorizer":        "none",
+		"vectorIndexConfig": map[string]any{"distance": distance(s.metric)},
+		"properties": []map[string]any{
+			{"name": PropertyID, "dataType": []string{"text"}, "tokenization": "field"},
+			{"name": PropertyText, "dataType": []string{"text"}},
+			{"name": PropertyMetadata, "dataType": []string{"text"}, "indexFilterable": false, "indexSearchable": false},
+		},
+	}
+	return s.do(ctx, http.MethodPost, "/v1/schema", body, nil)
+}
+
+// DeleteClass deletes the class and all its objects
+func (s *Store) DeleteClass(ctx context.Context) error {
+	return s.do(ctx, http.MethodDelete, "/v1/schema/"+url.PathEscape(s.class), nil, nil)
+}
+
+func (s *Store) Upsert(ctx context.Context, docs ...vectorstore.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	objects := make([]map[string]any, len(docs))
+	for i, d := range docs {
+		if d.ID == "" {
+			return fmt.Errorf("document ID cannot be empty")
+		}
+		metadata, err := json.Marshal(d.Metadata)
+		if err != nil {
+			return fmt.Errorf("encoding metadata of document %s: %w", d.ID, err)
+		}
+		properties := make(map[string]any, len(d.Metadata)+3)
+		for k, v := range d.Metadata {
+			properties[k] = v
+		}
+		properties[PropertyID] = d.ID
+		properties[PropertyText] = d.Text
+		properties[PropertyMetadata] = string(metadata)
+		objects[i] = map[string]any{"class": s.class, "id": objectID(d.ID), "vector": d.Embedding, "properties": properties}
+	}
+
+	var res []struct {
+		ID     string `json:"id"`
+		Result struct {
+			Errors *struct {
+				Error []struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"errors"`
+		} `json:"result"`
+	}
+	if err := s.do(ctx, http.MethodPost, "/v1/batch/objects", map[string]any{"objects": objects}, &res); err != nil {
+		return err
+	}
+	// The batch endpoint reports per-object failures with a 200 status
+	for _, r := range res {
+		if r.Result.Errors != nil && len(r.Result.Errors.Error) > 0 {
+			return fmt.Errorf("weaviate: upserting object %s: %s", r.ID, r.Result.Errors.Error[0].Message)
+		}
+	}
+	return nil
+}
+
+func (s *Store) Query(ctx context.Context, q vectorstore.Query) ([]vectorstore.Match, error) {
+	if err := q.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	limit := q.TopK
+	if limit <= 0 {
+		limit = 10
+	}
+
+	args := fmt.Sprintf("nearVector: %s, limit: %d", graphql(map[string]any{"vector": q.Vector}), limit)
+	if q.Filter != nil {
+		args += ", where: " + graphql(translate(q.Filter))
+	}
+	query := fmt.Sprintf("{ Get { %s(%s) { %s %s %s _additional { distance vector } } } }",
+		s.class, args, PropertyID, PropertyText, PropertyMetadata)
+
+	var res struct {
+		Data struct {
+			Get map[string][]struct {
+				ID         string `json:"docId"`
+				Text       string `json:"text"`
+				Metadata   string `json:"metadataJson"`
+				Additional struct {
+					Distance float64   `json:"distance"`
+					Vector   []float32 `json:"vector"`
+				} `json:"_additional"`
+			} `json:"Get"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := s.do(ctx, http.MethodPost, "/v1/graphql", map[string]string{"query": query}, &res); err != nil {
+		return nil, err
+	}
+	if len(res.Errors) > 0 {
+		return nil, fmt.Errorf("weaviate: %s", res.Errors[0].Message)
+	}
+
+	objects := res.Data.Get[s.class]
+	matches := make([]vectorstore.Match, len(objects))
+	for i, o := range objects {
+		d := vectorstore.Document{ID: o.ID, Text: o.Text, Embedding: o.Additional.Vector}
+		if o.Metadata != "" && o.Metadata != "null" {
+			if err := json.Unmarshal([]byte(o.Metadata), &d.Metadata); err != nil {
+				return nil, fmt.Errorf("decoding metadata of document %s: %w", o.ID, err)
+			}
+		}
+		matches[i] = vectorstore.Match{Document: d, Score: s.score(o.Additional.Distance)}
+	}
+	return matches, nil
+}
+
+func (s *Store) Delete(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		err := s.do(ctx, http.MethodDelete, "/v1/objects/"+url.PathEscape(s.class)+"/"+objectID(id), nil, nil)
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// score converts a Weaviate distance to a vectorstore score
+func (s *Store) score(distance float64) float64 {
+	if s.metric == vectorstore.Cosine || s.metric == "" {
+		return 1 - distance
+	}
+	return -distance // Negative dot product or squared Euclidean distance
+}
+
+type statusError struct {
+	status int
+	msg    string
+}
+
+func (e *statusError) Error() string { return e.msg }
+
+func isNotFound(err error) bool {
+	se, ok := err.(*statusError)
+	return ok && se.status == http.StatusNotFound
+}
+
+func (s *Store) do(ctx context.Context, method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return &statusError{
+			status: res.StatusCode,
+			msg:    fmt.Sprintf("weaviate: %s %s failed with status %s: %s", method, path, res.Status, msg),
+		}
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("weaviate: decoding response: %w", err)
+	}
+	return nil
+}
+
+func objectID(id string) string {
+	return uuid.NewSHA1(idNamespace, []byte(id)).String()
+}
+
+func distance(m vectorstore.Metric) string {
+	switch m {
+	case vectorstore.DotProduct:
+		return "dot"
+	case vectorstore.Euclidean:
+		return "l2-squared"
+	default:
+		return "cosine"
+	}
+}
@@ -0,0 +1,83 @@
+package weaviate
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/parikxxit/go-llm/vectorstore"
+	"github.com/parikxxit/go-llm/vectorstore/vectorstoretest"
+)
+
+func TestStore(t *testing.T) {
+	var graphqlQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/batch/objects":
+			io.WriteString(w, `[{"id":"x","result":{}}]`)
+		case r.URL.Path == "/v1/graphql":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			graphqlQuery = body["query"]
+			io.WriteString(w, `{"data":{"Get":{"Docs":[{"docId":"a","text":"cats","metadataJson":"{\"lang\":\"en\"}","_additional":{"distance":0.25,"vector":[1,0]}}]}}}`)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	// Weaviate answers with the capitalized class name
+	s := New(srv.URL, "docs")
+	if err := s.Upsert(ctx, vectorstore.Document{ID: "a", Text: "cats", Embedding: []float32{1, 0}}); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := s.Query(ctx, vectorstore.Query{Vector: []float32{1, 0}, TopK: 1, Filter: vectorstore.Eq("lang", "en")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Docs(nearVector: {vector: [1,0]}, limit: 1, where: {operator: Equal, path: ["lang"], valueText: "en"})`
+	if !strings.Contains(graphqlQuery, want) {
+		t.Errorf("Query() sent %s, want it to contain %s", graphqlQuery, want)
+	}
+	if len(matches) != 1 || matches[0].Score != 0.75 || matches[0].Document.Metadata["lang"] != "en" {
+		t.Errorf("Query() = %+v", matches)
+	}
+
+	if err := s.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Delete() of missing document error = %v", err)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	f := vectorstore.Or(vectorstore.Gte("year", 2021), vectorstore.In("tag", "a", "b"))
+	got := graphql(translate(f))
+	want := `{operands: [{operator: GreaterThanEqual, path: ["year"], valueNumber: 2021}, {operator: ContainsAny, path: ["tag"], valueTextArray: ["a", "b"]}], operator: Or}`
+	if got != want {
+		t.Errorf("translate() = %s, want %s", got, want)
+	}
+}
+
+// TestConformance runs against the server in WEAVIATE_URL, e.g.
+// http://localhost:8080
+func TestConformance(t *testing.T) {
+	addr := os.Getenv("WEAVIATE_URL")
+	if addr == "" {
+		t.Skip("WEAVIATE_URL not set")
+	}
+	vectorstoretest.Suite{New: func(t *testing.T) vectorstore.Store {
+		s := New(addr, "Gollm"+strings.ReplaceAll(uuid.NewString(), "-", ""))
+		if err := s.CreateClass(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.DeleteClass(context.Background()) })
+		return s
+	}}.Run(t)
+}